@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"crypto/x509"
 	"flag"
 	"log"
 	"net/url"
@@ -12,6 +13,7 @@ import (
 	"syscall"
 	"time"
 
+	"techulus/cloud-agent/internal/acme"
 	"techulus/cloud-agent/internal/agent"
 	"techulus/cloud-agent/internal/api"
 	"techulus/cloud-agent/internal/build"
@@ -53,12 +55,21 @@ func sanitizedEndpoint(endpoint string) string {
 
 func main() {
 	var (
-		controlPlaneURL     string
-		token               string
-		isProxy             bool
-		logsEndpointFlag    string
-		metricsEndpointFlag string
-		disableDNS          bool
+		controlPlaneURL      string
+		token                string
+		isProxy              bool
+		logsEndpointFlag     string
+		metricsEndpointFlag  string
+		disableDNS           bool
+		acmeDirectoryURL     string
+		acmeEmail            string
+		acmeCABundle         string
+		serverlessCheckpoint bool
+		buildSlots           int
+		buildQueue           int
+		buildCPUs            float64
+		buildMemory          string
+		vulnDB               string
 	)
 
 	flag.StringVar(&controlPlaneURL, "url", "", "Control plane URL (required)")
@@ -67,12 +78,30 @@ func main() {
 	flag.StringVar(&logsEndpointFlag, "logs-endpoint", "", "Override logs endpoint URL (optional)")
 	flag.StringVar(&metricsEndpointFlag, "metrics-endpoint", "", "Override metrics endpoint URL (optional)")
 	flag.BoolVar(&disableDNS, "no-dns", false, "Disable local DNS server")
+	flag.StringVar(&acmeDirectoryURL, "acme-directory", acme.DefaultDirectoryURL, "ACME directory URL for fallback certificate renewal (proxy only)")
+	flag.StringVar(&acmeEmail, "acme-email", "", "Contact email for the fallback ACME account (optional)")
+	flag.StringVar(&acmeCABundle, "acme-ca-bundle", "", "PEM bundle trusted for the ACME directory, e.g. a local Pebble CA (optional)")
+	flag.BoolVar(&serverlessCheckpoint, "serverless-checkpoint", false, "Checkpoint sleeping serverless containers with CRIU and restore them on wake (proxy only)")
+	flag.IntVar(&buildSlots, "build-slots", 1, "Number of builds this agent runs concurrently")
+	flag.IntVar(&buildQueue, "build-queue", 2, "Number of extra builds this agent accepts and queues locally while every build slot is busy")
+	flag.Float64Var(&buildCPUs, "build-cpus", 0, "CPU limit per build, e.g. 2 or 1.5 (0 for unlimited)")
+	flag.StringVar(&buildMemory, "build-memory", "", "Memory limit per build, e.g. 4g or 512m (optional)")
+	flag.StringVar(&vulnDB, "vuln-db", "", "Trivy cache directory holding an offline vulnerability database; scans built images when set (optional)")
 	flag.Parse()
 
 	if controlPlaneURL == "" {
 		log.Fatal("--url is required")
 	}
 
+	buildMemoryBytes, memoryErr := build.ParseMemory(buildMemory)
+	if memoryErr != nil {
+		log.Fatalf("Invalid --build-memory: %v", memoryErr)
+	}
+	buildLimits := build.Limits{Slots: buildSlots, QueueSize: buildQueue, CPUs: buildCPUs, MemoryBytes: buildMemoryBytes}
+	if err := buildLimits.Validate(); err != nil {
+		log.Fatalf("Invalid build limits: %v", err)
+	}
+
 	var logsEndpoint string
 	var metricsEndpoint string
 
@@ -100,6 +129,11 @@ func main() {
 	if err := build.CheckPrerequisites(); err != nil {
 		log.Fatalf("Build prerequisites check failed: %v", err)
 	}
+	if vulnDB != "" {
+		if err := build.CheckScannerPrerequisites(vulnDB); err != nil {
+			log.Fatalf("Vulnerability scanner prerequisites check failed: %v", err)
+		}
+	}
 
 	if err := os.MkdirAll(dataDir, 0o700); err != nil {
 		log.Fatalf("Failed to create data directory: %v", err)
@@ -306,12 +340,37 @@ func main() {
 
 	var builder *build.Builder
 	if logsSender != nil {
-		builder = build.NewBuilder(dataDir, logsSender)
+		builder = build.NewBuilder(dataDir, logsSender, buildLimits)
 		log.Println("[build] build system enabled")
 	} else {
-		builder = build.NewBuilder(dataDir, nil)
+		builder = build.NewBuilder(dataDir, nil, buildLimits)
 		log.Println("[build] build system enabled (no log streaming)")
 	}
+	log.Printf("[build] %d build slots, %d queued builds", buildLimits.Slots, buildLimits.QueueSize)
+	if vulnDB != "" {
+		builder.SetVulnerabilityDB(vulnDB)
+		log.Printf("[build] scanning built images with the vulnerability database in %s", vulnDB)
+	}
+
+	var acmeManager *acme.Manager
+	if config.IsProxy {
+		acmeConfig := acme.Config{DirectoryURL: acmeDirectoryURL, Email: acmeEmail}
+		if acmeCABundle != "" {
+			bundle, err := os.ReadFile(acmeCABundle)
+			if err != nil {
+				log.Fatalf("Failed to read ACME CA bundle: %v", err)
+			}
+			acmeConfig.RootCAs = x509.NewCertPool()
+			if !acmeConfig.RootCAs.AppendCertsFromPEM(bundle) {
+				log.Fatalf("ACME CA bundle %s contains no certificates", acmeCABundle)
+			}
+		}
+		acmeManager, err = acme.NewManager(dataDir, config.EncryptionKey, acmeConfig)
+		if err != nil {
+			log.Fatalf("Failed to initialize ACME fallback: %v", err)
+		}
+		log.Printf("[acme] fallback renewal enabled, directory: %s", acmeDirectoryURL)
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
@@ -333,7 +392,7 @@ func main() {
 	privateIP := network.PrivateIP()
 	log.Printf("Agent %s started. Public IP: %s, Private IP: %s. Tick interval: %v", agent.Version, publicIP, privateIP, agent.TickInterval)
 
-	agentInstance := agent.NewAgent(client, reconciler, config, publicIP, privateIP, dataDir, logCollector, traefikLogCollector, metricsSender, routeOwners, builder, registryManager, config.IsProxy, disableDNS)
+	agentInstance := agent.NewAgent(client, reconciler, config, publicIP, privateIP, dataDir, logCollector, traefikLogCollector, metricsSender, routeOwners, builder, registryManager, acmeManager, config.IsProxy, disableDNS, serverlessCheckpoint)
 	agentInstance.Run(ctx)
 
 	if agentLogFlusherDone != nil {
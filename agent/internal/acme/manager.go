@@ -0,0 +1,423 @@
+package acme
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/acme"
+
+	"techulus/cloud-agent/internal/crypto"
+)
+
+const (
+	DefaultDirectoryURL = acme.LetsEncryptURL
+	ChallengePort       = 18081
+	challengePathPrefix = "/.well-known/acme-challenge/"
+	accountKeyFileName  = "account.key"
+)
+
+type Config struct {
+	DirectoryURL string
+	Email        string
+	// RootCAs overrides the trust store used to reach the ACME directory, so a
+	// local Pebble instance with its own CA can stand in for Let's Encrypt.
+	RootCAs *x509.CertPool
+}
+
+type Certificate struct {
+	Domain         string    `json:"domain"`
+	Certificate    string    `json:"certificate"`
+	CertificateKey string    `json:"certificateKey"`
+	NotAfter       time.Time `json:"notAfter"`
+	IssuedAt       time.Time `json:"issuedAt"`
+}
+
+type Manager struct {
+	mu        sync.Mutex
+	issueMu   sync.Mutex
+	root, key string
+	config    Config
+	client    *acme.Client
+	tokens    map[string]string
+	certs     map[string]Certificate
+	server    *http.Server
+}
+
+func NewManager(dataDir, encryptionKey string, config Config) (*Manager, error) {
+	if config.DirectoryURL == "" {
+		config.DirectoryURL = DefaultDirectoryURL
+	}
+	m := &Manager{
+		root:   filepath.Join(dataDir, "acme"),
+		key:    encryptionKey,
+		config: config,
+		tokens: map[string]string{},
+		certs:  map[string]Certificate{},
+	}
+	if err := os.MkdirAll(filepath.Join(m.root, "certs"), 0700); err != nil {
+		return nil, fmt.Errorf("create acme directory: %w", err)
+	}
+	if err := m.loadCertificates(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (m *Manager) DirectoryURL() string {
+	return m.config.DirectoryURL
+}
+
+func (m *Manager) Start(ctx context.Context) error {
+	addr := fmt.Sprintf("127.0.0.1:%d", ChallengePort)
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	m.server = &http.Server{
+		Handler:           m,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := m.server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("[acme] shutdown error: %v", err)
+		}
+	}()
+
+	go func() {
+		log.Printf("[acme] challenge responder listening on %s", addr)
+		if err := m.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("[acme] challenge responder error: %v", err)
+		}
+	}()
+
+	return nil
+}
+
+func (m *Manager) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.URL.Path, challengePathPrefix) {
+		http.NotFound(w, r)
+		return
+	}
+	token := strings.TrimPrefix(r.URL.Path, challengePathPrefix)
+
+	m.mu.Lock()
+	response, ok := m.tokens[token]
+	m.mu.Unlock()
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	_, _ = w.Write([]byte(response))
+}
+
+func (m *Manager) Certificates() []Certificate {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	certs := make([]Certificate, 0, len(m.certs))
+	for _, cert := range m.certs {
+		certs = append(certs, cert)
+	}
+	sort.Slice(certs, func(i, j int) bool {
+		return certs[i].Domain < certs[j].Domain
+	})
+	return certs
+}
+
+func (m *Manager) Forget(domain string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.certs[domain]; !ok {
+		return nil
+	}
+	if err := os.Remove(m.certificatePath(domain)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove certificate for %s: %w", domain, err)
+	}
+	delete(m.certs, domain)
+	return nil
+}
+
+func (m *Manager) Obtain(ctx context.Context, domain string) (*Certificate, error) {
+	if domain == "" || strings.Contains(domain, "*") || strings.ContainsAny(domain, `/\`) {
+		return nil, fmt.Errorf("domain %q cannot be issued with HTTP-01", domain)
+	}
+
+	m.issueMu.Lock()
+	defer m.issueMu.Unlock()
+
+	client, err := m.acmeClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(domain))
+	if err != nil {
+		return nil, fmt.Errorf("create order: %w", err)
+	}
+	for _, authzURL := range order.AuthzURLs {
+		if err := m.authorize(ctx, client, authzURL); err != nil {
+			return nil, err
+		}
+	}
+	order, err = client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return nil, fmt.Errorf("wait for order: %w", err)
+	}
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate certificate key: %w", err)
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: domain},
+		DNSNames: []string{domain},
+	}, privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("create certificate request: %w", err)
+	}
+	chain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, fmt.Errorf("finalize order: %w", err)
+	}
+	if len(chain) == 0 {
+		return nil, errors.New("finalize order: empty certificate chain")
+	}
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return nil, fmt.Errorf("parse issued certificate: %w", err)
+	}
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return nil, fmt.Errorf("marshal certificate key: %w", err)
+	}
+	var certPEM strings.Builder
+	for _, der := range chain {
+		_ = pem.Encode(&certPEM, &pem.Block{Type: "CERTIFICATE", Bytes: der})
+	}
+
+	cert := Certificate{
+		Domain:         domain,
+		Certificate:    certPEM.String(),
+		CertificateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER})),
+		NotAfter:       leaf.NotAfter.UTC(),
+		IssuedAt:       time.Now().UTC(),
+	}
+	if err := m.store(cert); err != nil {
+		return nil, err
+	}
+	log.Printf("[acme] issued certificate for %s (expires %s)", domain, cert.NotAfter.Format(time.RFC3339))
+	return &cert, nil
+}
+
+func (m *Manager) authorize(ctx context.Context, client *acme.Client, authzURL string) error {
+	authz, err := client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return fmt.Errorf("get authorization: %w", err)
+	}
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+
+	var challenge *acme.Challenge
+	for _, c := range authz.Challenges {
+		if c.Type == "http-01" {
+			challenge = c
+			break
+		}
+	}
+	if challenge == nil {
+		return fmt.Errorf("no http-01 challenge offered for %s", authz.Identifier.Value)
+	}
+
+	response, err := client.HTTP01ChallengeResponse(challenge.Token)
+	if err != nil {
+		return fmt.Errorf("build challenge response: %w", err)
+	}
+	m.mu.Lock()
+	m.tokens[challenge.Token] = response
+	m.mu.Unlock()
+	defer func() {
+		m.mu.Lock()
+		delete(m.tokens, challenge.Token)
+		m.mu.Unlock()
+	}()
+
+	if _, err := client.Accept(ctx, challenge); err != nil {
+		return fmt.Errorf("accept challenge for %s: %w", authz.Identifier.Value, err)
+	}
+	if _, err := client.WaitAuthorization(ctx, authz.URI); err != nil {
+		return fmt.Errorf("authorize %s: %w", authz.Identifier.Value, err)
+	}
+	return nil
+}
+
+func (m *Manager) acmeClient(ctx context.Context) (*acme.Client, error) {
+	if m.client != nil {
+		return m.client, nil
+	}
+
+	accountKey, err := m.loadOrCreateAccountKey()
+	if err != nil {
+		return nil, err
+	}
+	client := &acme.Client{
+		Key:          accountKey,
+		DirectoryURL: m.config.DirectoryURL,
+		UserAgent:    "techulus-agent",
+	}
+	if m.config.RootCAs != nil {
+		client.HTTPClient = &http.Client{
+			Timeout: 30 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: m.config.RootCAs},
+			},
+		}
+	}
+
+	account := &acme.Account{}
+	if m.config.Email != "" {
+		account.Contact = []string{"mailto:" + m.config.Email}
+	}
+	if _, err := client.Register(ctx, account, acme.AcceptTOS); err != nil && !errors.Is(err, acme.ErrAccountAlreadyExists) {
+		return nil, fmt.Errorf("register acme account: %w", err)
+	}
+	m.client = client
+	return client, nil
+}
+
+func (m *Manager) loadOrCreateAccountKey() (*ecdsa.PrivateKey, error) {
+	path := filepath.Join(m.root, accountKeyFileName)
+	if data, err := os.ReadFile(path); err == nil {
+		decrypted, err := crypto.DecryptSecret(string(data), m.key)
+		if err != nil {
+			return nil, fmt.Errorf("decrypt acme account key: %w", err)
+		}
+		block, _ := pem.Decode([]byte(decrypted))
+		if block == nil {
+			return nil, errors.New("decode acme account key: no PEM block")
+		}
+		return x509.ParseECPrivateKey(block.Bytes)
+	} else if !os.IsNotExist(err) {
+		return nil, fmt.Errorf("read acme account key: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate acme account key: %w", err)
+	}
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("marshal acme account key: %w", err)
+	}
+	encrypted, err := crypto.EncryptSecret(string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})), m.key)
+	if err != nil {
+		return nil, fmt.Errorf("encrypt acme account key: %w", err)
+	}
+	if err := writeFile(path, []byte(encrypted)); err != nil {
+		return nil, fmt.Errorf("write acme account key: %w", err)
+	}
+	return key, nil
+}
+
+func (m *Manager) store(cert Certificate) error {
+	data, err := json.Marshal(cert)
+	if err != nil {
+		return fmt.Errorf("marshal certificate: %w", err)
+	}
+	encrypted, err := crypto.EncryptSecret(string(data), m.key)
+	if err != nil {
+		return fmt.Errorf("encrypt certificate: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := writeFile(m.certificatePath(cert.Domain), []byte(encrypted)); err != nil {
+		return fmt.Errorf("write certificate for %s: %w", cert.Domain, err)
+	}
+	m.certs[cert.Domain] = cert
+	return nil
+}
+
+func (m *Manager) loadCertificates() error {
+	dir := filepath.Join(m.root, "certs")
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read acme certificates: %w", err)
+	}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("read acme certificate %s: %w", entry.Name(), err)
+		}
+		decrypted, err := crypto.DecryptSecret(string(data), m.key)
+		if err != nil {
+			log.Printf("[acme] skipping unreadable certificate %s: %v", entry.Name(), err)
+			continue
+		}
+		var cert Certificate
+		if err := json.Unmarshal([]byte(decrypted), &cert); err != nil || cert.Domain == "" {
+			log.Printf("[acme] skipping malformed certificate %s", entry.Name())
+			continue
+		}
+		m.certs[cert.Domain] = cert
+	}
+	return nil
+}
+
+func (m *Manager) certificatePath(domain string) string {
+	return filepath.Join(m.root, "certs", domain+".json")
+}
+
+func writeFile(path string, data []byte) error {
+	tmpPath := path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
+
+func CertificateNotAfter(certPEM string) (time.Time, error) {
+	block, _ := pem.Decode([]byte(certPEM))
+	if block == nil || block.Type != "CERTIFICATE" {
+		return time.Time{}, errors.New("no certificate PEM block")
+	}
+	leaf, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return leaf.NotAfter.UTC(), nil
+}
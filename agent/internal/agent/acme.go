@@ -0,0 +1,192 @@
+package agent
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"techulus/cloud-agent/internal/acme"
+	"techulus/cloud-agent/internal/crypto"
+	agenthttp "techulus/cloud-agent/internal/http"
+	"techulus/cloud-agent/internal/traefik"
+)
+
+const (
+	acmeFallbackInterval      = 1 * time.Hour
+	acmeFallbackOfflineAfter  = 30 * time.Minute
+	acmeFallbackRenewBefore   = 14 * 24 * time.Hour
+	acmeChallengeRouteTimeout = 15 * time.Second
+)
+
+func (a *Agent) ACMEFallbackLoop(ctx context.Context) {
+	ticker := time.NewTicker(acmeFallbackInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.runACMEFallback(ctx)
+		}
+	}
+}
+
+func (a *Agent) markControlPlaneSynced() {
+	a.lastControlPlaneSync.Store(time.Now().UnixNano())
+}
+
+func (a *Agent) controlPlaneOfflineFor() time.Duration {
+	last := a.lastControlPlaneSync.Load()
+	if last == 0 {
+		return time.Since(agentStartTime)
+	}
+	return time.Since(time.Unix(0, last))
+}
+
+func (a *Agent) runACMEFallback(ctx context.Context) {
+	expected := a.ExpectedState()
+	if a.ACME == nil || expected == nil {
+		return
+	}
+
+	offline := a.controlPlaneOfflineFor()
+	if offline < acmeFallbackOfflineAfter {
+		a.forgetAdoptedCertificates(expected)
+		return
+	}
+
+	domains := acmeRenewalCandidates(expected.Traefik.Certificates, a.ACME.Certificates(), time.Now().Add(acmeFallbackRenewBefore))
+	if len(domains) == 0 {
+		return
+	}
+
+	log.Printf("[acme] control plane unreachable for %s, renewing %d certificate(s) via %s", offline.Round(time.Minute), len(domains), a.ACME.DirectoryURL())
+	challengeURL := fmt.Sprintf("http://127.0.0.1:%d", acme.ChallengePort)
+	if err := traefik.WriteLocalChallengeRoute(challengeURL, acmeChallengeRouteTimeout); err != nil {
+		log.Printf("[acme] failed to route challenges locally: %v", err)
+		if err := traefik.RemoveLocalChallengeRoute(); err != nil {
+			log.Printf("[acme] %v", err)
+		}
+		return
+	}
+	defer func() {
+		if err := traefik.RemoveLocalChallengeRoute(); err != nil {
+			log.Printf("[acme] %v", err)
+		}
+	}()
+
+	issued := 0
+	for _, domain := range domains {
+		if _, err := a.ACME.Obtain(ctx, domain); err != nil {
+			log.Printf("[acme] failed to issue certificate for %s: %v", domain, err)
+			continue
+		}
+		issued++
+	}
+	if issued > 0 {
+		a.invalidateCompiledTraefikState()
+		a.RequestReconcile("certificates issued locally")
+	}
+}
+
+// forgetAdoptedCertificates drops locally issued certificates once the control
+// plane serves the same or a newer certificate for the domain, or no longer
+// serves the domain at all.
+func (a *Agent) forgetAdoptedCertificates(expected *agenthttp.ExpectedState) {
+	served := map[string]time.Time{}
+	for _, cert := range expected.Traefik.Certificates {
+		notAfter, _ := acme.CertificateNotAfter(cert.Certificate)
+		served[cert.Domain] = notAfter
+	}
+
+	changed := false
+	for _, cert := range a.ACME.Certificates() {
+		notAfter, ok := served[cert.Domain]
+		if ok && notAfter.Before(cert.NotAfter) {
+			continue
+		}
+		if err := a.ACME.Forget(cert.Domain); err != nil {
+			log.Printf("[acme] %v", err)
+			continue
+		}
+		log.Printf("[acme] dropping locally issued certificate for %s, control plane has taken over", cert.Domain)
+		changed = true
+	}
+	if changed {
+		a.invalidateCompiledTraefikState()
+	}
+}
+
+func acmeRenewalCandidates(expected []agenthttp.Certificate, issued []acme.Certificate, renewBy time.Time) []string {
+	issuedNotAfter := map[string]time.Time{}
+	for _, cert := range issued {
+		issuedNotAfter[cert.Domain] = cert.NotAfter
+	}
+
+	var domains []string
+	for _, cert := range expected {
+		notAfter, _ := acme.CertificateNotAfter(cert.Certificate)
+		if local, ok := issuedNotAfter[cert.Domain]; ok && local.After(notAfter) {
+			notAfter = local
+		}
+		if notAfter.Before(renewBy) {
+			domains = append(domains, cert.Domain)
+		}
+	}
+	return domains
+}
+
+func mergeIssuedCertificates(certificates []traefik.Certificate, issued []acme.Certificate) []traefik.Certificate {
+	if len(issued) == 0 {
+		return certificates
+	}
+
+	byDomain := map[string]acme.Certificate{}
+	for _, cert := range issued {
+		byDomain[cert.Domain] = cert
+	}
+	for i, cert := range certificates {
+		local, ok := byDomain[cert.Domain]
+		if !ok {
+			continue
+		}
+		notAfter, err := acme.CertificateNotAfter(cert.Certificate)
+		if err == nil && !local.NotAfter.After(notAfter) {
+			continue
+		}
+		certificates[i] = traefik.Certificate{
+			Domain:         local.Domain,
+			Certificate:    local.Certificate,
+			CertificateKey: local.CertificateKey,
+		}
+	}
+	return certificates
+}
+
+func (a *Agent) issuedCertificates() []acme.Certificate {
+	if a.ACME == nil {
+		return nil
+	}
+	return a.ACME.Certificates()
+}
+
+func (a *Agent) issuedCertificateReports() []agenthttp.IssuedCertificate {
+	var reports []agenthttp.IssuedCertificate
+	for _, cert := range a.issuedCertificates() {
+		encryptedKey, err := crypto.EncryptSecret(cert.CertificateKey, a.Config.EncryptionKey)
+		if err != nil {
+			log.Printf("[acme] failed to encrypt key for %s: %v", cert.Domain, err)
+			continue
+		}
+		reports = append(reports, agenthttp.IssuedCertificate{
+			Domain:                  cert.Domain,
+			Certificate:             cert.Certificate,
+			EncryptedCertificateKey: encryptedKey,
+			NotAfter:                cert.NotAfter.Format(time.RFC3339),
+			IssuedAt:                cert.IssuedAt.Format(time.RFC3339),
+		})
+	}
+	return reports
+}
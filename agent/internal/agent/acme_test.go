@@ -0,0 +1,60 @@
+package agent
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"techulus/cloud-agent/internal/acme"
+	agenthttp "techulus/cloud-agent/internal/http"
+	"techulus/cloud-agent/internal/traefik"
+)
+
+func testCertificatePEM(t *testing.T, domain string, notAfter time.Time) string {
+	t.Helper()
+	certPEM, _ := testKeyPairPEM(t, domain, notAfter)
+	return certPEM
+}
+
+func TestACMERenewalCandidates(t *testing.T) {
+	now := time.Now()
+	expected := []agenthttp.Certificate{
+		{Domain: "fresh.example.com", Certificate: testCertificatePEM(t, "fresh.example.com", now.Add(60*24*time.Hour))},
+		{Domain: "expiring.example.com", Certificate: testCertificatePEM(t, "expiring.example.com", now.Add(3*24*time.Hour))},
+		{Domain: "renewed.example.com", Certificate: testCertificatePEM(t, "renewed.example.com", now.Add(3*24*time.Hour))},
+		{Domain: "broken.example.com", Certificate: "not a certificate"},
+	}
+	issued := []acme.Certificate{{Domain: "renewed.example.com", NotAfter: now.Add(90 * 24 * time.Hour)}}
+
+	got := acmeRenewalCandidates(expected, issued, now.Add(acmeFallbackRenewBefore))
+	want := []string{"expiring.example.com", "broken.example.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("candidates = %v, want %v", got, want)
+	}
+}
+
+func TestMergeIssuedCertificatesPrefersNewerCertificate(t *testing.T) {
+	now := time.Now()
+	controlPlaneCert := testCertificatePEM(t, "app.example.com", now.Add(3*24*time.Hour))
+	newerCert := testCertificatePEM(t, "api.example.com", now.Add(80*24*time.Hour))
+	certificates := []traefik.Certificate{
+		{Domain: "app.example.com", Certificate: controlPlaneCert, CertificateKey: "cp-key"},
+		{Domain: "api.example.com", Certificate: newerCert, CertificateKey: "cp-key"},
+	}
+	issued := []acme.Certificate{
+		{Domain: "app.example.com", Certificate: "local-cert", CertificateKey: "local-key", NotAfter: now.Add(90 * 24 * time.Hour)},
+		{Domain: "api.example.com", Certificate: "stale-cert", CertificateKey: "stale-key", NotAfter: now.Add(10 * 24 * time.Hour)},
+		{Domain: "removed.example.com", Certificate: "orphan", CertificateKey: "orphan", NotAfter: now.Add(90 * 24 * time.Hour)},
+	}
+
+	merged := mergeIssuedCertificates(certificates, issued)
+	if len(merged) != 2 {
+		t.Fatalf("merged = %+v", merged)
+	}
+	if merged[0].Certificate != "local-cert" || merged[0].CertificateKey != "local-key" {
+		t.Fatalf("app certificate = %+v, want locally issued", merged[0])
+	}
+	if merged[1].Certificate != newerCert {
+		t.Fatalf("api certificate replaced by older local certificate")
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"techulus/cloud-agent/internal/acme"
 	"techulus/cloud-agent/internal/build"
 	"techulus/cloud-agent/internal/container"
 	"techulus/cloud-agent/internal/health"
@@ -13,6 +14,8 @@ import (
 	"techulus/cloud-agent/internal/reconcile"
 	"techulus/cloud-agent/internal/registryauth"
 	"techulus/cloud-agent/internal/routeowners"
+	"techulus/cloud-agent/internal/serverless"
+	"techulus/cloud-agent/internal/traefik"
 )
 
 const (
@@ -45,6 +48,7 @@ type ActualState struct {
 	CertificatesHash      string
 	TraefikReloaded       bool
 	ChallengeRouteWritten bool
+	TrustedIPs            []string
 	WireguardHash         string
 }
 
@@ -58,6 +62,8 @@ type Agent struct {
 	pendingExpectedStateRefresh  bool
 	workMutex                    sync.Mutex
 	activeWorkItem               *agenthttp.WorkQueueItem
+	activeBuildItems             map[string]agenthttp.WorkQueueItem
+	activeRunItems               map[string]agenthttp.WorkQueueItem
 	pendingWorkResults           []agenthttp.CompletedWorkItem
 	deploymentErrorMutex         sync.Mutex
 	pendingDeploymentErrors      []agenthttp.DeploymentError
@@ -87,14 +93,15 @@ type Agent struct {
 	RouteOwners                  *routeowners.Registry
 	Builder                      *build.Builder
 	RegistryAuth                 *registryauth.Manager
-	isBuilding                   bool
-	buildMutex                   sync.Mutex
-	currentBuildID               string
+	ACME                         *acme.Manager
 	IsProxy                      bool
 	serverlessGatewayRunning     atomic.Bool
+	serverlessGateway            atomic.Pointer[serverless.Gateway]
 	crowdSecHealth               atomic.Pointer[health.CrowdSecHealth]
 	crowdSecHealthCollecting     atomic.Bool
 	DisableDNS                   bool
+	ServerlessCheckpoint         bool
+	lastControlPlaneSync         atomic.Int64
 }
 
 func NewAgent(
@@ -108,8 +115,10 @@ func NewAgent(
 	routeOwners *routeowners.Registry,
 	builder *build.Builder,
 	registryAuth *registryauth.Manager,
+	acmeManager *acme.Manager,
 	isProxy bool,
 	disableDNS bool,
+	serverlessCheckpoint bool,
 ) *Agent {
 	return &Agent{
 		state:                  StateIdle,
@@ -128,8 +137,10 @@ func NewAgent(
 		RouteOwners:            routeOwners,
 		Builder:                builder,
 		RegistryAuth:           registryAuth,
+		ACME:                   acmeManager,
 		IsProxy:                isProxy,
 		DisableDNS:             disableDNS,
+		ServerlessCheckpoint:   serverlessCheckpoint,
 		deploymentDeployLocks:  map[string]*sync.Mutex{},
 		pendingServerlessSleep: map[string]serverlessTransitionGuard{},
 		pendingServerlessWake:  map[string]serverlessTransitionGuard{},
@@ -141,6 +152,8 @@ type MetricsSender interface {
 	SendAgentStats(stats *health.AgentProcessStats, collectedAt time.Time) error
 	SendContainerStats(stats []container.ResourceStats, collectedAt time.Time) error
 	SendPrometheusMetrics(data []byte, extraLabels map[string]string) error
+	SendCertificateExpiry(certs []traefik.CertificateInfo, collectedAt time.Time) error
+	SendServerlessStats(stats []serverless.ServiceStats, collectedAt time.Time) error
 }
 
 func (a *Agent) GetState() AgentState {
@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"log"
+	"time"
+
+	agenthttp "techulus/cloud-agent/internal/http"
+	"techulus/cloud-agent/internal/traefik"
+)
+
+const certificateExpiryWarning = 21 * 24 * time.Hour
+
+const (
+	certificateIssueExpiring       = "expiring"
+	certificateIssueExpired        = "expired"
+	certificateIssueDomainMismatch = "domain_mismatch"
+	certificateIssueKeyMismatch    = "key_mismatch"
+	certificateIssueInvalid        = "invalid"
+)
+
+// inspectCertificates drops certificates that cannot be served (unparseable or
+// with a mismatched key) so the installed set converges instead of failing every
+// Traefik update, and records why each one was rejected.
+func inspectCertificates(certs []traefik.Certificate) ([]traefik.Certificate, []traefik.CertificateInfo, []agenthttp.CertificateIssue) {
+	installable := make([]traefik.Certificate, 0, len(certs))
+	var infos []traefik.CertificateInfo
+	var rejected []agenthttp.CertificateIssue
+	for _, cert := range certs {
+		info, err := traefik.InspectCertificate(cert)
+		if err != nil {
+			rejected = append(rejected, agenthttp.CertificateIssue{
+				Domain: cert.Domain,
+				Issue:  certificateIssueInvalid,
+				Error:  err.Error(),
+			})
+			continue
+		}
+		if err := traefik.VerifyCertificateKey(cert); err != nil {
+			rejected = append(rejected, certificateIssue(*info, certificateIssueKeyMismatch, err.Error()))
+			continue
+		}
+		installable = append(installable, cert)
+		infos = append(infos, *info)
+	}
+	for _, issue := range rejected {
+		log.Printf("[traefik] not installing certificate for %s: %s", issue.Domain, issue.Error)
+	}
+	return installable, infos, rejected
+}
+
+func certificateIssues(compiled *compiledTraefikState, now time.Time) []agenthttp.CertificateIssue {
+	issues := append([]agenthttp.CertificateIssue{}, compiled.RejectedCertificates...)
+	for _, info := range compiled.CertificateInfos {
+		switch {
+		case !info.NotAfter.After(now):
+			issues = append(issues, certificateIssue(info, certificateIssueExpired, ""))
+		case info.NotAfter.Before(now.Add(certificateExpiryWarning)):
+			issues = append(issues, certificateIssue(info, certificateIssueExpiring, ""))
+		}
+		if !info.MatchesDomain {
+			issues = append(issues, certificateIssue(info, certificateIssueDomainMismatch, ""))
+		}
+	}
+	return issues
+}
+
+func certificateIssue(info traefik.CertificateInfo, issue, message string) agenthttp.CertificateIssue {
+	return agenthttp.CertificateIssue{
+		Domain:   info.Domain,
+		Issue:    issue,
+		NotAfter: info.NotAfter.Format(time.RFC3339),
+		SANs:     info.SANs,
+		Issuer:   info.Issuer,
+		Error:    message,
+	}
+}
+
+func (a *Agent) certificateReport() []agenthttp.CertificateIssue {
+	expected := a.ExpectedState()
+	if expected == nil {
+		return nil
+	}
+	return certificateIssues(a.compiledTraefikState(expected), time.Now())
+}
+
+func (a *Agent) sendCertificateMetrics(collectedAt time.Time) {
+	expected := a.ExpectedState()
+	if a.MetricsSender == nil || expected == nil {
+		return
+	}
+	infos := a.compiledTraefikState(expected).CertificateInfos
+	if err := a.MetricsSender.SendCertificateExpiry(infos, collectedAt); err != nil {
+		log.Printf("[metrics] failed to send certificate expiry: %v", err)
+	}
+}
@@ -0,0 +1,100 @@
+package agent
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	agenthttp "techulus/cloud-agent/internal/http"
+)
+
+func testKeyPairPEM(t *testing.T, domain string, notAfter time.Time) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: domain},
+		DNSNames:     []string{domain},
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestCompileTraefikStateReportsCertificateIssues(t *testing.T) {
+	now := time.Now()
+	healthyCert, healthyKey := testKeyPairPEM(t, "healthy.example.com", now.Add(60*24*time.Hour))
+	expiringCert, expiringKey := testKeyPairPEM(t, "expiring.example.com", now.Add(5*24*time.Hour))
+	mismatchCert, mismatchKey := testKeyPairPEM(t, "other.example.com", now.Add(60*24*time.Hour))
+	_, wrongKey := testKeyPairPEM(t, "wrong-key.example.com", now.Add(60*24*time.Hour))
+
+	expected := &agenthttp.ExpectedState{}
+	expected.Traefik.Certificates = []agenthttp.Certificate{
+		{Domain: "healthy.example.com", Certificate: healthyCert, CertificateKey: healthyKey},
+		{Domain: "expiring.example.com", Certificate: expiringCert, CertificateKey: expiringKey},
+		{Domain: "mismatch.example.com", Certificate: mismatchCert, CertificateKey: mismatchKey},
+		{Domain: "wrong-key.example.com", Certificate: healthyCert, CertificateKey: wrongKey},
+		{Domain: "invalid.example.com", Certificate: "garbage", CertificateKey: "garbage"},
+	}
+
+	compiled := compileTraefikState(expected, nil)
+	if len(compiled.Certificates) != 3 {
+		t.Fatalf("installable certificates = %d, want 3", len(compiled.Certificates))
+	}
+	for _, cert := range compiled.Certificates {
+		if cert.Domain == "wrong-key.example.com" || cert.Domain == "invalid.example.com" {
+			t.Fatalf("unusable certificate %s was kept", cert.Domain)
+		}
+	}
+
+	got := map[string]string{}
+	for _, issue := range certificateIssues(compiled, now) {
+		got[issue.Domain] = issue.Issue
+	}
+	want := map[string]string{
+		"expiring.example.com":  certificateIssueExpiring,
+		"mismatch.example.com":  certificateIssueDomainMismatch,
+		"wrong-key.example.com": certificateIssueKeyMismatch,
+		"invalid.example.com":   certificateIssueInvalid,
+	}
+	if len(got) != len(want) {
+		t.Fatalf("issues = %v, want %v", got, want)
+	}
+	for domain, issue := range want {
+		if got[domain] != issue {
+			t.Fatalf("issue for %s = %q, want %q (all: %v)", domain, got[domain], issue, got)
+		}
+	}
+}
+
+func TestCertificateIssuesReportsHealthyProxyAsEmpty(t *testing.T) {
+	now := time.Now()
+	cert, key := testKeyPairPEM(t, "healthy.example.com", now.Add(60*24*time.Hour))
+	expected := &agenthttp.ExpectedState{}
+	expected.Traefik.Certificates = []agenthttp.Certificate{
+		{Domain: "healthy.example.com", Certificate: cert, CertificateKey: key},
+	}
+
+	issues := certificateIssues(compileTraefikState(expected, nil), now)
+	if issues == nil || len(issues) != 0 {
+		t.Fatalf("issues = %#v, want an empty list", issues)
+	}
+}
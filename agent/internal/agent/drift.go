@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"slices"
 	"time"
 
 	"techulus/cloud-agent/internal/container"
@@ -126,6 +127,8 @@ func (a *Agent) handleIdle() {
 
 	if fromCache {
 		log.Printf("[idle] using cached state (CP unreachable)")
+	} else {
+		a.markControlPlaneSynced()
 	}
 	a.SetLatestExpectedState(expected)
 	a.ReconcilePendingServerlessTransitionsWithExpected(expected, fromCache)
@@ -135,6 +138,7 @@ func (a *Agent) handleIdle() {
 		log.Printf("[idle] failed to get actual state: %v", err)
 		return
 	}
+	a.CleanupOrphanedTasks()
 
 	actions := a.planReconcile(expected, actual)
 	if len(actions) > 0 {
@@ -214,6 +218,7 @@ func (a *Agent) getActualState() (*ActualState, error) {
 			log.Printf("[traefik] failed to determine dynamic config reload state: %v", err)
 		}
 		state.ChallengeRouteWritten = traefik.ChallengeRouteExists()
+		state.TrustedIPs = traefik.CurrentTrustedIPs()
 	}
 	return state, nil
 }
@@ -356,6 +361,7 @@ func (a *Agent) planReconcile(expected *agenthttp.ExpectedState, actual *ActualS
 		if compiled.CompileErr != nil ||
 			compiled.RoutesHash != actual.TraefikConfigHash ||
 			compiled.CertHash != actual.CertificatesHash ||
+			!slices.Equal(compiled.TrustedIPs, actual.TrustedIPs) ||
 			!actual.TraefikReloaded {
 			actions = append(actions, reconcileAction{
 				Kind: actionUpdateTraefik,
@@ -558,6 +564,12 @@ func (a *Agent) updateTraefik() error {
 	}
 	needsRestart = metricsRestart
 
+	forwardedHeadersRestart, err := traefik.EnsureForwardedHeaders(compiled.TrustedIPs)
+	if err != nil {
+		return fmt.Errorf("failed to ensure Traefik trusted proxies: %w", err)
+	}
+	needsRestart = needsRestart || forwardedHeadersRestart
+
 	if len(compiled.TCPPorts) > 0 || len(compiled.UDPPorts) > 0 {
 		log.Printf("[reconcile] ensuring L4 entry points: %d TCP, %d UDP", len(compiled.TCPPorts), len(compiled.UDPPorts))
 		entryPointsRestart, err := traefik.EnsureEntryPoints(compiled.TCPPorts, compiled.UDPPorts)
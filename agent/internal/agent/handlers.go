@@ -5,10 +5,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"runtime"
+	"sort"
 	"time"
 	"unicode/utf8"
 
@@ -16,6 +19,7 @@ import (
 	"techulus/cloud-agent/internal/container"
 	"techulus/cloud-agent/internal/crypto"
 	agenthttp "techulus/cloud-agent/internal/http"
+	"techulus/cloud-agent/internal/logs"
 	"techulus/cloud-agent/internal/paths"
 	"techulus/cloud-agent/internal/registryauth"
 )
@@ -37,6 +41,78 @@ func (a *Agent) ProcessCommand(item agenthttp.WorkQueueItem) (container.CommandR
 	return container.ExecCommand(payload.ContainerID, payload.ServiceID, payload.DeploymentID, payload.Command)
 }
 
+const maxRunTimeout = time.Hour
+
+func (a *Agent) ProcessRun(item agenthttp.WorkQueueItem) (container.TaskResult, error) {
+	var payload struct {
+		RunID          string                      `json:"runId"`
+		ServiceID      string                      `json:"serviceId"`
+		DeploymentID   string                      `json:"deploymentId"`
+		RolloutID      string                      `json:"rolloutId"`
+		Command        string                      `json:"command"`
+		TimeoutSeconds int                         `json:"timeoutSeconds"`
+		Container      agenthttp.ExpectedContainer `json:"container"`
+	}
+	if err := json.Unmarshal([]byte(item.Payload), &payload); err != nil {
+		return container.TaskResult{}, fmt.Errorf("failed to parse run payload: %w", err)
+	}
+	timeout := time.Duration(payload.TimeoutSeconds) * time.Second
+	if payload.RunID != item.ID || payload.ServiceID == "" || (payload.DeploymentID == "" && payload.RolloutID == "") || payload.Command == "" || utf8.RuneCountInString(payload.Command) > 4096 ||
+		timeout <= 0 || timeout > maxRunTimeout || payload.Container.ServiceID != payload.ServiceID || payload.Container.Name == "" || payload.Container.Image == "" {
+		return container.TaskResult{}, fmt.Errorf("invalid run payload")
+	}
+	if a.Reconciler == nil {
+		return container.TaskResult{}, fmt.Errorf("reconciler not configured")
+	}
+
+	info := logs.ContainerInfo{DeploymentID: payload.RunID, ServiceID: payload.ServiceID, ContainerID: payload.Container.Name}
+	if payload.RolloutID != "" {
+		log.Printf("[run] running release task %s for service %s in rollout %s", Truncate(payload.RunID, 8), Truncate(payload.ServiceID, 8), Truncate(payload.RolloutID, 8))
+		info = logs.ContainerInfo{RolloutID: payload.RolloutID, ServiceID: payload.ServiceID, ContainerID: payload.Container.Name}
+	} else {
+		log.Printf("[run] running task %s for service %s from deployment %s", Truncate(payload.RunID, 8), Truncate(payload.ServiceID, 8), Truncate(payload.DeploymentID, 8))
+	}
+	result, err := a.Reconciler.RunTask(payload.RunID, payload.Container, payload.Command, timeout, func(entry container.LogEntry) {
+		if a.LogCollector != nil {
+			a.LogCollector.Record(info, entry)
+		}
+	})
+	if a.LogCollector != nil {
+		if flushErr := a.LogCollector.Flush(); flushErr != nil {
+			log.Printf("[run] failed to flush logs for task %s: %v", Truncate(payload.RunID, 8), flushErr)
+		}
+	}
+	return result, err
+}
+
+// CleanupOrphanedTasks removes task containers whose run is no longer active
+// here, such as those left running when the agent restarted.
+func (a *Agent) CleanupOrphanedTasks() {
+	tasks, err := container.ListTasks()
+	if err != nil {
+		log.Printf("[run] task cleanup skipped: %v", err)
+		return
+	}
+	for _, containerID := range a.orphanedTasks(tasks) {
+		log.Printf("[run] removing task container %s with no active run", Truncate(containerID, 12))
+		container.RemoveTask(containerID)
+	}
+}
+
+func (a *Agent) orphanedTasks(tasks map[string]string) []string {
+	a.workMutex.Lock()
+	defer a.workMutex.Unlock()
+
+	var orphaned []string
+	for containerID, runID := range tasks {
+		if _, ok := a.activeRunItems[runID]; !ok {
+			orphaned = append(orphaned, containerID)
+		}
+	}
+	sort.Strings(orphaned)
+	return orphaned
+}
+
 func (a *Agent) ProcessRestart(item agenthttp.WorkQueueItem) error {
 	var payload struct {
 		DeploymentID string `json:"deploymentId"`
@@ -127,6 +203,8 @@ func (a *Agent) ProcessCleanupVolumes(item agenthttp.WorkQueueItem) error {
 	return nil
 }
 
+const defaultBuildTimeoutMinutes = 30
+
 func (a *Agent) ProcessBuild(item agenthttp.WorkQueueItem) error {
 	if a.Builder == nil {
 		return fmt.Errorf("builder not configured")
@@ -140,21 +218,26 @@ func (a *Agent) ProcessBuild(item agenthttp.WorkQueueItem) error {
 		return fmt.Errorf("failed to parse build payload: %w", err)
 	}
 
-	a.buildMutex.Lock()
-	if a.isBuilding {
-		a.buildMutex.Unlock()
-		return fmt.Errorf("another build is in progress")
+	checkCancelled := func() bool {
+		status, err := a.Client.GetBuildStatus(payload.BuildID)
+		if err != nil {
+			return false
+		}
+		return status == "cancelled"
 	}
-	a.isBuilding = true
-	a.currentBuildID = payload.BuildID
-	a.buildMutex.Unlock()
 
-	defer func() {
-		a.buildMutex.Lock()
-		a.isBuilding = false
-		a.currentBuildID = ""
-		a.buildMutex.Unlock()
-	}()
+	releaseSlot, ok := a.Builder.TryAcquireSlot()
+	if !ok {
+		log.Printf("[build] build %s queued until a build slot is free", Truncate(payload.BuildID, 8))
+		queueCtx, cancelQueue := context.WithTimeout(context.Background(), defaultBuildTimeoutMinutes*time.Minute)
+		var err error
+		releaseSlot, err = a.Builder.AcquireQueuedSlot(queueCtx, checkCancelled)
+		cancelQueue()
+		if err != nil {
+			return fmt.Errorf("build %s left the queue: %w", Truncate(payload.BuildID, 8), err)
+		}
+	}
+	defer releaseSlot()
 
 	snapshot, releaseRegistryAuth, err := a.RegistryAuth.Acquire()
 	if err != nil {
@@ -168,22 +251,14 @@ func (a *Agent) ProcessBuild(item agenthttp.WorkQueueItem) error {
 
 	timeoutMinutes := buildDetails.TimeoutMinutes
 	if timeoutMinutes <= 0 {
-		timeoutMinutes = 30
+		timeoutMinutes = defaultBuildTimeoutMinutes
 	}
 	log.Printf("[build] starting build %s for commit %s (timeout: %d minutes)", Truncate(payload.BuildID, 8), Truncate(buildDetails.Build.CommitSha, 8), timeoutMinutes)
 
-	if err := a.Client.UpdateBuildStatus(payload.BuildID, "cloning", "", "", ""); err != nil {
+	if err := a.Client.UpdateBuildStatus(payload.BuildID, "cloning", "", "", "", nil, nil, nil); err != nil {
 		log.Printf("[build] failed to update status to cloning: %v", err)
 	}
 
-	checkCancelled := func() bool {
-		status, err := a.Client.GetBuildStatus(payload.BuildID)
-		if err != nil {
-			return false
-		}
-		return status == "cancelled"
-	}
-
 	decryptedSecrets := make(map[string]string)
 	for key, encryptedValue := range buildDetails.Secrets {
 		decrypted, err := crypto.DecryptSecret(encryptedValue, a.Config.EncryptionKey)
@@ -194,9 +269,30 @@ func (a *Agent) ProcessBuild(item agenthttp.WorkQueueItem) error {
 		decryptedSecrets[key] = decrypted
 	}
 
+	var gitAuth *build.GitAuth
+	if buildDetails.GitAuth != nil {
+		secret, err := crypto.DecryptSecret(buildDetails.GitAuth.EncryptedSecret, a.Config.EncryptionKey)
+		if err != nil {
+			err = fmt.Errorf("failed to decrypt git credentials: %w", err)
+			if updateErr := a.Client.UpdateBuildStatus(payload.BuildID, "failed", err.Error(), "", "", nil, nil, nil); updateErr != nil {
+				log.Printf("[build] failed to update status to failed: %v", updateErr)
+			}
+			return err
+		}
+		gitAuth = &build.GitAuth{
+			Type:       buildDetails.GitAuth.Type,
+			Username:   buildDetails.GitAuth.Username,
+			Secret:     secret,
+			KnownHosts: buildDetails.GitAuth.KnownHosts,
+		}
+	}
+
 	buildConfig := &build.Config{
 		BuildID:         payload.BuildID,
 		CloneURL:        buildDetails.CloneURL,
+		GitAuth:         gitAuth,
+		Submodules:      buildDetails.Submodules,
+		LFS:             buildDetails.LFS,
 		CommitSha:       buildDetails.Build.CommitSha,
 		Branch:          buildDetails.Build.Branch,
 		GitRef:          buildDetails.Build.GitRef,
@@ -205,14 +301,24 @@ func (a *Agent) ProcessBuild(item agenthttp.WorkQueueItem) error {
 		ServiceID:       buildDetails.Build.ServiceID,
 		ProjectID:       buildDetails.Build.ProjectID,
 		RootDir:         buildDetails.RootDir,
+		DockerfilePath:  buildDetails.Dockerfile,
+		Target:          buildDetails.Target,
+		BuildArgs:       buildDetails.BuildArgs,
 		Secrets:         decryptedSecrets,
 		TargetPlatforms: buildDetails.TargetPlatforms,
 		DockerConfigDir: snapshot.DockerConfigDir,
+		EmptyHome:       snapshot.EmptyHome,
 		TargetTLSVerify: snapshot.TLSVerify(buildDetails.ImageURI),
 	}
+	if buildDetails.ContextURL != "" {
+		buildConfig.ContextChecksum = buildDetails.ContextChecksum
+		buildConfig.OpenContext = func(ctx context.Context) (io.ReadCloser, error) {
+			return a.Client.DownloadBuildContext(ctx, buildDetails.ContextURL)
+		}
+	}
 
 	onStatusChange := func(status string) {
-		if err := a.Client.UpdateBuildStatus(payload.BuildID, status, "", buildConfig.ResolvedCommitSha, ""); err != nil {
+		if err := a.Client.UpdateBuildStatus(payload.BuildID, status, "", buildConfig.ResolvedCommitSha, "", nil, nil, nil); err != nil {
 			log.Printf("[build] failed to update status to %s: %v", status, err)
 		}
 	}
@@ -222,20 +328,58 @@ func (a *Agent) ProcessBuild(item agenthttp.WorkQueueItem) error {
 	artifact, err := a.Builder.Build(ctx, buildConfig, checkCancelled, onStatusChange)
 	if err != nil {
 		log.Printf("[build] build %s failed: %v", Truncate(payload.BuildID, 8), err)
-		if updateErr := a.Client.UpdateBuildStatus(payload.BuildID, "failed", err.Error(), buildConfig.ResolvedCommitSha, ""); updateErr != nil {
+		if updateErr := a.Client.UpdateBuildStatus(payload.BuildID, "failed", err.Error(), buildConfig.ResolvedCommitSha, "", buildCacheStats(buildConfig), nil, nil); updateErr != nil {
 			log.Printf("[build] failed to update status to failed: %v", updateErr)
 		}
 		return err
 	}
 
 	log.Printf("[build] build %s completed successfully", Truncate(payload.BuildID, 8))
-	if err := a.Client.UpdateBuildStatus(payload.BuildID, "completed", "", buildConfig.ResolvedCommitSha, artifact); err != nil {
+	if err := a.Client.UpdateBuildStatus(payload.BuildID, "completed", "", buildConfig.ResolvedCommitSha, artifact, buildCacheStats(buildConfig), buildAttestations(buildConfig), buildVulnerabilities(buildConfig)); err != nil {
 		log.Printf("[build] failed to update status to completed: %v", err)
 	}
 
 	return nil
 }
 
+func buildAttestations(config *build.Config) []agenthttp.BuildAttestation {
+	attestations := make([]agenthttp.BuildAttestation, 0, len(config.Attestations))
+	for _, attestation := range config.Attestations {
+		attestations = append(attestations, agenthttp.BuildAttestation{
+			Platform:         attestation.Platform,
+			ImageDigest:      attestation.ImageDigest,
+			ManifestDigest:   attestation.ManifestDigest,
+			SBOMDigest:       attestation.SBOMDigest,
+			ProvenanceDigest: attestation.ProvenanceDigest,
+		})
+	}
+	return attestations
+}
+
+func buildVulnerabilities(config *build.Config) *agenthttp.BuildVulnerabilities {
+	if config.Vulnerabilities == nil {
+		return nil
+	}
+	return vulnerabilityReport(*config.Vulnerabilities)
+}
+
+func vulnerabilityReport(counts build.VulnerabilityCounts) *agenthttp.BuildVulnerabilities {
+	return &agenthttp.BuildVulnerabilities{
+		Critical: counts.Critical,
+		High:     counts.High,
+		Medium:   counts.Medium,
+		Low:      counts.Low,
+		Unknown:  counts.Unknown,
+	}
+}
+
+func buildCacheStats(config *build.Config) *agenthttp.BuildCacheStats {
+	if config.CacheStats == nil {
+		return nil
+	}
+	return &agenthttp.BuildCacheStats{Hits: config.CacheStats.Hits, Steps: config.CacheStats.Steps}
+}
+
 func (a *Agent) RunBuildCleanup() {
 	if a.Builder == nil {
 		return
@@ -299,6 +443,40 @@ func (a *Agent) ProcessCreateManifest(item agenthttp.WorkQueueItem) error {
 	return nil
 }
 
+const imageScanTimeout = 10 * time.Minute
+
+// ProcessScanImage scans a pulled service image for this server's platform so
+// the control plane can hold a rollout over its vulnerability threshold.
+func (a *Agent) ProcessScanImage(item agenthttp.WorkQueueItem) (build.VulnerabilityCounts, error) {
+	var payload struct {
+		RolloutID string `json:"rolloutId"`
+		Image     string `json:"image"`
+	}
+	if err := json.Unmarshal([]byte(item.Payload), &payload); err != nil || payload.RolloutID == "" || payload.Image == "" {
+		return build.VulnerabilityCounts{}, fmt.Errorf("invalid scan_image payload")
+	}
+	if a.Builder == nil {
+		return build.VulnerabilityCounts{}, fmt.Errorf("builder not configured")
+	}
+
+	snapshot, releaseRegistryAuth, err := a.RegistryAuth.Acquire()
+	if err != nil {
+		return build.VulnerabilityCounts{}, err
+	}
+	defer releaseRegistryAuth()
+	platform := "linux/" + runtime.GOARCH
+	log.Printf("[scan_image] scanning %s (%s) for rollout %s", payload.Image, platform, Truncate(payload.RolloutID, 8))
+	ctx, cancel := context.WithTimeout(context.Background(), imageScanTimeout)
+	defer cancel()
+	return a.Builder.ScanImage(ctx, build.ScanTarget{
+		Reference:       payload.Image,
+		Platform:        platform,
+		DockerConfigDir: snapshot.DockerConfigDir,
+		EmptyHome:       snapshot.EmptyHome,
+		TLSVerify:       snapshot.TLSVerify(payload.Image),
+	})
+}
+
 func (a *Agent) ProcessSyncRegistries(item agenthttp.WorkQueueItem) error {
 	var payload struct {
 		Version string `json:"version"`
@@ -24,3 +24,25 @@ func TestProcessCommandRequiresOwnershipIdentifiers(t *testing.T) {
 		})
 	}
 }
+
+func TestProcessRunRejectsInvalidPayload(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+	}{
+		{"mismatched run ID", `{"runId":"other","serviceId":"service","deploymentId":"deployment","command":"true","timeoutSeconds":60,"container":{"serviceId":"service","name":"run","image":"nginx"}}`},
+		{"missing timeout", `{"runId":"run","serviceId":"service","deploymentId":"deployment","command":"true","container":{"serviceId":"service","name":"run","image":"nginx"}}`},
+		{"timeout over an hour", `{"runId":"run","serviceId":"service","deploymentId":"deployment","command":"true","timeoutSeconds":3601,"container":{"serviceId":"service","name":"run","image":"nginx"}}`},
+		{"foreign container", `{"runId":"run","serviceId":"service","deploymentId":"deployment","command":"true","timeoutSeconds":60,"container":{"serviceId":"other","name":"run","image":"nginx"}}`},
+		{"missing image", `{"runId":"run","serviceId":"service","deploymentId":"deployment","command":"true","timeoutSeconds":60,"container":{"serviceId":"service","name":"run"}}`},
+		{"neither deployment nor rollout", `{"runId":"run","serviceId":"service","command":"true","timeoutSeconds":60,"container":{"serviceId":"service","name":"run","image":"nginx"}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := (&Agent{}).ProcessRun(agenthttp.WorkQueueItem{ID: "run", Payload: tt.payload})
+			if err == nil || err.Error() != "invalid run payload" {
+				t.Fatalf("expected invalid payload for %s, got %v", tt.name, err)
+			}
+		})
+	}
+}
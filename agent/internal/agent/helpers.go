@@ -1,6 +1,7 @@
 package agent
 
 import (
+	"techulus/cloud-agent/internal/acme"
 	agenthttp "techulus/cloud-agent/internal/http"
 	"techulus/cloud-agent/internal/traefik"
 )
@@ -19,7 +20,7 @@ func ConvertToHttpRoutes(routes []agenthttp.TraefikRoute) []traefik.TraefikRoute
 		for j, u := range r.Upstreams {
 			upstreams[j] = traefik.Upstream{URL: u.Url, Weight: u.Weight}
 		}
-		httpRoutes[i] = traefik.TraefikRoute{ID: r.ID, Domain: r.Domain, Upstreams: upstreams, ServiceId: r.ServiceId}
+		httpRoutes[i] = traefik.TraefikRoute{ID: r.ID, Domain: r.Domain, Upstreams: upstreams, ServiceId: r.ServiceId, ClientAuth: convertClientAuth(r.ClientAuth)}
 	}
 	return httpRoutes
 }
@@ -33,31 +34,46 @@ func ConvertToTCPRoutes(routes []agenthttp.TraefikTCPRoute) []traefik.TraefikTCP
 			Upstreams:      r.Upstreams,
 			ExternalPort:   r.ExternalPort,
 			TLSPassthrough: r.TLSPassthrough,
+			TLSTermination: r.TLSTermination,
+			Domain:         r.Domain,
+			ClientAuth:     convertClientAuth(r.ClientAuth),
+			ProxyProtocol:  r.ProxyProtocol,
 		}
 	}
 	return tcpRoutes
 }
 
+func convertClientAuth(auth *agenthttp.ClientAuth) *traefik.ClientAuth {
+	if auth == nil {
+		return nil
+	}
+	return &traefik.ClientAuth{CABundle: auth.CABundle, Mode: auth.Mode}
+}
+
 func ConvertToUDPRoutes(routes []agenthttp.TraefikUDPRoute) []traefik.TraefikUDPRoute {
 	udpRoutes := make([]traefik.TraefikUDPRoute, len(routes))
 	for i, r := range routes {
 		udpRoutes[i] = traefik.TraefikUDPRoute{
-			ID:           r.ID,
-			ServiceId:    r.ServiceId,
-			Upstreams:    r.Upstreams,
-			ExternalPort: r.ExternalPort,
+			ID:            r.ID,
+			ServiceId:     r.ServiceId,
+			Upstreams:     r.Upstreams,
+			ExternalPort:  r.ExternalPort,
+			ProxyProtocol: r.ProxyProtocol,
 		}
 	}
 	return udpRoutes
 }
 
 type compiledTraefikState struct {
-	HTTP         []traefik.TraefikRoute
-	TCP          []traefik.TraefikTCPRoute
-	UDP          []traefik.TraefikUDPRoute
-	Certificates []traefik.Certificate
-	TCPPorts     []int
-	UDPPorts     []int
+	HTTP                 []traefik.TraefikRoute
+	TCP                  []traefik.TraefikTCPRoute
+	UDP                  []traefik.TraefikUDPRoute
+	Certificates         []traefik.Certificate
+	CertificateInfos     []traefik.CertificateInfo
+	RejectedCertificates []agenthttp.CertificateIssue
+	TCPPorts             []int
+	UDPPorts             []int
+	TrustedIPs           []string
 
 	Routes     *traefik.RoutesConfig
 	RoutesHash string
@@ -65,7 +81,7 @@ type compiledTraefikState struct {
 	CertHash   string
 }
 
-func compileTraefikState(expected *agenthttp.ExpectedState) *compiledTraefikState {
+func compileTraefikState(expected *agenthttp.ExpectedState, issued []acme.Certificate) *compiledTraefikState {
 	httpRoutes := ConvertToHttpRoutes(expected.Traefik.HttpRoutes)
 	tcpRoutes := ConvertToTCPRoutes(expected.Traefik.TCPRoutes)
 	udpRoutes := ConvertToUDPRoutes(expected.Traefik.UDPRoutes)
@@ -78,6 +94,8 @@ func compileTraefikState(expected *agenthttp.ExpectedState) *compiledTraefikStat
 			CertificateKey: c.CertificateKey,
 		}
 	}
+	certificates = mergeIssuedCertificates(certificates, issued)
+	certificates, certificateInfos, rejectedCertificates := inspectCertificates(certificates)
 
 	var tcpPorts, udpPorts []int
 	for _, r := range tcpRoutes {
@@ -88,17 +106,24 @@ func compileTraefikState(expected *agenthttp.ExpectedState) *compiledTraefikStat
 	}
 
 	routesConfig, compileErr := traefik.CompileRoutes(httpRoutes, tcpRoutes, udpRoutes, expected.ServerName)
+	trustedIPs, err := traefik.NormalizeTrustedIPs(expected.Traefik.TrustedProxies)
+	if err != nil && compileErr == nil {
+		compileErr = err
+	}
 	return &compiledTraefikState{
-		HTTP:         httpRoutes,
-		TCP:          tcpRoutes,
-		UDP:          udpRoutes,
-		Certificates: certificates,
-		TCPPorts:     tcpPorts,
-		UDPPorts:     udpPorts,
-		Routes:       routesConfig,
-		RoutesHash:   traefik.HashRoutesConfig(routesConfig),
-		CompileErr:   compileErr,
-		CertHash:     traefik.HashCertificates(certificates),
+		HTTP:                 httpRoutes,
+		TCP:                  tcpRoutes,
+		UDP:                  udpRoutes,
+		Certificates:         certificates,
+		CertificateInfos:     certificateInfos,
+		RejectedCertificates: rejectedCertificates,
+		TCPPorts:             tcpPorts,
+		UDPPorts:             udpPorts,
+		TrustedIPs:           trustedIPs,
+		Routes:               routesConfig,
+		RoutesHash:           traefik.HashRoutesConfig(routesConfig),
+		CompileErr:           compileErr,
+		CertHash:             traefik.HashCertificates(certificates),
 	}
 }
 
@@ -107,8 +132,15 @@ func (a *Agent) compiledTraefikState(expected *agenthttp.ExpectedState) *compile
 	defer a.compiledTraefikMutex.Unlock()
 
 	if a.compiledTraefikFor != expected || a.compiledTraefik == nil {
-		a.compiledTraefik = compileTraefikState(expected)
+		a.compiledTraefik = compileTraefikState(expected, a.issuedCertificates())
 		a.compiledTraefikFor = expected
 	}
 	return a.compiledTraefik
 }
+
+func (a *Agent) invalidateCompiledTraefikState() {
+	a.compiledTraefikMutex.Lock()
+	defer a.compiledTraefikMutex.Unlock()
+	a.compiledTraefik = nil
+	a.compiledTraefikFor = nil
+}
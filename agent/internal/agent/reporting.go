@@ -41,6 +41,10 @@ func (a *Agent) BuildStatusReport(includeResources bool) *agenthttp.StatusReport
 	}
 	if a.IsProxy {
 		report.CrowdSecHealth = a.crowdSecHealth.Load()
+		report.IssuedCertificates = a.issuedCertificateReports()
+		// An empty list clears issues the control plane stored earlier; nil
+		// (no expected state yet) leaves them untouched.
+		report.CertificateIssues = a.certificateReport()
 	}
 
 	if includeResources {
@@ -72,6 +76,10 @@ func (a *Agent) BuildStatusReport(includeResources bool) *agenthttp.StatusReport
 					log.Printf("[metrics] failed to send container stats: %v", err)
 				}
 			}()
+			if a.IsProxy {
+				go a.sendCertificateMetrics(collectedAt)
+				go a.sendServerlessMetrics(collectedAt)
+			}
 		}
 		report.NetworkHealth = health.CollectNetworkHealth("wg0")
 		report.ContainerHealth = health.CollectContainerHealth()
@@ -56,6 +56,26 @@ func (a *Agent) Run(ctx context.Context) {
 			log.Printf("[serverless-gateway] failed to start: %v", err)
 		} else {
 			a.serverlessGatewayRunning.Store(true)
+			a.serverlessGateway.Store(gateway)
+		}
+	}
+
+	if a.IsProxy && a.ServerlessCheckpoint {
+		go a.CleanupServerlessCheckpoints()
+	}
+
+	if a.Config.WireGuardIP != "" {
+		peerWake := serverless.NewPeerWakeServer(a)
+		if err := peerWake.Start(ctx, a.Config.WireGuardIP); err != nil {
+			log.Printf("[serverless-peer] failed to start: %v", err)
+		}
+	}
+
+	if a.IsProxy && a.ACME != nil {
+		if err := a.ACME.Start(ctx); err != nil {
+			log.Printf("[acme] failed to start challenge responder: %v", err)
+		} else {
+			go a.ACMEFallbackLoop(ctx)
 		}
 	}
 
@@ -270,9 +290,9 @@ func (a *Agent) reportStatus(reason string) {
 	startedAt := time.Now()
 	report := a.BuildStatusReport(true)
 	reportedDeploymentErrorCount := len(report.DeploymentErrors)
-	completed, active := a.SnapshotWorkStatus()
+	completed, active, buildCapacity := a.SnapshotWorkStatus()
 	serverlessTransitions := a.SnapshotServerlessTransitions()
-	response, err := a.Client.ReportStatus(report, completed, active, serverlessTransitions)
+	response, err := a.Client.ReportStatus(report, completed, active, buildCapacity, serverlessTransitions)
 	if err != nil {
 		log.Printf("[status] failed to report (%s) latency=%s: %v", reason, time.Since(startedAt).Round(time.Millisecond), err)
 		return
@@ -3,14 +3,20 @@ package agent
 import (
 	"crypto/rand"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"techulus/cloud-agent/internal/container"
 	agenthttp "techulus/cloud-agent/internal/http"
+	"techulus/cloud-agent/internal/serverless"
 	"techulus/cloud-agent/internal/traefik"
 )
 
@@ -37,8 +43,9 @@ func (a *Agent) ExpectedState() *agenthttp.ExpectedState {
 	return a.latestExpectedState
 }
 
-func (a *Agent) DeployServerlessContainer(expected agenthttp.ExpectedContainer) error {
-	return a.withDeploymentDeployLock(expected.DeploymentID, func() error {
+func (a *Agent) DeployServerlessContainer(expected agenthttp.ExpectedContainer) (serverless.StartMode, error) {
+	mode := serverless.StartModeCold
+	err := a.withDeploymentDeployLock(expected.DeploymentID, func() error {
 		containers, err := container.List()
 		if err == nil {
 			for _, actual := range containers {
@@ -57,6 +64,24 @@ func (a *Agent) DeployServerlessContainer(expected agenthttp.ExpectedContainer)
 				if actual.State == "running" {
 					return nil
 				}
+				if a.hasServerlessCheckpoint(actual.ID) {
+					log.Printf(
+						"[serverless] restoring checkpointed container %s for deployment %s",
+						Truncate(actual.ID, 12),
+						Truncate(expected.DeploymentID, 8),
+					)
+					err := container.Restore(actual.ID, a.serverlessCheckpointArchive(actual.ID))
+					a.removeServerlessCheckpoint(actual.ID)
+					if err == nil {
+						mode = serverless.StartModeRestore
+						return nil
+					}
+					log.Printf(
+						"[serverless] restore failed for deployment %s, cold starting: %v",
+						Truncate(expected.DeploymentID, 8),
+						err,
+					)
+				}
 				log.Printf(
 					"[serverless] starting stopped container %s for deployment %s",
 					Truncate(actual.ID, 12),
@@ -70,11 +95,15 @@ func (a *Agent) DeployServerlessContainer(expected agenthttp.ExpectedContainer)
 					)
 					return a.Reconciler.Deploy(expected)
 				}
+				if a.ServerlessCheckpoint {
+					a.removeServerlessCheckpoint(actual.ID)
+				}
 				return nil
 			}
 		}
 		return a.Reconciler.Deploy(expected)
 	})
+	return mode, err
 }
 
 func (a *Agent) DeployExpectedContainer(expected agenthttp.ExpectedContainer) error {
@@ -111,10 +140,110 @@ func (a *Agent) withDeploymentDeployLock(deploymentID string, fn func() error) e
 	return fn()
 }
 
+func (a *Agent) sendServerlessMetrics(collectedAt time.Time) {
+	gateway := a.serverlessGateway.Load()
+	if a.MetricsSender == nil || gateway == nil {
+		return
+	}
+	if err := a.MetricsSender.SendServerlessStats(gateway.Stats(), collectedAt); err != nil {
+		log.Printf("[metrics] failed to send serverless stats: %v", err)
+	}
+}
+
+func (a *Agent) SignPeerRequest(req *http.Request, body []byte) {
+	a.Client.SignPeerRequest(req, body)
+}
+
 func (a *Agent) StopServerlessContainer(containerID string) error {
+	if a.ServerlessCheckpoint {
+		err := os.MkdirAll(a.serverlessCheckpointDir(), 0700)
+		if err == nil {
+			err = container.Checkpoint(containerID, a.serverlessCheckpointArchive(containerID))
+		}
+		if err == nil {
+			return nil
+		}
+		log.Printf("[serverless] checkpoint failed for container %s, stopping instead: %v", Truncate(containerID, 12), err)
+	}
 	return container.Stop(containerID)
 }
 
+// Checkpoints are exported to archives the agent owns, so it can delete them
+// without touching podman's container storage.
+func (a *Agent) serverlessCheckpointDir() string {
+	return filepath.Join(a.DataDir, "checkpoints")
+}
+
+func (a *Agent) serverlessCheckpointArchive(containerID string) string {
+	return filepath.Join(a.serverlessCheckpointDir(), containerID+".tar.gz")
+}
+
+func (a *Agent) hasServerlessCheckpoint(containerID string) bool {
+	if !a.ServerlessCheckpoint {
+		return false
+	}
+	checkpointed, err := container.CheckpointRestorable(containerID, a.serverlessCheckpointArchive(containerID))
+	if err != nil {
+		log.Printf("[serverless] checkpoint lookup failed for container %s: %v", Truncate(containerID, 12), err)
+		return false
+	}
+	return checkpointed
+}
+
+func (a *Agent) removeServerlessCheckpoint(containerID string) {
+	if err := os.Remove(a.serverlessCheckpointArchive(containerID)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("[serverless] failed to clean up checkpoint of container %s: %v", Truncate(containerID, 12), err)
+	}
+}
+
+// CleanupServerlessCheckpoints frees checkpoint archives that can no longer
+// be restored, including those from before the agent restarted.
+func (a *Agent) CleanupServerlessCheckpoints() {
+	entries, err := os.ReadDir(a.serverlessCheckpointDir())
+	if err != nil {
+		if !errors.Is(err, os.ErrNotExist) {
+			log.Printf("[serverless] checkpoint cleanup skipped: %v", err)
+		}
+		return
+	}
+	containers, err := container.List()
+	if err != nil {
+		log.Printf("[serverless] checkpoint cleanup skipped: %v", err)
+		return
+	}
+	stale, stopped := partitionServerlessCheckpoints(entries, containers)
+	for _, containerID := range stopped {
+		if !a.hasServerlessCheckpoint(containerID) {
+			stale = append(stale, containerID)
+		}
+	}
+	for _, containerID := range stale {
+		a.removeServerlessCheckpoint(containerID)
+	}
+}
+
+// partitionServerlessCheckpoints splits checkpoint archives into those whose
+// container is gone or running again, which are stale, and those whose
+// container is stopped and may still be restored from them.
+func partitionServerlessCheckpoints(entries []os.DirEntry, containers []container.Container) (stale, stopped []string) {
+	states := make(map[string]string, len(containers))
+	for _, actual := range containers {
+		states[actual.ID] = actual.State
+	}
+	for _, entry := range entries {
+		containerID, ok := strings.CutSuffix(entry.Name(), ".tar.gz")
+		if !ok {
+			continue
+		}
+		if state, exists := states[containerID]; !exists || state == "running" {
+			stale = append(stale, containerID)
+		} else {
+			stopped = append(stopped, containerID)
+		}
+	}
+	return stale, stopped
+}
+
 func (a *Agent) ListServerlessContainers() ([]container.Container, error) {
 	return container.List()
 }
@@ -124,7 +253,7 @@ func (a *Agent) GetServerlessContainerHealth(containerID string) string {
 }
 
 func (a *Agent) QueueServerlessTransition(transition agenthttp.ServerlessTransition) {
-	if transition.Type == "" || transition.DeploymentID == "" {
+	if transition.Type == "" || (transition.DeploymentID == "" && transition.ServiceID == "") {
 		return
 	}
 	if transition.ID == "" {
@@ -187,9 +316,10 @@ func (a *Agent) AcknowledgeServerlessTransitions(results []agenthttp.ServerlessT
 		acknowledged[result.ID] = result
 		if result.Outcome == "rejected" {
 			log.Printf(
-				"[serverless] transition rejected type=%s deployment=%s reason=%s",
+				"[serverless] transition rejected type=%s deployment=%s service=%s reason=%s",
 				result.Type,
 				Truncate(result.DeploymentID, 8),
+				Truncate(result.ServiceID, 8),
 				result.Reason,
 			)
 		}
@@ -1,6 +1,9 @@
 package agent
 
 import (
+	"encoding/json"
+	"os"
+	"path/filepath"
 	"slices"
 	"testing"
 	"time"
@@ -192,6 +195,42 @@ func TestRejectedSleepClearsGuard(t *testing.T) {
 	}
 }
 
+func TestScaleTransitionRoundTripsWithoutDeployment(t *testing.T) {
+	agent := &Agent{}
+	agent.QueueServerlessTransition(agenthttp.ServerlessTransition{
+		Type:           "scale_up",
+		ServiceID:      "svc_1",
+		Replicas:       3,
+		ActiveRequests: 5,
+	})
+	transitions := agent.SnapshotServerlessTransitions()
+	if len(transitions) != 1 || transitions[0].ID == "" {
+		t.Fatalf("transitions = %+v, want one identified scale_up", transitions)
+	}
+
+	body, err := json.Marshal(transitions[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var payload map[string]any
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload["type"] != "scale_up" || payload["serviceId"] != "svc_1" || payload["replicas"] != float64(3) || payload["deploymentId"] != "" {
+		t.Fatalf("payload = %s", body)
+	}
+
+	var results []agenthttp.ServerlessTransitionResult
+	response := `[{"id":"` + transitions[0].ID + `","type":"scale_up","serviceId":"svc_1","outcome":"applied"}]`
+	if err := json.Unmarshal([]byte(response), &results); err != nil {
+		t.Fatal(err)
+	}
+	agent.AcknowledgeServerlessTransitions(results, len(transitions))
+	if remaining := agent.SnapshotServerlessTransitions(); len(remaining) != 0 {
+		t.Fatalf("remaining transitions = %+v, want none", remaining)
+	}
+}
+
 func TestSleepGuardExpiresWhenExpectedStateStaysRunning(t *testing.T) {
 	agent := &Agent{
 		pendingServerlessSleep: map[string]serverlessTransitionGuard{
@@ -234,3 +273,24 @@ func expectedServerlessState(desiredState string) *agenthttp.ExpectedState {
 		},
 	}
 }
+
+func TestPartitionServerlessCheckpoints(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"gone.tar.gz", "awake.tar.gz", "asleep.tar.gz", "partial.tmp"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stale, stopped := partitionServerlessCheckpoints(entries, []container.Container{
+		{ID: "awake", State: "running"},
+		{ID: "asleep", State: "exited"},
+	})
+	if !slices.Equal(stale, []string{"awake", "gone"}) || !slices.Equal(stopped, []string{"asleep"}) {
+		t.Fatalf("stale = %v, stopped = %v", stale, stopped)
+	}
+}
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"sort"
 	"time"
 
+	"techulus/cloud-agent/internal/build"
 	"techulus/cloud-agent/internal/container"
 	agenthttp "techulus/cloud-agent/internal/http"
 )
@@ -18,10 +20,10 @@ const (
 func (a *Agent) HasActiveWorkItem() bool {
 	a.workMutex.Lock()
 	defer a.workMutex.Unlock()
-	return a.activeWorkItem != nil
+	return a.activeWorkItem != nil || len(a.activeBuildItems) > 0
 }
 
-func (a *Agent) SnapshotWorkStatus() ([]agenthttp.CompletedWorkItem, []agenthttp.ActiveWorkItem) {
+func (a *Agent) SnapshotWorkStatus() ([]agenthttp.CompletedWorkItem, []agenthttp.ActiveWorkItem, int) {
 	a.workMutex.Lock()
 	defer a.workMutex.Unlock()
 
@@ -33,8 +35,28 @@ func (a *Agent) SnapshotWorkStatus() ([]agenthttp.CompletedWorkItem, []agenthttp
 			Attempt: a.activeWorkItem.Attempt,
 		})
 	}
+	for _, item := range a.activeBuildItems {
+		active = append(active, agenthttp.ActiveWorkItem{
+			ID:      item.ID,
+			Attempt: item.Attempt,
+		})
+	}
+	for _, item := range a.activeRunItems {
+		active = append(active, agenthttp.ActiveWorkItem{
+			ID:      item.ID,
+			Attempt: item.Attempt,
+		})
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].ID < active[j].ID })
 
-	return completed, active
+	return completed, active, a.buildCapacityLocked()
+}
+
+func (a *Agent) buildCapacityLocked() int {
+	if a.Builder == nil || a.activeWorkItem != nil {
+		return 0
+	}
+	return max(a.Builder.Capacity()-len(a.activeBuildItems), 0)
 }
 
 func (a *Agent) AcknowledgeWorkResults(accepted []string, rejected []agenthttp.RejectedWorkItemResult) {
@@ -77,11 +99,54 @@ func (a *Agent) AcceptLeasedWorkItems(items []agenthttp.WorkQueueItem) {
 	item := items[0]
 
 	a.workMutex.Lock()
+	// Task runs can last up to an hour, so they run beside the work slot
+	// instead of holding it; the control plane renews them as active items.
+	if item.Type == "run" {
+		// The task container is named after the run, so starting a re-leased
+		// attempt would replace the one still running.
+		if _, ok := a.activeRunItems[item.ID]; ok {
+			log.Printf("[work-queue] ignoring leased run %s that is already running", Truncate(item.ID, 8))
+			a.workMutex.Unlock()
+			return
+		}
+		if a.activeRunItems == nil {
+			a.activeRunItems = map[string]agenthttp.WorkQueueItem{}
+		}
+		a.activeRunItems[item.ID] = item
+		a.workMutex.Unlock()
+
+		go a.processLeasedWorkItem(item)
+		return
+	}
 	if a.activeWorkItem != nil {
 		log.Printf("[work-queue] ignoring leased item %s while %s is active", Truncate(item.ID, 8), Truncate(a.activeWorkItem.ID, 8))
 		a.workMutex.Unlock()
 		return
 	}
+	if item.Type == "build" {
+		if a.buildCapacityLocked() == 0 {
+			log.Printf("[work-queue] ignoring leased build %s with no free build capacity", Truncate(item.ID, 8))
+			a.workMutex.Unlock()
+			return
+		}
+		if a.activeBuildItems == nil {
+			a.activeBuildItems = map[string]agenthttp.WorkQueueItem{}
+		}
+		a.activeBuildItems[item.ID] = item
+		moreCapacity := a.buildCapacityLocked() > 0
+		a.workMutex.Unlock()
+
+		go a.processLeasedWorkItem(item)
+		if moreCapacity {
+			a.RequestStatusReport("build slot available")
+		}
+		return
+	}
+	if len(a.activeBuildItems) > 0 {
+		log.Printf("[work-queue] ignoring leased item %s while builds are active", Truncate(item.ID, 8))
+		a.workMutex.Unlock()
+		return
+	}
 	a.activeWorkItem = &item
 	a.workMutex.Unlock()
 
@@ -95,6 +160,8 @@ func (a *Agent) processLeasedWorkItem(item agenthttp.WorkQueueItem) {
 	errorMsg := ""
 	restartAfterReport := false
 	var commandResult *container.CommandResult
+	var runResult *container.TaskResult
+	var scanResult *build.VulnerabilityCounts
 	var processErr error
 	if item.Type == "command" {
 		result, err := a.ProcessCommand(item)
@@ -102,6 +169,18 @@ func (a *Agent) processLeasedWorkItem(item agenthttp.WorkQueueItem) {
 		if err == nil {
 			commandResult = &result
 		}
+	} else if item.Type == "run" {
+		result, err := a.ProcessRun(item)
+		processErr = err
+		if err == nil {
+			runResult = &result
+		}
+	} else if item.Type == "scan_image" {
+		result, err := a.ProcessScanImage(item)
+		processErr = err
+		if err == nil {
+			scanResult = &result
+		}
 	} else {
 		processErr = a.ProcessWorkItem(item)
 	}
@@ -122,6 +201,12 @@ func (a *Agent) processLeasedWorkItem(item agenthttp.WorkQueueItem) {
 	if !restartAfterReport && a.activeWorkItem != nil && a.activeWorkItem.ID == item.ID && a.activeWorkItem.Attempt == item.Attempt {
 		a.activeWorkItem = nil
 	}
+	if active, ok := a.activeBuildItems[item.ID]; ok && active.Attempt == item.Attempt {
+		delete(a.activeBuildItems, item.ID)
+	}
+	if active, ok := a.activeRunItems[item.ID]; ok && active.Attempt == item.Attempt {
+		delete(a.activeRunItems, item.ID)
+	}
 	completed := agenthttp.CompletedWorkItem{
 		ID:      item.ID,
 		Attempt: item.Attempt,
@@ -143,6 +228,26 @@ func (a *Agent) processLeasedWorkItem(item agenthttp.WorkQueueItem) {
 			completed.Status = "failed"
 		}
 	}
+	if runResult != nil {
+		completed.Result = agenthttp.RunWorkItemResult{
+			Type:     "run",
+			ExitCode: &runResult.ExitCode,
+			TimedOut: runResult.TimedOut,
+		}
+		if runResult.TimedOut {
+			completed.Status = "failed"
+			completed.Error = "run timed out"
+		} else if runResult.ExitCode != 0 {
+			completed.Status = "failed"
+			completed.Error = fmt.Sprintf("run exited with code %d", runResult.ExitCode)
+		}
+	}
+	if scanResult != nil {
+		completed.Result = agenthttp.ScanImageWorkItemResult{
+			Type:            "scan_image",
+			Vulnerabilities: vulnerabilityReport(*scanResult),
+		}
+	}
 	a.pendingWorkResults = append(a.pendingWorkResults, completed)
 	a.workMutex.Unlock()
 
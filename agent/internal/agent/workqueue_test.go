@@ -0,0 +1,95 @@
+package agent
+
+import (
+	"testing"
+
+	"techulus/cloud-agent/internal/build"
+	agenthttp "techulus/cloud-agent/internal/http"
+)
+
+func TestBuildCapacityCountsActiveBuilds(t *testing.T) {
+	a := &Agent{
+		Builder: build.NewBuilder(t.TempDir(), nil, build.Limits{Slots: 2, QueueSize: 1}),
+		activeBuildItems: map[string]agenthttp.WorkQueueItem{
+			"build-b": {ID: "build-b", Type: "build", Attempt: 1},
+			"build-a": {ID: "build-a", Type: "build", Attempt: 2},
+		},
+	}
+
+	_, active, capacity := a.SnapshotWorkStatus()
+	if capacity != 1 {
+		t.Fatalf("build capacity = %d, want 1", capacity)
+	}
+	if len(active) != 2 || active[0].ID != "build-a" || active[0].Attempt != 2 || active[1].ID != "build-b" {
+		t.Fatalf("active work items = %+v", active)
+	}
+
+	a.activeWorkItem = &agenthttp.WorkQueueItem{ID: "deploy", Type: "deploy", Attempt: 1}
+	if _, _, capacity := a.SnapshotWorkStatus(); capacity != 0 {
+		t.Fatalf("build capacity with other work active = %d, want 0", capacity)
+	}
+}
+
+func TestAcceptLeasedWorkItemsHoldsOtherWorkWhileBuilding(t *testing.T) {
+	a := &Agent{
+		Builder: build.NewBuilder(t.TempDir(), nil, build.Limits{Slots: 1}),
+		activeBuildItems: map[string]agenthttp.WorkQueueItem{
+			"build-a": {ID: "build-a", Type: "build", Attempt: 1},
+		},
+	}
+
+	a.AcceptLeasedWorkItems([]agenthttp.WorkQueueItem{{ID: "build-b", Type: "build", Attempt: 1}})
+	a.AcceptLeasedWorkItems([]agenthttp.WorkQueueItem{{ID: "deploy", Type: "deploy", Attempt: 1}})
+
+	if a.activeWorkItem != nil || len(a.activeBuildItems) != 1 {
+		t.Fatalf("accepted work beyond capacity: active=%v builds=%v", a.activeWorkItem, a.activeBuildItems)
+	}
+}
+
+func TestRunsStayOutsideTheWorkSlot(t *testing.T) {
+	a := &Agent{
+		Builder: build.NewBuilder(t.TempDir(), nil, build.Limits{Slots: 1}),
+		activeRunItems: map[string]agenthttp.WorkQueueItem{
+			"run-a": {ID: "run-a", Type: "run", Attempt: 1},
+		},
+	}
+
+	if a.HasActiveWorkItem() {
+		t.Fatal("an active run should not block other work")
+	}
+	_, active, capacity := a.SnapshotWorkStatus()
+	if capacity != 1 {
+		t.Fatalf("build capacity during a run = %d, want 1", capacity)
+	}
+	if len(active) != 1 || active[0].ID != "run-a" {
+		t.Fatalf("active work items = %+v, want the run for lease renewal", active)
+	}
+}
+
+func TestReleasedRunIsNotStartedTwice(t *testing.T) {
+	running := agenthttp.WorkQueueItem{ID: "run-a", Type: "run", Attempt: 1}
+	a := &Agent{activeRunItems: map[string]agenthttp.WorkQueueItem{"run-a": running}}
+
+	a.AcceptLeasedWorkItems([]agenthttp.WorkQueueItem{{ID: "run-a", Type: "run", Attempt: 2}})
+
+	if got := a.activeRunItems["run-a"]; got != running {
+		t.Fatalf("active run = %+v, want the first attempt to keep running", got)
+	}
+}
+
+func TestOrphanedTasksSkipsActiveRuns(t *testing.T) {
+	a := &Agent{
+		activeRunItems: map[string]agenthttp.WorkQueueItem{
+			"run-a": {ID: "run-a", Type: "run", Attempt: 1},
+		},
+	}
+
+	orphaned := a.orphanedTasks(map[string]string{
+		"container-a": "run-a",
+		"container-c": "run-c",
+		"container-b": "run-b",
+	})
+	if len(orphaned) != 2 || orphaned[0] != "container-b" || orphaned[1] != "container-c" {
+		t.Fatalf("orphaned task containers = %v", orphaned)
+	}
+}
@@ -0,0 +1,159 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+
+	"techulus/cloud-agent/internal/paths"
+)
+
+const (
+	referenceTypeAnnotation   = "vnd.docker.reference.type"
+	referenceDigestAnnotation = "vnd.docker.reference.digest"
+	predicateTypeAnnotation   = "in-toto.io/predicate-type"
+	sbomPredicateType         = "https://spdx.dev/Document"
+	provenancePredicatePrefix = "https://slsa.dev/provenance/"
+)
+
+// Attestation records where the SBOM and SLSA provenance BuildKit pushed for
+// one platform image live in the registry.
+type Attestation struct {
+	Platform         string
+	ImageDigest      string
+	ManifestDigest   string
+	SBOMDigest       string
+	ProvenanceDigest string
+}
+
+type ociDescriptor struct {
+	Digest   string `json:"digest"`
+	Platform *struct {
+		OS           string `json:"os"`
+		Architecture string `json:"architecture"`
+		Variant      string `json:"variant"`
+	} `json:"platform"`
+	Annotations map[string]string `json:"annotations"`
+}
+
+type ociManifest struct {
+	Manifests []ociDescriptor `json:"manifests"`
+	Layers    []ociDescriptor `json:"layers"`
+}
+
+func attestationArgs() []string {
+	return []string{
+		"--opt", "attest:sbom=",
+		"--opt", "attest:provenance=mode=max",
+	}
+}
+
+// parseAttestations walks an image index and reads each attestation manifest
+// through fetch to find its SBOM and provenance layers.
+func parseAttestations(index []byte, fetch func(digest string) ([]byte, error)) ([]Attestation, error) {
+	var parsed ociManifest
+	if err := json.Unmarshal(index, &parsed); err != nil {
+		return nil, fmt.Errorf("failed to parse image index: %w", err)
+	}
+
+	platforms := make(map[string]string, len(parsed.Manifests))
+	for _, descriptor := range parsed.Manifests {
+		if descriptor.Platform != nil && descriptor.Annotations[referenceTypeAnnotation] == "" {
+			platform := descriptor.Platform.OS + "/" + descriptor.Platform.Architecture
+			if descriptor.Platform.Variant != "" {
+				platform += "/" + descriptor.Platform.Variant
+			}
+			platforms[descriptor.Digest] = platform
+		}
+	}
+
+	var attestations []Attestation
+	for _, descriptor := range parsed.Manifests {
+		if descriptor.Annotations[referenceTypeAnnotation] != "attestation-manifest" {
+			continue
+		}
+		imageDigest := descriptor.Annotations[referenceDigestAnnotation]
+		raw, err := fetch(descriptor.Digest)
+		if err != nil {
+			return nil, err
+		}
+		var manifest ociManifest
+		if err := json.Unmarshal(raw, &manifest); err != nil {
+			return nil, fmt.Errorf("failed to parse attestation manifest %s: %w", descriptor.Digest, err)
+		}
+		attestation := Attestation{
+			Platform:       platforms[imageDigest],
+			ImageDigest:    imageDigest,
+			ManifestDigest: descriptor.Digest,
+		}
+		for _, layer := range manifest.Layers {
+			predicateType := layer.Annotations[predicateTypeAnnotation]
+			switch {
+			case predicateType == sbomPredicateType && attestation.SBOMDigest == "":
+				attestation.SBOMDigest = layer.Digest
+			case strings.HasPrefix(predicateType, provenancePredicatePrefix) && attestation.ProvenanceDigest == "":
+				attestation.ProvenanceDigest = layer.Digest
+			}
+		}
+		attestations = append(attestations, attestation)
+	}
+
+	sort.Slice(attestations, func(i, j int) bool {
+		return attestations[i].Platform < attestations[j].Platform
+	})
+	return attestations, nil
+}
+
+func (b *Builder) readAttestations(ctx context.Context, config *Config, digest string) ([]Attestation, error) {
+	fetch := func(manifestDigest string) ([]byte, error) {
+		args := []string{"manifest"}
+		if !config.TargetTLSVerify {
+			args = append(args, "--insecure")
+		}
+		args = append(args, fmt.Sprintf("%s@%s", config.ImageRepository, manifestDigest))
+		cmd := exec.CommandContext(ctx, paths.CranePath, args...)
+		cmd.Env = append(os.Environ(), "DOCKER_CONFIG="+config.DockerConfigDir, "HOME="+config.EmptyHome)
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("crane manifest %s failed: %w: %s", manifestDigest, err, strings.TrimSpace(stderr.String()))
+		}
+		return out, nil
+	}
+
+	index, err := fetch(digest)
+	if err != nil {
+		return nil, err
+	}
+	return parseAttestations(index, fetch)
+}
+
+func (b *Builder) recordAttestations(ctx context.Context, config *Config, digest string) {
+	attestations, err := b.readAttestations(ctx, config, digest)
+	if err != nil {
+		log.Printf("[build:%s] failed to read attestations: %v", truncateStr(config.BuildID, 8), err)
+		b.sendLog(config, fmt.Sprintf("Could not read attestations: %v", err))
+		return
+	}
+	config.Attestations = attestations
+	for _, attestation := range attestations {
+		var kinds []string
+		if attestation.SBOMDigest != "" {
+			kinds = append(kinds, "SBOM")
+		}
+		if attestation.ProvenanceDigest != "" {
+			kinds = append(kinds, "provenance")
+		}
+		if len(kinds) == 0 {
+			continue
+		}
+		b.sendLog(config, fmt.Sprintf("Attestations for %s: %s (%s)", attestation.Platform, strings.Join(kinds, ", "), attestation.ManifestDigest))
+	}
+}
@@ -0,0 +1,66 @@
+package build
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseAttestations(t *testing.T) {
+	amd64 := "sha256:" + strings.Repeat("a", 64)
+	arm64 := "sha256:" + strings.Repeat("b", 64)
+	amd64Attestation := "sha256:" + strings.Repeat("c", 64)
+	arm64Attestation := "sha256:" + strings.Repeat("d", 64)
+	index := `{"manifests":[
+		{"digest":"` + arm64 + `","platform":{"os":"linux","architecture":"arm64","variant":"v8"}},
+		{"digest":"` + amd64 + `","platform":{"os":"linux","architecture":"amd64"}},
+		{"digest":"` + arm64Attestation + `","platform":{"os":"unknown","architecture":"unknown"},"annotations":{"vnd.docker.reference.type":"attestation-manifest","vnd.docker.reference.digest":"` + arm64 + `"}},
+		{"digest":"` + amd64Attestation + `","platform":{"os":"unknown","architecture":"unknown"},"annotations":{"vnd.docker.reference.type":"attestation-manifest","vnd.docker.reference.digest":"` + amd64 + `"}}
+	]}`
+	manifests := map[string]string{
+		amd64Attestation: `{"layers":[
+			{"digest":"sha256:sbom","annotations":{"in-toto.io/predicate-type":"https://spdx.dev/Document"}},
+			{"digest":"sha256:provenance","annotations":{"in-toto.io/predicate-type":"https://slsa.dev/provenance/v0.2"}}
+		]}`,
+		arm64Attestation: `{"layers":[
+			{"digest":"sha256:arm-provenance","annotations":{"in-toto.io/predicate-type":"https://slsa.dev/provenance/v1"}}
+		]}`,
+	}
+	var fetched []string
+	got, err := parseAttestations([]byte(index), func(digest string) ([]byte, error) {
+		fetched = append(fetched, digest)
+		manifest, ok := manifests[digest]
+		if !ok {
+			return nil, errors.New("unexpected manifest fetch")
+		}
+		return []byte(manifest), nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []Attestation{
+		{Platform: "linux/amd64", ImageDigest: amd64, ManifestDigest: amd64Attestation, SBOMDigest: "sha256:sbom", ProvenanceDigest: "sha256:provenance"},
+		{Platform: "linux/arm64/v8", ImageDigest: arm64, ManifestDigest: arm64Attestation, ProvenanceDigest: "sha256:arm-provenance"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("parseAttestations() = %+v, want %+v", got, want)
+	}
+	if len(fetched) != 2 {
+		t.Fatalf("fetched %v, want only attestation manifests", fetched)
+	}
+}
+
+func TestParseAttestationsWithoutAttestationManifests(t *testing.T) {
+	got, err := parseAttestations([]byte(`{"manifests":[{"digest":"sha256:image","platform":{"os":"linux","architecture":"amd64"}}]}`), func(string) ([]byte, error) {
+		t.Fatal("fetched a manifest without attestations")
+		return nil, nil
+	})
+	if err != nil || len(got) != 0 {
+		t.Fatalf("parseAttestations() = %+v, %v", got, err)
+	}
+	if _, err := parseAttestations([]byte("not json"), nil); err == nil {
+		t.Fatal("accepted an invalid index")
+	}
+}
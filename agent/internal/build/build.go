@@ -8,11 +8,13 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/exec"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"sort"
 	"strings"
 	"sync"
@@ -25,6 +27,11 @@ import (
 type Config struct {
 	BuildID           string
 	CloneURL          string
+	GitAuth           *GitAuth
+	Submodules        bool
+	LFS               bool
+	ContextChecksum   string
+	OpenContext       func(ctx context.Context) (io.ReadCloser, error)
 	CommitSha         string
 	Branch            string
 	GitRef            string
@@ -34,10 +41,22 @@ type Config struct {
 	ServiceID         string
 	ProjectID         string
 	RootDir           string
+	DockerfilePath    string
+	Target            string
+	BuildArgs         map[string]string
 	Secrets           map[string]string
 	TargetPlatforms   []string
 	DockerConfigDir   string
+	EmptyHome         string
 	TargetTLSVerify   bool
+	CacheStats        *CacheStats
+	Attestations      []Attestation
+	Vulnerabilities   *VulnerabilityCounts
+}
+
+type CacheStats struct {
+	Hits  int
+	Steps int
 }
 
 type LogSender interface {
@@ -47,6 +66,9 @@ type LogSender interface {
 type Builder struct {
 	dataDir   string
 	logSender LogSender
+	limits    Limits
+	slots     chan struct{}
+	vulnDB    string
 }
 
 const (
@@ -56,6 +78,7 @@ const (
 )
 
 type dockerfileConfig struct {
+	path      string
 	directory string
 	filename  string
 	found     bool
@@ -63,14 +86,20 @@ type dockerfileConfig struct {
 
 var managedTempArtifactPattern = regexp.MustCompile(`^(backup|restore)-[0-9a-fA-F-]{36}\.tar\.gz$|^restore-extract-[0-9a-fA-F-]{36}$`)
 var windowsAbsoluteRootPattern = regexp.MustCompile(`^[A-Za-z]:[\\/]`)
+var targetPlatformPattern = regexp.MustCompile(`^[a-z0-9]+/[a-z0-9_]+(/[a-z0-9]+)?$`)
+var buildStepPattern = regexp.MustCompile(`^#(\d+) \[[^\]]*\d+/\d+\] `)
+var cachedStepPattern = regexp.MustCompile(`^#(\d+) CACHED$`)
 var imageDigestPattern = regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)
 var credentialURLPattern = regexp.MustCompile(`(?i)https?://[^\s/@]+(?::[^\s/@]*)?@`)
 var pullRequestMergeRefPattern = regexp.MustCompile(`^refs/pull/[1-9][0-9]*/merge$`)
 
-func NewBuilder(dataDir string, logSender LogSender) *Builder {
+func NewBuilder(dataDir string, logSender LogSender, limits Limits) *Builder {
+	limits = limits.normalized()
 	return &Builder{
 		dataDir:   dataDir,
 		logSender: logSender,
+		limits:    limits,
+		slots:     make(chan struct{}, limits.Slots),
 	}
 }
 
@@ -90,7 +119,11 @@ func (b *Builder) Build(ctx context.Context, config *Config, checkCancelled func
 		return "", fmt.Errorf("build cancelled")
 	}
 
-	if err := b.clone(ctx, config, buildDir); err != nil {
+	if config.OpenContext != nil {
+		if err := b.unpackContext(ctx, config, buildDir); err != nil {
+			return "", fmt.Errorf("build context failed: %w", err)
+		}
+	} else if err := b.clone(ctx, config, buildDir); err != nil {
 		return "", fmt.Errorf("clone failed: %w", err)
 	}
 
@@ -151,13 +184,14 @@ func computeSecretsHash(secrets map[string]string) string {
 func (b *Builder) clone(ctx context.Context, config *Config, buildDir string) error {
 	log.Printf("[build:%s] cloning repository", truncateStr(config.BuildID, 8))
 
-	safeURL := config.CloneURL
-	if idx := strings.Index(safeURL, "@"); idx != -1 {
-		safeURL = "https://***@" + safeURL[idx+1:]
+	b.sendLog(config, fmt.Sprintf("Cloning %s", credentialURLPattern.ReplaceAllString(config.CloneURL, "https://***@")))
+	env, cleanup, err := b.gitEnvironment(config)
+	if err != nil {
+		return err
 	}
-	b.sendLog(config, fmt.Sprintf("Cloning %s", safeURL))
+	defer cleanup()
 	if pullRequestMergeRefPattern.MatchString(config.GitRef) {
-		return b.clonePullRequestRef(ctx, config, buildDir)
+		return b.clonePullRequestRef(ctx, config, buildDir, env)
 	}
 
 	branch := config.Branch
@@ -166,7 +200,7 @@ func (b *Builder) clone(ctx context.Context, config *Config, buildDir string) er
 	}
 
 	if config.CommitSha == "HEAD" {
-		cmd := exec.CommandContext(ctx, "git", "clone", "--depth", "1", "--branch", branch, config.CloneURL, buildDir)
+		cmd := gitCommand(ctx, env, "clone", "--depth", "1", "--branch", branch, config.CloneURL, buildDir)
 		output, err := b.runCommand(cmd, config)
 		if err != nil {
 			return fmt.Errorf("git clone failed: %s: %w", output, err)
@@ -176,7 +210,7 @@ func (b *Builder) clone(ctx context.Context, config *Config, buildDir string) er
 		if matched, _ := regexp.MatchString(`^[0-9a-fA-F]{40}$`, config.CommitSha); !matched {
 			return fmt.Errorf("invalid exact commit SHA")
 		}
-		cmd := exec.CommandContext(ctx, "git", "clone", "--depth", "50", "--branch", branch, "--single-branch", config.CloneURL, buildDir)
+		cmd := gitCommand(ctx, env, "clone", "--depth", "50", "--branch", branch, "--single-branch", config.CloneURL, buildDir)
 		output, err := b.runCommand(cmd, config)
 		if err != nil {
 			return fmt.Errorf("git clone failed: %s: %w", output, err)
@@ -184,24 +218,27 @@ func (b *Builder) clone(ctx context.Context, config *Config, buildDir string) er
 
 		b.sendLog(config, fmt.Sprintf("Checking out commit %s", truncateStr(config.CommitSha, 8)))
 
-		cmd = exec.CommandContext(ctx, "git", "-C", buildDir, "cat-file", "-e", config.CommitSha+"^{commit}")
+		cmd = gitCommand(ctx, env, "-C", buildDir, "cat-file", "-e", config.CommitSha+"^{commit}")
 		_, err = b.runCommand(cmd, config)
 		if err != nil {
 			b.sendLog(config, "Selected commit is outside the shallow clone; fetching full branch history")
-			cmd = exec.CommandContext(ctx, "git", "-C", buildDir, "fetch", "--unshallow", "origin", branch)
+			cmd = gitCommand(ctx, env, "-C", buildDir, "fetch", "--unshallow", "origin", branch)
 			output, err = b.runCommand(cmd, config)
 			if err != nil {
 				return fmt.Errorf("git fetch full branch history failed: %s: %w", output, err)
 			}
 		}
 
-		cmd = exec.CommandContext(ctx, "git", "-C", buildDir, "checkout", config.CommitSha)
+		cmd = gitCommand(ctx, env, "-C", buildDir, "checkout", config.CommitSha)
 		output, err = b.runCommand(cmd, config)
 		if err != nil {
 			return fmt.Errorf("git checkout failed: %s: %w", output, err)
 		}
 	}
 
+	if err := b.fetchCheckoutExtras(ctx, config, buildDir, env); err != nil {
+		return err
+	}
 	b.sendLog(config, "Clone completed")
 	resolvedCommitSha, err := b.resolveCommitSha(ctx, config, buildDir)
 	if err != nil {
@@ -215,26 +252,26 @@ func (b *Builder) clone(ctx context.Context, config *Config, buildDir string) er
 	return nil
 }
 
-func (b *Builder) clonePullRequestRef(ctx context.Context, config *Config, buildDir string) error {
+func (b *Builder) clonePullRequestRef(ctx context.Context, config *Config, buildDir string, env []string) error {
 	if matched, _ := regexp.MatchString(`^[0-9a-fA-F]{40}$`, config.CommitSha); !matched {
 		return fmt.Errorf("invalid exact commit SHA")
 	}
-	cmd := exec.CommandContext(ctx, "git", "init", buildDir)
+	cmd := gitCommand(ctx, env, "init", buildDir)
 	output, err := b.runCommand(cmd, config)
 	if err != nil {
 		return fmt.Errorf("git init failed: %s: %w", output, err)
 	}
-	cmd = exec.CommandContext(ctx, "git", "-C", buildDir, "remote", "add", "origin", config.CloneURL)
+	cmd = gitCommand(ctx, env, "-C", buildDir, "remote", "add", "origin", config.CloneURL)
 	output, err = b.runCommand(cmd, config)
 	if err != nil {
 		return fmt.Errorf("git remote setup failed: %s: %w", output, err)
 	}
-	cmd = exec.CommandContext(ctx, "git", "-C", buildDir, "fetch", "--depth", "1", "--no-tags", "origin", config.GitRef)
+	cmd = gitCommand(ctx, env, "-C", buildDir, "fetch", "--depth", "1", "--no-tags", "origin", config.GitRef)
 	output, err = b.runCommand(cmd, config)
 	if err != nil {
 		return fmt.Errorf("git fetch pull request ref failed: %s: %w", output, err)
 	}
-	cmd = exec.CommandContext(ctx, "git", "-C", buildDir, "rev-parse", "FETCH_HEAD")
+	cmd = gitCommand(ctx, env, "-C", buildDir, "rev-parse", "FETCH_HEAD")
 	fetchedCommit, err := b.runCommand(cmd, config)
 	if err != nil {
 		return fmt.Errorf("git resolve fetched ref failed: %s: %w", fetchedCommit, err)
@@ -243,11 +280,14 @@ func (b *Builder) clonePullRequestRef(ctx context.Context, config *Config, build
 		return fmt.Errorf("fetched ref resolved to %s, expected %s", strings.TrimSpace(fetchedCommit), config.CommitSha)
 	}
 	b.sendLog(config, fmt.Sprintf("Checking out commit %s", truncateStr(config.CommitSha, 8)))
-	cmd = exec.CommandContext(ctx, "git", "-C", buildDir, "checkout", "--detach", config.CommitSha)
+	cmd = gitCommand(ctx, env, "-C", buildDir, "checkout", "--detach", config.CommitSha)
 	output, err = b.runCommand(cmd, config)
 	if err != nil {
 		return fmt.Errorf("git checkout failed: %s: %w", output, err)
 	}
+	if err := b.fetchCheckoutExtras(ctx, config, buildDir, env); err != nil {
+		return err
+	}
 	b.sendLog(config, "Clone completed")
 	resolvedCommitSha, err := b.resolveCommitSha(ctx, config, buildDir)
 	if err != nil {
@@ -285,10 +325,13 @@ func (b *Builder) buildAndPush(ctx context.Context, config *Config, buildDir str
 		b.sendLog(config, fmt.Sprintf("Using root directory: %s", config.RootDir))
 	}
 
-	dockerfile, err := resolveDockerfile(contextDir, config.Secrets)
+	dockerfile, err := resolveDockerfile(contextDir, config.DockerfilePath, config.Secrets)
 	if err != nil {
 		return "", err
 	}
+	if !dockerfile.found && config.Target != "" {
+		return "", fmt.Errorf("build target %s requires a Dockerfile", config.Target)
+	}
 	if config.ImageRepository == "" {
 		return "", fmt.Errorf("image repository is required")
 	}
@@ -309,22 +352,37 @@ func (b *Builder) buildAndPush(ctx context.Context, config *Config, buildDir str
 		secretEnv = append(secretEnv, fmt.Sprintf("%s=%s", key, value))
 	}
 
-	platform := "linux/amd64"
-	if len(config.TargetPlatforms) > 0 {
-		platform = config.TargetPlatforms[0]
+	platforms, err := resolveTargetPlatforms(config.TargetPlatforms)
+	if err != nil {
+		return "", err
+	}
+	platform := strings.Join(platforms, ",")
+	for _, emulated := range emulatedPlatforms(platforms, runtime.GOARCH) {
+		b.sendLog(config, fmt.Sprintf("Building %s under emulation", emulated))
+	}
+	cgroupParent, removeCgroup, err := b.createBuildCgroup(config.BuildID)
+	if err != nil {
+		return "", err
 	}
-	outputFlag := fmt.Sprintf("type=image,name=%s,push=true,push-by-digest=true", config.ImageRepository)
+	defer removeCgroup()
+	outputFlag := fmt.Sprintf("type=image,name=%s,push=true,push-by-digest=true,oci-mediatypes=true", config.ImageRepository)
 	if !config.TargetTLSVerify {
 		outputFlag += ",registry.insecure=true"
 	}
+	cacheRef := buildCacheRef(config.ImageRepository, platforms)
+	cacheArgs := registryCacheArgs(cacheRef, config.TargetTLSVerify)
+	b.sendLog(config, fmt.Sprintf("Using build cache %s", cacheRef))
 
 	if dockerfile.found {
 		log.Printf("[build:%s] building with Dockerfile via buildctl for %s", truncateStr(config.BuildID, 8), platform)
-		if configuredPath := strings.TrimSpace(config.Secrets[dockerfilePathKey]); configuredPath != "" {
-			b.sendLog(config, fmt.Sprintf("Using Dockerfile: %s", configuredPath))
+		if dockerfile.path != "" {
+			b.sendLog(config, fmt.Sprintf("Using Dockerfile: %s", dockerfile.path))
 		} else {
 			b.sendLog(config, "Using existing Dockerfile")
 		}
+		if config.Target != "" {
+			b.sendLog(config, fmt.Sprintf("Building target stage: %s", config.Target))
+		}
 		b.sendLog(config, fmt.Sprintf("Building and pushing %s", config.ImageRepository))
 
 		args := []string{
@@ -337,6 +395,13 @@ func (b *Builder) buildAndPush(ctx context.Context, config *Config, buildDir str
 			"--opt", fmt.Sprintf("platform=%s", platform),
 			"--output", outputFlag,
 			"--metadata-file", metadataPath,
+			"--progress", "plain",
+		}
+		args = append(args, cacheArgs...)
+		args = append(args, attestationArgs()...)
+		args = append(args, dockerfileBuildOpts(config)...)
+		if cgroupParent != "" {
+			args = append(args, "--opt", fmt.Sprintf("cgroup-parent=%s", cgroupParent))
 		}
 		args = append(args, secretArgs...)
 
@@ -349,12 +414,16 @@ func (b *Builder) buildAndPush(ctx context.Context, config *Config, buildDir str
 			b.sendLog(config, fmt.Sprintf("Build error: %s", output))
 			return "", fmt.Errorf("buildctl build failed:\n%s", tailLines(output, 20))
 		}
+		b.recordCacheStats(config, output)
 	} else {
 		log.Printf("[build:%s] building with Railpack via buildctl for %s", truncateStr(config.BuildID, 8), platform)
 		b.sendLog(config, "No Dockerfile found, using Railpack...")
 
 		b.sendLog(config, "Generating build plan...")
 		prepareArgs := []string{"prepare", ".", "--plan-out", "railpack-plan.json"}
+		for _, key := range sortedKeys(config.BuildArgs) {
+			prepareArgs = append(prepareArgs, "--env", fmt.Sprintf("%s=%s", key, config.BuildArgs[key]))
+		}
 		for key, value := range config.Secrets {
 			prepareArgs = append(prepareArgs, "--env", fmt.Sprintf("%s=%s", key, value))
 		}
@@ -369,6 +438,9 @@ func (b *Builder) buildAndPush(ctx context.Context, config *Config, buildDir str
 		}
 
 		b.sendLog(config, fmt.Sprintf("Building for %s...", platform))
+		if cgroupParent != "" {
+			b.sendLog(config, "Build CPU and memory limits apply to Dockerfile builds only")
+		}
 
 		args := []string{
 			"--addr", buildkitAddr,
@@ -381,7 +453,10 @@ func (b *Builder) buildAndPush(ctx context.Context, config *Config, buildDir str
 			"--opt", fmt.Sprintf("platform=%s", platform),
 			"--output", outputFlag,
 			"--metadata-file", metadataPath,
+			"--progress", "plain",
 		}
+		args = append(args, cacheArgs...)
+		args = append(args, attestationArgs()...)
 
 		secretsHash := computeSecretsHash(config.Secrets)
 		if secretsHash != "" {
@@ -398,16 +473,92 @@ func (b *Builder) buildAndPush(ctx context.Context, config *Config, buildDir str
 			b.sendLog(config, fmt.Sprintf("Build error (%s): %s", platform, output))
 			return "", fmt.Errorf("buildctl build failed for %s:\n%s", platform, tailLines(output, 20))
 		}
+		b.recordCacheStats(config, output)
 	}
 
 	digest, err := readImageDigest(metadataPath)
 	if err != nil {
 		return "", err
 	}
+	b.recordAttestations(ctx, config, digest)
+	b.recordVulnerabilities(ctx, config, platforms, digest)
 	b.sendLog(config, "Build completed")
 	return fmt.Sprintf("%s@%s", config.ImageRepository, digest), nil
 }
 
+func resolveTargetPlatforms(targets []string) ([]string, error) {
+	if len(targets) == 0 {
+		return []string{"linux/amd64"}, nil
+	}
+	seen := make(map[string]bool, len(targets))
+	platforms := make([]string, 0, len(targets))
+	for _, target := range targets {
+		platform := strings.TrimSpace(target)
+		if !targetPlatformPattern.MatchString(platform) {
+			return nil, fmt.Errorf("unsupported target platform %q", target)
+		}
+		if seen[platform] {
+			continue
+		}
+		seen[platform] = true
+		platforms = append(platforms, platform)
+	}
+	return platforms, nil
+}
+
+func emulatedPlatforms(platforms []string, hostArch string) []string {
+	var emulated []string
+	for _, platform := range platforms {
+		if strings.Split(platform, "/")[1] != hostArch {
+			emulated = append(emulated, platform)
+		}
+	}
+	return emulated
+}
+
+func buildCacheRef(repository string, platforms []string) string {
+	return fmt.Sprintf("%s:buildcache-%s", repository, strings.ReplaceAll(strings.Join(platforms, "-"), "/", "-"))
+}
+
+func registryCacheArgs(cacheRef string, tlsVerify bool) []string {
+	registryOpts := ""
+	if !tlsVerify {
+		registryOpts = ",registry.insecure=true"
+	}
+	return []string{
+		"--import-cache", fmt.Sprintf("type=registry,ref=%s%s", cacheRef, registryOpts),
+		"--export-cache", fmt.Sprintf("type=registry,ref=%s,mode=max,image-manifest=true,oci-mediatypes=true,ignore-error=true%s", cacheRef, registryOpts),
+	}
+}
+
+func (b *Builder) recordCacheStats(config *Config, output string) {
+	stats := parseCacheStats(output)
+	config.CacheStats = &stats
+	if stats.Steps > 0 {
+		b.sendLog(config, fmt.Sprintf("Build cache: %d of %d steps cached", stats.Hits, stats.Steps))
+	}
+}
+
+func parseCacheStats(output string) CacheStats {
+	steps := map[string]bool{}
+	cached := map[string]bool{}
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if match := buildStepPattern.FindStringSubmatch(line); match != nil {
+			steps[match[1]] = true
+		} else if match := cachedStepPattern.FindStringSubmatch(line); match != nil {
+			cached[match[1]] = true
+		}
+	}
+	stats := CacheStats{Steps: len(steps)}
+	for id := range cached {
+		if steps[id] {
+			stats.Hits++
+		}
+	}
+	return stats
+}
+
 func readImageDigest(metadataPath string) (string, error) {
 	data, err := os.ReadFile(metadataPath)
 	if err != nil {
@@ -464,11 +615,40 @@ func resolveBuildContext(buildDir, rootDir string) (string, error) {
 	return contextDir, nil
 }
 
-func resolveDockerfile(contextDir string, secrets map[string]string) (dockerfileConfig, error) {
-	configuredPath, configured := secrets[dockerfilePathKey]
-	configuredPath = strings.TrimSpace(configuredPath)
+// dockerfileBuildOpts returns the frontend options for the configured target
+// stage and build arguments, sorted so identical inputs reuse the same cache.
+func dockerfileBuildOpts(config *Config) []string {
+	var opts []string
+	if config.Target != "" {
+		opts = append(opts, "--opt", fmt.Sprintf("target=%s", config.Target))
+	}
+	for _, key := range sortedKeys(config.BuildArgs) {
+		opts = append(opts, "--opt", fmt.Sprintf("build-arg:%s=%s", key, config.BuildArgs[key]))
+	}
+	return opts
+}
+
+func sortedKeys(values map[string]string) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// resolveDockerfile prefers the Dockerfile path from the service's build
+// settings and falls back to the legacy TECHULUS_DOCKERFILE_PATH secret.
+func resolveDockerfile(contextDir, dockerfilePath string, secrets map[string]string) (dockerfileConfig, error) {
+	setting := "dockerfile"
+	configuredPath, configured := strings.TrimSpace(dockerfilePath), dockerfilePath != ""
+	if !configured {
+		setting = dockerfilePathKey
+		configuredPath, configured = secrets[dockerfilePathKey]
+		configuredPath = strings.TrimSpace(configuredPath)
+	}
 	if configured && configuredPath == "" {
-		return dockerfileConfig{}, fmt.Errorf("%s cannot be empty", dockerfilePathKey)
+		return dockerfileConfig{}, fmt.Errorf("%s cannot be empty", setting)
 	}
 
 	if !configured {
@@ -482,7 +662,7 @@ func resolveDockerfile(contextDir string, secrets map[string]string) (dockerfile
 
 	cleanedPath := filepath.Clean(configuredPath)
 	if filepath.IsAbs(cleanedPath) || cleanedPath == ".." || strings.HasPrefix(cleanedPath, ".."+string(filepath.Separator)) {
-		return dockerfileConfig{}, fmt.Errorf("%s must be relative to the service root directory", dockerfilePathKey)
+		return dockerfileConfig{}, fmt.Errorf("%s must be relative to the service root directory", setting)
 	}
 
 	info, err := os.Stat(filepath.Join(contextDir, cleanedPath))
@@ -502,10 +682,11 @@ func resolveDockerfile(contextDir string, secrets map[string]string) (dockerfile
 	}
 	relativePath, err := filepath.Rel(resolvedContextDir, resolvedPath)
 	if err != nil || relativePath == ".." || strings.HasPrefix(relativePath, ".."+string(filepath.Separator)) {
-		return dockerfileConfig{}, fmt.Errorf("%s must resolve inside the service root directory", dockerfilePathKey)
+		return dockerfileConfig{}, fmt.Errorf("%s must resolve inside the service root directory", setting)
 	}
 
 	return dockerfileConfig{
+		path:      configuredPath,
 		directory: filepath.Dir(cleanedPath),
 		filename:  filepath.Base(cleanedPath),
 		found:     true,
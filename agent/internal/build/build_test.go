@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"reflect"
 	"strconv"
 	"strings"
 	"testing"
@@ -104,7 +105,7 @@ func TestCloneFetchesExactPullRequestMergeRef(t *testing.T) {
 		Branch:    "main",
 		GitRef:    "refs/pull/42/merge",
 	}
-	builder := NewBuilder(t.TempDir(), nil)
+	builder := NewBuilder(t.TempDir(), nil, Limits{})
 
 	if err := builder.clone(context.Background(), config, buildDir); err != nil {
 		t.Fatal(err)
@@ -142,7 +143,7 @@ func TestCloneDeepensConfiguredBranchForSelectedCommit(t *testing.T) {
 		CommitSha: selectedSHA,
 		Branch:    "main",
 	}
-	if err := NewBuilder(t.TempDir(), nil).clone(context.Background(), config, buildDir); err != nil {
+	if err := NewBuilder(t.TempDir(), nil, Limits{}).clone(context.Background(), config, buildDir); err != nil {
 		t.Fatal(err)
 	}
 	if config.ResolvedCommitSha != selectedSHA {
@@ -177,7 +178,7 @@ func TestCloneRejectsMovedRef(t *testing.T) {
 		Branch:    "main",
 		GitRef:    "refs/pull/42/merge",
 	}
-	err := NewBuilder(t.TempDir(), nil).clone(
+	err := NewBuilder(t.TempDir(), nil, Limits{}).clone(
 		context.Background(),
 		config,
 		filepath.Join(t.TempDir(), "build"),
@@ -271,6 +272,7 @@ func TestResolveDockerfile(t *testing.T) {
 
 	tests := []struct {
 		name      string
+		path      string
 		secrets   map[string]string
 		directory string
 		filename  string
@@ -295,11 +297,21 @@ func TestResolveDockerfile(t *testing.T) {
 		{name: "escaping custom path", secrets: map[string]string{dockerfilePathKey: "../Dockerfile"}, wantErr: true},
 		{name: "directory custom path", secrets: map[string]string{dockerfilePathKey: "dockerfiles"}, wantErr: true},
 		{name: "symlink escape", secrets: map[string]string{dockerfilePathKey: "outside/Dockerfile"}, wantErr: true},
+		{
+			name:      "build setting overrides secret",
+			path:      "docker/Dockerfile.prod",
+			secrets:   map[string]string{dockerfilePathKey: "Dockerfile.custom"},
+			directory: "docker",
+			filename:  "Dockerfile.prod",
+		},
+		{name: "missing build setting", path: "missing.Dockerfile", wantErr: true},
+		{name: "escaping build setting", path: "../Dockerfile", wantErr: true},
+		{name: "symlinked build setting", path: "outside/Dockerfile", wantErr: true},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, err := resolveDockerfile(contextDir, tt.secrets)
+			got, err := resolveDockerfile(contextDir, tt.path, tt.secrets)
 			if tt.wantErr {
 				if err == nil {
 					t.Fatal("expected an error")
@@ -316,8 +328,26 @@ func TestResolveDockerfile(t *testing.T) {
 	}
 }
 
+func TestDockerfileBuildOpts(t *testing.T) {
+	got := dockerfileBuildOpts(&Config{
+		Target:    "runtime",
+		BuildArgs: map[string]string{"VERSION": "1.2.3", "NODE_ENV": "production"},
+	})
+	want := []string{
+		"--opt", "target=runtime",
+		"--opt", "build-arg:NODE_ENV=production",
+		"--opt", "build-arg:VERSION=1.2.3",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("dockerfileBuildOpts() = %v, want %v", got, want)
+	}
+	if got := dockerfileBuildOpts(&Config{}); len(got) != 0 {
+		t.Fatalf("dockerfileBuildOpts() = %v, want none", got)
+	}
+}
+
 func TestResolveDockerfileFallsBackToRailpack(t *testing.T) {
-	got, err := resolveDockerfile(t.TempDir(), map[string]string{})
+	got, err := resolveDockerfile(t.TempDir(), "", map[string]string{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -387,3 +417,81 @@ func assertMissing(t *testing.T, path string) {
 		t.Fatalf("expected %s to be removed, got err=%v", path, err)
 	}
 }
+
+func TestResolveTargetPlatforms(t *testing.T) {
+	got, err := resolveTargetPlatforms(nil)
+	if err != nil || strings.Join(got, ",") != "linux/amd64" {
+		t.Fatalf("resolveTargetPlatforms(nil) = %v, %v, want linux/amd64", got, err)
+	}
+
+	got, err = resolveTargetPlatforms([]string{"linux/arm64", " linux/amd64", "linux/arm64"})
+	if err != nil || strings.Join(got, ",") != "linux/arm64,linux/amd64" {
+		t.Fatalf("resolveTargetPlatforms() = %v, %v, want every platform once in order", got, err)
+	}
+
+	got, err = resolveTargetPlatforms([]string{"linux/arm/v7", "linux/riscv64"})
+	if err != nil || strings.Join(got, ",") != "linux/arm/v7,linux/riscv64" {
+		t.Fatalf("resolveTargetPlatforms() = %v, %v, want variants and other architectures accepted", got, err)
+	}
+
+	for _, target := range []string{"", "linux", "linux/", "linux/amd64,linux/arm64", "linux/arm/v7/extra"} {
+		if got, err := resolveTargetPlatforms([]string{target}); err == nil {
+			t.Fatalf("resolveTargetPlatforms(%q) = %v, want error", target, got)
+		}
+	}
+}
+
+func TestEmulatedPlatforms(t *testing.T) {
+	got := emulatedPlatforms([]string{"linux/amd64", "linux/arm64"}, "amd64")
+	if strings.Join(got, ",") != "linux/arm64" {
+		t.Fatalf("emulatedPlatforms() = %v, want linux/arm64", got)
+	}
+	if got := emulatedPlatforms([]string{"linux/arm64", "linux/arm/v7"}, "arm64"); strings.Join(got, ",") != "linux/arm/v7" {
+		t.Fatalf("emulatedPlatforms() = %v, want linux/arm/v7", got)
+	}
+}
+
+func TestParseCacheStats(t *testing.T) {
+	output := strings.Join([]string{
+		"#1 [internal] load build definition from Dockerfile",
+		"#1 DONE 0.0s",
+		"#4 [internal] load metadata for docker.io/library/node:22",
+		"#5 importing cache manifest from registry.example/app:buildcache-linux-amd64",
+		"#6 [1/4] FROM docker.io/library/node:22@sha256:abc",
+		"#6 CACHED",
+		"#7 [builder 2/4] COPY package.json .",
+		"#7 CACHED",
+		"#8 [builder 3/4] RUN npm ci",
+		"#8 0.512 added 120 packages",
+		"#8 DONE 9.1s",
+		"#9 [4/4] COPY . .",
+		"#9 DONE 0.2s",
+		"#10 exporting to image",
+		"#10 CACHED",
+	}, "\n")
+
+	got := parseCacheStats(output)
+	if got != (CacheStats{Hits: 2, Steps: 4}) {
+		t.Fatalf("parseCacheStats() = %+v, want 2 of 4 steps cached", got)
+	}
+}
+
+func TestRegistryCacheArgs(t *testing.T) {
+	ref := buildCacheRef("registry.example/app", []string{"linux/amd64", "linux/arm64"})
+	if ref != "registry.example/app:buildcache-linux-amd64-linux-arm64" {
+		t.Fatalf("buildCacheRef() = %q", ref)
+	}
+
+	args := strings.Join(registryCacheArgs(ref, false), " ")
+	for _, want := range []string{
+		"--import-cache type=registry,ref=" + ref + ",registry.insecure=true",
+		"--export-cache type=registry,ref=" + ref + ",mode=max,",
+	} {
+		if !strings.Contains(args, want) {
+			t.Fatalf("registryCacheArgs() = %q, want %q", args, want)
+		}
+	}
+	if strings.Contains(strings.Join(registryCacheArgs(ref, true), " "), "insecure") {
+		t.Fatal("registryCacheArgs() marked a TLS-verified registry insecure")
+	}
+}
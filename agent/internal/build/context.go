@@ -0,0 +1,189 @@
+package build
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// maxExtractedContextBytes bounds the unpacked size of an uploaded context so
+// a small, highly compressed archive cannot fill the build disk.
+const maxExtractedContextBytes = 4 << 30
+
+func (b *Builder) unpackContext(ctx context.Context, config *Config, buildDir string) error {
+	log.Printf("[build:%s] downloading build context", truncateStr(config.BuildID, 8))
+	b.sendLog(config, fmt.Sprintf("Downloading build context %s", truncateStr(config.ContextChecksum, 12)))
+
+	archive, err := config.OpenContext(ctx)
+	if err != nil {
+		return err
+	}
+	defer archive.Close()
+
+	files, err := extractContextArchive(archive, buildDir, config.ContextChecksum)
+	if err != nil {
+		return err
+	}
+	b.sendLog(config, fmt.Sprintf("Extracted build context (%d files)", files))
+	return nil
+}
+
+// extractContextArchive unpacks a gzip tarball into dest and verifies the
+// archive's SHA-256. Entries may not leave dest, either by path or through a
+// previously extracted symlink.
+func extractContextArchive(archive io.Reader, dest, checksum string) (int, error) {
+	hash := sha256.New()
+	hashed := io.TeeReader(archive, hash)
+	gz, err := gzip.NewReader(hashed)
+	if err != nil {
+		return 0, fmt.Errorf("invalid build context archive: %w", err)
+	}
+	defer gz.Close()
+
+	root, err := filepath.Abs(dest)
+	if err != nil {
+		return 0, err
+	}
+	var extracted int64
+	files := 0
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return files, fmt.Errorf("invalid build context archive: %w", err)
+		}
+
+		target, err := contextEntryPath(root, header.Name)
+		if err != nil {
+			return files, err
+		}
+		if target == root {
+			continue
+		}
+		if err := ensureNoSymlinkParents(root, target); err != nil {
+			return files, err
+		}
+
+		switch header.Typeflag {
+		case tar.TypeDir:
+			if err := os.MkdirAll(target, 0755); err != nil {
+				return files, fmt.Errorf("failed to create %s: %w", header.Name, err)
+			}
+		case tar.TypeReg:
+			extracted += header.Size
+			if extracted > maxExtractedContextBytes {
+				return files, fmt.Errorf("build context exceeds %d bytes when extracted", int64(maxExtractedContextBytes))
+			}
+			if err := writeContextFile(target, reader, header); err != nil {
+				return files, err
+			}
+			files++
+		case tar.TypeSymlink:
+			if filepath.IsAbs(header.Linkname) {
+				return files, fmt.Errorf("build context symlink %s has an absolute target", header.Name)
+			}
+			resolved := filepath.Join(filepath.Dir(target), header.Linkname)
+			if !withinDir(root, resolved) || descendsBeforeClimbing(header.Linkname) {
+				return files, fmt.Errorf("build context symlink %s points outside the context", header.Name)
+			}
+			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+				return files, fmt.Errorf("failed to create %s: %w", header.Name, err)
+			}
+			if err := os.Symlink(header.Linkname, target); err != nil {
+				return files, fmt.Errorf("failed to create symlink %s: %w", header.Name, err)
+			}
+		default:
+			return files, fmt.Errorf("build context entry %s has unsupported type %q", header.Name, header.Typeflag)
+		}
+	}
+
+	if _, err := io.Copy(io.Discard, hashed); err != nil {
+		return files, fmt.Errorf("failed to read build context: %w", err)
+	}
+	if actual := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(actual, checksum) {
+		return files, fmt.Errorf("build context checksum %s does not match expected %s", actual, checksum)
+	}
+	return files, nil
+}
+
+func contextEntryPath(root, name string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(name))
+	if filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("build context entry %s escapes the context", name)
+	}
+	return filepath.Join(root, clean), nil
+}
+
+func withinDir(root, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// descendsBeforeClimbing reports whether a symlink target uses ".." after a
+// named component. Leading ".." only climbs the real directories above the
+// link, but a later one could climb out of a symlinked directory, which the
+// lexical check above cannot see.
+func descendsBeforeClimbing(linkname string) bool {
+	descended := false
+	for _, part := range strings.Split(filepath.ToSlash(linkname), "/") {
+		switch part {
+		case "", ".":
+		case "..":
+			if descended {
+				return true
+			}
+		default:
+			descended = true
+		}
+	}
+	return false
+}
+
+func ensureNoSymlinkParents(root, target string) error {
+	rel, err := filepath.Rel(root, filepath.Dir(target))
+	if err != nil || rel == "." {
+		return err
+	}
+	current := root
+	for _, part := range strings.Split(rel, string(filepath.Separator)) {
+		current = filepath.Join(current, part)
+		info, err := os.Lstat(current)
+		if os.IsNotExist(err) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if info.Mode()&os.ModeSymlink != 0 {
+			return fmt.Errorf("build context entry %s is inside a symlink", target[len(root)+1:])
+		}
+	}
+	return nil
+}
+
+func writeContextFile(target string, reader io.Reader, header *tar.Header) error {
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", header.Name, err)
+	}
+	file, err := os.OpenFile(target, os.O_CREATE|os.O_EXCL|os.O_WRONLY, os.FileMode(header.Mode)&0755|0644)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %w", header.Name, err)
+	}
+	if _, err := io.CopyN(file, reader, header.Size); err != nil {
+		file.Close()
+		return fmt.Errorf("failed to write %s: %w", header.Name, err)
+	}
+	return file.Close()
+}
@@ -0,0 +1,114 @@
+package build
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+type contextEntry struct {
+	name     string
+	body     string
+	linkname string
+	typeflag byte
+}
+
+func contextArchive(t *testing.T, entries ...contextEntry) ([]byte, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gz)
+	for _, entry := range entries {
+		typeflag := entry.typeflag
+		if typeflag == 0 {
+			typeflag = tar.TypeReg
+		}
+		header := &tar.Header{Name: entry.name, Typeflag: typeflag, Mode: 0644, Size: int64(len(entry.body)), Linkname: entry.linkname}
+		if typeflag != tar.TypeReg {
+			header.Size = 0
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if typeflag == tar.TypeReg {
+			if _, err := tw.Write([]byte(entry.body)); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(buf.Bytes())
+	return buf.Bytes(), hex.EncodeToString(sum[:])
+}
+
+func TestExtractContextArchive(t *testing.T) {
+	archive, checksum := contextArchive(t,
+		contextEntry{name: "app/", typeflag: tar.TypeDir},
+		contextEntry{name: "app/Dockerfile", body: "FROM scratch\n"},
+		contextEntry{name: "README.md", body: "hello"},
+		contextEntry{name: "app/readme", linkname: "../README.md", typeflag: tar.TypeSymlink},
+	)
+	dest := t.TempDir()
+
+	files, err := extractContextArchive(bytes.NewReader(archive), dest, checksum)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if files != 2 {
+		t.Fatalf("extracted %d files, want 2", files)
+	}
+	data, err := os.ReadFile(filepath.Join(dest, "app", "Dockerfile"))
+	if err != nil || string(data) != "FROM scratch\n" {
+		t.Fatalf("Dockerfile = %q, %v", data, err)
+	}
+	if link, err := os.Readlink(filepath.Join(dest, "app", "readme")); err != nil || link != "../README.md" {
+		t.Fatalf("symlink = %q, %v", link, err)
+	}
+}
+
+func TestExtractContextArchiveRejectsChecksumMismatch(t *testing.T) {
+	archive, _ := contextArchive(t, contextEntry{name: "Dockerfile", body: "FROM scratch\n"})
+
+	_, err := extractContextArchive(bytes.NewReader(archive), t.TempDir(), strings.Repeat("0", 64))
+	if err == nil || !strings.Contains(err.Error(), "does not match expected") {
+		t.Fatalf("expected checksum mismatch, got %v", err)
+	}
+}
+
+func TestExtractContextArchiveRejectsEscapes(t *testing.T) {
+	tests := map[string][]contextEntry{
+		"parent path":       {{name: "../outside", body: "x"}},
+		"absolute symlink":  {{name: "etc", linkname: "/etc", typeflag: tar.TypeSymlink}},
+		"escaping symlink":  {{name: "up", linkname: "../..", typeflag: tar.TypeSymlink}},
+		"chained symlink":   {{name: "a/s", linkname: "..", typeflag: tar.TypeSymlink}, {name: "t", linkname: "a/s/..", typeflag: tar.TypeSymlink}},
+		"write via symlink": {{name: "dir", linkname: ".", typeflag: tar.TypeSymlink}, {name: "dir/file", body: "x"}},
+		"hard link":         {{name: "link", linkname: "file", typeflag: tar.TypeLink}},
+	}
+	for name, entries := range tests {
+		t.Run(name, func(t *testing.T) {
+			archive, checksum := contextArchive(t, entries...)
+			parent := t.TempDir()
+			dest := filepath.Join(parent, "context")
+			if err := os.Mkdir(dest, 0755); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := extractContextArchive(bytes.NewReader(archive), dest, checksum); err == nil {
+				t.Fatal("expected extraction to be rejected")
+			}
+			if _, err := os.Stat(filepath.Join(parent, "outside")); !os.IsNotExist(err) {
+				t.Fatalf("entry escaped the context: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package build
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	GitAuthHTTPSToken = "https_token"
+	GitAuthSSHKey     = "ssh_key"
+
+	defaultGitTokenUsername = "oauth2"
+)
+
+// GitAuth carries decrypted per-service credentials for a generic git host.
+// Nothing here is embedded in the clone URL, so it never reaches git output.
+type GitAuth struct {
+	Type       string
+	Username   string
+	Secret     string
+	KnownHosts string
+}
+
+// gitEnvironment returns the environment for every git command of a clone,
+// writing SSH key material into a private directory beside the build
+// directory. The returned cleanup removes that directory.
+func (b *Builder) gitEnvironment(config *Config) ([]string, func(), error) {
+	env := append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if config.LFS {
+		env = append(env, "GIT_LFS_SKIP_SMUDGE=1")
+	}
+	cleanup := func() {}
+	if config.GitAuth == nil {
+		return env, cleanup, nil
+	}
+
+	switch config.GitAuth.Type {
+	case GitAuthHTTPSToken:
+		header, scope, err := gitTokenHeader(config.CloneURL, config.GitAuth)
+		if err != nil {
+			return nil, cleanup, err
+		}
+		env = append(env,
+			"GIT_CONFIG_COUNT=1",
+			"GIT_CONFIG_KEY_0=http."+scope+".extraHeader",
+			"GIT_CONFIG_VALUE_0="+header,
+		)
+		return env, cleanup, nil
+	case GitAuthSSHKey:
+		authDir := filepath.Join(b.dataDir, "builds", config.BuildID+"-git")
+		if err := os.MkdirAll(authDir, 0700); err != nil {
+			return nil, cleanup, fmt.Errorf("failed to create git auth directory: %w", err)
+		}
+		cleanup = func() { os.RemoveAll(authDir) }
+		command, err := writeSSHCommand(authDir, config.GitAuth)
+		if err != nil {
+			cleanup()
+			return nil, func() {}, err
+		}
+		return append(env, "GIT_SSH_COMMAND="+command), cleanup, nil
+	default:
+		return nil, cleanup, fmt.Errorf("unsupported git authentication %q", config.GitAuth.Type)
+	}
+}
+
+// gitTokenHeader builds a basic auth header scoped to the clone URL's origin,
+// so submodules on the same host authenticate and other hosts never see it.
+func gitTokenHeader(cloneURL string, auth *GitAuth) (string, string, error) {
+	u, err := url.Parse(cloneURL)
+	if err != nil || u.Host == "" {
+		return "", "", fmt.Errorf("invalid git repository URL")
+	}
+	if u.Scheme != "https" {
+		return "", "", fmt.Errorf("git tokens can only be sent to HTTPS repositories")
+	}
+	if u.User != nil {
+		return "", "", fmt.Errorf("git repository URL cannot embed credentials when a token is configured")
+	}
+	if auth.Secret == "" {
+		return "", "", fmt.Errorf("git token is empty")
+	}
+	username := auth.Username
+	if username == "" {
+		username = defaultGitTokenUsername
+	}
+	credentials := base64.StdEncoding.EncodeToString([]byte(username + ":" + auth.Secret))
+	return "Authorization: Basic " + credentials, "https://" + u.Host + "/", nil
+}
+
+func writeSSHCommand(authDir string, auth *GitAuth) (string, error) {
+	key := strings.TrimSpace(auth.Secret)
+	if key == "" {
+		return "", fmt.Errorf("git deploy key is empty")
+	}
+	keyPath := filepath.Join(authDir, "id")
+	if err := os.WriteFile(keyPath, []byte(key+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to write git deploy key: %w", err)
+	}
+	knownHosts := strings.TrimSpace(auth.KnownHosts)
+	if knownHosts == "" {
+		return "", fmt.Errorf("git deploy key has no known hosts; set them with the service's git credentials")
+	}
+	knownHostsPath := filepath.Join(authDir, "known_hosts")
+	if err := os.WriteFile(knownHostsPath, []byte(knownHosts+"\n"), 0600); err != nil {
+		return "", fmt.Errorf("failed to write git known hosts: %w", err)
+	}
+	return strings.Join([]string{
+		"ssh",
+		"-i", shellQuote(keyPath),
+		"-o", "IdentitiesOnly=yes",
+		"-o", "IdentityAgent=none",
+		"-o", "BatchMode=yes",
+		"-o", "UserKnownHostsFile=" + shellQuote(knownHostsPath),
+		"-o", "StrictHostKeyChecking=yes",
+	}, " "), nil
+}
+
+func shellQuote(value string) string {
+	return "'" + strings.ReplaceAll(value, "'", `'\''`) + "'"
+}
+
+func gitCommand(ctx context.Context, env []string, args ...string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Env = env
+	return cmd
+}
+
+// fetchCheckoutExtras initialises submodules and downloads LFS objects for
+// the checked-out commit when the source asks for them.
+func (b *Builder) fetchCheckoutExtras(ctx context.Context, config *Config, buildDir string, env []string) error {
+	if config.Submodules {
+		b.sendLog(config, "Updating submodules")
+		output, err := b.runCommand(gitCommand(ctx, env, "-C", buildDir, "submodule", "update", "--init", "--recursive"), config)
+		if err != nil {
+			return fmt.Errorf("git submodule update failed: %s: %w", output, err)
+		}
+	}
+	if config.LFS {
+		b.sendLog(config, "Fetching Git LFS objects")
+		output, err := b.runCommand(gitCommand(ctx, env, "-C", buildDir, "lfs", "pull"), config)
+		if err != nil {
+			return fmt.Errorf("git lfs pull failed: %s: %w", output, err)
+		}
+		if config.Submodules {
+			output, err := b.runCommand(gitCommand(ctx, env, "-C", buildDir, "submodule", "foreach", "--recursive", "git lfs pull"), config)
+			if err != nil {
+				return fmt.Errorf("git lfs pull in submodules failed: %s: %w", output, err)
+			}
+		}
+	}
+	return nil
+}
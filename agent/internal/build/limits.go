@@ -0,0 +1,169 @@
+package build
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Limits struct {
+	Slots       int
+	QueueSize   int
+	CPUs        float64
+	MemoryBytes int64
+}
+
+const cgroupCPUPeriod = 100000
+
+const buildCgroupName = "techulus-builds"
+
+var cgroupFSRoot = "/sys/fs/cgroup"
+
+var queuedBuildPollInterval = 10 * time.Second
+
+func (l Limits) normalized() Limits {
+	if l.Slots < 1 {
+		l.Slots = 1
+	}
+	if l.QueueSize < 0 {
+		l.QueueSize = 0
+	}
+	return l
+}
+
+func (l Limits) hasResourceLimits() bool {
+	return l.CPUs > 0 || l.MemoryBytes > 0
+}
+
+func (l Limits) Validate() error {
+	if l.Slots < 1 {
+		return fmt.Errorf("build slots must be at least 1")
+	}
+	if l.QueueSize < 0 {
+		return fmt.Errorf("build queue size cannot be negative")
+	}
+	if l.CPUs < 0 {
+		return fmt.Errorf("build CPU limit cannot be negative")
+	}
+	if l.CPUs > 0 && l.CPUs*cgroupCPUPeriod < 1000 {
+		return fmt.Errorf("build CPU limit must be at least 0.01")
+	}
+	if l.MemoryBytes < 0 {
+		return fmt.Errorf("build memory limit cannot be negative")
+	}
+	if l.MemoryBytes > 0 && l.MemoryBytes < 64<<20 {
+		return fmt.Errorf("build memory limit must be at least 64MiB")
+	}
+	return nil
+}
+
+func ParseMemory(raw string) (int64, error) {
+	value := strings.TrimSpace(strings.ToLower(raw))
+	if value == "" || value == "0" {
+		return 0, nil
+	}
+	multiplier := int64(1)
+	for suffix, size := range map[string]int64{"k": 1 << 10, "m": 1 << 20, "g": 1 << 30} {
+		if strings.HasSuffix(value, suffix) {
+			multiplier = size
+			value = strings.TrimSuffix(value, suffix)
+			break
+		}
+	}
+	amount, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || amount < 0 || amount > (1<<62)/multiplier {
+		return 0, fmt.Errorf("invalid memory size %q", raw)
+	}
+	return amount * multiplier, nil
+}
+
+// Capacity is how many builds the agent accepts at once, running and queued.
+func (b *Builder) Capacity() int {
+	return b.limits.Slots + b.limits.QueueSize
+}
+
+func (b *Builder) AcquireSlot(ctx context.Context) (func(), error) {
+	select {
+	case b.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return func() { <-b.slots }, nil
+}
+
+// AcquireQueuedSlot waits for a slot like AcquireSlot but keeps polling
+// checkCancelled, so a build cancelled while queued gives up its place.
+func (b *Builder) AcquireQueuedSlot(ctx context.Context, checkCancelled func() bool) (func(), error) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	go func() {
+		ticker := time.NewTicker(queuedBuildPollInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if checkCancelled() {
+					cancel(fmt.Errorf("build cancelled"))
+					return
+				}
+			}
+		}
+	}()
+	release, err := b.AcquireSlot(ctx)
+	if err != nil {
+		return nil, context.Cause(ctx)
+	}
+	return release, nil
+}
+
+func (b *Builder) TryAcquireSlot() (func(), bool) {
+	select {
+	case b.slots <- struct{}{}:
+		return func() { <-b.slots }, true
+	default:
+		return nil, false
+	}
+}
+
+// createBuildCgroup returns the cgroup parent BuildKit runs the build's steps
+// under, so CPU and memory limits apply to each build independently.
+func (b *Builder) createBuildCgroup(buildID string) (string, func(), error) {
+	if !b.limits.hasResourceLimits() {
+		return "", func() {}, nil
+	}
+	buildCgroupRoot := filepath.Join(cgroupFSRoot, buildCgroupName)
+	if err := os.MkdirAll(buildCgroupRoot, 0755); err != nil {
+		return "", nil, fmt.Errorf("failed to create build cgroup: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(buildCgroupRoot, "cgroup.subtree_control"), []byte("+cpu +memory"), 0644); err != nil {
+		return "", nil, fmt.Errorf("failed to enable build cgroup controllers: %w", err)
+	}
+
+	dir := filepath.Join(buildCgroupRoot, buildID)
+	if err := os.Mkdir(dir, 0755); err != nil && !os.IsExist(err) {
+		return "", nil, fmt.Errorf("failed to create build cgroup: %w", err)
+	}
+	cleanup := func() { os.Remove(dir) }
+
+	if b.limits.CPUs > 0 {
+		quota := int64(b.limits.CPUs * cgroupCPUPeriod)
+		if err := os.WriteFile(filepath.Join(dir, "cpu.max"), []byte(fmt.Sprintf("%d %d", quota, cgroupCPUPeriod)), 0644); err != nil {
+			cleanup()
+			return "", nil, fmt.Errorf("failed to set build CPU limit: %w", err)
+		}
+	}
+	if b.limits.MemoryBytes > 0 {
+		if err := os.WriteFile(filepath.Join(dir, "memory.max"), []byte(strconv.FormatInt(b.limits.MemoryBytes, 10)), 0644); err != nil {
+			cleanup()
+			return "", nil, fmt.Errorf("failed to set build memory limit: %w", err)
+		}
+	}
+
+	return "/" + buildCgroupName + "/" + buildID, cleanup, nil
+}
@@ -0,0 +1,166 @@
+package build
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestParseMemory(t *testing.T) {
+	tests := map[string]int64{
+		"":     0,
+		"0":    0,
+		"1024": 1024,
+		"512m": 512 << 20,
+		" 4G ": 4 << 30,
+		"256k": 256 << 10,
+	}
+	for input, want := range tests {
+		got, err := ParseMemory(input)
+		if err != nil || got != want {
+			t.Fatalf("ParseMemory(%q) = %d, %v, want %d", input, got, err, want)
+		}
+	}
+	for _, input := range []string{"4gb", "-1g", "lots", "1.5g"} {
+		if got, err := ParseMemory(input); err == nil {
+			t.Fatalf("ParseMemory(%q) = %d, want error", input, got)
+		}
+	}
+}
+
+func TestLimitsValidate(t *testing.T) {
+	if err := (Limits{Slots: 2, QueueSize: 4, CPUs: 1.5, MemoryBytes: 1 << 30}).Validate(); err != nil {
+		t.Fatalf("Validate() = %v", err)
+	}
+	for _, limits := range []Limits{
+		{Slots: 0},
+		{Slots: 1, QueueSize: -1},
+		{Slots: 1, CPUs: -1},
+		{Slots: 1, CPUs: 0.001},
+		{Slots: 1, MemoryBytes: 1 << 20},
+	} {
+		if err := limits.Validate(); err == nil {
+			t.Fatalf("Validate(%+v) = nil, want error", limits)
+		}
+	}
+}
+
+func TestBuildSlotsQueueBeyondSlotCount(t *testing.T) {
+	builder := NewBuilder(t.TempDir(), nil, Limits{Slots: 2, QueueSize: 3})
+	if builder.Capacity() != 5 {
+		t.Fatalf("Capacity() = %d, want 5", builder.Capacity())
+	}
+
+	releaseFirst, ok := builder.TryAcquireSlot()
+	if !ok {
+		t.Fatal("first slot was not available")
+	}
+	if _, ok := builder.TryAcquireSlot(); !ok {
+		t.Fatal("second slot was not available")
+	}
+	if _, ok := builder.TryAcquireSlot(); ok {
+		t.Fatal("acquired a third slot with two configured")
+	}
+
+	acquired := make(chan struct{})
+	go func() {
+		release, err := builder.AcquireSlot(context.Background())
+		if err == nil {
+			release()
+		}
+		close(acquired)
+	}()
+	select {
+	case <-acquired:
+		t.Fatal("queued build started while every slot was busy")
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	releaseFirst()
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("queued build did not start after a slot was released")
+	}
+}
+
+func TestQueuedBuildGivesUpItsPlaceWhenCancelled(t *testing.T) {
+	previous := queuedBuildPollInterval
+	queuedBuildPollInterval = time.Millisecond
+	t.Cleanup(func() { queuedBuildPollInterval = previous })
+
+	builder := NewBuilder(t.TempDir(), nil, Limits{Slots: 1, QueueSize: 1})
+	release, ok := builder.TryAcquireSlot()
+	if !ok {
+		t.Fatal("first slot was not available")
+	}
+	defer release()
+
+	var cancelled atomic.Bool
+	done := make(chan error, 1)
+	go func() {
+		_, err := builder.AcquireQueuedSlot(context.Background(), cancelled.Load)
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		t.Fatalf("queued build left the queue early: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	cancelled.Store(true)
+	select {
+	case err := <-done:
+		if err == nil || err.Error() != "build cancelled" {
+			t.Fatalf("AcquireQueuedSlot() error = %v, want build cancelled", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("cancelled build stayed queued")
+	}
+}
+
+func TestCreateBuildCgroupWritesLimits(t *testing.T) {
+	root := t.TempDir()
+	previous := cgroupFSRoot
+	cgroupFSRoot = root
+	t.Cleanup(func() { cgroupFSRoot = previous })
+
+	builder := NewBuilder(t.TempDir(), nil, Limits{Slots: 1, CPUs: 1.5, MemoryBytes: 2 << 30})
+	parent, cleanup, err := builder.createBuildCgroup("build-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parent != "/techulus-builds/build-1" {
+		t.Fatalf("cgroup parent = %q", parent)
+	}
+
+	dir := filepath.Join(root, "techulus-builds", "build-1")
+	for file, want := range map[string]string{
+		"cpu.max":    "150000 100000",
+		"memory.max": "2147483648",
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, file))
+		if err != nil || string(got) != want {
+			t.Fatalf("%s = %q, %v, want %q", file, got, err, want)
+		}
+	}
+
+	for _, file := range []string{"cpu.max", "memory.max"} {
+		os.Remove(filepath.Join(dir, file))
+	}
+	cleanup()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Fatalf("build cgroup was not removed: %v", err)
+	}
+}
+
+func TestCreateBuildCgroupSkippedWithoutLimits(t *testing.T) {
+	parent, cleanup, err := NewBuilder(t.TempDir(), nil, Limits{}).createBuildCgroup("build-1")
+	if err != nil || parent != "" {
+		t.Fatalf("createBuildCgroup() = %q, %v, want no cgroup", parent, err)
+	}
+	cleanup()
+}
@@ -0,0 +1,178 @@
+package build
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"techulus/cloud-agent/internal/paths"
+)
+
+// VulnerabilityCounts is the number of vulnerabilities found in a built image
+// by severity. For multi-platform builds each count is the highest across
+// platforms.
+type VulnerabilityCounts struct {
+	Critical int
+	High     int
+	Medium   int
+	Low      int
+	Unknown  int
+}
+
+type trivyReport struct {
+	Results []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID string `json:"VulnerabilityID"`
+			PkgName         string `json:"PkgName"`
+			Severity        string `json:"Severity"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// SetVulnerabilityDB enables scanning of built images against the Trivy
+// database mirrored into dbDir. An empty dbDir disables scanning.
+func (b *Builder) SetVulnerabilityDB(dbDir string) {
+	b.vulnDB = dbDir
+}
+
+func CheckScannerPrerequisites(dbDir string) error {
+	if _, err := os.Stat(paths.TrivyPath); err != nil {
+		return fmt.Errorf("trivy not found at %s: %w", paths.TrivyPath, err)
+	}
+	dbPath := filepath.Join(dbDir, "db", "trivy.db")
+	if _, err := os.Stat(dbPath); err != nil {
+		return fmt.Errorf("vulnerability database not found at %s: %w", dbPath, err)
+	}
+	return nil
+}
+
+// parseTrivyReport counts the vulnerabilities in a Trivy JSON report by
+// severity. The same vulnerability reported twice for one package is counted
+// once.
+func parseTrivyReport(report []byte) (VulnerabilityCounts, error) {
+	var parsed trivyReport
+	if err := json.Unmarshal(report, &parsed); err != nil {
+		return VulnerabilityCounts{}, fmt.Errorf("failed to parse trivy report: %w", err)
+	}
+
+	var counts VulnerabilityCounts
+	seen := make(map[string]bool)
+	for _, result := range parsed.Results {
+		for _, vulnerability := range result.Vulnerabilities {
+			key := vulnerability.VulnerabilityID + "\x00" + vulnerability.PkgName
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			switch strings.ToUpper(vulnerability.Severity) {
+			case "CRITICAL":
+				counts.Critical++
+			case "HIGH":
+				counts.High++
+			case "MEDIUM":
+				counts.Medium++
+			case "LOW":
+				counts.Low++
+			default:
+				counts.Unknown++
+			}
+		}
+	}
+	return counts, nil
+}
+
+func maxVulnerabilityCounts(a, b VulnerabilityCounts) VulnerabilityCounts {
+	return VulnerabilityCounts{
+		Critical: max(a.Critical, b.Critical),
+		High:     max(a.High, b.High),
+		Medium:   max(a.Medium, b.Medium),
+		Low:      max(a.Low, b.Low),
+		Unknown:  max(a.Unknown, b.Unknown),
+	}
+}
+
+// ScanTarget is a registry image scanned remotely for one platform.
+type ScanTarget struct {
+	Reference       string
+	Platform        string
+	DockerConfigDir string
+	EmptyHome       string
+	TLSVerify       bool
+}
+
+// ScanImage scans an image the agent did not build, such as one a service
+// pulls, against the offline vulnerability database.
+func (b *Builder) ScanImage(ctx context.Context, target ScanTarget) (VulnerabilityCounts, error) {
+	if b.vulnDB == "" {
+		return VulnerabilityCounts{}, fmt.Errorf("no vulnerability database configured on this server")
+	}
+	return b.scan(ctx, target)
+}
+
+func (b *Builder) scanImage(ctx context.Context, config *Config, platform, digest string) (VulnerabilityCounts, error) {
+	return b.scan(ctx, ScanTarget{
+		Reference:       fmt.Sprintf("%s@%s", config.ImageRepository, digest),
+		Platform:        platform,
+		DockerConfigDir: config.DockerConfigDir,
+		EmptyHome:       config.EmptyHome,
+		TLSVerify:       config.TargetTLSVerify,
+	})
+}
+
+func (b *Builder) scan(ctx context.Context, target ScanTarget) (VulnerabilityCounts, error) {
+	args := []string{
+		"image",
+		"--cache-dir", b.vulnDB,
+		"--skip-db-update",
+		"--skip-java-db-update",
+		"--offline-scan",
+		"--scanners", "vuln",
+		"--format", "json",
+		"--quiet",
+		"--image-src", "remote",
+		"--platform", target.Platform,
+	}
+	if !target.TLSVerify {
+		args = append(args, "--insecure")
+	}
+	args = append(args, target.Reference)
+
+	cmd := exec.CommandContext(ctx, paths.TrivyPath, args...)
+	cmd.Env = append(os.Environ(), "DOCKER_CONFIG="+target.DockerConfigDir, "HOME="+target.EmptyHome)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return VulnerabilityCounts{}, fmt.Errorf("trivy scan of %s failed: %w: %s", target.Platform, err, strings.TrimSpace(stderr.String()))
+	}
+	return parseTrivyReport(out)
+}
+
+// recordVulnerabilities scans every platform image of a build. A failed scan
+// leaves config.Vulnerabilities unset so a service with a threshold does not
+// roll out an image nobody scanned.
+func (b *Builder) recordVulnerabilities(ctx context.Context, config *Config, platforms []string, digest string) {
+	if b.vulnDB == "" {
+		return
+	}
+
+	b.sendLog(config, "Scanning image for vulnerabilities...")
+	var total VulnerabilityCounts
+	for _, platform := range platforms {
+		counts, err := b.scanImage(ctx, config, platform, digest)
+		if err != nil {
+			log.Printf("[build:%s] vulnerability scan failed: %v", truncateStr(config.BuildID, 8), err)
+			b.sendLog(config, fmt.Sprintf("Could not scan for vulnerabilities: %v", err))
+			return
+		}
+		b.sendLog(config, fmt.Sprintf("Vulnerabilities in %s: %d critical, %d high, %d medium, %d low, %d unknown", platform, counts.Critical, counts.High, counts.Medium, counts.Low, counts.Unknown))
+		total = maxVulnerabilityCounts(total, counts)
+	}
+	config.Vulnerabilities = &total
+}
@@ -0,0 +1,78 @@
+package build
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseTrivyReport(t *testing.T) {
+	report := `{"SchemaVersion":2,"Results":[
+		{"Target":"alpine","Vulnerabilities":[
+			{"VulnerabilityID":"CVE-2024-0001","PkgName":"openssl","Severity":"CRITICAL"},
+			{"VulnerabilityID":"CVE-2024-0002","PkgName":"openssl","Severity":"HIGH"},
+			{"VulnerabilityID":"CVE-2024-0003","PkgName":"zlib","Severity":"LOW"}
+		]},
+		{"Target":"app/package-lock.json","Vulnerabilities":[
+			{"VulnerabilityID":"CVE-2024-0001","PkgName":"openssl","Severity":"CRITICAL"},
+			{"VulnerabilityID":"GHSA-xxxx","PkgName":"lodash","Severity":"MEDIUM"},
+			{"VulnerabilityID":"CVE-2024-0004","PkgName":"busybox","Severity":"UNKNOWN"}
+		]},
+		{"Target":"clean"}
+	]}`
+
+	got, err := parseTrivyReport([]byte(report))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := VulnerabilityCounts{Critical: 1, High: 1, Medium: 1, Low: 1, Unknown: 1}
+	if got != want {
+		t.Fatalf("parseTrivyReport() = %+v, want %+v", got, want)
+	}
+
+	if _, err := parseTrivyReport([]byte("not json")); err == nil {
+		t.Fatal("expected error for invalid report")
+	}
+}
+
+func TestMaxVulnerabilityCounts(t *testing.T) {
+	got := maxVulnerabilityCounts(
+		VulnerabilityCounts{Critical: 2, High: 1, Low: 7},
+		VulnerabilityCounts{Critical: 1, High: 4, Medium: 3},
+	)
+	want := VulnerabilityCounts{Critical: 2, High: 4, Medium: 3, Low: 7}
+	if got != want {
+		t.Fatalf("maxVulnerabilityCounts() = %+v, want %+v", got, want)
+	}
+}
+
+func TestRecordVulnerabilitiesDisabled(t *testing.T) {
+	builder := NewBuilder(t.TempDir(), nil, Limits{})
+	config := &Config{BuildID: "build-1"}
+	builder.recordVulnerabilities(t.Context(), config, []string{"linux/amd64"}, "sha256:digest")
+	if config.Vulnerabilities != nil {
+		t.Fatalf("expected no scan without a vulnerability database, got %+v", config.Vulnerabilities)
+	}
+}
+
+func TestScanImageRequiresVulnerabilityDB(t *testing.T) {
+	builder := NewBuilder(t.TempDir(), nil, Limits{})
+	_, err := builder.ScanImage(t.Context(), ScanTarget{Reference: "nginx:1.27", Platform: "linux/amd64"})
+	if err == nil || !strings.Contains(err.Error(), "no vulnerability database") {
+		t.Fatalf("ScanImage() error = %v, want missing database", err)
+	}
+}
+
+func TestCheckScannerPrerequisitesMissingDB(t *testing.T) {
+	dir := t.TempDir()
+	if err := CheckScannerPrerequisites(dir); err == nil {
+		t.Fatal("expected error without trivy or database")
+	}
+	if err := os.MkdirAll(filepath.Join(dir, "db"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckScannerPrerequisites(dir); err == nil {
+		t.Fatal("expected error without trivy.db")
+	}
+}
@@ -114,22 +114,8 @@ func Deploy(config *DeployConfig) (*DeployResult, error) {
 
 	image := config.Image
 
-	logFunc("stdout", fmt.Sprintf("Pulling image: %s", image))
-
-	pullCmd := exec.Command("podman", buildPodmanPullArgs(config)...)
-	pullOutput, err := pullCmd.CombinedOutput()
-	if err != nil {
-		logFunc("stderr", fmt.Sprintf("Pull failed: %s", string(pullOutput)))
-		return nil, fmt.Errorf("failed to pull image: %s: %w", string(pullOutput), err)
-	}
-	logFunc("stdout", string(pullOutput))
-
-	for _, vm := range config.VolumeMounts {
-		if err := os.MkdirAll(vm.HostPath, 0755); err != nil {
-			logFunc("stderr", fmt.Sprintf("Failed to create volume directory %s: %s", vm.HostPath, err))
-			return nil, fmt.Errorf("failed to create volume directory %s: %w", vm.HostPath, err)
-		}
-		logFunc("stdout", fmt.Sprintf("Created volume directory: %s", vm.HostPath))
+	if err := prepareContainer(config, logFunc); err != nil {
+		return nil, err
 	}
 
 	args := buildPodmanRunArgs(config, image)
@@ -173,6 +159,27 @@ func Deploy(config *DeployConfig) (*DeployResult, error) {
 	}, nil
 }
 
+func prepareContainer(config *DeployConfig, logFunc BuildLogFunc) error {
+	logFunc("stdout", fmt.Sprintf("Pulling image: %s", config.Image))
+
+	pullCmd := exec.Command("podman", buildPodmanPullArgs(config)...)
+	pullOutput, err := pullCmd.CombinedOutput()
+	if err != nil {
+		logFunc("stderr", fmt.Sprintf("Pull failed: %s", string(pullOutput)))
+		return fmt.Errorf("failed to pull image: %s: %w", string(pullOutput), err)
+	}
+	logFunc("stdout", string(pullOutput))
+
+	for _, vm := range config.VolumeMounts {
+		if err := os.MkdirAll(vm.HostPath, 0755); err != nil {
+			logFunc("stderr", fmt.Sprintf("Failed to create volume directory %s: %s", vm.HostPath, err))
+			return fmt.Errorf("failed to create volume directory %s: %w", vm.HostPath, err)
+		}
+		logFunc("stdout", fmt.Sprintf("Created volume directory: %s", vm.HostPath))
+	}
+	return nil
+}
+
 func buildPodmanPullArgs(config *DeployConfig) []string {
 	return []string{"pull", "--authfile", config.AuthFile, fmt.Sprintf("--tls-verify=%t", config.TLSVerify), config.Image}
 }
@@ -180,11 +187,16 @@ func buildPodmanPullArgs(config *DeployConfig) []string {
 func buildPodmanRunArgs(config *DeployConfig, image string) []string {
 	networkMAC := StableMACAddress(config.IPAddress)
 
+	restartPolicy := "on-failure:5"
+	if config.TaskID != "" {
+		restartPolicy = "no"
+	}
+
 	args := []string{
 		"run", "-d",
 		"--name", config.Name,
 		"--replace",
-		"--restart", "on-failure:5",
+		"--restart", restartPolicy,
 		"--cap-drop", "ALL",
 		"--cap-add", "CHOWN",
 		"--cap-add", "DAC_OVERRIDE",
@@ -198,11 +210,26 @@ func buildPodmanRunArgs(config *DeployConfig, image string) []string {
 		"--log-opt", "max-file=3",
 	}
 
-	args = append(args,
-		"--label", fmt.Sprintf("techulus.service.id=%s", config.ServiceID),
-		"--label", fmt.Sprintf("techulus.service.name=%s", config.ServiceName),
-		"--label", fmt.Sprintf("techulus.deployment.id=%s", config.DeploymentID),
-	)
+	if config.TaskID != "" {
+		// Task containers carry their own labels so List and drift detection
+		// never mistake them for replicas of the service.
+		args = append(args,
+			"--label", fmt.Sprintf("%s=%s", TaskLabel, config.TaskID),
+			"--label", fmt.Sprintf("techulus.task.service.id=%s", config.ServiceID),
+			"--label", fmt.Sprintf("techulus.task.deployment.id=%s", config.DeploymentID),
+		)
+	} else {
+		args = append(args,
+			"--label", fmt.Sprintf("techulus.service.id=%s", config.ServiceID),
+			"--label", fmt.Sprintf("techulus.service.name=%s", config.ServiceName),
+			"--label", fmt.Sprintf("techulus.deployment.id=%s", config.DeploymentID),
+		)
+	}
+	// A verified image runs pinned to its digest; the label keeps the
+	// reference the control plane asked for so drift checks still match it.
+	if config.RequestedImage != "" {
+		args = append(args, "--label", fmt.Sprintf("%s=%s", RequestedImageLabel, config.RequestedImage))
+	}
 	if config.IPAddress != "" {
 		args = append(args, "--network", NetworkName, "--ip", config.IPAddress)
 		if networkMAC != "" {
@@ -295,6 +322,98 @@ func Stop(containerID string) error {
 	return nil
 }
 
+// Checkpoint dumps the running container's process state with CRIU into
+// archive and leaves the container stopped, so a later Restore resumes it
+// warm. Podman keeps no copy of an exported checkpoint; the caller owns the
+// archive and deletes it once it is restored or stale.
+func Checkpoint(containerID, archive string) error {
+	log.Printf("[podman:checkpoint] checkpointing container %s", containerID)
+	cmd := exec.Command("podman", "container", "checkpoint", "--export", archive, containerID)
+	if output, err := cmd.CombinedOutput(); err != nil {
+		os.Remove(archive)
+		return fmt.Errorf("failed to checkpoint container: %s: %w", string(output), err)
+	}
+	log.Printf("[podman:checkpoint] container %s checkpointed successfully", containerID)
+	return nil
+}
+
+// Restore replaces the stopped container with the one checkpointed into
+// archive. Podman imports a checkpoint as a container of its own under the
+// same name, so the stopped container is removed first.
+func Restore(containerID, archive string) error {
+	log.Printf("[podman:restore] restoring container %s", containerID)
+	if output, err := exec.Command("podman", "rm", "-f", containerID).CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to remove container before restore: %s: %w", string(output), err)
+	}
+	output, err := exec.Command("podman", "container", "restore", "--import", archive).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("failed to restore container: %s: %w", string(output), err)
+	}
+	fields := strings.Fields(string(output))
+	if len(fields) == 0 {
+		return fmt.Errorf("podman restore returned no container ID")
+	}
+	restoredID := fields[len(fields)-1]
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	err = retry.WithBackoff(ctx, retry.DeployBackoff, func() (bool, error) {
+		return IsContainerRunning(restoredID)
+	})
+	if err != nil {
+		return fmt.Errorf("container failed to restore: %w", err)
+	}
+
+	log.Printf("[podman:restore] container %s restored successfully as %s", containerID, restoredID)
+	return nil
+}
+
+type checkpointInspect struct {
+	State struct {
+		StartedAt time.Time `json:"StartedAt"`
+	} `json:"State"`
+}
+
+// restorable reports whether a checkpoint archived at archivedAt was taken
+// from the container's latest run. Restoring one taken before a cold start
+// would resume an older process.
+func (c checkpointInspect) restorable(archivedAt time.Time) bool {
+	return !archivedAt.Before(c.State.StartedAt)
+}
+
+func inspectCheckpoint(containerID string) (checkpointInspect, error) {
+	output, err := exec.Command("podman", "inspect", "--format", "json", containerID).Output()
+	if err != nil {
+		return checkpointInspect{}, fmt.Errorf("failed to inspect container: %w", err)
+	}
+	var containers []checkpointInspect
+	if err := json.Unmarshal(output, &containers); err != nil {
+		return checkpointInspect{}, fmt.Errorf("failed to parse container inspect: %w", err)
+	}
+	if len(containers) == 0 {
+		return checkpointInspect{}, fmt.Errorf("container %s not found", containerID)
+	}
+	return containers[0], nil
+}
+
+// CheckpointRestorable reports whether archive holds a checkpoint the stopped
+// container can be restored from.
+func CheckpointRestorable(containerID, archive string) (bool, error) {
+	info, err := os.Stat(archive)
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to stat checkpoint: %w", err)
+	}
+	inspect, err := inspectCheckpoint(containerID)
+	if err != nil {
+		return false, err
+	}
+	return inspect.restorable(info.ModTime()), nil
+}
+
 func ForceRemove(containerID string) error {
 	exists, err := ContainerExists(containerID)
 	if err != nil {
@@ -481,10 +600,14 @@ func List() ([]Container, error) {
 		if len(pc.Names) > 0 {
 			name = pc.Names[0]
 		}
+		image := pc.Image
+		if requested := pc.Labels[RequestedImageLabel]; requested != "" {
+			image = requested
+		}
 		containers[i] = Container{
 			ID:           pc.Id,
 			Name:         name,
-			Image:        pc.Image,
+			Image:        image,
 			State:        pc.State,
 			Created:      pc.Created,
 			Labels:       pc.Labels,
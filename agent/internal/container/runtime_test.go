@@ -1,6 +1,7 @@
 package container
 
 import (
+	"encoding/json"
 	"errors"
 	"net"
 	"os"
@@ -8,6 +9,7 @@ import (
 	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestBuildPodmanPullArgs(t *testing.T) {
@@ -92,6 +94,24 @@ func TestStableMACAddress(t *testing.T) {
 	}
 }
 
+func TestBuildPodmanRunArgsLabelsTheRequestedImageOfAPinnedDigest(t *testing.T) {
+	pinned := "registry.example/app@sha256:" + strings.Repeat("a", 64)
+	args := buildPodmanRunArgs(&DeployConfig{
+		Name:           "svc-dep",
+		Image:          pinned,
+		RequestedImage: "registry.example/app:v1",
+		ServiceID:      "svc",
+		DeploymentID:   "dep",
+	}, pinned)
+
+	if !slices.Contains(args, RequestedImageLabel+"=registry.example/app:v1") {
+		t.Fatalf("args missing the requested image label: %+v", args)
+	}
+	if !slices.Contains(args, pinned) {
+		t.Fatalf("args do not run the pinned image: %+v", args)
+	}
+}
+
 func TestBuildPodmanRunArgsDoesNotPublishStaticIPPortsByDefault(t *testing.T) {
 	args := buildPodmanRunArgs(&DeployConfig{
 		Name:         "svc-dep",
@@ -110,6 +130,63 @@ func TestBuildPodmanRunArgsDoesNotPublishStaticIPPortsByDefault(t *testing.T) {
 	}
 }
 
+func TestBuildPodmanRunArgsLabelsTasksSeparately(t *testing.T) {
+	args := buildPodmanRunArgs(&DeployConfig{
+		Name:         "run-abc",
+		Image:        "docker.io/library/alpine:latest",
+		ServiceID:    "svc",
+		ServiceName:  "api",
+		DeploymentID: "dep",
+		TaskID:       "run-1",
+		StartCommand: "bin/migrate",
+	}, "docker.io/library/alpine:latest")
+
+	for _, want := range []string{"no", TaskLabel + "=run-1", "techulus.task.service.id=svc", "techulus.task.deployment.id=dep"} {
+		if !slices.Contains(args, want) {
+			t.Fatalf("args missing %q: %+v", want, args)
+		}
+	}
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "techulus.service.id=") || arg == "on-failure:5" {
+			t.Fatalf("task args contain replica setting %q: %+v", arg, args)
+		}
+	}
+	if args[len(args)-1] != "bin/migrate" {
+		t.Fatalf("task command = %q, want bin/migrate", args[len(args)-1])
+	}
+}
+
+func TestParseWaitExitCode(t *testing.T) {
+	for output, want := range map[string]int{"0\n": 0, "137\n": 137, "warning\n3\n": 3} {
+		got, err := parseWaitExitCode(output)
+		if err != nil || got != want {
+			t.Fatalf("parseWaitExitCode(%q) = %d, %v; want %d", output, got, err, want)
+		}
+	}
+	if _, err := parseWaitExitCode(""); err == nil {
+		t.Fatal("expected empty output to fail")
+	}
+}
+
+func TestCheckpointRestorableOnlyFromLatestRun(t *testing.T) {
+	archivedAt := time.Date(2026, 1, 1, 0, 5, 0, 0, time.UTC)
+	for name, tc := range map[string]struct {
+		state string
+		want  bool
+	}{
+		"checkpointed":       {`{"StartedAt":"2026-01-01T00:00:00Z"}`, true},
+		"cold started after": {`{"StartedAt":"2026-01-01T00:10:00Z"}`, false},
+	} {
+		var inspect []checkpointInspect
+		if err := json.Unmarshal([]byte(`[{"State":`+tc.state+`}]`), &inspect); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got := inspect[0].restorable(archivedAt); got != tc.want {
+			t.Fatalf("%s: restorable = %v, want %v", name, got, tc.want)
+		}
+	}
+}
+
 func TestEnsurePodmanSocketDoesNotEnableExistingSocket(t *testing.T) {
 	socketPath := testPodmanSocketPath(t)
 	listener, err := net.Listen("unix", socketPath)
@@ -0,0 +1,135 @@
+package container
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const TaskLabel = "techulus.task.id"
+
+// RequestedImageLabel holds the image reference a container was deployed for
+// when it runs an image pinned by digest.
+const RequestedImageLabel = "techulus.image"
+
+var (
+	taskStopTimeout  = 10 * time.Second
+	taskLogDrainTime = 5 * time.Second
+)
+
+// RunTask starts a one-shot container from config, forwards its output to
+// onLog while it runs and removes it once it exits or the timeout passes.
+func RunTask(config *DeployConfig, timeout time.Duration, onLog func(LogEntry)) (TaskResult, error) {
+	if config.TaskID == "" {
+		return TaskResult{}, fmt.Errorf("task ID is required")
+	}
+	logFunc := config.LogFunc
+	if logFunc == nil {
+		logFunc = func(stream string, message string) {}
+	}
+
+	if err := prepareContainer(config, logFunc); err != nil {
+		return TaskResult{}, err
+	}
+
+	logFunc("stdout", fmt.Sprintf("Starting task container: %s", config.Name))
+	output, err := exec.Command("podman", buildPodmanRunArgs(config, config.Image)...).CombinedOutput()
+	if err != nil {
+		logFunc("stderr", fmt.Sprintf("Start failed: %s", string(output)))
+		exec.Command("podman", "rm", "-f", config.Name).Run()
+		return TaskResult{}, fmt.Errorf("failed to run task container: %s: %w", string(output), err)
+	}
+	containerID := strings.TrimSpace(string(output))
+	defer RemoveTask(containerID)
+
+	logCtx, cancelLogs := context.WithCancel(context.Background())
+	defer cancelLogs()
+	entryCh := make(chan LogEntry, 100)
+	errCh := make(chan error, 1)
+	go StreamLogs(logCtx, LogsOptions{ContainerID: containerID, Follow: true, Tail: -1}, entryCh, errCh)
+	logsDone := make(chan struct{})
+	go func() {
+		defer close(logsDone)
+		for entry := range entryCh {
+			onLog(entry)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	exitCode, err := waitTask(ctx, containerID)
+	result := TaskResult{ExitCode: exitCode}
+	if ctx.Err() != nil {
+		logFunc("stderr", fmt.Sprintf("Task exceeded %s, stopping", timeout))
+		stopSeconds := strconv.Itoa(int(taskStopTimeout.Seconds()))
+		if output, stopErr := exec.Command("podman", "stop", "-t", stopSeconds, containerID).CombinedOutput(); stopErr != nil {
+			log.Printf("[task] failed to stop %s: %s: %v", containerID, string(output), stopErr)
+		}
+		result = TaskResult{ExitCode: 124, TimedOut: true}
+		err = nil
+	}
+
+	select {
+	case <-logsDone:
+	case <-time.After(taskLogDrainTime):
+		cancelLogs()
+		<-logsDone
+	}
+	if streamErr := <-errCh; streamErr != nil {
+		log.Printf("[task] error streaming logs from %s: %v", containerID, streamErr)
+	}
+
+	return result, err
+}
+
+func waitTask(ctx context.Context, containerID string) (int, error) {
+	output, err := exec.CommandContext(ctx, "podman", "wait", containerID).CombinedOutput()
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		return 0, fmt.Errorf("failed to wait for task container: %s: %w", string(output), err)
+	}
+	return parseWaitExitCode(string(output))
+}
+
+func parseWaitExitCode(output string) (int, error) {
+	lines := strings.Fields(output)
+	if len(lines) == 0 {
+		return 0, fmt.Errorf("podman wait returned no exit code")
+	}
+	exitCode, err := strconv.Atoi(lines[len(lines)-1])
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse task exit code %q: %w", output, err)
+	}
+	return exitCode, nil
+}
+
+// ListTasks maps the ID of every task container, running or not, to the task
+// it was started for.
+func ListTasks() (map[string]string, error) {
+	output, err := exec.Command("podman", "ps", "-a", "--filter", "label="+TaskLabel, "--format", "json").CombinedOutput()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list task containers: %s: %w", string(output), err)
+	}
+	var podmanContainers []podmanContainer
+	if err := json.Unmarshal(output, &podmanContainers); err != nil {
+		return nil, fmt.Errorf("failed to parse task container list: %w", err)
+	}
+	tasks := make(map[string]string, len(podmanContainers))
+	for _, pc := range podmanContainers {
+		tasks[pc.Id] = pc.Labels[TaskLabel]
+	}
+	return tasks, nil
+}
+
+func RemoveTask(containerID string) {
+	if output, err := exec.Command("podman", "rm", "-f", "-v", containerID).CombinedOutput(); err != nil {
+		log.Printf("[task] failed to remove %s: %s: %v", containerID, string(output), err)
+	}
+}
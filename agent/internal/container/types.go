@@ -28,6 +28,7 @@ type BuildLogFunc func(stream string, message string)
 type DeployConfig struct {
 	Name              string
 	Image             string
+	RequestedImage    string
 	AuthFile          string
 	TLSVerify         bool
 	ServiceID         string
@@ -41,6 +42,7 @@ type DeployConfig struct {
 	Env               map[string]string
 	VolumeMounts      []VolumeMount
 	StartCommand      string
+	TaskID            string
 	CPULimit          *float64
 	MemoryLimitMb     *int
 	LogFunc           BuildLogFunc
@@ -50,6 +52,11 @@ type DeployResult struct {
 	ContainerID string
 }
 
+type TaskResult struct {
+	ExitCode int
+	TimedOut bool
+}
+
 type Container struct {
 	ID           string            `json:"Id"`
 	Name         string            `json:"Name"`
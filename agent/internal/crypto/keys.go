@@ -42,6 +42,21 @@ func (kp *KeyPair) Sign(message []byte) string {
 	return base64.StdEncoding.EncodeToString(sig)
 }
 
+func Verify(publicKeyBase64 string, message []byte, signatureBase64 string) error {
+	publicKey, err := base64.StdEncoding.DecodeString(publicKeyBase64)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid public key")
+	}
+	signature, err := base64.StdEncoding.DecodeString(signatureBase64)
+	if err != nil {
+		return fmt.Errorf("invalid signature encoding: %w", err)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(publicKey), message, signature) {
+		return fmt.Errorf("signature mismatch")
+	}
+	return nil
+}
+
 func (kp *KeyPair) SaveToFile(dir string) error {
 	if err := os.MkdirAll(dir, 0700); err != nil {
 		return fmt.Errorf("failed to create key directory: %w", err)
@@ -87,6 +102,10 @@ func KeyPairExists(dir string) bool {
 	return err == nil
 }
 
+func EncryptSecret(plaintext string, keyHex string) (string, error) {
+	return encryptAESGCM(plaintext, keyHex, nil)
+}
+
 func DecryptSecret(encryptedBase64 string, keyHex string) (string, error) {
 	return decryptAESGCM(encryptedBase64, keyHex, nil)
 }
@@ -96,13 +115,53 @@ func DecryptRegistryCredential(encryptedBase64, keyHex, id, canonicalHost string
 	return decryptAESGCM(encryptedBase64, keyHex, aad)
 }
 
-func decryptAESGCM(encryptedBase64 string, keyHex string, aad []byte) (string, error) {
+func newGCM(keyHex string) (cipher.AEAD, error) {
 	key, err := hex.DecodeString(keyHex)
 	if err != nil {
-		return "", fmt.Errorf("invalid encryption key: %w", err)
+		return nil, fmt.Errorf("invalid encryption key: %w", err)
 	}
 	if len(key) != 32 {
-		return "", fmt.Errorf("encryption key must be 32 bytes")
+		return nil, fmt.Errorf("encryption key must be 32 bytes")
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return gcm, nil
+}
+
+func encryptAESGCM(plaintext string, keyHex string, aad []byte) (string, error) {
+	gcm, err := newGCM(keyHex)
+	if err != nil {
+		return "", err
+	}
+
+	iv := make([]byte, ivLength)
+	if _, err := rand.Read(iv); err != nil {
+		return "", fmt.Errorf("failed to generate IV: %w", err)
+	}
+
+	sealed := gcm.Seal(nil, iv, []byte(plaintext), aad)
+	ciphertext := sealed[:len(sealed)-authTagLength]
+	authTag := sealed[len(sealed)-authTagLength:]
+
+	framed := make([]byte, 0, len(sealed)+ivLength)
+	framed = append(framed, iv...)
+	framed = append(framed, authTag...)
+	framed = append(framed, ciphertext...)
+	return base64.StdEncoding.EncodeToString(framed), nil
+}
+
+func decryptAESGCM(encryptedBase64 string, keyHex string, aad []byte) (string, error) {
+	gcm, err := newGCM(keyHex)
+	if err != nil {
+		return "", err
 	}
 
 	data, err := base64.StdEncoding.DecodeString(encryptedBase64)
@@ -118,16 +177,6 @@ func decryptAESGCM(encryptedBase64 string, keyHex string, aad []byte) (string, e
 	authTag := data[ivLength : ivLength+authTagLength]
 	ciphertext := data[ivLength+authTagLength:]
 
-	block, err := aes.NewCipher(key)
-	if err != nil {
-		return "", fmt.Errorf("failed to create cipher: %w", err)
-	}
-
-	gcm, err := cipher.NewGCM(block)
-	if err != nil {
-		return "", fmt.Errorf("failed to create GCM: %w", err)
-	}
-
 	ciphertextWithTag := append(ciphertext, authTag...)
 	plaintext, err := gcm.Open(nil, iv, ciphertextWithTag, aad)
 	if err != nil {
@@ -27,3 +27,15 @@ func TestDecryptRegistryCredentialAAD(t *testing.T) {
 		t.Fatal("decrypt succeeded with wrong AAD")
 	}
 }
+
+func TestEncryptSecretRoundTrip(t *testing.T) {
+	key := hex.EncodeToString(make([]byte, 32))
+	encrypted, err := EncryptSecret("certificate-key", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := DecryptSecret(encrypted, key)
+	if err != nil || got != "certificate-key" {
+		t.Fatalf("decrypt = %q, %v", got, err)
+	}
+}
@@ -117,22 +117,33 @@ type VolumeMount struct {
 	ContainerPath string `json:"containerPath"`
 }
 
+type SigningIdentity struct {
+	Issuer  string `json:"issuer"`
+	Subject string `json:"subject"`
+}
+
+type ImageSigningPolicy struct {
+	PublicKeys []string          `json:"publicKeys"`
+	Identities []SigningIdentity `json:"identities"`
+}
+
 type ExpectedContainer struct {
-	DeploymentID          string            `json:"deploymentId"`
-	ServiceID             string            `json:"serviceId"`
-	ServiceName           string            `json:"serviceName"`
-	Name                  string            `json:"name"`
-	DesiredState          string            `json:"desiredState"`
-	Image                 string            `json:"image"`
-	IPAddress             string            `json:"ipAddress"`
-	Ports                 []PortMapping     `json:"ports"`
-	PublishLocalPorts     bool              `json:"publishLocalPorts"`
-	Env                   map[string]string `json:"env"`
-	StartCommand          string            `json:"startCommand"`
-	HealthCheck           *HealthCheck      `json:"healthCheck"`
-	Volumes               []VolumeMount     `json:"volumes"`
-	ResourceCPULimit      *float64          `json:"resourceCpuLimit"`
-	ResourceMemoryLimitMb *int              `json:"resourceMemoryLimitMb"`
+	DeploymentID          string              `json:"deploymentId"`
+	ServiceID             string              `json:"serviceId"`
+	ServiceName           string              `json:"serviceName"`
+	Name                  string              `json:"name"`
+	DesiredState          string              `json:"desiredState"`
+	Image                 string              `json:"image"`
+	IPAddress             string              `json:"ipAddress"`
+	Ports                 []PortMapping       `json:"ports"`
+	PublishLocalPorts     bool                `json:"publishLocalPorts"`
+	Env                   map[string]string   `json:"env"`
+	StartCommand          string              `json:"startCommand"`
+	HealthCheck           *HealthCheck        `json:"healthCheck"`
+	Volumes               []VolumeMount       `json:"volumes"`
+	ResourceCPULimit      *float64            `json:"resourceCpuLimit"`
+	ResourceMemoryLimitMb *int                `json:"resourceMemoryLimitMb"`
+	SigningPolicy         *ImageSigningPolicy `json:"signingPolicy"`
 }
 
 type DnsRecord struct {
@@ -154,35 +165,84 @@ type ServerlessUpstream struct {
 }
 
 type ServerlessRoute struct {
-	ServiceID          string               `json:"serviceId"`
-	Domain             string               `json:"domain"`
-	Port               int                  `json:"port"`
-	SleepAfterSeconds  int                  `json:"sleepAfterSeconds"`
-	WakeTimeoutSeconds int                  `json:"wakeTimeoutSeconds"`
-	LocalDeploymentIDs []string             `json:"localDeploymentIds"`
-	Upstreams          []ServerlessUpstream `json:"upstreams"`
+	ServiceID                    string                 `json:"serviceId"`
+	Domain                       string                 `json:"domain"`
+	Port                         int                    `json:"port"`
+	SleepAfterSeconds            int                    `json:"sleepAfterSeconds"`
+	WakeTimeoutSeconds           int                    `json:"wakeTimeoutSeconds"`
+	LocalDeploymentIDs           []string               `json:"localDeploymentIds"`
+	Upstreams                    []ServerlessUpstream   `json:"upstreams"`
+	MaxQueuedRequests            int                    `json:"maxQueuedRequests"`
+	MaxQueueWaitSeconds          int                    `json:"maxQueueWaitSeconds"`
+	Autoscaling                  *ServerlessAutoscaling `json:"autoscaling,omitempty"`
+	RemoteDeployments            []RemoteDeployment     `json:"remoteDeployments,omitempty"`
+	KeepWarm                     []KeepWarmWindow       `json:"keepWarm,omitempty"`
+	StreamIdleTimeoutSeconds     int                    `json:"streamIdleTimeoutSeconds"`
+	MaxConnectionLifetimeSeconds int                    `json:"maxConnectionLifetimeSeconds"`
+	Triggers                     *ServerlessTriggers    `json:"triggers,omitempty"`
+}
+
+type ServerlessTriggers struct {
+	TCP       []ServerlessTCPTrigger `json:"tcp,omitempty"`
+	EventPath string                 `json:"eventPath,omitempty"`
+}
+
+type ServerlessTCPTrigger struct {
+	ListenPort int `json:"listenPort"`
+	Port       int `json:"port"`
+}
+
+type KeepWarmWindow struct {
+	Days           []string `json:"days,omitempty"`
+	Start          string   `json:"start"`
+	End            string   `json:"end"`
+	TimeZone       string   `json:"timeZone,omitempty"`
+	PrewarmSeconds int      `json:"prewarmSeconds,omitempty"`
+}
+
+type RemoteDeployment struct {
+	DeploymentID string `json:"deploymentId"`
+	ServerID     string `json:"serverId"`
+}
+
+type ServerlessAutoscaling struct {
+	TargetConcurrency        int `json:"targetConcurrency"`
+	MinReplicas              int `json:"minReplicas"`
+	MaxReplicas              int `json:"maxReplicas"`
+	ScaleDownCooldownSeconds int `json:"scaleDownCooldownSeconds"`
 }
 
 type TraefikRoute struct {
-	ID        string     `json:"id"`
-	Domain    string     `json:"domain"`
-	Upstreams []Upstream `json:"upstreams"`
-	ServiceId string     `json:"serviceId"`
+	ID         string      `json:"id"`
+	Domain     string      `json:"domain"`
+	Upstreams  []Upstream  `json:"upstreams"`
+	ServiceId  string      `json:"serviceId"`
+	ClientAuth *ClientAuth `json:"clientAuth,omitempty"`
+}
+
+type ClientAuth struct {
+	CABundle string `json:"caBundle,omitempty"`
+	Mode     string `json:"mode,omitempty"`
 }
 
 type TraefikTCPRoute struct {
-	ID             string   `json:"id"`
-	ServiceId      string   `json:"serviceId"`
-	Upstreams      []string `json:"upstreams"`
-	ExternalPort   int      `json:"externalPort"`
-	TLSPassthrough bool     `json:"tlsPassthrough"`
+	ID             string      `json:"id"`
+	ServiceId      string      `json:"serviceId"`
+	Upstreams      []string    `json:"upstreams"`
+	ExternalPort   int         `json:"externalPort"`
+	TLSPassthrough bool        `json:"tlsPassthrough"`
+	TLSTermination bool        `json:"tlsTermination,omitempty"`
+	Domain         string      `json:"domain,omitempty"`
+	ClientAuth     *ClientAuth `json:"clientAuth,omitempty"`
+	ProxyProtocol  int         `json:"proxyProtocol,omitempty"`
 }
 
 type TraefikUDPRoute struct {
-	ID           string   `json:"id"`
-	ServiceId    string   `json:"serviceId"`
-	Upstreams    []string `json:"upstreams"`
-	ExternalPort int      `json:"externalPort"`
+	ID            string   `json:"id"`
+	ServiceId     string   `json:"serviceId"`
+	Upstreams     []string `json:"upstreams"`
+	ExternalPort  int      `json:"externalPort"`
+	ProxyProtocol int      `json:"proxyProtocol,omitempty"`
 }
 
 type Certificate struct {
@@ -196,9 +256,12 @@ type ChallengeRouteConfig struct {
 }
 
 type WireGuardPeer struct {
-	PublicKey  string  `json:"publicKey"`
-	AllowedIPs string  `json:"allowedIps"`
-	Endpoint   *string `json:"endpoint"`
+	PublicKey        string  `json:"publicKey"`
+	AllowedIPs       string  `json:"allowedIps"`
+	Endpoint         *string `json:"endpoint"`
+	ServerID         string  `json:"serverId,omitempty"`
+	WireGuardIP      string  `json:"wireguardIp,omitempty"`
+	SigningPublicKey string  `json:"signingPublicKey,omitempty"`
 }
 
 type ExpectedState struct {
@@ -217,6 +280,7 @@ type ExpectedState struct {
 		UDPRoutes      []TraefikUDPRoute     `json:"udpRoutes"`
 		Certificates   []Certificate         `json:"certificates,omitempty"`
 		ChallengeRoute *ChallengeRouteConfig `json:"challengeRoute,omitempty"`
+		TrustedProxies []string              `json:"trustedProxies,omitempty"`
 	} `json:"traefik"`
 	Wireguard struct {
 		Peers []WireGuardPeer `json:"peers"`
@@ -355,6 +419,25 @@ type StatusReport struct {
 	ContainerHealth         *health.ContainerHealth `json:"containerHealth,omitempty"`
 	CrowdSecHealth          *health.CrowdSecHealth  `json:"crowdsecHealth,omitempty"`
 	AgentHealth             *AgentHealth            `json:"agentHealth,omitempty"`
+	IssuedCertificates      []IssuedCertificate     `json:"issuedCertificates,omitempty"`
+	CertificateIssues       []CertificateIssue      `json:"certificateIssues"`
+}
+
+type CertificateIssue struct {
+	Domain   string   `json:"domain"`
+	Issue    string   `json:"issue"`
+	NotAfter string   `json:"notAfter,omitempty"`
+	SANs     []string `json:"sans,omitempty"`
+	Issuer   string   `json:"issuer,omitempty"`
+	Error    string   `json:"error,omitempty"`
+}
+
+type IssuedCertificate struct {
+	Domain                  string `json:"domain"`
+	Certificate             string `json:"certificate"`
+	EncryptedCertificateKey string `json:"encryptedCertificateKey"`
+	NotAfter                string `json:"notAfter"`
+	IssuedAt                string `json:"issuedAt"`
 }
 
 type CompletedWorkItem struct {
@@ -379,17 +462,35 @@ type CommandWorkItemResult struct {
 
 func (CommandWorkItemResult) isWorkItemResult() {}
 
+type RunWorkItemResult struct {
+	Type     string `json:"type"`
+	ExitCode *int   `json:"exitCode,omitempty"`
+	TimedOut bool   `json:"timedOut,omitempty"`
+}
+
+func (RunWorkItemResult) isWorkItemResult() {}
+
+type ScanImageWorkItemResult struct {
+	Type            string                `json:"type"`
+	Vulnerabilities *BuildVulnerabilities `json:"vulnerabilities,omitempty"`
+}
+
+func (ScanImageWorkItemResult) isWorkItemResult() {}
+
 type ActiveWorkItem struct {
 	ID      string `json:"id"`
 	Attempt int    `json:"attempt"`
 }
 
 type ServerlessTransition struct {
-	ID           string `json:"id,omitempty"`
-	Type         string `json:"type"`
-	DeploymentID string `json:"deploymentId"`
-	ContainerID  string `json:"containerId,omitempty"`
-	Error        string `json:"error,omitempty"`
+	ID             string `json:"id,omitempty"`
+	Type           string `json:"type"`
+	DeploymentID   string `json:"deploymentId"`
+	ServiceID      string `json:"serviceId,omitempty"`
+	ContainerID    string `json:"containerId,omitempty"`
+	Replicas       int    `json:"replicas,omitempty"`
+	ActiveRequests int    `json:"activeRequests,omitempty"`
+	Error          string `json:"error,omitempty"`
 }
 
 type BuildDetails struct {
@@ -403,14 +504,29 @@ type BuildDetails struct {
 		ProjectID     string `json:"projectId"`
 	} `json:"build"`
 	CloneURL        string            `json:"cloneUrl"`
+	GitAuth         *BuildGitAuth     `json:"gitAuth"`
+	Submodules      bool              `json:"submodules"`
+	LFS             bool              `json:"lfs"`
+	ContextURL      string            `json:"contextUrl"`
+	ContextChecksum string            `json:"contextChecksum"`
 	ImageRepository string            `json:"imageRepository"`
 	ImageURI        string            `json:"imageUri"`
 	RootDir         string            `json:"rootDir"`
+	Dockerfile      string            `json:"dockerfile"`
+	Target          string            `json:"target"`
+	BuildArgs       map[string]string `json:"buildArgs"`
 	Secrets         map[string]string `json:"secrets"`
 	TimeoutMinutes  int               `json:"timeoutMinutes"`
 	TargetPlatforms []string          `json:"targetPlatforms"`
 }
 
+type BuildGitAuth struct {
+	Type            string `json:"type"`
+	Username        string `json:"username"`
+	EncryptedSecret string `json:"encryptedSecret"`
+	KnownHosts      string `json:"knownHosts"`
+}
+
 func (c *Client) ClaimBuild(buildID string) (*BuildDetails, error) {
 	req, err := http.NewRequest("POST", c.baseURL+"/api/v1/agent/builds/"+buildID, nil)
 	if err != nil {
@@ -438,8 +554,52 @@ func (c *Client) ClaimBuild(buildID string) (*BuildDetails, error) {
 	return &result, nil
 }
 
-func (c *Client) UpdateBuildStatus(buildID, status, errorMsg, resolvedCommitSha, imageURI string) error {
-	payload := map[string]string{
+// DownloadBuildContext streams an uploaded build context. It is bounded by ctx
+// rather than the client timeout because contexts can take minutes to fetch.
+func (c *Client) DownloadBuildContext(ctx context.Context, contextURL string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+contextURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
+	c.signRequest(req, "")
+
+	client := &http.Client{Transport: c.client.Transport}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download build context: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		return nil, fmt.Errorf("download build context failed with status %d: %s", resp.StatusCode, string(body))
+	}
+	return resp.Body, nil
+}
+
+type BuildCacheStats struct {
+	Hits  int `json:"hits"`
+	Steps int `json:"steps"`
+}
+
+type BuildAttestation struct {
+	Platform         string `json:"platform"`
+	ImageDigest      string `json:"imageDigest"`
+	ManifestDigest   string `json:"manifestDigest"`
+	SBOMDigest       string `json:"sbomDigest,omitempty"`
+	ProvenanceDigest string `json:"provenanceDigest,omitempty"`
+}
+
+type BuildVulnerabilities struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Unknown  int `json:"unknown"`
+}
+
+func (c *Client) UpdateBuildStatus(buildID, status, errorMsg, resolvedCommitSha, imageURI string, cache *BuildCacheStats, attestations []BuildAttestation, vulnerabilities *BuildVulnerabilities) error {
+	payload := map[string]any{
 		"status": status,
 	}
 	if errorMsg != "" {
@@ -451,6 +611,15 @@ func (c *Client) UpdateBuildStatus(buildID, status, errorMsg, resolvedCommitSha,
 	if imageURI != "" {
 		payload["imageUri"] = imageURI
 	}
+	if cache != nil {
+		payload["cache"] = cache
+	}
+	if len(attestations) > 0 {
+		payload["attestations"] = attestations
+	}
+	if vulnerabilities != nil {
+		payload["vulnerabilities"] = vulnerabilities
+	}
 
 	body, err := json.Marshal(payload)
 	if err != nil {
@@ -497,11 +666,12 @@ type ServerlessTransitionResult struct {
 	ID           string `json:"id,omitempty"`
 	Type         string `json:"type,omitempty"`
 	DeploymentID string `json:"deploymentId,omitempty"`
+	ServiceID    string `json:"serviceId,omitempty"`
 	Outcome      string `json:"outcome"`
 	Reason       string `json:"reason,omitempty"`
 }
 
-func (c *Client) ReportStatus(report *StatusReport, completed []CompletedWorkItem, active []ActiveWorkItem, serverlessTransitions []ServerlessTransition) (*StatusResponse, error) {
+func (c *Client) ReportStatus(report *StatusReport, completed []CompletedWorkItem, active []ActiveWorkItem, buildCapacity int, serverlessTransitions []ServerlessTransition) (*StatusResponse, error) {
 	payload := map[string]interface{}{
 		"statusReport": report,
 	}
@@ -511,6 +681,9 @@ func (c *Client) ReportStatus(report *StatusReport, completed []CompletedWorkIte
 	if len(active) > 0 {
 		payload["activeWorkItems"] = active
 	}
+	if buildCapacity > 0 {
+		payload["buildCapacity"] = buildCapacity
+	}
 	if len(serverlessTransitions) > 0 {
 		payload["serverlessTransitions"] = serverlessTransitions
 	}
@@ -1,6 +1,7 @@
 package http
 
 import (
+	"context"
 	"encoding/json"
 	"io"
 	stdhttp "net/http"
@@ -32,7 +33,7 @@ func TestSignedJSONRequests(t *testing.T) {
 	}))
 	defer server.Close()
 	client := NewClient(server.URL, "server-1", keyPair, "")
-	response, err := client.ReportStatus(&StatusReport{}, nil, nil, nil)
+	response, err := client.ReportStatus(&StatusReport{}, nil, nil, 0, nil)
 	if err != nil || !response.OK {
 		t.Fatalf("accepted response was not decoded: response=%+v err=%v", response, err)
 	}
@@ -70,11 +71,19 @@ func TestUpdateBuildStatusImageURI(t *testing.T) {
 	}
 	digestURI := "registry.example/repository@sha256:" + strings.Repeat("a", 64)
 	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
-		var payload map[string]string
+		var payload struct {
+			Status          string                `json:"status"`
+			ImageURI        string                `json:"imageUri"`
+			Cache           *BuildCacheStats      `json:"cache"`
+			Attestations    []BuildAttestation    `json:"attestations"`
+			Vulnerabilities *BuildVulnerabilities `json:"vulnerabilities"`
+		}
 		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
 			t.Error(err)
 		}
-		if payload["status"] != "completed" || payload["imageUri"] != digestURI {
+		if payload.Status != "completed" || payload.ImageURI != digestURI || payload.Cache == nil || *payload.Cache != (BuildCacheStats{Hits: 3, Steps: 5}) ||
+			len(payload.Attestations) != 1 || payload.Attestations[0].Platform != "linux/amd64" || payload.Attestations[0].SBOMDigest != "sha256:sbom" ||
+			payload.Vulnerabilities == nil || *payload.Vulnerabilities != (BuildVulnerabilities{Critical: 1, High: 2}) {
 			t.Errorf("unexpected payload: %#v", payload)
 		}
 		w.WriteHeader(stdhttp.StatusOK)
@@ -82,7 +91,42 @@ func TestUpdateBuildStatusImageURI(t *testing.T) {
 	defer server.Close()
 
 	client := NewClient(server.URL, "server-1", keyPair, "")
-	if err := client.UpdateBuildStatus("build-1", "completed", "", "commit-sha", digestURI); err != nil {
+	if err := client.UpdateBuildStatus("build-1", "completed", "", "commit-sha", digestURI, &BuildCacheStats{Hits: 3, Steps: 5}, []BuildAttestation{{Platform: "linux/amd64", ImageDigest: "sha256:image", ManifestDigest: "sha256:attestation", SBOMDigest: "sha256:sbom"}}, &BuildVulnerabilities{Critical: 1, High: 2}); err != nil {
 		t.Fatal(err)
 	}
 }
+
+func TestDownloadBuildContext(t *testing.T) {
+	keyPair, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(stdhttp.HandlerFunc(func(w stdhttp.ResponseWriter, r *stdhttp.Request) {
+		message := "agent-request:v2\x00" + r.Header.Get("x-timestamp") + "\x00" + r.Method + "\x00" + r.URL.RequestURI() + "\x00"
+		if r.Header.Get("x-signature") != keyPair.Sign([]byte(message)) {
+			t.Error("context request signature not preserved")
+		}
+		if r.URL.Path == "/api/v1/agent/builds/build-1/context" {
+			io.WriteString(w, "archive")
+			return
+		}
+		w.WriteHeader(stdhttp.StatusNotFound)
+		io.WriteString(w, `{"error":"Build context is no longer available"}`)
+	}))
+	defer server.Close()
+	client := NewClient(server.URL, "server-1", keyPair, "")
+
+	body, err := client.DownloadBuildContext(context.Background(), "/api/v1/agent/builds/build-1/context")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(body)
+	body.Close()
+	if err != nil || string(data) != "archive" {
+		t.Fatalf("unexpected context body %q: %v", data, err)
+	}
+
+	if _, err := client.DownloadBuildContext(context.Background(), "/api/v1/agent/builds/build-2/context"); err == nil || !strings.Contains(err.Error(), "status 404") {
+		t.Fatalf("expected missing context error, got %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package http
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"techulus/cloud-agent/internal/crypto"
+)
+
+const peerRequestMaxSkew = time.Minute
+
+// PeerNonces remembers the nonces of verified peer requests for as long as
+// their timestamps are accepted, so a captured request cannot be replayed.
+type PeerNonces struct {
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+func NewPeerNonces() *PeerNonces {
+	return &PeerNonces{seen: map[string]time.Time{}}
+}
+
+func (n *PeerNonces) claim(key string, now time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	for seenKey, expiresAt := range n.seen {
+		if !expiresAt.After(now) {
+			delete(n.seen, seenKey)
+		}
+	}
+	if _, ok := n.seen[key]; ok {
+		return false
+	}
+	n.seen[key] = now.Add(2 * peerRequestMaxSkew)
+	return true
+}
+
+// SignPeerRequest signs an agent-to-agent request with this server's signing
+// key. Peers verify it against the signing key the control plane lists for
+// us in their WireGuard peers.
+func (c *Client) SignPeerRequest(req *http.Request, body []byte) {
+	timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	nonceBytes := make([]byte, 16)
+	rand.Read(nonceBytes)
+	nonce := hex.EncodeToString(nonceBytes)
+	message := peerRequestMessage(timestamp, nonce, req.Method, req.URL.RequestURI(), body)
+
+	req.Header.Set("x-server-id", c.serverID)
+	req.Header.Set("x-timestamp", timestamp)
+	req.Header.Set("x-nonce", nonce)
+	req.Header.Set("x-signature", c.keyPair.Sign(message))
+}
+
+// VerifyPeerRequest checks a signed agent-to-agent request and returns the
+// sending server ID. Each nonce is accepted once.
+func VerifyPeerRequest(req *http.Request, body []byte, peers []WireGuardPeer, nonces *PeerNonces, now time.Time) (string, error) {
+	serverID := req.Header.Get("x-server-id")
+	timestamp := req.Header.Get("x-timestamp")
+	nonce := req.Header.Get("x-nonce")
+	signature := req.Header.Get("x-signature")
+	if serverID == "" || timestamp == "" || nonce == "" || signature == "" {
+		return "", fmt.Errorf("missing signature headers")
+	}
+
+	millis, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return "", fmt.Errorf("invalid timestamp: %w", err)
+	}
+	skew := now.Sub(time.UnixMilli(millis))
+	if skew > peerRequestMaxSkew || skew < -peerRequestMaxSkew {
+		return "", fmt.Errorf("timestamp outside allowed skew")
+	}
+
+	for _, peer := range peers {
+		if peer.ServerID != serverID || peer.SigningPublicKey == "" {
+			continue
+		}
+		message := peerRequestMessage(timestamp, nonce, req.Method, req.URL.RequestURI(), body)
+		if err := crypto.Verify(peer.SigningPublicKey, message, signature); err != nil {
+			return "", err
+		}
+		if !nonces.claim(serverID+"\x00"+nonce, now) {
+			return "", fmt.Errorf("replayed request")
+		}
+		return serverID, nil
+	}
+	return "", fmt.Errorf("unknown peer %s", serverID)
+}
+
+func peerRequestMessage(timestamp, nonce, method, requestURI string, body []byte) []byte {
+	return []byte("peer-request:v2\x00" + timestamp + "\x00" + nonce + "\x00" + method + "\x00" + requestURI + "\x00" + string(body))
+}
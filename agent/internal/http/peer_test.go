@@ -0,0 +1,73 @@
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"testing"
+	"time"
+
+	"techulus/cloud-agent/internal/crypto"
+)
+
+func TestVerifyPeerRequestAcceptsSignedRequest(t *testing.T) {
+	keyPair, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("generate key pair: %v", err)
+	}
+	client := NewClient("https://control.example.com", "server-a", keyPair, "")
+	peers := []WireGuardPeer{{ServerID: "server-a", SigningPublicKey: keyPair.PublicKeyBase64()}}
+	body := []byte(`{"deploymentId":"dep_1"}`)
+
+	req, _ := http.NewRequest(http.MethodPost, "http://10.0.0.2:18082/serverless/wake", bytes.NewReader(body))
+	client.SignPeerRequest(req, body)
+
+	serverID, err := VerifyPeerRequest(req, body, peers, NewPeerNonces(), time.Now())
+	if err != nil {
+		t.Fatalf("VerifyPeerRequest returned error: %v", err)
+	}
+	if serverID != "server-a" {
+		t.Fatalf("serverID = %q, want server-a", serverID)
+	}
+
+	if _, err := VerifyPeerRequest(req, []byte(`{"deploymentId":"dep_2"}`), peers, NewPeerNonces(), time.Now()); err == nil {
+		t.Fatal("expected tampered body to be rejected")
+	}
+	if _, err := VerifyPeerRequest(req, body, peers, NewPeerNonces(), time.Now().Add(2*time.Minute)); err == nil {
+		t.Fatal("expected stale timestamp to be rejected")
+	}
+	if _, err := VerifyPeerRequest(req, body, []WireGuardPeer{{ServerID: "server-b", SigningPublicKey: keyPair.PublicKeyBase64()}}, NewPeerNonces(), time.Now()); err == nil {
+		t.Fatal("expected unknown peer to be rejected")
+	}
+}
+
+func TestVerifyPeerRequestRejectsReplayedNonce(t *testing.T) {
+	keyPair, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("generate key pair: %v", err)
+	}
+	client := NewClient("https://control.example.com", "server-a", keyPair, "")
+	peers := []WireGuardPeer{{ServerID: "server-a", SigningPublicKey: keyPair.PublicKeyBase64()}}
+	body := []byte(`{"deploymentId":"dep_1"}`)
+	req, _ := http.NewRequest(http.MethodPost, "http://10.0.0.2:18082/serverless/wake", bytes.NewReader(body))
+	client.SignPeerRequest(req, body)
+	nonces := NewPeerNonces()
+	now := time.Now()
+
+	if _, err := VerifyPeerRequest(req, body, peers, nonces, now); err != nil {
+		t.Fatalf("first request rejected: %v", err)
+	}
+	if _, err := VerifyPeerRequest(req, body, peers, nonces, now.Add(30*time.Second)); err == nil {
+		t.Fatal("expected replayed request to be rejected")
+	}
+
+	fresh, _ := http.NewRequest(http.MethodPost, "http://10.0.0.2:18082/serverless/wake", bytes.NewReader(body))
+	client.SignPeerRequest(fresh, body)
+	if _, err := VerifyPeerRequest(fresh, body, peers, nonces, now); err != nil {
+		t.Fatalf("fresh request rejected: %v", err)
+	}
+
+	req.Header.Del("x-nonce")
+	if _, err := VerifyPeerRequest(req, body, peers, NewPeerNonces(), now); err == nil {
+		t.Fatal("expected request without nonce to be rejected")
+	}
+}
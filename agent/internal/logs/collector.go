@@ -21,6 +21,7 @@ const (
 type LogEntry struct {
 	EventID      string
 	DeploymentID string
+	RolloutID    string
 	ServiceID    string
 	Stream       string
 	Message      string
@@ -44,6 +45,7 @@ type LogBatch struct {
 
 type ContainerInfo struct {
 	DeploymentID string
+	RolloutID    string
 	ServiceID    string
 	ContainerID  string
 }
@@ -157,21 +159,7 @@ func (c *Collector) collectFromContainer(ctr ContainerInfo) {
 
 	var lastTimestamp time.Time
 	for entry := range entryCh {
-		eventID, err := newLogEventID(time.Now())
-		if err != nil {
-			log.Printf("[logs] failed to identify log from container %s: %v", ctr.ContainerID, err)
-			continue
-		}
-		logEntry := LogEntry{
-			EventID:      eventID,
-			DeploymentID: ctr.DeploymentID,
-			ServiceID:    ctr.ServiceID,
-			Stream:       entry.Stream,
-			Message:      string(entry.Message),
-			Timestamp:    entry.Timestamp.Format(time.RFC3339Nano),
-		}
-
-		c.enqueue(logEntry)
+		c.Record(ctr, entry)
 		lastTimestamp = entry.Timestamp
 	}
 
@@ -184,6 +172,25 @@ func (c *Collector) collectFromContainer(ctr ContainerInfo) {
 	}
 }
 
+// Record queues a single log line from ctr, for containers such as one-off
+// tasks that stream their own output instead of being collected by Collect.
+func (c *Collector) Record(ctr ContainerInfo, entry container.LogEntry) {
+	eventID, err := newLogEventID(time.Now())
+	if err != nil {
+		log.Printf("[logs] failed to identify log from container %s: %v", ctr.ContainerID, err)
+		return
+	}
+	c.enqueue(LogEntry{
+		EventID:      eventID,
+		DeploymentID: ctr.DeploymentID,
+		RolloutID:    ctr.RolloutID,
+		ServiceID:    ctr.ServiceID,
+		Stream:       entry.Stream,
+		Message:      string(entry.Message),
+		Timestamp:    entry.Timestamp.Format(time.RFC3339Nano),
+	})
+}
+
 func (c *Collector) enqueue(entry LogEntry) {
 	c.queueMu.Lock()
 	defer c.queueMu.Unlock()
@@ -209,6 +216,21 @@ func (c *Collector) flush() {
 	c.flushLocked()
 }
 
+// Flush sends queued logs and waits for the send to finish.
+func (c *Collector) Flush() error {
+	c.queueMu.Lock()
+	if len(c.queue) == 0 {
+		c.queueMu.Unlock()
+		return nil
+	}
+	batch := &LogBatch{Logs: make([]LogEntry, len(c.queue))}
+	copy(batch.Logs, c.queue)
+	c.queue = c.queue[:0]
+	c.queueMu.Unlock()
+
+	return c.sendWithRetry(batch)
+}
+
 func (c *Collector) flushLocked() {
 	if len(c.queue) == 0 {
 		return
@@ -68,3 +68,75 @@ func TestVictoriaLogsSenderPreservesEventID(t *testing.T) {
 		t.Fatalf("event_id = %v, want %s", got, eventID)
 	}
 }
+
+func TestVictoriaLogsSenderStoresReleaseOutputAsRolloutLogs(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := NewVictoriaLogsSender(server.URL, "server-1")
+	err := sender.SendLogs(&LogBatch{Logs: []LogEntry{{
+		EventID:   "e1784546100123456789abcdefghijklmnopqrstuvwxyz",
+		RolloutID: "rollout-1",
+		ServiceID: "service-1",
+		Stream:    "stdout",
+		Message:   "migrated 3 tables",
+		Timestamp: "2026-07-20T12:00:00Z",
+	}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var entry map[string]any
+	if err := json.Unmarshal([]byte(strings.TrimSpace(string(body))), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["log_type"] != "rollout" || entry["rollout_id"] != "rollout-1" || entry["stage"] != "release" {
+		t.Fatalf("release output was not stored as a rollout log: %v", entry)
+	}
+	if _, ok := entry["deployment_id"]; ok {
+		t.Fatalf("release output should not carry a deployment ID: %v", entry)
+	}
+}
+
+func TestSendBuildLogsIdentifiesEachLine(t *testing.T) {
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var err error
+		body, err = io.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := NewVictoriaLogsSender(server.URL, "server-1")
+	if err := sender.SendBuildLogs("build-1", "service-1", "project-1", []string{"#1 load", "#2 build"}); err != nil {
+		t.Fatal(err)
+	}
+
+	pattern := regexp.MustCompile(`^e[0-9]{19}[a-z]{26}$`)
+	seen := map[string]bool{}
+	for _, line := range strings.Split(strings.TrimSpace(string(body)), "\n") {
+		var entry map[string]any
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		eventID, _ := entry["event_id"].(string)
+		if !pattern.MatchString(eventID) || seen[eventID] {
+			t.Fatalf("build log line has no unique event ID: %v", entry)
+		}
+		seen[eventID] = true
+	}
+	if len(seen) != 2 {
+		t.Fatalf("sent %d build log lines, want 2", len(seen))
+	}
+}
@@ -84,12 +84,45 @@ type victoriaLogEntry struct {
 	LogType      string `json:"log_type"`
 }
 
+// Release commands are the only containers that log into a rollout, so their
+// lines are stored as rollout logs under the release stage.
+type victoriaRolloutLogEntry struct {
+	Msg       string `json:"_msg"`
+	Time      string `json:"_time"`
+	EventID   string `json:"event_id"`
+	RolloutID string `json:"rollout_id"`
+	ServiceID string `json:"service_id"`
+	ServerID  string `json:"server_id"`
+	Stage     string `json:"stage"`
+	Stream    string `json:"stream"`
+	LogType   string `json:"log_type"`
+}
+
 func (v *VictoriaLogsSender) SendLogs(batch *LogBatch) error {
 	var buf bytes.Buffer
 	for _, l := range batch.Logs {
 		if strings.TrimSpace(l.Message) == "" {
 			continue
 		}
+		if l.RolloutID != "" {
+			data, err := json.Marshal(victoriaRolloutLogEntry{
+				Msg:       l.Message,
+				Time:      l.Timestamp,
+				EventID:   l.EventID,
+				RolloutID: l.RolloutID,
+				ServiceID: l.ServiceID,
+				ServerID:  v.serverID,
+				Stage:     "release",
+				Stream:    l.Stream,
+				LogType:   "rollout",
+			})
+			if err != nil {
+				continue
+			}
+			buf.Write(data)
+			buf.WriteByte('\n')
+			continue
+		}
 		entry := victoriaLogEntry{
 			Msg:          l.Message,
 			Time:         l.Timestamp,
@@ -178,6 +211,7 @@ func (v *VictoriaLogsSender) SendHTTPLogs(logs []HTTPLogEntry) error {
 type victoriaBuildLogEntry struct {
 	Msg       string `json:"_msg"`
 	Time      string `json:"_time"`
+	EventID   string `json:"event_id"`
 	BuildID   string `json:"build_id"`
 	ServiceID string `json:"service_id"`
 	ProjectID string `json:"project_id"`
@@ -205,9 +239,14 @@ func (v *VictoriaLogsSender) SendBuildLogs(buildID, serviceID, projectID string,
 			continue
 		}
 		logTime := baseTime.Add(time.Duration(i) * time.Microsecond)
+		eventID, err := newLogEventID(logTime)
+		if err != nil {
+			return err
+		}
 		entry := victoriaBuildLogEntry{
 			Msg:       msg,
 			Time:      logTime.Format(time.RFC3339Nano),
+			EventID:   eventID,
 			BuildID:   buildID,
 			ServiceID: serviceID,
 			ProjectID: projectID,
@@ -11,6 +11,8 @@ import (
 
 	"techulus/cloud-agent/internal/container"
 	"techulus/cloud-agent/internal/health"
+	"techulus/cloud-agent/internal/serverless"
+	"techulus/cloud-agent/internal/traefik"
 )
 
 type VictoriaMetricsSender struct {
@@ -137,6 +139,59 @@ func (v *VictoriaMetricsSender) SendContainerStats(stats []container.ResourceSta
 	return v.postPrometheusImport(buf.Bytes(), nil)
 }
 
+func (v *VictoriaMetricsSender) SendCertificateExpiry(certs []traefik.CertificateInfo, collectedAt time.Time) error {
+	if len(certs) == 0 {
+		return nil
+	}
+
+	timestampMs := collectedAt.UnixMilli()
+	serverID := escapeLabelValue(v.serverID)
+
+	var buf bytes.Buffer
+	for _, cert := range certs {
+		labels := map[string]string{
+			"domain":    escapeLabelValue(cert.Domain),
+			"server_id": serverID,
+		}
+		writeGaugeWithLabels(&buf, "techulus_certificate_expiry_timestamp_seconds", labels, float64(cert.NotAfter.Unix()), timestampMs)
+	}
+
+	return v.postPrometheusImport(buf.Bytes(), nil)
+}
+
+func (v *VictoriaMetricsSender) SendServerlessStats(stats []serverless.ServiceStats, collectedAt time.Time) error {
+	if len(stats) == 0 {
+		return nil
+	}
+
+	timestampMs := collectedAt.UnixMilli()
+	serverID := escapeLabelValue(v.serverID)
+
+	var buf bytes.Buffer
+	for _, stat := range stats {
+		labels := map[string]string{
+			"service_id": escapeLabelValue(stat.ServiceID),
+			"server_id":  serverID,
+		}
+		writeGaugeWithLabels(&buf, "techulus_serverless_queued_requests", labels, float64(stat.QueuedRequests), timestampMs)
+		writeGaugeWithLabels(&buf, "techulus_serverless_active_requests", labels, float64(stat.ActiveRequests), timestampMs)
+		writeGaugeWithLabels(&buf, "techulus_serverless_open_streams", labels, float64(stat.OpenStreams), timestampMs)
+		writeGaugeWithLabels(&buf, "techulus_serverless_rejected_requests_total", labels, float64(stat.RejectedRequests), timestampMs)
+		for mode, starts := range stat.Starts {
+			modeLabels := map[string]string{
+				"mode":       escapeLabelValue(string(mode)),
+				"service_id": labels["service_id"],
+				"server_id":  serverID,
+			}
+			writeGaugeWithLabels(&buf, "techulus_serverless_cold_starts_total", modeLabels, float64(starts.Count), timestampMs)
+			writeGaugeWithLabels(&buf, "techulus_serverless_cold_start_seconds_sum", modeLabels, starts.Seconds, timestampMs)
+			writeGaugeWithLabels(&buf, "techulus_serverless_last_cold_start_seconds", modeLabels, starts.LastLatency.Seconds(), timestampMs)
+		}
+	}
+
+	return v.postPrometheusImport(buf.Bytes(), nil)
+}
+
 func aggregateContainerStats(stats []container.ResourceStats) []serviceResourceStats {
 	byService := make(map[string]*serviceResourceStats)
 	for _, stat := range stats {
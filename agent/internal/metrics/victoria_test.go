@@ -10,6 +10,8 @@ import (
 
 	"techulus/cloud-agent/internal/container"
 	"techulus/cloud-agent/internal/health"
+	"techulus/cloud-agent/internal/serverless"
+	"techulus/cloud-agent/internal/traefik"
 )
 
 func TestSendSystemStatsPostsPrometheusImport(t *testing.T) {
@@ -216,3 +218,59 @@ func TestSendContainerStatsOmitsInvalidDeploymentMetrics(t *testing.T) {
 		t.Fatalf("invalid aggregate memory metric was emitted:\n%s", gotBody)
 	}
 }
+
+func TestSendCertificateExpiryWritesTimestampPerDomain(t *testing.T) {
+	var gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := NewVictoriaMetricsSender(server.URL, "server-1")
+	err := sender.SendCertificateExpiry([]traefik.CertificateInfo{
+		{Domain: "app.example.com", NotAfter: time.Unix(1_800_000_000, 0)},
+	}, time.UnixMilli(1_700_000_000_000))
+	if err != nil {
+		t.Fatalf("send certificate expiry: %v", err)
+	}
+	if !strings.Contains(gotBody, `techulus_certificate_expiry_timestamp_seconds{domain="app.example.com",server_id="server-1"} 1800000000.000000 1700000000000`) {
+		t.Fatalf("missing certificate expiry metric:\n%s", gotBody)
+	}
+}
+
+func TestSendServerlessStatsWritesQueueAndColdStartMetrics(t *testing.T) {
+	var gotBody string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		gotBody = string(body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sender := NewVictoriaMetricsSender(server.URL, "server-1")
+	err := sender.SendServerlessStats([]serverless.ServiceStats{
+		{
+			ServiceID:      "svc-1",
+			QueuedRequests: 3,
+			Starts: map[serverless.StartMode]serverless.StartStats{
+				serverless.StartModeCold:    {Count: 2, Seconds: 4.5, LastLatency: 1500 * time.Millisecond},
+				serverless.StartModeRestore: {Count: 1, Seconds: 0.2, LastLatency: 200 * time.Millisecond},
+			},
+		},
+	}, time.UnixMilli(1_700_000_000_000))
+	if err != nil {
+		t.Fatalf("send serverless stats: %v", err)
+	}
+	for _, want := range []string{
+		`techulus_serverless_queued_requests{server_id="server-1",service_id="svc-1"} 3.000000 1700000000000`,
+		`techulus_serverless_cold_starts_total{mode="cold",server_id="server-1",service_id="svc-1"} 2.000000 1700000000000`,
+		`techulus_serverless_last_cold_start_seconds{mode="cold",server_id="server-1",service_id="svc-1"} 1.500000 1700000000000`,
+		`techulus_serverless_last_cold_start_seconds{mode="restore",server_id="server-1",service_id="svc-1"} 0.200000 1700000000000`,
+	} {
+		if !strings.Contains(gotBody, want) {
+			t.Fatalf("missing %q in:\n%s", want, gotBody)
+		}
+	}
+}
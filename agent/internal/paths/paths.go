@@ -9,4 +9,6 @@ const (
 	BuildctlPath   = "/usr/local/bin/buildctl"
 	RailpackPath   = "/usr/local/bin/railpack"
 	CranePath      = "/usr/local/bin/crane"
+	TrivyPath      = "/usr/local/bin/trivy"
+	CosignPath     = "/usr/local/bin/cosign"
 )
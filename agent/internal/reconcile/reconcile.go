@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"log"
 	"path/filepath"
+	"time"
 
 	"techulus/cloud-agent/internal/container"
 	"techulus/cloud-agent/internal/crypto"
@@ -26,11 +27,40 @@ func NewReconciler(encryptionKey, dataDir string, registryAuth *registryauth.Man
 }
 
 func (r *Reconciler) Deploy(exp agenthttp.ExpectedContainer) error {
-	snapshot, releaseRegistryAuth, err := r.registryAuth.Acquire()
+	config, release, err := r.deployConfig(exp)
 	if err != nil {
 		return err
 	}
-	defer releaseRegistryAuth()
+	defer release()
+	config.LogFunc = func(stream, message string) { log.Printf("[deploy:%s] %s", stream, message) }
+
+	_, err = container.Deploy(config)
+	return err
+}
+
+// RunTask runs command once in a container built from exp, so one-off jobs
+// get the same image, secrets and volumes as the service's replicas.
+func (r *Reconciler) RunTask(taskID string, exp agenthttp.ExpectedContainer, command string, timeout time.Duration, onLog func(container.LogEntry)) (container.TaskResult, error) {
+	config, release, err := r.deployConfig(exp)
+	if err != nil {
+		return container.TaskResult{}, err
+	}
+	defer release()
+	config.TaskID = taskID
+	config.StartCommand = command
+	config.PortMappings = nil
+	config.PublishLocalPorts = false
+	config.HealthCheck = nil
+	config.LogFunc = func(stream, message string) { log.Printf("[task:%s] %s", stream, message) }
+
+	return container.RunTask(config, timeout, onLog)
+}
+
+func (r *Reconciler) deployConfig(exp agenthttp.ExpectedContainer) (*container.DeployConfig, func(), error) {
+	snapshot, releaseRegistryAuth, err := r.registryAuth.Acquire()
+	if err != nil {
+		return nil, nil, err
+	}
 	portMappings := make([]container.PortMapping, len(exp.Ports))
 	for i, p := range exp.Ports {
 		portMappings[i] = container.PortMapping{
@@ -53,15 +83,32 @@ func (r *Reconciler) Deploy(exp agenthttp.ExpectedContainer) error {
 	decryptedEnv := make(map[string]string)
 	for key, encryptedValue := range exp.Env {
 		if r.encryptionKey == "" {
-			return fmt.Errorf("encryption key not configured, cannot decrypt secret %s", key)
+			releaseRegistryAuth()
+			return nil, nil, fmt.Errorf("encryption key not configured, cannot decrypt secret %s", key)
 		}
 		decrypted, err := crypto.DecryptSecret(encryptedValue, r.encryptionKey)
 		if err != nil {
-			return fmt.Errorf("failed to decrypt secret %s: %w", key, err)
+			releaseRegistryAuth()
+			return nil, nil, fmt.Errorf("failed to decrypt secret %s: %w", key, err)
 		}
 		decryptedEnv[key] = decrypted
 	}
 
+	// A signed image is pulled by the digest cosign verified, so the tag
+	// moving afterwards cannot swap in an unverified image.
+	image := exp.Image
+	requestedImage := ""
+	if exp.SigningPolicy != nil {
+		imageDigest, err := r.verifyImageSignature(exp.Image, exp.SigningPolicy, snapshot)
+		if err != nil {
+			releaseRegistryAuth()
+			return nil, nil, err
+		}
+		log.Printf("[deploy] verified signature of %s (%s)", exp.Image, imageDigest)
+		image = pinImageDigest(exp.Image, imageDigest)
+		requestedImage = exp.Image
+	}
+
 	volumeMounts := make([]container.VolumeMount, len(exp.Volumes))
 	for i, v := range exp.Volumes {
 		volumeMounts[i] = container.VolumeMount{
@@ -71,9 +118,10 @@ func (r *Reconciler) Deploy(exp agenthttp.ExpectedContainer) error {
 		}
 	}
 
-	_, err = container.Deploy(&container.DeployConfig{
+	return &container.DeployConfig{
 		Name:              exp.Name,
-		Image:             exp.Image,
+		Image:             image,
+		RequestedImage:    requestedImage,
 		AuthFile:          snapshot.AuthFile,
 		TLSVerify:         snapshot.TLSVerify(exp.Image),
 		ServiceID:         exp.ServiceID,
@@ -88,8 +136,5 @@ func (r *Reconciler) Deploy(exp agenthttp.ExpectedContainer) error {
 		StartCommand:      exp.StartCommand,
 		CPULimit:          exp.ResourceCPULimit,
 		MemoryLimitMb:     exp.ResourceMemoryLimitMb,
-		LogFunc:           func(stream, message string) { log.Printf("[deploy:%s] %s", stream, message) },
-	})
-
-	return err
+	}, releaseRegistryAuth, nil
 }
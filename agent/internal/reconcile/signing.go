@@ -0,0 +1,159 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	agenthttp "techulus/cloud-agent/internal/http"
+	"techulus/cloud-agent/internal/paths"
+	"techulus/cloud-agent/internal/registryauth"
+)
+
+const signatureVerifyTimeout = 2 * time.Minute
+
+type cosignVerification struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest string `json:"docker-manifest-digest"`
+		} `json:"image"`
+	} `json:"critical"`
+}
+
+// verifyImageSignature checks image against the project's signing policy and
+// returns the manifest digest the signature covers. The image passes when any
+// public key or keyless identity in the policy verifies it.
+func (r *Reconciler) verifyImageSignature(image string, policy *agenthttp.ImageSigningPolicy, snapshot registryauth.Snapshot) (string, error) {
+	if _, err := os.Stat(paths.CosignPath); err != nil {
+		return "", fmt.Errorf("image signature verification failed: cosign not found at %s: %w", paths.CosignPath, err)
+	}
+	tlsVerify := snapshot.TLSVerify(image)
+	env := append(os.Environ(),
+		"DOCKER_CONFIG="+snapshot.DockerConfigDir,
+		"HOME="+snapshot.EmptyHome,
+		"TUF_ROOT="+filepath.Join(r.dataDir, "sigstore"),
+	)
+
+	var failures []error
+	for i, key := range policy.PublicKeys {
+		digest, err := verifyWithPublicKey(image, key, tlsVerify, env)
+		if err == nil {
+			return digest, nil
+		}
+		failures = append(failures, fmt.Errorf("public key %d: %w", i+1, err))
+	}
+	for _, identity := range policy.Identities {
+		digest, err := runCosignVerify(cosignVerifyArgs(image, "", &identity, tlsVerify), env)
+		if err == nil {
+			return digest, nil
+		}
+		failures = append(failures, fmt.Errorf("identity %s (%s): %w", identity.Subject, identity.Issuer, err))
+	}
+	if len(failures) == 0 {
+		return "", fmt.Errorf("image signature verification failed for %s: signing policy has no keys or identities", image)
+	}
+	return "", fmt.Errorf("image signature verification failed for %s: %w", image, errors.Join(failures...))
+}
+
+func verifyWithPublicKey(image, key string, tlsVerify bool, env []string) (string, error) {
+	keyFile, err := os.CreateTemp("", "cosign-*.pub")
+	if err != nil {
+		return "", fmt.Errorf("failed to write public key: %w", err)
+	}
+	defer os.Remove(keyFile.Name())
+	if _, err := keyFile.WriteString(key); err != nil {
+		keyFile.Close()
+		return "", fmt.Errorf("failed to write public key: %w", err)
+	}
+	if err := keyFile.Close(); err != nil {
+		return "", fmt.Errorf("failed to write public key: %w", err)
+	}
+	return runCosignVerify(cosignVerifyArgs(image, keyFile.Name(), nil, tlsVerify), env)
+}
+
+func cosignVerifyArgs(image, keyPath string, identity *agenthttp.SigningIdentity, tlsVerify bool) []string {
+	args := []string{"verify", "--output", "json"}
+	if keyPath != "" {
+		args = append(args, "--key", keyPath)
+	}
+	if identity != nil {
+		args = append(args,
+			"--certificate-identity", identity.Subject,
+			"--certificate-oidc-issuer", identity.Issuer,
+		)
+	}
+	if !tlsVerify {
+		args = append(args, "--allow-insecure-registry", "--allow-http-registry")
+	}
+	return append(args, image)
+}
+
+func runCosignVerify(args, env []string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), signatureVerifyTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, paths.CosignPath, args...)
+	cmd.Env = env
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if message := cosignError(stderr.String()); message != "" {
+			return "", errors.New(message)
+		}
+		return "", err
+	}
+	return parseCosignDigest(out)
+}
+
+// parseCosignDigest reads the manifest digest every verified signature
+// covers from cosign's JSON output.
+// pinImageDigest replaces the tag or digest of image with digest.
+func pinImageDigest(image, digest string) string {
+	repository, _, _ := strings.Cut(image, "@")
+	name := repository[strings.LastIndex(repository, "/")+1:]
+	if tag := strings.LastIndex(name, ":"); tag >= 0 {
+		repository = repository[:len(repository)-len(name)+tag]
+	}
+	return repository + "@" + digest
+}
+
+func parseCosignDigest(output []byte) (string, error) {
+	var verifications []cosignVerification
+	if err := json.Unmarshal(output, &verifications); err != nil {
+		return "", fmt.Errorf("failed to parse cosign output: %w", err)
+	}
+	var digest string
+	for _, verification := range verifications {
+		current := verification.Critical.Image.DockerManifestDigest
+		if !strings.HasPrefix(current, "sha256:") {
+			return "", fmt.Errorf("cosign output has no manifest digest")
+		}
+		if digest != "" && digest != current {
+			return "", fmt.Errorf("verified signatures cover different digests %s and %s", digest, current)
+		}
+		digest = current
+	}
+	if digest == "" {
+		return "", fmt.Errorf("cosign verified no signatures")
+	}
+	return digest, nil
+}
+
+// cosignError picks the "Error:" line out of cosign's stderr, which also
+// carries warnings and a stack-like trailer.
+func cosignError(stderr string) string {
+	lines := strings.Split(strings.TrimSpace(stderr), "\n")
+	for _, line := range lines {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, "Error:") {
+			return line
+		}
+	}
+	return strings.TrimSpace(lines[0])
+}
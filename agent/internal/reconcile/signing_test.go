@@ -0,0 +1,82 @@
+package reconcile
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	agenthttp "techulus/cloud-agent/internal/http"
+)
+
+func TestCosignVerifyArgs(t *testing.T) {
+	image := "registry.example/app:v1"
+	got := cosignVerifyArgs(image, "/tmp/cosign.pub", nil, true)
+	want := []string{"verify", "--output", "json", "--key", "/tmp/cosign.pub", image}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("key args = %v, want %v", got, want)
+	}
+
+	identity := agenthttp.SigningIdentity{
+		Issuer:  "https://token.actions.githubusercontent.com",
+		Subject: "https://github.com/acme/api/.github/workflows/release.yml@refs/heads/main",
+	}
+	got = cosignVerifyArgs(image, "", &identity, false)
+	want = []string{
+		"verify", "--output", "json",
+		"--certificate-identity", identity.Subject,
+		"--certificate-oidc-issuer", identity.Issuer,
+		"--allow-insecure-registry", "--allow-http-registry",
+		image,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("identity args = %v, want %v", got, want)
+	}
+}
+
+func TestParseCosignDigest(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	other := "sha256:" + strings.Repeat("b", 64)
+	signature := func(d string) string {
+		return `{"critical":{"identity":{"docker-reference":"registry.example/app"},"image":{"docker-manifest-digest":"` + d + `"},"type":"cosign container image signature"},"optional":null}`
+	}
+
+	got, err := parseCosignDigest([]byte("[" + signature(digest) + "," + signature(digest) + "]\n"))
+	if err != nil || got != digest {
+		t.Fatalf("parseCosignDigest() = %q, %v; want %q", got, err, digest)
+	}
+
+	for name, output := range map[string]string{
+		"empty":          "[]",
+		"invalid":        "Verification for registry.example/app:v1 --",
+		"missing digest": `[{"critical":{"image":{}}}]`,
+		"mixed digests":  "[" + signature(digest) + "," + signature(other) + "]",
+	} {
+		if _, err := parseCosignDigest([]byte(output)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestCosignError(t *testing.T) {
+	stderr := "WARNING: Skipping tlog verification is an insecure practice\nError: no matching signatures: invalid signature\nmain.go:74: error during command execution: no matching signatures\n"
+	if got := cosignError(stderr); got != "Error: no matching signatures: invalid signature" {
+		t.Fatalf("cosignError() = %q", got)
+	}
+	if got := cosignError("context deadline exceeded\n"); got != "context deadline exceeded" {
+		t.Fatalf("cosignError() = %q", got)
+	}
+}
+
+func TestPinImageDigest(t *testing.T) {
+	digest := "sha256:" + strings.Repeat("a", 64)
+	for image, want := range map[string]string{
+		"registry.example/app:v1":            "registry.example/app@" + digest,
+		"registry.example:5000/team/app:v1":  "registry.example:5000/team/app@" + digest,
+		"registry.example:5000/app":          "registry.example:5000/app@" + digest,
+		"docker.io/library/nginx@sha256:0f0": "docker.io/library/nginx@" + digest,
+	} {
+		if got := pinImageDigest(image, digest); got != want {
+			t.Fatalf("pinImageDigest(%q) = %q, want %q", image, got, want)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+package serverless
+
+import (
+	"log"
+	"time"
+
+	agenthttp "techulus/cloud-agent/internal/http"
+)
+
+const scaleRequestTimeout = 2 * time.Minute
+
+var autoscaleInterval = 5 * time.Second
+
+type scaleState struct {
+	requestedReplicas int
+	requestedAt       time.Time
+	belowSince        time.Time
+	peakDesired       int
+}
+
+func (g *Gateway) evaluateAutoscaling() {
+	state := g.runtime.ExpectedState()
+	if state == nil {
+		return
+	}
+	seenServices := map[string]struct{}{}
+	for i := range state.Serverless.Routes {
+		route := &state.Serverless.Routes[i]
+		if _, seen := seenServices[route.ServiceID]; seen {
+			continue
+		}
+		seenServices[route.ServiceID] = struct{}{}
+		g.evaluateScale(route, time.Now())
+	}
+}
+
+// evaluateScale compares in-flight requests for the route's service against
+// its replica count and asks the control plane for more replicas as soon as
+// load exceeds the target, or fewer once load has stayed below it for the
+// cooldown. Scaling to zero stays with the sleep timer.
+func (g *Gateway) evaluateScale(route *agenthttp.ServerlessRoute, now time.Time) {
+	autoscaling := route.Autoscaling
+	if autoscaling == nil || autoscaling.TargetConcurrency <= 0 {
+		return
+	}
+
+	activity := g.activity(route.ServiceID)
+	activity.mu.Lock()
+	defer activity.mu.Unlock()
+
+	scale := &activity.scale
+	current := routeReplicas(route)
+	if scale.requestedReplicas > 0 && (scale.requestedReplicas == current || now.Sub(scale.requestedAt) >= scaleRequestTimeout) {
+		scale.requestedReplicas = 0
+	}
+	target := current
+	if scale.requestedReplicas > 0 {
+		target = scale.requestedReplicas
+	}
+
+	desired := desiredReplicas(activity.activeRequests, autoscaling)
+	switch {
+	case desired > target:
+		scale.belowSince = time.Time{}
+		g.requestScale(route, scale, "scale_up", current, desired, activity.activeRequests, now)
+	case desired < target:
+		if scale.belowSince.IsZero() {
+			scale.belowSince = now
+			scale.peakDesired = desired
+			return
+		}
+		scale.peakDesired = max(scale.peakDesired, desired)
+		if now.Sub(scale.belowSince) < scaleDownCooldown(autoscaling.ScaleDownCooldownSeconds) {
+			return
+		}
+		replicas := scale.peakDesired
+		scale.belowSince = time.Time{}
+		g.requestScale(route, scale, "scale_down", current, replicas, activity.activeRequests, now)
+	default:
+		scale.belowSince = time.Time{}
+	}
+}
+
+func (g *Gateway) requestScale(route *agenthttp.ServerlessRoute, scale *scaleState, transitionType string, current int, replicas int, activeRequests int, now time.Time) {
+	scale.requestedReplicas = replicas
+	scale.requestedAt = now
+	log.Printf(
+		"[serverless-gateway] %s requested service=%s replicas=%d->%d active=%d",
+		transitionType,
+		route.ServiceID,
+		current,
+		replicas,
+		activeRequests,
+	)
+	g.runtime.QueueServerlessTransition(agenthttp.ServerlessTransition{
+		Type:           transitionType,
+		ServiceID:      route.ServiceID,
+		Replicas:       replicas,
+		ActiveRequests: activeRequests,
+	})
+}
+
+func desiredReplicas(activeRequests int, autoscaling *agenthttp.ServerlessAutoscaling) int {
+	replicas := (activeRequests + autoscaling.TargetConcurrency - 1) / autoscaling.TargetConcurrency
+	replicas = max(replicas, autoscaling.MinReplicas, 1)
+	if autoscaling.MaxReplicas > 0 {
+		replicas = min(replicas, autoscaling.MaxReplicas)
+	}
+	return replicas
+}
+
+func routeReplicas(route *agenthttp.ServerlessRoute) int {
+	deploymentIDs := map[string]struct{}{}
+	for _, deploymentID := range route.LocalDeploymentIDs {
+		deploymentIDs[deploymentID] = struct{}{}
+	}
+	for _, upstream := range route.Upstreams {
+		if upstream.DeploymentID != "" {
+			deploymentIDs[upstream.DeploymentID] = struct{}{}
+		}
+	}
+	return len(deploymentIDs)
+}
+
+func scaleDownCooldown(cooldownSeconds int) time.Duration {
+	cooldown := time.Duration(cooldownSeconds) * time.Second
+	if cooldown <= 0 {
+		return 5 * time.Minute
+	}
+	return cooldown
+}
@@ -0,0 +1,109 @@
+package serverless
+
+import (
+	"testing"
+	"time"
+
+	agenthttp "techulus/cloud-agent/internal/http"
+)
+
+func TestDesiredReplicasClampsToBounds(t *testing.T) {
+	autoscaling := &agenthttp.ServerlessAutoscaling{TargetConcurrency: 10, MinReplicas: 2, MaxReplicas: 4}
+	tests := []struct {
+		active int
+		want   int
+	}{
+		{active: 0, want: 2},
+		{active: 15, want: 2},
+		{active: 21, want: 3},
+		{active: 100, want: 4},
+	}
+	for _, tt := range tests {
+		if got := desiredReplicas(tt.active, autoscaling); got != tt.want {
+			t.Errorf("desiredReplicas(%d) = %d, want %d", tt.active, got, tt.want)
+		}
+	}
+}
+
+func TestEvaluateScaleRequestsScaleUpOnce(t *testing.T) {
+	state := testAutoscalingState()
+	runtime := &fakeRuntime{state: state}
+	gateway := NewGateway(runtime)
+	route := &state.Serverless.Routes[0]
+	for range 5 {
+		gateway.beginActivity("svc_1")
+	}
+
+	now := time.Now()
+	gateway.evaluateScale(route, now)
+	gateway.evaluateScale(route, now.Add(time.Second))
+
+	transitions, _, _ := runtime.snapshot()
+	if len(transitions) != 1 {
+		t.Fatalf("transitions = %+v, want one scale_up", transitions)
+	}
+	transition := transitions[0]
+	if transition.Type != "scale_up" || transition.ServiceID != "svc_1" || transition.Replicas != 3 || transition.ActiveRequests != 5 {
+		t.Fatalf("transition = %+v, want scale_up to 3 replicas for svc_1", transition)
+	}
+}
+
+func TestEvaluateScaleWaitsForCooldownBeforeScalingDown(t *testing.T) {
+	state := testAutoscalingState()
+	route := &state.Serverless.Routes[0]
+	route.LocalDeploymentIDs = []string{"dep_local", "dep_local_2", "dep_local_3"}
+	runtime := &fakeRuntime{state: state}
+	gateway := NewGateway(runtime)
+	for range 3 {
+		gateway.beginActivity("svc_1")
+	}
+
+	now := time.Now()
+	gateway.evaluateScale(route, now)
+	gateway.evaluateScale(route, now.Add(30*time.Second))
+	if transitions, _, _ := runtime.snapshot(); len(transitions) != 0 {
+		t.Fatalf("transitions = %+v, want none during cooldown", transitions)
+	}
+
+	gateway.evaluateScale(route, now.Add(61*time.Second))
+	transitions, _, _ := runtime.snapshot()
+	if len(transitions) != 1 || transitions[0].Type != "scale_down" || transitions[0].Replicas != 2 {
+		t.Fatalf("transitions = %+v, want scale_down to 2 replicas", transitions)
+	}
+}
+
+func TestEvaluateScaleResetsCooldownWhenLoadReturns(t *testing.T) {
+	state := testAutoscalingState()
+	route := &state.Serverless.Routes[0]
+	route.LocalDeploymentIDs = []string{"dep_local", "dep_local_2"}
+	runtime := &fakeRuntime{state: state}
+	gateway := NewGateway(runtime)
+
+	now := time.Now()
+	gateway.evaluateScale(route, now)
+	for range 4 {
+		gateway.beginActivity("svc_1")
+	}
+	gateway.evaluateScale(route, now.Add(30*time.Second))
+	for range 4 {
+		gateway.endActivity("svc_1", "svc_1", 300)
+	}
+	gateway.evaluateScale(route, now.Add(61*time.Second))
+
+	if transitions, _, _ := runtime.snapshot(); len(transitions) != 0 {
+		t.Fatalf("transitions = %+v, want cooldown restarted by renewed load", transitions)
+	}
+	gateway.stopAllActivities()
+}
+
+func testAutoscalingState() *agenthttp.ExpectedState {
+	state := testExpectedState("running")
+	state.Serverless.Routes[0].Upstreams = nil
+	state.Serverless.Routes[0].Autoscaling = &agenthttp.ServerlessAutoscaling{
+		TargetConcurrency:        2,
+		MinReplicas:              1,
+		MaxReplicas:              3,
+		ScaleDownCooldownSeconds: 60,
+	}
+	return state
+}
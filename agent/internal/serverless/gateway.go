@@ -42,16 +42,25 @@ type Gateway struct {
 	upstreamCache map[string]cachedUpstreams
 	wakeCalls     map[string]*wakeCall
 	activities    map[string]*activityState
+	triggers      triggerState
 }
 
+type StartMode string
+
+const (
+	StartModeCold    StartMode = "cold"
+	StartModeRestore StartMode = "restore"
+)
+
 type Runtime interface {
 	ExpectedState() *agenthttp.ExpectedState
-	DeployServerlessContainer(agenthttp.ExpectedContainer) error
+	DeployServerlessContainer(agenthttp.ExpectedContainer) (StartMode, error)
 	StopServerlessContainer(containerID string) error
 	ListServerlessContainers() ([]container.Container, error)
 	GetServerlessContainerHealth(containerID string) string
 	QueueServerlessTransition(agenthttp.ServerlessTransition)
 	HasPendingServerlessSleep(deploymentID string) bool
+	SignPeerRequest(req *http.Request, body []byte)
 }
 
 type cachedUpstreams struct {
@@ -69,6 +78,12 @@ type activityState struct {
 	mu             sync.Mutex
 	activeRequests int
 	sleepTimer     *time.Timer
+	scale          scaleState
+	streams        map[*streamConn]struct{}
+
+	queuedRequests   int
+	rejectedRequests uint64
+	starts           map[StartMode]StartStats
 }
 
 type upstreamReadiness struct {
@@ -116,14 +131,23 @@ func (g *Gateway) Start(ctx context.Context) error {
 		ReadHeaderTimeout: 30 * time.Second,
 	}
 
+	eventServer := &http.Server{
+		Handler:           http.HandlerFunc(g.serveEvents),
+		ReadHeaderTimeout: 30 * time.Second,
+	}
+
 	go func() {
 		<-ctx.Done()
 		g.stopAllActivities()
+		g.closeTCPTriggers()
 		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 		defer cancel()
 		if err := g.server.Shutdown(shutdownCtx); err != nil {
 			log.Printf("[serverless-gateway] shutdown error: %v", err)
 		}
+		if err := eventServer.Shutdown(shutdownCtx); err != nil {
+			log.Printf("[serverless-trigger] event endpoint shutdown error: %v", err)
+		}
 	}()
 
 	go func() {
@@ -133,8 +157,23 @@ func (g *Gateway) Start(ctx context.Context) error {
 		}
 	}()
 
+	// The event endpoint is optional, so failing to bind it must not take
+	// down HTTP wake.
+	eventAddr := fmt.Sprintf("127.0.0.1:%d", TriggerEventPort)
+	if eventListener, err := net.Listen("tcp", eventAddr); err != nil {
+		log.Printf("[serverless-trigger] event endpoint disabled, failed to listen on %s: %v", eventAddr, err)
+	} else {
+		go func() {
+			log.Printf("[serverless-trigger] event endpoint listening on %s", eventAddr)
+			if err := eventServer.Serve(eventListener); err != nil && err != http.ErrServerClosed {
+				log.Printf("[serverless-trigger] event server error: %v", err)
+			}
+		}()
+	}
+
 	go func() {
 		g.seedIdleTimers()
+		g.reconcileTCPTriggers()
 		ticker := time.NewTicker(idleTimerSeedInterval)
 		defer ticker.Stop()
 		for {
@@ -143,6 +182,20 @@ func (g *Gateway) Start(ctx context.Context) error {
 				return
 			case <-ticker.C:
 				g.seedIdleTimers()
+				g.reconcileTCPTriggers()
+			}
+		}
+	}()
+
+	go func() {
+		ticker := time.NewTicker(autoscaleInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				g.evaluateAutoscaling()
 			}
 		}
 	}()
@@ -164,14 +217,35 @@ func (g *Gateway) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	activityKey := route.ServiceID
-	g.beginActivity(activityKey)
-	defer g.endActivity(activityKey, route.ServiceID, route.SleepAfterSeconds)
-
-	upstreams, err := g.getUpstreams(r.Context(), host)
+	if longLivedRequest(r) {
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		r = r.WithContext(ctx)
+		stream := g.openStream(activityKey, cancel)
+		defer g.closeStream(activityKey, stream)
+		g.scheduleSleepTimer(activityKey, route.ServiceID, route.SleepAfterSeconds)
+		w = &streamResponseWriter{ResponseWriter: w, stream: stream}
+	} else {
+		g.beginActivity(activityKey)
+		defer g.endActivity(activityKey, route.ServiceID, route.SleepAfterSeconds)
+		g.evaluateScale(route, time.Now())
+	}
+
+	upstreams, err := g.queuedUpstreams(r.Context(), route, host)
 	if err != nil {
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 			return
 		}
+		if errors.Is(err, errWakeQueueFull) || errors.Is(err, errWakeQueueTimeout) {
+			log.Printf("[serverless-gateway] request rejected host=%s service=%s reason=%q", host, route.ServiceID, err)
+			status := http.StatusServiceUnavailable
+			if errors.Is(err, errWakeQueueFull) {
+				status = http.StatusTooManyRequests
+			}
+			w.Header().Set("Retry-After", retryAfterSeconds(route))
+			http.Error(w, http.StatusText(status), status)
+			return
+		}
 		log.Printf("[serverless-gateway] wake failed for host %s: %v", host, err)
 		http.Error(w, "service unavailable", http.StatusServiceUnavailable)
 		return
@@ -299,6 +373,9 @@ func (g *Gateway) resolveUpstreams(host string) ([]agenthttp.ServerlessUpstream,
 		if len(ready) > 0 {
 			return ready, nil
 		}
+		if len(route.RemoteDeployments) > 0 {
+			return g.wakeRemote(route, state)
+		}
 		return nil, fmt.Errorf("no ready upstreams for host %s", host)
 	}
 
@@ -311,7 +388,7 @@ func (g *Gateway) resolveUpstreams(host string) ([]agenthttp.ServerlessUpstream,
 			len(ready),
 		)
 		go func() {
-			startedIDs := g.wakeLocalDeployments(route, state, sleepingLocalIDs)
+			startedIDs, _ := g.wakeLocalDeployments(route, state, sleepingLocalIDs)
 			if len(startedIDs) > 0 {
 				g.waitForWokenDeployments(route, route.WakeTimeoutSeconds, wakeStartedAt, startedIDs)
 			}
@@ -326,11 +403,36 @@ func (g *Gateway) resolveUpstreams(host string) ([]agenthttp.ServerlessUpstream,
 		len(ready),
 		wakeTimeout(route.WakeTimeoutSeconds),
 	)
-	startedIDs := g.wakeLocalDeployments(route, state, sleepingLocalIDs)
+	startedIDs, mode := g.wakeLocalDeployments(route, state, sleepingLocalIDs)
 	if len(startedIDs) == 0 {
 		return nil, fmt.Errorf("failed to start local serverless wake")
 	}
-	return g.waitForReadyUpstreams(route, route.WakeTimeoutSeconds, wakeStartedAt, startedIDs)
+	upstreams, err := g.waitForReadyUpstreams(route, route.WakeTimeoutSeconds, wakeStartedAt, startedIDs)
+	if err == nil {
+		g.recordColdStart(route.ServiceID, mode, time.Since(wakeStartedAt))
+	}
+	return upstreams, err
+}
+
+func (g *Gateway) wakeRemote(route *agenthttp.ServerlessRoute, state *agenthttp.ExpectedState) ([]agenthttp.ServerlessUpstream, error) {
+	wakeStartedAt := time.Now()
+	log.Printf(
+		"[serverless-gateway] wake requested host=%s remote_deployments=%d mode=forwarded timeout=%s",
+		route.Domain,
+		len(route.RemoteDeployments),
+		wakeTimeout(route.WakeTimeoutSeconds),
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), wakeTimeout(route.WakeTimeoutSeconds))
+	defer cancel()
+	started, mode := g.wakeRemoteDeployments(ctx, route, state)
+	if len(started) == 0 {
+		return nil, fmt.Errorf("failed to start remote serverless wake")
+	}
+	upstreams, err := waitForRemoteUpstreams(route, started, wakeStartedAt)
+	if err == nil {
+		g.recordColdStart(route.ServiceID, mode, time.Since(wakeStartedAt))
+	}
+	return upstreams, err
 }
 
 func (g *Gateway) inspectUpstreams(route *agenthttp.ServerlessRoute, state *agenthttp.ExpectedState) (upstreamResolution, error) {
@@ -423,9 +525,13 @@ func (g *Gateway) inspectUpstreams(route *agenthttp.ServerlessRoute, state *agen
 	return resolution, nil
 }
 
-func (g *Gateway) wakeLocalDeployments(route *agenthttp.ServerlessRoute, state *agenthttp.ExpectedState, deploymentIDs []string) []string {
+// wakeLocalDeployments starts the given deployments and returns the ones that
+// started. The mode is StartModeRestore only when every deployment was
+// restored from a checkpoint.
+func (g *Gateway) wakeLocalDeployments(route *agenthttp.ServerlessRoute, state *agenthttp.ExpectedState, deploymentIDs []string) ([]string, StartMode) {
 	expectedByDeploymentID := expectedContainersByDeploymentID(state)
 	startedIDs := []string{}
+	wakeMode := StartModeRestore
 	for _, deploymentID := range deploymentIDs {
 		expected, ok := expectedByDeploymentID[deploymentID]
 		if !ok {
@@ -448,7 +554,8 @@ func (g *Gateway) wakeLocalDeployments(route *agenthttp.ServerlessRoute, state *
 			Type:         "wake_started",
 			DeploymentID: deploymentID,
 		})
-		if err := g.runtime.DeployServerlessContainer(expected); err != nil {
+		mode, err := g.runtime.DeployServerlessContainer(expected)
+		if err != nil {
 			log.Printf(
 				"[serverless-gateway] wake failed host=%s deployment=%s service=%s latency=%s error=%v",
 				route.Domain,
@@ -465,16 +572,20 @@ func (g *Gateway) wakeLocalDeployments(route *agenthttp.ServerlessRoute, state *
 			continue
 		}
 		startedIDs = append(startedIDs, deploymentID)
+		if mode != StartModeRestore {
+			wakeMode = StartModeCold
+		}
 		log.Printf(
-			"[serverless-gateway] wake container started host=%s deployment=%s service=%s latency=%s",
+			"[serverless-gateway] wake container started host=%s deployment=%s service=%s mode=%s latency=%s",
 			route.Domain,
 			deploymentID,
 			expected.ServiceID,
+			mode,
 			roundDuration(time.Since(deployStartedAt)),
 		)
 	}
 	g.evictUpstreams(route.Domain)
-	return startedIDs
+	return startedIDs, wakeMode
 }
 
 func (g *Gateway) waitForReadyUpstreams(route *agenthttp.ServerlessRoute, wakeTimeoutSeconds int, startedAt time.Time, wokenDeploymentIDs []string) ([]agenthttp.ServerlessUpstream, error) {
@@ -880,6 +991,7 @@ func (g *Gateway) seedIdleTimers() {
 		}
 		g.scheduleSleepTimer(route.ServiceID, route.ServiceID, route.SleepAfterSeconds)
 	}
+	g.prewarmServices(state, actualByDeploymentID, time.Now())
 }
 
 func (g *Gateway) scheduleSleepTimer(key string, serviceID string, sleepAfterSeconds int) {
@@ -962,6 +1074,21 @@ func (g *Gateway) sleepService(serviceID string) {
 		log.Printf("[serverless-gateway] sleep skipped service=%s reason=missing_route_metadata", serviceID)
 		return
 	}
+	now := time.Now()
+	if until := keepWarmUntil(route, now); !until.IsZero() {
+		log.Printf("[serverless-gateway] sleep skipped service=%s reason=keep_warm until=%s", serviceID, until.Format(time.RFC3339))
+		g.rescheduleSleep(activity, serviceID, until.Sub(now))
+		return
+	}
+	activity.mu.Lock()
+	until := streamsAwakeUntil(activity, route, now)
+	openStreams := len(activity.streams)
+	activity.mu.Unlock()
+	if !until.IsZero() {
+		log.Printf("[serverless-gateway] sleep skipped service=%s reason=active_streams streams=%d until=%s", serviceID, openStreams, until.Format(time.RFC3339))
+		g.rescheduleSleep(activity, serviceID, until.Sub(now))
+		return
+	}
 	localDeploymentIDs := localDeploymentIDsForService(state, serviceID)
 	log.Printf(
 		"[serverless-gateway] sleep timer fired service=%s deployments=%d",
@@ -1044,6 +1171,13 @@ func (g *Gateway) sleepService(serviceID string) {
 				actual.ID,
 			)
 		}
+		if closed := closeStreams(activity); closed > 0 {
+			log.Printf(
+				"[serverless-gateway] sleep closing idle streams service=%s streams=%d",
+				serviceID,
+				closed,
+			)
+		}
 		if err := g.runtime.StopServerlessContainer(actual.ID); err != nil {
 			activity.mu.Unlock()
 			log.Printf(
@@ -1069,6 +1203,17 @@ func (g *Gateway) sleepService(serviceID string) {
 	g.evictServiceUpstreams(serviceID)
 }
 
+func (g *Gateway) rescheduleSleep(activity *activityState, serviceID string, delay time.Duration) {
+	activity.mu.Lock()
+	defer activity.mu.Unlock()
+	if activity.activeRequests > 0 || activity.sleepTimer != nil {
+		return
+	}
+	activity.sleepTimer = time.AfterFunc(delay, func() {
+		g.sleepService(serviceID)
+	})
+}
+
 func (g *Gateway) stopAllActivities() {
 	g.activityMu.Lock()
 	activities := make([]*activityState, 0, len(g.activities))
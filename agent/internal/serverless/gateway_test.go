@@ -37,6 +37,8 @@ type fakeRuntime struct {
 	deployStartedOnce sync.Once
 	allowDeploy       chan struct{}
 	pendingSleeps     map[string]bool
+	restoreStarts     bool
+	signer            *agenthttp.Client
 }
 
 func (f *fakeRuntime) ExpectedState() *agenthttp.ExpectedState {
@@ -45,7 +47,7 @@ func (f *fakeRuntime) ExpectedState() *agenthttp.ExpectedState {
 	return f.state
 }
 
-func (f *fakeRuntime) DeployServerlessContainer(expected agenthttp.ExpectedContainer) error {
+func (f *fakeRuntime) DeployServerlessContainer(expected agenthttp.ExpectedContainer) (StartMode, error) {
 	if f.deployStarted != nil {
 		f.deployStartedOnce.Do(func() {
 			close(f.deployStarted)
@@ -60,7 +62,7 @@ func (f *fakeRuntime) DeployServerlessContainer(expected agenthttp.ExpectedConta
 	for i, actual := range f.containers {
 		if actual.DeploymentID == expected.DeploymentID {
 			f.containers[i].State = "running"
-			return nil
+			return f.startMode(), nil
 		}
 	}
 	f.containers = append(f.containers, container.Container{
@@ -69,7 +71,14 @@ func (f *fakeRuntime) DeployServerlessContainer(expected agenthttp.ExpectedConta
 		DeploymentID: expected.DeploymentID,
 		ServiceID:    expected.ServiceID,
 	})
-	return nil
+	return f.startMode(), nil
+}
+
+func (f *fakeRuntime) startMode() StartMode {
+	if f.restoreStarts {
+		return StartModeRestore
+	}
+	return StartModeCold
 }
 
 func (f *fakeRuntime) StopServerlessContainer(containerID string) error {
@@ -123,6 +132,12 @@ func (f *fakeRuntime) HasPendingServerlessSleep(deploymentID string) bool {
 	return f.pendingSleeps[deploymentID]
 }
 
+func (f *fakeRuntime) SignPeerRequest(req *http.Request, body []byte) {
+	if f.signer != nil {
+		f.signer.SignPeerRequest(req, body)
+	}
+}
+
 func (f *fakeRuntime) snapshot() ([]agenthttp.ServerlessTransition, []string, int) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
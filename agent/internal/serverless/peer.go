@@ -0,0 +1,257 @@
+package serverless
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	agenthttp "techulus/cloud-agent/internal/http"
+)
+
+const (
+	PeerWakePort        = 18082
+	peerWakePath        = "/serverless/wake"
+	peerWakeMaxBodySize = 64 << 10
+)
+
+var peerWakeClient = &http.Client{}
+
+type PeerWakeRequest struct {
+	DeploymentID string `json:"deploymentId"`
+	Port         int    `json:"port"`
+}
+
+type PeerWakeResponse struct {
+	Url  string    `json:"url"`
+	Mode StartMode `json:"mode"`
+}
+
+// PeerWakeServer lets gateways on other servers wake serverless deployments
+// on this one directly over WireGuard instead of waiting for the control
+// plane to schedule the wake.
+type PeerWakeServer struct {
+	runtime Runtime
+	nonces  *agenthttp.PeerNonces
+	server  *http.Server
+}
+
+func NewPeerWakeServer(runtime Runtime) *PeerWakeServer {
+	return &PeerWakeServer{runtime: runtime, nonces: agenthttp.NewPeerNonces()}
+}
+
+func (s *PeerWakeServer) Start(ctx context.Context, address string) error {
+	addr := net.JoinHostPort(address, fmt.Sprintf("%d", PeerWakePort))
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(peerWakePath, s)
+	s.server = &http.Server{
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := s.server.Shutdown(shutdownCtx); err != nil {
+			log.Printf("[serverless-peer] shutdown error: %v", err)
+		}
+	}()
+
+	go func() {
+		log.Printf("[serverless-peer] listening on %s", addr)
+		if err := s.server.Serve(listener); err != nil && err != http.ErrServerClosed {
+			log.Printf("[serverless-peer] server error: %v", err)
+		}
+	}()
+	return nil
+}
+
+func (s *PeerWakeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	body, err := io.ReadAll(io.LimitReader(r.Body, peerWakeMaxBodySize))
+	if err != nil {
+		http.Error(w, "failed to read body", http.StatusBadRequest)
+		return
+	}
+
+	state := s.runtime.ExpectedState()
+	if state == nil {
+		http.Error(w, "expected state not loaded", http.StatusServiceUnavailable)
+		return
+	}
+	serverID, err := agenthttp.VerifyPeerRequest(r, body, state.Wireguard.Peers, s.nonces, time.Now())
+	if err != nil {
+		log.Printf("[serverless-peer] rejected wake request from %s: %v", r.RemoteAddr, err)
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var wake PeerWakeRequest
+	if err := json.Unmarshal(body, &wake); err != nil || wake.DeploymentID == "" || wake.Port <= 0 {
+		http.Error(w, "invalid wake request", http.StatusBadRequest)
+		return
+	}
+	// Peers may only start replicas this server serves through a serverless
+	// route and that the control plane wants running.
+	expected, ok := expectedContainersByDeploymentID(state)[wake.DeploymentID]
+	if !ok || expected.DesiredState != "running" || !servesServerlessRoute(state, wake.DeploymentID) {
+		http.Error(w, "unknown deployment", http.StatusNotFound)
+		return
+	}
+	if expected.IPAddress == "" {
+		http.Error(w, "deployment has no address", http.StatusConflict)
+		return
+	}
+
+	startedAt := time.Now()
+	log.Printf("[serverless-peer] wake starting deployment=%s service=%s requested_by=%s", wake.DeploymentID, expected.ServiceID, serverID)
+	s.runtime.QueueServerlessTransition(agenthttp.ServerlessTransition{
+		Type:         "wake_started",
+		DeploymentID: wake.DeploymentID,
+	})
+	mode, err := s.runtime.DeployServerlessContainer(expected)
+	if err != nil {
+		log.Printf("[serverless-peer] wake failed deployment=%s error=%v", wake.DeploymentID, err)
+		s.runtime.QueueServerlessTransition(agenthttp.ServerlessTransition{
+			Type:         "wake_failed",
+			DeploymentID: wake.DeploymentID,
+			Error:        err.Error(),
+		})
+		http.Error(w, "wake failed", http.StatusBadGateway)
+		return
+	}
+	log.Printf("[serverless-peer] wake container started deployment=%s mode=%s latency=%s", wake.DeploymentID, mode, roundDuration(time.Since(startedAt)))
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(PeerWakeResponse{
+		Url:  net.JoinHostPort(expected.IPAddress, fmt.Sprintf("%d", wake.Port)),
+		Mode: mode,
+	})
+}
+
+func servesServerlessRoute(state *agenthttp.ExpectedState, deploymentID string) bool {
+	for _, route := range state.Serverless.Routes {
+		if slices.Contains(route.LocalDeploymentIDs, deploymentID) {
+			return true
+		}
+	}
+	return false
+}
+
+// wakeRemoteDeployments asks the peer agents hosting the route's remote
+// deployments to start them and returns the upstreams they report.
+func (g *Gateway) wakeRemoteDeployments(ctx context.Context, route *agenthttp.ServerlessRoute, state *agenthttp.ExpectedState) ([]agenthttp.ServerlessUpstream, StartMode) {
+	peersByServerID := map[string]agenthttp.WireGuardPeer{}
+	for _, peer := range state.Wireguard.Peers {
+		if peer.ServerID != "" && peer.WireGuardIP != "" {
+			peersByServerID[peer.ServerID] = peer
+		}
+	}
+
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		upstreams []agenthttp.ServerlessUpstream
+		wakeMode  = StartModeRestore
+	)
+	for _, remote := range route.RemoteDeployments {
+		peer, ok := peersByServerID[remote.ServerID]
+		if !ok {
+			log.Printf("[serverless-gateway] remote wake skipped host=%s deployment=%s reason=unknown_peer server=%s", route.Domain, remote.DeploymentID, remote.ServerID)
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response, err := g.sendPeerWake(ctx, peer, PeerWakeRequest{DeploymentID: remote.DeploymentID, Port: route.Port})
+			if err != nil {
+				log.Printf("[serverless-gateway] remote wake failed host=%s deployment=%s server=%s error=%v", route.Domain, remote.DeploymentID, remote.ServerID, err)
+				return
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if response.Mode != StartModeRestore {
+				wakeMode = StartModeCold
+			}
+			upstreams = append(upstreams, agenthttp.ServerlessUpstream{
+				DeploymentID: remote.DeploymentID,
+				ServerID:     remote.ServerID,
+				Url:          response.Url,
+			})
+		}()
+	}
+	wg.Wait()
+	sortUpstreams(upstreams)
+	return upstreams, wakeMode
+}
+
+func (g *Gateway) sendPeerWake(ctx context.Context, peer agenthttp.WireGuardPeer, wake PeerWakeRequest) (PeerWakeResponse, error) {
+	body, err := json.Marshal(wake)
+	if err != nil {
+		return PeerWakeResponse{}, err
+	}
+	url := fmt.Sprintf("http://%s%s", net.JoinHostPort(peer.WireGuardIP, fmt.Sprintf("%d", PeerWakePort)), peerWakePath)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return PeerWakeResponse{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	g.runtime.SignPeerRequest(req, body)
+
+	resp, err := peerWakeClient.Do(req)
+	if err != nil {
+		return PeerWakeResponse{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return PeerWakeResponse{}, fmt.Errorf("peer returned %d: %s", resp.StatusCode, strings.TrimSpace(string(message)))
+	}
+	var response PeerWakeResponse
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return PeerWakeResponse{}, fmt.Errorf("invalid peer response: %w", err)
+	}
+	if response.Url == "" {
+		return PeerWakeResponse{}, fmt.Errorf("peer returned no upstream")
+	}
+	return response, nil
+}
+
+func waitForRemoteUpstreams(route *agenthttp.ServerlessRoute, upstreams []agenthttp.ServerlessUpstream, startedAt time.Time) ([]agenthttp.ServerlessUpstream, error) {
+	deadline := startedAt.Add(wakeTimeout(route.WakeTimeoutSeconds))
+	attempt := 0
+	for {
+		attempt++
+		ready := []agenthttp.ServerlessUpstream{}
+		for _, upstream := range upstreams {
+			if checkUpstreamReady(upstream.Url).ready {
+				ready = append(ready, upstream)
+			}
+		}
+		if len(ready) > 0 {
+			logWakeReady(route, ready, attempt, startedAt)
+			return ready, nil
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for remote serverless wake")
+		}
+		time.Sleep(wakePollInterval)
+	}
+}
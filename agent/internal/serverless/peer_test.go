@@ -0,0 +1,165 @@
+package serverless
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"techulus/cloud-agent/internal/crypto"
+	agenthttp "techulus/cloud-agent/internal/http"
+)
+
+type handlerTransport struct {
+	handler http.Handler
+}
+
+func (t handlerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rec := httptest.NewRecorder()
+	t.handler.ServeHTTP(rec, req)
+	return rec.Result(), nil
+}
+
+func testPeerSigner(t *testing.T, serverID string) (*agenthttp.Client, agenthttp.WireGuardPeer) {
+	t.Helper()
+	keyPair, err := crypto.GenerateKeyPair()
+	if err != nil {
+		t.Fatalf("generate key pair: %v", err)
+	}
+	client := agenthttp.NewClient("https://control.example.com", serverID, keyPair, "")
+	return client, agenthttp.WireGuardPeer{
+		ServerID:         serverID,
+		WireGuardIP:      "10.200.0.1",
+		SigningPublicKey: keyPair.PublicKeyBase64(),
+	}
+}
+
+func testPeerState(proxy agenthttp.WireGuardPeer) *agenthttp.ExpectedState {
+	state := &agenthttp.ExpectedState{
+		Containers: []agenthttp.ExpectedContainer{
+			{DeploymentID: "dep_remote", ServiceID: "svc_1", DesiredState: "running", IPAddress: "10.0.1.5"},
+			{DeploymentID: "dep_stopped", ServiceID: "svc_1", DesiredState: "stopped", IPAddress: "10.0.1.6"},
+			{DeploymentID: "dep_always_on", ServiceID: "svc_2", DesiredState: "running", IPAddress: "10.0.1.7"},
+		},
+	}
+	state.Serverless.Routes = []agenthttp.ServerlessRoute{
+		{ServiceID: "svc_1", Domain: "app.example.com", Port: 3000, LocalDeploymentIDs: []string{"dep_remote", "dep_stopped"}},
+	}
+	state.Wireguard.Peers = []agenthttp.WireGuardPeer{proxy}
+	return state
+}
+
+func TestPeerWakeServerRequiresSignedRequest(t *testing.T) {
+	signer, proxyPeer := testPeerSigner(t, "server-proxy")
+	peerRuntime := &fakeRuntime{state: testPeerState(proxyPeer)}
+	server := NewPeerWakeServer(peerRuntime)
+	body, _ := json.Marshal(PeerWakeRequest{DeploymentID: "dep_remote", Port: 3000})
+
+	unsigned := httptest.NewRequest(http.MethodPost, peerWakePath, bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, unsigned)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("unsigned status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	signed := httptest.NewRequest(http.MethodPost, peerWakePath, bytes.NewReader(body))
+	signer.SignPeerRequest(signed, body)
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, signed)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("signed status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body.String())
+	}
+	var response PeerWakeResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if response.Url != "10.0.1.5:3000" || response.Mode != StartModeCold {
+		t.Fatalf("response = %+v, want cold start at 10.0.1.5:3000", response)
+	}
+
+	replayed := httptest.NewRequest(http.MethodPost, peerWakePath, bytes.NewReader(body))
+	replayed.Header = signed.Header.Clone()
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, replayed)
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("replayed status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+
+	transitions, _, deployCalls := peerRuntime.snapshot()
+	if deployCalls != 1 {
+		t.Fatalf("deployCalls = %d, want 1", deployCalls)
+	}
+	if len(transitions) != 1 || transitions[0].Type != "wake_started" || transitions[0].DeploymentID != "dep_remote" {
+		t.Fatalf("transitions = %+v, want wake_started for dep_remote", transitions)
+	}
+}
+
+func TestPeerWakeServerRejectsDeploymentsOutsideServerlessRoutes(t *testing.T) {
+	signer, proxyPeer := testPeerSigner(t, "server-proxy")
+	peerRuntime := &fakeRuntime{state: testPeerState(proxyPeer)}
+	server := NewPeerWakeServer(peerRuntime)
+
+	body, _ := json.Marshal(PeerWakeRequest{DeploymentID: "dep_always_on", Port: 3000})
+	req := httptest.NewRequest(http.MethodPost, peerWakePath, bytes.NewReader(body))
+	signer.SignPeerRequest(req, body)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if _, _, deployCalls := peerRuntime.snapshot(); deployCalls != 0 {
+		t.Fatalf("deployCalls = %d, want 0", deployCalls)
+	}
+}
+
+func TestPeerWakeServerRejectsDeploymentsDesiredStopped(t *testing.T) {
+	signer, proxyPeer := testPeerSigner(t, "server-proxy")
+	peerRuntime := &fakeRuntime{state: testPeerState(proxyPeer)}
+	server := NewPeerWakeServer(peerRuntime)
+
+	body, _ := json.Marshal(PeerWakeRequest{DeploymentID: "dep_stopped", Port: 3000})
+	req := httptest.NewRequest(http.MethodPost, peerWakePath, bytes.NewReader(body))
+	signer.SignPeerRequest(req, body)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if _, _, deployCalls := peerRuntime.snapshot(); deployCalls != 0 {
+		t.Fatalf("deployCalls = %d, want 0", deployCalls)
+	}
+}
+
+func TestGatewayForwardsWakeToPeerForRemoteOnlyRoute(t *testing.T) {
+	useFastWakePolling(t)
+	signer, proxyPeer := testPeerSigner(t, "server-proxy")
+	peerRuntime := &fakeRuntime{state: testPeerState(proxyPeer)}
+	previous := peerWakeClient
+	peerWakeClient = &http.Client{Transport: handlerTransport{handler: NewPeerWakeServer(peerRuntime)}}
+	t.Cleanup(func() { peerWakeClient = previous })
+
+	state := &agenthttp.ExpectedState{}
+	state.Serverless.Routes = []agenthttp.ServerlessRoute{
+		{
+			ServiceID:          "svc_1",
+			Domain:             "app.example.com",
+			Port:               3000,
+			WakeTimeoutSeconds: 5,
+			RemoteDeployments:  []agenthttp.RemoteDeployment{{DeploymentID: "dep_remote", ServerID: "server-worker"}},
+		},
+	}
+	state.Wireguard.Peers = []agenthttp.WireGuardPeer{{ServerID: "server-worker", WireGuardIP: "10.200.0.2"}}
+	gateway := NewGateway(&fakeRuntime{state: state, signer: signer})
+
+	upstreams, err := gateway.resolveUpstreams("app.example.com")
+	if err != nil {
+		t.Fatalf("resolveUpstreams returned error: %v", err)
+	}
+	if len(upstreams) != 1 || upstreams[0].Url != "10.0.1.5:3000" || upstreams[0].ServerID != "server-worker" {
+		t.Fatalf("upstreams = %+v, want remote dep_remote upstream", upstreams)
+	}
+	if _, _, deployCalls := peerRuntime.snapshot(); deployCalls != 1 {
+		t.Fatalf("peer deployCalls = %d, want 1", deployCalls)
+	}
+}
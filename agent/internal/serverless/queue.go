@@ -0,0 +1,150 @@
+package serverless
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"sort"
+	"strconv"
+	"time"
+
+	agenthttp "techulus/cloud-agent/internal/http"
+)
+
+const (
+	defaultMaxQueuedRequests = 1000
+	wakeRetryAfter           = 5 * time.Second
+)
+
+var (
+	errWakeQueueFull    = errors.New("wake queue full")
+	errWakeQueueTimeout = errors.New("timed out in wake queue")
+)
+
+type ServiceStats struct {
+	ServiceID        string
+	QueuedRequests   int
+	ActiveRequests   int
+	RejectedRequests uint64
+	OpenStreams      int
+	Starts           map[StartMode]StartStats
+}
+
+type StartStats struct {
+	Count       uint64
+	Seconds     float64
+	LastLatency time.Duration
+}
+
+// queuedUpstreams resolves upstreams for a request, holding it in the
+// service's wake queue while the service starts. Requests beyond the queue
+// bound fail fast with errWakeQueueFull, and requests that outlive the
+// route's queue wait fail with errWakeQueueTimeout.
+func (g *Gateway) queuedUpstreams(ctx context.Context, route *agenthttp.ServerlessRoute, host string) ([]agenthttp.ServerlessUpstream, error) {
+	if upstreams, ok := g.cachedUpstreams(host); ok {
+		return upstreams, nil
+	}
+
+	if !g.enterWakeQueue(route) {
+		return nil, errWakeQueueFull
+	}
+	defer g.leaveWakeQueue(route.ServiceID)
+
+	queueCtx, cancel := context.WithTimeout(ctx, queueWait(route))
+	defer cancel()
+	upstreams, err := g.getUpstreams(queueCtx, host)
+	if errors.Is(err, context.DeadlineExceeded) && ctx.Err() == nil {
+		g.recordRejectedRequest(route.ServiceID)
+		return nil, errWakeQueueTimeout
+	}
+	return upstreams, err
+}
+
+func (g *Gateway) enterWakeQueue(route *agenthttp.ServerlessRoute) bool {
+	activity := g.activity(route.ServiceID)
+	activity.mu.Lock()
+	defer activity.mu.Unlock()
+
+	if activity.queuedRequests >= maxQueuedRequests(route) {
+		activity.rejectedRequests++
+		return false
+	}
+	activity.queuedRequests++
+	return true
+}
+
+func (g *Gateway) leaveWakeQueue(serviceID string) {
+	activity := g.activity(serviceID)
+	activity.mu.Lock()
+	defer activity.mu.Unlock()
+	if activity.queuedRequests > 0 {
+		activity.queuedRequests--
+	}
+}
+
+func (g *Gateway) recordRejectedRequest(serviceID string) {
+	activity := g.activity(serviceID)
+	activity.mu.Lock()
+	defer activity.mu.Unlock()
+	activity.rejectedRequests++
+}
+
+func (g *Gateway) recordColdStart(serviceID string, mode StartMode, latency time.Duration) {
+	activity := g.activity(serviceID)
+	activity.mu.Lock()
+	defer activity.mu.Unlock()
+	if activity.starts == nil {
+		activity.starts = map[StartMode]StartStats{}
+	}
+	stats := activity.starts[mode]
+	stats.Count++
+	stats.Seconds += latency.Seconds()
+	stats.LastLatency = latency
+	activity.starts[mode] = stats
+}
+
+func (g *Gateway) Stats() []ServiceStats {
+	g.activityMu.Lock()
+	keys := make([]string, 0, len(g.activities))
+	for key := range g.activities {
+		keys = append(keys, key)
+	}
+	g.activityMu.Unlock()
+	sort.Strings(keys)
+
+	stats := make([]ServiceStats, 0, len(keys))
+	for _, key := range keys {
+		activity := g.activity(key)
+		activity.mu.Lock()
+		stats = append(stats, ServiceStats{
+			ServiceID:        key,
+			QueuedRequests:   activity.queuedRequests,
+			ActiveRequests:   activity.activeRequests,
+			RejectedRequests: activity.rejectedRequests,
+			OpenStreams:      len(activity.streams),
+			Starts:           maps.Clone(activity.starts),
+		})
+		activity.mu.Unlock()
+	}
+	return stats
+}
+
+func maxQueuedRequests(route *agenthttp.ServerlessRoute) int {
+	if route.MaxQueuedRequests <= 0 {
+		return defaultMaxQueuedRequests
+	}
+	return route.MaxQueuedRequests
+}
+
+func queueWait(route *agenthttp.ServerlessRoute) time.Duration {
+	wait := time.Duration(route.MaxQueueWaitSeconds) * time.Second
+	if wait <= 0 {
+		return wakeTimeout(route.WakeTimeoutSeconds)
+	}
+	return wait
+}
+
+func retryAfterSeconds(route *agenthttp.ServerlessRoute) string {
+	retryAfter := min(wakeRetryAfter, wakeTimeout(route.WakeTimeoutSeconds))
+	return strconv.Itoa(max(int(retryAfter.Seconds()), 1))
+}
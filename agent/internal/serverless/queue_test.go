@@ -0,0 +1,99 @@
+package serverless
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestServeHTTPRejectsWhenWakeQueueIsFull(t *testing.T) {
+	state := testExpectedState("stopped")
+	state.Serverless.Routes[0].Upstreams = nil
+	state.Serverless.Routes[0].MaxQueuedRequests = 1
+	runtime := &fakeRuntime{state: state}
+	gateway := NewGateway(runtime)
+	if !gateway.enterWakeQueue(&state.Serverless.Routes[0]) {
+		t.Fatal("first request was not admitted to the wake queue")
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "http://app.example.com/", nil)
+	rec := httptest.NewRecorder()
+	gateway.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusTooManyRequests)
+	}
+	if got := rec.Header().Get("Retry-After"); got != "5" {
+		t.Fatalf("Retry-After = %q, want 5", got)
+	}
+	if _, _, deployCalls := runtime.snapshot(); deployCalls != 0 {
+		t.Fatalf("deployCalls = %d, want no wake for rejected request", deployCalls)
+	}
+	stats := gateway.Stats()
+	if len(stats) != 1 || stats[0].QueuedRequests != 1 || stats[0].RejectedRequests != 1 {
+		t.Fatalf("stats = %+v, want one queued and one rejected request", stats)
+	}
+	gateway.stopAllActivities()
+}
+
+func TestServeHTTPReturnsServiceUnavailableAfterQueueWait(t *testing.T) {
+	state := testExpectedState("stopped")
+	state.Serverless.Routes[0].Upstreams = nil
+	state.Serverless.Routes[0].MaxQueueWaitSeconds = 1
+	runtime := &fakeRuntime{state: state, allowDeploy: make(chan struct{})}
+	defer close(runtime.allowDeploy)
+	gateway := NewGateway(runtime)
+
+	req := httptest.NewRequest(http.MethodGet, "http://app.example.com/", nil)
+	rec := httptest.NewRecorder()
+	startedAt := time.Now()
+	gateway.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+	if rec.Header().Get("Retry-After") == "" {
+		t.Fatal("missing Retry-After header")
+	}
+	if elapsed := time.Since(startedAt); elapsed > 3*time.Second {
+		t.Fatalf("request waited %s, want about the queue wait", elapsed)
+	}
+	stats := gateway.Stats()
+	if len(stats) != 1 || stats[0].QueuedRequests != 0 || stats[0].RejectedRequests != 1 {
+		t.Fatalf("stats = %+v, want drained queue with one rejected request", stats)
+	}
+	gateway.stopAllActivities()
+}
+
+func TestBlockingWakeRecordsColdStart(t *testing.T) {
+	useFastWakePolling(t)
+	state := testExpectedState("stopped")
+	state.Serverless.Routes[0].Upstreams = nil
+	gateway := NewGateway(&fakeRuntime{state: state})
+
+	if _, err := gateway.resolveUpstreams("app.example.com"); err != nil {
+		t.Fatalf("resolveUpstreams returned error: %v", err)
+	}
+
+	stats := gateway.Stats()
+	if len(stats) != 1 || stats[0].Starts[StartModeCold].Count != 1 || stats[0].ServiceID != "svc_1" {
+		t.Fatalf("stats = %+v, want one cold start for svc_1", stats)
+	}
+}
+
+func TestBlockingWakeRecordsRestoredStart(t *testing.T) {
+	useFastWakePolling(t)
+	state := testExpectedState("stopped")
+	state.Serverless.Routes[0].Upstreams = nil
+	gateway := NewGateway(&fakeRuntime{state: state, restoreStarts: true})
+
+	if _, err := gateway.resolveUpstreams("app.example.com"); err != nil {
+		t.Fatalf("resolveUpstreams returned error: %v", err)
+	}
+
+	stats := gateway.Stats()
+	if len(stats) != 1 || stats[0].Starts[StartModeRestore].Count != 1 || stats[0].Starts[StartModeCold].Count != 0 {
+		t.Fatalf("stats = %+v, want one restored start", stats)
+	}
+}
@@ -0,0 +1,138 @@
+package serverless
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"techulus/cloud-agent/internal/container"
+	agenthttp "techulus/cloud-agent/internal/http"
+)
+
+var weekdays = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// keepWarmUntil returns the end of the keep-warm window covering now, or the
+// zero time when the route may sleep. A window's pre-warm lead counts as part
+// of the window.
+func keepWarmUntil(route *agenthttp.ServerlessRoute, now time.Time) time.Time {
+	var until time.Time
+	for _, window := range route.KeepWarm {
+		end, err := windowEndCovering(window, now)
+		if err != nil {
+			log.Printf("[serverless-gateway] ignoring keep-warm window service=%s: %v", route.ServiceID, err)
+			continue
+		}
+		if end.After(until) {
+			until = end
+		}
+	}
+	return until
+}
+
+func windowEndCovering(window agenthttp.KeepWarmWindow, now time.Time) (time.Time, error) {
+	location := time.UTC
+	if window.TimeZone != "" {
+		loaded, err := time.LoadLocation(window.TimeZone)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid time zone %q: %w", window.TimeZone, err)
+		}
+		location = loaded
+	}
+	startHour, startMinute, err := parseClock(window.Start)
+	if err != nil {
+		return time.Time{}, err
+	}
+	endHour, endMinute, err := parseClock(window.End)
+	if err != nil {
+		return time.Time{}, err
+	}
+	days, err := parseWeekdays(window.Days)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	local := now.In(location)
+	prewarm := time.Duration(window.PrewarmSeconds) * time.Second
+	// Check the window that opened today and the one that opened yesterday,
+	// which may still be open if it runs past midnight. Tomorrow's window only
+	// matters through its pre-warm lead.
+	for _, offset := range []int{-1, 0, 1} {
+		day := time.Date(local.Year(), local.Month(), local.Day()+offset, 0, 0, 0, 0, location)
+		if len(days) > 0 && !days[day.Weekday()] {
+			continue
+		}
+		start := time.Date(day.Year(), day.Month(), day.Day(), startHour, startMinute, 0, 0, location)
+		end := time.Date(day.Year(), day.Month(), day.Day(), endHour, endMinute, 0, 0, location)
+		if !end.After(start) {
+			end = end.AddDate(0, 0, 1)
+		}
+		if !local.Before(start.Add(-prewarm)) && local.Before(end) {
+			return end, nil
+		}
+	}
+	return time.Time{}, nil
+}
+
+func parseClock(value string) (int, int, error) {
+	parsed, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid time %q, expected HH:MM", value)
+	}
+	return parsed.Hour(), parsed.Minute(), nil
+}
+
+func parseWeekdays(values []string) (map[time.Weekday]bool, error) {
+	days := map[time.Weekday]bool{}
+	for _, value := range values {
+		name := strings.ToLower(strings.TrimSpace(value))
+		if len(name) > 3 {
+			name = name[:3]
+		}
+		day, ok := weekdays[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid day %q", value)
+		}
+		days[day] = true
+	}
+	return days, nil
+}
+
+// prewarmServices wakes services whose keep-warm window has opened while
+// they are asleep, so the first request after a known spike starts warm.
+func (g *Gateway) prewarmServices(state *agenthttp.ExpectedState, actualByDeploymentID map[string]container.Container, now time.Time) {
+	seenServices := map[string]struct{}{}
+	for i := range state.Serverless.Routes {
+		route := &state.Serverless.Routes[i]
+		if _, seen := seenServices[route.ServiceID]; seen {
+			continue
+		}
+		seenServices[route.ServiceID] = struct{}{}
+		if keepWarmUntil(route, now).IsZero() {
+			continue
+		}
+		if len(route.LocalDeploymentIDs) > 0 {
+			if hasRunningLocalDeployment(route.LocalDeploymentIDs, actualByDeploymentID) {
+				continue
+			}
+		} else if len(route.RemoteDeployments) == 0 || len(route.Upstreams) > 0 {
+			continue
+		}
+		log.Printf("[serverless-gateway] prewarm starting service=%s host=%s", route.ServiceID, route.Domain)
+		domain := route.Domain
+		go func() {
+			if _, err := g.getUpstreams(context.Background(), domain); err != nil {
+				log.Printf("[serverless-gateway] prewarm failed host=%s error=%v", domain, err)
+			}
+		}()
+	}
+}
@@ -0,0 +1,98 @@
+package serverless
+
+import (
+	"testing"
+	"time"
+
+	"techulus/cloud-agent/internal/container"
+	agenthttp "techulus/cloud-agent/internal/http"
+)
+
+func TestWindowEndCovering(t *testing.T) {
+	berlin, err := time.LoadLocation("Europe/Berlin")
+	if err != nil {
+		t.Skipf("time zone data unavailable: %v", err)
+	}
+	weekdays := agenthttp.KeepWarmWindow{Days: []string{"mon", "tue", "wed", "thu", "fri"}, Start: "08:00", End: "20:00", TimeZone: "Europe/Berlin"}
+	overnight := agenthttp.KeepWarmWindow{Start: "22:00", End: "02:00"}
+	prewarm := agenthttp.KeepWarmWindow{Start: "09:00", End: "10:00", PrewarmSeconds: 900}
+
+	tests := []struct {
+		name   string
+		window agenthttp.KeepWarmWindow
+		now    time.Time
+		want   time.Time
+	}{
+		{"weekday inside", weekdays, time.Date(2026, 10, 14, 12, 0, 0, 0, berlin), time.Date(2026, 10, 14, 20, 0, 0, 0, berlin)},
+		{"weekday before start", weekdays, time.Date(2026, 10, 14, 7, 59, 0, 0, berlin), time.Time{}},
+		{"weekend", weekdays, time.Date(2026, 10, 17, 12, 0, 0, 0, berlin), time.Time{}},
+		{"overnight after midnight", overnight, time.Date(2026, 10, 15, 1, 0, 0, 0, time.UTC), time.Date(2026, 10, 15, 2, 0, 0, 0, time.UTC)},
+		{"overnight closed", overnight, time.Date(2026, 10, 15, 3, 0, 0, 0, time.UTC), time.Time{}},
+		{"prewarm lead", prewarm, time.Date(2026, 10, 15, 8, 50, 0, 0, time.UTC), time.Date(2026, 10, 15, 10, 0, 0, 0, time.UTC)},
+		{"before prewarm lead", prewarm, time.Date(2026, 10, 15, 8, 40, 0, 0, time.UTC), time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := windowEndCovering(tt.window, tt.now)
+			if err != nil {
+				t.Fatalf("windowEndCovering returned error: %v", err)
+			}
+			if !got.Equal(tt.want) {
+				t.Fatalf("windowEndCovering = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWindowEndCoveringRejectsInvalidWindow(t *testing.T) {
+	for _, window := range []agenthttp.KeepWarmWindow{
+		{Start: "8am", End: "20:00"},
+		{Start: "08:00", End: "20:00", Days: []string{"someday"}},
+		{Start: "08:00", End: "20:00", TimeZone: "Mars/Olympus"},
+	} {
+		if _, err := windowEndCovering(window, time.Now()); err == nil {
+			t.Fatalf("windowEndCovering(%+v) succeeded, want error", window)
+		}
+	}
+}
+
+func TestSleepServiceSkipsDuringKeepWarmWindow(t *testing.T) {
+	state := testExpectedState("running")
+	state.Serverless.Routes[0].KeepWarm = []agenthttp.KeepWarmWindow{{Start: "00:00", End: "00:00"}}
+	runtime := &fakeRuntime{
+		state: state,
+		containers: []container.Container{
+			{ID: "ctr-local", State: "running", DeploymentID: "dep_local", ServiceID: "svc_1"},
+		},
+	}
+	gateway := NewGateway(runtime)
+	defer gateway.stopAllActivities()
+
+	gateway.sleepService("svc_1")
+
+	transitions, stopped, _ := runtime.snapshot()
+	if len(stopped) != 0 || len(transitions) != 0 {
+		t.Fatalf("stopped = %+v transitions = %+v, want service kept warm", stopped, transitions)
+	}
+}
+
+func TestSeedIdleTimersPrewarmsSleepingService(t *testing.T) {
+	useFastWakePolling(t)
+	state := testExpectedState("stopped")
+	state.Serverless.Routes[0].Upstreams = nil
+	state.Serverless.Routes[0].KeepWarm = []agenthttp.KeepWarmWindow{{Start: "00:00", End: "00:00"}}
+	runtime := &fakeRuntime{state: state}
+	gateway := NewGateway(runtime)
+	defer gateway.stopAllActivities()
+
+	gateway.seedIdleTimers()
+
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		if _, _, deployCalls := runtime.snapshot(); deployCalls == 1 {
+			return
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	t.Fatal("sleeping service was not prewarmed")
+}
@@ -0,0 +1,140 @@
+package serverless
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	agenthttp "techulus/cloud-agent/internal/http"
+)
+
+// streamConn is an upgraded connection or event stream proxied to a service.
+// Unlike plain requests it only holds the service awake while bytes keep
+// flowing and, when configured, until it reaches its maximum lifetime.
+type streamConn struct {
+	openedAt     time.Time
+	lastActivity atomic.Int64
+	cancel       context.CancelFunc
+}
+
+func (s *streamConn) touch() {
+	s.lastActivity.Store(time.Now().UnixNano())
+}
+
+type streamResponseWriter struct {
+	http.ResponseWriter
+	stream *streamConn
+}
+
+func (w *streamResponseWriter) Write(p []byte) (int, error) {
+	w.stream.touch()
+	return w.ResponseWriter.Write(p)
+}
+
+func (w *streamResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *streamResponseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err != nil {
+		return nil, nil, err
+	}
+	return &streamNetConn{Conn: conn, stream: w.stream}, rw, nil
+}
+
+type streamNetConn struct {
+	net.Conn
+	stream *streamConn
+}
+
+func (c *streamNetConn) Read(p []byte) (int, error) {
+	n, err := c.Conn.Read(p)
+	if n > 0 {
+		c.stream.touch()
+	}
+	return n, err
+}
+
+func (c *streamNetConn) Write(p []byte) (int, error) {
+	n, err := c.Conn.Write(p)
+	if n > 0 {
+		c.stream.touch()
+	}
+	return n, err
+}
+
+func longLivedRequest(r *http.Request) bool {
+	if r.Header.Get("Upgrade") != "" || headerContainsToken(r.Header, "Connection", "upgrade") {
+		return true
+	}
+	return strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
+
+func (g *Gateway) openStream(key string, cancel context.CancelFunc) *streamConn {
+	stream := &streamConn{openedAt: time.Now(), cancel: cancel}
+	stream.touch()
+
+	activity := g.activity(key)
+	activity.mu.Lock()
+	defer activity.mu.Unlock()
+	if activity.streams == nil {
+		activity.streams = map[*streamConn]struct{}{}
+	}
+	activity.streams[stream] = struct{}{}
+	if activity.sleepTimer != nil {
+		activity.sleepTimer.Stop()
+		activity.sleepTimer = nil
+	}
+	return stream
+}
+
+func (g *Gateway) closeStream(key string, stream *streamConn) {
+	activity := g.activity(key)
+	activity.mu.Lock()
+	defer activity.mu.Unlock()
+	delete(activity.streams, stream)
+}
+
+// streamsAwakeUntil returns when the service's open streams stop holding it
+// awake, or the zero time if none currently do. The caller holds activity.mu.
+func streamsAwakeUntil(activity *activityState, route *agenthttp.ServerlessRoute, now time.Time) time.Time {
+	idleTimeout := streamIdleTimeout(route)
+	maxLifetime := time.Duration(route.MaxConnectionLifetimeSeconds) * time.Second
+
+	var until time.Time
+	for stream := range activity.streams {
+		idleAt := time.Unix(0, stream.lastActivity.Load()).Add(idleTimeout)
+		if maxLifetime > 0 {
+			if expiresAt := stream.openedAt.Add(maxLifetime); expiresAt.Before(idleAt) {
+				idleAt = expiresAt
+			}
+		}
+		if idleAt.After(now) && idleAt.After(until) {
+			until = idleAt
+		}
+	}
+	return until
+}
+
+func closeStreams(activity *activityState) int {
+	closed := 0
+	for stream := range activity.streams {
+		stream.cancel()
+		delete(activity.streams, stream)
+		closed++
+	}
+	return closed
+}
+
+func streamIdleTimeout(route *agenthttp.ServerlessRoute) time.Duration {
+	timeout := time.Duration(route.StreamIdleTimeoutSeconds) * time.Second
+	if timeout <= 0 {
+		return sleepDelay(route.SleepAfterSeconds)
+	}
+	return timeout
+}
@@ -0,0 +1,120 @@
+package serverless
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"techulus/cloud-agent/internal/container"
+)
+
+func testStreamRuntime() *fakeRuntime {
+	return &fakeRuntime{
+		state: testExpectedState("running"),
+		containers: []container.Container{
+			{ID: "ctr-local", State: "running", DeploymentID: "dep_local", ServiceID: "svc_1"},
+		},
+	}
+}
+
+func TestSleepServiceSkipsWhileStreamIsActive(t *testing.T) {
+	runtime := testStreamRuntime()
+	gateway := NewGateway(runtime)
+	defer gateway.stopAllActivities()
+	cancelled := false
+	gateway.openStream("svc_1", func() { cancelled = true })
+
+	gateway.sleepService("svc_1")
+
+	if _, stopped, _ := runtime.snapshot(); len(stopped) != 0 {
+		t.Fatalf("stopped = %+v, want no stops under an active stream", stopped)
+	}
+	if cancelled {
+		t.Fatal("active stream was closed")
+	}
+}
+
+func TestSleepServiceClosesIdleStream(t *testing.T) {
+	runtime := testStreamRuntime()
+	runtime.state.Serverless.Routes[0].StreamIdleTimeoutSeconds = 60
+	gateway := NewGateway(runtime)
+	cancelled := false
+	stream := gateway.openStream("svc_1", func() { cancelled = true })
+	stream.lastActivity.Store(time.Now().Add(-2 * time.Minute).UnixNano())
+
+	gateway.sleepService("svc_1")
+
+	if _, stopped, _ := runtime.snapshot(); len(stopped) != 1 {
+		t.Fatalf("stopped = %+v, want idle stream to allow sleep", stopped)
+	}
+	if !cancelled {
+		t.Fatal("idle stream was not closed before sleep")
+	}
+}
+
+func TestSleepServiceIgnoresStreamPastMaxLifetime(t *testing.T) {
+	runtime := testStreamRuntime()
+	runtime.state.Serverless.Routes[0].MaxConnectionLifetimeSeconds = 60
+	gateway := NewGateway(runtime)
+	stream := gateway.openStream("svc_1", func() {})
+	stream.openedAt = time.Now().Add(-2 * time.Minute)
+
+	gateway.sleepService("svc_1")
+
+	if _, stopped, _ := runtime.snapshot(); len(stopped) != 1 {
+		t.Fatalf("stopped = %+v, want stream past max lifetime to allow sleep", stopped)
+	}
+}
+
+func TestServeHTTPProxiesUpgradedConnection(t *testing.T) {
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, rw, err := http.NewResponseController(w).Hijack()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		rw.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+		rw.Flush()
+		io.Copy(conn, rw)
+	}))
+	t.Cleanup(backend.Close)
+
+	gateway := testProxyGateway(t, backend.Listener.Addr().String())
+	defer gateway.stopAllActivities()
+	front := httptest.NewServer(gateway)
+	t.Cleanup(front.Close)
+
+	conn, err := net.Dial("tcp", front.Listener.Addr().String())
+	if err != nil {
+		t.Fatalf("dial gateway: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	fmt.Fprint(conn, "GET /socket HTTP/1.1\r\nHost: app.example.com\r\nConnection: Upgrade\r\nUpgrade: echo\r\n\r\n")
+
+	reader := bufio.NewReader(conn)
+	response, err := http.ReadResponse(reader, nil)
+	if err != nil {
+		t.Fatalf("read upgrade response: %v", err)
+	}
+	if response.StatusCode != http.StatusSwitchingProtocols {
+		t.Fatalf("status = %d, want %d", response.StatusCode, http.StatusSwitchingProtocols)
+	}
+
+	fmt.Fprint(conn, "ping\n")
+	line, err := reader.ReadString('\n')
+	if err != nil || strings.TrimSpace(line) != "ping" {
+		t.Fatalf("echo = %q, %v; want ping", line, err)
+	}
+
+	stats := gateway.Stats()
+	if len(stats) != 1 || stats[0].OpenStreams != 1 || stats[0].ActiveRequests != 0 {
+		t.Fatalf("stats = %+v, want one open stream and no active requests", stats)
+	}
+}
@@ -0,0 +1,312 @@
+package serverless
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	agenthttp "techulus/cloud-agent/internal/http"
+)
+
+const (
+	TriggerEventPort      = 18083
+	triggerEventPrefix    = "/events/"
+	triggerHeader         = "X-Techulus-Trigger"
+	maxQueuedEvents       = 100
+	maxEventBodyBytes     = 1 << 20
+	maxEventAttempts      = 5
+	triggerDeliverTimeout = 5 * time.Minute
+)
+
+var (
+	triggerClient    = &http.Client{}
+	eventRetryDelay  = time.Second
+	tcpTriggerDialer = &net.Dialer{Timeout: 5 * time.Second}
+)
+
+type triggerState struct {
+	mu           sync.Mutex
+	eventQueues  map[string]*eventQueue
+	tcpListeners map[int]*tcpTriggerListener
+}
+
+type eventQueue struct {
+	events   []queuedEvent
+	draining bool
+}
+
+type queuedEvent struct {
+	body        []byte
+	contentType string
+}
+
+type tcpTriggerListener struct {
+	listener  net.Listener
+	serviceID string
+	port      int
+}
+
+// deliverTrigger sends a queued event to the service as a plain request,
+// waking it first if needed. The delivery counts as an active request, so the
+// service goes back to sleep on its usual idle timer once it completes.
+func (g *Gateway) deliverTrigger(ctx context.Context, route agenthttp.ServerlessRoute, kind string, req *http.Request) (int, error) {
+	g.beginActivity(route.ServiceID)
+	defer g.endActivity(route.ServiceID, route.ServiceID, route.SleepAfterSeconds)
+
+	upstreams, err := g.getUpstreams(ctx, route.Domain)
+	if err != nil {
+		return 0, err
+	}
+	if len(upstreams) == 0 {
+		return 0, fmt.Errorf("no upstreams for host %s", route.Domain)
+	}
+	upstream := upstreams[g.nextIndex(len(upstreams))]
+
+	req = req.WithContext(ctx)
+	req.URL.Scheme = "http"
+	req.URL.Host = upstream.Url
+	req.Host = route.Domain
+	req.Header.Set(triggerHeader, kind)
+	resp, err := triggerClient.Do(req)
+	if err != nil {
+		g.evictUpstreams(route.Domain)
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode, nil
+}
+
+// serveEvents accepts events for a service on the loopback event port and
+// queues them for delivery to the service's event path.
+func (g *Gateway) serveEvents(w http.ResponseWriter, r *http.Request) {
+	serviceID, ok := strings.CutPrefix(r.URL.Path, triggerEventPrefix)
+	if !ok || serviceID == "" || strings.Contains(serviceID, "/") {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	route := findRouteByServiceID(g.runtime.ExpectedState(), serviceID)
+	if route == nil || route.Triggers == nil || route.Triggers.EventPath == "" {
+		http.Error(w, "no event trigger for service", http.StatusNotFound)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxEventBodyBytes))
+	if err != nil {
+		http.Error(w, "invalid event body", http.StatusRequestEntityTooLarge)
+		return
+	}
+	if !g.enqueueEvent(serviceID, queuedEvent{body: body, contentType: r.Header.Get("Content-Type")}) {
+		http.Error(w, "event queue full", http.StatusTooManyRequests)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+}
+
+func (g *Gateway) enqueueEvent(serviceID string, event queuedEvent) bool {
+	g.triggers.mu.Lock()
+	defer g.triggers.mu.Unlock()
+	if g.triggers.eventQueues == nil {
+		g.triggers.eventQueues = map[string]*eventQueue{}
+	}
+	queue := g.triggers.eventQueues[serviceID]
+	if queue == nil {
+		queue = &eventQueue{}
+		g.triggers.eventQueues[serviceID] = queue
+	}
+	if len(queue.events) >= maxQueuedEvents {
+		return false
+	}
+	queue.events = append(queue.events, event)
+	if !queue.draining {
+		queue.draining = true
+		go g.drainEvents(serviceID, queue)
+	}
+	return true
+}
+
+func (g *Gateway) drainEvents(serviceID string, queue *eventQueue) {
+	for {
+		g.triggers.mu.Lock()
+		if len(queue.events) == 0 {
+			queue.draining = false
+			g.triggers.mu.Unlock()
+			return
+		}
+		event := queue.events[0]
+		queue.events = queue.events[1:]
+		g.triggers.mu.Unlock()
+
+		g.deliverEvent(serviceID, event)
+	}
+}
+
+func (g *Gateway) deliverEvent(serviceID string, event queuedEvent) {
+	delay := eventRetryDelay
+	for attempt := 1; attempt <= maxEventAttempts; attempt++ {
+		route := findRouteByServiceID(g.runtime.ExpectedState(), serviceID)
+		if route == nil || route.Triggers == nil || route.Triggers.EventPath == "" {
+			log.Printf("[serverless-trigger] dropping event service=%s: event trigger removed", serviceID)
+			return
+		}
+
+		req, err := http.NewRequest(http.MethodPost, route.Triggers.EventPath, bytes.NewReader(event.body))
+		if err != nil {
+			log.Printf("[serverless-trigger] dropping event service=%s: %v", serviceID, err)
+			return
+		}
+		if event.contentType != "" {
+			req.Header.Set("Content-Type", event.contentType)
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), triggerDeliverTimeout)
+		status, err := g.deliverTrigger(ctx, *route, "event", req)
+		cancel()
+		if err == nil && status < http.StatusInternalServerError {
+			return
+		}
+		if err == nil {
+			err = fmt.Errorf("status %d", status)
+		}
+		log.Printf("[serverless-trigger] event delivery failed service=%s attempt=%d/%d error=%v", serviceID, attempt, maxEventAttempts, err)
+		if attempt < maxEventAttempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	log.Printf("[serverless-trigger] dropping event service=%s after %d attempts", serviceID, maxEventAttempts)
+}
+
+// reconcileTCPTriggers opens a loopback listener for every TCP trigger in the
+// expected state and closes listeners whose trigger has gone away. Traefik
+// sends the service's L4 route to these listeners.
+func (g *Gateway) reconcileTCPTriggers() {
+	state := g.runtime.ExpectedState()
+	if state == nil {
+		return
+	}
+	wanted := map[int]agenthttp.ServerlessRoute{}
+	ports := map[int]int{}
+	for _, route := range state.Serverless.Routes {
+		if route.Triggers == nil {
+			continue
+		}
+		for _, trigger := range route.Triggers.TCP {
+			wanted[trigger.ListenPort] = route
+			ports[trigger.ListenPort] = trigger.Port
+		}
+	}
+
+	g.triggers.mu.Lock()
+	defer g.triggers.mu.Unlock()
+	if g.triggers.tcpListeners == nil {
+		g.triggers.tcpListeners = map[int]*tcpTriggerListener{}
+	}
+	for listenPort, existing := range g.triggers.tcpListeners {
+		route, ok := wanted[listenPort]
+		if ok && route.ServiceID == existing.serviceID && ports[listenPort] == existing.port {
+			continue
+		}
+		existing.listener.Close()
+		delete(g.triggers.tcpListeners, listenPort)
+	}
+	for listenPort, route := range wanted {
+		if _, ok := g.triggers.tcpListeners[listenPort]; ok {
+			continue
+		}
+		addr := fmt.Sprintf("127.0.0.1:%d", listenPort)
+		listener, err := net.Listen("tcp", addr)
+		if err != nil {
+			log.Printf("[serverless-trigger] failed to listen on %s service=%s: %v", addr, route.ServiceID, err)
+			continue
+		}
+		trigger := &tcpTriggerListener{listener: listener, serviceID: route.ServiceID, port: ports[listenPort]}
+		g.triggers.tcpListeners[listenPort] = trigger
+		log.Printf("[serverless-trigger] tcp trigger listening on %s service=%s", addr, route.ServiceID)
+		go g.acceptTCPTrigger(trigger)
+	}
+}
+
+func (g *Gateway) closeTCPTriggers() {
+	g.triggers.mu.Lock()
+	defer g.triggers.mu.Unlock()
+	for listenPort, trigger := range g.triggers.tcpListeners {
+		trigger.listener.Close()
+		delete(g.triggers.tcpListeners, listenPort)
+	}
+}
+
+func (g *Gateway) acceptTCPTrigger(trigger *tcpTriggerListener) {
+	for {
+		conn, err := trigger.listener.Accept()
+		if err != nil {
+			return
+		}
+		go g.proxyTCPTrigger(trigger, conn)
+	}
+}
+
+// proxyTCPTrigger wakes the service behind a TCP trigger and pipes the
+// connection to it. The connection is tracked like an upgraded HTTP stream so
+// an idle connection does not hold the service awake forever.
+func (g *Gateway) proxyTCPTrigger(trigger *tcpTriggerListener, conn net.Conn) {
+	defer conn.Close()
+	route := findRouteByServiceID(g.runtime.ExpectedState(), trigger.serviceID)
+	if route == nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	stream := g.openStream(route.ServiceID, func() {
+		cancel()
+		conn.Close()
+	})
+	defer g.closeStream(route.ServiceID, stream)
+	g.scheduleSleepTimer(route.ServiceID, route.ServiceID, route.SleepAfterSeconds)
+
+	wakeCtx, wakeCancel := context.WithTimeout(ctx, wakeTimeout(route.WakeTimeoutSeconds))
+	upstreams, err := g.getUpstreams(wakeCtx, route.Domain)
+	wakeCancel()
+	if err != nil || len(upstreams) == 0 {
+		log.Printf("[serverless-trigger] tcp wake failed service=%s error=%v", route.ServiceID, err)
+		return
+	}
+	address := upstreams[g.nextIndex(len(upstreams))].Url
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		log.Printf("[serverless-trigger] invalid upstream %q service=%s: %v", address, route.ServiceID, err)
+		return
+	}
+	address = net.JoinHostPort(host, fmt.Sprint(trigger.port))
+	upstream, err := tcpTriggerDialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		g.evictUpstreams(route.Domain)
+		log.Printf("[serverless-trigger] tcp dial failed service=%s upstream=%s error=%v", route.ServiceID, address, err)
+		return
+	}
+	defer upstream.Close()
+
+	client := &streamNetConn{Conn: conn, stream: stream}
+	done := make(chan struct{}, 2)
+	go func() {
+		io.Copy(upstream, client)
+		done <- struct{}{}
+	}()
+	go func() {
+		io.Copy(client, upstream)
+		done <- struct{}{}
+	}()
+	<-done
+}
@@ -0,0 +1,185 @@
+package serverless
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	agenthttp "techulus/cloud-agent/internal/http"
+)
+
+type triggerRequest struct {
+	method  string
+	path    string
+	trigger string
+	body    string
+}
+
+func triggerBackend(t *testing.T, status int) (*httptest.Server, <-chan triggerRequest) {
+	t.Helper()
+	requests := make(chan triggerRequest, 10)
+	backend := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- triggerRequest{
+			method:  r.Method,
+			path:    r.URL.RequestURI(),
+			trigger: r.Header.Get(triggerHeader),
+			body:    string(body),
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(backend.Close)
+	return backend, requests
+}
+
+func receiveTriggerRequest(t *testing.T, requests <-chan triggerRequest) triggerRequest {
+	t.Helper()
+	select {
+	case request := <-requests:
+		return request
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for trigger delivery")
+		return triggerRequest{}
+	}
+}
+
+func TestServeEventsQueuesAndDeliversEvent(t *testing.T) {
+	backend, requests := triggerBackend(t, http.StatusOK)
+	gateway := testProxyGateway(t, backend.Listener.Addr().String())
+	defer gateway.stopAllActivities()
+	gateway.runtime.(*fakeRuntime).state.Serverless.Routes[0].Triggers = &agenthttp.ServerlessTriggers{EventPath: "/events"}
+
+	req := httptest.NewRequest(http.MethodPost, "/events/svc_1", strings.NewReader(`{"id":1}`))
+	req.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	gateway.serveEvents(recorder, req)
+
+	if recorder.Code != http.StatusAccepted {
+		t.Fatalf("status = %d, want %d", recorder.Code, http.StatusAccepted)
+	}
+	request := receiveTriggerRequest(t, requests)
+	if request.method != http.MethodPost || request.path != "/events" || request.body != `{"id":1}` || request.trigger != "event" {
+		t.Fatalf("request = %+v, want event posted to /events", request)
+	}
+}
+
+func TestServeEventsRetriesFailedDelivery(t *testing.T) {
+	previousDelay := eventRetryDelay
+	eventRetryDelay = time.Millisecond
+	t.Cleanup(func() { eventRetryDelay = previousDelay })
+
+	backend, requests := triggerBackend(t, http.StatusServiceUnavailable)
+	gateway := testProxyGateway(t, backend.Listener.Addr().String())
+	defer gateway.stopAllActivities()
+	gateway.runtime.(*fakeRuntime).state.Serverless.Routes[0].Triggers = &agenthttp.ServerlessTriggers{EventPath: "/events"}
+
+	recorder := httptest.NewRecorder()
+	gateway.serveEvents(recorder, httptest.NewRequest(http.MethodPost, "/events/svc_1", strings.NewReader("payload")))
+
+	for range maxEventAttempts {
+		receiveTriggerRequest(t, requests)
+	}
+	select {
+	case extra := <-requests:
+		t.Fatalf("unexpected delivery %+v after %d attempts", extra, maxEventAttempts)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestServeEventsRejectsUnknownServiceAndFullQueue(t *testing.T) {
+	gateway := NewGateway(&fakeRuntime{state: testExpectedState("running")})
+	defer gateway.stopAllActivities()
+
+	recorder := httptest.NewRecorder()
+	gateway.serveEvents(recorder, httptest.NewRequest(http.MethodPost, "/events/svc_1", nil))
+	if recorder.Code != http.StatusNotFound {
+		t.Fatalf("status without event trigger = %d, want %d", recorder.Code, http.StatusNotFound)
+	}
+
+	gateway.runtime.(*fakeRuntime).state.Serverless.Routes[0].Triggers = &agenthttp.ServerlessTriggers{EventPath: "/events"}
+	gateway.triggers.eventQueues = map[string]*eventQueue{
+		"svc_1": {events: make([]queuedEvent, maxQueuedEvents), draining: true},
+	}
+	recorder = httptest.NewRecorder()
+	gateway.serveEvents(recorder, httptest.NewRequest(http.MethodPost, "/events/svc_1", nil))
+	if recorder.Code != http.StatusTooManyRequests {
+		t.Fatalf("status with full queue = %d, want %d", recorder.Code, http.StatusTooManyRequests)
+	}
+}
+
+func TestTCPTriggerProxiesConnection(t *testing.T) {
+	backend, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen backend: %v", err)
+	}
+	t.Cleanup(func() { backend.Close() })
+	go func() {
+		for {
+			conn, err := backend.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	free, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("reserve trigger port: %v", err)
+	}
+	listenPort := free.Addr().(*net.TCPAddr).Port
+	free.Close()
+
+	gateway := testProxyGateway(t, "127.0.0.1:3000")
+	defer gateway.stopAllActivities()
+	defer gateway.closeTCPTriggers()
+	gateway.runtime.(*fakeRuntime).state.Serverless.Routes[0].Triggers = &agenthttp.ServerlessTriggers{
+		TCP: []agenthttp.ServerlessTCPTrigger{{ListenPort: listenPort, Port: backend.Addr().(*net.TCPAddr).Port}},
+	}
+	gateway.reconcileTCPTriggers()
+
+	conn, err := net.Dial("tcp", fmt.Sprintf("127.0.0.1:%d", listenPort))
+	if err != nil {
+		t.Fatalf("dial trigger: %v", err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(5 * time.Second))
+	fmt.Fprint(conn, "ping\n")
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || strings.TrimSpace(line) != "ping" {
+		t.Fatalf("echo = %q, %v; want ping", line, err)
+	}
+
+	stats := gateway.Stats()
+	if len(stats) != 1 || stats[0].OpenStreams != 1 {
+		t.Fatalf("stats = %+v, want the tcp connection tracked as a stream", stats)
+	}
+}
+
+func TestReconcileTCPTriggersClosesRemovedListeners(t *testing.T) {
+	gateway := testProxyGateway(t, "127.0.0.1:3000")
+	defer gateway.closeTCPTriggers()
+	route := &gateway.runtime.(*fakeRuntime).state.Serverless.Routes[0]
+	route.Triggers = &agenthttp.ServerlessTriggers{
+		TCP: []agenthttp.ServerlessTCPTrigger{{ListenPort: 0, Port: 5432}},
+	}
+	gateway.reconcileTCPTriggers()
+	if len(gateway.triggers.tcpListeners) != 1 {
+		t.Fatalf("listeners = %d, want 1", len(gateway.triggers.tcpListeners))
+	}
+
+	route.Triggers = nil
+	gateway.reconcileTCPTriggers()
+	if len(gateway.triggers.tcpListeners) != 0 {
+		t.Fatalf("listeners = %d, want the removed trigger closed", len(gateway.triggers.tcpListeners))
+	}
+}
@@ -2,18 +2,81 @@ package traefik
 
 import (
 	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/hex"
+	"encoding/pem"
 	"fmt"
 	"log"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
+type CertificateInfo struct {
+	Domain        string
+	Subject       string
+	Issuer        string
+	SANs          []string
+	NotBefore     time.Time
+	NotAfter      time.Time
+	ChainLength   int
+	MatchesDomain bool
+}
+
+func InspectCertificate(cert Certificate) (*CertificateInfo, error) {
+	var chain []*x509.Certificate
+	rest := []byte(cert.Certificate)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		parsed, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse certificate for %s: %w", cert.Domain, err)
+		}
+		chain = append(chain, parsed)
+	}
+	if len(chain) == 0 {
+		return nil, fmt.Errorf("no certificate found for %s", cert.Domain)
+	}
+
+	leaf := chain[0]
+	return &CertificateInfo{
+		Domain:        cert.Domain,
+		Subject:       leaf.Subject.CommonName,
+		Issuer:        leaf.Issuer.CommonName,
+		SANs:          append([]string(nil), leaf.DNSNames...),
+		NotBefore:     leaf.NotBefore.UTC(),
+		NotAfter:      leaf.NotAfter.UTC(),
+		ChainLength:   len(chain),
+		MatchesDomain: leaf.VerifyHostname(cert.Domain) == nil,
+	}, nil
+}
+
+func VerifyCertificateKey(cert Certificate) error {
+	if _, err := tls.X509KeyPair([]byte(cert.Certificate), []byte(cert.CertificateKey)); err != nil {
+		return fmt.Errorf("certificate key for %s does not match: %w", cert.Domain, err)
+	}
+	return nil
+}
+
 func UpdateCertificates(certs []Certificate) error {
+	for _, cert := range certs {
+		if err := VerifyCertificateKey(cert); err != nil {
+			return fmt.Errorf("refusing to install certificate: %w", err)
+		}
+	}
+
 	if err := os.MkdirAll(traefikCertsDir, 0700); err != nil {
 		return fmt.Errorf("failed to create certs dir: %w", err)
 	}
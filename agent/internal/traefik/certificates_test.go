@@ -0,0 +1,82 @@
+package traefik
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testKeyPair(t *testing.T, dnsNames []string, notAfter time.Time) (string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: dnsNames[0]},
+		Issuer:       pkix.Name{CommonName: "Test CA"},
+		DNSNames:     dnsNames,
+		NotBefore:    notAfter.Add(-90 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+}
+
+func TestInspectCertificateReportsExpiryAndDomainMatch(t *testing.T) {
+	notAfter := time.Now().Add(10 * 24 * time.Hour).UTC().Truncate(time.Second)
+	certPEM, _ := testKeyPair(t, []string{"*.example.com", "example.com"}, notAfter)
+
+	info, err := InspectCertificate(Certificate{Domain: "app.example.com", Certificate: certPEM + certPEM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !info.NotAfter.Equal(notAfter) || info.ChainLength != 2 || !info.MatchesDomain {
+		t.Fatalf("info = %+v", info)
+	}
+	if strings.Join(info.SANs, ",") != "*.example.com,example.com" {
+		t.Fatalf("SANs = %v", info.SANs)
+	}
+
+	info, err = InspectCertificate(Certificate{Domain: "other.test", Certificate: certPEM})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.MatchesDomain {
+		t.Fatal("certificate for example.com matched other.test")
+	}
+
+	if _, err := InspectCertificate(Certificate{Domain: "app.example.com", Certificate: "garbage"}); err == nil {
+		t.Fatal("expected an error for a missing certificate")
+	}
+}
+
+func TestUpdateCertificatesRefusesMismatchedKey(t *testing.T) {
+	notAfter := time.Now().Add(30 * 24 * time.Hour)
+	certPEM, _ := testKeyPair(t, []string{"app.example.com"}, notAfter)
+	_, otherKey := testKeyPair(t, []string{"app.example.com"}, notAfter)
+
+	cert := Certificate{Domain: "app.example.com", Certificate: certPEM, CertificateKey: otherKey}
+	if err := VerifyCertificateKey(cert); err == nil {
+		t.Fatal("expected key mismatch")
+	}
+	if err := UpdateCertificates([]Certificate{cert}); err == nil || !strings.Contains(err.Error(), "refusing") {
+		t.Fatalf("UpdateCertificates error = %v", err)
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
@@ -95,3 +96,67 @@ func ChallengeRouteExists() bool {
 	_, err := os.Stat(challengePath)
 	return err == nil
 }
+
+// WriteLocalChallengeRoute overrides the control plane challenge route so the
+// agent can answer HTTP-01 challenges itself. It waits for Traefik to pick up
+// the route, since the ACME server validates as soon as a challenge is accepted.
+func WriteLocalChallengeRoute(upstreamURL string, timeout time.Duration) error {
+	config := challengeConfig{
+		HTTP: httpConfigWithMiddlewares{
+			Routers: map[string]routerWithMiddleware{
+				"acme_challenge_local": {
+					Rule:        "PathPrefix(`/.well-known/acme-challenge/`)",
+					EntryPoints: []string{"web"},
+					Service:     "acme_challenge_local_svc",
+					Priority:    10000,
+				},
+			},
+			Services: map[string]service{
+				"acme_challenge_local_svc": {
+					LoadBalancer: loadBalancer{
+						Servers: []server{
+							{URL: upstreamURL},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("failed to marshal local challenge route config: %w", err)
+	}
+
+	if err := os.MkdirAll(dynamicConfigDir, 0755); err != nil {
+		return fmt.Errorf("failed to create dynamic config dir: %w", err)
+	}
+
+	baseline, err := LastSuccessfulReload()
+	if err != nil {
+		return fmt.Errorf("failed to capture Traefik reload baseline: %w", err)
+	}
+	if err := atomicWrite(filepath.Join(dynamicConfigDir, localChallengesFileName), data, 0644); err != nil {
+		return fmt.Errorf("failed to write local challenge route config: %w", err)
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		if lastReload, err := LastSuccessfulReload(); err == nil && lastReload.After(baseline) {
+			log.Printf("[traefik] local challenge route written pointing to %s", upstreamURL)
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("traefik did not load the local challenge route within %s", timeout)
+		}
+		time.Sleep(reloadPollInterval)
+	}
+}
+
+func RemoveLocalChallengeRoute() error {
+	err := os.Remove(filepath.Join(dynamicConfigDir, localChallengesFileName))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to remove local challenge route config: %w", err)
+	}
+	return nil
+}
@@ -28,6 +28,9 @@ func ValidateL4Routes(tcpRoutes []TraefikTCPRoute, udpRoutes []TraefikUDPRoute)
 		if err := ValidateTCPPort(route.ExternalPort); err != nil {
 			return fmt.Errorf("invalid TCP route %s: %w", route.ID, err)
 		}
+		if route.ProxyProtocol != 0 && route.ProxyProtocol != 1 && route.ProxyProtocol != 2 {
+			return fmt.Errorf("invalid TCP route %s: unsupported PROXY protocol version %d", route.ID, route.ProxyProtocol)
+		}
 	}
 	for _, route := range udpRoutes {
 		if err := ValidateUDPPort(route.ExternalPort); err != nil {
@@ -37,6 +40,22 @@ func ValidateL4Routes(tcpRoutes []TraefikTCPRoute, udpRoutes []TraefikUDPRoute)
 	return nil
 }
 
+func validateTCPRouteTLS(route TraefikTCPRoute) error {
+	if route.TLSPassthrough && route.TLSTermination {
+		return fmt.Errorf("TLS passthrough and termination are mutually exclusive")
+	}
+	if route.TLSTermination && route.Domain == "" {
+		return fmt.Errorf("TLS termination requires a domain for SNI routing")
+	}
+	if route.Domain != "" && !route.TLSPassthrough && !route.TLSTermination {
+		return fmt.Errorf("SNI routing requires TLS passthrough or termination")
+	}
+	if route.ClientAuth != nil && !route.TLSTermination {
+		return fmt.Errorf("client auth requires TLS termination")
+	}
+	return nil
+}
+
 // CompileRoutes is the single pure compiler for the durable routes.yaml model.
 func CompileRoutes(httpRoutes []TraefikRoute, tcpRoutes []TraefikTCPRoute, udpRoutes []TraefikUDPRoute, serverName string) (*RoutesConfig, error) {
 	if err := ValidateL4Routes(tcpRoutes, udpRoutes); err != nil {
@@ -63,7 +82,15 @@ func CompileRoutes(httpRoutes []TraefikRoute, tcpRoutes []TraefikTCPRoute, udpRo
 		if _, exists := config.HTTP.Routers[name]; exists {
 			return nil, duplicateResource("HTTP", name)
 		}
-		config.HTTP.Routers[name] = routerWithMiddleware{Rule: fmt.Sprintf("Host(`%s`)", route.Domain), EntryPoints: []string{"websecure"}, Service: name, TLS: &tlsConfig{}, Middlewares: middlewareNames}
+		routerTLS := &tlsConfig{}
+		if route.ClientAuth != nil {
+			options, err := clientAuthOptions(&config, *route.ClientAuth)
+			if err != nil {
+				return nil, fmt.Errorf("invalid client auth for HTTP route %s: %w", route.ID, err)
+			}
+			routerTLS.Options = options
+		}
+		config.HTTP.Routers[name] = routerWithMiddleware{Rule: fmt.Sprintf("Host(`%s`)", route.Domain), EntryPoints: []string{"websecure"}, Service: name, TLS: routerTLS, Middlewares: middlewareNames}
 		servers := make([]server, len(route.Upstreams))
 		for i, upstream := range route.Upstreams {
 			servers[i] = server{URL: fmt.Sprintf("http://%s", upstream.URL)}
@@ -81,21 +108,51 @@ func CompileRoutes(httpRoutes []TraefikRoute, tcpRoutes []TraefikTCPRoute, udpRo
 		if _, exists := config.TCP.Routers[name]; exists {
 			return nil, duplicateResource("TCP", name)
 		}
+		if err := validateTCPRouteTLS(route); err != nil {
+			return nil, fmt.Errorf("invalid TCP route %s: %w", route.ID, err)
+		}
 		router := tcpRouter{Rule: "HostSNI(`*`)", EntryPoints: []string{fmt.Sprintf("tcp-%d", route.ExternalPort)}, Service: name}
-		if route.TLSPassthrough {
+		if route.Domain != "" {
+			router.Rule = fmt.Sprintf("HostSNI(`%s`)", route.Domain)
+		}
+		switch {
+		case route.TLSPassthrough:
 			router.TLS = &tcpTLSConfig{Passthrough: true}
+		case route.TLSTermination:
+			// Without a certResolver Traefik picks the certificate from the
+			// default store by SNI, i.e. the same tls.yaml certificates HTTP
+			// routes are served with.
+			router.TLS = &tcpTLSConfig{}
+			if route.ClientAuth != nil {
+				options, err := clientAuthOptions(&config, *route.ClientAuth)
+				if err != nil {
+					return nil, fmt.Errorf("invalid client auth for TCP route %s: %w", route.ID, err)
+				}
+				router.TLS.Options = options
+			}
 		}
 		config.TCP.Routers[name] = router
 		servers := make([]tcpServer, len(route.Upstreams))
 		for i, upstream := range route.Upstreams {
 			servers[i] = tcpServer{Address: upstream}
 		}
-		config.TCP.Services[name] = tcpService{LoadBalancer: tcpLoadBalancer{Servers: servers}}
+		loadBalancer := tcpLoadBalancer{Servers: servers}
+		if route.ProxyProtocol != 0 {
+			loadBalancer.ProxyProtocol = &tcpProxyProtocol{Version: route.ProxyProtocol}
+		}
+		config.TCP.Services[name] = tcpService{LoadBalancer: loadBalancer}
 	}
 	for _, route := range udpRoutes {
 		if len(route.Upstreams) == 0 {
 			continue
 		}
+		// Traefik only speaks PROXY protocol to TCP backends. Skipping the route
+		// keeps it from silently dropping the client address without failing
+		// every other route on the proxy.
+		if route.ProxyProtocol != 0 {
+			log.Printf("[traefik] skipping UDP route %s: PROXY protocol is not supported for UDP", route.ID)
+			continue
+		}
 		name := resourceName("udp", route.ServiceId, route.ID)
 		if _, exists := config.UDP.Routers[name]; exists {
 			return nil, duplicateResource("UDP", name)
@@ -0,0 +1,64 @@
+package traefik
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"fmt"
+)
+
+const (
+	ClientAuthRequireAndVerify = "RequireAndVerifyClientCert"
+	ClientAuthVerifyIfGiven    = "VerifyClientCertIfGiven"
+	ClientAuthRequireAny       = "RequireAnyClientCert"
+	ClientAuthRequest          = "RequestClientCert"
+)
+
+type ClientAuth struct {
+	CABundle string
+	Mode     string
+}
+
+func (c ClientAuth) mode() string {
+	if c.Mode == "" {
+		return ClientAuthRequireAndVerify
+	}
+	return c.Mode
+}
+
+func ValidateClientAuth(auth ClientAuth) error {
+	switch auth.mode() {
+	case ClientAuthRequireAndVerify, ClientAuthVerifyIfGiven:
+		if auth.CABundle == "" {
+			return fmt.Errorf("client auth mode %s requires a CA bundle", auth.mode())
+		}
+	case ClientAuthRequireAny, ClientAuthRequest:
+	default:
+		return fmt.Errorf("unsupported client auth mode %q", auth.Mode)
+	}
+	if auth.CABundle != "" && !x509.NewCertPool().AppendCertsFromPEM([]byte(auth.CABundle)) {
+		return fmt.Errorf("client auth CA bundle contains no certificates")
+	}
+	return nil
+}
+
+// clientAuthOptions registers TLS options for a client auth policy and returns
+// their name. Routes with the same policy share one options entry, and the CA
+// bundle is inlined so routes.yaml stays the single durable source of routing.
+func clientAuthOptions(config *traefikFullConfigWithMiddlewares, auth ClientAuth) (string, error) {
+	if err := ValidateClientAuth(auth); err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256([]byte(auth.mode() + "\x00" + auth.CABundle))
+	name := "mtls-" + hex.EncodeToString(hash[:8])
+
+	if config.TLS == nil {
+		config.TLS = &routesTLSSection{Options: map[string]tlsOptions{}}
+	}
+	options := tlsOptions{MinVersion: "VersionTLS12", ClientAuth: tlsClientAuth{ClientAuthType: auth.mode()}}
+	if auth.CABundle != "" {
+		options.ClientAuth.CAFiles = []string{auth.CABundle}
+	}
+	config.TLS.Options[name] = options
+	return name, nil
+}
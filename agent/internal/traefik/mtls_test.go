@@ -0,0 +1,103 @@
+package traefik
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompileRoutesAddsClientAuthOptions(t *testing.T) {
+	originalDir := dynamicConfigDir
+	t.Cleanup(func() { dynamicConfigDir = originalDir })
+	dynamicConfigDir = t.TempDir()
+
+	caBundle, _ := testKeyPair(t, []string{"client-ca.example.com"}, time.Now().Add(365*24*time.Hour))
+	auth := &ClientAuth{CABundle: caBundle}
+	routes := []TraefikRoute{
+		{ID: "b2b.example.com", Domain: "b2b.example.com", ServiceId: "svc-1", Upstreams: []Upstream{{URL: "10.0.0.1:3000"}}, ClientAuth: auth},
+		{ID: "partners.example.com", Domain: "partners.example.com", ServiceId: "svc-1", Upstreams: []Upstream{{URL: "10.0.0.1:3000"}}, ClientAuth: auth},
+		{ID: "www.example.com", Domain: "www.example.com", ServiceId: "svc-2", Upstreams: []Upstream{{URL: "10.0.0.2:3000"}}},
+	}
+	tcpRoutes := []TraefikTCPRoute{{
+		ID:             "tcp-route",
+		ServiceId:      "svc-3",
+		Upstreams:      []string{"10.0.0.3:5432"},
+		ExternalPort:   TCPPortStart,
+		TLSTermination: true,
+		Domain:         "db.example.com",
+		ClientAuth:     &ClientAuth{CABundle: caBundle, Mode: ClientAuthVerifyIfGiven},
+	}}
+
+	compiled, err := CompileRoutes(routes, tcpRoutes, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := WriteRoutesConfig(compiled); err != nil {
+		t.Fatal(err)
+	}
+	config, err := readCurrentFullConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if config.TLS == nil || len(config.TLS.Options) != 2 {
+		t.Fatalf("TLS options = %#v, want one shared HTTP policy and one TCP policy", config.TLS)
+	}
+	b2b := config.HTTP.Routers[resourceName("http", "svc-1", "b2b.example.com")]
+	partners := config.HTTP.Routers[resourceName("http", "svc-1", "partners.example.com")]
+	if b2b.TLS == nil || b2b.TLS.Options == "" || b2b.TLS.Options != partners.TLS.Options {
+		t.Fatalf("mTLS routers do not share options: %#v %#v", b2b.TLS, partners.TLS)
+	}
+	options := config.TLS.Options[b2b.TLS.Options]
+	if options.ClientAuth.ClientAuthType != ClientAuthRequireAndVerify || len(options.ClientAuth.CAFiles) != 1 || options.ClientAuth.CAFiles[0] != caBundle {
+		t.Fatalf("client auth options = %#v", options)
+	}
+	if plain := config.HTTP.Routers[resourceName("http", "svc-2", "www.example.com")]; plain.TLS == nil || plain.TLS.Options != "" {
+		t.Fatalf("route without client auth got TLS options: %#v", plain.TLS)
+	}
+	tcpRouter := config.TCP.Routers[resourceName("tcp", "svc-3", "tcp-route")]
+	if tcpRouter.TLS == nil || tcpRouter.TLS.Passthrough || config.TLS.Options[tcpRouter.TLS.Options].ClientAuth.ClientAuthType != ClientAuthVerifyIfGiven {
+		t.Fatalf("TCP router TLS = %#v", tcpRouter.TLS)
+	}
+	if got, want := GetCurrentConfigHash(), HashRoutesConfig(compiled); got != want {
+		t.Fatalf("current config hash %q, want %q", got, want)
+	}
+}
+
+func TestCompileRoutesRejectsInvalidClientAuth(t *testing.T) {
+	upstreams := []Upstream{{URL: "10.0.0.1:3000"}}
+	tests := map[string]struct {
+		http []TraefikRoute
+		tcp  []TraefikTCPRoute
+		want string
+	}{
+		"missing CA": {
+			http: []TraefikRoute{{ID: "r", Domain: "a.example.com", Upstreams: upstreams, ClientAuth: &ClientAuth{}}},
+			want: "requires a CA bundle",
+		},
+		"bad CA": {
+			http: []TraefikRoute{{ID: "r", Domain: "a.example.com", Upstreams: upstreams, ClientAuth: &ClientAuth{CABundle: "nope"}}},
+			want: "contains no certificates",
+		},
+		"unknown mode": {
+			http: []TraefikRoute{{ID: "r", Domain: "a.example.com", Upstreams: upstreams, ClientAuth: &ClientAuth{Mode: "Sometimes"}}},
+			want: "unsupported client auth mode",
+		},
+		"passthrough": {
+			tcp:  []TraefikTCPRoute{{ID: "t", Upstreams: []string{"10.0.0.1:5432"}, ExternalPort: TCPPortStart, TLSPassthrough: true, ClientAuth: &ClientAuth{Mode: ClientAuthRequireAny}}},
+			want: "requires TLS termination",
+		},
+		"plain TCP": {
+			tcp:  []TraefikTCPRoute{{ID: "t", Upstreams: []string{"10.0.0.1:5432"}, ExternalPort: TCPPortStart, ClientAuth: &ClientAuth{Mode: ClientAuthRequireAny}}},
+			want: "requires TLS termination",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := CompileRoutes(tt.http, tt.tcp, nil, "")
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -136,3 +136,77 @@ func TestHashRoutesConfigDoesNotMutateInput(t *testing.T) {
 		t.Fatalf("hashing reordered its input: %#v", servers)
 	}
 }
+
+func TestCompileRoutesTerminatesTLSForTCPRoutesBySNI(t *testing.T) {
+	tcpRoutes := []TraefikTCPRoute{
+		{ID: "postgres", ServiceId: "svc-db", Upstreams: []string{"10.0.0.1:5432"}, ExternalPort: TCPPortStart, TLSTermination: true, Domain: "db.example.com"},
+		{ID: "redis", ServiceId: "svc-cache", Upstreams: []string{"10.0.0.2:6379"}, ExternalPort: TCPPortStart, TLSTermination: true, Domain: "cache.example.com"},
+	}
+	compiled, err := CompileRoutes(nil, tcpRoutes, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	postgres := compiled.config.TCP.Routers[resourceName("tcp", "svc-db", "postgres")]
+	if postgres.Rule != "HostSNI(`db.example.com`)" || postgres.TLS == nil || postgres.TLS.Passthrough {
+		t.Fatalf("postgres router = %#v", postgres)
+	}
+	redis := compiled.config.TCP.Routers[resourceName("tcp", "svc-cache", "redis")]
+	if redis.Rule != "HostSNI(`cache.example.com`)" || redis.EntryPoints[0] != postgres.EntryPoints[0] {
+		t.Fatalf("redis router = %#v, want SNI routing on the shared entry point", redis)
+	}
+
+	invalid := []TraefikTCPRoute{
+		{ID: "both", Upstreams: []string{"10.0.0.1:5432"}, ExternalPort: TCPPortStart, TLSTermination: true, TLSPassthrough: true, Domain: "db.example.com"},
+		{ID: "no-domain", Upstreams: []string{"10.0.0.1:5432"}, ExternalPort: TCPPortStart, TLSTermination: true},
+		{ID: "plain-sni", Upstreams: []string{"10.0.0.1:5432"}, ExternalPort: TCPPortStart, Domain: "db.example.com"},
+	}
+	for _, route := range invalid {
+		if _, err := CompileRoutes(nil, []TraefikTCPRoute{route}, nil, ""); err == nil {
+			t.Fatalf("route %s compiled, want an error", route.ID)
+		}
+	}
+}
+
+func TestCompileRoutesEnablesProxyProtocolPerTCPRoute(t *testing.T) {
+	tcpRoutes := []TraefikTCPRoute{
+		{ID: "smtp", ServiceId: "svc-mail", Upstreams: []string{"10.0.0.1:25"}, ExternalPort: TCPPortStart, ProxyProtocol: 2},
+		{ID: "plain", ServiceId: "svc-plain", Upstreams: []string{"10.0.0.2:9000"}, ExternalPort: TCPPortStart + 1},
+	}
+	compiled, err := CompileRoutes(nil, tcpRoutes, nil, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	smtp := compiled.config.TCP.Services[resourceName("tcp", "svc-mail", "smtp")]
+	if smtp.LoadBalancer.ProxyProtocol == nil || smtp.LoadBalancer.ProxyProtocol.Version != 2 {
+		t.Fatalf("smtp load balancer = %#v", smtp.LoadBalancer)
+	}
+	if plain := compiled.config.TCP.Services[resourceName("tcp", "svc-plain", "plain")]; plain.LoadBalancer.ProxyProtocol != nil {
+		t.Fatalf("PROXY protocol enabled without opt-in: %#v", plain.LoadBalancer)
+	}
+
+	if _, err := CompileRoutes(nil, []TraefikTCPRoute{{ID: "bad", Upstreams: []string{"10.0.0.1:25"}, ExternalPort: TCPPortStart, ProxyProtocol: 3}}, nil, ""); err == nil {
+		t.Fatal("expected unsupported PROXY protocol version to be rejected")
+	}
+}
+
+func TestCompileRoutesSkipsUDPRoutesWithProxyProtocol(t *testing.T) {
+	tcpRoutes := []TraefikTCPRoute{{ID: "smtp", ServiceId: "svc-mail", Upstreams: []string{"10.0.0.1:25"}, ExternalPort: TCPPortStart}}
+	udpRoutes := []TraefikUDPRoute{
+		{ID: "game", ServiceId: "svc-game", Upstreams: []string{"10.0.0.1:27015"}, ExternalPort: UDPPortStart, ProxyProtocol: 1},
+		{ID: "dns", ServiceId: "svc-dns", Upstreams: []string{"10.0.0.2:53"}, ExternalPort: UDPPortStart + 1},
+	}
+	compiled, err := CompileRoutes(nil, tcpRoutes, udpRoutes, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := compiled.config.UDP.Routers[resourceName("udp", "svc-game", "game")]; ok {
+		t.Fatal("UDP route with PROXY protocol was compiled")
+	}
+	if _, ok := compiled.config.UDP.Routers[resourceName("udp", "svc-dns", "dns")]; !ok {
+		t.Fatal("valid UDP route was dropped")
+	}
+	if _, ok := compiled.config.TCP.Routers[resourceName("tcp", "svc-mail", "smtp")]; !ok {
+		t.Fatal("TCP route was dropped")
+	}
+}
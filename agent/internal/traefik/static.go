@@ -3,9 +3,11 @@ package traefik
 import (
 	"fmt"
 	"log"
+	"net"
 	"os"
 	"os/exec"
 	"reflect"
+	"sort"
 
 	"gopkg.in/yaml.v3"
 )
@@ -204,6 +206,100 @@ func ensurePrometheusMetricsConfig(config map[string]interface{}) bool {
 	return modified
 }
 
+var forwardedHeadersEntryPoints = []string{"web", "websecure"}
+
+func NormalizeTrustedIPs(values []string) ([]string, error) {
+	seen := map[string]bool{}
+	var normalized []string
+	for _, value := range values {
+		var entry string
+		if _, network, err := net.ParseCIDR(value); err == nil {
+			entry = network.String()
+		} else if ip := net.ParseIP(value); ip != nil {
+			entry = ip.String()
+		} else {
+			return nil, fmt.Errorf("invalid trusted proxy %q: must be an IP address or CIDR", value)
+		}
+		if !seen[entry] {
+			seen[entry] = true
+			normalized = append(normalized, entry)
+		}
+	}
+	sort.Strings(normalized)
+	return normalized, nil
+}
+
+// EnsureForwardedHeaders makes the HTTP entry points trust X-Forwarded-*
+// headers only from the given proxies, so X-Forwarded-For carries the real
+// client address when the proxy sits behind a load balancer or CDN.
+func EnsureForwardedHeaders(trustedIPs []string) (needsRestart bool, err error) {
+	needsRestart, err = updateStaticConfig(func(config map[string]interface{}) bool {
+		return ensureForwardedHeadersConfig(config, trustedIPs)
+	})
+	if err != nil || !needsRestart {
+		return needsRestart, err
+	}
+
+	log.Printf("[traefik] trusted proxies updated (%d entries), restart required", len(trustedIPs))
+	return true, nil
+}
+
+func ensureForwardedHeadersConfig(config map[string]interface{}, trustedIPs []string) bool {
+	entryPoints, ok := config["entryPoints"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+
+	modified := false
+	for _, name := range forwardedHeadersEntryPoints {
+		entryPoint, ok := entryPoints[name].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if len(trustedIPs) == 0 {
+			if _, exists := entryPoint["forwardedHeaders"]; exists {
+				delete(entryPoint, "forwardedHeaders")
+				modified = true
+			}
+			continue
+		}
+		ips := make([]interface{}, len(trustedIPs))
+		for i, ip := range trustedIPs {
+			ips[i] = ip
+		}
+		if setMapValue(entryPoint, "forwardedHeaders", map[string]interface{}{"trustedIPs": ips}) {
+			modified = true
+		}
+	}
+	return modified
+}
+
+func CurrentTrustedIPs() []string {
+	data, err := os.ReadFile(traefikStaticConfigPath)
+	if err != nil {
+		return nil
+	}
+	var config map[string]interface{}
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return nil
+	}
+	return trustedIPsFromConfig(config)
+}
+
+func trustedIPsFromConfig(config map[string]interface{}) []string {
+	entryPoints, _ := config["entryPoints"].(map[string]interface{})
+	entryPoint, _ := entryPoints["websecure"].(map[string]interface{})
+	forwardedHeaders, _ := entryPoint["forwardedHeaders"].(map[string]interface{})
+	values, _ := forwardedHeaders["trustedIPs"].([]interface{})
+	var ips []string
+	for _, value := range values {
+		if ip, ok := value.(string); ok {
+			ips = append(ips, ip)
+		}
+	}
+	return ips
+}
+
 func setMapValue(values map[string]interface{}, key string, value interface{}) bool {
 	if reflect.DeepEqual(values[key], value) {
 		return false
@@ -62,3 +62,47 @@ func TestEnsurePrometheusMetricsConfigIsStable(t *testing.T) {
 		t.Fatal("expected second call to be stable")
 	}
 }
+
+func TestEnsureForwardedHeadersConfigTrustsOnlyConfiguredProxies(t *testing.T) {
+	config := map[string]interface{}{
+		"entryPoints": map[string]interface{}{
+			"web":       map[string]interface{}{"address": ":80"},
+			"websecure": map[string]interface{}{"address": ":443"},
+			"tcp-10000": map[string]interface{}{"address": ":10000"},
+		},
+	}
+
+	trustedIPs, err := NormalizeTrustedIPs([]string{"10.0.0.0/8", "203.0.113.7", "10.1.2.3/8"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ensureForwardedHeadersConfig(config, trustedIPs) {
+		t.Fatal("expected config to be modified")
+	}
+
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded map[string]interface{}
+	if err := yaml.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if got := trustedIPsFromConfig(decoded); len(got) != 2 || got[0] != "10.0.0.0/8" || got[1] != "203.0.113.7" {
+		t.Fatalf("trusted IPs = %v", got)
+	}
+	if ensureForwardedHeadersConfig(decoded, trustedIPs) {
+		t.Fatal("expected round-tripped config to be stable")
+	}
+	tcpEntryPoint := decoded["entryPoints"].(map[string]interface{})["tcp-10000"].(map[string]interface{})
+	if _, exists := tcpEntryPoint["forwardedHeaders"]; exists {
+		t.Fatal("forwarded headers configured on an L4 entry point")
+	}
+
+	if !ensureForwardedHeadersConfig(decoded, nil) || len(trustedIPsFromConfig(decoded)) != 0 {
+		t.Fatal("expected trusted proxies to be removed")
+	}
+	if _, err := NormalizeTrustedIPs([]string{"not-an-ip"}); err == nil {
+		t.Fatal("expected invalid trusted proxy to be rejected")
+	}
+}
@@ -13,6 +13,7 @@ const (
 	routesFileName          = "routes.yaml"
 	tlsFileName             = "tls.yaml"
 	challengesFileName      = "challenges.yaml"
+	localChallengesFileName = "challenges-local.yaml"
 
 	TCPPortStart = 10000
 	TCPPortEnd   = 10999
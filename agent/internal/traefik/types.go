@@ -6,10 +6,11 @@ type Upstream struct {
 }
 
 type TraefikRoute struct {
-	ID        string
-	Domain    string
-	Upstreams []Upstream
-	ServiceId string
+	ID         string
+	Domain     string
+	Upstreams  []Upstream
+	ServiceId  string
+	ClientAuth *ClientAuth
 }
 
 type Certificate struct {
@@ -24,16 +25,37 @@ type TraefikTCPRoute struct {
 	Upstreams      []string
 	ExternalPort   int
 	TLSPassthrough bool
+	TLSTermination bool
+	Domain         string
+	ClientAuth     *ClientAuth
+	ProxyProtocol  int
 }
 
 type TraefikUDPRoute struct {
-	ID           string
-	ServiceId    string
-	Upstreams    []string
-	ExternalPort int
+	ID            string
+	ServiceId     string
+	Upstreams     []string
+	ExternalPort  int
+	ProxyProtocol int
+}
+
+type tlsConfig struct {
+	Options string `yaml:"options,omitempty"`
+}
+
+type routesTLSSection struct {
+	Options map[string]tlsOptions `yaml:"options,omitempty"`
 }
 
-type tlsConfig struct{}
+type tlsOptions struct {
+	MinVersion string        `yaml:"minVersion,omitempty"`
+	ClientAuth tlsClientAuth `yaml:"clientAuth"`
+}
+
+type tlsClientAuth struct {
+	CAFiles        []string `yaml:"caFiles,omitempty"`
+	ClientAuthType string   `yaml:"clientAuthType"`
+}
 
 type tlsFileConfig struct {
 	TLS tlsSection `yaml:"tls"`
@@ -119,7 +141,8 @@ type tcpRouter struct {
 }
 
 type tcpTLSConfig struct {
-	Passthrough bool `yaml:"passthrough"`
+	Passthrough bool   `yaml:"passthrough"`
+	Options     string `yaml:"options,omitempty"`
 }
 
 type tcpService struct {
@@ -127,7 +150,12 @@ type tcpService struct {
 }
 
 type tcpLoadBalancer struct {
-	Servers []tcpServer `yaml:"servers"`
+	Servers       []tcpServer       `yaml:"servers"`
+	ProxyProtocol *tcpProxyProtocol `yaml:"proxyProtocol,omitempty"`
+}
+
+type tcpProxyProtocol struct {
+	Version int `yaml:"version"`
 }
 
 type tcpServer struct {
@@ -160,4 +188,5 @@ type traefikFullConfigWithMiddlewares struct {
 	HTTP httpConfigWithMiddlewares `yaml:"http,omitempty"`
 	TCP  tcpConfig                 `yaml:"tcp,omitempty"`
 	UDP  udpConfig                 `yaml:"udp,omitempty"`
+	TLS  *routesTLSSection         `yaml:"tls,omitempty"`
 }
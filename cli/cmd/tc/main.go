@@ -14,6 +14,6 @@ func main() {
 		if !cli.IsHandledError(err) {
 			fmt.Fprintln(os.Stderr, err)
 		}
-		os.Exit(1)
+		os.Exit(cli.ExitCode(err))
 	}
 }
@@ -82,10 +82,27 @@ func (c *Client) RequestJSONWithHeaders(ctx context.Context, method, path string
 	return JSON(ctx, c.HTTPClient, method, endpoint, headers, body, out)
 }
 
-func requestJSON(ctx context.Context, client *http.Client, method, endpoint string, headers map[string]string, body any) (int, []byte, error) {
-	if client == nil {
-		client = http.DefaultClient
+// Upload sends raw bytes with the given content type and decodes a JSON
+// response. It is bounded by ctx rather than the client timeout because large
+// uploads can outlast it.
+func (c *Client) Upload(ctx context.Context, method, path, contentType string, body []byte, out any) error {
+	headers := map[string]string{"content-type": contentType}
+	if c.APIKey != "" {
+		headers["x-api-key"] = c.APIKey
+	}
+	client := &http.Client{}
+	if c.HTTPClient != nil {
+		client.Transport = c.HTTPClient.Transport
 	}
+	endpoint := c.Host + path
+	status, raw, err := do(ctx, client, method, endpoint, headers, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	return decodeResponse(endpoint, status, raw, out)
+}
+
+func requestJSON(ctx context.Context, client *http.Client, method, endpoint string, headers map[string]string, body any) (int, []byte, error) {
 	var reader io.Reader
 	if body != nil {
 		raw, err := json.Marshal(body)
@@ -94,11 +111,21 @@ func requestJSON(ctx context.Context, client *http.Client, method, endpoint stri
 		}
 		reader = bytes.NewReader(raw)
 	}
-	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	jsonHeaders := map[string]string{"content-type": "application/json"}
+	for key, value := range headers {
+		jsonHeaders[key] = value
+	}
+	return do(ctx, client, method, endpoint, jsonHeaders, reader)
+}
+
+func do(ctx context.Context, client *http.Client, method, endpoint string, headers map[string]string, body io.Reader) (int, []byte, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, body)
 	if err != nil {
 		return 0, nil, err
 	}
-	req.Header.Set("content-type", "application/json")
 	for key, value := range headers {
 		req.Header.Set(key, value)
 	}
@@ -118,6 +145,10 @@ func JSON(ctx context.Context, client *http.Client, method, endpoint string, hea
 	if err != nil {
 		return err
 	}
+	return decodeResponse(endpoint, status, raw, out)
+}
+
+func decodeResponse(endpoint string, status int, raw []byte, out any) error {
 	if status < 200 || status >= 300 {
 		var apiErr ErrorResponse
 		_ = json.Unmarshal(raw, &apiErr)
@@ -33,6 +33,26 @@ func TestRequestJSONSuccess(t *testing.T) {
 	}
 }
 
+func TestUploadSendsRawBody(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if r.Header.Get("content-type") != "application/gzip" || r.Header.Get("x-api-key") != "secret" || string(body) != "archive" {
+			t.Fatalf("content-type=%q key=%q body=%q", r.Header.Get("content-type"), r.Header.Get("x-api-key"), body)
+		}
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+		w.Write([]byte(`{"message":"Build context exceeds the 100 MiB limit","code":"BUILD_CONTEXT_TOO_LARGE"}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "secret")
+	client.HTTPClient = server.Client()
+	err := client.Upload(context.Background(), http.MethodPost, "/upload", "application/gzip", []byte("archive"), nil)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Status != http.StatusRequestEntityTooLarge || apiErr.Code != "BUILD_CONTEXT_TOO_LARGE" {
+		t.Fatalf("error = %v", err)
+	}
+}
+
 func TestRequestJSONAPIError(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.WriteHeader(http.StatusBadRequest)
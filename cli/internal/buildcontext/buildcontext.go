@@ -0,0 +1,178 @@
+package buildcontext
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+type Summary struct {
+	Files int
+	Bytes int64
+}
+
+type gitRules struct {
+	base  string
+	rules ignoreRules
+}
+
+type walker struct {
+	root   string
+	docker ignoreRules
+	tw     *tar.Writer
+	sum    Summary
+}
+
+type verdict struct {
+	docker bool
+	git    []bool
+}
+
+// Pack writes dir as a gzip-compressed tarball. The .git directory is always
+// left out, as is anything matched by the root .dockerignore or by any
+// .gitignore in the tree.
+func Pack(dir string, w io.Writer) (Summary, error) {
+	root, err := filepath.Abs(dir)
+	if err != nil {
+		return Summary{}, err
+	}
+	docker, err := readIgnoreFile(filepath.Join(root, ".dockerignore"), true)
+	if err != nil {
+		return Summary{}, fmt.Errorf("read .dockerignore: %w", err)
+	}
+
+	gz := gzip.NewWriter(w)
+	tw := tar.NewWriter(gz)
+	pack := &walker{root: root, docker: docker, tw: tw}
+	if err := pack.walk("", nil, verdict{}); err != nil {
+		return pack.sum, err
+	}
+	if err := tw.Close(); err != nil {
+		return pack.sum, err
+	}
+	return pack.sum, gz.Close()
+}
+
+func (p *walker) walk(rel string, inherited []gitRules, parent verdict) error {
+	abs := filepath.Join(p.root, filepath.FromSlash(rel))
+	rules, err := readIgnoreFile(filepath.Join(abs, ".gitignore"), false)
+	if err != nil {
+		return fmt.Errorf("read %s: %w", path.Join(rel, ".gitignore"), err)
+	}
+	scopes := inherited
+	if len(rules) > 0 {
+		scopes = append(append([]gitRules(nil), inherited...), gitRules{base: rel, rules: rules})
+	}
+	gitParent := append(append([]bool(nil), parent.git...), make([]bool, len(scopes)-len(parent.git))...)
+
+	entries, err := os.ReadDir(abs)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		name := entry.Name()
+		if rel == "" && name == ".git" {
+			continue
+		}
+		entryRel := path.Join(rel, name)
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		isDir := info.IsDir()
+
+		current := verdict{docker: p.docker.match(entryRel, isDir, parent.docker), git: make([]bool, len(scopes))}
+		ignored := current.docker
+		for i, scope := range scopes {
+			scoped := entryRel
+			if scope.base != "" {
+				scoped = entryRel[len(scope.base)+1:]
+			}
+			current.git[i] = scope.rules.match(scoped, isDir, gitParent[i])
+			ignored = ignored || current.git[i]
+		}
+
+		if isDir {
+			if ignored && !p.mayReinclude(scopes) {
+				continue
+			}
+			if !ignored {
+				if err := p.writeHeader(entryRel, info, ""); err != nil {
+					return err
+				}
+			}
+			if err := p.walk(entryRel, scopes, current); err != nil {
+				return err
+			}
+			continue
+		}
+		if ignored {
+			continue
+		}
+		if err := p.writeEntry(entryRel, filepath.Join(abs, name), info); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mayReinclude reports whether a negated pattern could bring back a file
+// beneath an ignored directory, so the walk must descend into it.
+func (p *walker) mayReinclude(scopes []gitRules) bool {
+	if p.docker.hasNegations() {
+		return true
+	}
+	for _, scope := range scopes {
+		if scope.rules.hasNegations() {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *walker) writeEntry(rel, abs string, info os.FileInfo) error {
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(abs)
+		if err != nil {
+			return err
+		}
+		return p.writeHeader(rel, info, target)
+	case info.Mode().IsRegular():
+		if err := p.writeHeader(rel, info, ""); err != nil {
+			return err
+		}
+		file, err := os.Open(abs)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		written, err := io.Copy(p.tw, file)
+		if err != nil {
+			return fmt.Errorf("archive %s: %w", rel, err)
+		}
+		p.sum.Files++
+		p.sum.Bytes += written
+		return nil
+	default:
+		return nil
+	}
+}
+
+func (p *walker) writeHeader(rel string, info os.FileInfo, link string) error {
+	header, err := tar.FileInfoHeader(info, link)
+	if err != nil {
+		return err
+	}
+	header.Name = rel
+	if info.IsDir() {
+		header.Name += "/"
+	}
+	header.Uid, header.Gid = 0, 0
+	header.Uname, header.Gname = "", ""
+	return p.tw.WriteHeader(header)
+}
@@ -0,0 +1,165 @@
+package buildcontext
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func writeTree(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, body := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func packedFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var buf bytes.Buffer
+	if _, err := Pack(dir, &buf); err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reader := tar.NewReader(gz)
+	var files []string
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Typeflag != tar.TypeDir {
+			files = append(files, header.Name)
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+func TestPackHonoursIgnoreFiles(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		".git/HEAD":               "ref: refs/heads/main",
+		".gitignore":              "node_modules/\n*.log\n/dist\n!keep.log\n",
+		".dockerignore":           "# docs are not needed\ndocs\n**/*.test.js\n",
+		"Dockerfile":              "FROM node",
+		"app.log":                 "x",
+		"keep.log":                "x",
+		"dist/bundle.js":          "x",
+		"docs/index.md":           "x",
+		"node_modules/a/index.js": "x",
+		"src/app.js":              "x",
+		"src/app.test.js":         "x",
+		"src/dist/keep.js":        "x",
+		"src/.gitignore":          "generated.js\n",
+		"src/generated.js":        "x",
+		"lib/generated.js":        "x",
+	})
+
+	want := []string{
+		".dockerignore",
+		".gitignore",
+		"Dockerfile",
+		"keep.log",
+		"lib/generated.js",
+		"src/.gitignore",
+		"src/app.js",
+		"src/dist/keep.js",
+	}
+	if got := packedFiles(t, dir); !reflect.DeepEqual(got, want) {
+		t.Fatalf("packed files = %v, want %v", got, want)
+	}
+}
+
+func TestPackReincludesFileUnderIgnoredDirectory(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		".dockerignore":     "config\n!config/app.yml\n",
+		"config/app.yml":    "x",
+		"config/secret.yml": "x",
+	})
+
+	want := []string{".dockerignore", "config/app.yml"}
+	if got := packedFiles(t, dir); !reflect.DeepEqual(got, want) {
+		t.Fatalf("packed files = %v, want %v", got, want)
+	}
+}
+
+func TestPackKeepsSymlinks(t *testing.T) {
+	dir := writeTree(t, map[string]string{"README.md": "x"})
+	if err := os.Symlink("README.md", filepath.Join(dir, "readme")); err != nil {
+		t.Skip("symlinks unsupported:", err)
+	}
+
+	var buf bytes.Buffer
+	summary, err := Pack(dir, &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Files != 1 || summary.Bytes != 1 {
+		t.Fatalf("summary = %+v", summary)
+	}
+	gz, _ := gzip.NewReader(&buf)
+	reader := tar.NewReader(gz)
+	for {
+		header, err := reader.Next()
+		if err == io.EOF {
+			t.Fatal("symlink missing from archive")
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if header.Name == "readme" {
+			if header.Typeflag != tar.TypeSymlink || header.Linkname != "README.md" {
+				t.Fatalf("symlink header = %+v", header)
+			}
+			return
+		}
+	}
+}
+
+func TestParseIgnoreLine(t *testing.T) {
+	tests := []struct {
+		line     string
+		anchored bool
+		path     string
+		isDir    bool
+		match    bool
+	}{
+		{"*.log", false, "deep/nested/app.log", false, true},
+		{"*.log", true, "deep/app.log", false, false},
+		{"/build", false, "src/build", true, false},
+		{"build/", false, "src/build", true, true},
+		{"build/", false, "src/build", false, false},
+		{"a/**/b", false, "a/x/y/b", false, true},
+		{"logs/**", false, "logs/today/app", false, true},
+		{"file[0-9].txt", true, "file7.txt", false, true},
+		{"./tmp", true, "tmp", true, true},
+	}
+	for _, tc := range tests {
+		rule, ok := parseIgnoreLine(tc.line, tc.anchored)
+		if !ok {
+			t.Fatalf("parseIgnoreLine(%q) rejected", tc.line)
+		}
+		if got := (ignoreRules{rule}).match(tc.path, tc.isDir, false); got != tc.match {
+			t.Fatalf("%q (anchored=%v) match %q = %v, want %v", tc.line, tc.anchored, tc.path, got, tc.match)
+		}
+	}
+}
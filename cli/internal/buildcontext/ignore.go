@@ -0,0 +1,144 @@
+package buildcontext
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+)
+
+type ignoreRule struct {
+	pattern *regexp.Regexp
+	negate  bool
+	dirOnly bool
+}
+
+// ignoreRules is an ordered pattern list where the last matching rule wins,
+// as in both .gitignore and .dockerignore.
+type ignoreRules []ignoreRule
+
+func (rules ignoreRules) hasNegations() bool {
+	for _, rule := range rules {
+		if rule.negate {
+			return true
+		}
+	}
+	return false
+}
+
+// match reports whether rel (slash-separated, relative to the rules' base) is
+// ignored. parentIgnored carries the verdict for the containing directory so
+// that ignoring a directory ignores everything beneath it.
+func (rules ignoreRules) match(rel string, isDir, parentIgnored bool) bool {
+	ignored := parentIgnored
+	for _, rule := range rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+		if rule.pattern.MatchString(rel) {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+func readIgnoreFile(file string, anchored bool) (ignoreRules, error) {
+	f, err := os.Open(file)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var rules ignoreRules
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if rule, ok := parseIgnoreLine(scanner.Text(), anchored); ok {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, scanner.Err()
+}
+
+// parseIgnoreLine compiles one pattern. Docker anchors every pattern to the
+// context root; git anchors only patterns containing a slash and otherwise
+// matches the name at any depth.
+func parseIgnoreLine(line string, anchored bool) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if anchored {
+		line = strings.TrimSpace(line)
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+	var rule ignoreRule
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = !anchored
+		line = strings.TrimRight(line, "/")
+	}
+	if !strings.Contains(line, "/") && !anchored {
+		line = "**/" + line
+	}
+	line = strings.TrimPrefix(line, "/")
+	if anchored {
+		line = strings.TrimPrefix(path.Clean("/"+line), "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+	pattern, err := regexp.Compile("^" + globToRegexp(line) + "$")
+	if err != nil {
+		return ignoreRule{}, false
+	}
+	rule.pattern = pattern
+	return rule, true
+}
+
+func globToRegexp(glob string) string {
+	var sb strings.Builder
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch {
+		case strings.HasPrefix(glob[i:], "**/"):
+			sb.WriteString("(?:.*/)?")
+			i += 2
+		case strings.HasPrefix(glob[i:], "/**") && i+3 == len(glob):
+			sb.WriteString("/.*")
+			i += 2
+		case strings.HasPrefix(glob[i:], "**"):
+			sb.WriteString(".*")
+			i++
+		case c == '*':
+			sb.WriteString("[^/]*")
+		case c == '?':
+			sb.WriteString("[^/]")
+		case c == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				sb.WriteString(`\[`)
+				continue
+			}
+			class := glob[i+1 : i+1+end]
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+			sb.WriteString("[" + strings.ReplaceAll(class, `\`, `\\`) + "]")
+			i += end + 1
+		case c == '\\' && i+1 < len(glob):
+			i++
+			sb.WriteString(regexp.QuoteMeta(string(glob[i])))
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	return sb.String()
+}
@@ -2,10 +2,12 @@ package cli
 
 import (
 	"bufio"
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"net/http"
 	"net/url"
 	"os"
@@ -22,6 +24,7 @@ import (
 
 	"techulus/cloud-cli/internal/api"
 	"techulus/cloud-cli/internal/auth"
+	"techulus/cloud-cli/internal/buildcontext"
 	"techulus/cloud-cli/internal/manifest"
 	"techulus/cloud-cli/internal/output"
 )
@@ -31,6 +34,8 @@ const (
 	defaultLogTail    = 100
 	logPollInterval   = 2 * time.Second
 	defaultAPITimeout = 30 * time.Second
+	// maxBuildContextBytes matches the control plane's upload limit.
+	maxBuildContextBytes = 100 << 20
 )
 
 type App struct {
@@ -42,8 +47,11 @@ type App struct {
 	Sleep         func(time.Duration)
 	IsInteractive func() bool
 	GetCWD        func() (string, error)
-	configStore   auth.ConfigStore
-	flags         globalFlags
+	// ResolveGitCommit resolves an SSH git source's branch head locally,
+	// since the control plane cannot reach SSH remotes.
+	ResolveGitCommit func(ctx context.Context, repository, branch string) (string, error)
+	configStore      auth.ConfigStore
+	flags            globalFlags
 }
 
 type globalFlags struct {
@@ -76,6 +84,24 @@ func IsHandledError(err error) bool {
 	return errors.As(err, &handled)
 }
 
+type exitCodeError struct {
+	code int
+	err  error
+}
+
+func (e exitCodeError) Error() string { return e.err.Error() }
+func (e exitCodeError) Unwrap() error { return e.err }
+
+// ExitCode returns the process exit status for err, passing through the
+// exit code of a failed `tc run` task.
+func ExitCode(err error) int {
+	var exit exitCodeError
+	if errors.As(err, &exit) && exit.code > 0 {
+		return exit.code
+	}
+	return 1
+}
+
 func Execute(version string, in io.Reader, out io.Writer, errOut io.Writer) error {
 	app := NewApp(version, in, out, errOut)
 	return app.Execute()
@@ -106,7 +132,8 @@ func NewApp(version string, in io.Reader, out io.Writer, errOut io.Writer) *App
 			}
 			return os.Getwd()
 		},
-		configStore: auth.NewConfigStore(version),
+		ResolveGitCommit: lsRemoteCommit,
+		configStore:      auth.NewConfigStore(version),
 	}
 }
 
@@ -118,7 +145,7 @@ func (a *App) Execute() error {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
 	defer stop()
 	if err := cmd.ExecuteContext(ctx); err != nil {
-		if a.isMachineOutput() {
+		if a.isMachineOutput() && !IsHandledError(err) {
 			_ = output.Error(a.Out, err)
 			return handledError{err: err}
 		}
@@ -160,13 +187,18 @@ func (a *App) rootCommand() *cobra.Command {
 	root.AddCommand(a.deployCommand())
 	root.AddCommand(a.statusCommand())
 	root.AddCommand(a.logsCommand())
+	root.AddCommand(a.runCommand())
 	root.AddCommand(a.projectsCommand())
 	root.AddCommand(a.environmentsCommand())
 	root.AddCommand(a.servicesCommand())
 	root.AddCommand(a.resourceCommand("config", "Show full service configuration", "/configuration", nil, printConfiguration))
 	root.AddCommand(a.paginatedCommand("rollouts", "List rollout history", "/rollouts", printRollouts))
 	root.AddCommand(a.rolloutCommand())
-	root.AddCommand(a.paginatedCommand("builds", "List build history", "/builds", printBuilds))
+	root.AddCommand(a.buildsCommand())
+	root.AddCommand(a.gitCredentialsCommand())
+	root.AddCommand(a.vulnerabilityPolicyCommand())
+	root.AddCommand(a.signingPolicyCommand())
+	root.AddCommand(a.cronsCommand())
 	root.AddCommand(a.metricsCommand())
 	root.AddCommand(a.revisionsCommand())
 	root.AddCommand(a.versionCommand())
@@ -421,9 +453,10 @@ func (a *App) linkCommand() *cobra.Command {
 				Current struct {
 					Hostname *string `json:"hostname"`
 					Ports    []struct {
-						ContainerPort int     `json:"containerPort"`
-						IsPublic      bool    `json:"public"`
-						Domain        *string `json:"domain"`
+						ContainerPort int                  `json:"containerPort"`
+						IsPublic      bool                 `json:"public"`
+						Domain        *string              `json:"domain"`
+						ClientAuth    *manifest.ClientAuth `json:"clientAuth"`
 					} `json:"ports"`
 					Replicas  int `json:"replicas"`
 					Placement *struct {
@@ -433,10 +466,11 @@ func (a *App) linkCommand() *cobra.Command {
 						ServerID string `json:"serverId"`
 						Count    int    `json:"count"`
 					} `json:"placements"`
-					HealthCheck  *manifest.HealthCheck `json:"healthCheck"`
-					StartCommand *string               `json:"startCommand"`
-					Resources    *manifest.Resources   `json:"resources"`
-					Crons        []manifest.Cron       `json:"crons"`
+					HealthCheck    *manifest.HealthCheck `json:"healthCheck"`
+					StartCommand   *string               `json:"startCommand"`
+					ReleaseCommand *string               `json:"releaseCommand"`
+					Resources      *manifest.Resources   `json:"resources"`
+					Crons          []manifest.Cron       `json:"crons"`
 				} `json:"current"`
 				Management *serviceManagement `json:"management"`
 			}
@@ -452,7 +486,7 @@ func (a *App) linkCommand() *cobra.Command {
 			}
 			ports := make([]manifest.Port, len(cfg.Current.Ports))
 			for i, p := range cfg.Current.Ports {
-				ports[i] = manifest.Port{ContainerPort: p.ContainerPort, Public: p.IsPublic, Domain: p.Domain}
+				ports[i] = manifest.Port{ContainerPort: p.ContainerPort, Public: p.IsPublic, Domain: p.Domain, ClientAuth: p.ClientAuth}
 			}
 			var placement *manifest.Placement
 			if cfg.Current.Placement != nil {
@@ -468,7 +502,7 @@ func (a *App) linkCommand() *cobra.Command {
 					}
 				}
 			}
-			m := manifest.Manifest{APIVersion: "v1", Target: &manifest.Target{ServiceID: service.ID}, Service: manifest.Service{Name: service.Name, Source: service.Source, Hostname: cfg.Current.Hostname, Ports: ports, Replicas: cfg.Current.Replicas, Placement: placement, HealthCheck: cfg.Current.HealthCheck, StartCommand: cfg.Current.StartCommand, Resources: cfg.Current.Resources, Crons: cfg.Current.Crons}}
+			m := manifest.Manifest{APIVersion: "v1", Target: &manifest.Target{ServiceID: service.ID}, Service: manifest.Service{Name: service.Name, Source: service.Source, Hostname: cfg.Current.Hostname, Ports: ports, Replicas: cfg.Current.Replicas, Placement: placement, HealthCheck: cfg.Current.HealthCheck, StartCommand: cfg.Current.StartCommand, ReleaseCommand: cfg.Current.ReleaseCommand, Resources: cfg.Current.Resources, Crons: cfg.Current.Crons}}
 			if existing != nil {
 				if existing.Manifest.Linked() && existing.Manifest.Target.ServiceID != service.ID {
 					return fmt.Errorf("manifest is linked to service %s; remove target.serviceId before relinking", existing.Manifest.Target.ServiceID)
@@ -521,7 +555,7 @@ func (a *App) applyCommand() *cobra.Command {
 			if crons == nil {
 				crons = []manifest.Cron{}
 			}
-			body := map[string]any{"name": loaded.Manifest.Service.Name, "source": sourcePatch(loaded.Manifest.Service.Source), "hostname": loaded.Manifest.Service.Hostname, "ports": loaded.Manifest.Service.Ports, "healthCheck": loaded.Manifest.Service.HealthCheck, "startCommand": loaded.Manifest.Service.StartCommand, "resources": loaded.Manifest.Service.Resources, "crons": crons}
+			body := map[string]any{"name": loaded.Manifest.Service.Name, "source": sourcePatch(loaded.Manifest.Service.Source), "hostname": loaded.Manifest.Service.Hostname, "ports": loaded.Manifest.Service.Ports, "healthCheck": loaded.Manifest.Service.HealthCheck, "startCommand": loaded.Manifest.Service.StartCommand, "releaseCommand": loaded.Manifest.Service.ReleaseCommand, "resources": loaded.Manifest.Service.Resources, "crons": crons}
 			if placement.Mode == "automatic" {
 				body["placement"] = map[string]any{"mode": "automatic", "replicas": loaded.Manifest.Service.Replicas}
 			} else {
@@ -641,7 +675,7 @@ func (a *App) finishLink(path string, existing *manifest.Loaded, service service
 		}
 		return a.printLinked(path, target)
 	}
-	m := manifest.Manifest{APIVersion: "v1", Target: &manifest.Target{ServiceID: serviceID}, Service: manifest.Service{Name: service.Name, Source: service.Source, Hostname: service.Hostname, Ports: service.Ports, Replicas: service.Replicas, Placement: service.Placement, HealthCheck: service.HealthCheck, StartCommand: service.StartCommand, Resources: service.Resources, Crons: service.Crons}}
+	m := manifest.Manifest{APIVersion: "v1", Target: &manifest.Target{ServiceID: serviceID}, Service: manifest.Service{Name: service.Name, Source: service.Source, Hostname: service.Hostname, Ports: service.Ports, Replicas: service.Replicas, Placement: service.Placement, HealthCheck: service.HealthCheck, StartCommand: service.StartCommand, ReleaseCommand: service.ReleaseCommand, Resources: service.Resources, Crons: service.Crons}}
 	if err := manifest.Save(path, m); err != nil {
 		return err
 	}
@@ -657,11 +691,12 @@ func (a *App) printLinked(path string, target targetContext) error {
 }
 
 func (a *App) deployCommand() *cobra.Command {
-	return &cobra.Command{
+	var local bool
+	cmd := &cobra.Command{
 		Use:   "deploy",
 		Short: "Deploy the service described by techulus.yml",
 		Annotations: map[string]string{
-			"agent_notes": "Requires techulus.yml in the current directory and queues a deployment for that service.",
+			"agent_notes": "Requires techulus.yml in the current directory and queues a deployment for that service.\nWith --local, the current directory is uploaded as the build context, honouring .dockerignore and .gitignore, and built instead of the configured source.",
 		},
 		RunE: func(cmd *cobra.Command, args []string) error {
 			config, err := a.requireConfig()
@@ -681,19 +716,43 @@ func (a *App) deployCommand() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			var persisted struct {
-				Current struct {
-					Source manifest.Source `json:"source"`
-				} `json:"current"`
-			}
-			if err := client.RequestJSON(cmd.Context(), http.MethodGet, base+"/configuration", nil, nil, &persisted); err != nil {
-				return err
-			}
-			if !sourcesEqual(loaded.Manifest.Service.Source, persisted.Current.Source) {
-				return errors.New("service source differs from techulus.yml: run `tc apply` before deploying")
-			}
-			if err := client.RequestJSON(cmd.Context(), http.MethodPost, base+"/deploy", nil, nil, &result); err != nil {
-				return err
+			var summary buildcontext.Summary
+			var commitSHA string
+			if local {
+				var archive bytes.Buffer
+				summary, err = buildcontext.Pack(filepath.Dir(loaded.Path), &archive)
+				if err != nil {
+					return fmt.Errorf("pack build context: %w", err)
+				}
+				if archive.Len() > maxBuildContextBytes {
+					return fmt.Errorf("build context is %s compressed, over the %s upload limit: exclude large paths in .dockerignore", formatBytes(float64(archive.Len())), formatBytes(maxBuildContextBytes))
+				}
+				if err := client.Upload(cmd.Context(), http.MethodPost, base+"/deploy/local", "application/gzip", archive.Bytes(), &result); err != nil {
+					return err
+				}
+			} else {
+				var persisted struct {
+					Current struct {
+						Source manifest.Source `json:"source"`
+					} `json:"current"`
+				}
+				if err := client.RequestJSON(cmd.Context(), http.MethodGet, base+"/configuration", nil, nil, &persisted); err != nil {
+					return err
+				}
+				if !sourcesEqual(loaded.Manifest.Service.Source, persisted.Current.Source) {
+					return errors.New("service source differs from techulus.yml: run `tc apply` before deploying")
+				}
+				var body any
+				if source := persisted.Current.Source; source.Type == "git" && manifest.IsSSHGitRepository(source.Repository) {
+					commitSHA, err = a.ResolveGitCommit(cmd.Context(), source.Repository, source.Branch)
+					if err != nil {
+						return fmt.Errorf("resolve %s from %s: %w", source.Branch, source.Repository, err)
+					}
+					body = map[string]string{"commitSha": commitSHA}
+				}
+				if err := client.RequestJSON(cmd.Context(), http.MethodPost, base+"/deploy", nil, body, &result); err != nil {
+					return err
+				}
 			}
 			if a.isMachineOutput() {
 				return a.writeData(result, "Deploy")
@@ -701,6 +760,12 @@ func (a *App) deployCommand() *cobra.Command {
 			output.Section(a.Out, "Deploy")
 			output.Field(a.Out, "Operation", result.Operation)
 			output.Field(a.Out, "Status", output.Status(result.Status))
+			if local {
+				output.Field(a.Out, "Context", fmt.Sprintf("%d files, %s", summary.Files, formatBytes(float64(summary.Bytes))))
+			}
+			if commitSHA != "" {
+				output.Field(a.Out, "Commit", commitSHA)
+			}
 			if result.RolloutID != nil && *result.RolloutID != "" {
 				output.Field(a.Out, "Rollout", *result.RolloutID)
 			}
@@ -711,6 +776,8 @@ func (a *App) deployCommand() *cobra.Command {
 			return nil
 		},
 	}
+	cmd.Flags().BoolVar(&local, "local", false, "Upload and build the current directory instead of the configured source")
+	return cmd
 }
 
 func (a *App) statusCommand() *cobra.Command {
@@ -793,6 +860,171 @@ func (a *App) logsCommand() *cobra.Command {
 	return cmd
 }
 
+func (a *App) runCommand() *cobra.Command {
+	var server string
+	var timeout time.Duration
+	var target serviceTargetFlags
+	cmd := &cobra.Command{
+		Use:   "run [flags] -- <command>",
+		Short: "Run a one-off command in a new container from the current deployment",
+		Args:  cobra.MinimumNArgs(1),
+		Annotations: map[string]string{
+			"agent_notes": "Starts an ephemeral container with the current deployment's image, environment and volumes, streams its logs and exits with the command's exit code.\nWithout --service, tc reads the target from techulus.yml in the current directory.\nIn --agent or --json mode, output is returned once the run finishes.",
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if timeout < time.Second || timeout > time.Hour {
+				return errors.New("timeout must be between 1s and 1h")
+			}
+			config, err := a.requireConfig()
+			if err != nil {
+				return err
+			}
+			value, err := a.resolveServiceTarget(target)
+			if err != nil {
+				return err
+			}
+			base, err := serviceBase(value)
+			if err != nil {
+				return err
+			}
+			body := map[string]any{
+				"command":        shellJoin(args),
+				"timeoutSeconds": int(timeout / time.Second),
+			}
+			if server != "" {
+				body["server"] = server
+			}
+			var started runResponse
+			client := a.client(config)
+			if err := client.RequestJSON(cmd.Context(), http.MethodPost, base+"/runs", nil, body, &started); err != nil {
+				return err
+			}
+			return a.followRun(cmd.Context(), client, base, started.Run)
+		},
+	}
+	cmd.Flags().StringVar(&server, "server", "", "Server name or ID (defaults to a server running the service)")
+	cmd.Flags().DurationVar(&timeout, "timeout", 10*time.Minute, "Stop the command after this long (max 1h)")
+	addServiceTargetFlags(cmd, &target)
+	return cmd
+}
+
+func (a *App) followRun(ctx context.Context, client *api.Client, base string, run serviceRun) error {
+	runPath := base + "/runs/" + url.PathEscape(run.ID)
+	human := !a.isMachineOutput()
+	if human {
+		output.Section(a.Out, "Run")
+		output.Field(a.Out, "ID", run.ID)
+		output.Field(a.Out, "Server", run.ServerName)
+		output.Field(a.Out, "Command", run.Command)
+		output.Section(a.Out, "Logs")
+	}
+	var collected []serviceLog
+	cursor := ""
+	fetch := func(wait bool) (logsResponse, error) {
+		query := url.Values{"limit": {"1000"}}
+		if cursor != "" {
+			query.Set("cursor", cursor)
+			if wait {
+				query.Set("wait", "5")
+			}
+		}
+		var page logsResponse
+		if err := client.RequestJSON(ctx, http.MethodGet, runPath+"/logs", query, nil, &page); err != nil {
+			return page, err
+		}
+		if human {
+			printLogs(a.Out, page.Logs)
+		} else {
+			collected = append(collected, page.Logs...)
+		}
+		if page.NextCursor != "" {
+			cursor = page.NextCursor
+		}
+		return page, nil
+	}
+	for {
+		page, err := fetch(true)
+		if err != nil {
+			return err
+		}
+		if page.HasMore {
+			continue
+		}
+		var current runResponse
+		if err := client.RequestJSON(ctx, http.MethodGet, runPath, nil, nil, &current); err != nil {
+			return err
+		}
+		run = current.Run
+		if isTerminalRunStatus(run.Status) {
+			break
+		}
+		delay := time.Duration(page.PollAfterMS) * time.Millisecond
+		if delay <= 0 || page.Provider == "disabled" {
+			delay = logPollInterval
+		}
+		if err := a.sleep(ctx, delay); err != nil {
+			return err
+		}
+	}
+	for {
+		page, err := fetch(false)
+		if err != nil {
+			return err
+		}
+		if !page.HasMore {
+			break
+		}
+	}
+
+	if human {
+		output.Section(a.Out, "Result")
+		output.Field(a.Out, "Status", output.Status(run.Status))
+		if run.ExitCode != nil {
+			output.Field(a.Out, "Exit code", strconv.Itoa(*run.ExitCode))
+		}
+		if run.ErrorMessage != nil && *run.ErrorMessage != "" {
+			output.Field(a.Out, "Error", *run.ErrorMessage)
+		}
+	} else if err := a.writeData(runOutput{Run: run, Logs: collected}, "Run"); err != nil {
+		return err
+	}
+	if run.Status == "succeeded" {
+		return nil
+	}
+	code := 1
+	if run.ExitCode != nil && *run.ExitCode > 0 {
+		code = *run.ExitCode
+	}
+	err := exitCodeError{code: code, err: fmt.Errorf("run %s: exit code %d", output.Status(run.Status), code)}
+	if a.isMachineOutput() {
+		return handledError{err: err}
+	}
+	return err
+}
+
+func isTerminalRunStatus(status string) bool {
+	return status == "succeeded" || status == "failed" || status == "timed_out"
+}
+
+// shellJoin turns run arguments into the shell command the agent executes.
+// A single argument is passed through so callers can use pipes and &&.
+func shellJoin(args []string) string {
+	if len(args) == 1 {
+		return args[0]
+	}
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		if arg != "" && strings.IndexFunc(arg, func(r rune) bool {
+			return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./:=@%+,", r))
+		}) < 0 {
+			quoted[i] = arg
+			continue
+		}
+		quoted[i] = "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+	}
+	return strings.Join(quoted, " ")
+}
+
 func (a *App) projectsCommand() *cobra.Command {
 	return &cobra.Command{
 		Use:   "projects",
@@ -984,6 +1216,18 @@ func (a *App) getRolloutResource(cmd *cobra.Command, t serviceTargetFlags, id st
 	}
 	return nil
 }
+func (a *App) cronsCommand() *cobra.Command {
+	var runs int
+	c := a.resourceCommand("crons", "List cron jobs and their recent runs", "/crons", func(*cobra.Command) url.Values { return url.Values{"limit": {strconv.Itoa(runs)}} }, printCrons)
+	c.Flags().IntVar(&runs, "runs", 10, "Recent runs per cron (1-100)")
+	c.PreRunE = func(*cobra.Command, []string) error {
+		if runs < 1 || runs > 100 {
+			return errors.New("runs must be between 1 and 100")
+		}
+		return nil
+	}
+	return c
+}
 func (a *App) metricsCommand() *cobra.Command {
 	var r string
 	c := a.resourceCommand("metrics", "Show service metrics", "/metrics", func(*cobra.Command) url.Values { return url.Values{"range": {r}} }, printMetrics)
@@ -1221,12 +1465,30 @@ func sourcePatch(source manifest.Source) map[string]any {
 	if source.Type == "image" {
 		return map[string]any{"type": "image", "image": source.Image}
 	}
-	return map[string]any{
-		"type":       "github",
+	patch := map[string]any{
+		"type":       source.Type,
 		"repository": source.Repository,
 		"branch":     source.Branch,
 		"rootDir":    source.RootDir,
+		"dockerfile": optionalString(source.Dockerfile),
+		"target":     optionalString(source.Target),
+		"buildArgs":  source.BuildArgs,
+	}
+	if source.BuildArgs == nil {
+		patch["buildArgs"] = map[string]string{}
+	}
+	if source.Type == "git" {
+		patch["submodules"] = source.Submodules
+		patch["lfs"] = source.LFS
+	}
+	return patch
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
 	}
+	return &value
 }
 
 func pageQuery(cursor string) url.Values {
@@ -1307,6 +1569,12 @@ func sourcesEqual(expected, actual manifest.Source) bool {
 	if expected.Type == "image" {
 		return expected.Image == actual.Image
 	}
+	if expected.Type == "git" && (expected.Submodules != actual.Submodules || expected.LFS != actual.LFS) {
+		return false
+	}
+	if expected.Dockerfile != actual.Dockerfile || expected.Target != actual.Target || !maps.Equal(expected.BuildArgs, actual.BuildArgs) {
+		return false
+	}
 	if !strings.EqualFold(expected.Repository, actual.Repository) || expected.Branch != actual.Branch {
 		return false
 	}
@@ -1631,6 +1899,34 @@ func printMapSummary(w io.Writer, m map[string]any) {
 	}
 }
 
+func buildCacheUsage(build map[string]any) string {
+	hits, hitsOK := build["cacheHits"].(float64)
+	steps, stepsOK := build["cacheSteps"].(float64)
+	if !hitsOK || !stepsOK || steps <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d/%d steps cached (%d%%)", int(hits), int(steps), int(hits*100/steps))
+}
+
+func printBuildAttestations(w io.Writer, build map[string]any) {
+	attestations, _ := build["attestations"].([]any)
+	for _, value := range attestations {
+		attestation, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		platform, _ := attestation["platform"].(string)
+		manifest, _ := attestation["manifestDigest"].(string)
+		output.Field(w, "Attestations", fmt.Sprintf("%s %s", platform, manifest))
+		if sbom, ok := attestation["sbomDigest"].(string); ok && sbom != "" {
+			output.Field(w, "SBOM", sbom)
+		}
+		if provenance, ok := attestation["provenanceDigest"].(string); ok && provenance != "" {
+			output.Field(w, "Provenance", provenance)
+		}
+	}
+}
+
 func printBuilds(w io.Writer, result map[string]any) {
 	if supported, ok := result["supported"].(bool); ok && !supported {
 		output.Section(w, "Builds")
@@ -1678,6 +1974,16 @@ func printBuilds(w io.Writer, result map[string]any) {
 				}
 				output.Field(w, field.label, value)
 			}
+			if cache := buildCacheUsage(build); cache != "" {
+				output.Field(w, "Cache", cache)
+			}
+			printBuildAttestations(w, build)
+			if vulnerabilities := buildVulnerabilities(build); vulnerabilities != "" {
+				output.Field(w, "Vulnerabilities", vulnerabilities)
+			}
+			if reason, ok := build["rolloutBlockedReason"].(string); ok && reason != "" {
+				output.Field(w, "Rollout", reason)
+			}
 		}
 	}
 	if cursor, ok := result["nextCursor"].(string); ok && cursor != "" {
@@ -1685,6 +1991,74 @@ func printBuilds(w io.Writer, result map[string]any) {
 	}
 }
 
+func printCrons(w io.Writer, result map[string]any) {
+	crons, _ := result["crons"].([]any)
+	output.Section(w, fmt.Sprintf("Crons (%d)", len(crons)))
+	if len(crons) == 0 {
+		output.Field(w, "Items", "none")
+	}
+	for i, value := range crons {
+		cron, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		path, _ := cron["path"].(string)
+		schedule, _ := cron["schedule"].(string)
+		if timeZone, ok := cron["timeZone"].(string); ok && timeZone != "" {
+			schedule += " (" + timeZone + ")"
+		}
+		output.Field(w, "Path", path)
+		output.Field(w, "Schedule", schedule)
+		if policy, ok := cron["concurrencyPolicy"].(string); ok && policy != "" {
+			output.Field(w, "Overlap", policy)
+		}
+		if timeout, ok := metricNumber(cron["timeoutSeconds"]); ok {
+			output.Field(w, "Timeout", formatMetric(timeout, "s"))
+		}
+		if retries, ok := metricNumber(cron["retries"]); ok && retries > 0 {
+			backoff, _ := metricNumber(cron["retryBackoffSeconds"])
+			output.Field(w, "Retries", fmt.Sprintf("%s, backoff %s", formatMetric(retries, ""), formatMetric(backoff, "s")))
+		}
+		if next, ok := cron["nextScheduledFor"].(string); ok && next != "" {
+			output.Field(w, "Next run", output.Timestamp(next))
+		}
+
+		runs, _ := cron["runs"].([]any)
+		output.Field(w, "Runs", len(runs))
+		for _, value := range runs {
+			run, ok := value.(map[string]any)
+			if !ok {
+				continue
+			}
+			startedAt, _ := run["startedAt"].(string)
+			status, _ := run["status"].(string)
+			line := fmt.Sprintf("    * %s  %s", output.Timestamp(startedAt), output.Status(status))
+			if code, ok := metricNumber(run["statusCode"]); ok {
+				line += fmt.Sprintf("  HTTP %s", formatMetric(code, ""))
+			}
+			if duration, ok := metricNumber(run["durationMs"]); ok {
+				line += "  " + formatMetric(duration, "ms")
+			}
+			if attempt, ok := metricNumber(run["attempt"]); ok && attempt > 1 {
+				line += fmt.Sprintf("  attempt %s", formatMetric(attempt, ""))
+			}
+			if source, _ := run["source"].(string); source == "manual" {
+				line += "  manual"
+			}
+			fmt.Fprintln(w, line)
+			if message, ok := run["error"].(string); ok && message != "" {
+				fmt.Fprintf(w, "      error: %s\n", message)
+			}
+			if snippet, ok := run["responseSnippet"].(string); ok && snippet != "" {
+				fmt.Fprintf(w, "      response: %s\n", snippet)
+			}
+		}
+	}
+}
+
 func printConfiguration(w io.Writer, result map[string]any) {
 	output.Section(w, "Configuration")
 	current, ok := result["current"].(map[string]any)
@@ -1700,6 +2074,7 @@ func printConfiguration(w io.Writer, result map[string]any) {
 	printOptionalField(w, "Stateful", current["stateful"])
 	printOptionalField(w, "Replicas", current["replicas"])
 	printOptionalField(w, "Start", current["startCommand"])
+	printOptionalField(w, "Release", current["releaseCommand"])
 	if resources, ok := current["resources"].(map[string]any); ok {
 		if cpu, ok := metricNumber(resources["cpuCores"]); ok {
 			output.Field(w, "CPU limit", formatMetric(cpu, " cores"))
@@ -1758,7 +2133,7 @@ func formatSource(source map[string]any) string {
 		if image, ok := source["image"].(string); ok {
 			return image
 		}
-	case "github":
+	case "github", "git":
 		repository, _ := source["repository"].(string)
 		branch, _ := source["branch"].(string)
 		value := repository
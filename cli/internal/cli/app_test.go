@@ -1,15 +1,19 @@
 package cli
 
 import (
+	"archive/tar"
 	"bytes"
+	"compress/gzip"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"reflect"
+	"sort"
 	"strings"
 	"testing"
 	"time"
@@ -313,6 +317,7 @@ func TestApplyExactNestedPatchForSources(t *testing.T) {
 		{"image", "{type: image, image: nginx:1.27}", "image"},
 		{"github", "{type: github, repository: https://github.com/acme/repo, branch: main, rootDir: cmd/api}", "github"},
 		{"github_clear_root", "{type: github, repository: https://github.com/acme/repo, branch: main}", "github"},
+		{"git_build_options", "{type: git, repository: https://git.example.com/acme/repo.git, branch: main, lfs: true, target: runtime, buildArgs: {NODE_ENV: production}}", "git"},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			d := t.TempDir()
@@ -335,9 +340,12 @@ func TestApplyExactNestedPatchForSources(t *testing.T) {
 			}
 			source := body["source"].(map[string]any)
 			placement := body["placement"].(map[string]any)
-			if source["type"] != tc.sourceType || placement["mode"] != "automatic" || placement["replicas"] != float64(2) || len(body) != 9 {
+			if source["type"] != tc.sourceType || placement["mode"] != "automatic" || placement["replicas"] != float64(2) || len(body) != 10 {
 				t.Fatalf("body=%#v", body)
 			}
+			if releaseCommand, present := body["releaseCommand"]; !present || releaseCommand != nil {
+				t.Fatalf("omitted releaseCommand must be sent as null to clear it: %#v", body)
+			}
 			if crons, ok := body["crons"].([]any); !ok || len(crons) != 0 {
 				t.Fatalf("omitted crons must be sent as an empty replacement: %#v", body["crons"])
 			}
@@ -347,6 +355,12 @@ func TestApplyExactNestedPatchForSources(t *testing.T) {
 					t.Fatalf("GitHub rootDir must be sent as explicit null: %#v", source)
 				}
 			}
+			if tc.name == "git_build_options" {
+				args, _ := source["buildArgs"].(map[string]any)
+				if source["lfs"] != true || source["target"] != "runtime" || source["dockerfile"] != nil || args["NODE_ENV"] != "production" {
+					t.Fatalf("git build options were not sent: %#v", source)
+				}
+			}
 		})
 	}
 }
@@ -925,6 +939,54 @@ func TestDeploySourceNeutralAndMismatch(t *testing.T) {
 	}
 }
 
+func TestDeployLocalUploadsWorkingDirectory(t *testing.T) {
+	d := t.TempDir()
+	writeManifest(t, d, imageManifest)
+	for name, body := range map[string]string{"Dockerfile": "FROM scratch\n", ".dockerignore": "secrets\n", "secrets/key": "x"} {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(d, name)), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(d, name), []byte(body), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var uploaded []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || !strings.HasSuffix(r.URL.Path, "/deploy/local") || r.Header.Get("content-type") != "application/gzip" {
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		gz, err := gzip.NewReader(r.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		reader := tar.NewReader(gz)
+		for {
+			header, err := reader.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			uploaded = append(uploaded, header.Name)
+		}
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte(`{"operation":"build","status":"build_queued","rolloutId":null,"buildId":null}`))
+	}))
+	defer s.Close()
+	writeConfig(t, s.URL)
+	app, out := testApp(t, d, s.Client())
+
+	if err := execute(app, "deploy", "--local"); err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(uploaded)
+	if want := []string{".dockerignore", "Dockerfile", "techulus.yml"}; !reflect.DeepEqual(uploaded, want) {
+		t.Fatalf("uploaded = %v, want %v", uploaded, want)
+	}
+	assertHumanOutput(t, out.String(), "Deploy", "build", "3 files", "build queued")
+}
+
 func TestStatusAndResourceRoutesAndOutput(t *testing.T) {
 	commands := []struct {
 		args        []string
@@ -936,6 +998,7 @@ func TestStatusAndResourceRoutesAndOutput(t *testing.T) {
 		{[]string{"rollout", "r1"}, "/rollouts/r1", ""},
 		{[]string{"rollout", "logs", "r1", "-q", "oops", "--limit", "9"}, "/rollouts/r1/logs", "limit=9&q=oops"},
 		{[]string{"builds", "--limit", "3"}, "/builds", "limit=3"},
+		{[]string{"crons", "--runs", "5"}, "/crons", "limit=5"},
 		{[]string{"metrics", "--range", "24h"}, "/metrics", "range=24h"},
 		{[]string{"revisions", "--cursor", "rev"}, "/revisions", "cursor=rev"},
 	}
@@ -992,6 +1055,21 @@ func TestBuildsHumanOutputIsFormatted(t *testing.T) {
 			response: `{"supported":true,"builds":[{"id":"0400075c-69aa-46c2-bccc-fc172b8c6b28","status":"in_progress","branch":"main","commitSha":"abcdef1234567890abcdef1234567890abcdef12","createdAt":"2026-01-01T00:00:00.820Z"}],"nextCursor":"next-page"}`,
 			want:     []string{"Builds (1)", "ID", "0400075c-69aa-46c2-bccc-fc172b8c6b28", "Status", "in progress", "Branch", "main", "Commit", "abcdef1234567890abcdef1234567890abcdef12", "Created", "2026-01-01T00:00:00.82Z", "next-page"},
 		},
+		{
+			name:     "cache usage",
+			response: `{"supported":true,"builds":[{"id":"build-1","status":"completed","cacheHits":9,"cacheSteps":12}],"nextCursor":null}`,
+			want:     []string{"Builds (1)", "Cache", "9/12 steps cached (75%)"},
+		},
+		{
+			name:     "attestations",
+			response: `{"supported":true,"builds":[{"id":"build-1","status":"completed","attestations":[{"platform":"linux/amd64","imageDigest":"sha256:image","manifestDigest":"sha256:attestation","sbomDigest":"sha256:sbom","provenanceDigest":"sha256:provenance"}]}],"nextCursor":null}`,
+			want:     []string{"Attestations", "linux/amd64 sha256:attestation", "SBOM", "sha256:sbom", "Provenance", "sha256:provenance"},
+		},
+		{
+			name:     "vulnerabilities",
+			response: `{"supported":true,"builds":[{"id":"build-1","status":"completed","vulnerabilities":{"critical":2,"high":4,"medium":0,"low":1,"unknown":0},"rolloutBlockedReason":"Rollout blocked: the linux/amd64 image has 2 critical vulnerabilities (threshold 0)"}],"nextCursor":null}`,
+			want:     []string{"Vulnerabilities", "2 critical, 4 high, 0 medium, 1 low, 0 unknown", "Rollout", "Rollout blocked: the linux/amd64 image has 2 critical vulnerabilities (threshold 0)"},
+		},
 	} {
 		t.Run(tc.name, func(t *testing.T) {
 			s := responseServer(t, tc.response)
@@ -1007,6 +1085,87 @@ func TestBuildsHumanOutputIsFormatted(t *testing.T) {
 	}
 }
 
+func TestCronsHumanOutputIsFormatted(t *testing.T) {
+	s := responseServer(t, `{"crons":[{"id":"c1","path":"/jobs/nightly","schedule":"0 5 * * *","timeZone":"Europe/Berlin","timeoutSeconds":60,"retries":3,"retryBackoffSeconds":30,"concurrencyPolicy":"forbid","nextScheduledFor":"2026-08-07T03:00:00.000Z","lastStatus":"failed","runs":[{"id":"r2","source":"scheduled","attempt":2,"status":"failed","statusCode":500,"durationMs":812,"responseSnippet":"database unavailable","error":"HTTP status 500","startedAt":"2026-08-06T03:00:40.000Z"},{"id":"r1","source":"manual","attempt":1,"status":"succeeded","statusCode":204,"durationMs":95,"responseSnippet":null,"error":null,"startedAt":"2026-08-05T12:00:00.000Z"}]}]}`)
+	writeConfig(t, s.URL)
+
+	app, out := testApp(t, t.TempDir(), s.Client())
+	if err := execute(app, "crons", "--service", "s"); err != nil {
+		t.Fatal(err)
+	}
+	assertHumanOutput(t, out.String(), "Crons (1)", "/jobs/nightly", "0 5 * * * (Europe/Berlin)", "forbid", "60s", "3, backoff 30s", "HTTP 500  812ms  attempt 2", "error: HTTP status 500", "response: database unavailable", "HTTP 204  95ms  manual")
+}
+
+func TestCronsRejectsInvalidRunLimit(t *testing.T) {
+	app, _ := testApp(t, t.TempDir(), nil)
+	if err := execute(app, "crons", "--service", "s", "--runs", "0"); err == nil || !strings.Contains(err.Error(), "runs must be between 1 and 100") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestRunStreamsLogsAndReturnsExitCode(t *testing.T) {
+	var body map[string]any
+	statusCalls, logCalls := 0, 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method + " " + r.URL.Path {
+		case "POST /api/v1/services/s/runs":
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Error(err)
+			}
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"run":{"id":"r1","deploymentId":"d1","serverId":"srv","serverName":"worker-1","command":"bin/migrate --step 'a b'","timeoutSeconds":120,"status":"pending"}}`))
+		case "GET /api/v1/services/s/runs/r1/logs":
+			logCalls++
+			if logCalls == 1 {
+				w.Write([]byte(`{"provider":"enabled","logs":[{"deploymentId":"r1","stream":"stdout","message":"migrating","timestamp":"2026-01-01T00:00:00Z"}],"nextCursor":"c1","hasMore":false,"pollAfterMs":250}`))
+				return
+			}
+			if r.URL.Query().Get("cursor") != "c1" {
+				t.Errorf("cursor = %q", r.URL.Query().Get("cursor"))
+			}
+			w.Write([]byte(`{"provider":"enabled","logs":[],"nextCursor":"c1","hasMore":false,"pollAfterMs":1000}`))
+		case "GET /api/v1/services/s/runs/r1":
+			statusCalls++
+			if statusCalls == 1 {
+				w.Write([]byte(`{"run":{"id":"r1","serverName":"worker-1","status":"running"}}`))
+				return
+			}
+			w.Write([]byte(`{"run":{"id":"r1","serverName":"worker-1","status":"failed","exitCode":3,"errorMessage":"run exited with code 3"}}`))
+		default:
+			t.Errorf("unexpected %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer s.Close()
+	writeConfig(t, s.URL)
+
+	app, out := testApp(t, t.TempDir(), s.Client())
+	app.Sleep = func(time.Duration) {}
+	err := execute(app, "run", "--service", "s", "--timeout", "2m", "--", "bin/migrate", "--step", "a b")
+	if ExitCode(err) != 3 {
+		t.Fatalf("exit code = %d, err = %v", ExitCode(err), err)
+	}
+	if body["command"] != "bin/migrate --step 'a b'" || body["timeoutSeconds"] != float64(120) {
+		t.Fatalf("body = %v", body)
+	}
+	assertHumanOutput(t, out.String(), "Run", "worker-1", "migrating", "Result", "failed", "Exit code", "3")
+}
+
+func TestRunRejectsInvalidTimeout(t *testing.T) {
+	app, _ := testApp(t, t.TempDir(), nil)
+	if err := execute(app, "run", "--service", "s", "--timeout", "2h", "--", "true"); err == nil || !strings.Contains(err.Error(), "timeout must be between 1s and 1h") {
+		t.Fatalf("err = %v", err)
+	}
+}
+
+func TestShellJoinQuotesOnlyWhenNeeded(t *testing.T) {
+	if got := shellJoin([]string{"npm run migrate && echo ok"}); got != "npm run migrate && echo ok" {
+		t.Fatalf("single argument = %q", got)
+	}
+	if got := shellJoin([]string{"echo", "it's", "a=b", ""}); got != `echo 'it'\''s' a=b ''` {
+		t.Fatalf("arguments = %q", got)
+	}
+}
+
 func TestConfigurationHumanOutputIsFormatted(t *testing.T) {
 	s := responseServer(t, `{"current":{"source":{"type":"github","repository":"https://github.com/acme/app","branch":"main","rootDir":"cmd/api"},"hostname":"api.example.com","stateful":true,"replicas":2,"placements":[{"serverId":"server-1","serverName":"ubuntu-2","count":2},{"serverId":"a868807a-42ee-4a40-99fe-cd8303036b02","count":1}],"healthCheck":{"cmd":"curl localhost:3000","interval":10,"timeout":5,"retries":3,"startPeriod":30},"startCommand":"npm start","resources":{"cpuCores":2,"memoryMb":512},"ports":[{"containerPort":3000,"public":true,"domain":"api.example.com","protocol":"http","externalPort":null}],"volumes":[{"name":"data","containerPath":"/data"}],"serverless":{"enabled":false,"sleepAfterSeconds":300,"wakeTimeoutSeconds":30},"schedules":{"deployment":null,"backup":{"enabled":false,"schedule":null}}},"active":null,"activeRevisionId":"0400075c-69aa-46c2-bccc-fc172b8c6b28","activeDeploymentId":"2c917d90-4bc1-4274-b3bf-34fed009fc12","hasPendingChanges":true,"changes":[{"field":"replicas","from":"active revision","to":"current configuration"}],"management":{"patchable":true,"blockers":[]}}`)
 	writeConfig(t, s.URL)
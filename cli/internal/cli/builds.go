@@ -0,0 +1,202 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"techulus/cloud-cli/internal/api"
+	"techulus/cloud-cli/internal/output"
+)
+
+func (a *App) buildsCommand() *cobra.Command {
+	c := a.paginatedCommand("builds", "List build history", "/builds", printBuilds)
+	c.AddCommand(a.buildRebuildCommand(), a.buildCancelCommand(), a.buildLogsCommand())
+	return c
+}
+
+func (a *App) buildRebuildCommand() *cobra.Command {
+	var target serviceTargetFlags
+	c := &cobra.Command{
+		Use:   "rebuild <buildId>",
+		Short: "Queue a new build of the same commit and build options",
+		Annotations: map[string]string{
+			"agent_notes": "Only GitHub and git builds can be rebuilt. Builds from tc deploy --local must be uploaded again.",
+		},
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var result deployResponse
+			if err := a.buildRequest(cmd.Context(), target, args[0], "/rebuild", &result); err != nil {
+				return err
+			}
+			if a.isMachineOutput() {
+				return a.writeData(result, "Rebuild")
+			}
+			output.Section(a.Out, "Rebuild")
+			output.Field(a.Out, "Build", args[0])
+			output.Field(a.Out, "Status", output.Status(result.Status))
+			output.Field(a.Out, "Next", "build queued; a rollout starts after it succeeds")
+			output.Next(a.Out, "tc builds")
+			return nil
+		},
+	}
+	addServiceTargetFlags(c, &target)
+	return c
+}
+
+func (a *App) buildCancelCommand() *cobra.Command {
+	var target serviceTargetFlags
+	c := &cobra.Command{
+		Use:   "cancel <buildId>",
+		Short: "Cancel a pending or running build",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var result map[string]any
+			if err := a.buildRequest(cmd.Context(), target, args[0], "/cancel", &result); err != nil {
+				return err
+			}
+			if a.isMachineOutput() {
+				return a.writeData(result, "Build")
+			}
+			build, _ := result["build"].(map[string]any)
+			status, _ := build["status"].(string)
+			output.Section(a.Out, "Build")
+			output.Field(a.Out, "ID", args[0])
+			output.Field(a.Out, "Status", output.Status(status))
+			return nil
+		},
+	}
+	addServiceTargetFlags(c, &target)
+	return c
+}
+
+func (a *App) buildRequest(ctx context.Context, target serviceTargetFlags, id, suffix string, out any) error {
+	cfg, err := a.requireConfig()
+	if err != nil {
+		return err
+	}
+	m, err := a.resolveServiceTarget(target)
+	if err != nil {
+		return err
+	}
+	base, err := serviceBase(m)
+	if err != nil {
+		return err
+	}
+	return a.client(cfg).RequestJSON(ctx, http.MethodPost, base+"/builds/"+url.PathEscape(id)+suffix, nil, nil, out)
+}
+
+func (a *App) buildLogsCommand() *cobra.Command {
+	var target serviceTargetFlags
+	var follow bool
+	var limit int
+	var q string
+	c := &cobra.Command{
+		Use:   "logs <buildId>",
+		Short: "Show build logs",
+		Annotations: map[string]string{
+			"agent_notes": "With --follow, tc polls until the build finishes. In --agent or --json mode the collected logs are returned once it does. Without --follow, truncated is true when the output stopped at --limit lines.",
+		},
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if limit < 1 || limit > 1000 {
+				return errors.New("limit must be between 1 and 1000")
+			}
+			cfg, err := a.requireConfig()
+			if err != nil {
+				return err
+			}
+			m, err := a.resolveServiceTarget(target)
+			if err != nil {
+				return err
+			}
+			base, err := serviceBase(m)
+			if err != nil {
+				return err
+			}
+			path := base + "/builds/" + url.PathEscape(args[0]) + "/logs"
+			return a.followBuildLogs(cmd.Context(), a.client(cfg), path, limit, q, follow)
+		},
+	}
+	c.Flags().BoolVarP(&follow, "follow", "f", false, "Keep streaming until the build finishes")
+	c.Flags().IntVar(&limit, "limit", 500, "Log lines per request")
+	c.Flags().StringVarP(&q, "query", "q", "", "Search logs")
+	addServiceTargetFlags(c, &target)
+	return c
+}
+
+func (a *App) followBuildLogs(ctx context.Context, client *api.Client, path string, limit int, q string, follow bool) error {
+	human := !a.isMachineOutput()
+	collected := []buildLog{}
+	var page buildLogsResponse
+	cursor := ""
+	lines := 0
+	for {
+		query := url.Values{"limit": {strconv.Itoa(limit)}}
+		if q != "" {
+			query.Set("q", q)
+		}
+		if cursor != "" {
+			query.Set("cursor", cursor)
+			if follow {
+				query.Set("wait", "5")
+			}
+		}
+		page = buildLogsResponse{}
+		if err := client.RequestJSON(ctx, http.MethodGet, path, query, nil, &page); err != nil {
+			return err
+		}
+		lines += len(page.Logs)
+		if human {
+			printBuildLogs(a.Out, page.Logs)
+		} else {
+			collected = append(collected, page.Logs...)
+		}
+		if page.NextCursor != "" {
+			cursor = page.NextCursor
+		}
+		if !follow || page.Done || page.Provider == "disabled" {
+			break
+		}
+		if page.PollAfterMS > 0 {
+			if err := a.sleep(ctx, time.Duration(page.PollAfterMS)*time.Millisecond); err != nil {
+				return err
+			}
+		}
+	}
+
+	// A single page that fills the limit may have cut the output short.
+	truncated := !follow && len(page.Logs) == limit
+	if !human {
+		return a.writeData(buildLogsOutput{Provider: page.Provider, Build: page.Build, Logs: collected, Truncated: truncated}, "Build logs")
+	}
+	if page.Provider == "disabled" {
+		output.Section(a.Out, "Build logs")
+		output.Field(a.Out, "Status", "disabled")
+		return nil
+	}
+	output.Section(a.Out, "Build")
+	output.Field(a.Out, "ID", page.Build.ID)
+	output.Field(a.Out, "Status", output.Status(page.Build.Status))
+	if lines == 0 {
+		output.Field(a.Out, "Lines", "none")
+	}
+	if truncated {
+		output.Field(a.Out, "More", fmt.Sprintf("output stopped at %d lines; use --follow or a higher --limit to read the rest", limit))
+	}
+	return nil
+}
+
+func printBuildLogs(w io.Writer, logs []buildLog) {
+	for _, log := range logs {
+		fmt.Fprintf(w, "%s %s\n", output.Timestamp(log.Timestamp), strings.TrimRight(log.Message, "\n"))
+	}
+}
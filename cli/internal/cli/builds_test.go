@@ -0,0 +1,147 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestBuildsRebuildAndCancel(t *testing.T) {
+	d := t.TempDir()
+	writeManifest(t, d, imageManifest)
+	var paths []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		paths = append(paths, r.URL.Path)
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/services/s/builds/build-1/rebuild"):
+			w.WriteHeader(http.StatusAccepted)
+			w.Write([]byte(`{"operation":"build","status":"build_queued","rolloutId":null,"buildId":null,"rebuildOf":"build-1","serviceRevisionId":"revision-2"}`))
+		case strings.HasSuffix(r.URL.Path, "/services/s/builds/build-2/cancel"):
+			w.Write([]byte(`{"build":{"id":"build-2","status":"cancelled"}}`))
+		default:
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"error":"Cannot cancel build in completed status","code":"BUILD_NOT_CANCELLABLE"}`))
+		}
+	}))
+	defer s.Close()
+	writeConfig(t, s.URL)
+
+	app, out := testApp(t, d, s.Client())
+	if err := execute(app, "builds", "rebuild", "build-1"); err != nil {
+		t.Fatal(err)
+	}
+	assertHumanOutput(t, out.String(), "Rebuild", "build-1", "build queued")
+
+	app, out = testApp(t, d, s.Client())
+	if err := execute(app, "builds", "cancel", "build-2"); err != nil {
+		t.Fatal(err)
+	}
+	assertHumanOutput(t, out.String(), "build-2", "cancelled")
+
+	app, _ = testApp(t, d, s.Client())
+	if err := execute(app, "builds", "cancel", "build-3"); err == nil || !strings.Contains(err.Error(), "Cannot cancel build in completed status") {
+		t.Fatalf("cancel finished build error = %v", err)
+	}
+	if len(paths) != 3 {
+		t.Fatalf("paths = %#v", paths)
+	}
+}
+
+func TestBuildLogsFollowUntilDone(t *testing.T) {
+	d := t.TempDir()
+	writeManifest(t, d, imageManifest)
+	pages := []string{
+		`{"provider":"enabled","build":{"id":"build-1","status":"building"},"logs":[{"message":"#1 load build definition","timestamp":"2026-01-01T00:00:00.000001Z"}],"nextCursor":"2026-01-01T00:00:00.000001Z","done":false,"pollAfterMs":250}`,
+		`{"provider":"enabled","build":{"id":"build-1","status":"pushing"},"logs":[],"nextCursor":"2026-01-01T00:00:00.000001Z","done":false,"pollAfterMs":1000}`,
+		`{"provider":"enabled","build":{"id":"build-1","status":"completed"},"logs":[{"message":"pushed image\n","timestamp":"2026-01-01T00:00:05.000002Z"}],"nextCursor":"2026-01-01T00:00:05.000002Z","done":true,"pollAfterMs":0}`,
+	}
+	var queries []string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/services/s/builds/build-1/logs") {
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		queries = append(queries, r.URL.RawQuery)
+		w.Write([]byte(pages[len(queries)-1]))
+	}))
+	defer s.Close()
+	writeConfig(t, s.URL)
+
+	app, out := testApp(t, d, s.Client())
+	var sleeps []time.Duration
+	app.Sleep = func(d time.Duration) { sleeps = append(sleeps, d) }
+	if err := execute(app, "builds", "logs", "build-1", "--follow"); err != nil {
+		t.Fatal(err)
+	}
+	assertHumanOutput(t, out.String(), "load build definition", "pushed image", "completed")
+	if len(queries) != 3 || strings.Contains(queries[0], "cursor") || !strings.Contains(queries[1], "cursor=2026-01-01T00%3A00%3A00.000001Z") || !strings.Contains(queries[1], "wait=5") {
+		t.Fatalf("queries = %#v", queries)
+	}
+	if len(sleeps) != 2 || sleeps[0] != 250*time.Millisecond || sleeps[1] != time.Second {
+		t.Fatalf("sleeps = %#v", sleeps)
+	}
+}
+
+func TestBuildLogsReportsOutputCutAtLimit(t *testing.T) {
+	d := t.TempDir()
+	writeManifest(t, d, imageManifest)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("limit") != "2" || r.URL.Query().Has("cursor") {
+			t.Fatalf("unexpected query %s", r.URL.RawQuery)
+		}
+		w.Write([]byte(`{"provider":"enabled","build":{"id":"build-1","status":"completed"},"logs":[{"message":"step 1","timestamp":"2026-01-01T00:00:01Z"},{"message":"step 2","timestamp":"2026-01-01T00:00:02Z"}],"nextCursor":"cursor-2","done":false,"pollAfterMs":250}`))
+	}))
+	defer s.Close()
+	writeConfig(t, s.URL)
+
+	app, out := testApp(t, d, s.Client())
+	if err := execute(app, "builds", "logs", "build-1", "--limit", "2"); err != nil {
+		t.Fatal(err)
+	}
+	assertHumanOutput(t, out.String(), "step 2", "output stopped at 2 lines; use --follow or a higher --limit to read the rest")
+
+	app, out = testApp(t, d, s.Client())
+	if err := execute(app, "--agent", "builds", "logs", "build-1", "--limit", "2"); err != nil {
+		t.Fatal(err)
+	}
+	var got buildLogsOutput
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("%v: %s", err, out.String())
+	}
+	if !got.Truncated || len(got.Logs) != 2 {
+		t.Fatalf("output = %#v", got)
+	}
+}
+
+func TestBuildLogsMachineOutputCollectsPages(t *testing.T) {
+	d := t.TempDir()
+	writeManifest(t, d, imageManifest)
+	requests := 0
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.Write([]byte(`{"provider":"enabled","build":{"id":"build-1","status":"building"},"logs":[{"message":"step 1","timestamp":"2026-01-01T00:00:01Z"}],"nextCursor":"2026-01-01T00:00:01Z","done":false,"pollAfterMs":0}`))
+			return
+		}
+		w.Write([]byte(`{"provider":"enabled","build":{"id":"build-1","status":"failed"},"logs":[{"message":"step 2","timestamp":"2026-01-01T00:00:02Z"}],"nextCursor":"2026-01-01T00:00:02Z","done":true,"pollAfterMs":0}`))
+	}))
+	defer s.Close()
+	writeConfig(t, s.URL)
+
+	app, out := testApp(t, d, s.Client())
+	if err := execute(app, "--agent", "builds", "logs", "build-1", "--follow"); err != nil {
+		t.Fatal(err)
+	}
+	var got buildLogsOutput
+	if err := json.Unmarshal(out.Bytes(), &got); err != nil {
+		t.Fatalf("%v: %s", err, out.String())
+	}
+	if got.Build.Status != "failed" || len(got.Logs) != 2 || got.Logs[1].Message != "step 2" {
+		t.Fatalf("output = %#v", got)
+	}
+}
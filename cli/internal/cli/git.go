@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"techulus/cloud-cli/internal/output"
+)
+
+var fullCommitSHA = regexp.MustCompile(`^[0-9a-f]{40}$`)
+
+// lsRemoteCommit asks the remote for a branch head with the user's own git
+// and SSH configuration.
+func lsRemoteCommit(ctx context.Context, repository, branch string) (string, error) {
+	ref := "refs/heads/" + branch
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", repository, ref)
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if message := strings.TrimSpace(stderr.String()); message != "" {
+			return "", fmt.Errorf("git ls-remote: %s", message)
+		}
+		return "", fmt.Errorf("git ls-remote: %w", err)
+	}
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && fields[1] == ref && fullCommitSHA.MatchString(fields[0]) {
+			return fields[0], nil
+		}
+	}
+	return "", fmt.Errorf("branch %s not found", branch)
+}
+
+func (a *App) gitCredentialsCommand() *cobra.Command {
+	var target serviceTargetFlags
+	c := &cobra.Command{
+		Use:   "git-credentials",
+		Short: "Show the credential a git source clones with",
+		Annotations: map[string]string{
+			"agent_notes": "Only applies to services with a git source. Secrets are write-only; this shows the credential type and username.",
+		},
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.gitCredentialsRequest(cmd, target, http.MethodGet, nil)
+		},
+	}
+	c.PersistentFlags().StringVar(&target.Service, "service", "", "Service ID")
+
+	var tokenEnv, username, sshKey, knownHosts string
+	set := &cobra.Command{
+		Use:   "set",
+		Short: "Replace the credential with an HTTPS token or SSH deploy key",
+		Annotations: map[string]string{
+			"agent_notes": "Pass --token-env with the name of an environment variable holding the token, or --ssh-key with a private key file and --known-hosts with the git host's keys. Tokens are never accepted as flag values.",
+		},
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var body map[string]any
+			switch {
+			case tokenEnv != "" && sshKey != "":
+				return errors.New("use either --token-env or --ssh-key")
+			case tokenEnv != "":
+				if knownHosts != "" {
+					return errors.New("--known-hosts only applies to --ssh-key")
+				}
+				token := os.Getenv(tokenEnv)
+				if token == "" {
+					return fmt.Errorf("environment variable %s is empty", tokenEnv)
+				}
+				body = map[string]any{"type": "https_token", "token": token}
+				if username != "" {
+					body["username"] = username
+				}
+			case sshKey != "":
+				if username != "" {
+					return errors.New("--username only applies to --token-env")
+				}
+				if knownHosts == "" {
+					return errors.New("--ssh-key requires --known-hosts so the git host's key can be verified")
+				}
+				key, err := os.ReadFile(sshKey)
+				if err != nil {
+					return fmt.Errorf("read ssh key: %w", err)
+				}
+				hosts, err := os.ReadFile(knownHosts)
+				if err != nil {
+					return fmt.Errorf("read known hosts: %w", err)
+				}
+				body = map[string]any{"type": "ssh_key", "privateKey": string(key), "knownHosts": string(hosts)}
+			default:
+				return errors.New("pass --token-env or --ssh-key")
+			}
+			return a.gitCredentialsRequest(cmd, target, http.MethodPut, body)
+		},
+	}
+	set.Flags().StringVar(&tokenEnv, "token-env", "", "Environment variable holding an HTTPS access token")
+	set.Flags().StringVar(&username, "username", "", "Username sent with the token (default oauth2)")
+	set.Flags().StringVar(&sshKey, "ssh-key", "", "Private deploy key file")
+	set.Flags().StringVar(&knownHosts, "known-hosts", "", "known_hosts file pinning the git host's key")
+
+	remove := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove the credential so the source clones anonymously",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.gitCredentialsRequest(cmd, target, http.MethodDelete, nil)
+		},
+	}
+	c.AddCommand(set, remove)
+	return c
+}
+
+func (a *App) gitCredentialsRequest(cmd *cobra.Command, target serviceTargetFlags, method string, body any) error {
+	cfg, err := a.requireConfig()
+	if err != nil {
+		return err
+	}
+	m, err := a.resolveServiceTarget(target)
+	if err != nil {
+		return err
+	}
+	base, err := serviceBase(m)
+	if err != nil {
+		return err
+	}
+	var out map[string]any
+	if err := a.client(cfg).RequestJSON(cmd.Context(), method, base+"/git-credentials", nil, body, &out); err != nil {
+		return err
+	}
+	if a.isMachineOutput() {
+		return a.writeData(out, "Git credentials")
+	}
+	printGitCredential(a.Out, out)
+	return nil
+}
+
+func printGitCredential(w io.Writer, result map[string]any) {
+	output.Section(w, "Git credentials")
+	if deleted, ok := result["deleted"].(bool); ok {
+		if deleted {
+			output.Field(w, "Credential", "removed")
+		} else {
+			output.Field(w, "Credential", "none configured")
+		}
+		return
+	}
+	credential, _ := result["credential"].(map[string]any)
+	switch credential["type"] {
+	case "https_token":
+		output.Field(w, "Type", "HTTPS token")
+		output.Field(w, "Username", credential["username"])
+	case "ssh_key":
+		output.Field(w, "Type", "SSH deploy key")
+		if pinned, _ := credential["knownHosts"].(bool); pinned {
+			output.Field(w, "Host key", "pinned")
+		} else {
+			output.Field(w, "Host key", "not pinned")
+		}
+	default:
+		output.Field(w, "Type", "none (anonymous clone)")
+		return
+	}
+	if updated, ok := credential["updatedAt"].(string); ok {
+		output.Field(w, "Updated", output.Timestamp(updated))
+	}
+}
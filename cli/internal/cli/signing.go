@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"techulus/cloud-cli/internal/output"
+)
+
+func (a *App) signingPolicyCommand() *cobra.Command {
+	var project string
+	c := &cobra.Command{
+		Use:   "signing-policy",
+		Short: "Show the image signing policy of a project",
+		Annotations: map[string]string{
+			"agent_notes": "Applies to every service in the project. Agents refuse to start containers whose image is not signed by one of the policy's cosign keys or keyless identities. Changing the policy requires an admin API key.",
+		},
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.signingPolicyRequest(cmd, project, http.MethodGet, nil)
+		},
+	}
+	c.PersistentFlags().StringVar(&project, "project", "", "Project ID")
+
+	var keys, issuers, subjects []string
+	set := &cobra.Command{
+		Use:   "set",
+		Short: "Replace the policy with cosign public keys and keyless identities",
+		Annotations: map[string]string{
+			"agent_notes": "Pass --key with a cosign public key file, or --issuer and --subject pairs for keyless signatures. An image passes when any key or identity verifies it.",
+		},
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(issuers) != len(subjects) {
+				return errors.New("pass --issuer and --subject in pairs")
+			}
+			if len(keys) == 0 && len(issuers) == 0 {
+				return errors.New("pass --key or --issuer and --subject")
+			}
+			publicKeys := make([]string, 0, len(keys))
+			for _, path := range keys {
+				key, err := os.ReadFile(path)
+				if err != nil {
+					return fmt.Errorf("read public key: %w", err)
+				}
+				publicKeys = append(publicKeys, string(key))
+			}
+			identities := make([]map[string]string, 0, len(issuers))
+			for i := range issuers {
+				identities = append(identities, map[string]string{"issuer": issuers[i], "subject": subjects[i]})
+			}
+			return a.signingPolicyRequest(cmd, project, http.MethodPut, map[string]any{"publicKeys": publicKeys, "identities": identities})
+		},
+	}
+	set.Flags().StringArrayVar(&keys, "key", nil, "cosign public key file (repeatable)")
+	set.Flags().StringArrayVar(&issuers, "issuer", nil, "OIDC issuer of a keyless signing identity (repeatable)")
+	set.Flags().StringArrayVar(&subjects, "subject", nil, "Certificate identity of a keyless signer, paired with --issuer (repeatable)")
+
+	remove := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove the policy so unsigned images deploy",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.signingPolicyRequest(cmd, project, http.MethodDelete, nil)
+		},
+	}
+	c.AddCommand(set, remove)
+	return c
+}
+
+func (a *App) signingPolicyRequest(cmd *cobra.Command, project, method string, body any) error {
+	cfg, err := a.requireConfig()
+	if err != nil {
+		return err
+	}
+	if project == "" {
+		return errors.New("missing --project")
+	}
+	var out map[string]any
+	if err := a.client(cfg).RequestJSON(cmd.Context(), method, "/api/v1/projects/"+url.PathEscape(project)+"/signing-policy", nil, body, &out); err != nil {
+		return err
+	}
+	if a.isMachineOutput() {
+		return a.writeData(out, "Signing policy")
+	}
+	printSigningPolicy(a.Out, out)
+	return nil
+}
+
+func printSigningPolicy(w io.Writer, result map[string]any) {
+	output.Section(w, "Signing policy")
+	if deleted, ok := result["deleted"].(bool); ok {
+		if deleted {
+			output.Field(w, "Policy", "removed")
+		} else {
+			output.Field(w, "Policy", "none configured")
+		}
+		return
+	}
+	policy, ok := result["policy"].(map[string]any)
+	if !ok {
+		output.Field(w, "Policy", "none (unsigned images deploy)")
+		return
+	}
+	keys, _ := policy["publicKeys"].([]any)
+	output.Field(w, "Public keys", len(keys))
+	identities, _ := policy["identities"].([]any)
+	for _, value := range identities {
+		identity, ok := value.(map[string]any)
+		if !ok {
+			continue
+		}
+		output.Field(w, "Identity", fmt.Sprintf("%v (%v)", identity["subject"], identity["issuer"]))
+	}
+}
@@ -0,0 +1,79 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSigningPolicy(t *testing.T) {
+	d := t.TempDir()
+	keyPath := filepath.Join(d, "cosign.pub")
+	if err := os.WriteFile(keyPath, []byte("-----BEGIN PUBLIC KEY-----\nkey\n-----END PUBLIC KEY-----\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	var requests []map[string]any
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/projects/p1/signing-policy" {
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		switch r.Method {
+		case http.MethodGet:
+			w.Write([]byte(`{"policy":null}`))
+		case http.MethodDelete:
+			w.Write([]byte(`{"deleted":true}`))
+		default:
+			var body map[string]any
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			requests = append(requests, body)
+			encoded, _ := json.Marshal(map[string]any{"policy": body})
+			w.Write(encoded)
+		}
+	}))
+	defer s.Close()
+	writeConfig(t, s.URL)
+
+	app, out := testApp(t, d, s.Client())
+	if err := execute(app, "signing-policy", "--project", "p1"); err != nil {
+		t.Fatal(err)
+	}
+	assertHumanOutput(t, out.String(), "Signing policy", "none (unsigned images deploy)")
+
+	app, out = testApp(t, d, s.Client())
+	if err := execute(app, "signing-policy", "set", "--project", "p1", "--key", keyPath, "--issuer", "https://token.actions.githubusercontent.com", "--subject", "https://github.com/acme/api/.github/workflows/release.yml@refs/heads/main"); err != nil {
+		t.Fatal(err)
+	}
+	assertHumanOutput(t, out.String(), "Public keys", "1", "Identity", "https://github.com/acme/api/.github/workflows/release.yml@refs/heads/main (https://token.actions.githubusercontent.com)")
+
+	app, out = testApp(t, d, s.Client())
+	if err := execute(app, "signing-policy", "clear", "--project", "p1"); err != nil {
+		t.Fatal(err)
+	}
+	assertHumanOutput(t, out.String(), "Policy", "removed")
+
+	if len(requests) != 1 {
+		t.Fatalf("requests = %#v", requests)
+	}
+	keys := requests[0]["publicKeys"].([]any)
+	identities := requests[0]["identities"].([]any)
+	if len(keys) != 1 || !strings.Contains(keys[0].(string), "BEGIN PUBLIC KEY") || len(identities) != 1 {
+		t.Fatalf("request = %#v", requests[0])
+	}
+
+	app, _ = testApp(t, d, s.Client())
+	if err := execute(app, "signing-policy", "set", "--project", "p1", "--issuer", "https://issuer.example"); err == nil || !strings.Contains(err.Error(), "in pairs") {
+		t.Fatalf("unpaired identity error = %v", err)
+	}
+	if err := execute(app, "signing-policy", "set", "--key", keyPath); err == nil || !strings.Contains(err.Error(), "missing --project") {
+		t.Fatalf("missing project error = %v", err)
+	}
+	if len(requests) != 1 {
+		t.Fatalf("invalid input reached the API: %#v", requests)
+	}
+}
@@ -41,17 +41,18 @@ type environmentItem struct {
 	Name string `json:"name"`
 }
 type serviceItem struct {
-	ID           string                `json:"id"`
-	Name         string                `json:"name"`
-	Hostname     *string               `json:"hostname"`
-	Source       manifest.Source       `json:"source"`
-	Ports        []manifest.Port       `json:"ports"`
-	Replicas     int                   `json:"replicas"`
-	Placement    *manifest.Placement   `json:"placement"`
-	HealthCheck  *manifest.HealthCheck `json:"healthCheck"`
-	StartCommand *string               `json:"startCommand"`
-	Resources    *manifest.Resources   `json:"resources"`
-	Crons        []manifest.Cron       `json:"crons"`
+	ID             string                `json:"id"`
+	Name           string                `json:"name"`
+	Hostname       *string               `json:"hostname"`
+	Source         manifest.Source       `json:"source"`
+	Ports          []manifest.Port       `json:"ports"`
+	Replicas       int                   `json:"replicas"`
+	Placement      *manifest.Placement   `json:"placement"`
+	HealthCheck    *manifest.HealthCheck `json:"healthCheck"`
+	StartCommand   *string               `json:"startCommand"`
+	ReleaseCommand *string               `json:"releaseCommand"`
+	Resources      *manifest.Resources   `json:"resources"`
+	Crons          []manifest.Cron       `json:"crons"`
 }
 type targetProject struct {
 	ID   string `json:"id"`
@@ -107,6 +108,8 @@ type deployResponse struct {
 	Status    string  `json:"status"`
 	RolloutID *string `json:"rolloutId"`
 	BuildID   *string `json:"buildId"`
+
+	ContextChecksum string `json:"contextChecksum,omitempty"`
 }
 type statusResponse struct {
 	Target  targetContext `json:"target"`
@@ -126,6 +129,54 @@ type serviceLog struct {
 	Timestamp    string  `json:"timestamp"`
 }
 
+type serviceRun struct {
+	ID             string  `json:"id"`
+	DeploymentID   string  `json:"deploymentId"`
+	ServerID       string  `json:"serverId"`
+	ServerName     string  `json:"serverName"`
+	Command        string  `json:"command"`
+	TimeoutSeconds int     `json:"timeoutSeconds"`
+	Status         string  `json:"status"`
+	ExitCode       *int    `json:"exitCode"`
+	ErrorMessage   *string `json:"errorMessage"`
+}
+
+type runResponse struct {
+	Run serviceRun `json:"run"`
+}
+
+type runOutput struct {
+	Run  serviceRun   `json:"run"`
+	Logs []serviceLog `json:"logs"`
+}
+
+type buildLog struct {
+	Message   string `json:"message"`
+	Timestamp string `json:"timestamp"`
+}
+
+type buildRef struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+type buildLogsResponse struct {
+	Provider    string     `json:"provider"`
+	Build       buildRef   `json:"build"`
+	Logs        []buildLog `json:"logs"`
+	NextCursor  string     `json:"nextCursor"`
+	Done        bool       `json:"done"`
+	PollAfterMS int        `json:"pollAfterMs"`
+}
+
+type buildLogsOutput struct {
+	Provider string     `json:"provider"`
+	Build    buildRef   `json:"build"`
+	Logs     []buildLog `json:"logs"`
+	// Truncated reports that the lines stopped at --limit without --follow.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
 type logsResponse struct {
 	Target      targetContext `json:"target"`
 	Provider    string        `json:"provider"`
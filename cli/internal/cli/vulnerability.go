@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"techulus/cloud-cli/internal/output"
+)
+
+func (a *App) vulnerabilityPolicyCommand() *cobra.Command {
+	var target serviceTargetFlags
+	c := &cobra.Command{
+		Use:   "vulnerability-policy",
+		Short: "Show the critical vulnerability threshold that gates rollouts",
+		Annotations: map[string]string{
+			"agent_notes": "Only built images are scanned. While a threshold is set, builds that exceed it or were not scanned do not roll out.",
+		},
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.vulnerabilityPolicyRequest(cmd, target, http.MethodGet, nil)
+		},
+	}
+	c.PersistentFlags().StringVar(&target.Service, "service", "", "Service ID")
+
+	maxCritical := -1
+	set := &cobra.Command{
+		Use:   "set",
+		Short: "Block rollouts of builds with more critical vulnerabilities than the threshold",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if maxCritical < 0 {
+				return errors.New("pass --max-critical with a value of 0 or more")
+			}
+			return a.vulnerabilityPolicyRequest(cmd, target, http.MethodPut, map[string]any{"maxCritical": maxCritical})
+		},
+	}
+	set.Flags().IntVar(&maxCritical, "max-critical", -1, "Highest number of critical vulnerabilities a build may have and still roll out")
+
+	remove := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove the threshold so scan results never block a rollout",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.vulnerabilityPolicyRequest(cmd, target, http.MethodPut, map[string]any{"maxCritical": nil})
+		},
+	}
+	c.AddCommand(set, remove)
+	return c
+}
+
+func (a *App) vulnerabilityPolicyRequest(cmd *cobra.Command, target serviceTargetFlags, method string, body any) error {
+	cfg, err := a.requireConfig()
+	if err != nil {
+		return err
+	}
+	m, err := a.resolveServiceTarget(target)
+	if err != nil {
+		return err
+	}
+	base, err := serviceBase(m)
+	if err != nil {
+		return err
+	}
+	var out map[string]any
+	if err := a.client(cfg).RequestJSON(cmd.Context(), method, base+"/vulnerability-policy", nil, body, &out); err != nil {
+		return err
+	}
+	if a.isMachineOutput() {
+		return a.writeData(out, "Vulnerability policy")
+	}
+	printVulnerabilityPolicy(a.Out, out)
+	return nil
+}
+
+func printVulnerabilityPolicy(w io.Writer, result map[string]any) {
+	output.Section(w, "Vulnerability policy")
+	policy, _ := result["policy"].(map[string]any)
+	if maxCritical, ok := policy["maxCritical"].(float64); ok {
+		output.Field(w, "Max critical", int(maxCritical))
+		output.Field(w, "Rollouts", "blocked above the threshold or without a scan")
+		return
+	}
+	output.Field(w, "Max critical", "none")
+	output.Field(w, "Rollouts", "never blocked by scan results")
+}
+
+func buildVulnerabilities(build map[string]any) string {
+	counts, ok := build["vulnerabilities"].(map[string]any)
+	if !ok {
+		return ""
+	}
+	var parts []string
+	for _, severity := range []string{"critical", "high", "medium", "low", "unknown"} {
+		count, _ := counts[severity].(float64)
+		parts = append(parts, fmt.Sprintf("%d %s", int(count), severity))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,65 @@
+package cli
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVulnerabilityPolicy(t *testing.T) {
+	d := t.TempDir()
+	writeManifest(t, d, imageManifest)
+	var requests []map[string]any
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasSuffix(r.URL.Path, "/services/s/vulnerability-policy") {
+			t.Fatalf("unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if r.Method == http.MethodGet {
+			w.Write([]byte(`{"policy":{"maxCritical":null}}`))
+			return
+		}
+		var body map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Fatal(err)
+		}
+		requests = append(requests, body)
+		encoded, _ := json.Marshal(map[string]any{"policy": body})
+		w.Write(encoded)
+	}))
+	defer s.Close()
+	writeConfig(t, s.URL)
+
+	app, out := testApp(t, d, s.Client())
+	if err := execute(app, "vulnerability-policy"); err != nil {
+		t.Fatal(err)
+	}
+	assertHumanOutput(t, out.String(), "Vulnerability policy", "Max critical", "none")
+
+	app, out = testApp(t, d, s.Client())
+	if err := execute(app, "vulnerability-policy", "set", "--max-critical", "0"); err != nil {
+		t.Fatal(err)
+	}
+	assertHumanOutput(t, out.String(), "Max critical", "0", "blocked above the threshold")
+
+	app, _ = testApp(t, d, s.Client())
+	if err := execute(app, "vulnerability-policy", "clear"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(requests) != 2 || requests[0]["maxCritical"] != float64(0) || requests[1]["maxCritical"] != nil {
+		t.Fatalf("requests = %#v", requests)
+	}
+	if _, ok := requests[1]["maxCritical"]; !ok {
+		t.Fatal("clear did not send maxCritical: null")
+	}
+
+	app, _ = testApp(t, d, s.Client())
+	if err := execute(app, "vulnerability-policy", "set"); err == nil || !strings.Contains(err.Error(), "--max-critical") {
+		t.Fatalf("missing threshold error = %v", err)
+	}
+	if len(requests) != 2 {
+		t.Fatalf("invalid input reached the API: %#v", requests)
+	}
+}
@@ -17,8 +17,15 @@ var (
 	windowsAbsolutePath = regexp.MustCompile(`^[A-Za-z]:[\\/]`)
 	hostnamePattern     = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
 	slugChars           = regexp.MustCompile(`[^a-z0-9]+`)
+	gitHostPattern      = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9.-]*[A-Za-z0-9])?$`)
+	gitUserPattern      = regexp.MustCompile(`^[A-Za-z0-9_][A-Za-z0-9._-]*$`)
+	scpLikeGitRemote    = regexp.MustCompile(`^([^@:/\s]+)@([^:/\s]+):(\S+)$`)
+	buildTargetPattern  = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_.-]{0,127}$`)
+	buildArgNamePattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]{0,127}$`)
 )
 
+const maxBuildArgs = 64
+
 type Manifest struct {
 	APIVersion string  `json:"apiVersion" yaml:"apiVersion"`
 	Target     *Target `json:"target,omitempty" yaml:"target,omitempty"`
@@ -28,20 +35,26 @@ type Target struct {
 	ServiceID string `json:"serviceId,omitempty" yaml:"serviceId,omitempty"`
 }
 type Service struct {
-	Name         string       `json:"name" yaml:"name"`
-	Source       Source       `json:"source" yaml:"source"`
-	Hostname     *string      `json:"hostname" yaml:"hostname"`
-	Ports        []Port       `json:"ports" yaml:"ports"`
-	Replicas     int          `json:"replicas" yaml:"replicas"`
-	Placement    *Placement   `json:"placement,omitempty" yaml:"placement,omitempty"`
-	HealthCheck  *HealthCheck `json:"healthCheck" yaml:"healthCheck"`
-	StartCommand *string      `json:"startCommand" yaml:"startCommand"`
-	Resources    *Resources   `json:"resources,omitempty" yaml:"resources,omitempty"`
-	Crons        []Cron       `json:"crons,omitempty" yaml:"crons,omitempty"`
+	Name           string       `json:"name" yaml:"name"`
+	Source         Source       `json:"source" yaml:"source"`
+	Hostname       *string      `json:"hostname" yaml:"hostname"`
+	Ports          []Port       `json:"ports" yaml:"ports"`
+	Replicas       int          `json:"replicas" yaml:"replicas"`
+	Placement      *Placement   `json:"placement,omitempty" yaml:"placement,omitempty"`
+	HealthCheck    *HealthCheck `json:"healthCheck" yaml:"healthCheck"`
+	StartCommand   *string      `json:"startCommand" yaml:"startCommand"`
+	ReleaseCommand *string      `json:"releaseCommand,omitempty" yaml:"releaseCommand,omitempty"`
+	Resources      *Resources   `json:"resources,omitempty" yaml:"resources,omitempty"`
+	Crons          []Cron       `json:"crons,omitempty" yaml:"crons,omitempty"`
 }
 type Cron struct {
-	Path     string `json:"path" yaml:"path"`
-	Schedule string `json:"schedule" yaml:"schedule"`
+	Path                string `json:"path" yaml:"path"`
+	Schedule            string `json:"schedule" yaml:"schedule"`
+	TimeZone            string `json:"timeZone,omitempty" yaml:"timeZone,omitempty"`
+	TimeoutSeconds      int    `json:"timeoutSeconds,omitempty" yaml:"timeoutSeconds,omitempty"`
+	Retries             int    `json:"retries,omitempty" yaml:"retries,omitempty"`
+	RetryBackoffSeconds int    `json:"retryBackoffSeconds,omitempty" yaml:"retryBackoffSeconds,omitempty"`
+	ConcurrencyPolicy   string `json:"concurrencyPolicy,omitempty" yaml:"concurrencyPolicy,omitempty"`
 }
 type Placement struct {
 	Mode    string            `json:"mode" yaml:"mode"`
@@ -57,11 +70,26 @@ type Source struct {
 	Repository string  `json:"repository,omitempty" yaml:"repository,omitempty"`
 	Branch     string  `json:"branch,omitempty" yaml:"branch,omitempty"`
 	RootDir    *string `json:"rootDir,omitempty" yaml:"rootDir,omitempty"`
+	Submodules bool    `json:"submodules,omitempty" yaml:"submodules,omitempty"`
+	LFS        bool    `json:"lfs,omitempty" yaml:"lfs,omitempty"`
+	Dockerfile string  `json:"dockerfile,omitempty" yaml:"dockerfile,omitempty"`
+	Target     string  `json:"target,omitempty" yaml:"target,omitempty"`
+	// BuildArgs are passed to the Dockerfile as build arguments and are
+	// visible in image history; use secrets for credentials.
+	BuildArgs map[string]string `json:"buildArgs,omitempty" yaml:"buildArgs,omitempty"`
 }
 type Port struct {
-	ContainerPort int     `json:"containerPort" yaml:"containerPort"`
-	Public        bool    `json:"public" yaml:"public"`
-	Domain        *string `json:"domain,omitempty" yaml:"domain,omitempty"`
+	ContainerPort int         `json:"containerPort" yaml:"containerPort"`
+	Public        bool        `json:"public" yaml:"public"`
+	Domain        *string     `json:"domain,omitempty" yaml:"domain,omitempty"`
+	ClientAuth    *ClientAuth `json:"clientAuth,omitempty" yaml:"clientAuth,omitempty"`
+}
+
+// ClientAuth makes the proxy verify client certificates against CABundle
+// before requests reach the service.
+type ClientAuth struct {
+	Mode     string `json:"mode,omitempty" yaml:"mode,omitempty"`
+	CABundle string `json:"caBundle" yaml:"caBundle"`
 }
 type HealthCheck struct {
 	Cmd         string `json:"cmd" yaml:"cmd"`
@@ -131,10 +159,17 @@ func ApplyDefaults(m *Manifest) {
 			s.Repository = v
 		}
 	}
+	if s.Type == "git" && s.Repository != "" {
+		if v, err := CanonicalGitRepository(s.Repository); err == nil {
+			s.Repository = v
+		}
+	}
 	if s.RootDir != nil {
 		v := strings.ReplaceAll(strings.TrimSpace(*s.RootDir), "\\", "/")
 		s.RootDir = &v
 	}
+	s.Dockerfile = strings.ReplaceAll(strings.TrimSpace(s.Dockerfile), "\\", "/")
+	s.Target = strings.TrimSpace(s.Target)
 	if m.Service.Hostname != nil {
 		v := strings.TrimSpace(*m.Service.Hostname)
 		m.Service.Hostname = &v
@@ -143,12 +178,27 @@ func ApplyDefaults(m *Manifest) {
 		v := strings.TrimSpace(*m.Service.StartCommand)
 		m.Service.StartCommand = &v
 	}
+	if m.Service.ReleaseCommand != nil {
+		v := strings.TrimSpace(*m.Service.ReleaseCommand)
+		m.Service.ReleaseCommand = &v
+	}
 	if m.Service.Ports == nil {
 		m.Service.Ports = []Port{}
 	}
+	for _, p := range m.Service.Ports {
+		if a := p.ClientAuth; a != nil {
+			a.Mode = strings.ToLower(strings.TrimSpace(a.Mode))
+			if a.Mode == "" {
+				a.Mode = "require"
+			}
+			a.CABundle = strings.TrimSpace(a.CABundle)
+		}
+	}
 	for i := range m.Service.Crons {
 		m.Service.Crons[i].Path = strings.TrimSpace(m.Service.Crons[i].Path)
 		m.Service.Crons[i].Schedule = strings.TrimSpace(m.Service.Crons[i].Schedule)
+		m.Service.Crons[i].TimeZone = strings.TrimSpace(m.Service.Crons[i].TimeZone)
+		m.Service.Crons[i].ConcurrencyPolicy = strings.ToLower(strings.TrimSpace(m.Service.Crons[i].ConcurrencyPolicy))
 	}
 	if m.Service.Replicas == 0 {
 		m.Service.Replicas = 1
@@ -188,34 +238,49 @@ func Validate(m Manifest) error {
 		if s.Image == "" {
 			return errors.New("service.source.image is required")
 		}
-		if s.Repository != "" || s.Branch != "" || s.RootDir != nil {
-			return errors.New("image source cannot contain GitHub fields")
+		if s.Repository != "" || s.Branch != "" || s.RootDir != nil || s.Submodules || s.LFS {
+			return errors.New("image source cannot contain repository fields")
+		}
+		if s.Dockerfile != "" || s.Target != "" || len(s.BuildArgs) > 0 {
+			return errors.New("image source cannot contain build fields")
 		}
 	case "github":
 		if s.Image != "" {
 			return errors.New("github source cannot contain image")
 		}
+		if s.Submodules || s.LFS {
+			return errors.New("github source does not support submodules or lfs")
+		}
 		if _, err := CanonicalGitHubRepository(s.Repository); err != nil {
 			return fmt.Errorf("service.source.repository: %w", err)
 		}
 		if s.Branch == "" {
 			return errors.New("service.source.branch is required")
 		}
-		if s.RootDir != nil {
-			if *s.RootDir == "" {
-				return errors.New("service.source.rootDir cannot be blank")
-			}
-			if filepath.IsAbs(*s.RootDir) || strings.HasPrefix(*s.RootDir, "\\") || windowsAbsolutePath.MatchString(*s.RootDir) {
-				return errors.New("service.source.rootDir must be relative")
-			}
-			for _, p := range strings.FieldsFunc(*s.RootDir, func(r rune) bool { return r == '/' || r == '\\' }) {
-				if p == ".." {
-					return errors.New("service.source.rootDir cannot contain '..'")
-				}
-			}
+		if err := validateRootDir(s.RootDir); err != nil {
+			return err
+		}
+		if err := validateBuildOptions(s); err != nil {
+			return err
+		}
+	case "git":
+		if s.Image != "" {
+			return errors.New("git source cannot contain image")
+		}
+		if _, err := CanonicalGitRepository(s.Repository); err != nil {
+			return fmt.Errorf("service.source.repository: %w", err)
+		}
+		if s.Branch == "" {
+			return errors.New("service.source.branch is required")
+		}
+		if err := validateRootDir(s.RootDir); err != nil {
+			return err
+		}
+		if err := validateBuildOptions(s); err != nil {
+			return err
 		}
 	default:
-		return errors.New("service.source.type must be image or github")
+		return errors.New("service.source.type must be image, github, or git")
 	}
 	if m.Service.Hostname == nil {
 		return errors.New("service.hostname is required")
@@ -229,6 +294,9 @@ func Validate(m Manifest) error {
 	if m.Service.StartCommand != nil && *m.Service.StartCommand == "" {
 		return errors.New("service.startCommand cannot be blank")
 	}
+	if m.Service.ReleaseCommand != nil && *m.Service.ReleaseCommand == "" {
+		return errors.New("service.releaseCommand cannot be blank")
+	}
 	if m.Service.Replicas < 1 || m.Service.Replicas > 32 {
 		return errors.New("service.replicas must be between 1 and 32")
 	}
@@ -290,6 +358,17 @@ func Validate(m Manifest) error {
 			}
 			seenDomains[domain] = struct{}{}
 		}
+		if a := p.ClientAuth; a != nil {
+			if !p.Public {
+				return fmt.Errorf("service.ports[%d].clientAuth requires a public port", i)
+			}
+			if a.Mode != "" && a.Mode != "require" && a.Mode != "optional" {
+				return fmt.Errorf("service.ports[%d].clientAuth.mode must be require or optional", i)
+			}
+			if !strings.Contains(a.CABundle, "-----BEGIN CERTIFICATE-----") {
+				return fmt.Errorf("service.ports[%d].clientAuth.caBundle must contain PEM encoded CA certificates", i)
+			}
+		}
 		portsByNumber[p.ContainerPort] = append(portsByNumber[p.ContainerPort], p)
 	}
 	for containerPort, ports := range portsByNumber {
@@ -328,6 +407,23 @@ func Validate(m Manifest) error {
 		if len(cron.Schedule) > 255 || len(strings.Fields(cron.Schedule)) != 5 {
 			return fmt.Errorf("service.crons[%d].schedule must be a five-field cron expression", i)
 		}
+		if len(cron.TimeZone) > 64 || strings.ContainsAny(cron.TimeZone, " \t") {
+			return fmt.Errorf("service.crons[%d].timeZone must be an IANA time zone name", i)
+		}
+		if cron.TimeoutSeconds < 0 || cron.TimeoutSeconds > 300 {
+			return fmt.Errorf("service.crons[%d].timeoutSeconds must be between 1 and 300, or 0 for the default", i)
+		}
+		if cron.Retries < 0 || cron.Retries > 10 {
+			return fmt.Errorf("service.crons[%d].retries must be between 0 and 10", i)
+		}
+		if cron.RetryBackoffSeconds < 0 || cron.RetryBackoffSeconds > 3600 {
+			return fmt.Errorf("service.crons[%d].retryBackoffSeconds must be between 1 and 3600, or 0 for the default", i)
+		}
+		switch cron.ConcurrencyPolicy {
+		case "", "allow", "forbid", "replace":
+		default:
+			return fmt.Errorf("service.crons[%d].concurrencyPolicy must be allow, forbid or replace", i)
+		}
 	}
 	return nil
 }
@@ -355,6 +451,50 @@ func validateCronPath(value string) error {
 	}
 	return nil
 }
+func validateRootDir(rootDir *string) error {
+	if rootDir == nil {
+		return nil
+	}
+	if *rootDir == "" {
+		return errors.New("service.source.rootDir cannot be blank")
+	}
+	if filepath.IsAbs(*rootDir) || strings.HasPrefix(*rootDir, "\\") || windowsAbsolutePath.MatchString(*rootDir) {
+		return errors.New("service.source.rootDir must be relative")
+	}
+	for _, p := range strings.FieldsFunc(*rootDir, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if p == ".." {
+			return errors.New("service.source.rootDir cannot contain '..'")
+		}
+	}
+	return nil
+}
+func validateBuildOptions(s Source) error {
+	if s.Dockerfile != "" {
+		if filepath.IsAbs(s.Dockerfile) || strings.HasPrefix(s.Dockerfile, "/") || windowsAbsolutePath.MatchString(s.Dockerfile) {
+			return errors.New("service.source.dockerfile must be relative to rootDir")
+		}
+		for _, p := range strings.Split(s.Dockerfile, "/") {
+			if p == ".." {
+				return errors.New("service.source.dockerfile cannot contain '..'")
+			}
+		}
+	}
+	if s.Target != "" && !buildTargetPattern.MatchString(s.Target) {
+		return errors.New("service.source.target must be a Dockerfile stage name")
+	}
+	if len(s.BuildArgs) > maxBuildArgs {
+		return fmt.Errorf("service.source.buildArgs cannot have more than %d entries", maxBuildArgs)
+	}
+	for name, value := range s.BuildArgs {
+		if !buildArgNamePattern.MatchString(name) {
+			return fmt.Errorf("service.source.buildArgs: invalid name %q", name)
+		}
+		if strings.ContainsRune(value, 0) {
+			return fmt.Errorf("service.source.buildArgs.%s cannot contain NUL", name)
+		}
+	}
+	return nil
+}
 func (m Manifest) Linked() bool {
 	return m.Target != nil && strings.TrimSpace(m.Target.ServiceID) != ""
 }
@@ -377,6 +517,62 @@ func CanonicalGitHubRepository(value string) (string, error) {
 	return "https://github.com/" + parts[0] + "/" + parts[1], nil
 }
 
+// CanonicalGitRepository normalizes a generic git remote the same way the
+// control plane does: HTTPS without credentials, ssh:// URLs, or user@host:path.
+func CanonicalGitRepository(value string) (string, error) {
+	value = strings.TrimSpace(value)
+	if m := scpLikeGitRemote.FindStringSubmatch(value); m != nil {
+		if !gitUserPattern.MatchString(m[1]) || !gitHostPattern.MatchString(m[2]) || !safeGitRepositoryPath(m[3]) {
+			return "", errors.New("invalid git repository")
+		}
+		return m[1] + "@" + strings.ToLower(m[2]) + ":" + m[3], nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "ssh") {
+		return "", errors.New("must be an HTTPS or SSH git URL")
+	}
+	_, hasPassword := u.User.Password()
+	if hasPassword || (u.Scheme == "https" && u.User != nil) || u.RawQuery != "" || u.Fragment != "" {
+		return "", errors.New("must not contain credentials, query, or fragment")
+	}
+	path := strings.TrimRight(u.Path, "/")
+	if (u.User != nil && !gitUserPattern.MatchString(u.User.Username())) || !gitHostPattern.MatchString(u.Hostname()) || !safeGitRepositoryPath(path) {
+		return "", errors.New("invalid git repository URL")
+	}
+	user := ""
+	if u.User != nil {
+		user = u.User.Username() + "@"
+	}
+	port := ""
+	if u.Port() != "" {
+		port = ":" + u.Port()
+	}
+	return u.Scheme + "://" + user + strings.ToLower(u.Hostname()) + port + path, nil
+}
+
+// IsSSHGitRepository reports whether a canonical git remote is reached over SSH.
+func IsSSHGitRepository(repository string) bool {
+	return !strings.HasPrefix(repository, "https://")
+}
+
+func safeGitRepositoryPath(path string) bool {
+	parts := strings.FieldsFunc(path, func(r rune) bool { return r == '/' })
+	if len(parts) == 0 || strings.ContainsAny(path, " \t\r\n\\\x00\x7f") {
+		return false
+	}
+	for _, r := range path {
+		if r < 0x20 {
+			return false
+		}
+	}
+	for _, part := range parts {
+		if part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
 func Slugify(v string) string {
 	value := strings.Trim(slugChars.ReplaceAllString(strings.ToLower(v), "-"), "-")
 	if len(value) > 63 {
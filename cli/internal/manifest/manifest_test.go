@@ -1,6 +1,8 @@
 package manifest
 
 import (
+	"fmt"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -41,6 +43,23 @@ func TestCronValidation(t *testing.T) {
 	if err := Validate(m); err == nil || !strings.Contains(err.Error(), "five-field") {
 		t.Fatalf("schedule error = %v", err)
 	}
+
+	for field, cron := range map[string]Cron{
+		"timeZone":            {Path: "/jobs/nightly", Schedule: "0 5 * * *", TimeZone: "Europe/ Berlin"},
+		"timeoutSeconds":      {Path: "/jobs/nightly", Schedule: "0 5 * * *", TimeoutSeconds: 301},
+		"retries":             {Path: "/jobs/nightly", Schedule: "0 5 * * *", Retries: 11},
+		"retryBackoffSeconds": {Path: "/jobs/nightly", Schedule: "0 5 * * *", RetryBackoffSeconds: -1},
+		"concurrencyPolicy":   {Path: "/jobs/nightly", Schedule: "0 5 * * *", ConcurrencyPolicy: "queue"},
+	} {
+		m.Service.Crons = []Cron{cron}
+		if err := Validate(m); err == nil || !strings.Contains(err.Error(), field) {
+			t.Fatalf("%s error = %v", field, err)
+		}
+	}
+	m.Service.Crons = []Cron{{Path: "/jobs/nightly", Schedule: "0 5 * * *", TimeZone: "Europe/Berlin", TimeoutSeconds: 60, Retries: 3, RetryBackoffSeconds: 30, ConcurrencyPolicy: "replace"}}
+	if err := Validate(m); err != nil {
+		t.Fatalf("valid cron policy rejected: %v", err)
+	}
 }
 
 func TestHostnameValidationAndSlugify(t *testing.T) {
@@ -123,6 +142,32 @@ func TestReplicaLimit(t *testing.T) {
 	}
 }
 
+func TestReleaseCommandIsTrimmedAndMustNotBeBlank(t *testing.T) {
+	got, err := Parse([]byte(`apiVersion: v1
+service:
+  name: web
+  source: {type: image, image: nginx}
+  hostname: web
+  placement: {mode: automatic}
+  releaseCommand: "  bin/migrate  "
+`))
+	if err != nil || got.Service.ReleaseCommand == nil || *got.Service.ReleaseCommand != "bin/migrate" {
+		t.Fatalf("got=%#v err=%v", got.Service.ReleaseCommand, err)
+	}
+
+	_, err = Parse([]byte(`apiVersion: v1
+service:
+  name: web
+  source: {type: image, image: nginx}
+  hostname: web
+  placement: {mode: automatic}
+  releaseCommand: "   "
+`))
+	if err == nil || !strings.Contains(err.Error(), "service.releaseCommand cannot be blank") {
+		t.Fatalf("error = %v", err)
+	}
+}
+
 func TestPlacementIsRequired(t *testing.T) {
 	_, err := Parse([]byte(`apiVersion: v1
 service:
@@ -170,6 +215,56 @@ func TestRejectGitHubURL(t *testing.T) {
 		}
 	}
 }
+func TestGitRepositoryCanonical(t *testing.T) {
+	for input, want := range map[string]string{
+		" https://Git.Example.com/acme/app.git/ ":       "https://git.example.com/acme/app.git",
+		"ssh://git@git.example.com:2222/acme/app.git":   "ssh://git@git.example.com:2222/acme/app.git",
+		"git@Git.Example.com:acme/app.git":              "git@git.example.com:acme/app.git",
+		"https://git.example.com/group/sub/project.git": "https://git.example.com/group/sub/project.git",
+	} {
+		if got, err := CanonicalGitRepository(input); err != nil || got != want {
+			t.Fatalf("CanonicalGitRepository(%q) = %q, %v; want %q", input, got, err, want)
+		}
+	}
+	for _, v := range []string{"http://git.example.com/a/b", "https://token@git.example.com/a/b", "https://git.example.com/a/b?ref=main", "file:///srv/app.git", "git@git.example.com:../app.git", "ext::sh -c id"} {
+		if _, err := CanonicalGitRepository(v); err == nil {
+			t.Fatalf("accepted %s", v)
+		}
+	}
+	if !IsSSHGitRepository("git@git.example.com:acme/app.git") || IsSSHGitRepository("https://git.example.com/acme/app.git") {
+		t.Fatal("IsSSHGitRepository misclassified a remote")
+	}
+}
+
+func TestGitSourceValidation(t *testing.T) {
+	m := base()
+	m.Service.Source = Source{Type: "git", Repository: "git@git.example.com:acme/app.git", Branch: "main", Submodules: true, LFS: true}
+	b, err := Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "submodules: true") || !strings.Contains(string(b), "lfs: true") {
+		t.Fatalf("git options were not written: %s", b)
+	}
+	if got, err := Parse(b); err != nil || !reflect.DeepEqual(got.Service.Source, m.Service.Source) {
+		t.Fatalf("got=%#v err=%v", got.Service.Source, err)
+	}
+
+	m.Service.Source.Branch = ""
+	if err := Validate(m); err == nil || !strings.Contains(err.Error(), "branch") {
+		t.Fatalf("missing branch error = %v", err)
+	}
+	root := "../x"
+	m.Service.Source.Branch = "main"
+	m.Service.Source.RootDir = &root
+	if Validate(m) == nil {
+		t.Fatal("escaping root accepted")
+	}
+	m.Service.Source = Source{Type: "github", Repository: "https://github.com/a/b", Branch: "main", Submodules: true}
+	if err := Validate(m); err == nil || !strings.Contains(err.Error(), "submodules") {
+		t.Fatalf("github submodules error = %v", err)
+	}
+}
 
 func TestRejectWindowsAbsoluteRootDir(t *testing.T) {
 	for _, root := range []string{`C:\app`, `D:/service`, `\\server\share`} {
@@ -237,3 +332,80 @@ func TestPublicAndInternalPortDomainRules(t *testing.T) {
 		t.Fatalf("valid public/internal ports rejected: %v", err)
 	}
 }
+
+func TestPortClientAuth(t *testing.T) {
+	domain := "api.example.com"
+	bundle := "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+	m := base()
+	m.Service.Ports = []Port{{ContainerPort: 80, Public: true, Domain: &domain, ClientAuth: &ClientAuth{CABundle: bundle}}}
+	ApplyDefaults(&m)
+	if err := Validate(m); err != nil || m.Service.Ports[0].ClientAuth.Mode != "require" {
+		t.Fatalf("client auth = %#v err=%v", m.Service.Ports[0].ClientAuth, err)
+	}
+	for _, tc := range []struct {
+		name string
+		port Port
+		want string
+	}{
+		{"internal port", Port{ContainerPort: 80, ClientAuth: &ClientAuth{CABundle: bundle}}, "requires a public port"},
+		{"unknown mode", Port{ContainerPort: 80, Public: true, Domain: &domain, ClientAuth: &ClientAuth{Mode: "any", CABundle: bundle}}, "require or optional"},
+		{"missing bundle", Port{ContainerPort: 80, Public: true, Domain: &domain, ClientAuth: &ClientAuth{Mode: "optional"}}, "PEM encoded"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			m := base()
+			m.Service.Ports = []Port{tc.port}
+			if err := Validate(m); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("error = %v", err)
+			}
+		})
+	}
+}
+
+func TestBuildOptions(t *testing.T) {
+	m := base()
+	m.Service.Source = Source{Type: "github", Repository: "https://github.com/acme/app", Branch: "main", Dockerfile: " docker\\Dockerfile.prod ", Target: "runtime", BuildArgs: map[string]string{"NODE_ENV": "production"}}
+	ApplyDefaults(&m)
+	if m.Service.Source.Dockerfile != "docker/Dockerfile.prod" {
+		t.Fatalf("dockerfile = %q", m.Service.Source.Dockerfile)
+	}
+	if err := Validate(m); err != nil {
+		t.Fatal(err)
+	}
+	b, err := Marshal(m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Parse(b); err != nil || !reflect.DeepEqual(got.Service.Source, m.Service.Source) {
+		t.Fatalf("got=%#v err=%v", got.Service.Source, err)
+	}
+
+	for _, tc := range []struct {
+		name   string
+		mutate func(*Source)
+		want   string
+	}{
+		{"escaping dockerfile", func(s *Source) { s.Dockerfile = "../Dockerfile" }, "cannot contain '..'"},
+		{"absolute dockerfile", func(s *Source) { s.Dockerfile = "/Dockerfile" }, "must be relative"},
+		{"bad target", func(s *Source) { s.Target = "run time" }, "stage name"},
+		{"bad arg name", func(s *Source) { s.BuildArgs = map[string]string{"NODE-ENV": "x"} }, "invalid name"},
+		{"image source", func(s *Source) { *s = Source{Type: "image", Image: "nginx", Target: "runtime"} }, "cannot contain build fields"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			invalid := m
+			invalid.Service.Source.BuildArgs = map[string]string{"NODE_ENV": "production"}
+			tc.mutate(&invalid.Service.Source)
+			if err := Validate(invalid); err == nil || !strings.Contains(err.Error(), tc.want) {
+				t.Fatalf("Validate() = %v, want %q", err, tc.want)
+			}
+		})
+	}
+
+	tooMany := m
+	tooMany.Service.Source.BuildArgs = map[string]string{}
+	for i := 0; i <= maxBuildArgs; i++ {
+		tooMany.Service.Source.BuildArgs[fmt.Sprintf("ARG_%d", i)] = "x"
+	}
+	if err := Validate(tooMany); err == nil || !strings.Contains(err.Error(), "more than 64") {
+		t.Fatalf("too many build args error = %v", err)
+	}
+}
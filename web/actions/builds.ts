@@ -1,76 +1,19 @@
 "use server";
 
-import { and, eq, inArray, isNull } from "drizzle-orm";
+import { and, eq, isNull } from "drizzle-orm";
 import { db } from "@/db";
 import { builds, githubRepos, services } from "@/db/schema";
 import { requireDeveloperRole } from "@/lib/auth";
+import { cancelBuildInternal, rebuildBuildInternal } from "@/lib/build-control";
 import { isFullCommitSha, listGitHubCommits } from "@/lib/github";
-import { inngest } from "@/lib/inngest/client";
-import { inngestEvents } from "@/lib/inngest/events";
 import {
-	requeueBuildRevisionInternal,
 	triggerBuildInternal,
 	triggerResolvedBuildInternal,
 } from "@/lib/trigger-build";
 
 export async function cancelBuild(buildId: string) {
 	await requireDeveloperRole();
-	const [build] = await db.select().from(builds).where(eq(builds.id, buildId));
-
-	if (!build) {
-		throw new Error("Build not found");
-	}
-
-	const cancellableStatuses = [
-		"pending",
-		"claimed",
-		"cloning",
-		"building",
-		"pushing",
-	];
-	if (!cancellableStatuses.includes(build.status)) {
-		throw new Error(`Cannot cancel build in ${build.status} status`);
-	}
-
-	const cancelled = await db
-		.update(builds)
-		.set({ status: "cancelled", completedAt: new Date() })
-		.where(
-			and(
-				eq(builds.id, buildId),
-				inArray(builds.status, [
-					"pending",
-					"claimed",
-					"cloning",
-					"building",
-					"pushing",
-				]),
-			),
-		)
-		.returning({ id: builds.id })
-		.then((rows) => rows[0]);
-	if (!cancelled) {
-		const current = await db
-			.select({ status: builds.status })
-			.from(builds)
-			.where(eq(builds.id, buildId))
-			.then((rows) => rows[0]);
-		if (!current) throw new Error("Build not found");
-		throw new Error(`Cannot cancel build in ${current.status} status`);
-	}
-
-	await inngest.send(
-		inngestEvents.buildCancelled.create(
-			{
-				buildId,
-				buildGroupId: build.buildGroupId,
-			},
-			{
-				id: `build-cancelled-${buildId}`,
-			},
-		),
-	);
-
+	await cancelBuildInternal(buildId);
 	return { success: true };
 }
 
@@ -83,34 +26,14 @@ export async function retryBuild(buildId: string) {
 		throw new Error("Build not found");
 	}
 
-	const [service] = await db
-		.select({ id: services.id, previewOfService: services.previewOfService })
-		.from(services)
-		.where(and(eq(services.id, build.serviceId), isNull(services.deletedAt)));
-
-	if (!service) {
-		throw new Error("Service not found");
-	}
-
 	if (build.status !== "failed" && build.status !== "cancelled") {
 		throw new Error(`Cannot retry build in ${build.status} status`);
 	}
 
-	const actor = {
-		type: "user" as const,
+	await rebuildBuildInternal(build, {
+		type: "user",
 		userId: session.user.id,
 		name: session.user.name,
-	};
-	if (service.previewOfService) {
-		await triggerBuildInternal(build.serviceId, "manual", actor);
-		return { success: true };
-	}
-	await requeueBuildRevisionInternal({
-		serviceId: build.serviceId,
-		serviceRevisionId: build.serviceRevisionId,
-		commitMessage: build.commitMessage ?? "Retry build",
-		author: build.author ?? undefined,
-		actor,
 	});
 
 	return { success: true };
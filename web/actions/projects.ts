@@ -49,6 +49,7 @@ import { validateDockerImageInternal } from "@/lib/docker-image";
 import { inngest } from "@/lib/inngest/client";
 import { inngestEvents } from "@/lib/inngest/events";
 import { allocatePort } from "@/lib/port-allocation";
+import { clientAuthSchema } from "@/lib/public-api";
 import { resolveRegistryImageHost } from "@/lib/registry-reference";
 import {
 	cleanupRegistryArtifactsForService,
@@ -71,8 +72,15 @@ import type {
 } from "@/lib/service-config";
 import { MIN_SERVERLESS_SLEEP_AFTER_SECONDS } from "@/lib/service-config";
 import {
+	findKeepWarmWindowIssue,
 	findServicePortValidationIssue,
 	getDefaultServiceHostname,
+	isServerlessEventPath,
+	MAX_SERVERLESS_KEEP_WARM_WINDOWS,
+	MAX_SERVERLESS_QUEUED_REQUESTS,
+	MAX_SERVERLESS_STREAM_SECONDS,
+	type ServerlessAutoscalingPolicy,
+	type ServerlessKeepWarmWindow,
 } from "@/lib/service-revision-spec";
 import type { DeleteConfirmation } from "@/lib/two-factor";
 import { getZodErrorMessage, slugify } from "@/lib/utils";
@@ -1132,6 +1140,61 @@ const serverlessSettingsSchema = z.object({
 		.min(MIN_SERVERLESS_SLEEP_AFTER_SECONDS)
 		.max(86_400),
 	wakeTimeoutSeconds: z.number().int().min(10).max(900),
+	autoscaling: z
+		.object({
+			targetConcurrency: z.number().int().min(1).max(10_000),
+			minReplicas: z.number().int().min(1).max(32),
+			maxReplicas: z.number().int().min(1).max(32),
+			scaleDownCooldownSeconds: z.number().int().min(30).max(3600),
+		})
+		.refine((policy) => policy.minReplicas <= policy.maxReplicas, {
+			message: "Minimum replicas cannot exceed maximum replicas",
+		})
+		.nullable()
+		.optional(),
+	maxQueuedRequests: z
+		.number()
+		.int()
+		.min(1)
+		.max(MAX_SERVERLESS_QUEUED_REQUESTS)
+		.nullable()
+		.optional(),
+	maxQueueWaitSeconds: z.number().int().min(1).max(900).nullable().optional(),
+	keepWarm: z
+		.array(
+			z.object({
+				days: z.array(z.string()).optional(),
+				start: z.string(),
+				end: z.string(),
+				timeZone: z.string().optional(),
+				prewarmSeconds: z.number().int().optional(),
+			}),
+		)
+		.max(MAX_SERVERLESS_KEEP_WARM_WINDOWS)
+		.nullable()
+		.optional(),
+	streamIdleTimeoutSeconds: z
+		.number()
+		.int()
+		.min(1)
+		.max(MAX_SERVERLESS_STREAM_SECONDS)
+		.nullable()
+		.optional(),
+	maxConnectionLifetimeSeconds: z
+		.number()
+		.int()
+		.min(1)
+		.max(MAX_SERVERLESS_STREAM_SECONDS)
+		.nullable()
+		.optional(),
+	eventPath: z
+		.string()
+		.trim()
+		.refine(isServerlessEventPath, {
+			message: "Event path must be an absolute path such as /events",
+		})
+		.nullable()
+		.optional(),
 });
 
 export async function updateServiceServerlessSettings(
@@ -1140,10 +1203,23 @@ export async function updateServiceServerlessSettings(
 		enabled: boolean;
 		sleepAfterSeconds: number;
 		wakeTimeoutSeconds: number;
+		autoscaling?: ServerlessAutoscalingPolicy | null;
+		maxQueuedRequests?: number | null;
+		maxQueueWaitSeconds?: number | null;
+		keepWarm?: ServerlessKeepWarmWindow[] | null;
+		streamIdleTimeoutSeconds?: number | null;
+		maxConnectionLifetimeSeconds?: number | null;
+		eventPath?: string | null;
 	},
 ) {
 	await requireDeveloperRole();
 	const validated = serverlessSettingsSchema.parse(settings);
+	const keepWarmIssue = validated.keepWarm
+		?.map(findKeepWarmWindowIssue)
+		.find((issue) => issue !== null);
+	if (keepWarmIssue) {
+		throw new Error(keepWarmIssue);
+	}
 
 	await db.transaction(async (tx) => {
 		await tx.execute(sql`SELECT pg_advisory_xact_lock(hashtext(${serviceId}))`);
@@ -1226,6 +1302,9 @@ export async function updateServiceServerlessSettings(
 					"Serverless services can only be deployed to proxy nodes",
 				);
 			}
+			if (validated.autoscaling && service.placementMode !== "automatic") {
+				throw new Error("Serverless autoscaling requires automatic placement");
+			}
 		}
 
 		await tx
@@ -1234,6 +1313,37 @@ export async function updateServiceServerlessSettings(
 				serverlessEnabled: validated.enabled,
 				serverlessSleepAfterSeconds: validated.sleepAfterSeconds,
 				serverlessWakeTimeoutSeconds: validated.wakeTimeoutSeconds,
+				...(validated.autoscaling !== undefined
+					? { serverlessAutoscaling: validated.autoscaling }
+					: {}),
+				...(validated.maxQueuedRequests !== undefined
+					? { serverlessMaxQueuedRequests: validated.maxQueuedRequests }
+					: {}),
+				...(validated.maxQueueWaitSeconds !== undefined
+					? { serverlessMaxQueueWaitSeconds: validated.maxQueueWaitSeconds }
+					: {}),
+				...(validated.keepWarm !== undefined
+					? {
+							serverlessKeepWarm: validated.keepWarm?.length
+								? validated.keepWarm
+								: null,
+						}
+					: {}),
+				...(validated.streamIdleTimeoutSeconds !== undefined
+					? {
+							serverlessStreamIdleTimeoutSeconds:
+								validated.streamIdleTimeoutSeconds,
+						}
+					: {}),
+				...(validated.maxConnectionLifetimeSeconds !== undefined
+					? {
+							serverlessMaxConnectionLifetimeSeconds:
+								validated.maxConnectionLifetimeSeconds,
+						}
+					: {}),
+				...(validated.eventPath !== undefined
+					? { serverlessEventPath: validated.eventPath }
+					: {}),
 			})
 			.where(eq(services.id, serviceId));
 	});
@@ -1397,7 +1507,12 @@ export async function updateServiceConfig(
 				}
 				for (const port of config.ports?.add ?? []) {
 					const protocol = port.protocol ?? "http";
+					const clientAuth = port.clientAuth
+						? clientAuthSchema.parse(port.clientAuth)
+						: null;
 					const domain = port.domain?.trim().toLowerCase() || null;
+					const tlsTermination =
+						protocol === "tcp" && (port.tlsTermination ?? false);
 					const externalPort =
 						port.isPublic && (protocol === "tcp" || protocol === "udp")
 							? await allocatePort(
@@ -1417,11 +1532,18 @@ export async function updateServiceConfig(
 						serviceId,
 						port: port.port,
 						isPublic: port.isPublic,
-						domain: protocol === "http" && port.isPublic ? domain : null,
+						domain:
+							port.isPublic && (protocol === "http" || tlsTermination)
+								? domain
+								: null,
 						protocol,
 						externalPort,
 						tlsPassthrough:
 							protocol === "tcp" ? (port.tlsPassthrough ?? false) : false,
+						tlsTermination,
+						proxyProtocol: port.proxyProtocol ?? null,
+						clientAuthMode: clientAuth?.mode ?? null,
+						clientCaBundle: clientAuth?.caBundle ?? null,
 					});
 				}
 
@@ -1435,6 +1557,15 @@ export async function updateServiceConfig(
 						isPublic: port.isPublic ?? false,
 						domain: port.domain ?? null,
 						protocol: port.protocol ?? "http",
+						tlsPassthrough: port.tlsPassthrough ?? false,
+						tlsTermination: port.tlsTermination ?? false,
+						proxyProtocol: port.proxyProtocol ?? undefined,
+						clientAuth: port.clientAuthMode
+							? {
+									mode: port.clientAuthMode,
+									caBundle: port.clientCaBundle ?? "",
+								}
+							: undefined,
 					})),
 				);
 				if (issue) throw new Error(issue.message);
@@ -17,6 +17,7 @@ import {
 	emailAlertsConfigSchema,
 	SETTING_KEYS,
 } from "@/lib/settings-keys";
+import { parseTrustedProxies } from "@/lib/trusted-proxies";
 import { getZodErrorMessage } from "@/lib/utils";
 
 async function requireAdminSession() {
@@ -69,6 +70,17 @@ export async function updateEdgeDomain(domain: string) {
 	return { success: true, hostname };
 }
 
+export async function updateTrustedProxies(input: string) {
+	await requireAdminSession();
+	const trustedProxies = parseTrustedProxies(input);
+	await setSetting(
+		SETTING_KEYS.TRUSTED_PROXIES,
+		trustedProxies.length > 0 ? trustedProxies : null,
+	);
+	revalidatePath("/dashboard/settings");
+	return { success: true, trustedProxies };
+}
+
 export async function updateAutoSubdomainDomain(domain: string) {
 	await requireAdminSession();
 	const hostname = domain.trim().toLowerCase().replace(/\.$/, "");
@@ -99,6 +99,7 @@ export default async function ServerDetailPage({
 						networkHealth: server.networkHealth,
 						containerHealth: server.containerHealth,
 						agentHealth: server.agentHealth,
+						certificateIssues: server.certificateIssues,
 					}}
 					initialMetrics={metricsSnapshot}
 				/>
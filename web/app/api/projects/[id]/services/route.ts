@@ -27,6 +27,7 @@ import {
 	type SourceConfig,
 } from "@/lib/service-config";
 import { parseServiceRevisionSpec } from "@/lib/service-revision-changes";
+import { isBuiltServiceSourceType } from "@/lib/service-revision-spec";
 
 const MAX_CANVAS_COORDINATE = 2_147_483_647;
 
@@ -246,7 +247,7 @@ export async function GET(
 							.where(eq(servers.id, service.lockedServerId))
 							.then((r) => r[0])
 					: Promise.resolve(null),
-				service.sourceType === "github"
+				isBuiltServiceSourceType(service.sourceType)
 					? db
 							.select({ id: builds.id, status: builds.status })
 							.from(builds)
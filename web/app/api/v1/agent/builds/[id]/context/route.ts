@@ -0,0 +1,69 @@
+import { and, eq, inArray } from "drizzle-orm";
+import { type NextRequest, NextResponse } from "next/server";
+import { db } from "@/db";
+import { builds, serviceRevisions } from "@/db/schema";
+import { verifyAgentRequest } from "@/lib/agent-auth";
+import { getBuildContext } from "@/lib/build-contexts";
+import { parseServiceRevisionSpec } from "@/lib/service-revision-changes";
+
+export async function GET(
+	request: NextRequest,
+	{ params }: { params: Promise<{ id: string }> },
+) {
+	const auth = await verifyAgentRequest(request);
+	if (!auth.success) {
+		return NextResponse.json({ error: auth.error }, { status: auth.status });
+	}
+
+	const { id: buildId } = await params;
+	const build = await db
+		.select({
+			serviceId: builds.serviceId,
+			specification: serviceRevisions.specification,
+		})
+		.from(builds)
+		.innerJoin(
+			serviceRevisions,
+			and(
+				eq(serviceRevisions.id, builds.serviceRevisionId),
+				eq(serviceRevisions.serviceId, builds.serviceId),
+			),
+		)
+		.where(
+			and(
+				eq(builds.id, buildId),
+				eq(builds.claimedBy, auth.serverId),
+				inArray(builds.status, ["claimed", "cloning", "building", "pushing"]),
+			),
+		)
+		.then((rows) => rows[0]);
+	if (!build) {
+		return NextResponse.json(
+			{ error: "Build not found or not claimed by this agent" },
+			{ status: 404 },
+		);
+	}
+
+	const { source } = parseServiceRevisionSpec(build.specification);
+	if (source.type !== "local") {
+		return NextResponse.json(
+			{ error: "Build does not use an uploaded context" },
+			{ status: 409 },
+		);
+	}
+	const context = await getBuildContext(build.serviceId, source.contextId);
+	if (!context || context.checksum !== source.checksum) {
+		return NextResponse.json(
+			{ error: "Build context is no longer available" },
+			{ status: 404 },
+		);
+	}
+
+	return new NextResponse(new Uint8Array(context.data), {
+		headers: {
+			"content-type": "application/gzip",
+			"content-length": String(context.sizeBytes),
+			"x-context-checksum": context.checksum,
+		},
+	});
+}
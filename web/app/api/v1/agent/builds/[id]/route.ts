@@ -2,13 +2,23 @@ import { and, eq, inArray } from "drizzle-orm";
 import { type NextRequest, NextResponse } from "next/server";
 import { db } from "@/db";
 import { getSetting } from "@/db/queries";
-import { builds, serviceRevisions, services } from "@/db/schema";
+import {
+	buildContexts,
+	builds,
+	serviceRevisions,
+	services,
+} from "@/db/schema";
 import { verifyAgentRequest } from "@/lib/agent-auth";
 import { cloneUrlForRevisionSource } from "@/lib/build-revision-source";
+import { getServiceGitCredential } from "@/lib/git-remote";
 import { inngest } from "@/lib/inngest/client";
 import { inngestEvents } from "@/lib/inngest/events";
 import { parseServiceRevisionSpec } from "@/lib/service-revision-changes";
 import { reportOperationFailure, reportServerError } from "@/lib/server-errors";
+import {
+	isBuiltServiceRevisionSource,
+	serviceRevisionBuildRef,
+} from "@/lib/service-revision-spec";
 import {
 	DEFAULT_BUILD_TIMEOUT_MINUTES,
 	SETTING_KEYS,
@@ -137,34 +147,79 @@ export async function POST(
 		console.error("[build:get] invalid service revision:", error);
 		return failClaim("Invalid build service revision");
 	}
+	const source = specification.source;
+	if (!isBuiltServiceRevisionSource(source)) {
+		return failClaim("Build metadata does not match its service revision");
+	}
+	const buildRef = serviceRevisionBuildRef(source);
 	if (
-		specification.source.type !== "github" ||
-		specification.source.commitSha !== build.commitSha ||
-		specification.source.branch !== build.branch
+		buildRef.commitSha !== build.commitSha ||
+		buildRef.branch !== build.branch
 	) {
 		return failClaim("Build metadata does not match its service revision");
 	}
 
-	let cloneUrl: string;
-	try {
-		cloneUrl = await cloneUrlForRevisionSource(specification.source);
-	} catch (error) {
-		reportServerError(error, "agent.build.claim.github-token", {
-			tags: {
-				buildId,
-				serviceId: build.serviceId,
-				revisionId: build.serviceRevisionId,
-				serverId,
-			},
-		});
-		console.error("[build:get] failed to get installation token:", error);
-		return failClaim("Failed to get GitHub installation token");
+	let cloneUrl = "";
+	let contextUrl = "";
+	let gitAuth: {
+		type: "https_token" | "ssh_key";
+		username: string;
+		encryptedSecret: string;
+		knownHosts: string;
+	} | null = null;
+	if (source.type === "local") {
+		const context = await db
+			.select({ id: buildContexts.id, checksum: buildContexts.checksum })
+			.from(buildContexts)
+			.where(
+				and(
+					eq(buildContexts.id, source.contextId),
+					eq(buildContexts.serviceId, build.serviceId),
+				),
+			)
+			.then((rows) => rows[0]);
+		if (!context || context.checksum !== source.checksum) {
+			return failClaim("Build context is no longer available", 404);
+		}
+		contextUrl = `/api/v1/agent/builds/${buildId}/context`;
+	} else if (source.type === "git") {
+		cloneUrl = source.repository;
+		if (source.authentication.type !== "none") {
+			const credential = await getServiceGitCredential(build.serviceId);
+			if (!credential || credential.type !== source.authentication.type) {
+				return failClaim(
+					"Git credentials for this service changed since the build was queued",
+					409,
+				);
+			}
+			gitAuth = {
+				type: credential.type,
+				username: credential.username ?? "",
+				encryptedSecret: credential.encryptedSecret,
+				knownHosts: credential.knownHosts ?? "",
+			};
+		}
+	} else {
+		try {
+			cloneUrl = await cloneUrlForRevisionSource(source);
+		} catch (error) {
+			reportServerError(error, "agent.build.claim.github-token", {
+				tags: {
+					buildId,
+					serviceId: build.serviceId,
+					revisionId: build.serviceRevisionId,
+					serverId,
+				},
+			});
+			console.error("[build:get] failed to get installation token:", error);
+			return failClaim("Failed to get GitHub installation token");
+		}
 	}
 
 	const secretsMap = Object.fromEntries(
 		specification.secrets.map((secret) => [secret.key, secret.encryptedValue]),
 	);
-	const targetPlatforms = [build.targetPlatform];
+	const targetPlatforms = build.targetPlatform.split(",");
 
 	console.log(
 		`[build:get] build ${buildId.slice(0, 8)} details fetched by ${serverId.slice(0, 8)}, revision: ${build.serviceRevisionId.slice(0, 8)}, image: ${specification.image}`,
@@ -173,17 +228,25 @@ export async function POST(
 	return NextResponse.json({
 		build: {
 			id: build.id,
-			commitSha: specification.source.commitSha,
+			commitSha: buildRef.commitSha,
 			commitMessage: build.commitMessage,
-			branch: specification.source.branch,
+			branch: buildRef.branch,
 			gitRef: service.previewGitRef,
 			serviceId: build.serviceId,
 			projectId: service.projectId,
 		},
 		cloneUrl,
+		contextUrl,
+		contextChecksum: source.type === "local" ? source.checksum : "",
 		imageRepository: imageRepository(specification.image),
 		imageUri: specification.image,
-		rootDir: specification.source.rootDir ?? "",
+		rootDir: source.rootDir ?? "",
+		gitAuth,
+		submodules: source.type === "git" && source.submodules,
+		lfs: source.type === "git" && source.lfs,
+		dockerfile: specification.build?.dockerfile ?? "",
+		target: specification.build?.target ?? "",
+		buildArgs: specification.build?.args ?? {},
 		secrets: secretsMap,
 		timeoutMinutes: buildTimeoutMinutes ?? DEFAULT_BUILD_TIMEOUT_MINUTES,
 		targetPlatforms,
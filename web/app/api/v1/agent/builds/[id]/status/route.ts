@@ -2,6 +2,7 @@ import { and, desc, eq, inArray, isNull, sql } from "drizzle-orm";
 import { type NextRequest, NextResponse } from "next/server";
 import { db } from "@/db";
 import {
+	type BuildAttestation,
 	builds,
 	environments,
 	projects,
@@ -17,6 +18,11 @@ import { notify } from "@/lib/notifications";
 import { updatePreviewGitHubStatus } from "@/lib/preview-deployments";
 import { parseServiceRevisionSpec } from "@/lib/service-revision-changes";
 import { reportOperationFailure, reportServerError } from "@/lib/server-errors";
+import {
+	isBuiltServiceRevisionSource,
+	serviceRevisionBuildRef,
+} from "@/lib/service-revision-spec";
+import { parseVulnerabilitySummary } from "@/lib/vulnerability-gate";
 import { enqueueWork } from "@/lib/work-queue";
 
 type StatusUpdate = {
@@ -24,8 +30,15 @@ type StatusUpdate = {
 	error?: string;
 	resolvedCommitSha?: string;
 	imageUri?: string;
+	cache?: { hits?: unknown; steps?: unknown };
+	attestations?: unknown;
+	vulnerabilities?: unknown;
 };
 
+const MAX_ATTESTATIONS = 16;
+const manifestDigestPattern = /^sha256:[0-9a-f]{64}$/;
+const platformPattern = /^[a-z0-9]+\/[a-z0-9_]+(?:\/[a-z0-9]+)?$/;
+
 const validStatuses = new Set<StatusUpdate["status"]>([
 	"cloning",
 	"building",
@@ -60,6 +73,69 @@ function digestImageRepository(image: string) {
 	return match?.[1] ?? null;
 }
 
+function validCacheStats(cache: StatusUpdate["cache"]) {
+	const hits = cache?.hits;
+	const steps = cache?.steps;
+	if (
+		typeof hits !== "number" ||
+		typeof steps !== "number" ||
+		!Number.isInteger(hits) ||
+		!Number.isInteger(steps) ||
+		hits < 0 ||
+		hits > steps
+	) {
+		return null;
+	}
+	return { hits, steps };
+}
+
+function optionalDigest(value: unknown) {
+	if (value === undefined || value === null || value === "") return null;
+	return typeof value === "string" && manifestDigestPattern.test(value)
+		? value
+		: undefined;
+}
+
+function validAttestations(
+	attestations: StatusUpdate["attestations"],
+): BuildAttestation[] | null {
+	if (
+		!Array.isArray(attestations) ||
+		attestations.length === 0 ||
+		attestations.length > MAX_ATTESTATIONS
+	) {
+		return null;
+	}
+	const valid: BuildAttestation[] = [];
+	for (const entry of attestations) {
+		if (!entry || typeof entry !== "object") return null;
+		const { platform, imageDigest, manifestDigest, ...layers } =
+			entry as Record<string, unknown>;
+		const sbomDigest = optionalDigest(layers.sbomDigest);
+		const provenanceDigest = optionalDigest(layers.provenanceDigest);
+		if (
+			typeof platform !== "string" ||
+			!platformPattern.test(platform) ||
+			typeof imageDigest !== "string" ||
+			!manifestDigestPattern.test(imageDigest) ||
+			typeof manifestDigest !== "string" ||
+			!manifestDigestPattern.test(manifestDigest) ||
+			sbomDigest === undefined ||
+			provenanceDigest === undefined
+		) {
+			return null;
+		}
+		valid.push({
+			platform,
+			imageDigest,
+			manifestDigest,
+			sbomDigest,
+			provenanceDigest,
+		});
+	}
+	return valid;
+}
+
 async function sendBuildCompletedEvent(data: {
 	buildId: string;
 	serviceId: string;
@@ -158,10 +234,13 @@ export async function POST(
 			{ status: 500 },
 		);
 	}
+	const buildRef = isBuiltServiceRevisionSource(specification.source)
+		? serviceRevisionBuildRef(specification.source)
+		: null;
 	if (
-		specification.source.type !== "github" ||
-		specification.source.commitSha !== build.commitSha ||
-		specification.source.branch !== build.branch
+		!buildRef ||
+		buildRef.commitSha !== build.commitSha ||
+		buildRef.branch !== build.branch
 	) {
 		return NextResponse.json(
 			{ error: "Build metadata does not match its service revision" },
@@ -170,7 +249,10 @@ export async function POST(
 	}
 	if (
 		update.resolvedCommitSha &&
-		update.resolvedCommitSha.toLowerCase() !== specification.source.commitSha
+		((specification.source.type !== "github" &&
+			specification.source.type !== "git") ||
+			update.resolvedCommitSha.toLowerCase() !==
+				specification.source.commitSha)
 	) {
 		return NextResponse.json(
 			{ error: "Resolved commit does not match the service revision" },
@@ -209,6 +291,19 @@ export async function POST(
 	}
 	if (update.error) updateData.error = update.error;
 	if (platformImageUri) updateData.imageUri = platformImageUri;
+	const cache = validCacheStats(update.cache);
+	if (cache && (update.status === "completed" || update.status === "failed")) {
+		updateData.cacheHits = cache.hits;
+		updateData.cacheSteps = cache.steps;
+	}
+	const attestations = validAttestations(update.attestations);
+	if (attestations && update.status === "completed") {
+		updateData.attestations = attestations;
+	}
+	const vulnerabilities = parseVulnerabilitySummary(update.vulnerabilities);
+	if (vulnerabilities && update.status === "completed") {
+		updateData.vulnerabilities = vulnerabilities;
+	}
 
 	const transitionedBuild = await db
 		.update(builds)
@@ -266,6 +361,7 @@ export async function POST(
 	if (
 		!replayingTerminalUpdate &&
 		build.githubDeploymentId &&
+		specification.source.type === "github" &&
 		specification.source.authentication.type === "github_app"
 	) {
 		try {
@@ -6,6 +6,7 @@ import {
 	claimNextWorkItem,
 	completeWorkItemResults,
 	type CompletedWorkItem,
+	holdsWorkSlot,
 	renewActiveWorkItems,
 } from "@/lib/work-queue";
 
@@ -13,6 +14,7 @@ type StatusRequestBody = {
 	statusReport?: StatusReport;
 	completedWorkItems?: CompletedWorkItem[];
 	activeWorkItems?: ActiveWorkItem[];
+	buildCapacity?: number;
 	serverlessTransitions?: unknown[];
 };
 
@@ -63,8 +65,16 @@ export async function POST(request: NextRequest) {
 
 	const rejectedActive = await renewActiveWorkItems(serverId, activeWorkItems);
 
-	const nextWorkItem =
-		activeWorkItems.length === 0 ? await claimNextWorkItem(serverId) : null;
+	const buildCapacity = Number.isInteger(data.buildCapacity)
+		? Number(data.buildCapacity)
+		: 0;
+
+	const slotBusy = await holdsWorkSlot(serverId, activeWorkItems);
+	const nextWorkItem = !slotBusy
+		? await claimNextWorkItem(serverId)
+		: buildCapacity > 0
+			? await claimNextWorkItem(serverId, { buildOnly: true })
+			: null;
 
 	return NextResponse.json({
 		ok: true,
@@ -0,0 +1,5 @@
+export {
+	deleteSigningPolicy as DELETE,
+	getSigningPolicy as GET,
+	putSigningPolicy as PUT,
+} from "@/lib/public-api-routes";
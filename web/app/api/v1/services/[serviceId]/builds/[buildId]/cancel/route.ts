@@ -0,0 +1 @@
+export { postBuildCancel as POST } from "@/lib/public-api-routes";
@@ -0,0 +1 @@
+export { getBuildLogs as GET } from "@/lib/public-api-routes";
@@ -0,0 +1 @@
+export { postBuildRebuild as POST } from "@/lib/public-api-routes";
@@ -0,0 +1 @@
+export { getCrons as GET } from "@/lib/public-api-routes";
@@ -0,0 +1 @@
+export { postLocalDeploy as POST } from "@/lib/public-api-routes";
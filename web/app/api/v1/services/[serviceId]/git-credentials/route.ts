@@ -0,0 +1,5 @@
+export {
+	deleteGitCredentials as DELETE,
+	getGitCredentials as GET,
+	putGitCredentials as PUT,
+} from "@/lib/public-api-routes";
@@ -0,0 +1 @@
+export { getRunLogs as GET } from "@/lib/public-api-routes";
@@ -0,0 +1 @@
+export { getRun as GET } from "@/lib/public-api-routes";
@@ -0,0 +1 @@
+export { postRun as POST } from "@/lib/public-api-routes";
@@ -0,0 +1,4 @@
+export {
+	getVulnerabilityPolicy as GET,
+	putVulnerabilityPolicy as PUT,
+} from "@/lib/public-api-routes";
@@ -47,6 +47,7 @@ type ServerOverview = Pick<
 	| "networkHealth"
 	| "containerHealth"
 	| "agentHealth"
+	| "certificateIssues"
 >;
 
 export type ServerMetricMode = "cpu" | "memory" | "disk";
@@ -446,8 +447,28 @@ function ServerOverviewPanel({
 					)}
 				</ConfigRow>
 				<ConfigRow label="Agent">{agentHealth?.version ?? "Unknown"}</ConfigRow>
+				{server.isProxy ? (
+					<ConfigRow label="Certificates">
+						{formatCertificateIssues(server.certificateIssues)}
+					</ConfigRow>
+				) : null}
 			</div>
 
+			{server.isProxy && server.certificateIssues?.length ? (
+				<div className="space-y-1.5 border-border border-t px-3 py-2.5 text-sm">
+					{server.certificateIssues.map((issue) => (
+						<ConfigRow
+							key={`${issue.domain}:${issue.issue}`}
+							label={issue.domain}
+						>
+							<span className="text-amber-600 dark:text-amber-400">
+								{describeCertificateIssue(issue)}
+							</span>
+						</ConfigRow>
+					))}
+				</div>
+			) : null}
+
 			<div className="flex-1 divide-y divide-border border-border border-t text-sm">
 				<div className="space-y-1.5 px-3 py-2.5">
 					<ConfigRow label="Public IP">{server.publicIp || "—"}</ConfigRow>
@@ -595,6 +616,31 @@ function getCurrentBytes(
 	return mode === "cpu" ? null : (current?.[`${mode}UsedBytes`] ?? null);
 }
 
+function formatCertificateIssues(issues: Server["certificateIssues"]) {
+	if (!issues) return "Unknown";
+	if (issues.length === 0) return "Healthy";
+	return `${issues.length} ${issues.length === 1 ? "issue" : "issues"}`;
+}
+
+function describeCertificateIssue(
+	issue: NonNullable<Server["certificateIssues"]>[number],
+) {
+	switch (issue.issue) {
+		case "expiring":
+			return issue.notAfter
+				? `Expires ${formatRelativeTime(issue.notAfter)}`
+				: "Expiring soon";
+		case "expired":
+			return "Expired";
+		case "domain_mismatch":
+			return "Does not cover domain";
+		case "key_mismatch":
+			return "Key does not match";
+		case "invalid":
+			return "Invalid certificate";
+	}
+}
+
 function formatHealth(healthy: boolean | undefined, detail: string) {
 	if (healthy === undefined) return "Unknown";
 	return healthy ? detail : `${detail} · unavailable`;
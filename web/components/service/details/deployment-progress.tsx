@@ -25,6 +25,7 @@ type StageInfo = {
 const STAGES: StageInfo[] = [
 	{ id: "migrating", label: "Migrating" },
 	{ id: "queued", label: "Queued" },
+	{ id: "release", label: "Running Release" },
 	{ id: "deploying", label: "Starting" },
 	{ id: "health_check", label: "Checking Health" },
 	{ id: "dns_sync", label: "Routing traffic" },
@@ -15,6 +15,7 @@ import {
 	getServiceTotalReplicas,
 	hasBuildAffectingChanges,
 } from "@/lib/service-config";
+import { isBuiltServiceSourceType } from "@/lib/service-revision-spec";
 
 interface PendingChangesBannerProps {
 	service: Service;
@@ -40,7 +41,7 @@ export const PendingChangesBanner = memo(function PendingChangesBanner({
 	const totalReplicas = getServiceTotalReplicas(service);
 	const hasNoDeployments = service.deployments.length === 0;
 	const shouldBuild =
-		service.sourceType === "github" &&
+		isBuiltServiceSourceType(service.sourceType) &&
 		(hasNoDeployments || hasBuildAffectingChanges(changes));
 
 	const hasChanges = changes.length > 0;
@@ -58,6 +58,7 @@ const STATUS_CONFIG: Record<
 const STAGE_LABELS: Record<string, string> = {
 	queued: "Queued",
 	preparing: "Preparing",
+	release: "Release Command",
 	certificates: "Issuing Certificates",
 	deploying: "Deploying",
 	health_check: "Health Check",
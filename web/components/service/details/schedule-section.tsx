@@ -7,6 +7,7 @@ import { ConfigSection } from "@/components/service/details/config-section";
 import { Button } from "@/components/ui/button";
 import { Input } from "@/components/ui/input";
 import type { ServiceWithDetails as Service } from "@/db/types";
+import { isBuiltServiceSourceType } from "@/lib/service-revision-spec";
 
 export const ScheduleSection = memo(function ScheduleSection({
 	service,
@@ -97,7 +98,7 @@ export const ScheduleSection = memo(function ScheduleSection({
 						<p className="text-xs text-muted-foreground">{description}</p>
 					)}
 					<p className="text-xs text-muted-foreground">
-						{service.sourceType === "github"
+						{isBuiltServiceSourceType(service.sourceType)
 							? "Triggers a build and deploy on schedule"
 							: "Redeploys the current image on schedule"}
 					</p>
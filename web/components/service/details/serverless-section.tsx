@@ -9,6 +9,23 @@ import { Input } from "@/components/ui/input";
 import { Switch } from "@/components/ui/switch";
 import type { ServiceWithDetails as Service } from "@/db/types";
 import { MIN_SERVERLESS_SLEEP_AFTER_SECONDS } from "@/lib/service-config";
+import {
+	findKeepWarmWindowIssue,
+	isServerlessEventPath,
+	isServerlessStreamDuration,
+	MAX_SERVERLESS_KEEP_WARM_WINDOWS,
+	MAX_SERVERLESS_QUEUED_REQUESTS,
+	MAX_SERVERLESS_STREAM_SECONDS,
+	type ServerlessKeepWarmWindow,
+} from "@/lib/service-revision-spec";
+
+type KeepWarmDraft = {
+	days: string;
+	start: string;
+	end: string;
+	timeZone: string;
+	prewarmSeconds: string;
+};
 
 type ServerlessSectionProps = {
 	service: Service;
@@ -25,7 +42,7 @@ export const ServerlessSection = memo(function ServerlessSection(
 	if (!hasPublicHttpEndpoint) return null;
 
 	// Persisted changes are authoritative and intentionally discard any stale local draft.
-	const settingsKey = `${service.id}:${service.serverlessEnabled}:${service.serverlessSleepAfterSeconds}:${service.serverlessWakeTimeoutSeconds}`;
+	const settingsKey = `${service.id}:${service.serverlessEnabled}:${service.serverlessSleepAfterSeconds}:${service.serverlessWakeTimeoutSeconds}:${JSON.stringify(service.serverlessAutoscaling)}:${service.serverlessMaxQueuedRequests}:${service.serverlessMaxQueueWaitSeconds}:${JSON.stringify(service.serverlessKeepWarm)}:${service.serverlessStreamIdleTimeoutSeconds}:${service.serverlessMaxConnectionLifetimeSeconds}:${service.serverlessEventPath}`;
 
 	return (
 		<ConfigSection
@@ -52,6 +69,43 @@ function ServerlessSectionEditor({
 	const [wakeTimeoutSeconds, setWakeTimeoutSeconds] = useState(
 		String(service.serverlessWakeTimeoutSeconds ?? 300),
 	);
+	const [maxQueuedRequests, setMaxQueuedRequests] = useState(
+		String(service.serverlessMaxQueuedRequests ?? ""),
+	);
+	const [maxQueueWaitSeconds, setMaxQueueWaitSeconds] = useState(
+		String(service.serverlessMaxQueueWaitSeconds ?? ""),
+	);
+	const [streamIdleTimeoutSeconds, setStreamIdleTimeoutSeconds] = useState(
+		String(service.serverlessStreamIdleTimeoutSeconds ?? ""),
+	);
+	const [maxConnectionLifetimeSeconds, setMaxConnectionLifetimeSeconds] =
+		useState(String(service.serverlessMaxConnectionLifetimeSeconds ?? ""));
+	const [eventPath, setEventPath] = useState(service.serverlessEventPath ?? "");
+	const [keepWarm, setKeepWarm] = useState<KeepWarmDraft[]>(() =>
+		(service.serverlessKeepWarm ?? []).map((entry) => ({
+			days: entry.days?.join(",") ?? "",
+			start: entry.start,
+			end: entry.end,
+			timeZone: entry.timeZone ?? "",
+			prewarmSeconds: String(entry.prewarmSeconds ?? ""),
+		})),
+	);
+	const [autoscalingEnabled, setAutoscalingEnabled] = useState(
+		!!service.serverlessAutoscaling,
+	);
+	const [targetConcurrency, setTargetConcurrency] = useState(
+		String(service.serverlessAutoscaling?.targetConcurrency ?? 10),
+	);
+	const [minReplicas, setMinReplicas] = useState(
+		String(service.serverlessAutoscaling?.minReplicas ?? 1),
+	);
+	const [maxReplicas, setMaxReplicas] = useState(
+		String(service.serverlessAutoscaling?.maxReplicas ?? 3),
+	);
+	const [scaleDownCooldownSeconds, setScaleDownCooldownSeconds] = useState(
+		String(service.serverlessAutoscaling?.scaleDownCooldownSeconds ?? 300),
+	);
+	const supportsAutoscaling = service.placementMode === "automatic";
 	const hasWorkerReplica = service.configuredReplicas.some(
 		(replica) => replica.count > 0 && !replica.serverIsProxy,
 	);
@@ -60,12 +114,52 @@ function ServerlessSectionEditor({
 		: null;
 	const optionsDisabled = !!unavailableReason || isSaving;
 
+	const updateKeepWarm = (index: number, patch: Partial<KeepWarmDraft>) =>
+		setKeepWarm((windows) =>
+			windows.map((entry, position) =>
+				position === index ? { ...entry, ...patch } : entry,
+			),
+		);
+
 	const parsed = useMemo(
 		() => ({
 			sleepAfterSeconds: Number.parseInt(sleepAfterSeconds, 10),
 			wakeTimeoutSeconds: Number.parseInt(wakeTimeoutSeconds, 10),
+			maxQueuedRequests: parseOptionalInteger(maxQueuedRequests),
+			maxQueueWaitSeconds: parseOptionalInteger(maxQueueWaitSeconds),
+			streamIdleTimeoutSeconds: parseOptionalInteger(streamIdleTimeoutSeconds),
+			maxConnectionLifetimeSeconds: parseOptionalInteger(
+				maxConnectionLifetimeSeconds,
+			),
+			eventPath: eventPath.trim() || null,
+			keepWarm: keepWarm.map(parseKeepWarmDraft),
+			autoscaling: autoscalingEnabled
+				? {
+						targetConcurrency: Number.parseInt(targetConcurrency, 10),
+						minReplicas: Number.parseInt(minReplicas, 10),
+						maxReplicas: Number.parseInt(maxReplicas, 10),
+						scaleDownCooldownSeconds: Number.parseInt(
+							scaleDownCooldownSeconds,
+							10,
+						),
+					}
+				: null,
 		}),
-		[sleepAfterSeconds, wakeTimeoutSeconds],
+		[
+			sleepAfterSeconds,
+			wakeTimeoutSeconds,
+			maxQueuedRequests,
+			maxQueueWaitSeconds,
+			streamIdleTimeoutSeconds,
+			maxConnectionLifetimeSeconds,
+			eventPath,
+			keepWarm,
+			autoscalingEnabled,
+			targetConcurrency,
+			minReplicas,
+			maxReplicas,
+			scaleDownCooldownSeconds,
+		],
 	);
 
 	const validationError = useMemo(() => {
@@ -86,13 +180,87 @@ function ServerlessSectionEditor({
 		) {
 			return "Wake timeout must be between 10 and 900 seconds";
 		}
+		if (
+			parsed.maxQueuedRequests !== null &&
+			(!Number.isInteger(parsed.maxQueuedRequests) ||
+				parsed.maxQueuedRequests < 1 ||
+				parsed.maxQueuedRequests > MAX_SERVERLESS_QUEUED_REQUESTS)
+		) {
+			return `Max queued requests must be between 1 and ${MAX_SERVERLESS_QUEUED_REQUESTS}`;
+		}
+		if (
+			parsed.maxQueueWaitSeconds !== null &&
+			(!Number.isInteger(parsed.maxQueueWaitSeconds) ||
+				parsed.maxQueueWaitSeconds < 1 ||
+				parsed.maxQueueWaitSeconds > 900)
+		) {
+			return "Max queue wait must be between 1 and 900 seconds";
+		}
+		if (
+			parsed.streamIdleTimeoutSeconds !== null &&
+			!isServerlessStreamDuration(parsed.streamIdleTimeoutSeconds)
+		) {
+			return `Stream idle timeout must be between 1 and ${MAX_SERVERLESS_STREAM_SECONDS} seconds`;
+		}
+		if (
+			parsed.maxConnectionLifetimeSeconds !== null &&
+			!isServerlessStreamDuration(parsed.maxConnectionLifetimeSeconds)
+		) {
+			return `Max connection lifetime must be between 1 and ${MAX_SERVERLESS_STREAM_SECONDS} seconds`;
+		}
+		if (parsed.eventPath !== null && !isServerlessEventPath(parsed.eventPath)) {
+			return "Event path must be an absolute path such as /events";
+		}
+		const keepWarmIssue = parsed.keepWarm
+			.map(findKeepWarmWindowIssue)
+			.find((issue) => issue !== null);
+		if (keepWarmIssue) {
+			return keepWarmIssue;
+		}
+		const { autoscaling } = parsed;
+		if (autoscaling) {
+			if (
+				!Number.isInteger(autoscaling.targetConcurrency) ||
+				autoscaling.targetConcurrency < 1 ||
+				autoscaling.targetConcurrency > 10_000
+			) {
+				return "Target concurrency must be between 1 and 10000";
+			}
+			if (
+				!Number.isInteger(autoscaling.minReplicas) ||
+				!Number.isInteger(autoscaling.maxReplicas) ||
+				autoscaling.minReplicas < 1 ||
+				autoscaling.maxReplicas > 32 ||
+				autoscaling.minReplicas > autoscaling.maxReplicas
+			) {
+				return "Replicas must satisfy 1 <= minimum <= maximum <= 32";
+			}
+			if (
+				!Number.isInteger(autoscaling.scaleDownCooldownSeconds) ||
+				autoscaling.scaleDownCooldownSeconds < 30 ||
+				autoscaling.scaleDownCooldownSeconds > 3600
+			) {
+				return "Scale down cooldown must be between 30 and 3600 seconds";
+			}
+		}
 		return null;
 	}, [enabled, parsed, unavailableReason]);
 
 	const hasChanges =
 		enabled !== service.serverlessEnabled ||
 		parsed.sleepAfterSeconds !== service.serverlessSleepAfterSeconds ||
-		parsed.wakeTimeoutSeconds !== service.serverlessWakeTimeoutSeconds;
+		parsed.wakeTimeoutSeconds !== service.serverlessWakeTimeoutSeconds ||
+		parsed.maxQueuedRequests !== service.serverlessMaxQueuedRequests ||
+		parsed.maxQueueWaitSeconds !== service.serverlessMaxQueueWaitSeconds ||
+		parsed.streamIdleTimeoutSeconds !==
+			service.serverlessStreamIdleTimeoutSeconds ||
+		parsed.maxConnectionLifetimeSeconds !==
+			service.serverlessMaxConnectionLifetimeSeconds ||
+		parsed.eventPath !== (service.serverlessEventPath ?? null) ||
+		JSON.stringify(parsed.keepWarm) !==
+			JSON.stringify(service.serverlessKeepWarm ?? []) ||
+		JSON.stringify(parsed.autoscaling) !==
+			JSON.stringify(service.serverlessAutoscaling ?? null);
 
 	const handleSave = async () => {
 		setIsSaving(true);
@@ -101,6 +269,13 @@ function ServerlessSectionEditor({
 				enabled,
 				sleepAfterSeconds: parsed.sleepAfterSeconds,
 				wakeTimeoutSeconds: parsed.wakeTimeoutSeconds,
+				maxQueuedRequests: parsed.maxQueuedRequests,
+				maxQueueWaitSeconds: parsed.maxQueueWaitSeconds,
+				streamIdleTimeoutSeconds: parsed.streamIdleTimeoutSeconds,
+				maxConnectionLifetimeSeconds: parsed.maxConnectionLifetimeSeconds,
+				eventPath: parsed.eventPath,
+				keepWarm: parsed.keepWarm,
+				autoscaling: parsed.autoscaling,
 			});
 			onUpdate();
 		} catch (error) {
@@ -161,11 +336,297 @@ function ServerlessSectionEditor({
 						onChange={(event) => setWakeTimeoutSeconds(event.target.value)}
 					/>
 				</div>
+				<div className="space-y-1">
+					<label
+						htmlFor="serverless-max-queued-requests"
+						className="text-xs font-medium"
+					>
+						Max Queued Requests
+					</label>
+					<Input
+						id="serverless-max-queued-requests"
+						type="number"
+						min="1"
+						max={MAX_SERVERLESS_QUEUED_REQUESTS}
+						placeholder="1000"
+						value={maxQueuedRequests}
+						disabled={optionsDisabled}
+						onChange={(event) => setMaxQueuedRequests(event.target.value)}
+					/>
+				</div>
+				<div className="space-y-1">
+					<label
+						htmlFor="serverless-max-queue-wait"
+						className="text-xs font-medium"
+					>
+						Max Queue Wait (s)
+					</label>
+					<Input
+						id="serverless-max-queue-wait"
+						type="number"
+						min="1"
+						max="900"
+						placeholder="Wake timeout"
+						value={maxQueueWaitSeconds}
+						disabled={optionsDisabled}
+						onChange={(event) => setMaxQueueWaitSeconds(event.target.value)}
+					/>
+				</div>
+				<div className="space-y-1">
+					<label
+						htmlFor="serverless-stream-idle-timeout"
+						className="text-xs font-medium"
+					>
+						Stream Idle Timeout (s)
+					</label>
+					<Input
+						id="serverless-stream-idle-timeout"
+						type="number"
+						min="1"
+						max={MAX_SERVERLESS_STREAM_SECONDS}
+						placeholder="Sleep after"
+						value={streamIdleTimeoutSeconds}
+						disabled={optionsDisabled}
+						onChange={(event) =>
+							setStreamIdleTimeoutSeconds(event.target.value)
+						}
+					/>
+				</div>
+				<div className="space-y-1">
+					<label
+						htmlFor="serverless-max-connection-lifetime"
+						className="text-xs font-medium"
+					>
+						Max Connection Lifetime (s)
+					</label>
+					<Input
+						id="serverless-max-connection-lifetime"
+						type="number"
+						min="1"
+						max={MAX_SERVERLESS_STREAM_SECONDS}
+						placeholder="Unlimited"
+						value={maxConnectionLifetimeSeconds}
+						disabled={optionsDisabled}
+						onChange={(event) =>
+							setMaxConnectionLifetimeSeconds(event.target.value)
+						}
+					/>
+				</div>
+				<div className="space-y-1">
+					<label
+						htmlFor="serverless-event-path"
+						className="text-xs font-medium"
+					>
+						Event Path
+					</label>
+					<Input
+						id="serverless-event-path"
+						placeholder="Disabled"
+						value={eventPath}
+						disabled={optionsDisabled}
+						onChange={(event) => setEventPath(event.target.value)}
+					/>
+				</div>
+			</div>
+
+			<div className="space-y-2">
+				<div className="flex items-center justify-between gap-4">
+					<span className="text-sm font-medium">Keep-warm windows</span>
+					<Button
+						variant="outline"
+						size="sm"
+						disabled={
+							optionsDisabled ||
+							keepWarm.length >= MAX_SERVERLESS_KEEP_WARM_WINDOWS
+						}
+						onClick={() =>
+							setKeepWarm((windows) => [
+								...windows,
+								{
+									days: "mon,tue,wed,thu,fri",
+									start: "08:00",
+									end: "20:00",
+									timeZone: "UTC",
+									prewarmSeconds: "",
+								},
+							])
+						}
+					>
+						Add window
+					</Button>
+				</div>
+				{keepWarm.map((entry, index) => (
+					<div
+						// oxlint-disable-next-line react/no-array-index-key -- Windows have no identity beyond their position.
+						key={index}
+						className="grid gap-2 md:grid-cols-[2fr_1fr_1fr_2fr_1fr_auto]"
+					>
+						<Input
+							aria-label="Days"
+							placeholder="Every day"
+							value={entry.days}
+							disabled={optionsDisabled}
+							onChange={(event) =>
+								updateKeepWarm(index, { days: event.target.value })
+							}
+						/>
+						<Input
+							aria-label="Start"
+							type="time"
+							value={entry.start}
+							disabled={optionsDisabled}
+							onChange={(event) =>
+								updateKeepWarm(index, { start: event.target.value })
+							}
+						/>
+						<Input
+							aria-label="End"
+							type="time"
+							value={entry.end}
+							disabled={optionsDisabled}
+							onChange={(event) =>
+								updateKeepWarm(index, { end: event.target.value })
+							}
+						/>
+						<Input
+							aria-label="Time zone"
+							placeholder="UTC"
+							value={entry.timeZone}
+							disabled={optionsDisabled}
+							onChange={(event) =>
+								updateKeepWarm(index, { timeZone: event.target.value })
+							}
+						/>
+						<Input
+							aria-label="Prewarm seconds"
+							type="number"
+							min="0"
+							max="3600"
+							placeholder="Prewarm (s)"
+							value={entry.prewarmSeconds}
+							disabled={optionsDisabled}
+							onChange={(event) =>
+								updateKeepWarm(index, { prewarmSeconds: event.target.value })
+							}
+						/>
+						<Button
+							variant="ghost"
+							size="sm"
+							disabled={optionsDisabled}
+							onClick={() =>
+								setKeepWarm((windows) =>
+									windows.filter((_, position) => position !== index),
+								)
+							}
+						>
+							Remove
+						</Button>
+					</div>
+				))}
+				<p className="text-xs text-muted-foreground">
+					Replicas stay awake inside these windows and wake early by the
+					prewarm lead. Outside them the sleep timeout applies.
+				</p>
 			</div>
 
+			{supportsAutoscaling && (
+				<div className="space-y-3">
+					<div className="flex items-center justify-between gap-4">
+						<span className="text-sm font-medium">Scale on concurrency</span>
+						<Switch
+							checked={autoscalingEnabled}
+							onCheckedChange={setAutoscalingEnabled}
+							disabled={optionsDisabled}
+						/>
+					</div>
+					{autoscalingEnabled && (
+						<div className="grid gap-3 md:grid-cols-4">
+							<div className="space-y-1">
+								<label
+									htmlFor="serverless-target-concurrency"
+									className="text-xs font-medium"
+								>
+									Requests / Replica
+								</label>
+								<Input
+									id="serverless-target-concurrency"
+									type="number"
+									min="1"
+									max="10000"
+									value={targetConcurrency}
+									disabled={optionsDisabled}
+									onChange={(event) => setTargetConcurrency(event.target.value)}
+								/>
+							</div>
+							<div className="space-y-1">
+								<label
+									htmlFor="serverless-min-replicas"
+									className="text-xs font-medium"
+								>
+									Min Replicas
+								</label>
+								<Input
+									id="serverless-min-replicas"
+									type="number"
+									min="1"
+									max="32"
+									value={minReplicas}
+									disabled={optionsDisabled}
+									onChange={(event) => setMinReplicas(event.target.value)}
+								/>
+							</div>
+							<div className="space-y-1">
+								<label
+									htmlFor="serverless-max-replicas"
+									className="text-xs font-medium"
+								>
+									Max Replicas
+								</label>
+								<Input
+									id="serverless-max-replicas"
+									type="number"
+									min="1"
+									max="32"
+									value={maxReplicas}
+									disabled={optionsDisabled}
+									onChange={(event) => setMaxReplicas(event.target.value)}
+								/>
+							</div>
+							<div className="space-y-1">
+								<label
+									htmlFor="serverless-scale-down-cooldown"
+									className="text-xs font-medium"
+								>
+									Scale Down After (s)
+								</label>
+								<Input
+									id="serverless-scale-down-cooldown"
+									type="number"
+									min="30"
+									max="3600"
+									step="30"
+									value={scaleDownCooldownSeconds}
+									disabled={optionsDisabled}
+									onChange={(event) =>
+										setScaleDownCooldownSeconds(event.target.value)
+									}
+								/>
+							</div>
+						</div>
+					)}
+				</div>
+			)}
+
 			<p className="text-sm text-muted-foreground">
 				Containers scale down to zero when idle and wake on traffic. Requests
 				while sleeping are queued and served after the container is ready.
+				Requests beyond the queue limit receive a 429, and requests that wait
+				too long receive a 503, both with a Retry-After header. Open WebSocket
+				and streaming connections keep the service awake until they go idle
+				for the stream idle timeout or reach the max connection lifetime. With
+				an event path set, events posted to the agent are queued and delivered
+				to that path, waking the service if needed. Public TCP ports also wake
+				the service when a connection arrives.
 			</p>
 
 			{unavailableReason && !enabled && (
@@ -188,3 +649,22 @@ function ServerlessSectionEditor({
 		</div>
 	);
 }
+
+function parseOptionalInteger(value: string) {
+	return value.trim() ? Number.parseInt(value, 10) : null;
+}
+
+function parseKeepWarmDraft(draft: KeepWarmDraft): ServerlessKeepWarmWindow {
+	const days = draft.days
+		.split(/[\s,]+/)
+		.map((day) => day.trim().toLowerCase())
+		.filter(Boolean);
+	const prewarmSeconds = parseOptionalInteger(draft.prewarmSeconds);
+	return {
+		...(days.length > 0 ? { days } : {}),
+		start: draft.start,
+		end: draft.end,
+		...(draft.timeZone.trim() ? { timeZone: draft.timeZone.trim() } : {}),
+		...(prewarmSeconds ? { prewarmSeconds } : {}),
+	};
+}
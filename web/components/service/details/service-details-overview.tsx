@@ -1,6 +1,6 @@
 "use client";
 
-import { Box, Github, type LucideIcon } from "lucide-react";
+import { Box, GitBranch, Github, type LucideIcon } from "lucide-react";
 import { type ReactNode, useMemo, useState } from "react";
 import {
 	CartesianGrid,
@@ -822,6 +822,20 @@ function getSourceInfo(service: Service): SourceInfo {
 		};
 	}
 
+	if (service.sourceType === "git" && service.gitRepoUrl) {
+		return {
+			icon: GitBranch,
+			label: service.gitRepoUrl
+				.replace(/^(https|ssh):\/\//, "")
+				.replace(/\.git$/, ""),
+			detail: "Git",
+			href: service.gitRepoUrl.startsWith("https://")
+				? service.gitRepoUrl
+				: undefined,
+			branch: service.gitBranch || "main",
+		};
+	}
+
 	return {
 		icon: Box,
 		label: service.image,
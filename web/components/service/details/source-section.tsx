@@ -244,6 +244,60 @@ export const SourceSection = memo(function SourceSection({
 		);
 	}
 
+	if (service.sourceType === "git" && service.gitRepoUrl) {
+		return (
+			<ConfigSection
+				title="Source"
+				summary={`${service.gitRepoUrl}@${service.gitBranch || "main"}`}
+			>
+				<div className="space-y-4">
+					<div className="grid gap-4 sm:grid-cols-3">
+						<div className="space-y-1">
+							<p className="text-xs font-medium text-muted-foreground">
+								Repository
+							</p>
+							<p className="text-sm font-mono break-all">
+								{service.gitRepoUrl}
+							</p>
+						</div>
+						<div className="space-y-1">
+							<p className="text-xs font-medium text-muted-foreground flex items-center gap-1">
+								<GitBranch className="size-3" />
+								Branch
+							</p>
+							<p className="text-sm font-mono">
+								{service.gitBranch || "main"}
+							</p>
+						</div>
+						{service.gitRootDir && (
+							<div className="space-y-1">
+								<p className="text-xs font-medium text-muted-foreground">
+									Root Directory
+								</p>
+								<p className="text-sm font-mono">{service.gitRootDir}</p>
+							</div>
+						)}
+					</div>
+					{(service.gitSubmodules || service.gitLfs) && (
+						<p className="text-xs text-muted-foreground">
+							{[
+								service.gitSubmodules && "Submodules",
+								service.gitLfs && "Git LFS",
+							]
+								.filter(Boolean)
+								.join(" and ")}{" "}
+							enabled
+						</p>
+					)}
+					<p className="text-xs text-muted-foreground">
+						Git sources and their credentials are managed with the tc CLI or
+						the public API.
+					</p>
+				</div>
+			</ConfigSection>
+		);
+	}
+
 	return (
 		<ConfigSection title="Source" summary={service.image}>
 			<div>
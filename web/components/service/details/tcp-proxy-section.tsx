@@ -6,6 +6,10 @@ import { toast } from "sonner";
 import { updateServiceConfig } from "@/actions/projects";
 import { Button } from "@/components/ui/button";
 import { Input } from "@/components/ui/input";
+import {
+	NativeSelect,
+	NativeSelectOption,
+} from "@/components/ui/native-select";
 import { Switch } from "@/components/ui/switch";
 import type { ServiceWithDetails as Service } from "@/db/types";
 
@@ -21,6 +25,9 @@ export const TCPUDPPorts = memo(function TCPUDPPorts({
 	const [newPort, setNewPort] = useState("");
 	const [protocol, setProtocol] = useState<"tcp" | "udp">("tcp");
 	const [tlsPassthrough, setTlsPassthrough] = useState(false);
+	const [tlsTermination, setTlsTermination] = useState(false);
+	const [domain, setDomain] = useState("");
+	const [proxyProtocol, setProxyProtocol] = useState<"" | "1" | "2">("");
 	const [isSaving, setIsSaving] = useState(false);
 	const [copiedPort, setCopiedPort] = useState<string | null>(null);
 
@@ -34,8 +41,10 @@ export const TCPUDPPorts = memo(function TCPUDPPorts({
 		Number.isInteger(port) &&
 		port >= 1 &&
 		port <= 65535;
+	const terminatesTls = protocol === "tcp" && tlsTermination;
 	const canAdd =
 		isValidPort &&
+		(!terminatesTls || domain.trim() !== "") &&
 		!tcpUdpPorts.some((p) => p.port === port && p.protocol === protocol) &&
 		!isSaving;
 
@@ -52,15 +61,23 @@ export const TCPUDPPorts = memo(function TCPUDPPorts({
 						{
 							port,
 							isPublic: true,
-							domain: null,
+							domain: terminatesTls ? domain.trim() : null,
 							protocol,
 							tlsPassthrough: protocol === "tcp" ? tlsPassthrough : undefined,
+							tlsTermination: terminatesTls || undefined,
+							proxyProtocol:
+								protocol === "tcp" && proxyProtocol
+									? (Number(proxyProtocol) as 1 | 2)
+									: undefined,
 						},
 					],
 				},
 			});
 			setNewPort("");
 			setTlsPassthrough(false);
+			setTlsTermination(false);
+			setDomain("");
+			setProxyProtocol("");
 			onUpdate();
 		} catch (error) {
 			toast.error(
@@ -90,9 +107,11 @@ export const TCPUDPPorts = memo(function TCPUDPPorts({
 	const getConnectionString = (port: {
 		protocol: string | null;
 		externalPort: number | null;
+		domain: string | null;
 	}) => {
-		if (!port.externalPort || !edgeDomain) return null;
-		return `${port.protocol}://${edgeDomain}:${port.externalPort}`;
+		const host = port.domain ?? edgeDomain;
+		if (!port.externalPort || !host) return null;
+		return `${port.protocol}://${host}:${port.externalPort}`;
 	};
 
 	const copyToClipboard = async (text: string, portId: string) => {
@@ -128,6 +147,17 @@ export const TCPUDPPorts = memo(function TCPUDPPorts({
 												TLS
 											</span>
 										)}
+										{port.tlsTermination && (
+											<span className="text-xs px-1.5 py-0.5 rounded bg-background flex items-center gap-1">
+												<Lock className="h-3 w-3" />
+												TLS terminated
+											</span>
+										)}
+										{port.proxyProtocol && (
+											<span className="text-xs px-1.5 py-0.5 rounded bg-background">
+												PROXY v{port.proxyProtocol}
+											</span>
+										)}
 									</div>
 									<button
 										type="button"
@@ -210,7 +240,10 @@ export const TCPUDPPorts = memo(function TCPUDPPorts({
 						<Switch
 							id={`${service.id}-tls-passthrough`}
 							checked={tlsPassthrough}
-							onCheckedChange={setTlsPassthrough}
+							onCheckedChange={(checked) => {
+								setTlsPassthrough(checked);
+								if (checked) setTlsTermination(false);
+							}}
 							size="sm"
 						/>
 						<label
@@ -221,6 +254,48 @@ export const TCPUDPPorts = memo(function TCPUDPPorts({
 						</label>
 					</div>
 				)}
+				{protocol === "tcp" && (
+					<div className="flex items-center gap-2 text-sm">
+						<Switch
+							id={`${service.id}-tls-termination`}
+							checked={tlsTermination}
+							onCheckedChange={(checked) => {
+								setTlsTermination(checked);
+								if (checked) setTlsPassthrough(false);
+							}}
+							size="sm"
+						/>
+						<label
+							htmlFor={`${service.id}-tls-termination`}
+							className="text-muted-foreground"
+						>
+							Terminate TLS
+						</label>
+					</div>
+				)}
+				{protocol === "tcp" && (
+					<NativeSelect
+						size="sm"
+						aria-label="PROXY protocol"
+						value={proxyProtocol}
+						onChange={(e) =>
+							setProxyProtocol(e.target.value as "" | "1" | "2")
+						}
+					>
+						<NativeSelectOption value="">No PROXY protocol</NativeSelectOption>
+						<NativeSelectOption value="1">PROXY protocol v1</NativeSelectOption>
+						<NativeSelectOption value="2">PROXY protocol v2</NativeSelectOption>
+					</NativeSelect>
+				)}
+				{terminatesTls && (
+					<Input
+						aria-label="TLS domain"
+						placeholder="db.example.com"
+						value={domain}
+						onChange={(e) => setDomain(e.target.value)}
+						className="w-48"
+					/>
+				)}
 				<Button
 					size="sm"
 					variant="outline"
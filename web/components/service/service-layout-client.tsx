@@ -8,6 +8,7 @@ import type { ServiceWithDetails as Service } from "@/db/types";
 import { fetcher } from "@/lib/fetcher";
 import type { ConfigChange } from "@/lib/service-config";
 import { buildCurrentConfig, diffConfigs } from "@/lib/service-config";
+import { isBuiltServiceSourceType } from "@/lib/service-revision-spec";
 import { cn } from "@/lib/utils";
 
 const ACTIVE_BUILD_STATUSES = [
@@ -96,6 +97,10 @@ export function ServiceLayoutClient({
 			domain: p.domain,
 			protocol: p.protocol,
 			tlsPassthrough: p.tlsPassthrough,
+			tlsTermination: p.tlsTermination,
+			proxyProtocol: p.proxyProtocol,
+			clientAuthMode: p.clientAuthMode,
+			clientCaBundle: p.clientCaBundle,
 		}));
 		const current = buildCurrentConfig(
 			service,
@@ -158,7 +163,7 @@ export function ServiceLayoutClient({
 		...(hasPublicPorts
 			? [{ name: "Requests", href: `${basePath}/requests` }]
 			: []),
-		...(service?.sourceType === "github"
+		...(service && isBuiltServiceSourceType(service.sourceType)
 			? [{ name: "Builds", href: `${basePath}/builds` }]
 			: []),
 		...(service?.stateful
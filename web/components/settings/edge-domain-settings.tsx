@@ -7,6 +7,7 @@ import { toast } from "sonner";
 import {
 	updateAutoSubdomainDomain,
 	updateEdgeDomain,
+	updateTrustedProxies,
 } from "@/actions/settings";
 import { Badge } from "@/components/ui/badge";
 import { Button } from "@/components/ui/button";
@@ -17,6 +18,7 @@ import type { Server } from "@/db/types";
 
 export type EdgeDomainOverview = {
 	hostname: string | null;
+	trustedProxies: string[];
 };
 
 export function EdgeDomainSettings({
@@ -33,7 +35,11 @@ export function EdgeDomainSettings({
 	const [autoSubdomainDomain, setAutoSubdomainDomain] = useState(
 		initialAutoSubdomainDomain ?? "",
 	);
+	const [trustedProxies, setTrustedProxies] = useState(
+		initial.trustedProxies.join(", "),
+	);
 	const [isSaving, setIsSaving] = useState(false);
+	const [isSavingTrustedProxies, setIsSavingTrustedProxies] = useState(false);
 	const [isSavingAutoSubdomain, setIsSavingAutoSubdomain] = useState(false);
 	const proxies = servers.filter((server) => server.isProxy);
 	const ipv4Targets = [
@@ -42,6 +48,8 @@ export function EdgeDomainSettings({
 		),
 	].sort();
 	const hasChanges = domain !== (initial.hostname ?? "");
+	const trustedProxiesHaveChanges =
+		trustedProxies !== initial.trustedProxies.join(", ");
 	const autoSubdomainHasChanges =
 		autoSubdomainDomain !== (initialAutoSubdomainDomain ?? "");
 
@@ -61,6 +69,24 @@ export function EdgeDomainSettings({
 		}
 	};
 
+	const handleSaveTrustedProxies = async () => {
+		setIsSavingTrustedProxies(true);
+		try {
+			const result = await updateTrustedProxies(trustedProxies);
+			setTrustedProxies(result.trustedProxies.join(", "));
+			toast.success("Trusted proxies updated");
+			router.refresh();
+		} catch (error) {
+			toast.error(
+				error instanceof Error
+					? error.message
+					: "Failed to update trusted proxies",
+			);
+		} finally {
+			setIsSavingTrustedProxies(false);
+		}
+	};
+
 	const handleSaveAutoSubdomain = async () => {
 		setIsSavingAutoSubdomain(true);
 		try {
@@ -151,6 +177,32 @@ export function EdgeDomainSettings({
 						</div>
 					</div>
 
+					<div className="space-y-2">
+						<Label htmlFor="trusted-proxies">Trusted load balancers</Label>
+						<Input
+							id="trusted-proxies"
+							value={trustedProxies}
+							onChange={(event) => setTrustedProxies(event.target.value)}
+							placeholder="10.0.0.0/8, 203.0.113.7"
+						/>
+						<p className="text-xs text-muted-foreground mt-1">
+							IP addresses or CIDR ranges of the load balancers in front of
+							your proxies. Their X-Forwarded-For headers are kept, so services
+							see the real client address.
+						</p>
+						{trustedProxiesHaveChanges && (
+							<div className="pt-2">
+								<Button
+									onClick={handleSaveTrustedProxies}
+									disabled={isSavingTrustedProxies}
+									size="sm"
+								>
+									{isSavingTrustedProxies ? "Saving..." : "Save"}
+								</Button>
+							</div>
+						)}
+					</div>
+
 					<div>
 						<Label>Custom domains</Label>
 						<ul className="mt-2 space-y-2 text-sm text-muted-foreground">
@@ -145,6 +145,7 @@ export const getServerDetails = cache(async (id: string) => {
 			containerHealth: servers.containerHealth,
 			agentHealth: servers.agentHealth,
 			crowdsecHealth: servers.crowdsecHealth,
+			certificateIssues: servers.certificateIssues,
 			agentUpgradeTargetVersion: servers.agentUpgradeTargetVersion,
 			agentUpgradeStatus: servers.agentUpgradeStatus,
 			agentUpgradeStartedAt: servers.agentUpgradeStartedAt,
@@ -252,6 +253,7 @@ export async function getGlobalSettings() {
 		buildTimeout,
 		acmeEmail,
 		edgeDomain,
+		trustedProxies,
 		autoSubdomainDomain,
 		emailAlertsConfig,
 		controlPlaneUpdateState,
@@ -261,6 +263,7 @@ export async function getGlobalSettings() {
 		getSetting<number>("build_timeout_minutes"),
 		getSetting<string>("acme_email"),
 		getSetting<string>("edge_domain"),
+		getSetting<string[]>("trusted_proxies"),
 		getSetting<string>("auto_subdomain_domain"),
 		getSetting<EmailAlertsConfig>("email_alerts_config"),
 		getSetting<ControlPlaneUpdateState>("control_plane_update_state"),
@@ -272,6 +275,7 @@ export async function getGlobalSettings() {
 		acmeEmail: acmeEmail ?? null,
 		edgeDomain: {
 			hostname: edgeDomain ?? null,
+			trustedProxies: trustedProxies ?? [],
 		},
 		autoSubdomainDomain: autoSubdomainDomain ?? null,
 		emailAlertsConfig: emailAlertsConfig ?? null,
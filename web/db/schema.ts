@@ -3,6 +3,7 @@ import {
 	bigint,
 	boolean,
 	check,
+	customType,
 	foreignKey,
 	index,
 	integer,
@@ -15,7 +16,12 @@ import {
 	uniqueIndex,
 } from "drizzle-orm/pg-core";
 import type { ServiceRevisionActor } from "@/lib/service-revision-actor";
-import type { ServiceRevisionSpec } from "@/lib/service-revision-spec";
+import type {
+	ProxyProtocolVersion,
+	ServerlessAutoscalingPolicy,
+	ServerlessKeepWarmWindow,
+	ServiceRevisionSpec,
+} from "@/lib/service-revision-spec";
 
 export const user = pgTable("user", {
 	id: text("id").primaryKey(),
@@ -399,6 +405,20 @@ export type CrowdSecHealth = {
 	};
 };
 
+export type CertificateIssue = {
+	domain: string;
+	issue:
+		| "expiring"
+		| "expired"
+		| "domain_mismatch"
+		| "key_mismatch"
+		| "invalid";
+	notAfter?: string;
+	sans?: string[];
+	issuer?: string;
+	error?: string;
+};
+
 export type AgentUpgradeStatus =
 	| "idle"
 	| "queued"
@@ -428,6 +448,7 @@ export const servers = pgTable("servers", {
 	containerHealth: jsonb("container_health").$type<ContainerHealth>(),
 	agentHealth: jsonb("agent_health").$type<AgentHealth>(),
 	crowdsecHealth: jsonb("crowdsec_health").$type<CrowdSecHealth>(),
+	certificateIssues: jsonb("certificate_issues").$type<CertificateIssue[]>(),
 	agentUpgradeTargetVersion: text("agent_upgrade_target_version"),
 	agentUpgradeStatus: text("agent_upgrade_status", {
 		enum: ["idle", "queued", "upgrading", "succeeded", "failed"],
@@ -465,10 +486,16 @@ export const registryCredentials = pgTable(
 	(table) => [uniqueIndex("registry_credentials_host_idx").on(table.host)],
 );
 
+export type ImageSigningPolicy = {
+	publicKeys: string[];
+	identities: Array<{ issuer: string; subject: string }>;
+};
+
 export const projects = pgTable("projects", {
 	id: text("id").primaryKey(),
 	name: text("name").notNull(),
 	slug: text("slug").notNull().unique(),
+	imageSigningPolicy: jsonb("image_signing_policy").$type<ImageSigningPolicy>(),
 	createdAt: timestamp("created_at", { withTimezone: true })
 		.defaultNow()
 		.notNull(),
@@ -507,12 +534,24 @@ export const services = pgTable(
 		name: text("name").notNull(),
 		hostname: text("hostname").unique(),
 		image: text("image").notNull(),
-		sourceType: text("source_type", { enum: ["image", "github"] })
+		sourceType: text("source_type", { enum: ["image", "github", "git"] })
 			.notNull()
 			.default("image"),
 		githubRepoUrl: text("github_repo_url"),
 		githubBranch: text("github_branch").default("main"),
 		githubRootDir: text("github_root_dir"),
+		gitRepoUrl: text("git_repo_url"),
+		gitBranch: text("git_branch"),
+		gitRootDir: text("git_root_dir"),
+		gitSubmodules: boolean("git_submodules").notNull().default(false),
+		gitLfs: boolean("git_lfs").notNull().default(false),
+		buildDockerfile: text("build_dockerfile"),
+		buildTarget: text("build_target"),
+		buildArgs: jsonb("build_args")
+			.$type<Record<string, string>>()
+			.notNull()
+			.default(sql`'{}'::jsonb`),
+		vulnerabilityMaxCritical: integer("vulnerability_max_critical"),
 		replicas: integer("replicas").notNull().default(1),
 		autoscalingEnabled: boolean("autoscaling_enabled").notNull().default(false),
 		autoscalingMinReplicas: integer("autoscaling_min_replicas")
@@ -544,6 +583,7 @@ export const services = pgTable(
 		healthCheckRetries: integer("health_check_retries").default(3),
 		healthCheckStartPeriod: integer("health_check_start_period").default(30),
 		startCommand: text("start_command"),
+		releaseCommand: text("release_command"),
 		resourceCpuLimit: real("resource_cpu_limit"),
 		resourceMemoryLimitMb: integer("resource_memory_limit_mb"),
 		serverlessEnabled: boolean("serverless_enabled").notNull().default(false),
@@ -553,6 +593,23 @@ export const services = pgTable(
 		serverlessWakeTimeoutSeconds: integer("serverless_wake_timeout_seconds")
 			.notNull()
 			.default(300),
+		serverlessAutoscaling: jsonb(
+			"serverless_autoscaling",
+		).$type<ServerlessAutoscalingPolicy>(),
+		serverlessMaxQueuedRequests: integer("serverless_max_queued_requests"),
+		serverlessMaxQueueWaitSeconds: integer(
+			"serverless_max_queue_wait_seconds",
+		),
+		serverlessKeepWarm: jsonb(
+			"serverless_keep_warm",
+		).$type<ServerlessKeepWarmWindow[]>(),
+		serverlessStreamIdleTimeoutSeconds: integer(
+			"serverless_stream_idle_timeout_seconds",
+		),
+		serverlessMaxConnectionLifetimeSeconds: integer(
+			"serverless_max_connection_lifetime_seconds",
+		),
+		serverlessEventPath: text("serverless_event_path"),
 		deploymentSchedule: text("deployment_schedule"),
 		lastScheduledDeploymentRunAt: timestamp(
 			"last_scheduled_deployment_run_at",
@@ -654,6 +711,17 @@ export const serviceCrons = pgTable(
 		lastStatusCode: integer("last_status_code"),
 		lastDurationMs: integer("last_duration_ms"),
 		lastError: text("last_error"),
+		timeZone: text("time_zone").notNull().default("UTC"),
+		timeoutSeconds: integer("timeout_seconds").notNull().default(10),
+		retries: integer("retries").notNull().default(0),
+		retryBackoffSeconds: integer("retry_backoff_seconds")
+			.notNull()
+			.default(10),
+		concurrencyPolicy: text("concurrency_policy", {
+			enum: ["allow", "forbid", "replace"],
+		})
+			.notNull()
+			.default("forbid"),
 		createdAt: timestamp("created_at", { withTimezone: true })
 			.defaultNow()
 			.notNull(),
@@ -667,6 +735,37 @@ export const serviceCrons = pgTable(
 	],
 );
 
+export const serviceCronRuns = pgTable(
+	"service_cron_runs",
+	{
+		id: text("id").primaryKey(),
+		cronId: text("cron_id")
+			.notNull()
+			.references(() => serviceCrons.id, { onDelete: "cascade" }),
+		serviceId: text("service_id")
+			.notNull()
+			.references(() => services.id, { onDelete: "cascade" }),
+		source: text("source", { enum: ["scheduled", "manual"] }).notNull(),
+		scheduledFor: timestamp("scheduled_for", { withTimezone: true }).notNull(),
+		attempt: integer("attempt").notNull().default(1),
+		status: text("status", {
+			enum: ["running", "succeeded", "failed", "skipped", "cancelled"],
+		}).notNull(),
+		statusCode: integer("status_code"),
+		durationMs: integer("duration_ms"),
+		responseSnippet: text("response_snippet"),
+		error: text("error"),
+		startedAt: timestamp("started_at", { withTimezone: true }).notNull(),
+		finishedAt: timestamp("finished_at", { withTimezone: true }),
+	},
+	(table) => [
+		index("service_cron_runs_cron_started_idx").on(
+			table.cronId,
+			table.startedAt,
+		),
+	],
+);
+
 export const serviceReplicas = pgTable(
 	"service_replicas",
 	{
@@ -697,6 +796,10 @@ export const servicePorts = pgTable(
 			.default("http"),
 		externalPort: integer("external_port"),
 		tlsPassthrough: boolean("tls_passthrough").notNull().default(false),
+		tlsTermination: boolean("tls_termination").notNull().default(false),
+		proxyProtocol: integer("proxy_protocol").$type<ProxyProtocolVersion>(),
+		clientAuthMode: text("client_auth_mode", { enum: ["require", "optional"] }),
+		clientCaBundle: text("client_ca_bundle"),
 		createdAt: timestamp("created_at", { withTimezone: true })
 			.defaultNow()
 			.notNull(),
@@ -914,6 +1017,7 @@ export const rollouts = pgTable(
 			.$type<string[]>()
 			.notNull()
 			.default(sql`'[]'::jsonb`),
+		vulnerabilities: jsonb("vulnerabilities").$type<VulnerabilitySummary>(),
 		createdAt: timestamp("created_at", { withTimezone: true })
 			.defaultNow()
 			.notNull(),
@@ -976,6 +1080,8 @@ export const workQueue = pgTable(
 				"upgrade_agent",
 				"sync_registries",
 				"command",
+				"run",
+				"scan_image",
 			],
 		}).notNull(),
 		payload: text("payload").notNull(),
@@ -1047,6 +1153,47 @@ export const serviceCommands = pgTable(
 	],
 );
 
+export const serviceRuns = pgTable(
+	"service_runs",
+	{
+		id: text("id").primaryKey(),
+		serviceId: text("service_id")
+			.notNull()
+			.references(() => services.id, { onDelete: "cascade" }),
+		deploymentId: text("deployment_id"),
+		rolloutId: text("rollout_id"),
+		serverId: text("server_id").notNull(),
+		serverName: text("server_name").notNull(),
+		ipAddress: text("ip_address"),
+		actor: jsonb("actor").$type<{ id: string; name: string }>(),
+		command: text("command").notNull(),
+		timeoutSeconds: integer("timeout_seconds").notNull(),
+		status: text("status", {
+			enum: ["pending", "running", "succeeded", "failed", "timed_out"],
+		})
+			.notNull()
+			.default("pending"),
+		exitCode: integer("exit_code"),
+		errorMessage: text("error_message"),
+		createdAt: timestamp("created_at", { withTimezone: true })
+			.defaultNow()
+			.notNull(),
+		startedAt: timestamp("started_at", { withTimezone: true }),
+		completedAt: timestamp("completed_at", { withTimezone: true }),
+	},
+	(table) => [
+		index("service_runs_service_created_id_idx").on(
+			table.serviceId,
+			table.createdAt,
+			table.id,
+		),
+		index("service_runs_server_status_idx").on(table.serverId, table.status),
+		uniqueIndex("service_runs_rollout_id_unique_idx")
+			.on(table.rolloutId)
+			.where(sql`${table.rolloutId} is not null`),
+	],
+);
+
 export const githubInstallations = pgTable(
 	"github_installations",
 	{
@@ -1093,6 +1240,22 @@ export const githubRepos = pgTable(
 	],
 );
 
+export type VulnerabilitySummary = {
+	critical: number;
+	high: number;
+	medium: number;
+	low: number;
+	unknown: number;
+};
+
+export type BuildAttestation = {
+	platform: string;
+	imageDigest: string;
+	manifestDigest: string;
+	sbomDigest: string | null;
+	provenanceDigest: string | null;
+};
+
 export const builds = pgTable(
 	"builds",
 	{
@@ -1124,6 +1287,11 @@ export const builds = pgTable(
 		githubDeploymentId: bigint("github_deployment_id", { mode: "number" }),
 		targetPlatform: text("target_platform").notNull(),
 		buildGroupId: text("build_group_id").notNull(),
+		cacheHits: integer("cache_hits"),
+		cacheSteps: integer("cache_steps"),
+		attestations: jsonb("attestations").$type<BuildAttestation[]>(),
+		vulnerabilities: jsonb("vulnerabilities").$type<VulnerabilitySummary>(),
+		rolloutBlockedReason: text("rollout_blocked_reason"),
 		claimedBy: text("claimed_by").references(() => servers.id, {
 			onDelete: "set null",
 		}),
@@ -1147,6 +1315,51 @@ export const builds = pgTable(
 	],
 );
 
+const bytea = customType<{ data: Buffer; driverData: Buffer }>({
+	dataType() {
+		return "bytea";
+	},
+});
+
+export const buildContexts = pgTable(
+	"build_contexts",
+	{
+		id: text("id").primaryKey(),
+		serviceId: text("service_id")
+			.notNull()
+			.references(() => services.id, { onDelete: "cascade" }),
+		checksum: text("checksum").notNull(),
+		sizeBytes: integer("size_bytes").notNull(),
+		data: bytea("data").notNull(),
+		createdAt: timestamp("created_at", { withTimezone: true })
+			.defaultNow()
+			.notNull(),
+	},
+	(table) => [
+		index("build_contexts_service_created_at_idx").on(
+			table.serviceId,
+			table.createdAt,
+		),
+	],
+);
+
+export const serviceGitCredentials = pgTable("service_git_credentials", {
+	serviceId: text("service_id")
+		.primaryKey()
+		.references(() => services.id, { onDelete: "cascade" }),
+	type: text("type", { enum: ["https_token", "ssh_key"] }).notNull(),
+	username: text("username"),
+	encryptedSecret: text("encrypted_secret").notNull(),
+	knownHosts: text("known_hosts"),
+	createdAt: timestamp("created_at", { withTimezone: true })
+		.defaultNow()
+		.notNull(),
+	updatedAt: timestamp("updated_at", { withTimezone: true })
+		.defaultNow()
+		.$onUpdate(() => new Date())
+		.notNull(),
+});
+
 export const settings = pgTable("settings", {
 	key: text("key").primaryKey(),
 	value: jsonb("value").notNull(),
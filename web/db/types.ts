@@ -13,7 +13,9 @@ import type {
 	servicePorts,
 	serviceReplicas,
 	serviceCommands,
+	serviceCronRuns,
 	serviceCrons,
+	serviceRuns,
 	services,
 	serviceVolumes,
 	user,
@@ -29,6 +31,8 @@ export type ServiceVolume = typeof serviceVolumes.$inferSelect;
 export type ServiceReplica = typeof serviceReplicas.$inferSelect;
 export type ServiceCommand = typeof serviceCommands.$inferSelect;
 export type ServiceCron = typeof serviceCrons.$inferSelect;
+export type ServiceCronRun = typeof serviceCronRuns.$inferSelect;
+export type ServiceRun = typeof serviceRuns.$inferSelect;
 export type Secret = typeof secrets.$inferSelect;
 export type Deployment = typeof deployments.$inferSelect;
 export type DeploymentPort = typeof deploymentPorts.$inferSelect;
@@ -0,0 +1,143 @@
+import { createPrivateKey, X509Certificate } from "node:crypto";
+import { and, eq, lt } from "drizzle-orm";
+import { z } from "zod";
+import { db } from "@/db";
+import {
+	type CertificateIssue,
+	domainCertificates,
+	servers,
+} from "@/db/schema";
+import { decryptSecret } from "@/lib/crypto";
+
+const MAX_REPORTED_CERTIFICATES = 200;
+
+const issuedCertificateSchema = z.object({
+	domain: z.string().min(1).max(253),
+	certificate: z.string().min(1).max(64 * 1024),
+	encryptedCertificateKey: z.string().min(1).max(16 * 1024),
+});
+
+const certificateIssueSchema = z.object({
+	domain: z.string().min(1).max(253),
+	issue: z.enum([
+		"expiring",
+		"expired",
+		"domain_mismatch",
+		"key_mismatch",
+		"invalid",
+	]),
+	notAfter: z.string().max(64).optional(),
+	sans: z.array(z.string().max(253)).max(100).optional(),
+	issuer: z.string().max(512).optional(),
+	error: z.string().max(1000).optional(),
+});
+
+/**
+ * Reads the certificate problems a proxy reports. Returns null when the
+ * report carries none at all (non-proxy servers, older agents), so stored
+ * issues are only replaced by an actual list.
+ */
+export function parseCertificateIssues(
+	value: unknown,
+): CertificateIssue[] | null {
+	if (!Array.isArray(value)) return null;
+	return value.slice(0, MAX_REPORTED_CERTIFICATES).flatMap((item) => {
+		const parsed = certificateIssueSchema.safeParse(item);
+		return parsed.success ? [parsed.data] : [];
+	});
+}
+
+export type IssuedCertificateReport = z.infer<typeof issuedCertificateSchema>;
+
+export type VerifiedCertificate = {
+	domain: string;
+	certificate: string;
+	certificateKey: string;
+	expiresAt: Date;
+};
+
+/**
+ * Checks a certificate a proxy issued for itself while the control plane was
+ * unreachable: it must cover the domain, match its key and still be valid.
+ */
+export function verifyIssuedCertificate(
+	report: IssuedCertificateReport,
+	certificateKey: string,
+	now = new Date(),
+): VerifiedCertificate {
+	const certificate = new X509Certificate(report.certificate);
+	if (!certificate.checkHost(report.domain)) {
+		throw new Error(`certificate does not cover ${report.domain}`);
+	}
+	if (!certificate.checkPrivateKey(createPrivateKey(certificateKey))) {
+		throw new Error("certificate key does not match the certificate");
+	}
+	const expiresAt = new Date(certificate.validTo);
+	if (Number.isNaN(expiresAt.getTime()) || expiresAt <= now) {
+		throw new Error("certificate has expired");
+	}
+	return {
+		domain: report.domain,
+		certificate: report.certificate,
+		certificateKey,
+		expiresAt,
+	};
+}
+
+/**
+ * Adopts certificates a proxy renewed on its own. Only domains the control
+ * plane already manages are updated, and only with a later expiry, so a
+ * proxy can never introduce or downgrade a certificate.
+ */
+export async function applyIssuedCertificates(
+	serverId: string,
+	reports: unknown,
+) {
+	if (!Array.isArray(reports) || reports.length === 0) return 0;
+
+	const [server] = await db
+		.select({ isProxy: servers.isProxy })
+		.from(servers)
+		.where(eq(servers.id, serverId))
+		.limit(1);
+	if (!server?.isProxy) return 0;
+
+	let adopted = 0;
+	for (const value of reports.slice(0, MAX_REPORTED_CERTIFICATES)) {
+		const parsed = issuedCertificateSchema.safeParse(value);
+		if (!parsed.success) continue;
+		try {
+			const verified = verifyIssuedCertificate(
+				parsed.data,
+				await decryptSecret(parsed.data.encryptedCertificateKey),
+			);
+			const updated = await db
+				.update(domainCertificates)
+				.set({
+					certificate: verified.certificate,
+					certificateKey: verified.certificateKey,
+					expiresAt: verified.expiresAt,
+					issuedAt: new Date(),
+				})
+				.where(
+					and(
+						eq(domainCertificates.domain, verified.domain),
+						lt(domainCertificates.expiresAt, verified.expiresAt),
+					),
+				)
+				.returning({ id: domainCertificates.id });
+			if (updated.length > 0) {
+				adopted++;
+				console.log(
+					`[acme] adopted certificate for ${verified.domain} issued by server ${serverId}`,
+				);
+			}
+		} catch (error) {
+			console.error(
+				`[acme] rejected certificate for ${parsed.data.domain} from server ${serverId}:`,
+				error,
+			);
+		}
+	}
+	return adopted;
+}
@@ -12,6 +12,10 @@ import {
 	services,
 	workQueue,
 } from "@/db/schema";
+import {
+	applyIssuedCertificates,
+	parseCertificateIssues,
+} from "@/lib/agent-certificates";
 import {
 	AUTOHEAL_MAX_RECREATES,
 	AUTOHEAL_MAX_RESTARTS,
@@ -30,9 +34,13 @@ import {
 } from "@/lib/deployment-status";
 import { inngest } from "@/lib/inngest/client";
 import { inngestEvents } from "@/lib/inngest/events";
+import { sendRolloutCreated } from "@/lib/rollout-enqueue";
 import { isRoutingSyncAcknowledgementEligible } from "@/lib/routing-sync";
 import { getServerlessWakeFailureUpdate } from "@/lib/serverless-wake-failures";
 import type { ServiceRevisionSpec } from "@/lib/service-revision-spec";
+import {
+	cloneActiveRevisionForServerlessScaling,
+} from "@/lib/service-revisions";
 import { ingestRolloutLog } from "@/lib/victoria-logs";
 import {
 	enqueueReconcileForAllOnlineServers,
@@ -52,15 +60,28 @@ type DeploymentError = {
 	message: string;
 };
 
-export type ServerlessTransition =
+type DeploymentServerlessTransition =
 	| { id?: string; type: "sleep"; deploymentId: string; containerId: string }
 	| { id?: string; type: "wake_started"; deploymentId: string }
 	| { id?: string; type: "wake_failed"; deploymentId: string; error: string };
 
+type ServerlessScaleTransition = {
+	id?: string;
+	type: "scale_up" | "scale_down";
+	serviceId: string;
+	replicas: number;
+	activeRequests?: number;
+};
+
+export type ServerlessTransition =
+	| DeploymentServerlessTransition
+	| ServerlessScaleTransition;
+
 export type ServerlessTransitionResult = {
 	id?: string;
 	type?: ServerlessTransition["type"];
 	deploymentId?: string;
+	serviceId?: string;
 	outcome: "applied" | "already_applied" | "rejected";
 	reason?: string;
 };
@@ -280,6 +301,12 @@ async function applyServerlessTransitions(
 			});
 			continue;
 		}
+		if (isServerlessScaleTransition(transitionValue)) {
+			results.push(
+				await applyServerlessScaleTransition(serverId, transitionValue),
+			);
+			continue;
+		}
 		const transition = transitionValue;
 
 		const validResultBase = {
@@ -476,6 +503,60 @@ async function applyServerlessTransitions(
 	return results;
 }
 
+async function applyServerlessScaleTransition(
+	serverId: string,
+	transition: ServerlessScaleTransition,
+): Promise<ServerlessTransitionResult> {
+	const resultBase = {
+		id: transition.id,
+		type: transition.type,
+		serviceId: transition.serviceId,
+	};
+	const server = await db
+		.select({ isProxy: servers.isProxy })
+		.from(servers)
+		.where(eq(servers.id, serverId))
+		.then((rows) => rows[0]);
+	if (!server?.isProxy) {
+		console.log(
+			`[serverless:status] rejected ${transition.type} for service ${transition.serviceId}: server is not a proxy`,
+		);
+		return {
+			...resultBase,
+			outcome: "rejected",
+			reason: "server is not a proxy",
+		};
+	}
+
+	const result = await cloneActiveRevisionForServerlessScaling({
+		serviceId: transition.serviceId,
+		direction: transition.type === "scale_up" ? "up" : "down",
+		targetReplicas: transition.replicas,
+	});
+	if (!result.created) {
+		const outcome =
+			result.reason === "unchanged" ? "already_applied" : "rejected";
+		console.log(
+			`[serverless:status] ${outcome} ${transition.type} for service ${transition.serviceId}: ${result.reason}`,
+		);
+		return { ...resultBase, outcome, reason: result.reason };
+	}
+
+	console.log(
+		`[serverless:status] service ${transition.serviceId} scaling to ${result.targetReplicas} replicas for ${transition.activeRequests ?? 0} active requests`,
+	);
+	try {
+		await sendRolloutCreated(result.rolloutId, transition.serviceId);
+	} catch (error) {
+		console.error(
+			`[serverless:status] failed to enqueue rollout ${result.rolloutId}:`,
+			error,
+		);
+		return { ...resultBase, outcome: "rejected", reason: "enqueue failed" };
+	}
+	return { ...resultBase, outcome: "applied" };
+}
+
 function getServerlessTransitionResultBase(
 	transition: unknown,
 ): Omit<ServerlessTransitionResult, "outcome" | "reason"> {
@@ -489,9 +570,13 @@ function getServerlessTransitionResultBase(
 			? candidate.type
 			: undefined,
 		deploymentId:
-			typeof candidate.deploymentId === "string"
+			typeof candidate.deploymentId === "string" && candidate.deploymentId
 				? candidate.deploymentId
 				: undefined,
+		serviceId:
+			typeof candidate.serviceId === "string" && candidate.serviceId
+				? candidate.serviceId
+				: undefined,
 	};
 }
 
@@ -499,23 +584,34 @@ function isServerlessTransitionType(
 	value: unknown,
 ): value is ServerlessTransition["type"] {
 	return (
-		value === "sleep" || value === "wake_started" || value === "wake_failed"
+		value === "sleep" ||
+		value === "wake_started" ||
+		value === "wake_failed" ||
+		value === "scale_up" ||
+		value === "scale_down"
 	);
 }
 
+function isServerlessScaleTransition(
+	transition: ServerlessTransition,
+): transition is ServerlessScaleTransition {
+	return transition.type === "scale_up" || transition.type === "scale_down";
+}
+
 export function getSleepTransitionDeploymentIds(
 	transitions: unknown[],
 ): Set<string> {
 	return new Set(
 		transitions
 			.filter(isValidServerlessTransition)
-			.filter((transition) => transition.type === "sleep")
-			.map((transition) => transition.deploymentId),
+			.flatMap((transition) =>
+				transition.type === "sleep" ? [transition.deploymentId] : [],
+			),
 	);
 }
 
 function isAlreadyAppliedServerlessTransition(
-	transition: ServerlessTransition,
+	transition: DeploymentServerlessTransition,
 	deployment:
 		| {
 				runtimeDesiredState: string;
@@ -549,6 +645,15 @@ function isValidServerlessTransition(
 ): transition is ServerlessTransition {
 	if (!transition || typeof transition !== "object") return false;
 	const candidate = transition as ServerlessTransition;
+	if (isServerlessScaleTransition(candidate)) {
+		return (
+			typeof candidate.serviceId === "string" &&
+			!!candidate.serviceId &&
+			Number.isInteger(candidate.replicas) &&
+			candidate.replicas >= 1 &&
+			candidate.replicas <= 32
+		);
+	}
 	if (typeof candidate.deploymentId !== "string" || !candidate.deploymentId) {
 		return false;
 	}
@@ -570,7 +675,7 @@ function getInvalidServerlessTransitionReason({
 	deployment,
 }: {
 	serverId: string;
-	transition: ServerlessTransition;
+	transition: DeploymentServerlessTransition;
 	deployment:
 		| {
 				serverId: string;
@@ -657,6 +762,8 @@ export type StatusReport = {
 	agentHealth?: AgentHealth;
 	crowdsecHealth?: CrowdSecHealth;
 	deploymentErrors?: DeploymentError[];
+	issuedCertificates?: unknown[];
+	certificateIssues?: unknown[] | null;
 };
 
 export async function applyStatusReport(
@@ -701,6 +808,10 @@ export async function applyStatusReport(
 	if (report.crowdsecHealth) {
 		updateData.crowdsecHealth = report.crowdsecHealth;
 	}
+	const certificateIssues = parseCertificateIssues(report.certificateIssues);
+	if (certificateIssues) {
+		updateData.certificateIssues = certificateIssues;
+	}
 	if (report.agentHealth) {
 		updateData.agentHealth = report.agentHealth;
 
@@ -738,6 +849,8 @@ export async function applyStatusReport(
 			);
 	}
 
+	await applyIssuedCertificates(serverId, report.issuedCertificates);
+
 	let serverLogName: string | undefined;
 	const getCurrentServerLogName = async () => {
 		serverLogName ??= await getServerLogName(serverId);
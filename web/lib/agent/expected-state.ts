@@ -1,8 +1,11 @@
 import { and, eq, inArray, isNull } from "drizzle-orm";
 import { db } from "@/db";
+import { getSetting } from "@/db/queries";
 import {
 	deploymentPorts,
 	deployments,
+	type ImageSigningPolicy,
+	projects,
 	rollouts,
 	servers,
 	serviceRevisions,
@@ -15,14 +18,20 @@ import {
 	observedReadyPhases,
 	runtimeExpectedStates,
 } from "@/lib/deployment-status";
+import { parseImageSigningPolicy } from "@/lib/image-signing-policy";
 import { normalizeImageReference } from "@/lib/registry-reference";
 import { selectRoutingSyncRolloutIds } from "@/lib/routing-sync";
 import { parseServiceRevisionSpec } from "@/lib/service-revision-changes";
 import {
 	getPublishedContainerPorts,
+	type ProxyProtocolVersion,
+	type ServerlessAutoscalingPolicy,
+	type ServerlessKeepWarmWindow,
+	type ServiceRevisionClientAuth,
 	type ServiceRevisionSecret,
 	type ServiceRevisionSpec,
 } from "@/lib/service-revision-spec";
+import { SETTING_KEYS } from "@/lib/settings-keys";
 import { getWireGuardPeers } from "@/lib/wireguard";
 
 type Server = typeof servers.$inferSelect;
@@ -30,6 +39,11 @@ type Service = typeof services.$inferSelect;
 type Deployment = typeof deployments.$inferSelect;
 type ServiceRevision = typeof serviceRevisions.$inferSelect;
 const SERVERLESS_GATEWAY_PORT = 18080;
+// Public TCP ports of serverless services are routed through a loopback
+// listener on the proxy agent, which wakes the service before piping the
+// connection. External TCP ports come from 10000-10999, so the listener ports
+// cannot collide.
+const SERVERLESS_TCP_TRIGGER_PORT_OFFSET = 10_000;
 
 type RouteServicePort = {
 	id: string;
@@ -40,6 +54,9 @@ type RouteServicePort = {
 	protocol: "http" | "tcp" | "udp";
 	externalPort: number | null;
 	tlsPassthrough: boolean;
+	tlsTermination?: boolean;
+	proxyProtocol?: ProxyProtocolVersion;
+	clientAuth?: ServiceRevisionClientAuth;
 };
 
 export type RuntimeServiceRevision = {
@@ -71,6 +88,12 @@ export type ExpectedContainer = {
 	volumes: Array<{ name: string; containerPath: string }>;
 	resourceCpuLimit: number | null;
 	resourceMemoryLimitMb: number | null;
+	signingPolicy: ImageSigningPolicy | null;
+};
+
+type ClientAuth = {
+	caBundle: string;
+	mode: "RequireAndVerifyClientCert" | "VerifyClientCertIfGiven";
 };
 
 type HttpRoute = {
@@ -78,6 +101,7 @@ type HttpRoute = {
 	domain: string;
 	upstreams: Array<{ url: string; weight: number }>;
 	serviceId: string;
+	clientAuth?: ClientAuth;
 };
 
 type TcpRoute = {
@@ -86,6 +110,10 @@ type TcpRoute = {
 	upstreams: string[];
 	externalPort: number;
 	tlsPassthrough: boolean;
+	tlsTermination?: boolean;
+	domain?: string;
+	clientAuth?: ClientAuth;
+	proxyProtocol?: ProxyProtocolVersion;
 };
 
 type UdpRoute = {
@@ -111,6 +139,17 @@ export type ServerlessRoute = {
 	wakeTimeoutSeconds: number;
 	localDeploymentIds: string[];
 	upstreams: ServerlessRouteUpstream[];
+	autoscaling?: ServerlessAutoscalingPolicy;
+	maxQueuedRequests?: number;
+	maxQueueWaitSeconds?: number;
+	remoteDeployments?: { deploymentId: string; serverId: string }[];
+	keepWarm?: ServerlessKeepWarmWindow[];
+	streamIdleTimeoutSeconds?: number;
+	maxConnectionLifetimeSeconds?: number;
+	triggers?: {
+		tcp?: { listenPort: number; port: number }[];
+		eventPath?: string;
+	};
 };
 
 export type AgentExpectedState = {
@@ -125,6 +164,7 @@ export type AgentExpectedState = {
 		udpRoutes: UdpRoute[];
 		certificates?: Awaited<ReturnType<typeof getAllCertificatesForDomains>>;
 		challengeRoute?: { controlPlaneUrl: string };
+		trustedProxies?: string[];
 	};
 	wireguard: { peers: Awaited<ReturnType<typeof getWireGuardPeers>> };
 };
@@ -290,12 +330,26 @@ async function buildExpectedContainers(
 			.where(inArray(serviceRevisions.id, revisionIds)),
 		fetchDeploymentPorts(serverDeployments.map((dep) => dep.id)),
 	]);
+	const projectIds = [
+		...new Set(activeServices.map((service) => service.projectId)),
+	];
+	const projectRows =
+		projectIds.length === 0
+			? []
+			: await db
+					.select({
+						id: projects.id,
+						imageSigningPolicy: projects.imageSigningPolicy,
+					})
+					.from(projects)
+					.where(inArray(projects.id, projectIds));
 
 	return buildExpectedContainersFromRows({
 		deployments: serverDeployments,
 		services: activeServices,
 		revisions,
 		deploymentPorts: depPorts,
+		projects: projectRows,
 	});
 }
 
@@ -322,15 +376,23 @@ export function buildExpectedContainersFromRows({
 	services: serviceRows,
 	revisions: revisionRows,
 	deploymentPorts: deploymentPortRows,
+	projects: projectRows = [],
 }: {
 	deployments: Deployment[];
 	services: Service[];
 	revisions: ServiceRevision[];
 	deploymentPorts: DeploymentPortRow[];
+	projects?: Array<{ id: string; imageSigningPolicy: unknown }>;
 }): ExpectedContainer[] {
 	const servicesById = new Map(
 		serviceRows.map((service) => [service.id, service]),
 	);
+	const signingPoliciesByProjectId = new Map(
+		projectRows.map((project) => [
+			project.id,
+			parseImageSigningPolicy(project.imageSigningPolicy),
+		]),
+	);
 	const portsByDeploymentId = Map.groupBy(
 		deploymentPortRows,
 		(port) => port.deploymentId,
@@ -378,13 +440,7 @@ export function buildExpectedContainersFromRows({
 				throw new Error(`Deployment ${dep.id} has incomplete port allocation`);
 			}
 			const env = buildEnv(specification.secrets);
-			const volumes = specification.volumes
-				.slice()
-				.sort((a, b) => a.containerPath.localeCompare(b.containerPath))
-				.map((volume) => ({
-					name: volume.name,
-					containerPath: volume.containerPath,
-				}));
+			const volumes = buildVolumes(specification);
 			return [
 				{
 					deploymentId: dep.id,
@@ -403,11 +459,50 @@ export function buildExpectedContainersFromRows({
 					volumes,
 					resourceCpuLimit: specification.resourceLimits.cpuCores,
 					resourceMemoryLimitMb: specification.resourceLimits.memoryMb,
+					signingPolicy:
+						signingPoliciesByProjectId.get(service.projectId) ?? null,
 				},
 			];
 		});
 }
 
+export function buildTaskContainer({
+	taskId,
+	serviceId,
+	serviceName,
+	deploymentId,
+	ipAddress,
+	specification,
+	signingPolicy,
+}: {
+	taskId: string;
+	serviceId: string;
+	serviceName: string;
+	deploymentId: string | null;
+	ipAddress: string | null;
+	specification: ServiceRevisionSpec;
+	signingPolicy: ImageSigningPolicy | null;
+}): ExpectedContainer {
+	return {
+		deploymentId: deploymentId ?? "",
+		serviceId,
+		serviceName,
+		name: `${serviceId}-task-${taskId.slice(0, 8)}`,
+		desiredState: "running",
+		image: normalizeImage(specification.image),
+		ipAddress,
+		ports: [],
+		publishLocalPorts: false,
+		env: buildEnv(specification.secrets),
+		startCommand: null,
+		healthCheck: null,
+		volumes: buildVolumes(specification),
+		resourceCpuLimit: specification.resourceLimits.cpuCores,
+		resourceMemoryLimitMb: specification.resourceLimits.memoryMb,
+		signingPolicy,
+	};
+}
+
 async function buildServerlessExpectedState(
 	server: Server,
 	allServices: RuntimeServiceRevision[],
@@ -505,6 +600,20 @@ export function buildServerlessRoutesFromRows({
 				)
 				.map((deployment) => deployment.id)
 				.sort();
+			// Sleeping replicas on other proxies, which this gateway can wake
+			// directly through their agents.
+			const remoteDeployments = serviceDeployments
+				.filter(
+					(deployment) =>
+						deployment.serverId !== serverId &&
+						deployment.serverIsProxy &&
+						deployment.trafficState === "active",
+				)
+				.map((deployment) => ({
+					deploymentId: deployment.id,
+					serverId: deployment.serverId,
+				}))
+				.sort((a, b) => a.deploymentId.localeCompare(b.deploymentId));
 
 			const upstreams = serviceDeployments
 				.filter(
@@ -522,16 +631,50 @@ export function buildServerlessRoutesFromRows({
 				}))
 				.sort(compareServerlessUpstreams);
 
+			const { serverless } = service.specification;
+			const tcpTriggers = (portsByServiceId.get(service.id) ?? [])
+				.slice()
+				.sort(compareServicePorts)
+				.flatMap((servicePort) => {
+					const listenPort = serverlessTcpTriggerPort(servicePort);
+					return listenPort ? [{ listenPort, port: servicePort.port }] : [];
+				});
+			const triggers = {
+				...(tcpTriggers.length > 0 ? { tcp: tcpTriggers } : {}),
+				...(serverless.eventPath ? { eventPath: serverless.eventPath } : {}),
+			};
 			return [
 				{
 					serviceId: service.id,
 					domain: port.domain,
 					port: port.port,
-					sleepAfterSeconds: service.specification.serverless.sleepAfterSeconds,
-					wakeTimeoutSeconds:
-						service.specification.serverless.wakeTimeoutSeconds,
+					sleepAfterSeconds: serverless.sleepAfterSeconds,
+					wakeTimeoutSeconds: serverless.wakeTimeoutSeconds,
 					localDeploymentIds,
 					upstreams,
+					...(serverless.autoscaling
+						? { autoscaling: serverless.autoscaling }
+						: {}),
+					...(serverless.maxQueuedRequests
+						? { maxQueuedRequests: serverless.maxQueuedRequests }
+						: {}),
+					...(serverless.maxQueueWaitSeconds
+						? { maxQueueWaitSeconds: serverless.maxQueueWaitSeconds }
+						: {}),
+					...(remoteDeployments.length > 0 ? { remoteDeployments } : {}),
+					...(serverless.keepWarm?.length
+						? { keepWarm: serverless.keepWarm }
+						: {}),
+					...(serverless.streamIdleTimeoutSeconds
+						? { streamIdleTimeoutSeconds: serverless.streamIdleTimeoutSeconds }
+						: {}),
+					...(serverless.maxConnectionLifetimeSeconds
+						? {
+								maxConnectionLifetimeSeconds:
+									serverless.maxConnectionLifetimeSeconds,
+							}
+						: {}),
+					...(Object.keys(triggers).length > 0 ? { triggers } : {}),
 				},
 			];
 		});
@@ -636,7 +779,10 @@ async function buildTraefikConfig(
 		serverlessRouteSuppressedServiceIds,
 	});
 	const certificateDomains = buildTraefikCertificateDomains(routePorts);
-	const certificates = await getAllCertificatesForDomains(certificateDomains);
+	const [certificates, trustedProxies] = await Promise.all([
+		getAllCertificatesForDomains(certificateDomains),
+		getSetting<string[]>(SETTING_KEYS.TRUSTED_PROXIES),
+	]);
 	const controlPlaneUrl = process.env.APP_URL;
 
 	if (!controlPlaneUrl) {
@@ -649,6 +795,7 @@ async function buildTraefikConfig(
 		...routes,
 		certificates,
 		challengeRoute: controlPlaneUrl ? { controlPlaneUrl } : undefined,
+		...(trustedProxies?.length ? { trustedProxies } : {}),
 	};
 }
 
@@ -685,6 +832,7 @@ export function buildTraefikRoutes({
 						{ url: `127.0.0.1:${SERVERLESS_GATEWAY_PORT}`, weight: 1 },
 					],
 					serviceId: port.serviceId,
+					...routeClientAuth(port),
 				});
 				continue;
 			}
@@ -721,10 +869,16 @@ export function buildTraefikRoutes({
 					domain: port.domain,
 					upstreams,
 					serviceId: port.serviceId,
+					...routeClientAuth(port),
 				});
 			}
 		} else if (port.isPublic && port.protocol === "tcp" && port.externalPort) {
-			const upstreams = upstreamUrls(serviceDeployments, port.port);
+			const triggerPort = serverlessServiceIds.has(port.serviceId)
+				? serverlessTcpTriggerPort(port)
+				: null;
+			const upstreams = triggerPort
+				? [`127.0.0.1:${triggerPort}`]
+				: upstreamUrls(serviceDeployments, port.port);
 
 			if (upstreams.length > 0) {
 				tcpRoutes.push({
@@ -733,6 +887,16 @@ export function buildTraefikRoutes({
 					upstreams,
 					externalPort: port.externalPort,
 					tlsPassthrough: port.tlsPassthrough,
+					...(port.tlsTermination && port.domain
+						? {
+								tlsTermination: true,
+								domain: port.domain,
+								...routeClientAuth(port),
+							}
+						: {}),
+					...(port.proxyProtocol
+						? { proxyProtocol: port.proxyProtocol }
+						: {}),
 				});
 			}
 		} else if (port.isPublic && port.protocol === "udp" && port.externalPort) {
@@ -796,7 +960,12 @@ export function buildTraefikCertificateDomains(ports: RouteServicePort[]) {
 	return Array.from(
 		new Set(
 			ports
-				.filter((port) => port.isPublic && port.protocol === "http")
+				.filter(
+					(port) =>
+						port.isPublic &&
+						(port.protocol === "http" ||
+							(port.protocol === "tcp" && port.tlsTermination)),
+				)
 				.map((port) => port.domain?.trim())
 				.filter((domain): domain is string => Boolean(domain)),
 		),
@@ -813,6 +982,40 @@ function buildEnv(secretRows: ServiceRevisionSecret[]) {
 	return env;
 }
 
+function buildVolumes(specification: ServiceRevisionSpec) {
+	return specification.volumes
+		.slice()
+		.sort((a, b) => a.containerPath.localeCompare(b.containerPath))
+		.map((volume) => ({
+			name: volume.name,
+			containerPath: volume.containerPath,
+		}));
+}
+
+// Traefik's names for the two verifying client auth modes; both require the
+// configured CA bundle.
+const traefikClientAuthModes = {
+	require: "RequireAndVerifyClientCert",
+	optional: "VerifyClientCertIfGiven",
+} as const;
+
+function routeClientAuth(port: RouteServicePort): { clientAuth?: ClientAuth } {
+	return port.clientAuth
+		? {
+				clientAuth: {
+					caBundle: port.clientAuth.caBundle,
+					mode: traefikClientAuthModes[port.clientAuth.mode],
+				},
+			}
+		: {};
+}
+
+function serverlessTcpTriggerPort(port: RouteServicePort) {
+	return port.isPublic && port.protocol === "tcp" && port.externalPort
+		? port.externalPort + SERVERLESS_TCP_TRIGGER_PORT_OFFSET
+		: null;
+}
+
 function upstreamUrls(deployments: RoutableDeploymentRow[], port: number) {
 	return deployments
 		.map((d) => d.ipAddress)
@@ -847,6 +1050,9 @@ export function buildRuntimeRoutePorts(
 			protocol: port.protocol,
 			externalPort: port.externalPort,
 			tlsPassthrough: port.tlsPassthrough,
+			tlsTermination: port.tlsTermination,
+			proxyProtocol: port.proxyProtocol,
+			clientAuth: port.clientAuth,
 		})),
 	);
 }
@@ -43,28 +43,24 @@ async function getStatefulBuildTargetServer(
 	};
 }
 
-export async function selectBuildServerForRevision(
-	specification: ServiceRevisionSpec,
-	platform: string,
-): Promise<string> {
+export type BuildAssignment = { platforms: string[]; serverId: string };
+
+function platformArch(platform: string) {
 	const arch = platform.split("/")[1];
 	if (!arch) throw new Error(`Invalid build platform ${platform}`);
+	return arch;
+}
 
-	if (specification.stateful) {
-		const targetServer = await getStatefulBuildTargetServer(specification);
-		if (targetServer.meta.arch !== arch) {
-			throw new Error(
-				`Stateful service target server architecture ${targetServer.meta.arch} does not match platform ${platform}`,
-			);
-		}
-		return targetServer.id;
-	}
+function pickServer<T>(candidates: T[]): T {
+	return candidates[Math.floor(Math.random() * candidates.length)];
+}
 
+async function getOnlineBuildServers() {
 	const allowedBuildServerIds = await getSetting<string[]>(
 		SETTING_KEYS.SERVERS_ALLOWED_FOR_BUILDS,
 	);
-	const onlineServers = allowedBuildServerIds?.length
-		? await db
+	return allowedBuildServerIds?.length
+		? db
 				.select({ id: servers.id, meta: servers.meta })
 				.from(servers)
 				.where(
@@ -73,10 +69,29 @@ export async function selectBuildServerForRevision(
 						inArray(servers.id, allowedBuildServerIds),
 					),
 				)
-		: await db
+		: db
 				.select({ id: servers.id, meta: servers.meta })
 				.from(servers)
 				.where(eq(servers.status, "online"));
+}
+
+export async function selectBuildServerForRevision(
+	specification: ServiceRevisionSpec,
+	platform: string,
+): Promise<string> {
+	const arch = platformArch(platform);
+
+	if (specification.stateful) {
+		const targetServer = await getStatefulBuildTargetServer(specification);
+		if (targetServer.meta.arch !== arch) {
+			throw new Error(
+				`Stateful service target server architecture ${targetServer.meta.arch} does not match platform ${platform}`,
+			);
+		}
+		return targetServer.id;
+	}
+
+	const onlineServers = await getOnlineBuildServers();
 	const matchingServers = onlineServers.filter(
 		(server) => server.meta?.arch === arch,
 	);
@@ -85,7 +100,47 @@ export async function selectBuildServerForRevision(
 	if (buildServers.length === 0) {
 		throw new Error(`No online servers available for platform ${platform}`);
 	}
-	return buildServers[Math.floor(Math.random() * buildServers.length)].id;
+	return pickServer(buildServers).id;
+}
+
+/**
+ * Fans a build out to one native builder per platform. When any platform has
+ * no native builder online, a single builder produces every platform and
+ * emulates the ones it cannot run, so the group never mixes emulated and
+ * native images.
+ */
+export async function assignBuildServersForRevision(
+	specification: ServiceRevisionSpec,
+	targetPlatforms: string[],
+): Promise<BuildAssignment[]> {
+	if (specification.stateful) {
+		return Promise.all(
+			targetPlatforms.map(async (platform) => ({
+				platforms: [platform],
+				serverId: await selectBuildServerForRevision(specification, platform),
+			})),
+		);
+	}
+
+	const onlineServers = await getOnlineBuildServers();
+	const nativeServers = targetPlatforms.map((platform) => {
+		const arch = platformArch(platform);
+		return onlineServers.filter((server) => server.meta?.arch === arch);
+	});
+	if (nativeServers.every((candidates) => candidates.length > 0)) {
+		return targetPlatforms.map((platform, index) => ({
+			platforms: [platform],
+			serverId: pickServer(nativeServers[index]).id,
+		}));
+	}
+	if (onlineServers.length === 0) {
+		throw new Error(
+			`No online servers available for platform ${targetPlatforms.join(",")}`,
+		);
+	}
+	return [
+		{ platforms: targetPlatforms, serverId: pickServer(onlineServers).id },
+	];
 }
 
 export async function getTargetPlatformsForRevision(
@@ -0,0 +1,57 @@
+import { createHash, randomUUID } from "node:crypto";
+import { and, desc, eq, notInArray } from "drizzle-orm";
+import { db } from "@/db";
+import { buildContexts } from "@/db/schema";
+
+export const MAX_BUILD_CONTEXT_BYTES = 100 * 1024 * 1024;
+export const BUILD_CONTEXTS_RETAINED = 5;
+
+const GZIP_MAGIC = [0x1f, 0x8b];
+
+export function isGzipArchive(data: Uint8Array): boolean {
+	return data.length > 2 && GZIP_MAGIC.every((byte, i) => data[i] === byte);
+}
+
+/** Stores an uploaded build context and prunes the service's older ones. */
+export async function storeBuildContext(serviceId: string, data: Buffer) {
+	const id = randomUUID();
+	const checksum = createHash("sha256").update(data).digest("hex");
+	await db.transaction(async (tx) => {
+		await tx.insert(buildContexts).values({
+			id,
+			serviceId,
+			checksum,
+			sizeBytes: data.length,
+			data,
+		});
+		const retained = await tx
+			.select({ id: buildContexts.id })
+			.from(buildContexts)
+			.where(eq(buildContexts.serviceId, serviceId))
+			.orderBy(desc(buildContexts.createdAt), desc(buildContexts.id))
+			.limit(BUILD_CONTEXTS_RETAINED);
+		await tx.delete(buildContexts).where(
+			and(
+				eq(buildContexts.serviceId, serviceId),
+				notInArray(
+					buildContexts.id,
+					retained.map((row) => row.id),
+				),
+			),
+		);
+	});
+	return { id, checksum };
+}
+
+export async function getBuildContext(serviceId: string, contextId: string) {
+	return db
+		.select()
+		.from(buildContexts)
+		.where(
+			and(
+				eq(buildContexts.id, contextId),
+				eq(buildContexts.serviceId, serviceId),
+			),
+		)
+		.then((rows) => rows[0]);
+}
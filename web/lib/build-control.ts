@@ -0,0 +1,144 @@
+import { and, eq, inArray, isNull } from "drizzle-orm";
+import { db } from "@/db";
+import { builds, serviceRevisions, services } from "@/db/schema";
+import type { Build, BuildStatus } from "@/db/types";
+import { inngest } from "@/lib/inngest/client";
+import { inngestEvents } from "@/lib/inngest/events";
+import type { ServiceRevisionActor } from "@/lib/service-revision-actor";
+import { parseServiceRevisionSpec } from "@/lib/service-revision-changes";
+import {
+	requeueBuildRevisionInternal,
+	triggerBuildInternal,
+} from "@/lib/trigger-build";
+
+export const cancellableBuildStatuses: BuildStatus[] = [
+	"pending",
+	"claimed",
+	"cloning",
+	"building",
+	"pushing",
+];
+
+export class BuildControlError extends Error {
+	constructor(
+		message: string,
+		readonly code: string,
+		readonly status = 409,
+	) {
+		super(message);
+		this.name = "BuildControlError";
+	}
+}
+
+export async function cancelBuildInternal(buildId: string) {
+	const build = await db
+		.select({
+			status: builds.status,
+			buildGroupId: builds.buildGroupId,
+		})
+		.from(builds)
+		.where(eq(builds.id, buildId))
+		.then((rows) => rows[0]);
+	if (!build) {
+		throw new BuildControlError("Build not found", "NOT_FOUND", 404);
+	}
+	if (!cancellableBuildStatuses.includes(build.status)) {
+		throw new BuildControlError(
+			`Cannot cancel build in ${build.status} status`,
+			"BUILD_NOT_CANCELLABLE",
+		);
+	}
+
+	const cancelled = await db
+		.update(builds)
+		.set({ status: "cancelled", completedAt: new Date() })
+		.where(
+			and(
+				eq(builds.id, buildId),
+				inArray(builds.status, cancellableBuildStatuses),
+			),
+		)
+		.returning({ id: builds.id })
+		.then((rows) => rows[0]);
+	if (!cancelled) {
+		const current = await db
+			.select({ status: builds.status })
+			.from(builds)
+			.where(eq(builds.id, buildId))
+			.then((rows) => rows[0]);
+		if (!current) {
+			throw new BuildControlError("Build not found", "NOT_FOUND", 404);
+		}
+		throw new BuildControlError(
+			`Cannot cancel build in ${current.status} status`,
+			"BUILD_NOT_CANCELLABLE",
+		);
+	}
+
+	await inngest.send(
+		inngestEvents.buildCancelled.create(
+			{
+				buildId,
+				buildGroupId: build.buildGroupId,
+			},
+			{
+				id: `build-cancelled-${buildId}`,
+			},
+		),
+	);
+}
+
+/**
+ * Queues a new build from the exact service revision an earlier build used,
+ * so the commit, build options and secrets match. Preview services rebuild
+ * their pull request ref instead. Uploaded contexts cannot be rebuilt: only
+ * the last five per service are kept, so an older build's context may be gone.
+ */
+export async function rebuildBuildInternal(
+	build: Pick<
+		Build,
+		"serviceId" | "serviceRevisionId" | "commitMessage" | "author"
+	>,
+	actor: ServiceRevisionActor,
+) {
+	const [service, revision] = await Promise.all([
+		db
+			.select({ id: services.id, previewOfService: services.previewOfService })
+			.from(services)
+			.where(and(eq(services.id, build.serviceId), isNull(services.deletedAt)))
+			.then((rows) => rows[0]),
+		db
+			.select({ specification: serviceRevisions.specification })
+			.from(serviceRevisions)
+			.where(eq(serviceRevisions.id, build.serviceRevisionId))
+			.then((rows) => rows[0]),
+	]);
+	if (!service) {
+		throw new BuildControlError("Service not found", "NOT_FOUND", 404);
+	}
+
+	if (service.previewOfService) {
+		await triggerBuildInternal(build.serviceId, "manual", actor);
+		return { status: "queued" as const, serviceRevisionId: null };
+	}
+	const source = revision
+		? parseServiceRevisionSpec(revision.specification).source
+		: null;
+	if (source?.type !== "github" && source?.type !== "git") {
+		throw new BuildControlError(
+			"Only GitHub and git builds can be rebuilt; upload the context again with tc deploy --local",
+			"BUILD_NOT_REBUILDABLE",
+		);
+	}
+	const result = await requeueBuildRevisionInternal({
+		serviceId: build.serviceId,
+		serviceRevisionId: build.serviceRevisionId,
+		commitMessage: build.commitMessage ?? "Rebuild",
+		author: build.author ?? undefined,
+		actor,
+	});
+	return {
+		status: result.status,
+		serviceRevisionId: result.serviceRevisionId,
+	};
+}
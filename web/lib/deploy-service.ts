@@ -6,6 +6,7 @@ import { serviceReplicas } from "@/db/schema";
 import { startMigrationInternal } from "@/lib/migrations";
 import { sendRolloutCreated } from "@/lib/rollout-enqueue";
 import type { ServiceRevisionActor } from "@/lib/service-revision-actor";
+import { isBuiltServiceSourceType } from "@/lib/service-revision-spec";
 import {
 	createRolloutForServiceRevision,
 	createRolloutWithServiceRevision,
@@ -35,6 +36,7 @@ export async function deployServiceInternal(
 	options: {
 		runtimeBaseRevisionId?: string;
 		githubTrigger?: "manual" | "scheduled";
+		commitSha?: string;
 	} = {},
 ) {
 	const service = await getService(serviceId);
@@ -75,13 +77,17 @@ export async function deployServiceInternal(
 			return { migrationStarted: true };
 		}
 	}
-	const runtimeBaseRevisionId =
-		service.sourceType === "github" ? options.runtimeBaseRevisionId : undefined;
-	if (service.sourceType === "github" && !runtimeBaseRevisionId) {
+	const builtSource = isBuiltServiceSourceType(service.sourceType);
+	const runtimeBaseRevisionId = builtSource
+		? options.runtimeBaseRevisionId
+		: undefined;
+	if (builtSource && !runtimeBaseRevisionId) {
 		if (!options.githubTrigger || !actor) {
 			throw new Error("GitHub deployment requires a build trigger");
 		}
-		return triggerBuildInternal(serviceId, options.githubTrigger, actor);
+		return triggerBuildInternal(serviceId, options.githubTrigger, actor, {
+			commitSha: options.commitSha,
+		});
 	}
 
 	const { rolloutId } = await createRolloutWithServiceRevision(
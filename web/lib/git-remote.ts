@@ -0,0 +1,156 @@
+import { eq } from "drizzle-orm";
+import { db } from "@/db";
+import { serviceGitCredentials } from "@/db/schema";
+import { decryptSecret, encryptSecret } from "@/lib/crypto";
+
+const ADVERTISEMENT_TIMEOUT_MS = 15_000;
+const DEFAULT_TOKEN_USERNAME = "oauth2";
+const fullCommitSha = /^[0-9a-f]{40}$/;
+
+export type GitCredentialType = typeof serviceGitCredentials.$inferSelect.type;
+
+export type GitCredentialInput =
+	| { type: "https_token"; username?: string | null; token: string }
+	| { type: "ssh_key"; privateKey: string; knownHosts: string };
+
+export type GitTokenAuth = { username: string; token: string };
+
+export async function getServiceGitCredential(serviceId: string) {
+	return db
+		.select()
+		.from(serviceGitCredentials)
+		.where(eq(serviceGitCredentials.serviceId, serviceId))
+		.then((rows) => rows[0] ?? null);
+}
+
+export function describeGitCredential(
+	credential: typeof serviceGitCredentials.$inferSelect | null,
+) {
+	if (!credential) return { type: "none" as const };
+	return {
+		type: credential.type,
+		username:
+			credential.type === "https_token"
+				? credential.username || DEFAULT_TOKEN_USERNAME
+				: null,
+		knownHosts: credential.type === "ssh_key" ? !!credential.knownHosts : null,
+		updatedAt: credential.updatedAt,
+	};
+}
+
+export async function setServiceGitCredential(
+	serviceId: string,
+	input: GitCredentialInput,
+) {
+	const values =
+		input.type === "https_token"
+			? {
+					type: input.type,
+					username: input.username?.trim() || null,
+					encryptedSecret: await encryptSecret(input.token),
+					knownHosts: null,
+				}
+			: {
+					type: input.type,
+					username: null,
+					encryptedSecret: await encryptSecret(input.privateKey.trim()),
+					knownHosts: input.knownHosts.trim(),
+				};
+	return db
+		.insert(serviceGitCredentials)
+		.values({ serviceId, ...values })
+		.onConflictDoUpdate({
+			target: serviceGitCredentials.serviceId,
+			set: values,
+		})
+		.returning()
+		.then((rows) => rows[0]);
+}
+
+export async function deleteServiceGitCredential(serviceId: string) {
+	const deleted = await db
+		.delete(serviceGitCredentials)
+		.where(eq(serviceGitCredentials.serviceId, serviceId))
+		.returning({ serviceId: serviceGitCredentials.serviceId });
+	return deleted.length > 0;
+}
+
+export async function gitTokenAuthForService(
+	serviceId: string,
+): Promise<GitTokenAuth | null> {
+	const credential = await getServiceGitCredential(serviceId);
+	if (!credential || credential.type !== "https_token") return null;
+	return {
+		username: credential.username || DEFAULT_TOKEN_USERNAME,
+		token: await decryptSecret(credential.encryptedSecret),
+	};
+}
+
+/**
+ * Reads a ref advertisement in git's pkt-line framing and returns the commit
+ * a branch points at. Both the v0 advertisement and the smart HTTP service
+ * preamble are accepted.
+ */
+export function findAdvertisedBranch(
+	advertisement: Buffer,
+	branch: string,
+): string | null {
+	const wanted = `refs/heads/${branch}`;
+	let offset = 0;
+	while (offset + 4 <= advertisement.length) {
+		const length = Number.parseInt(
+			advertisement.subarray(offset, offset + 4).toString("ascii"),
+			16,
+		);
+		if (Number.isNaN(length)) return null;
+		if (length < 4) {
+			offset += 4;
+			continue;
+		}
+		const line = advertisement
+			.subarray(offset + 4, offset + length)
+			.toString("utf8")
+			.split("\0", 1)[0]
+			.replace(/\n$/, "");
+		offset += length;
+		const [sha, ref] = line.split(" ", 2);
+		if (ref === wanted && fullCommitSha.test(sha)) return sha;
+	}
+	return null;
+}
+
+/** Resolves a branch head over git's smart HTTP protocol. */
+export async function resolveGitBranchCommit(
+	repository: string,
+	branch: string,
+	auth: GitTokenAuth | null,
+	fetchImpl: typeof fetch = fetch,
+): Promise<string> {
+	if (!repository.startsWith("https://")) {
+		throw new Error("Only HTTPS git sources can be resolved by the server");
+	}
+	const headers: Record<string, string> = {
+		"User-Agent": "git/2.0 techulus-cloud",
+	};
+	if (auth) {
+		headers.Authorization = `Basic ${Buffer.from(`${auth.username}:${auth.token}`).toString("base64")}`;
+	}
+	const response = await fetchImpl(
+		`${repository.replace(/\/+$/, "")}/info/refs?service=git-upload-pack`,
+		{ headers, signal: AbortSignal.timeout(ADVERTISEMENT_TIMEOUT_MS) },
+	);
+	if (response.status === 401 || response.status === 403) {
+		throw new Error("Git repository rejected the configured credentials");
+	}
+	if (!response.ok) {
+		throw new Error(`Git repository returned HTTP ${response.status}`);
+	}
+	const sha = findAdvertisedBranch(
+		Buffer.from(await response.arrayBuffer()),
+		branch,
+	);
+	if (!sha) {
+		throw new Error(`Git branch ${branch} was not found in the repository`);
+	}
+	return sha;
+}
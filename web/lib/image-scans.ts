@@ -0,0 +1,138 @@
+import { and, desc, eq, sql } from "drizzle-orm";
+import { db } from "@/db";
+import {
+	builds,
+	rollouts,
+	serviceRevisions,
+	services,
+	workQueue,
+} from "@/db/schema";
+import {
+	isBuiltServiceRevisionSource,
+	type ServiceRevisionSpec,
+} from "@/lib/service-revision-spec";
+import { evaluateVulnerabilityGate } from "@/lib/vulnerability-gate";
+import { enqueueWork } from "@/lib/work-queue";
+
+export type RolloutVulnerabilityGate =
+	| { status: "passed" }
+	| { status: "blocked"; reason: string }
+	| { status: "scanning"; scanId: string };
+
+function gateStatus(
+	gate: ReturnType<typeof evaluateVulnerabilityGate>,
+): RolloutVulnerabilityGate {
+	return gate.blocked
+		? { status: "blocked", reason: gate.reason }
+		: { status: "passed" };
+}
+
+async function getVulnerabilityThreshold(serviceId: string) {
+	return db
+		.select({ maxCritical: services.vulnerabilityMaxCritical })
+		.from(services)
+		.where(eq(services.id, serviceId))
+		.then((rows) => rows[0]?.maxCritical ?? null);
+}
+
+// The latest completed build group whose revision produced the image.
+async function getArtifactBuilds(serviceId: string, image: string) {
+	const rows = await db
+		.select({
+			buildGroupId: builds.buildGroupId,
+			targetPlatform: builds.targetPlatform,
+			vulnerabilities: builds.vulnerabilities,
+		})
+		.from(builds)
+		.innerJoin(
+			serviceRevisions,
+			eq(serviceRevisions.id, builds.serviceRevisionId),
+		)
+		.where(
+			and(
+				eq(builds.serviceId, serviceId),
+				eq(builds.status, "completed"),
+				sql`${serviceRevisions.specification}->>'image' = ${image}`,
+			),
+		)
+		.orderBy(desc(builds.createdAt));
+	const buildGroupId = rows[0]?.buildGroupId;
+	return rows.filter((row) => row.buildGroupId === buildGroupId);
+}
+
+export function imageScanId(rolloutId: string) {
+	return `image-scan-${rolloutId}`;
+}
+
+/**
+ * Applies the service's critical vulnerability threshold to the image a
+ * rollout deploys. Built images are judged by the scans of the builds that
+ * produced them, so redeploys and rollbacks follow the threshold too. A
+ * pulled image is first scanned by the server the rollout starts on.
+ */
+export async function checkRolloutVulnerabilityGate(
+	rolloutId: string,
+	serviceId: string,
+	specification: ServiceRevisionSpec,
+	serverId: string,
+): Promise<RolloutVulnerabilityGate> {
+	const maxCritical = await getVulnerabilityThreshold(serviceId);
+	if (maxCritical === null) return { status: "passed" };
+
+	if (isBuiltServiceRevisionSource(specification.source)) {
+		const artifactBuilds = await getArtifactBuilds(
+			serviceId,
+			specification.image,
+		);
+		return gateStatus(
+			evaluateVulnerabilityGate(
+				maxCritical,
+				artifactBuilds.length > 0
+					? artifactBuilds
+					: [{ targetPlatform: specification.image, vulnerabilities: null }],
+			),
+		);
+	}
+
+	const scanId = imageScanId(rolloutId);
+	await enqueueWork(
+		serverId,
+		"scan_image",
+		{ rolloutId, image: specification.image },
+		{ id: scanId },
+	);
+	return { status: "scanning", scanId };
+}
+
+export async function getImageScanStatus(scanId: string) {
+	return db
+		.select({ status: workQueue.status })
+		.from(workQueue)
+		.where(eq(workQueue.id, scanId))
+		.then((rows) => rows[0]?.status ?? null);
+}
+
+/** Judges a pulled image once its scan has finished, failed or timed out. */
+export async function evaluateImageScan(
+	rolloutId: string,
+	serviceId: string,
+	image: string,
+): Promise<RolloutVulnerabilityGate> {
+	const [maxCritical, rollout] = await Promise.all([
+		getVulnerabilityThreshold(serviceId),
+		db
+			.select({ vulnerabilities: rollouts.vulnerabilities })
+			.from(rollouts)
+			.where(eq(rollouts.id, rolloutId))
+			.then((rows) => rows[0]),
+	]);
+	if (maxCritical === null) return { status: "passed" };
+	return gateStatus(
+		evaluateVulnerabilityGate(maxCritical, [
+			{
+				targetPlatform: image,
+				vulnerabilities: rollout?.vulnerabilities ?? null,
+			},
+		]),
+	);
+}
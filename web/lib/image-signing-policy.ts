@@ -0,0 +1,55 @@
+import { eq } from "drizzle-orm";
+import { z } from "zod";
+import { db } from "@/db";
+import { type ImageSigningPolicy, projects, services } from "@/db/schema";
+
+const publicKeyPattern =
+	/^-----BEGIN PUBLIC KEY-----\r?\n[A-Za-z0-9+/=\r\n]+-----END PUBLIC KEY-----\s*$/;
+
+export const imageSigningPolicySchema = z
+	.strictObject({
+		publicKeys: z
+			.array(
+				z
+					.string()
+					.max(4096)
+					.regex(publicKeyPattern, "Public keys must be PEM encoded"),
+			)
+			.max(8)
+			.default([]),
+		identities: z
+			.array(
+				z.strictObject({
+					issuer: z
+						.string()
+						.max(512)
+						.regex(/^https:\/\/\S+$/, "Issuer must be an https URL"),
+					subject: z.string().trim().min(1).max(512),
+				}),
+			)
+			.max(8)
+			.default([]),
+	})
+	.refine(
+		(policy) => policy.publicKeys.length > 0 || policy.identities.length > 0,
+		"A signing policy needs at least one public key or identity",
+	);
+
+export function parseImageSigningPolicy(
+	value: unknown,
+): ImageSigningPolicy | null {
+	const parsed = imageSigningPolicySchema.safeParse(value);
+	return parsed.success ? parsed.data : null;
+}
+
+export async function getServiceSigningPolicy(
+	serviceId: string,
+): Promise<ImageSigningPolicy | null> {
+	const row = await db
+		.select({ policy: projects.imageSigningPolicy })
+		.from(services)
+		.innerJoin(projects, eq(projects.id, services.projectId))
+		.where(eq(services.id, serviceId))
+		.then((rows) => rows[0]);
+	return parseImageSigningPolicy(row?.policy ?? null);
+}
@@ -6,7 +6,7 @@ export type BuildEvents = {
 			serviceId: string;
 			serviceRevisionId: string;
 			buildRequestId: string;
-			trigger: "manual" | "scheduled" | "push" | "preview";
+			trigger: "manual" | "scheduled" | "push" | "preview" | "local";
 			commitSha: string;
 			commitMessage: string;
 			branch: string;
@@ -7,6 +7,7 @@ export type ResourceEvents = {
 				| "backup"
 				| "restore"
 				| "build"
+				| "run"
 				| "workItem"
 				| "server"
 				| "service";
@@ -17,6 +18,7 @@ export type ResourceEvents = {
 				| "backup"
 				| "restore"
 				| "build"
+				| "run"
 				| "workItem"
 				| "server"
 				| "service";
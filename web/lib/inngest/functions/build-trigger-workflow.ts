@@ -3,12 +3,16 @@ import { and, desc, eq, inArray, isNull, sql } from "drizzle-orm";
 import { db } from "@/db";
 import { builds, serviceRevisions, services } from "@/db/schema";
 import {
+	assignBuildServersForRevision,
 	getTargetPlatformsForRevision,
-	selectBuildServerForRevision,
 } from "@/lib/build-assignment";
 import { isFullCommitSha } from "@/lib/github";
 import { createPreviewGitHubDeployment } from "@/lib/preview-deployments";
 import { parseServiceRevisionSpec } from "@/lib/service-revision-changes";
+import {
+	isBuiltServiceRevisionSource,
+	serviceRevisionBuildRef,
+} from "@/lib/service-revision-spec";
 import { enqueueWork } from "@/lib/work-queue";
 import { inngest } from "../client";
 import { inngestEvents } from "../events";
@@ -40,7 +44,7 @@ export const buildTriggerWorkflow = inngest.createFunction(
 			githubDeploymentId,
 			actor = null,
 		} = event.data;
-		if (!isFullCommitSha(commitSha)) {
+		if (trigger !== "local" && !isFullCommitSha(commitSha)) {
 			throw new Error("Build fan-out requires a full 40-character commit SHA");
 		}
 		const exactCommitSha = commitSha.toLowerCase();
@@ -61,10 +65,14 @@ export const buildTriggerWorkflow = inngest.createFunction(
 				.then((rows) => rows[0]);
 			if (!revision) throw new Error("Build service revision not found");
 			const parsed = parseServiceRevisionSpec(revision.specification);
+			const buildRef = isBuiltServiceRevisionSource(parsed.source)
+				? serviceRevisionBuildRef(parsed.source)
+				: null;
 			if (
-				parsed.source.type !== "github" ||
-				parsed.source.commitSha !== exactCommitSha ||
-				parsed.source.branch !== branch ||
+				!buildRef ||
+				(parsed.source.type === "local") !== (trigger === "local") ||
+				buildRef.commitSha !== exactCommitSha ||
+				buildRef.branch !== branch ||
 				(revision.previewGitRef ?? undefined) !== gitRef
 			) {
 				throw new Error("Build trigger does not match its service revision");
@@ -86,13 +94,16 @@ export const buildTriggerWorkflow = inngest.createFunction(
 				throw new Error("Duplicate target platforms configured for this build");
 			}
 
-			const assignments = await Promise.all(
-				targetPlatforms.map(async (platform) => ({
+			const assignments = (
+				await assignBuildServersForRevision(specification, targetPlatforms)
+			).map(({ platforms, serverId }) => {
+				const platform = platforms.join(",");
+				return {
 					id: buildIdForRequest(buildRequestId, platform),
 					platform,
-					serverId: await selectBuildServerForRevision(specification, platform),
-				})),
-			);
+					serverId,
+				};
+			});
 			const buildRows = assignments.map(({ id, platform }) => ({
 				id,
 				serviceId,
@@ -1,9 +1,16 @@
 import { and, eq, inArray } from "drizzle-orm";
 import { db } from "@/db";
-import { builds, workQueue } from "@/db/schema";
+import {
+	builds,
+	services,
+	type VulnerabilitySummary,
+	workQueue,
+} from "@/db/schema";
 import { deployServiceRevisionInternal } from "@/lib/deploy-service";
+import { notify } from "@/lib/notifications";
 import { updatePreviewGitHubStatus } from "@/lib/preview-deployments";
 import { reportOperationFailure, reportServerError } from "@/lib/server-errors";
+import { evaluateVulnerabilityGate } from "@/lib/vulnerability-gate";
 import { inngest } from "../client";
 import { inngestEvents } from "../events";
 
@@ -13,6 +20,7 @@ type GroupBuild = {
 	status: BuildStatus;
 	targetPlatform: string;
 	imageUri: string | null;
+	vulnerabilities?: VulnerabilitySummary | null;
 };
 type ManifestState =
 	| {
@@ -32,14 +40,16 @@ const nonTerminalBuildStatuses: BuildStatus[] = [
 ];
 
 function validateTargetPlatform(targetPlatform: string) {
-	const [operatingSystem, architecture, ...extra] = targetPlatform.split("/");
-	if (
-		operatingSystem !== "linux" ||
-		!architecture ||
-		extra.length > 0 ||
-		!["amd64", "arm64"].includes(architecture)
-	) {
-		throw new Error(`Invalid build target platform: ${targetPlatform}`);
+	for (const platform of targetPlatform.split(",")) {
+		const [operatingSystem, architecture, ...extra] = platform.split("/");
+		if (
+			operatingSystem !== "linux" ||
+			!architecture ||
+			extra.length > 0 ||
+			!["amd64", "arm64"].includes(architecture)
+		) {
+			throw new Error(`Invalid build target platform: ${targetPlatform}`);
+		}
 	}
 }
 
@@ -69,6 +79,7 @@ async function getGroupBuilds(
 			status: builds.status,
 			targetPlatform: builds.targetPlatform,
 			imageUri: builds.imageUri,
+			vulnerabilities: builds.vulnerabilities,
 		})
 		.from(builds)
 		.where(
@@ -386,6 +397,47 @@ export const buildWorkflow = inngest.createFunction(
 
 		groupBuilds = await step.run("validate-group-before-deploy", readGroup);
 		validateCompletedGroup(groupBuilds, manifest);
+		const completedBuilds = groupBuilds;
+		const gate = await step.run("check-vulnerability-gate", async () => {
+			const service = await db
+				.select({ maxCritical: services.vulnerabilityMaxCritical })
+				.from(services)
+				.where(eq(services.id, serviceId))
+				.then((rows) => rows[0]);
+			return evaluateVulnerabilityGate(
+				service?.maxCritical ?? null,
+				completedBuilds.map((build) => ({
+					targetPlatform: build.targetPlatform,
+					vulnerabilities: build.vulnerabilities ?? null,
+				})),
+			);
+		});
+		if (gate.blocked) {
+			await step.run("record-vulnerability-block", async () => {
+				await db
+					.update(builds)
+					.set({ rolloutBlockedReason: gate.reason })
+					.where(
+						and(
+							eq(builds.serviceId, serviceId),
+							eq(builds.buildGroupId, buildGroupId),
+						),
+					);
+				await notify({
+					kind: "build.failed",
+					occurrenceId: `vulnerability-gate-${buildGroupId}`,
+					serviceId,
+					buildId: completedBuilds[0].id,
+					error: gate.reason,
+				});
+				await markPreviewBuildFailed(
+					serviceId,
+					serviceRevisionId,
+					"Preview rollout blocked by vulnerability scan",
+				);
+			});
+			return { status: "blocked", reason: "vulnerabilities", buildGroupId };
+		}
 		const deployment = await step.run("trigger-deploy-group", () =>
 			deployServiceRevisionInternal(
 				serviceId,
@@ -10,7 +10,10 @@ import { cleanupOldBackups, runScheduledBackups } from "@/lib/backup-scheduler";
 import { checkAndPersistControlPlaneUpdate } from "@/lib/control-plane-updates";
 import { cleanupReadNotifications } from "@/lib/notifications";
 import { cleanupRegistryArtifactsDaily } from "@/lib/registry-retention";
-import { cleanupOldServiceCommands } from "@/lib/service-command-retention";
+import {
+	cleanupOldServiceCommands,
+	cleanupOldServiceRuns,
+} from "@/lib/service-command-retention";
 import {
 	checkAndRecoverStaleServers,
 	checkAndRunScheduledDeployments,
@@ -212,8 +215,14 @@ export const serviceCommandRetention = inngest.createFunction(
 		triggers: [cron("0 7 * * *")],
 		singleton: { mode: "skip" },
 	},
-	async ({ step }) =>
-		step.run("cleanup-service-commands", cleanupOldServiceCommands),
+	async ({ step }) => {
+		const commands = await step.run(
+			"cleanup-service-commands",
+			cleanupOldServiceCommands,
+		);
+		const runs = await step.run("cleanup-service-runs", cleanupOldServiceRuns);
+		return { commands, runs };
+	},
 );
 
 export const previewReconciliation = inngest.createFunction(
@@ -295,6 +304,7 @@ export const serviceCronDispatcher = inngest.createFunction(
 					row.schedule,
 					new Date(row.nextScheduledFor.getTime() - 1),
 					now,
+					row.timeZone,
 				);
 				if (!occurrence) continue;
 				await inngest.send({
@@ -311,7 +321,11 @@ export const serviceCronDispatcher = inngest.createFunction(
 					.update(serviceCrons)
 					.set({
 						lastScheduledFor: occurrence,
-						nextScheduledFor: nextOccurrenceAfter(row.schedule, now),
+						nextScheduledFor: nextOccurrenceAfter(
+							row.schedule,
+							now,
+							row.timeZone,
+						),
 					})
 					.where(
 						and(
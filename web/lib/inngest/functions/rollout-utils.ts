@@ -13,6 +13,9 @@ import {
 type RolloutFailureStage =
 	| "workflow_failed"
 	| "preflight_failed"
+	| "vulnerability_blocked"
+	| "release_failed"
+	| "release_timeout"
 	| "certificate_provisioning_failed"
 	| "deployment_failed"
 	| "health_check_failed"
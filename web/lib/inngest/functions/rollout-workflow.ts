@@ -20,12 +20,22 @@ import {
 	services,
 } from "@/db/schema";
 import { isObservedReady, observedReadyPhases } from "@/lib/deployment-status";
+import {
+	checkRolloutVulnerabilityGate,
+	evaluateImageScan,
+	getImageScanStatus,
+} from "@/lib/image-scans";
 import { buildRoutingTargets } from "@/lib/routing-sync";
 import {
 	canDeployServiceRevision,
 	updatePreviewGitHubStatus,
 } from "@/lib/preview-deployments";
 import type { ServiceRevisionSpec } from "@/lib/service-revision-spec";
+import {
+	abandonReleaseRun,
+	getReleaseRun,
+	startReleaseRun,
+} from "@/lib/service-runs";
 import { getRolloutServiceRevision } from "@/lib/service-revisions";
 import { reportOperationFailure } from "@/lib/server-errors";
 import { ingestRolloutLog } from "@/lib/victoria-logs";
@@ -57,6 +67,9 @@ const PREFLIGHT_FAILURE_PREFIXES = ["Server "];
 
 const ROLLOUT_TURN_WAIT_ATTEMPTS = 360;
 const ROLLOUT_TURN_WAIT_INTERVAL = "10s";
+// Covers the release command's own timeout plus pulling the new image.
+const RELEASE_WAIT_TIMEOUT = "45m";
+const IMAGE_SCAN_WAIT_TIMEOUT = "15m";
 
 type RolloutTurnState = "acquired" | "waiting" | "terminal";
 
@@ -78,6 +91,21 @@ function getPreflightFailureReason(error: unknown) {
 	return null;
 }
 
+function describeReleaseFailure(
+	release: Awaited<ReturnType<typeof getReleaseRun>>,
+) {
+	switch (release?.status) {
+		case "failed":
+			return release.exitCode === null
+				? `Release command failed: ${release.errorMessage ?? "unknown error"}`
+				: `Release command exited with code ${release.exitCode}`;
+		case "timed_out":
+			return "Release command timed out";
+		default:
+			return "Release command did not finish in time";
+	}
+}
+
 export async function acquireRolloutTurn(
 	rolloutId: string,
 	serviceId: string,
@@ -446,6 +474,118 @@ export const rolloutWorkflow = inngest.createFunction(
 
 		const { serverIds } = serverValidation;
 
+		let vulnerabilityGate = await step.run("check-vulnerability-gate", () =>
+			checkRolloutVulnerabilityGate(
+				rolloutId,
+				serviceId,
+				specification,
+				serverIds[0],
+			),
+		);
+		if (vulnerabilityGate.status === "scanning") {
+			const { scanId } = vulnerabilityGate;
+			const scanStatus = await step.run("check-image-scan-before-wait", () =>
+				getImageScanStatus(scanId),
+			);
+			if (scanStatus === "pending" || scanStatus === "processing") {
+				await step.waitForEvent("wait-image-scan", {
+					event: inngestEvents.resourceStatusChanged,
+					timeout: IMAGE_SCAN_WAIT_TIMEOUT,
+					if: `async.data.type == "workItem" && async.data.id == "${scanId}"`,
+				});
+			}
+			vulnerabilityGate = await step.run("check-image-scan-after-wait", () =>
+				evaluateImageScan(rolloutId, serviceId, specification.image),
+			);
+		}
+		if (vulnerabilityGate.status === "blocked") {
+			const { reason } = vulnerabilityGate;
+			await step.run("handle-vulnerability-block", async () => {
+				await ingestRolloutLog(rolloutId, serviceId, "preparing", reason);
+				await handleRolloutFailure({
+					rolloutId,
+					serviceId,
+					reason: "vulnerability_blocked",
+					failureStage: "vulnerability_blocked",
+					isRollingUpdate: false,
+					report: false,
+				});
+			});
+			return { status: "failed", rolloutId, reason: "vulnerabilities" };
+		}
+
+		// The release command runs before any existing deployment is touched, so
+		// a failed release leaves the previous version serving traffic.
+		if (specification.releaseCommand) {
+			const releaseRunId = await step.run("start-release", async () => {
+				await db
+					.update(rollouts)
+					.set({ currentStage: "release" })
+					.where(eq(rollouts.id, rolloutId));
+				const runId = await startReleaseRun({
+					rolloutId,
+					serviceId,
+					serverId: serverIds[0],
+				});
+				await ingestRolloutLog(
+					rolloutId,
+					serviceId,
+					"release",
+					`Running release command: ${specification.releaseCommand}`,
+				);
+				return runId;
+			});
+
+			let release = await step.run("check-release-before-wait", () =>
+				getReleaseRun(releaseRunId),
+			);
+			if (release?.status === "pending" || release?.status === "running") {
+				await step.waitForEvent("wait-release", {
+					event: inngestEvents.resourceStatusChanged,
+					timeout: RELEASE_WAIT_TIMEOUT,
+					if: `async.data.type == "run" && async.data.id == "${releaseRunId}"`,
+				});
+				release = await step.run("check-release-after-wait", () =>
+					getReleaseRun(releaseRunId),
+				);
+			}
+
+			if (release?.status !== "succeeded") {
+				const reason =
+					release?.status === "failed"
+						? ("release_failed" as const)
+						: ("release_timeout" as const);
+				await step.run("handle-release-failure", async () => {
+					if (reason === "release_timeout") {
+						await abandonReleaseRun(releaseRunId);
+					}
+					await ingestRolloutLog(
+						rolloutId,
+						serviceId,
+						"release",
+						describeReleaseFailure(release),
+					);
+					await handleRolloutFailure({
+						rolloutId,
+						serviceId,
+						reason,
+						failureStage: reason,
+						isRollingUpdate: false,
+					});
+				});
+				return { status: "failed", rolloutId, reason };
+			}
+
+			await step.run("log-release-succeeded", async () => {
+				await ingestRolloutLog(
+					rolloutId,
+					serviceId,
+					"release",
+					"Release command succeeded",
+				);
+			});
+		}
+
 		await step.run("cleanup-terminal-deployments", async () => {
 			await cleanupTerminalDeployments(serviceId);
 		});
@@ -6,16 +6,34 @@ export const serviceCronWorkflow = inngest.createFunction(
 	{
 		id: "service-cron-execute",
 		retries: 0,
-		concurrency: [{ limit: 1, key: "event.data.cronId" }],
+		concurrency: [{ limit: 10, key: "event.data.cronId" }],
 		triggers: [inngestEvents.serviceCronExecute],
 	},
-	async ({ event, step }) =>
-		step.run("execute-service-cron", () =>
-			executeServiceCron(
-				event.data.cronId,
-				event.data.schedule,
-				new Date(event.data.scheduledFor),
-				event.data.source,
-			),
-		),
+	async ({ event, step }) => {
+		for (let attempt = 1; ; attempt++) {
+			const result = await step.run(
+				attempt === 1
+					? "execute-service-cron"
+					: `execute-service-cron-retry-${attempt}`,
+				() =>
+					executeServiceCron(
+						event.data.cronId,
+						event.data.schedule,
+						new Date(event.data.scheduledFor),
+						event.data.source,
+						attempt,
+					),
+			);
+			if (
+				result.stale ||
+				!("retryInSeconds" in result) ||
+				result.retryInSeconds === null
+			)
+				return result;
+			await step.sleep(
+				`service-cron-retry-backoff-${attempt}`,
+				`${result.retryInSeconds}s`,
+			);
+		}
+	},
 );
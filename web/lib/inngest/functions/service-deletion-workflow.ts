@@ -36,6 +36,10 @@ import {
 	prepareRegistryArtifactCleanup,
 } from "@/lib/registry-retention";
 import { parseServiceRevisionSpec } from "@/lib/service-revision-changes";
+import {
+	isBuiltServiceRevisionSource,
+	isBuiltServiceSourceType,
+} from "@/lib/service-revision-spec";
 import { reportOperationFailure, reportServerError } from "@/lib/server-errors";
 import { enqueueWork } from "@/lib/work-queue";
 import { inngest } from "../client";
@@ -398,36 +402,36 @@ export const serviceRestoreWorkflow = inngest.createFunction(
 				if (!service || !service.deletedAt) {
 					throw new Error("Deleted service not found");
 				}
-				const runtimeBaseRevision =
-					service.sourceType === "github"
-						? await db
-								.select({
-									id: rollouts.serviceRevisionId,
-									specification: serviceRevisions.specification,
-								})
-								.from(rollouts)
-								.innerJoin(
-									serviceRevisions,
-									and(
-										eq(serviceRevisions.id, rollouts.serviceRevisionId),
-										eq(serviceRevisions.serviceId, rollouts.serviceId),
-									),
-								)
-								.where(
-									and(
-										eq(rollouts.serviceId, serviceId),
-										eq(rollouts.status, "completed"),
-									),
-								)
-								.orderBy(
-									desc(rollouts.completedAt),
-									desc(rollouts.createdAt),
-									desc(rollouts.id),
-								)
-								.limit(1)
-								.then((rows) => rows[0])
-						: undefined;
-				if (service.sourceType === "github" && !runtimeBaseRevision) {
+				const builtSource = isBuiltServiceSourceType(service.sourceType);
+				const runtimeBaseRevision = builtSource
+					? await db
+							.select({
+								id: rollouts.serviceRevisionId,
+								specification: serviceRevisions.specification,
+							})
+							.from(rollouts)
+							.innerJoin(
+								serviceRevisions,
+								and(
+									eq(serviceRevisions.id, rollouts.serviceRevisionId),
+									eq(serviceRevisions.serviceId, rollouts.serviceId),
+								),
+							)
+							.where(
+								and(
+									eq(rollouts.serviceId, serviceId),
+									eq(rollouts.status, "completed"),
+								),
+							)
+							.orderBy(
+								desc(rollouts.completedAt),
+								desc(rollouts.createdAt),
+								desc(rollouts.id),
+							)
+							.limit(1)
+							.then((rows) => rows[0])
+					: undefined;
+				if (builtSource && !runtimeBaseRevision) {
 					throw new Error(
 						"Cannot restore GitHub service without a completed runtime revision",
 					);
@@ -441,7 +445,7 @@ export const serviceRestoreWorkflow = inngest.createFunction(
 					} catch {
 						throw new Error("GitHub runtime base revision is invalid");
 					}
-					if (baseSpecification.source.type !== "github") {
+					if (!isBuiltServiceRevisionSource(baseSpecification.source)) {
 						throw new Error(
 							"GitHub runtime base revision is not a GitHub build",
 						);
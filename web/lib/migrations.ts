@@ -12,6 +12,10 @@ import { inngest } from "@/lib/inngest/client";
 import { inngestEvents } from "@/lib/inngest/events";
 import type { ServiceRevisionActor } from "@/lib/service-revision-actor";
 import { parseServiceRevisionSpec } from "@/lib/service-revision-changes";
+import {
+	isBuiltServiceRevisionSource,
+	isBuiltServiceSourceType,
+} from "@/lib/service-revision-spec";
 
 export async function startMigrationInternal(
 	serviceId: string,
@@ -80,7 +84,7 @@ export async function startMigrationInternal(
 	if (deployment.serverId === targetServerId) {
 		throw new Error("Service is already running on the target server");
 	}
-	if (service.sourceType === "github") {
+	if (isBuiltServiceSourceType(service.sourceType)) {
 		const baseRevision = await db
 			.select({ specification: serviceRevisions.specification })
 			.from(serviceRevisions)
@@ -99,7 +103,7 @@ export async function startMigrationInternal(
 		} catch {
 			throw new Error("GitHub runtime base revision is invalid");
 		}
-		if (specification.source.type !== "github") {
+		if (!isBuiltServiceRevisionSource(specification.source)) {
 			throw new Error("GitHub runtime base revision is not a GitHub build");
 		}
 	}
@@ -20,7 +20,9 @@ import {
 } from "@/lib/github";
 import { resolveRegistryImageHost } from "@/lib/registry-reference";
 import {
+	type ClientAuthMode,
 	getDefaultServiceHostname,
+	type ProxyProtocolVersion,
 	pullRequestMergeRef,
 	pullRequestNumberFromMergeRef,
 } from "@/lib/service-revision-spec";
@@ -38,6 +40,10 @@ type PreviewPort = {
 	protocol: "http" | "tcp" | "udp";
 	externalPort: number | null;
 	tlsPassthrough: boolean;
+	tlsTermination?: boolean;
+	proxyProtocol?: ProxyProtocolVersion | null;
+	clientAuthMode?: ClientAuthMode | null;
+	clientCaBundle?: string | null;
 };
 
 type PreviewDeploymentState =
@@ -231,6 +237,10 @@ export function previewPortConfiguration(input: {
 			domain: null,
 			externalPort: null,
 			tlsPassthrough: false,
+			tlsTermination: false,
+			proxyProtocol: null,
+			clientAuthMode: null,
+			clientCaBundle: null,
 		};
 	});
 }
@@ -358,6 +368,9 @@ export async function createPreviewClone(input: {
 			githubRepoUrl: base.githubRepoUrl,
 			githubBranch: base.githubBranch,
 			githubRootDir: base.githubRootDir,
+			buildDockerfile: base.buildDockerfile,
+			buildTarget: base.buildTarget,
+			buildArgs: base.buildArgs,
 			replicas: base.replicas,
 			autoscalingEnabled: base.autoscalingEnabled,
 			autoscalingMinReplicas: base.autoscalingMinReplicas,
@@ -371,6 +384,7 @@ export async function createPreviewClone(input: {
 			healthCheckRetries: base.healthCheckRetries,
 			healthCheckStartPeriod: base.healthCheckStartPeriod,
 			startCommand: base.startCommand,
+			releaseCommand: base.releaseCommand,
 			resourceCpuLimit: base.resourceCpuLimit,
 			resourceMemoryLimitMb: base.resourceMemoryLimitMb,
 			serverlessEnabled: base.serverlessEnabled && primaryDomain !== undefined,
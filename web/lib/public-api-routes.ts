@@ -1,8 +1,37 @@
-import { and, desc, eq, inArray, lt, or, sql } from "drizzle-orm";
+import { and, asc, desc, eq, inArray, lt, or, sql } from "drizzle-orm";
+import { z } from "zod";
 import { db } from "@/db";
-import { builds, deployments, rollouts, servers } from "@/db/schema";
+import {
+	builds,
+	deployments,
+	projects,
+	rollouts,
+	servers,
+	serviceCronRuns,
+	serviceCrons,
+	serviceRuns,
+	services,
+} from "@/db/schema";
 import { requireApiKeyDeveloperRole, requireApiKeyRole } from "@/lib/api-auth";
+import {
+	BuildControlError,
+	cancelBuildInternal,
+	cancellableBuildStatuses,
+	rebuildBuildInternal,
+} from "@/lib/build-control";
+import {
+	isGzipArchive,
+	MAX_BUILD_CONTEXT_BYTES,
+	storeBuildContext,
+} from "@/lib/build-contexts";
 import { deployServiceInternal } from "@/lib/deploy-service";
+import {
+	deleteServiceGitCredential,
+	describeGitCredential,
+	getServiceGitCredential,
+	setServiceGitCredential,
+} from "@/lib/git-remote";
+import { imageSigningPolicySchema } from "@/lib/image-signing-policy";
 import {
 	DEFAULT_LOG_TIME_RANGE,
 	isLogCursor,
@@ -32,10 +61,19 @@ import {
 } from "@/lib/public-api-pagination";
 import { reportServerError } from "@/lib/server-errors";
 import { queryServiceRevisionChangelog } from "@/lib/service-revision-changelog";
+import { isBuiltServiceSourceType } from "@/lib/service-revision-spec";
+import {
+	MAX_RUN_COMMAND_LENGTH,
+	MAX_RUN_TIMEOUT_SECONDS,
+	startServiceRun,
+} from "@/lib/service-runs";
+import { triggerLocalBuildInternal } from "@/lib/trigger-build";
 import {
+	type BuildLog,
 	isLoggingEnabled,
 	isPublicServiceLogEventId,
 	type PublicServiceLogCursor,
+	queryLogsByBuild,
 	queryLogsByRollout,
 	queryPublicServiceLogs,
 	ServiceLogCursorUnavailableError,
@@ -133,6 +171,7 @@ export async function getServiceDetails(
 						: current.placement,
 				healthCheck: current.healthCheck,
 				startCommand: current.startCommand,
+				releaseCommand: current.releaseCommand,
 				resources:
 					current.resources.cpuCores == null ? null : current.resources,
 				crons: current.crons,
@@ -267,6 +306,11 @@ const safeBuild = {
 	author: builds.author,
 	status: builds.status,
 	targetPlatform: builds.targetPlatform,
+	cacheHits: builds.cacheHits,
+	cacheSteps: builds.cacheSteps,
+	attestations: builds.attestations,
+	vulnerabilities: builds.vulnerabilities,
+	rolloutBlockedReason: builds.rolloutBlockedReason,
 	startedAt: builds.startedAt,
 	completedAt: builds.completedAt,
 	createdAt: builds.createdAt,
@@ -325,7 +369,9 @@ export async function getStatus(
 				name: scope.service.name,
 				source,
 			},
-			latestBuild: scope.service.sourceType === "github" ? latestBuild : null,
+			latestBuild: isBuiltServiceSourceType(scope.service.sourceType)
+				? latestBuild
+				: null,
 			latestRollout,
 			deployments: persisted,
 		});
@@ -337,7 +383,7 @@ export async function getStatus(
 function deployConflict(error: unknown) {
 	const message = error instanceof Error ? error.message : "Deployment failed";
 	if (
-		/replica|placement|server|migration|GitHub repository|GitHub service/i.test(
+		/replica|placement|server|migration|GitHub repository|GitHub service|git source|git repository|git branch/i.test(
 			message,
 		)
 	) {
@@ -351,12 +397,41 @@ function deployConflict(error: unknown) {
 	);
 }
 
+const deployRequestSchema = z
+	.object({
+		commitSha: z
+			.string()
+			.regex(/^[0-9a-f]{40}$/, "commitSha must be a full 40-character SHA")
+			.optional(),
+	})
+	.strict();
+
 export async function postDeploy(
 	request: Request,
 	context: PublicServiceContext,
 ) {
 	const scope = await writeScope(request, context);
 	if ("response" in scope) return scope.response;
+	const body = await request.text().catch(() => "");
+	let payload: unknown = {};
+	if (body.trim()) {
+		try {
+			payload = JSON.parse(body);
+		} catch {
+			return badRequest("Deploy body must be JSON");
+		}
+	}
+	const parsed = deployRequestSchema.safeParse(payload);
+	if (!parsed.success) {
+		return badRequest(parsed.error.issues[0]?.message ?? "Invalid deploy");
+	}
+	const { commitSha } = parsed.data;
+	if (commitSha && scope.service.sourceType !== "git") {
+		return badRequest(
+			"Only git sources accept an explicit build commit",
+			"COMMIT_NOT_SUPPORTED",
+		);
+	}
 	const actor = {
 		type: "user" as const,
 		userId: scope.auth.session.user.id,
@@ -365,9 +440,10 @@ export async function postDeploy(
 	try {
 		const result = await deployServiceInternal(scope.service.id, actor, {
 			githubTrigger: "manual",
+			commitSha,
 		});
 		if (
-			scope.service.sourceType === "github" &&
+			isBuiltServiceSourceType(scope.service.sourceType) &&
 			!("migrationStarted" in result)
 		) {
 			return Response.json(
@@ -394,6 +470,68 @@ export async function postDeploy(
 	}
 }
 
+export async function postLocalDeploy(
+	request: Request,
+	context: PublicServiceContext,
+) {
+	const scope = await writeScope(request, context);
+	if ("response" in scope) return scope.response;
+	if (scope.service.previewOfService) {
+		return apiError(
+			"Preview services build from their pull request",
+			"DEPLOYMENT_CONFLICT",
+			409,
+		);
+	}
+	const declaredSize = Number(request.headers.get("content-length") ?? 0);
+	if (declaredSize > MAX_BUILD_CONTEXT_BYTES) {
+		return apiError(
+			"Build context exceeds the 100 MiB limit",
+			"BUILD_CONTEXT_TOO_LARGE",
+			413,
+		);
+	}
+	const data = Buffer.from(await request.arrayBuffer());
+	if (data.length > MAX_BUILD_CONTEXT_BYTES) {
+		return apiError(
+			"Build context exceeds the 100 MiB limit",
+			"BUILD_CONTEXT_TOO_LARGE",
+			413,
+		);
+	}
+	if (!isGzipArchive(data)) {
+		return badRequest(
+			"Build context must be a gzip-compressed tar archive",
+			"INVALID_BUILD_CONTEXT",
+		);
+	}
+	const actor = {
+		type: "user" as const,
+		userId: scope.auth.session.user.id,
+		name: scope.auth.session.user.name,
+	};
+	try {
+		const stored = await storeBuildContext(scope.service.id, data);
+		const result = await triggerLocalBuildInternal(scope.service.id, {
+			contextId: stored.id,
+			checksum: stored.checksum,
+			actor,
+		});
+		return Response.json(
+			{
+				operation: "build",
+				status: "build_queued",
+				rolloutId: null,
+				buildId: result.buildId,
+				contextChecksum: stored.checksum,
+			},
+			{ status: 202 },
+		);
+	} catch (error) {
+		return deployConflict(error);
+	}
+}
+
 function waitForPoll(delayMs: number, signal?: AbortSignal) {
 	if (signal?.aborted || delayMs <= 0) return Promise.resolve();
 	return new Promise<void>((resolve) => {
@@ -537,6 +675,16 @@ export function nextServiceLogCursor(
 	});
 }
 
+function nextBuildLogCursor(logs: BuildLog[], rawCursor: string | null) {
+	const last = logs.at(-1);
+	if (!last) return rawCursor;
+	return encodeServiceLogCursor({
+		v: 1,
+		t: last._time,
+		e: isPublicServiceLogEventId(last.event_id) ? last.event_id : "",
+	});
+}
+
 export async function getServiceLogs(
 	request: Request,
 	context: PublicServiceContext,
@@ -756,7 +904,20 @@ export async function getBuilds(
 ) {
 	const scope = await readScope(request, context);
 	if ("response" in scope) return scope.response;
-	if (scope.service.sourceType !== "github") {
+	let hasBuildHistory = isBuiltServiceSourceType(scope.service.sourceType);
+	if (!hasBuildHistory) {
+		try {
+			hasBuildHistory = await db
+				.select({ id: builds.id })
+				.from(builds)
+				.where(eq(builds.serviceId, scope.service.id))
+				.limit(1)
+				.then((rows) => rows.length > 0);
+		} catch (error) {
+			return internalError(error, "list builds");
+		}
+	}
+	if (!hasBuildHistory) {
 		return Response.json({
 			supported: false,
 			reason: "IMAGE_SOURCE",
@@ -794,6 +955,166 @@ export async function getBuilds(
 	}
 }
 
+type BuildContext = PublicServiceContext & {
+	params: Promise<PublicServiceParams & { buildId: string }>;
+};
+
+async function findServiceBuild(serviceId: string, context: BuildContext) {
+	const buildId = (await context.params).buildId;
+	return db
+		.select({
+			id: builds.id,
+			status: builds.status,
+			serviceId: builds.serviceId,
+			serviceRevisionId: builds.serviceRevisionId,
+			commitMessage: builds.commitMessage,
+			author: builds.author,
+			completedAt: builds.completedAt,
+		})
+		.from(builds)
+		.where(and(eq(builds.id, buildId), eq(builds.serviceId, serviceId)))
+		.limit(1)
+		.then((rows) => rows[0]);
+}
+
+function buildControlResponse(error: unknown, operation: string) {
+	if (error instanceof BuildControlError) {
+		return apiError(error.message, error.code, error.status);
+	}
+	return internalError(error, operation);
+}
+
+export async function postBuildRebuild(
+	request: Request,
+	context: BuildContext,
+) {
+	const scope = await writeScope(request, context);
+	if ("response" in scope) return scope.response;
+	try {
+		const build = await findServiceBuild(scope.service.id, context);
+		if (!build) return notFound();
+		if (cancellableBuildStatuses.includes(build.status)) {
+			return apiError(
+				`Cannot rebuild build in ${build.status} status`,
+				"BUILD_IN_PROGRESS",
+				409,
+			);
+		}
+		const result = await rebuildBuildInternal(build, {
+			type: "user",
+			userId: scope.auth.session.user.id,
+			name: scope.auth.session.user.name,
+		});
+		return Response.json(
+			{
+				operation: "build",
+				status: "build_queued",
+				rolloutId: null,
+				buildId: null,
+				rebuildOf: build.id,
+				serviceRevisionId: result.serviceRevisionId,
+			},
+			{ status: 202 },
+		);
+	} catch (error) {
+		return buildControlResponse(error, "rebuild build");
+	}
+}
+
+export async function postBuildCancel(
+	request: Request,
+	context: BuildContext,
+) {
+	const scope = await writeScope(request, context);
+	if ("response" in scope) return scope.response;
+	try {
+		const build = await findServiceBuild(scope.service.id, context);
+		if (!build) return notFound();
+		await cancelBuildInternal(build.id);
+		return Response.json({ build: { id: build.id, status: "cancelled" } });
+	} catch (error) {
+		return buildControlResponse(error, "cancel build");
+	}
+}
+
+// Agents flush build logs shortly after their last status update, so a
+// finished build is only reported done once it has been quiet this long.
+const BUILD_LOG_SETTLE_MS = 5_000;
+
+export async function getBuildLogs(request: Request, context: BuildContext) {
+	const scope = await readScope(request, context);
+	if ("response" in scope) return scope.response;
+	let build: Awaited<ReturnType<typeof findServiceBuild>>;
+	try {
+		build = await findServiceBuild(scope.service.id, context);
+		if (!build) return notFound();
+	} catch (error) {
+		return internalError(error, "resolve build logs scope");
+	}
+	if (!isLoggingEnabled()) {
+		return Response.json({
+			provider: "disabled",
+			build: { id: build.id, status: build.status },
+			logs: [],
+			nextCursor: null,
+			done: true,
+			pollAfterMs: 0,
+		});
+	}
+	const buildId = build.id;
+	try {
+		const url = new URL(request.url);
+		const rawCursor = url.searchParams.get("cursor");
+		const cursor = decodeServiceLogCursor(rawCursor);
+		if (cursor === null) throw new RangeError("Invalid log cursor");
+		const limit = parseLogLimit(url.searchParams.get("limit"), 500);
+		const waitRaw = url.searchParams.get("wait");
+		const wait = waitRaw === null ? 0 : Number(waitRaw);
+		if (!Number.isInteger(wait) || wait < 0 || wait > 20) {
+			throw new RangeError("wait must be an integer from 0 to 20");
+		}
+		const query = () =>
+			queryLogsByBuild(buildId, {
+				limit,
+				after: cursor && { time: cursor.t, eventId: cursor.e },
+				search: normalizeLogSearch(url.searchParams.get("q")),
+				signal: request.signal,
+			});
+		const result =
+			wait > 0 && cancellableBuildStatuses.includes(build.status)
+				? await longPollLogs(query, {
+						waitMs: wait * 1000,
+						signal: request.signal,
+					})
+				: await query();
+		const current =
+			(await findServiceBuild(scope.service.id, context)) ?? build;
+		const settled =
+			!cancellableBuildStatuses.includes(current.status) &&
+			(current.completedAt === null ||
+				Date.now() - current.completedAt.getTime() > BUILD_LOG_SETTLE_MS);
+		const done = settled && result.logs.length < limit;
+		return Response.json({
+			provider: "enabled",
+			build: { id: current.id, status: current.status },
+			logs: result.logs.map((log) => ({
+				message: log._msg,
+				timestamp: log._time,
+			})),
+			nextCursor: nextBuildLogCursor(result.logs, rawCursor),
+			done,
+			pollAfterMs: done ? 0 : result.logs.length > 0 ? 250 : 1000,
+		});
+	} catch (error) {
+		if (request.signal.aborted) return new Response(null, { status: 499 });
+		if (error instanceof RangeError) return invalidLogQuery(error);
+		reportServerError(error, "public-api.build-logs.query", {
+			tags: { serviceId: scope.service.id, buildId },
+		});
+		return apiError("Log provider unavailable", "LOG_PROVIDER_ERROR", 502);
+	}
+}
+
 export async function getMetrics(
 	request: Request,
 	context: PublicServiceContext,
@@ -864,3 +1185,415 @@ export async function getRevisions(
 		return internalError(error, "list revisions");
 	}
 }
+
+export async function getCrons(
+	request: Request,
+	context: PublicServiceContext,
+) {
+	const scope = await readScope(request, context);
+	if ("response" in scope) return scope.response;
+	const rawLimit = new URL(request.url).searchParams.get("limit");
+	const limit = rawLimit === null ? 10 : Number(rawLimit);
+	if (!Number.isInteger(limit) || limit < 1 || limit > 100) {
+		return badRequest(
+			"limit must be an integer from 1 to 100",
+			"INVALID_LIMIT",
+		);
+	}
+	try {
+		const crons = await db
+			.select({
+				id: serviceCrons.id,
+				path: serviceCrons.path,
+				schedule: serviceCrons.schedule,
+				timeZone: serviceCrons.timeZone,
+				timeoutSeconds: serviceCrons.timeoutSeconds,
+				retries: serviceCrons.retries,
+				retryBackoffSeconds: serviceCrons.retryBackoffSeconds,
+				concurrencyPolicy: serviceCrons.concurrencyPolicy,
+				nextScheduledFor: serviceCrons.nextScheduledFor,
+				lastStatus: serviceCrons.lastStatus,
+				lastFinishedAt: serviceCrons.lastFinishedAt,
+			})
+			.from(serviceCrons)
+			.where(eq(serviceCrons.serviceId, scope.service.id))
+			.orderBy(asc(serviceCrons.path));
+		const runs = await Promise.all(
+			crons.map((cron) =>
+				db
+					.select({
+						id: serviceCronRuns.id,
+						source: serviceCronRuns.source,
+						scheduledFor: serviceCronRuns.scheduledFor,
+						attempt: serviceCronRuns.attempt,
+						status: serviceCronRuns.status,
+						statusCode: serviceCronRuns.statusCode,
+						durationMs: serviceCronRuns.durationMs,
+						responseSnippet: serviceCronRuns.responseSnippet,
+						error: serviceCronRuns.error,
+						startedAt: serviceCronRuns.startedAt,
+						finishedAt: serviceCronRuns.finishedAt,
+					})
+					.from(serviceCronRuns)
+					.where(eq(serviceCronRuns.cronId, cron.id))
+					.orderBy(
+						desc(serviceCronRuns.startedAt),
+						desc(serviceCronRuns.id),
+					)
+					.limit(limit),
+			),
+		);
+		return Response.json({
+			crons: crons.map((cron, index) => ({ ...cron, runs: runs[index] })),
+		});
+	} catch (error) {
+		return internalError(error, "list crons");
+	}
+}
+
+const runRequestSchema = z.object({
+	command: z
+		.string()
+		.refine(
+			(value) =>
+				value.trim().length > 0 && value.length <= MAX_RUN_COMMAND_LENGTH,
+			`command must be 1-${MAX_RUN_COMMAND_LENGTH} characters`,
+		),
+	server: z.string().trim().min(1).optional(),
+	timeoutSeconds: z
+		.number()
+		.int()
+		.min(1)
+		.max(MAX_RUN_TIMEOUT_SECONDS)
+		.optional(),
+});
+
+const safeRun = {
+	id: serviceRuns.id,
+	deploymentId: serviceRuns.deploymentId,
+	serverId: serviceRuns.serverId,
+	serverName: serviceRuns.serverName,
+	command: serviceRuns.command,
+	timeoutSeconds: serviceRuns.timeoutSeconds,
+	status: serviceRuns.status,
+	exitCode: serviceRuns.exitCode,
+	errorMessage: serviceRuns.errorMessage,
+	createdAt: serviceRuns.createdAt,
+	startedAt: serviceRuns.startedAt,
+	completedAt: serviceRuns.completedAt,
+};
+
+type RunContext = PublicServiceContext & {
+	params: Promise<PublicServiceParams & { runId: string }>;
+};
+
+export async function postRun(
+	request: Request,
+	context: PublicServiceContext,
+) {
+	const scope = await writeScope(request, context);
+	if ("response" in scope) return scope.response;
+	const parsed = runRequestSchema.safeParse(
+		await request.json().catch(() => null),
+	);
+	if (!parsed.success) {
+		return badRequest(parsed.error.issues[0]?.message ?? "Invalid run");
+	}
+	try {
+		const run = await startServiceRun(
+			scope.service,
+			{ id: scope.auth.session.user.id, name: scope.auth.session.user.name },
+			parsed.data,
+		);
+		return Response.json({ run }, { status: 202 });
+	} catch (error) {
+		return isPublicApiDomainError(error)
+			? publicApiDomainResponse(error)
+			: internalError(error, "start run");
+	}
+}
+
+async function findRun(serviceId: string, context: RunContext) {
+	const { runId } = await context.params;
+	return db
+		.select(safeRun)
+		.from(serviceRuns)
+		.where(
+			and(eq(serviceRuns.id, runId), eq(serviceRuns.serviceId, serviceId)),
+		)
+		.then((rows) => rows[0] ?? null);
+}
+
+export async function getRun(request: Request, context: RunContext) {
+	const scope = await readScope(request, context);
+	if ("response" in scope) return scope.response;
+	try {
+		const run = await findRun(scope.service.id, context);
+		return run ? Response.json({ run }) : notFound();
+	} catch (error) {
+		return internalError(error, "read run");
+	}
+}
+
+export async function getRunLogs(request: Request, context: RunContext) {
+	const scope = await readScope(request, context);
+	if ("response" in scope) return scope.response;
+	let runId: string;
+	try {
+		const run = await findRun(scope.service.id, context);
+		if (!run) return notFound();
+		runId = run.id;
+	} catch (error) {
+		return internalError(error, "resolve run logs scope");
+	}
+	if (!isLoggingEnabled()) {
+		return Response.json({
+			provider: "disabled",
+			logs: [],
+			nextCursor: null,
+			hasMore: false,
+			pollAfterMs: 2000,
+		});
+	}
+	try {
+		const options = logOptions(new URL(request.url));
+		const query = () =>
+			queryPublicServiceLogs({
+				serviceId: scope.service.id,
+				deploymentId: runId,
+				logType: "container",
+				limit: options.limit,
+				cursor: options.cursor
+					? ({
+							time: options.cursor.t,
+							eventId: options.cursor.e,
+						} satisfies PublicServiceLogCursor)
+					: undefined,
+				range: options.range,
+				search: options.search,
+				signal: request.signal,
+			});
+		const result =
+			options.cursor && options.wait > 0
+				? await longPollLogs(query, {
+						waitMs: options.wait * 1000,
+						signal: request.signal,
+					})
+				: await query();
+		return Response.json({
+			provider: "enabled",
+			logs: result.logs.map(publicServiceLog),
+			nextCursor: nextServiceLogCursor(result.logs, options.rawCursor),
+			hasMore: result.hasMore,
+			pollAfterMs: result.hasMore ? 0 : result.logs.length > 0 ? 250 : 1000,
+		});
+	} catch (error) {
+		if (request.signal.aborted) return new Response(null, { status: 499 });
+		if (error instanceof ServiceLogCursorUnavailableError) {
+			return apiError(error.message, "LOG_CURSOR_UNAVAILABLE", 409);
+		}
+		if (error instanceof RangeError) return invalidLogQuery(error);
+		reportServerError(error, "public-api.run-logs.query", {
+			tags: { serviceId: scope.service.id, runId },
+		});
+		return apiError("Log provider unavailable", "LOG_PROVIDER_ERROR", 502);
+	}
+}
+
+const gitCredentialSchema = z.discriminatedUnion("type", [
+	z
+		.object({
+			type: z.literal("https_token"),
+			username: z.string().trim().max(255).nullable().optional(),
+			token: z.string().min(1, "token is required").max(4096),
+		})
+		.strict(),
+	z
+		.object({
+			type: z.literal("ssh_key"),
+			privateKey: z
+				.string()
+				.max(16384)
+				.refine(
+					(value) => /-----BEGIN [A-Z ]*PRIVATE KEY-----/.test(value),
+					"privateKey must be a PEM or OpenSSH private key",
+				),
+			knownHosts: z
+				.string()
+				.trim()
+				.min(1, "knownHosts is required for ssh_key credentials")
+				.max(16384),
+		})
+		.strict(),
+]);
+
+export async function getGitCredentials(
+	request: Request,
+	context: PublicServiceContext,
+) {
+	const scope = await readScope(request, context);
+	if ("response" in scope) return scope.response;
+	try {
+		const credential = await getServiceGitCredential(scope.service.id);
+		return Response.json({ credential: describeGitCredential(credential) });
+	} catch (error) {
+		return internalError(error, "read git credentials");
+	}
+}
+
+export async function putGitCredentials(
+	request: Request,
+	context: PublicServiceContext,
+) {
+	const scope = await writeScope(request, context);
+	if ("response" in scope) return scope.response;
+	const parsed = gitCredentialSchema.safeParse(
+		await request.json().catch(() => null),
+	);
+	if (!parsed.success) {
+		return badRequest(
+			parsed.error.issues[0]?.message ?? "Invalid git credentials",
+		);
+	}
+	try {
+		const credential = await setServiceGitCredential(
+			scope.service.id,
+			parsed.data,
+		);
+		return Response.json({ credential: describeGitCredential(credential) });
+	} catch (error) {
+		return internalError(error, "update git credentials");
+	}
+}
+
+export async function deleteGitCredentials(
+	request: Request,
+	context: PublicServiceContext,
+) {
+	const scope = await writeScope(request, context);
+	if ("response" in scope) return scope.response;
+	try {
+		const deleted = await deleteServiceGitCredential(scope.service.id);
+		return Response.json({ deleted });
+	} catch (error) {
+		return internalError(error, "delete git credentials");
+	}
+}
+
+const vulnerabilityPolicySchema = z.strictObject({
+	maxCritical: z.number().int().min(0).max(100_000).nullable(),
+});
+
+export async function getVulnerabilityPolicy(
+	request: Request,
+	context: PublicServiceContext,
+) {
+	const scope = await readScope(request, context);
+	if ("response" in scope) return scope.response;
+	return Response.json({
+		policy: { maxCritical: scope.service.vulnerabilityMaxCritical },
+	});
+}
+
+export async function putVulnerabilityPolicy(
+	request: Request,
+	context: PublicServiceContext,
+) {
+	const scope = await writeScope(request, context);
+	if ("response" in scope) return scope.response;
+	const parsed = vulnerabilityPolicySchema.safeParse(
+		await request.json().catch(() => null),
+	);
+	if (!parsed.success) {
+		return badRequest(
+			parsed.error.issues[0]?.message ?? "Invalid vulnerability policy",
+		);
+	}
+	try {
+		await db
+			.update(services)
+			.set({ vulnerabilityMaxCritical: parsed.data.maxCritical })
+			.where(eq(services.id, scope.service.id));
+		return Response.json({ policy: parsed.data });
+	} catch (error) {
+		return internalError(error, "update vulnerability policy");
+	}
+}
+
+export type PublicProjectContext = {
+	params: Promise<{ projectId: string }>;
+};
+
+async function findProjectSigningPolicy(projectId: string) {
+	return db
+		.select({ id: projects.id, policy: projects.imageSigningPolicy })
+		.from(projects)
+		.where(eq(projects.id, projectId))
+		.then((rows) => rows[0]);
+}
+
+export async function getSigningPolicy(
+	request: Request,
+	context: PublicProjectContext,
+) {
+	const auth = await requireApiKeyRole(request, [...readRoles]);
+	if (!auth.ok) return auth.response;
+	try {
+		const { projectId } = await context.params;
+		const project = await findProjectSigningPolicy(projectId);
+		if (!project) return notFound();
+		return Response.json({ policy: project.policy ?? null });
+	} catch (error) {
+		return internalError(error, "read signing policy");
+	}
+}
+
+// Only admins change signing policies: a developer key that could relax the
+// policy could also deploy the unsigned image it is meant to stop.
+export async function putSigningPolicy(
+	request: Request,
+	context: PublicProjectContext,
+) {
+	const auth = await requireApiKeyRole(request, ["admin"]);
+	if (!auth.ok) return auth.response;
+	const parsed = imageSigningPolicySchema.safeParse(
+		await request.json().catch(() => null),
+	);
+	if (!parsed.success) {
+		return badRequest(
+			parsed.error.issues[0]?.message ?? "Invalid signing policy",
+		);
+	}
+	try {
+		const { projectId } = await context.params;
+		const project = await findProjectSigningPolicy(projectId);
+		if (!project) return notFound();
+		await db
+			.update(projects)
+			.set({ imageSigningPolicy: parsed.data })
+			.where(eq(projects.id, project.id));
+		return Response.json({ policy: parsed.data });
+	} catch (error) {
+		return internalError(error, "update signing policy");
+	}
+}
+
+export async function deleteSigningPolicy(
+	request: Request,
+	context: PublicProjectContext,
+) {
+	const auth = await requireApiKeyRole(request, ["admin"]);
+	if (!auth.ok) return auth.response;
+	try {
+		const { projectId } = await context.params;
+		const project = await findProjectSigningPolicy(projectId);
+		if (!project) return notFound();
+		await db
+			.update(projects)
+			.set({ imageSigningPolicy: null })
+			.where(eq(projects.id, project.id));
+		return Response.json({ deleted: project.policy !== null });
+	} catch (error) {
+		return internalError(error, "delete signing policy");
+	}
+}
@@ -1,4 +1,4 @@
-import { createHash, randomUUID } from "node:crypto";
+import { createHash, randomUUID, X509Certificate } from "node:crypto";
 import {
 	and,
 	desc,
@@ -38,15 +38,74 @@ import {
 const githubPathPart = /^[A-Za-z0-9_.-]+$/;
 const windowsAbsolutePath = /^[A-Za-z]:[\\/]/;
 
-export function nextOccurrenceAfter(schedule: string, after: Date): Date {
+export function nextOccurrenceAfter(
+	schedule: string,
+	after: Date,
+	timeZone = "UTC",
+): Date {
 	return CronExpressionParser.parse(schedule, {
 		currentDate: after,
-		tz: "UTC",
+		tz: timeZone,
 	})
 		.next()
 		.toDate();
 }
 
+export function isValidTimeZone(value: string): boolean {
+	try {
+		new Intl.DateTimeFormat("en-US", { timeZone: value });
+		return true;
+	} catch {
+		return false;
+	}
+}
+
+export const CRON_DEFAULTS = {
+	timeZone: "UTC",
+	timeoutSeconds: 10,
+	retries: 0,
+	retryBackoffSeconds: 10,
+	concurrencyPolicy: "forbid",
+} as const;
+
+export type CronPolicy = {
+	timeZone: string;
+	timeoutSeconds: number;
+	retries: number;
+	retryBackoffSeconds: number;
+	concurrencyPolicy: "allow" | "forbid" | "replace";
+};
+
+type CronDefinition = { path: string; schedule: string } & Partial<CronPolicy>;
+
+// Policy fields at their default value are left out so existing manifests and
+// configuration fingerprints stay stable.
+export function canonicalCron({
+	path,
+	schedule,
+	...policy
+}: CronDefinition): CronDefinition {
+	const cron: CronDefinition = { path, schedule };
+	for (const key of Object.keys(CRON_DEFAULTS) as Array<keyof CronPolicy>) {
+		const value = policy[key];
+		if (value !== undefined && value !== CRON_DEFAULTS[key])
+			Object.assign(cron, { [key]: value });
+	}
+	return cron;
+}
+
+export function resolveCronPolicy(cron: Partial<CronPolicy>): CronPolicy {
+	return {
+		timeZone: cron.timeZone ?? CRON_DEFAULTS.timeZone,
+		timeoutSeconds: cron.timeoutSeconds ?? CRON_DEFAULTS.timeoutSeconds,
+		retries: cron.retries ?? CRON_DEFAULTS.retries,
+		retryBackoffSeconds:
+			cron.retryBackoffSeconds ?? CRON_DEFAULTS.retryBackoffSeconds,
+		concurrencyPolicy:
+			cron.concurrencyPolicy ?? CRON_DEFAULTS.concurrencyPolicy,
+	};
+}
+
 export function isSafeCronPath(value: string): boolean {
 	if (!value.startsWith("/") || value.startsWith("//") || value.length > 2048)
 		return false;
@@ -89,6 +148,17 @@ export const publicCronSchema = z.strictObject({
 				return false;
 			}
 		}, "Invalid UTC cron expression"),
+	timeZone: z
+		.string()
+		.trim()
+		.min(1)
+		.max(64)
+		.refine(isValidTimeZone, "Invalid time zone")
+		.optional(),
+	timeoutSeconds: z.number().int().min(1).max(300).optional(),
+	retries: z.number().int().min(0).max(10).optional(),
+	retryBackoffSeconds: z.number().int().min(1).max(3600).optional(),
+	concurrencyPolicy: z.enum(["allow", "forbid", "replace"]).optional(),
 });
 
 export function canonicalGitHubRepository(value: string): string {
@@ -131,6 +201,74 @@ export function canonicalGitHubRepository(value: string): string {
 	return `https://github.com/${owner}/${repository}`;
 }
 
+const gitHostPattern = /^[A-Za-z0-9](?:[A-Za-z0-9.-]*[A-Za-z0-9])?$/;
+const gitUserPattern = /^[A-Za-z0-9_][A-Za-z0-9._-]*$/;
+const scpLikeGitRepository = /^([^@:/\s]+)@([^:/\s]+):([^\s]+)$/;
+
+function isSafeGitRepositoryPath(path: string): boolean {
+	const parts = path.split("/").filter(Boolean);
+	return (
+		parts.length > 0 &&
+		!/[\s\\\u0000-\u001f\u007f]/.test(path) &&
+		!parts.some((part) => part === "." || part === "..")
+	);
+}
+
+/**
+ * Normalizes a generic git remote. HTTPS remotes may not embed credentials;
+ * SSH remotes are accepted as ssh:// URLs or the scp-like user@host:path form.
+ */
+export function canonicalGitRepository(value: string): string {
+	const trimmed = value.trim();
+	const scpLike = scpLikeGitRepository.exec(trimmed);
+	if (scpLike) {
+		const [, user, host, path] = scpLike;
+		if (
+			!gitUserPattern.test(user) ||
+			!gitHostPattern.test(host) ||
+			!isSafeGitRepositoryPath(path)
+		) {
+			throw new Error("Invalid git repository");
+		}
+		return `${user}@${host.toLowerCase()}:${path}`;
+	}
+
+	let url: URL;
+	try {
+		url = new URL(trimmed);
+	} catch {
+		throw new Error("Invalid git repository URL");
+	}
+	if (url.protocol !== "https:" && url.protocol !== "ssh:") {
+		throw new Error("Git repositories must use HTTPS or SSH");
+	}
+	if (
+		url.password ||
+		(url.protocol === "https:" && url.username) ||
+		url.search ||
+		url.hash
+	) {
+		throw new Error(
+			"Repository must not contain credentials, query, or fragment",
+		);
+	}
+	const path = url.pathname.replace(/\/+$/, "");
+	if (
+		(url.username && !gitUserPattern.test(url.username)) ||
+		!gitHostPattern.test(url.hostname) ||
+		!isSafeGitRepositoryPath(path)
+	) {
+		throw new Error("Invalid git repository URL");
+	}
+	const user = url.username ? `${url.username}@` : "";
+	const port = url.port ? `:${url.port}` : "";
+	return `${url.protocol}//${user}${url.hostname.toLowerCase()}${port}${path}`;
+}
+
+export function isSshGitRepository(repository: string): boolean {
+	return !repository.startsWith("https://");
+}
+
 export function isSafeRepositoryRoot(value: string): boolean {
 	const rootDir = value.trim();
 	if (
@@ -166,6 +304,60 @@ const githubRepositorySchema = z
 	}, "Invalid GitHub repository URL")
 	.transform(canonicalGitHubRepository);
 
+const gitRepositorySchema = z
+	.string()
+	.trim()
+	.max(2048)
+	.refine((value) => {
+		try {
+			canonicalGitRepository(value);
+			return true;
+		} catch {
+			return false;
+		}
+	}, "Invalid git repository URL")
+	.transform(canonicalGitRepository);
+
+export const MAX_BUILD_ARGS = 64;
+const buildArgNamePattern = /^[A-Za-z_][A-Za-z0-9_]{0,127}$/;
+const buildTargetPattern = /^[A-Za-z0-9][A-Za-z0-9_.-]{0,127}$/;
+
+const buildOptionFields = {
+	dockerfile: z
+		.string()
+		.trim()
+		.max(512)
+		.refine(
+			isSafeRepositoryRoot,
+			"dockerfile must be relative to rootDir and cannot contain '..'",
+		)
+		.transform((value) => value.replaceAll("\\", "/"))
+		.nullable()
+		.default(null),
+	target: z
+		.string()
+		.trim()
+		.regex(buildTargetPattern, "target must be a Dockerfile stage name")
+		.nullable()
+		.default(null),
+	buildArgs: z
+		.record(
+			z.string().regex(buildArgNamePattern, "Invalid build argument name"),
+			z
+				.string()
+				.max(4096)
+				.refine(
+					(value) => !value.includes("\0"),
+					"Build argument values cannot contain NUL",
+				),
+		)
+		.refine(
+			(args) => Object.keys(args).length <= MAX_BUILD_ARGS,
+			`At most ${MAX_BUILD_ARGS} build arguments are allowed`,
+		)
+		.default({}),
+};
+
 export const publicSourceSchema = z.discriminatedUnion("type", [
 	z.strictObject({
 		type: z.literal("image"),
@@ -176,17 +368,57 @@ export const publicSourceSchema = z.discriminatedUnion("type", [
 		repository: githubRepositorySchema,
 		branch: z.string().trim().min(1).max(255),
 		rootDir: rootDirSchema.nullable(),
+		...buildOptionFields,
+	}),
+	z.strictObject({
+		type: z.literal("git"),
+		repository: gitRepositorySchema,
+		branch: z.string().trim().min(1).max(255),
+		rootDir: rootDirSchema.nullable(),
+		submodules: z.boolean().default(false),
+		lfs: z.boolean().default(false),
+		...buildOptionFields,
 	}),
 ]);
 
+export type PublicBuildOptions = {
+	dockerfile: string | null;
+	target: string | null;
+	buildArgs: Record<string, string>;
+};
+
 export type PublicSource =
 	| { type: "image"; image: string }
-	| {
+	| ({
 			type: "github";
 			repository: string | null;
 			branch: string;
 			rootDir: string | null;
-	  };
+	  } & PublicBuildOptions)
+	| ({
+			type: "git";
+			repository: string | null;
+			branch: string;
+			rootDir: string | null;
+			submodules: boolean;
+			lfs: boolean;
+	  } & PublicBuildOptions);
+
+function sortedBuildArgs(
+	args: Record<string, string> | null | undefined,
+): Record<string, string> {
+	return Object.fromEntries(
+		Object.entries(args ?? {}).toSorted(([a], [b]) => a.localeCompare(b, "en")),
+	);
+}
+
+function persistedBuildOptions(service: NestedService): PublicBuildOptions {
+	return {
+		dockerfile: service.buildDockerfile?.trim() || null,
+		target: service.buildTarget?.trim() || null,
+		buildArgs: sortedBuildArgs(service.buildArgs),
+	};
+}
 export type NestedService = typeof services.$inferSelect;
 type GitHubRepo = typeof githubRepos.$inferSelect;
 
@@ -218,6 +450,25 @@ export function resolvePersistedSourceFromRows(
 	if (service.sourceType === "image") {
 		return { type: "image", image: service.image };
 	}
+	if (service.sourceType === "git") {
+		let repository: string | null = null;
+		try {
+			repository = service.gitRepoUrl
+				? canonicalGitRepository(service.gitRepoUrl)
+				: null;
+		} catch {
+			repository = null;
+		}
+		return {
+			type: "git",
+			repository,
+			branch: service.gitBranch?.trim() || "main",
+			rootDir: service.gitRootDir?.trim() || null,
+			submodules: service.gitSubmodules,
+			lfs: service.gitLfs,
+			...persistedBuildOptions(service),
+		};
+	}
 	return {
 		type: "github",
 		repository: resolveRepository(service, repo),
@@ -227,14 +478,15 @@ export function resolvePersistedSourceFromRows(
 			service.githubBranch?.trim() ||
 			"main",
 		rootDir: service.githubRootDir?.trim() || null,
+		...persistedBuildOptions(service),
 	};
 }
 
 export async function resolvePersistedSource(
 	service: NestedService,
 ): Promise<PublicSource> {
-	if (service.sourceType === "image") {
-		return { type: "image", image: service.image };
+	if (service.sourceType !== "github") {
+		return resolvePersistedSourceFromRows(service, undefined);
 	}
 	const repo = await db
 		.select()
@@ -336,11 +588,30 @@ function getManagementBlockers(input: {
 			});
 		}
 	}
+	if (input.source.type === "git") {
+		if (!input.source.repository) {
+			blockers.push({
+				code: "INCOMPLETE_GIT_SOURCE",
+				message: "The configured git repository is missing or invalid",
+			});
+		}
+		if (input.source.rootDir && !isSafeRepositoryRoot(input.source.rootDir)) {
+			blockers.push({
+				code: "INVALID_GIT_ROOT",
+				message: "The configured git root directory is unsafe",
+			});
+		}
+	}
 	return blockers;
 }
 
 function sanitizeSpec(specification: unknown) {
 	const spec = parseServiceRevisionSpec(specification);
+	const build = {
+		dockerfile: spec.build?.dockerfile ?? null,
+		target: spec.build?.target ?? null,
+		buildArgs: sortedBuildArgs(spec.build?.args),
+	};
 	const replicas = getServiceRevisionTotalReplicas(spec);
 	const placement =
 		spec.placement.mode === "automatic"
@@ -354,8 +625,26 @@ function sanitizeSpec(specification: unknown) {
 						repository: spec.source.repository,
 						branch: spec.source.branch,
 						rootDir: spec.source.rootDir,
+						...build,
 					}
-				: { type: "image" as const, image: spec.source.image },
+				: spec.source.type === "git"
+					? {
+							type: "git" as const,
+							repository: spec.source.repository,
+							branch: spec.source.branch,
+							rootDir: spec.source.rootDir,
+							submodules: spec.source.submodules,
+							lfs: spec.source.lfs,
+							...build,
+						}
+					: spec.source.type === "local"
+						? {
+								type: "local" as const,
+								checksum: spec.source.checksum,
+								rootDir: spec.source.rootDir,
+								...build,
+							}
+						: { type: "image" as const, image: spec.source.image },
 		hostname: spec.hostname,
 		stateful: spec.stateful,
 		placement,
@@ -363,6 +652,7 @@ function sanitizeSpec(specification: unknown) {
 		placements: spec.placements,
 		healthCheck: spec.healthCheck,
 		startCommand: spec.startCommand,
+		releaseCommand: spec.releaseCommand ?? null,
 		resources: spec.resourceLimits,
 		ports: spec.ports.map((port) => ({
 			containerPort: port.containerPort,
@@ -371,6 +661,7 @@ function sanitizeSpec(specification: unknown) {
 			protocol: port.protocol,
 			externalPort: port.externalPort,
 			tlsPassthrough: port.tlsPassthrough,
+			...(port.clientAuth ? { clientAuth: port.clientAuth } : {}),
 		})),
 		volumes: spec.volumes,
 		serverless: spec.serverless,
@@ -391,7 +682,15 @@ export async function safeConfiguration(service: NestedService) {
 				.from(servicePorts)
 				.where(eq(servicePorts.serviceId, service.id)),
 			db
-				.select({ path: serviceCrons.path, schedule: serviceCrons.schedule })
+				.select({
+					path: serviceCrons.path,
+					schedule: serviceCrons.schedule,
+					timeZone: serviceCrons.timeZone,
+					timeoutSeconds: serviceCrons.timeoutSeconds,
+					retries: serviceCrons.retries,
+					retryBackoffSeconds: serviceCrons.retryBackoffSeconds,
+					concurrencyPolicy: serviceCrons.concurrencyPolicy,
+				})
 				.from(serviceCrons)
 				.where(eq(serviceCrons.serviceId, service.id)),
 			db
@@ -442,9 +741,9 @@ export async function safeConfiguration(service: NestedService) {
 			a.name.localeCompare(b.name, "en") ||
 			a.containerPath.localeCompare(b.containerPath, "en"),
 	);
-	const sortedCrons = crons.toSorted((a, b) =>
-		a.path.localeCompare(b.path, "en"),
-	);
+	const sortedCrons = crons
+		.map(canonicalCron)
+		.toSorted((a, b) => a.path.localeCompare(b.path, "en"));
 	const replicaCount = getServiceTotalReplicas({
 		...service,
 		configuredReplicas: sortedPlacements,
@@ -486,6 +785,7 @@ export async function safeConfiguration(service: NestedService) {
 				}
 			: null,
 		startCommand: service.startCommand,
+		releaseCommand: service.releaseCommand,
 		resources: {
 			cpuCores: service.resourceCpuLimit,
 			memoryMb: service.resourceMemoryLimitMb,
@@ -497,6 +797,7 @@ export async function safeConfiguration(service: NestedService) {
 			protocol: port.protocol,
 			externalPort: port.externalPort,
 			tlsPassthrough: port.tlsPassthrough,
+			...persistedClientAuth(port),
 		})),
 		volumes: sortedVolumes,
 		crons: sortedCrons,
@@ -504,6 +805,35 @@ export async function safeConfiguration(service: NestedService) {
 			enabled: service.serverlessEnabled,
 			sleepAfterSeconds: service.serverlessSleepAfterSeconds,
 			wakeTimeoutSeconds: service.serverlessWakeTimeoutSeconds,
+			...(service.serverlessEnabled && service.serverlessAutoscaling
+				? { autoscaling: service.serverlessAutoscaling }
+				: {}),
+			...(service.serverlessEnabled && service.serverlessMaxQueuedRequests
+				? { maxQueuedRequests: service.serverlessMaxQueuedRequests }
+				: {}),
+			...(service.serverlessEnabled && service.serverlessMaxQueueWaitSeconds
+				? { maxQueueWaitSeconds: service.serverlessMaxQueueWaitSeconds }
+				: {}),
+			...(service.serverlessEnabled && service.serverlessKeepWarm?.length
+				? { keepWarm: service.serverlessKeepWarm }
+				: {}),
+			...(service.serverlessEnabled &&
+			service.serverlessStreamIdleTimeoutSeconds
+				? {
+						streamIdleTimeoutSeconds:
+							service.serverlessStreamIdleTimeoutSeconds,
+					}
+				: {}),
+			...(service.serverlessEnabled &&
+			service.serverlessMaxConnectionLifetimeSeconds
+				? {
+						maxConnectionLifetimeSeconds:
+							service.serverlessMaxConnectionLifetimeSeconds,
+					}
+				: {}),
+			...(service.serverlessEnabled && service.serverlessEventPath
+				? { eventPath: service.serverlessEventPath }
+				: {}),
 		},
 		schedules: {
 			deployment: service.deploymentSchedule,
@@ -549,6 +879,7 @@ export async function safeConfiguration(service: NestedService) {
 					},
 		healthCheck: current.healthCheck,
 		startCommand: current.startCommand?.trim() || null,
+		releaseCommand: current.releaseCommand?.trim() || null,
 		resources: current.resources,
 		ports: current.ports,
 		volumes: current.volumes,
@@ -594,6 +925,36 @@ const healthCheckSchema = z.strictObject({
 	retries: z.number().int().min(1),
 	startPeriod: z.number().int().min(0),
 });
+const MAX_CLIENT_CA_CERTIFICATES = 50;
+
+export function isValidClientCaBundle(bundle: string): boolean {
+	const certificates = bundle.match(
+		/-----BEGIN CERTIFICATE-----[\s\S]+?-----END CERTIFICATE-----/g,
+	);
+	if (!certificates || certificates.length > MAX_CLIENT_CA_CERTIFICATES) {
+		return false;
+	}
+	try {
+		for (const certificate of certificates) new X509Certificate(certificate);
+		return true;
+	} catch {
+		return false;
+	}
+}
+
+export const clientAuthSchema = z.strictObject({
+	mode: z.enum(["require", "optional"]).default("require"),
+	caBundle: z
+		.string()
+		.trim()
+		.min(1)
+		.max(64 * 1024)
+		.refine(
+			isValidClientCaBundle,
+			"caBundle must contain PEM encoded CA certificates",
+		),
+});
+
 const portSchema = z
 	.strictObject({
 		containerPort: z.number().int().min(1).max(65535),
@@ -606,8 +967,16 @@ const portSchema = z
 			.transform((value) => value.toLowerCase())
 			.nullable()
 			.optional(),
+		clientAuth: clientAuthSchema.nullable().optional(),
 	})
 	.superRefine((port, context) => {
+		if (port.clientAuth && !port.public) {
+			context.addIssue({
+				code: "custom",
+				path: ["clientAuth"],
+				message: "Client certificates require a public port with a domain",
+			});
+		}
 		if (port.public && !port.domain) {
 			context.addIssue({
 				code: "custom",
@@ -690,6 +1059,7 @@ export const replaceConfigurationSchema = z.strictObject({
 	placement: placementSchema,
 	healthCheck: healthCheckSchema.nullable(),
 	startCommand: z.string().trim().min(1).max(4096).nullable(),
+	releaseCommand: z.string().trim().min(1).max(4096).nullable().optional(),
 	resources: z
 		.strictObject({
 			cpuCores: z.number().min(0.1).max(64).nullable(),
@@ -752,20 +1122,45 @@ type CanonicalReplacementInput = Omit<ReplacementInput, "crons"> & {
 type ConfigurationChange = { field: string; from: unknown; to: unknown };
 
 function canonicalPlanSource(source: PublicSource) {
-	return source.type === "github"
+	if (source.type === "image") return source;
+	return {
+		...source,
+		repository:
+			source.type === "github"
+				? (source.repository?.toLowerCase() ?? null)
+				: source.repository,
+		buildArgs: sortedBuildArgs(source.buildArgs),
+	};
+}
+
+type PersistedClientAuthColumns = {
+	clientAuthMode?: "require" | "optional" | null;
+	clientCaBundle?: string | null;
+};
+
+function persistedClientAuth(port: PersistedClientAuthColumns) {
+	return port.clientAuthMode && port.clientCaBundle
 		? {
-				...source,
-				repository: source.repository?.toLowerCase() ?? null,
+				clientAuth: {
+					mode: port.clientAuthMode,
+					caBundle: port.clientCaBundle,
+				},
 			}
-		: source;
+		: {};
 }
 
 function canonicalReplacementState(
 	service: NestedService,
 	source: ReturnType<typeof resolvePersistedSourceFromRows>,
-	ports: Array<{ port: number; isPublic: boolean; domain: string | null }>,
+	ports: Array<
+		{
+			port: number;
+			isPublic: boolean;
+			domain: string | null;
+		} & PersistedClientAuthColumns
+	>,
 	placements: Array<{ serverId: string; count: number }>,
-	crons: Array<{ path: string; schedule: string }>,
+	crons: CronDefinition[],
 ) {
 	const resources =
 		service.resourceCpuLimit == null && service.resourceMemoryLimitMb == null
@@ -785,6 +1180,7 @@ function canonicalReplacementState(
 				containerPort: port.port,
 				public: port.isPublic,
 				domain: port.domain,
+				...persistedClientAuth(port),
 			}))
 			.toSorted(
 				(a, b) =>
@@ -811,22 +1207,27 @@ function canonicalReplacementState(
 					},
 		healthCheck: healthCheckFromService(service),
 		startCommand: service.startCommand?.trim() || null,
+		releaseCommand: service.releaseCommand?.trim() || null,
 		resources,
 		crons: crons
-			.map(({ path, schedule }) => ({ path, schedule }))
+			.map(canonicalCron)
 			.toSorted((a, b) => a.path.localeCompare(b.path, "en")),
 		serverless: { enabled: service.serverlessEnabled },
 	};
 }
 
-export function canonicalDesired(input: CanonicalReplacementInput) {
+export function canonicalDesired({
+	releaseCommand,
+	...input
+}: CanonicalReplacementInput) {
 	return {
 		...input,
 		source: canonicalPlanSource(input.source),
 		ports: input.ports
-			.map((port) => ({
+			.map(({ clientAuth, ...port }) => ({
 				...port,
 				domain: port.domain ?? null,
+				...(clientAuth ? { clientAuth } : {}),
 			}))
 			.toSorted(
 				(a, b) =>
@@ -851,9 +1252,10 @@ export function canonicalDesired(input: CanonicalReplacementInput) {
 							},
 						}
 					: input.placement,
-		crons: (input.crons ?? []).toSorted((a, b) =>
-			a.path.localeCompare(b.path, "en"),
-		),
+		crons: (input.crons ?? [])
+			.map(canonicalCron)
+			.toSorted((a, b) => a.path.localeCompare(b.path, "en")),
+		releaseCommand: releaseCommand ?? null,
 	};
 }
 
@@ -899,18 +1301,23 @@ function configurationChanges(
 }
 
 export function planCanonicalConfiguration(
-	current: Omit<ReturnType<typeof canonicalReplacementState>, "crons"> & {
-		crons?: Array<{ path: string; schedule: string }>;
+	current: Omit<
+		ReturnType<typeof canonicalReplacementState>,
+		"crons" | "releaseCommand"
+	> & {
+		crons?: CronDefinition[];
+		releaseCommand?: string | null;
 	},
 	desiredInput: CanonicalReplacementInput,
 ) {
-	const { serverless, ...currentWithoutServerless } = current;
+	const { serverless, releaseCommand, ...currentWithoutServerless } = current;
 	const canonicalCurrent = {
 		...currentWithoutServerless,
 		source: canonicalPlanSource(current.source),
 		crons: (current.crons ?? [])
-			.map(({ path, schedule }) => ({ path, schedule }))
+			.map(canonicalCron)
 			.toSorted((a, b) => a.path.localeCompare(b.path, "en")),
+		releaseCommand: releaseCommand ?? null,
 		serverless,
 	};
 	const desiredConfiguration = canonicalDesired(desiredInput);
@@ -1068,7 +1475,11 @@ async function replaceConfigurationInternal(
 			domainError(blockers[0].message, blockers[0].code);
 		}
 
-		if (input.source.type !== persisted.sourceType) {
+		const convertsGitSource =
+			input.source.type !== "github" &&
+			persisted.sourceType !== "github" &&
+			(input.source.type === "git" || persisted.sourceType === "git");
+		if (input.source.type !== persisted.sourceType && !convertsGitSource) {
 			domainError(
 				"Source type conversion is not supported; change the source in the web UI",
 				"SOURCE_TYPE_CONVERSION",
@@ -1199,6 +1610,12 @@ async function replaceConfigurationInternal(
 		) {
 			set.startCommand = input.startCommand;
 		}
+		const releaseCommand = input.releaseCommand ?? null;
+		if (
+			changed("releaseCommand", currentState.releaseCommand, releaseCommand)
+		) {
+			set.releaseCommand = releaseCommand;
+		}
 		if (
 			changed(
 				"healthCheck",
@@ -1247,6 +1664,9 @@ async function replaceConfigurationInternal(
 		) {
 			set.image = input.source.image;
 		}
+		if (changed("source.type", persisted.sourceType, input.source.type)) {
+			set.sourceType = input.source.type;
+		}
 		if (input.placement) {
 			const requestedPlacement =
 				input.placement.mode === "automatic" && "autoscaling" in input.placement
@@ -1350,13 +1770,56 @@ async function replaceConfigurationInternal(
 				set.githubRootDir = desiredRoot;
 			}
 		}
+		if (input.source.type === "git") {
+			const { repository, branch, rootDir, submodules, lfs } = input.source;
+			if (changed("source.repository", persisted.gitRepoUrl, repository)) {
+				set.gitRepoUrl = repository;
+			}
+			if (changed("source.branch", persisted.gitBranch, branch)) {
+				set.gitBranch = branch;
+			}
+			if (changed("source.rootDir", persisted.gitRootDir, rootDir)) {
+				set.gitRootDir = rootDir;
+			}
+			if (changed("source.submodules", persisted.gitSubmodules, submodules)) {
+				set.gitSubmodules = submodules;
+			}
+			if (changed("source.lfs", persisted.gitLfs, lfs)) {
+				set.gitLfs = lfs;
+			}
+		}
+		const build =
+			input.source.type === "image"
+				? { dockerfile: null, target: null, buildArgs: {} }
+				: input.source;
+		const currentBuild = persistedBuildOptions(persisted);
+		if (
+			changed("source.dockerfile", currentBuild.dockerfile, build.dockerfile)
+		) {
+			set.buildDockerfile = build.dockerfile;
+		}
+		if (changed("source.target", currentBuild.target, build.target)) {
+			set.buildTarget = build.target;
+		}
+		const buildArgs = sortedBuildArgs(build.buildArgs);
+		if (changed("source.buildArgs", currentBuild.buildArgs, buildArgs)) {
+			set.buildArgs = buildArgs;
+		}
 
 		if (Object.keys(set).length > 0) {
 			await tx.update(services).set(set).where(eq(services.id, service.id));
 		}
 		if (input.ports) {
 			const currentPorts = ports
-				.map((port) => [port.port, port.isPublic, port.domain] as const)
+				.map(
+					(port) =>
+						[
+							port.port,
+							port.isPublic,
+							port.domain,
+							persistedClientAuth(port).clientAuth ?? null,
+						] as const,
+				)
 				.toSorted(
 					(a, b) =>
 						a[0] - b[0] ||
@@ -1366,7 +1829,12 @@ async function replaceConfigurationInternal(
 			const desiredPorts = input.ports
 				.map(
 					(port) =>
-						[port.containerPort, port.public, port.domain ?? null] as const,
+						[
+							port.containerPort,
+							port.public,
+							port.domain ?? null,
+							port.clientAuth ?? null,
+						] as const,
 				)
 				.toSorted(
 					(a, b) =>
@@ -1388,6 +1856,8 @@ async function replaceConfigurationInternal(
 								isPublic: port.public,
 								domain: port.public ? (port.domain ?? null) : null,
 								protocol: "http" as const,
+								clientAuthMode: port.clientAuth?.mode ?? null,
+								clientCaBundle: port.clientAuth?.caBundle ?? null,
 							})),
 						);
 					} catch (error) {
@@ -1408,21 +1878,44 @@ async function replaceConfigurationInternal(
 		const appliedAt = new Date();
 		for (const cron of input.crons) {
 			const existing = cronsByPath.get(cron.path);
+			const policy = resolveCronPolicy(cron);
 			if (!existing) {
 				await tx.insert(serviceCrons).values({
 					id: randomUUID(),
 					serviceId: service.id,
-					...cron,
-					nextScheduledFor: nextOccurrenceAfter(cron.schedule, appliedAt),
+					path: cron.path,
+					schedule: cron.schedule,
+					...policy,
+					nextScheduledFor: nextOccurrenceAfter(
+						cron.schedule,
+						appliedAt,
+						policy.timeZone,
+					),
 				});
-			} else if (existing.schedule !== cron.schedule) {
+			} else if (
+				existing.schedule !== cron.schedule ||
+				existing.timeZone !== policy.timeZone
+			) {
 				await tx
 					.update(serviceCrons)
 					.set({
 						schedule: cron.schedule,
-						nextScheduledFor: nextOccurrenceAfter(cron.schedule, appliedAt),
+						...policy,
+						nextScheduledFor: nextOccurrenceAfter(
+							cron.schedule,
+							appliedAt,
+							policy.timeZone,
+						),
 					})
 					.where(eq(serviceCrons.id, existing.id));
+			} else if (
+				JSON.stringify(canonicalCron(existing)) !==
+				JSON.stringify(canonicalCron(cron))
+			) {
+				await tx
+					.update(serviceCrons)
+					.set(policy)
+					.where(eq(serviceCrons.id, existing.id));
 			}
 		}
 		if (input.crons.length === 0) {
@@ -3,6 +3,7 @@ import { db } from "@/db";
 import { builds, serviceRevisions, workQueue } from "@/db/schema";
 import { reportServerError } from "@/lib/server-errors";
 import { parseServiceRevisionSpec } from "@/lib/service-revision-changes";
+import { isBuiltServiceRevisionSource } from "@/lib/service-revision-spec";
 
 type RegistryCleanupTransaction = Parameters<
 	Parameters<typeof db.transaction>[0]
@@ -140,7 +141,7 @@ async function deleteArtifactReferences(
 ) {
 	if (revision.artifactDeletedAt) return false;
 	const specification = parseServiceRevisionSpec(revision.specification);
-	if (specification.source.type !== "github") return false;
+	if (!isBuiltServiceRevisionSource(specification.source)) return false;
 	const config = registryConfig();
 	const finalReference = managedReference(specification.image, config.host);
 	if (finalReference.isDigest) {
@@ -174,7 +175,10 @@ async function deleteArtifactReferences(
 
 export async function cleanupRevisionArtifact(revision: RevisionArtifact) {
 	const specification = parseServiceRevisionSpec(revision.specification);
-	if (revision.artifactDeletedAt || specification.source.type !== "github") {
+	if (
+		revision.artifactDeletedAt ||
+		!isBuiltServiceRevisionSource(specification.source)
+	) {
 		return false;
 	}
 	const revisions = await db
@@ -315,7 +319,7 @@ export async function cleanupRegistryArtifactsForService(serviceId: string) {
 	const artifacts = new Map<string, RevisionArtifact>();
 	for (const revision of revisions) {
 		const specification = parseServiceRevisionSpec(revision.specification);
-		if (specification.source.type !== "github") continue;
+		if (!isBuiltServiceRevisionSource(specification.source)) continue;
 		const image = specification.image;
 		if (!artifacts.has(image)) artifacts.set(image, revision);
 	}
@@ -18,6 +18,7 @@ import {
 	serviceRevisions,
 	services,
 	serviceCommands,
+	serviceRuns,
 	workQueue,
 } from "@/db/schema";
 import {
@@ -45,6 +46,8 @@ import {
 	cloneActiveRevisionForAutoscaling,
 } from "@/lib/service-revisions";
 import {
+	publishImageScanResult,
+	publishReleaseRunResult,
 	WORK_QUEUE_LEASE_DURATION_MS,
 	WORK_QUEUE_MAX_ATTEMPTS,
 } from "@/lib/work-queue";
@@ -887,7 +890,7 @@ export async function cleanupStaleItems(): Promise<void> {
 					eq(workQueue.status, "processing"),
 					lt(workQueue.startedAt, workItemLeaseThreshold),
 					or(
-						eq(workQueue.type, "command"),
+						inArray(workQueue.type, ["command", "run"]),
 						sql`${workQueue.attempts} >= ${WORK_QUEUE_MAX_ATTEMPTS}`,
 					),
 				),
@@ -896,6 +899,7 @@ export async function cleanupStaleItems(): Promise<void> {
 				id: workQueue.id,
 				serverId: workQueue.serverId,
 				type: workQueue.type,
+				payload: workQueue.payload,
 			});
 		if (items.length > 0) {
 			await tx
@@ -911,6 +915,19 @@ export async function cleanupStaleItems(): Promise<void> {
 						items.map((item) => item.id),
 					),
 				);
+			await tx
+				.update(serviceRuns)
+				.set({
+					status: "failed",
+					errorMessage: "Agent did not report a result",
+					completedAt: new Date(),
+				})
+				.where(
+					inArray(
+						serviceRuns.id,
+						items.map((item) => item.id),
+					),
+				);
 		}
 		return items;
 	});
@@ -927,6 +944,12 @@ export async function cleanupStaleItems(): Promise<void> {
 				},
 			});
 		}
+		// Release runs and image scans wake the rollout waiting on them, as
+		// agent results do.
+		for (const item of staleWorkItems) {
+			if (item.type === "run") await publishReleaseRunResult(item);
+			if (item.type === "scan_image") await publishImageScanResult(item);
+		}
 		console.log(
 			`[scheduler] cleaned up ${staleWorkItems.length} stale work queue items`,
 		);
@@ -1,6 +1,6 @@
 import { lt } from "drizzle-orm";
 import { db } from "@/db";
-import { serviceCommands } from "@/db/schema";
+import { serviceCommands, serviceRuns } from "@/db/schema";
 import { DAY_IN_MILLISECONDS, subtractMilliseconds } from "@/lib/date";
 
 export const COMMAND_RETENTION_DAYS = 90;
@@ -17,3 +17,16 @@ export async function cleanupOldServiceCommands(now = new Date()) {
 
 	return result.rowCount ?? 0;
 }
+
+export async function cleanupOldServiceRuns(now = new Date()) {
+	const result = await db
+		.delete(serviceRuns)
+		.where(
+			lt(
+				serviceRuns.createdAt,
+				subtractMilliseconds(now, COMMAND_RETENTION_DAYS * DAY_IN_MILLISECONDS),
+			),
+		);
+
+	return result.rowCount ?? 0;
+}
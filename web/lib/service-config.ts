@@ -1,7 +1,16 @@
 import {
+	type ClientAuthMode,
+	clientAuthDescription,
 	getServiceRevisionTotalReplicas,
+	type ProxyProtocolVersion,
+	type ServerlessAutoscalingPolicy,
+	type ServerlessKeepWarmWindow,
 	type ServiceAutoscalingPolicy,
+	type ServiceRevisionClientAuth,
 	type ServiceRevisionSpec,
+	keepWarmDescription,
+	optionalLimitDescription,
+	serverlessAutoscalingDescription,
 } from "@/lib/service-revision-spec";
 
 export type ReplicaConfig = {
@@ -16,6 +25,9 @@ export type PortConfig = {
 	domain: string | null;
 	protocol?: "http" | "tcp" | "udp";
 	tlsPassthrough?: boolean;
+	tlsTermination?: boolean;
+	proxyProtocol?: ProxyProtocolVersion;
+	clientAuth?: ServiceRevisionClientAuth;
 };
 
 export type HealthCheckConfig = {
@@ -36,6 +48,14 @@ export type SourceConfig =
 			repository: string | null;
 			branch: string;
 			rootDir: string | null;
+	  }
+	| {
+			type: "git";
+			repository: string | null;
+			branch: string;
+			rootDir: string | null;
+			submodules: boolean;
+			lfs: boolean;
 	  };
 
 export type SecretConfig = {
@@ -63,12 +83,25 @@ export type ServerlessConfig = {
 	enabled: boolean;
 	sleepAfterSeconds: number;
 	wakeTimeoutSeconds: number;
+	autoscaling?: ServerlessAutoscalingPolicy;
+	maxQueuedRequests?: number;
+	maxQueueWaitSeconds?: number;
+	keepWarm?: ServerlessKeepWarmWindow[];
+	streamIdleTimeoutSeconds?: number;
+	maxConnectionLifetimeSeconds?: number;
+	eventPath?: string;
 };
 
 export const MIN_SERVERLESS_SLEEP_AFTER_SECONDS = 120;
 const DEFAULT_SERVERLESS_SLEEP_AFTER_SECONDS = 300;
 const DEFAULT_SERVERLESS_WAKE_TIMEOUT_SECONDS = 300;
 
+export type BuildConfig = {
+	dockerfile: string | null;
+	target: string | null;
+	args: Record<string, string>;
+};
+
 export type DeployedConfig = {
 	source: SourceConfig;
 	hostname?: string;
@@ -77,6 +110,8 @@ export type DeployedConfig = {
 	replicas: ReplicaConfig[];
 	healthCheck: HealthCheckConfig | null;
 	startCommand?: string | null;
+	releaseCommand?: string | null;
+	build?: BuildConfig | null;
 	resourceLimits?: ResourceLimitsConfig;
 	ports: PortConfig[];
 	serverless?: ServerlessConfig;
@@ -116,6 +151,28 @@ export function getServiceTotalReplicas(service: {
 			);
 }
 
+function currentBuildConfig(service: {
+	buildDockerfile?: string | null;
+	buildTarget?: string | null;
+	buildArgs?: Record<string, string> | null;
+}): BuildConfig | null {
+	const dockerfile = service.buildDockerfile?.trim() || null;
+	const target = service.buildTarget?.trim() || null;
+	const args = service.buildArgs ?? {};
+	return dockerfile || target || Object.keys(args).length > 0
+		? { dockerfile, target, args }
+		: null;
+}
+
+function formatBuildArgs(args: Record<string, string> | undefined): string {
+	const entries = Object.entries(args ?? {}).sort(([a], [b]) =>
+		a.localeCompare(b, "en"),
+	);
+	return entries.length > 0
+		? entries.map(([key, value]) => `${key}=${value}`).join(", ")
+		: "(none)";
+}
+
 export function buildCurrentConfig(
 	service: {
 		image: string;
@@ -126,6 +183,10 @@ export function buildCurrentConfig(
 		healthCheckRetries: number | null;
 		healthCheckStartPeriod: number | null;
 		startCommand: string | null;
+		releaseCommand?: string | null;
+		buildDockerfile?: string | null;
+		buildTarget?: string | null;
+		buildArgs?: Record<string, string> | null;
 		resourceCpuLimit: number | null;
 		resourceMemoryLimitMb: number | null;
 		replicas: number;
@@ -137,6 +198,13 @@ export function buildCurrentConfig(
 		serverlessEnabled?: boolean | null;
 		serverlessSleepAfterSeconds?: number | null;
 		serverlessWakeTimeoutSeconds?: number | null;
+		serverlessAutoscaling?: ServerlessAutoscalingPolicy | null;
+		serverlessMaxQueuedRequests?: number | null;
+		serverlessMaxQueueWaitSeconds?: number | null;
+		serverlessKeepWarm?: ServerlessKeepWarmWindow[] | null;
+		serverlessStreamIdleTimeoutSeconds?: number | null;
+		serverlessMaxConnectionLifetimeSeconds?: number | null;
+		serverlessEventPath?: string | null;
 	},
 	replicas: { serverId: string; serverName: string; count: number }[],
 	ports: {
@@ -145,6 +213,10 @@ export function buildCurrentConfig(
 		domain: string | null;
 		protocol?: "http" | "tcp" | "udp" | null;
 		tlsPassthrough?: boolean | null;
+		tlsTermination?: boolean | null;
+		proxyProtocol?: ProxyProtocolVersion | null;
+		clientAuthMode?: ClientAuthMode | null;
+		clientCaBundle?: string | null;
 	}[],
 	secrets: { key: string; updatedAt: Date | string }[] | undefined,
 	volumes: { name: string; containerPath: string }[] | undefined,
@@ -189,6 +261,8 @@ export function buildCurrentConfig(
 				}
 			: null,
 		startCommand: service.startCommand,
+		releaseCommand: service.releaseCommand?.trim() || null,
+		build: source.type === "image" ? null : currentBuildConfig(service),
 		resourceLimits: hasResourceLimits
 			? {
 					cpuCores: service.resourceCpuLimit,
@@ -201,6 +275,12 @@ export function buildCurrentConfig(
 			domain: p.domain,
 			protocol: p.protocol ?? "http",
 			tlsPassthrough: p.tlsPassthrough ?? undefined,
+			tlsTermination: p.tlsTermination || undefined,
+			proxyProtocol: p.proxyProtocol ?? undefined,
+			clientAuth:
+				p.clientAuthMode && p.clientCaBundle
+					? { mode: p.clientAuthMode, caBundle: p.clientCaBundle }
+					: undefined,
 		})),
 		serverless: getCurrentServerlessConfig(service),
 		secrets: (secrets ?? []).map((s) => ({
@@ -215,6 +295,10 @@ export function buildCurrentConfig(
 	};
 }
 
+function sourceLabel(type: SourceConfig["type"]): string {
+	return type === "github" ? "GitHub" : type === "git" ? "Git" : "Image";
+}
+
 export function diffConfigs(
 	deployed: DeployedConfig | null,
 	current: DeployedConfig,
@@ -228,9 +312,12 @@ export function diffConfigs(
 				from: "(not deployed)",
 				to: current.source.image,
 			});
-		} else if (current.source.type === "github") {
+		} else if (current.source.type !== "image") {
 			changes.push({
-				field: "GitHub repository",
+				field:
+					current.source.type === "github"
+						? "GitHub repository"
+						: "Git repository",
 				from: "(not deployed)",
 				to: current.source.repository ?? "(not configured)",
 				requiresBuild: true,
@@ -257,6 +344,13 @@ export function diffConfigs(
 				to: current.startCommand,
 			});
 		}
+		if (current.releaseCommand) {
+			changes.push({
+				field: "Release command",
+				from: "(none)",
+				to: current.releaseCommand,
+			});
+		}
 		if (current.resourceLimits?.cpuCores) {
 			changes.push({
 				field: "CPU limit",
@@ -350,6 +444,93 @@ export function diffConfigs(
 				to: `${currentServerless.wakeTimeoutSeconds}s`,
 			});
 		}
+		const deployedAutoscaling = serverlessAutoscalingDescription(
+			deployedServerless.autoscaling,
+		);
+		const currentAutoscaling = serverlessAutoscalingDescription(
+			currentServerless.autoscaling,
+		);
+		if (deployedAutoscaling !== currentAutoscaling) {
+			changes.push({
+				field: "Serverless autoscaling",
+				from: deployedAutoscaling,
+				to: currentAutoscaling,
+			});
+		}
+		if (
+			deployedServerless.maxQueuedRequests !==
+			currentServerless.maxQueuedRequests
+		) {
+			changes.push({
+				field: "Serverless queue limit",
+				from: optionalLimitDescription(deployedServerless.maxQueuedRequests),
+				to: optionalLimitDescription(currentServerless.maxQueuedRequests),
+			});
+		}
+		if (
+			deployedServerless.maxQueueWaitSeconds !==
+			currentServerless.maxQueueWaitSeconds
+		) {
+			changes.push({
+				field: "Serverless queue wait",
+				from: optionalLimitDescription(
+					deployedServerless.maxQueueWaitSeconds,
+					"s",
+				),
+				to: optionalLimitDescription(
+					currentServerless.maxQueueWaitSeconds,
+					"s",
+				),
+			});
+		}
+		const deployedKeepWarm = keepWarmDescription(deployedServerless.keepWarm);
+		const currentKeepWarm = keepWarmDescription(currentServerless.keepWarm);
+		if (deployedKeepWarm !== currentKeepWarm) {
+			changes.push({
+				field: "Serverless keep-warm",
+				from: deployedKeepWarm,
+				to: currentKeepWarm,
+			});
+		}
+		if (
+			deployedServerless.streamIdleTimeoutSeconds !==
+			currentServerless.streamIdleTimeoutSeconds
+		) {
+			changes.push({
+				field: "Serverless stream idle timeout",
+				from: optionalLimitDescription(
+					deployedServerless.streamIdleTimeoutSeconds,
+					"s",
+				),
+				to: optionalLimitDescription(
+					currentServerless.streamIdleTimeoutSeconds,
+					"s",
+				),
+			});
+		}
+		if (
+			deployedServerless.maxConnectionLifetimeSeconds !==
+			currentServerless.maxConnectionLifetimeSeconds
+		) {
+			changes.push({
+				field: "Serverless connection lifetime",
+				from: optionalLimitDescription(
+					deployedServerless.maxConnectionLifetimeSeconds,
+					"s",
+				),
+				to: optionalLimitDescription(
+					currentServerless.maxConnectionLifetimeSeconds,
+					"s",
+				),
+			});
+		}
+		if (deployedServerless.eventPath !== currentServerless.eventPath) {
+			changes.push({
+				field: "Serverless event path",
+				from: deployedServerless.eventPath ?? "None",
+				to: currentServerless.eventPath ?? "None",
+			});
+		}
 	}
 
 	if (
@@ -393,12 +574,40 @@ export function diffConfigs(
 				requiresBuild: true,
 			});
 		}
+	} else if (deployed.source.type === "git" && current.source.type === "git") {
+		const source = deployed.source;
+		const gitChanges: Array<[string, string, string]> = [
+			[
+				"Git repository",
+				source.repository ?? "(not configured)",
+				current.source.repository ?? "(not configured)",
+			],
+			["Git branch", source.branch, current.source.branch],
+			[
+				"Git root directory",
+				source.rootDir ?? "(repository root)",
+				current.source.rootDir ?? "(repository root)",
+			],
+			[
+				"Git submodules",
+				source.submodules ? "Enabled" : "Disabled",
+				current.source.submodules ? "Enabled" : "Disabled",
+			],
+			[
+				"Git LFS",
+				source.lfs ? "Enabled" : "Disabled",
+				current.source.lfs ? "Enabled" : "Disabled",
+			],
+		];
+		for (const [field, from, to] of gitChanges) {
+			if (from !== to) changes.push({ field, from, to, requiresBuild: true });
+		}
 	} else if (deployed.source.type !== current.source.type) {
 		changes.push({
 			field: "Source",
-			from: deployed.source.type === "github" ? "GitHub" : "Image",
-			to: current.source.type === "github" ? "GitHub" : "Image",
-			requiresBuild: current.source.type === "github",
+			from: sourceLabel(deployed.source.type),
+			to: sourceLabel(current.source.type),
+			requiresBuild: current.source.type !== "image",
 		});
 	}
 
@@ -545,6 +754,35 @@ export function diffConfigs(
 		});
 	}
 
+	if ((deployed.releaseCommand ?? null) !== (current.releaseCommand ?? null)) {
+		changes.push({
+			field: "Release command",
+			from: deployed.releaseCommand || "(none)",
+			to: current.releaseCommand || "(none)",
+		});
+	}
+
+	const buildChanges: Array<[string, string, string]> = [
+		[
+			"Dockerfile",
+			deployed.build?.dockerfile ?? "(default)",
+			current.build?.dockerfile ?? "(default)",
+		],
+		[
+			"Build target",
+			deployed.build?.target ?? "(final stage)",
+			current.build?.target ?? "(final stage)",
+		],
+		[
+			"Build arguments",
+			formatBuildArgs(deployed.build?.args),
+			formatBuildArgs(current.build?.args),
+		],
+	];
+	for (const [field, from, to] of buildChanges) {
+		if (from !== to) changes.push({ field, from, to, requiresBuild: true });
+	}
+
 	const deployedCpu = deployed.resourceLimits?.cpuCores ?? null;
 	const currentCpu = current.resourceLimits?.cpuCores ?? null;
 	if (deployedCpu !== currentCpu) {
@@ -675,6 +913,11 @@ function describePorts(ports: PortConfig[]): string {
 			if (protocol !== "http") details.push(protocol.toUpperCase());
 			if (port.domain) details.push(port.domain);
 			if (port.tlsPassthrough) details.push("TLS passthrough");
+			if (port.tlsTermination) details.push("TLS termination");
+			if (port.proxyProtocol) {
+				details.push(`PROXY protocol v${port.proxyProtocol}`);
+			}
+			if (port.clientAuth) details.push(clientAuthDescription(port.clientAuth));
 			return details.join(", ");
 		})
 		.sort((a, b) => a.localeCompare(b))
@@ -692,6 +935,21 @@ export function normalizeServerlessConfig(
 		),
 		wakeTimeoutSeconds:
 			config?.wakeTimeoutSeconds ?? DEFAULT_SERVERLESS_WAKE_TIMEOUT_SECONDS,
+		...(config?.autoscaling ? { autoscaling: config.autoscaling } : {}),
+		...(config?.maxQueuedRequests
+			? { maxQueuedRequests: config.maxQueuedRequests }
+			: {}),
+		...(config?.maxQueueWaitSeconds
+			? { maxQueueWaitSeconds: config.maxQueueWaitSeconds }
+			: {}),
+		...(config?.keepWarm?.length ? { keepWarm: config.keepWarm } : {}),
+		...(config?.streamIdleTimeoutSeconds
+			? { streamIdleTimeoutSeconds: config.streamIdleTimeoutSeconds }
+			: {}),
+		...(config?.maxConnectionLifetimeSeconds
+			? { maxConnectionLifetimeSeconds: config.maxConnectionLifetimeSeconds }
+			: {}),
+		...(config?.eventPath ? { eventPath: config.eventPath } : {}),
 	};
 }
 
@@ -699,6 +957,13 @@ export function getCurrentServerlessConfig(service: {
 	serverlessEnabled?: boolean | null;
 	serverlessSleepAfterSeconds?: number | null;
 	serverlessWakeTimeoutSeconds?: number | null;
+	serverlessAutoscaling?: ServerlessAutoscalingPolicy | null;
+	serverlessMaxQueuedRequests?: number | null;
+	serverlessMaxQueueWaitSeconds?: number | null;
+	serverlessKeepWarm?: ServerlessKeepWarmWindow[] | null;
+	serverlessStreamIdleTimeoutSeconds?: number | null;
+	serverlessMaxConnectionLifetimeSeconds?: number | null;
+	serverlessEventPath?: string | null;
 }): ServerlessConfig {
 	return {
 		enabled: service.serverlessEnabled ?? false,
@@ -710,6 +975,31 @@ export function getCurrentServerlessConfig(service: {
 		wakeTimeoutSeconds:
 			service.serverlessWakeTimeoutSeconds ??
 			DEFAULT_SERVERLESS_WAKE_TIMEOUT_SECONDS,
+		...(service.serverlessEnabled && service.serverlessAutoscaling
+			? { autoscaling: service.serverlessAutoscaling }
+			: {}),
+		...(service.serverlessEnabled && service.serverlessMaxQueuedRequests
+			? { maxQueuedRequests: service.serverlessMaxQueuedRequests }
+			: {}),
+		...(service.serverlessEnabled && service.serverlessMaxQueueWaitSeconds
+			? { maxQueueWaitSeconds: service.serverlessMaxQueueWaitSeconds }
+			: {}),
+		...(service.serverlessEnabled && service.serverlessKeepWarm?.length
+			? { keepWarm: service.serverlessKeepWarm }
+			: {}),
+		...(service.serverlessEnabled && service.serverlessStreamIdleTimeoutSeconds
+			? { streamIdleTimeoutSeconds: service.serverlessStreamIdleTimeoutSeconds }
+			: {}),
+		...(service.serverlessEnabled &&
+		service.serverlessMaxConnectionLifetimeSeconds
+			? {
+					maxConnectionLifetimeSeconds:
+						service.serverlessMaxConnectionLifetimeSeconds,
+				}
+			: {}),
+		...(service.serverlessEnabled && service.serverlessEventPath
+			? { eventPath: service.serverlessEventPath }
+			: {}),
 	};
 }
 
@@ -727,7 +1017,22 @@ export function revisionSpecToDeployedConfig(
 						branch: specification.source.branch,
 						rootDir: specification.source.rootDir,
 					}
-				: { type: "image", image: specification.source.image },
+				: specification.source.type === "git"
+					? {
+							type: "git",
+							repository: specification.source.repository,
+							branch: specification.source.branch,
+							rootDir: specification.source.rootDir,
+							submodules: specification.source.submodules,
+							lfs: specification.source.lfs,
+						}
+					: {
+						type: "image",
+						image:
+							specification.source.type === "image"
+								? specification.source.image
+								: specification.image,
+					},
 		hostname: specification.hostname,
 		stateful: specification.stateful,
 		placement: {
@@ -742,6 +1047,8 @@ export function revisionSpecToDeployedConfig(
 		})),
 		healthCheck: specification.healthCheck,
 		startCommand: specification.startCommand,
+		releaseCommand: specification.releaseCommand ?? null,
+		build: specification.build ?? null,
 		resourceLimits: {
 			cpuCores: specification.resourceLimits.cpuCores,
 			memoryMb: specification.resourceLimits.memoryMb,
@@ -752,6 +1059,9 @@ export function revisionSpecToDeployedConfig(
 			domain: port.domain,
 			protocol: port.protocol,
 			tlsPassthrough: port.tlsPassthrough,
+			tlsTermination: port.tlsTermination,
+			proxyProtocol: port.proxyProtocol,
+			clientAuth: port.clientAuth,
 		})),
 		serverless: specification.serverless,
 		secrets: specification.secrets.map((secret) => ({
@@ -1,22 +1,41 @@
+import { randomUUID } from "node:crypto";
 import * as http from "node:http";
 import * as https from "node:https";
-import { and, eq, inArray, isNull, lt, or } from "drizzle-orm";
+import { and, eq, gt, inArray, isNull, lt, or, sql } from "drizzle-orm";
 import { CronExpressionParser } from "cron-parser";
 import { db } from "@/db";
-import { secrets, serviceCrons, services } from "@/db/schema";
+import {
+	secrets,
+	serviceCronRuns,
+	serviceCrons,
+	services,
+} from "@/db/schema";
 import { decryptSecret } from "@/lib/crypto";
+import { DAY_IN_MILLISECONDS, subtractMilliseconds } from "@/lib/date";
 import { notify } from "@/lib/notifications";
-import { isSafeCronPath, nextOccurrenceAfter } from "@/lib/public-api";
+import {
+	isSafeCronPath,
+	nextOccurrenceAfter,
+	resolveCronPolicy,
+} from "@/lib/public-api";
 import { reportOperationFailure, reportServerError } from "@/lib/server-errors";
 import { ingestCronLog, type CronLog } from "@/lib/victoria-logs";
 
 const MAX_ERROR = 500;
-const EXECUTION_BUDGET_MS = 10_000;
+const MAX_RESPONSE_SNIPPET = 512;
+const MAX_RETRY_BACKOFF_SECONDS = 3600;
+const RUN_STALE_GRACE_MS = 60_000;
+export const CRON_RUN_RETENTION_DAYS = 30;
+
+// Runs started by this process, so a replacing run can abort them directly.
+// Runs in other processes are marked cancelled and their result is discarded.
+const activeRuns = new Map<string, AbortController>();
 
 export type CronRequestResult = {
 	status: "succeeded" | "failed";
 	statusCode: number | null;
 	error: string | null;
+	responseSnippet: string | null;
 };
 
 export { nextOccurrenceAfter };
@@ -25,11 +44,12 @@ export function latestDueOccurrence(
 	schedule: string,
 	cursor: Date,
 	now: Date,
+	timeZone = "UTC",
 ): Date | null {
 	try {
 		const occurrence = CronExpressionParser.parse(schedule, {
 			currentDate: new Date(now.getTime() + 1),
-			tz: "UTC",
+			tz: timeZone,
 		})
 			.prev()
 			.toDate();
@@ -50,6 +70,23 @@ export function sanitizeCronError(error: unknown): string {
 	return message.replace(/[\u0000-\u001f\u007f]/g, " ").slice(0, MAX_ERROR);
 }
 
+export function sanitizeResponseSnippet(body: Buffer): string | null {
+	const snippet = body
+		.subarray(0, MAX_RESPONSE_SNIPPET)
+		.toString("utf8")
+		// eslint-disable-next-line no-control-regex -- Strip unsafe control characters from persisted responses.
+		.replace(/[\u0000-\u001f\u007f\ufffd]/g, " ")
+		.trim();
+	return snippet || null;
+}
+
+export function retryBackoffSeconds(baseSeconds: number, attempt: number) {
+	return Math.min(
+		baseSeconds * 2 ** Math.max(0, attempt - 1),
+		MAX_RETRY_BACKOFF_SECONDS,
+	);
+}
+
 export function parseCronUrl(base: string, path: string): URL {
 	if (!isSafeCronPath(path)) throw new Error("Invalid cron path");
 	let url: URL;
@@ -82,6 +119,7 @@ export async function performCronGet(
 	requestImpl: RequestImpl = url.protocol === "https:"
 		? https.request
 		: http.request,
+	signal?: AbortSignal,
 ): Promise<CronRequestResult> {
 	return new Promise((resolve) => {
 		let settled = false;
@@ -95,6 +133,7 @@ export async function performCronGet(
 				status: "failed",
 				statusCode: null,
 				error: "Cron request timed out",
+				responseSnippet: null,
 			});
 		const requestOptions: http.RequestOptions = {
 			method: "GET",
@@ -103,52 +142,153 @@ export async function performCronGet(
 		};
 		const req = requestImpl(url, requestOptions, (response) => {
 			const code = response.statusCode ?? null;
-			response.destroy();
-			finish(
-				code != null && code >= 200 && code < 300
-					? { status: "succeeded", statusCode: code, error: null }
-					: {
-							status: "failed",
-							statusCode: code,
-							error: `HTTP status ${code ?? "unknown"}`,
-						},
-			);
+			const chunks: Buffer[] = [];
+			let received = 0;
+			const complete = () => {
+				response.destroy();
+				const responseSnippet = sanitizeResponseSnippet(
+					Buffer.concat(chunks),
+				);
+				finish(
+					code != null && code >= 200 && code < 300
+						? {
+								status: "succeeded",
+								statusCode: code,
+								error: null,
+								responseSnippet,
+							}
+						: {
+								status: "failed",
+								statusCode: code,
+								error: `HTTP status ${code ?? "unknown"}`,
+								responseSnippet,
+							},
+				);
+			};
+			response.on("data", (chunk: Buffer) => {
+				chunks.push(chunk);
+				received += chunk.length;
+				if (received >= MAX_RESPONSE_SNIPPET) complete();
+			});
+			response.once("end", complete);
+			response.once("error", complete);
 		});
 		const timer = setTimeout(() => {
 			finish({
 				status: "failed",
 				statusCode: null,
 				error: "Cron request timed out",
+				responseSnippet: null,
 			});
 			req.destroy();
 		}, timeoutMs);
 		timer.unref?.();
+		const abort = () => {
+			finish({
+				status: "failed",
+				statusCode: null,
+				error: "Cron run was replaced",
+				responseSnippet: null,
+			});
+			req.destroy();
+		};
+		if (signal?.aborted) abort();
+		signal?.addEventListener("abort", abort, { once: true });
 		req.once("error", () =>
 			finish({
 				status: "failed",
 				statusCode: null,
 				error: "Cron request failed",
+				responseSnippet: null,
 			}),
 		);
 		req.once("close", () => {
 			clearTimeout(timer);
+			signal?.removeEventListener("abort", abort);
 			if (!settled)
 				finish({
 					status: "failed",
 					statusCode: null,
 					error: "Cron request closed",
+					responseSnippet: null,
 				});
 		});
 		req.end();
 	});
 }
+type CronRunStart = "started" | "forbidden";
+
+async function startCronRun(values: {
+	runId: string;
+	cronId: string;
+	serviceId: string;
+	source: "scheduled" | "manual";
+	scheduledFor: Date;
+	attempt: number;
+	startedAt: Date;
+	concurrencyPolicy: "allow" | "forbid" | "replace";
+	timeoutSeconds: number;
+}): Promise<CronRunStart> {
+	return db.transaction(async (tx) => {
+		await tx.execute(
+			sql`SELECT pg_advisory_xact_lock(hashtext(${values.cronId}))`,
+		);
+		if (values.attempt === 1 && values.concurrencyPolicy !== "allow") {
+			const staleBefore = subtractMilliseconds(
+				values.startedAt,
+				values.timeoutSeconds * 1_000 + RUN_STALE_GRACE_MS,
+			);
+			const running = await tx
+				.select({ id: serviceCronRuns.id })
+				.from(serviceCronRuns)
+				.where(
+					and(
+						eq(serviceCronRuns.cronId, values.cronId),
+						eq(serviceCronRuns.status, "running"),
+						gt(serviceCronRuns.startedAt, staleBefore),
+					),
+				);
+			if (running.length > 0 && values.concurrencyPolicy === "forbid")
+				return "forbidden";
+			if (running.length > 0) {
+				const runIds = running.map(({ id }) => id);
+				await tx
+					.update(serviceCronRuns)
+					.set({
+						status: "cancelled",
+						error: "Replaced by a newer run",
+						finishedAt: values.startedAt,
+					})
+					.where(
+						and(
+							inArray(serviceCronRuns.id, runIds),
+							eq(serviceCronRuns.status, "running"),
+						),
+					);
+				for (const runId of runIds) activeRuns.get(runId)?.abort();
+			}
+		}
+		await tx.insert(serviceCronRuns).values({
+			id: values.runId,
+			cronId: values.cronId,
+			serviceId: values.serviceId,
+			source: values.source,
+			scheduledFor: values.scheduledFor,
+			attempt: values.attempt,
+			status: "running",
+			startedAt: values.startedAt,
+		});
+		return "started";
+	});
+}
+
 export async function executeServiceCron(
 	cronId: string,
 	schedule: string,
 	scheduledFor: Date,
 	source: "scheduled" | "manual",
+	attempt = 1,
 ) {
-	const deadline = Date.now() + EXECUTION_BUDGET_MS;
 	const row = await db
 		.select({ cron: serviceCrons, serviceId: services.id })
 		.from(serviceCrons)
@@ -160,7 +300,9 @@ export async function executeServiceCron(
 		.limit(1)
 		.then((rows) => rows[0]);
 	if (!row || row.cron.schedule !== schedule) return { stale: true as const };
+	const policy = resolveCronPolicy(row.cron);
 	const startedAt = new Date();
+	const deadline = startedAt.getTime() + policy.timeoutSeconds * 1_000;
 	const claimed = await db
 		.update(serviceCrons)
 		.set({
@@ -171,7 +313,7 @@ export async function executeServiceCron(
 			and(
 				eq(serviceCrons.id, cronId),
 				eq(serviceCrons.schedule, schedule),
-				source === "scheduled"
+				source === "scheduled" && attempt === 1
 					? or(
 							isNull(serviceCrons.lastAttemptedFor),
 							lt(serviceCrons.lastAttemptedFor, scheduledFor),
@@ -181,62 +323,121 @@ export async function executeServiceCron(
 		)
 		.returning({ id: serviceCrons.id });
 	if (!claimed.length) return { stale: true as const };
+	const runId = randomUUID();
+	const runStart = await startCronRun({
+		runId,
+		cronId,
+		serviceId: row.serviceId,
+		source,
+		scheduledFor,
+		attempt,
+		startedAt,
+		concurrencyPolicy: policy.concurrencyPolicy,
+		timeoutSeconds: policy.timeoutSeconds,
+	});
 	let status: "succeeded" | "failed" | "skipped" = "skipped";
 	let statusCode: number | null = null;
 	let error: string | null = null;
+	let responseSnippet: string | null = null;
 	let base = "";
 	let secret: string | undefined;
 	let failureReason: "configuration_load_failed" | "request_failed" =
 		"request_failed";
-	try {
-		const values = await db
-			.select()
-			.from(secrets)
-			.where(
-				and(
-					eq(secrets.serviceId, row.serviceId),
-					inArray(secrets.key, ["CRON_BASE_URL", "CRON_SECRET"]),
-				),
-			);
-		const encrypted = new Map(
-			values.map((value) => [value.key, value.encryptedValue]),
-		);
-		base = encrypted.get("CRON_BASE_URL")
-			? await decryptSecret(encrypted.get("CRON_BASE_URL")!)
-			: "";
-		secret = encrypted.get("CRON_SECRET")
-			? await decryptSecret(encrypted.get("CRON_SECRET")!)
-			: undefined;
-	} catch (cause) {
-		reportServerError(cause, "service-cron.configuration.load", {
-			tags: { cronId, serviceId: row.serviceId },
-		});
-		status = "failed";
-		error = "Cron configuration could not be loaded";
-		failureReason = "configuration_load_failed";
-	}
-	if (error === null) {
+	if (runStart === "forbidden") {
+		error = "Previous run is still in progress";
+	} else {
+		const controller = new AbortController();
+		activeRuns.set(runId, controller);
 		try {
-			if (!base.trim()) throw new Error("CRON_BASE_URL is not configured");
-			const url = parseCronUrl(base.trim(), row.cron.path);
-			validateCronTransport(url, secret);
 			try {
-				({ status, statusCode, error } = await performCronGet(
-					url,
-					secret,
-					deadline - Date.now(),
-				));
+				const values = await db
+					.select()
+					.from(secrets)
+					.where(
+						and(
+							eq(secrets.serviceId, row.serviceId),
+							inArray(secrets.key, ["CRON_BASE_URL", "CRON_SECRET"]),
+						),
+					);
+				const encrypted = new Map(
+					values.map((value) => [value.key, value.encryptedValue]),
+				);
+				base = encrypted.get("CRON_BASE_URL")
+					? await decryptSecret(encrypted.get("CRON_BASE_URL")!)
+					: "";
+				secret = encrypted.get("CRON_SECRET")
+					? await decryptSecret(encrypted.get("CRON_SECRET")!)
+					: undefined;
 			} catch (cause) {
+				reportServerError(cause, "service-cron.configuration.load", {
+					tags: { cronId, serviceId: row.serviceId },
+				});
 				status = "failed";
-				error = sanitizeCronError(cause);
+				error = "Cron configuration could not be loaded";
+				failureReason = "configuration_load_failed";
+			}
+			if (error === null) {
+				try {
+					if (!base.trim()) throw new Error("CRON_BASE_URL is not configured");
+					const url = parseCronUrl(base.trim(), row.cron.path);
+					validateCronTransport(url, secret);
+					try {
+						({ status, statusCode, error, responseSnippet } =
+							await performCronGet(
+								url,
+								secret,
+								deadline - Date.now(),
+								undefined,
+								controller.signal,
+							));
+					} catch (cause) {
+						status = "failed";
+						error = sanitizeCronError(cause);
+					}
+				} catch (cause) {
+					error = sanitizeCronError(cause);
+					status = "skipped";
+				}
 			}
-		} catch (cause) {
-			error = sanitizeCronError(cause);
-			status = "skipped";
+		} finally {
+			activeRuns.delete(runId);
 		}
 	}
 	const finishedAt = new Date();
 	const durationMs = Math.max(0, finishedAt.getTime() - startedAt.getTime());
+	const runResult = {
+		status,
+		statusCode,
+		durationMs,
+		responseSnippet,
+		error,
+		finishedAt,
+	};
+	if (runStart === "forbidden") {
+		await db.insert(serviceCronRuns).values({
+			id: runId,
+			cronId,
+			serviceId: row.serviceId,
+			source,
+			scheduledFor,
+			attempt,
+			startedAt,
+			...runResult,
+		});
+	} else {
+		const recorded = await db
+			.update(serviceCronRuns)
+			.set(runResult)
+			.where(
+				and(
+					eq(serviceCronRuns.id, runId),
+					eq(serviceCronRuns.status, "running"),
+				),
+			)
+			.returning({ id: serviceCronRuns.id });
+		if (!recorded.length)
+			return { stale: false as const, status: "cancelled" as const };
+	}
 	await db
 		.update(serviceCrons)
 		.set({
@@ -247,6 +448,24 @@ export async function executeServiceCron(
 			lastError: error,
 		})
 		.where(eq(serviceCrons.id, cronId));
+	await db
+		.delete(serviceCronRuns)
+		.where(
+			and(
+				eq(serviceCronRuns.cronId, cronId),
+				lt(
+					serviceCronRuns.startedAt,
+					subtractMilliseconds(
+						finishedAt,
+						CRON_RUN_RETENTION_DAYS * DAY_IN_MILLISECONDS,
+					),
+				),
+			),
+		);
+	const retryInSeconds =
+		status === "failed" && attempt <= policy.retries
+			? retryBackoffSeconds(policy.retryBackoffSeconds, attempt)
+			: null;
 	const log: CronLog = {
 		_msg: `Cron ${status}`,
 		_time: finishedAt.toISOString(),
@@ -255,6 +474,7 @@ export async function executeServiceCron(
 		path: row.cron.path,
 		source,
 		scheduled_for: scheduledFor.toISOString(),
+		attempt,
 		started_at: startedAt.toISOString(),
 		finished_at: finishedAt.toISOString(),
 		result: status,
@@ -264,13 +484,13 @@ export async function executeServiceCron(
 		log_type: "cron",
 	};
 	await ingestCronLog(log);
-	if (status === "failed") {
+	if (status === "failed" && retryInSeconds === null) {
 		const occurrenceId = cronEventId(cronId, scheduledFor);
 		reportOperationFailure("service-cron.failed", {
 			occurrenceId,
 			reason: failureReason,
 			tags: { cronId, serviceId: row.serviceId, source },
-			extra: { statusCode },
+			extra: { statusCode, attempt },
 		});
 		notify({
 			kind: "cron.failed",
@@ -289,5 +509,11 @@ export async function executeServiceCron(
 			);
 		});
 	}
-	return { stale: false as const, status, statusCode, error };
+	return {
+		stale: false as const,
+		status,
+		statusCode,
+		error,
+		retryInSeconds,
+	};
 }
@@ -6,7 +6,13 @@ import type {
 	ServiceRevisionPort,
 	ServiceRevisionSpec,
 } from "@/lib/service-revision-spec";
-import { validateServiceRevisionPorts } from "@/lib/service-revision-spec";
+import {
+	clientAuthDescription,
+	keepWarmDescription,
+	optionalLimitDescription,
+	serverlessAutoscalingDescription,
+	validateServiceRevisionPorts,
+} from "@/lib/service-revision-spec";
 
 const serviceRevisionSpecFields = {
 	image: z.string(),
@@ -27,6 +33,24 @@ const serviceRevisionSpecFields = {
 				}),
 			]),
 		}),
+		z.strictObject({
+			type: z.literal("git"),
+			repository: z.string().min(1),
+			branch: z.string().min(1),
+			commitSha: z.string().regex(/^[0-9a-f]{40}$/),
+			rootDir: z.string().min(1).nullable(),
+			submodules: z.boolean(),
+			lfs: z.boolean(),
+			authentication: z.strictObject({
+				type: z.enum(["none", "https_token", "ssh_key"]),
+			}),
+		}),
+		z.strictObject({
+			type: z.literal("local"),
+			contextId: z.string().min(1),
+			checksum: z.string().regex(/^[0-9a-f]{64}$/),
+			rootDir: z.string().min(1).nullable(),
+		}),
 	]),
 	hostname: z.string(),
 	stateful: z.boolean(),
@@ -34,6 +58,30 @@ const serviceRevisionSpecFields = {
 		enabled: z.boolean(),
 		sleepAfterSeconds: z.number(),
 		wakeTimeoutSeconds: z.number(),
+		autoscaling: z
+			.strictObject({
+				targetConcurrency: z.number(),
+				minReplicas: z.number(),
+				maxReplicas: z.number(),
+				scaleDownCooldownSeconds: z.number(),
+			})
+			.optional(),
+		maxQueuedRequests: z.number().optional(),
+		maxQueueWaitSeconds: z.number().optional(),
+		keepWarm: z
+			.array(
+				z.strictObject({
+					days: z.array(z.string()).optional(),
+					start: z.string(),
+					end: z.string(),
+					timeZone: z.string().optional(),
+					prewarmSeconds: z.number().optional(),
+				}),
+			)
+			.optional(),
+		streamIdleTimeoutSeconds: z.number().optional(),
+		maxConnectionLifetimeSeconds: z.number().optional(),
+		eventPath: z.string().optional(),
 	}),
 	healthCheck: z
 		.strictObject({
@@ -45,6 +93,14 @@ const serviceRevisionSpecFields = {
 		})
 		.nullable(),
 	startCommand: z.string().nullable(),
+	releaseCommand: z.string().optional(),
+	build: z
+		.strictObject({
+			dockerfile: z.string().min(1).nullable(),
+			target: z.string().min(1).nullable(),
+			args: z.record(z.string(), z.string()),
+		})
+		.optional(),
 	resourceLimits: z.strictObject({
 		cpuCores: z.number().nullable(),
 		memoryMb: z.number().nullable(),
@@ -70,6 +126,14 @@ const serviceRevisionSpecFields = {
 			protocol: z.enum(["http", "tcp", "udp"]),
 			externalPort: z.number().nullable(),
 			tlsPassthrough: z.boolean(),
+			tlsTermination: z.boolean().optional(),
+			proxyProtocol: z.union([z.literal(1), z.literal(2)]).optional(),
+			clientAuth: z
+				.strictObject({
+					mode: z.enum(["require", "optional"]),
+					caBundle: z.string().min(1),
+				})
+				.optional(),
 		}),
 	),
 	secrets: z.array(
@@ -222,9 +286,21 @@ function portDescription(port: ServiceRevisionPort): string {
 		`domain ${port.domain ?? "(none)"}`,
 		`external ${port.externalPort ?? "(default)"}`,
 		`TLS passthrough ${enabled(port.tlsPassthrough).toLowerCase()}`,
+		...(port.tlsTermination ? ["TLS termination enabled"] : []),
+		...(port.proxyProtocol ? [`PROXY protocol v${port.proxyProtocol}`] : []),
+		...(port.clientAuth ? [clientAuthDescription(port.clientAuth)] : []),
 	].join(", ");
 }
 
+function formatBuildArgs(args: Record<string, string> | undefined): string {
+	const entries = Object.entries(args ?? {}).sort(([a], [b]) =>
+		compareStrings(a, b),
+	);
+	return entries.length > 0
+		? entries.map(([key, value]) => `${key}=${value}`).join(", ")
+		: "(none)";
+}
+
 /** Compare two immutable v2 specifications without requiring browser APIs. */
 export function diffServiceRevisionSpecs(
 	previous: ServiceRevisionSpec,
@@ -252,6 +328,34 @@ export function diffServiceRevisionSpecs(
 			current.source.rootDir ?? "(repository root)",
 		);
 	}
+	if (previous.source.type === "git" && current.source.type === "git") {
+		add(
+			"Git repository",
+			previous.source.repository,
+			current.source.repository,
+		);
+		add("Git branch", previous.source.branch, current.source.branch);
+		add("Git commit", previous.source.commitSha, current.source.commitSha);
+		add(
+			"Git root directory",
+			previous.source.rootDir ?? "(repository root)",
+			current.source.rootDir ?? "(repository root)",
+		);
+		add(
+			"Git submodules",
+			enabled(previous.source.submodules),
+			enabled(current.source.submodules),
+		);
+		add("Git LFS", enabled(previous.source.lfs), enabled(current.source.lfs));
+		add(
+			"Git authentication",
+			previous.source.authentication.type,
+			current.source.authentication.type,
+		);
+	}
+	if (previous.source.type === "local" && current.source.type === "local") {
+		add("Local context", previous.source.checksum, current.source.checksum);
+	}
 	add("Hostname", previous.hostname, current.hostname);
 	add(
 		"Service type",
@@ -273,6 +377,47 @@ export function diffServiceRevisionSpecs(
 		`${previous.serverless.wakeTimeoutSeconds}s`,
 		`${current.serverless.wakeTimeoutSeconds}s`,
 	);
+	add(
+		"Serverless autoscaling",
+		serverlessAutoscalingDescription(previous.serverless.autoscaling),
+		serverlessAutoscalingDescription(current.serverless.autoscaling),
+	);
+	add(
+		"Serverless queue limit",
+		optionalLimitDescription(previous.serverless.maxQueuedRequests),
+		optionalLimitDescription(current.serverless.maxQueuedRequests),
+	);
+	add(
+		"Serverless queue wait",
+		optionalLimitDescription(previous.serverless.maxQueueWaitSeconds, "s"),
+		optionalLimitDescription(current.serverless.maxQueueWaitSeconds, "s"),
+	);
+	add(
+		"Serverless keep-warm",
+		keepWarmDescription(previous.serverless.keepWarm),
+		keepWarmDescription(current.serverless.keepWarm),
+	);
+	add(
+		"Serverless stream idle timeout",
+		optionalLimitDescription(previous.serverless.streamIdleTimeoutSeconds, "s"),
+		optionalLimitDescription(current.serverless.streamIdleTimeoutSeconds, "s"),
+	);
+	add(
+		"Serverless connection lifetime",
+		optionalLimitDescription(
+			previous.serverless.maxConnectionLifetimeSeconds,
+			"s",
+		),
+		optionalLimitDescription(
+			current.serverless.maxConnectionLifetimeSeconds,
+			"s",
+		),
+	);
+	add(
+		"Serverless event path",
+		previous.serverless.eventPath ?? "None",
+		current.serverless.eventPath ?? "None",
+	);
 
 	if (previous.healthCheck === null || current.healthCheck === null) {
 		add(
@@ -317,6 +462,26 @@ export function diffServiceRevisionSpecs(
 		previous.startCommand ?? "(default)",
 		current.startCommand ?? "(default)",
 	);
+	add(
+		"Release command",
+		previous.releaseCommand ?? "(none)",
+		current.releaseCommand ?? "(none)",
+	);
+	add(
+		"Dockerfile",
+		previous.build?.dockerfile ?? "(default)",
+		current.build?.dockerfile ?? "(default)",
+	);
+	add(
+		"Build target",
+		previous.build?.target ?? "(final stage)",
+		current.build?.target ?? "(final stage)",
+	);
+	add(
+		"Build arguments",
+		formatBuildArgs(previous.build?.args),
+		formatBuildArgs(current.build?.args),
+	);
 	add(
 		"CPU limit",
 		previous.resourceLimits.cpuCores === null
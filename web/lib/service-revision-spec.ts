@@ -56,6 +56,36 @@ export type ServiceAutoscalingPolicy =
 	| { enabled: false }
 	| { enabled: true; minReplicas: number; maxReplicas: number };
 
+/** Request-based scaling the serverless gateway applies to awake replicas. */
+export type ServerlessAutoscalingPolicy = {
+	targetConcurrency: number;
+	minReplicas: number;
+	maxReplicas: number;
+	scaleDownCooldownSeconds: number;
+};
+
+/** A daily window in which the serverless gateway keeps replicas awake. */
+export type ServerlessKeepWarmWindow = {
+	days?: string[];
+	start: string;
+	end: string;
+	timeZone?: string;
+	prewarmSeconds?: number;
+};
+
+export const MAX_SERVERLESS_KEEP_WARM_WINDOWS = 20;
+
+const keepWarmDays = ["mon", "tue", "wed", "thu", "fri", "sat", "sun"];
+
+export type ClientAuthMode = "require" | "optional";
+
+export type ProxyProtocolVersion = 1 | 2;
+
+export type ServiceRevisionClientAuth = {
+	mode: ClientAuthMode;
+	caBundle: string;
+};
+
 export type ServiceRevisionPort = {
 	containerPort: number;
 	isPublic: boolean;
@@ -63,18 +93,135 @@ export type ServiceRevisionPort = {
 	protocol: "http" | "tcp" | "udp";
 	externalPort: number | null;
 	tlsPassthrough: boolean;
+	tlsTermination?: boolean;
+	proxyProtocol?: ProxyProtocolVersion;
+	clientAuth?: ServiceRevisionClientAuth;
 };
 
 export type ServicePortValidationIssue = {
-	code: "DUPLICATE_DOMAIN" | "DUPLICATE_PORT" | "INVALID_DOMAIN";
+	code:
+		| "DUPLICATE_DOMAIN"
+		| "DUPLICATE_PORT"
+		| "INVALID_DOMAIN"
+		| "INVALID_PROXY_PROTOCOL"
+		| "INVALID_CLIENT_AUTH";
 	message: string;
 };
 
+/**
+ * Short, stable label for a client CA bundle so pending-change and revision
+ * diffs notice a replaced bundle without printing the PEM.
+ */
+export function clientAuthDescription(
+	clientAuth: ServiceRevisionClientAuth,
+): string {
+	let hash = 0x811c9dc5;
+	for (let i = 0; i < clientAuth.caBundle.length; i++) {
+		hash = Math.imul(hash ^ clientAuth.caBundle.charCodeAt(i), 0x01000193);
+	}
+	const digest = (hash >>> 0).toString(16).padStart(8, "0");
+	return `client certificates ${clientAuth.mode === "require" ? "required" : "optional"} (CA ${digest})`;
+}
+
+export const MAX_SERVERLESS_QUEUED_REQUESTS = 10_000;
+export const MAX_SERVERLESS_STREAM_SECONDS = 86_400;
+
+export function isServerlessStreamDuration(seconds: number) {
+	return (
+		Number.isInteger(seconds) &&
+		seconds >= 1 &&
+		seconds <= MAX_SERVERLESS_STREAM_SECONDS
+	);
+}
+
+export const MAX_SERVERLESS_EVENT_PATH_LENGTH = 2048;
+
+// Event paths are sent as the request target of delivered events, so only
+// printable origin-form paths are accepted.
+export function isServerlessEventPath(path: string) {
+	return (
+		path.length <= MAX_SERVERLESS_EVENT_PATH_LENGTH &&
+		/^\/(?!\/)[\x21-\x7e]*$/.test(path) &&
+		!path.includes("#")
+	);
+}
+
+export function optionalLimitDescription(
+	value: number | undefined,
+	unit = "",
+): string {
+	return value === undefined ? "Default" : `${value}${unit}`;
+}
+
+export function keepWarmDescription(
+	windows: ServerlessKeepWarmWindow[] | undefined,
+): string {
+	if (!windows?.length) return "None";
+	return windows
+		.map((window) =>
+			[
+				window.days?.length ? window.days.join(",") : "daily",
+				`${window.start}-${window.end}`,
+				window.timeZone ?? "UTC",
+				...(window.prewarmSeconds ? [`prewarm ${window.prewarmSeconds}s`] : []),
+			].join(" "),
+		)
+		.join("; ");
+}
+
+/** Returns why a keep-warm window is invalid, or null when it is valid. */
+export function findKeepWarmWindowIssue(
+	window: ServerlessKeepWarmWindow,
+): string | null {
+	const clock = /^([01]\d|2[0-3]):[0-5]\d$/;
+	if (!clock.test(window.start) || !clock.test(window.end)) {
+		return "Keep-warm windows need start and end times as HH:MM";
+	}
+	if (window.start === window.end) {
+		return "Keep-warm windows cannot start and end at the same time";
+	}
+	if (window.days?.some((day) => !keepWarmDays.includes(day))) {
+		return `Keep-warm days must be one of ${keepWarmDays.join(", ")}`;
+	}
+	if (window.timeZone !== undefined) {
+		try {
+			new Intl.DateTimeFormat("en-US", { timeZone: window.timeZone });
+		} catch {
+			return `Unknown time zone "${window.timeZone}"`;
+		}
+	}
+	if (
+		window.prewarmSeconds !== undefined &&
+		(!Number.isInteger(window.prewarmSeconds) ||
+			window.prewarmSeconds < 0 ||
+			window.prewarmSeconds > 3600)
+	) {
+		return "Keep-warm prewarm must be between 0 and 3600 seconds";
+	}
+	return null;
+}
+
+export function serverlessAutoscalingDescription(
+	policy: ServerlessAutoscalingPolicy | undefined,
+): string {
+	return policy
+		? `${policy.targetConcurrency} requests per replica, ${policy.minReplicas}-${policy.maxReplicas} replicas, scale down after ${policy.scaleDownCooldownSeconds}s`
+		: "Disabled";
+}
+
+type ValidatedServicePort = Pick<
+	ServiceRevisionPort,
+	"containerPort" | "isPublic" | "domain" | "protocol"
+> &
+	Partial<
+		Pick<
+			ServiceRevisionPort,
+			"tlsPassthrough" | "tlsTermination" | "proxyProtocol" | "clientAuth"
+		>
+	>;
+
 export function findServicePortValidationIssue(
-	ports: Pick<
-		ServiceRevisionPort,
-		"containerPort" | "isPublic" | "domain" | "protocol"
-	>[],
+	ports: ValidatedServicePort[],
 ): ServicePortValidationIssue | null {
 	const domains = new Set<string>();
 	const portsByNumberAndProtocol = Map.groupBy(
@@ -89,10 +236,48 @@ export function findServicePortValidationIssue(
 				message: "Public HTTP ports require a domain",
 			};
 		}
-		if ((!port.isPublic || port.protocol !== "http") && port.domain) {
+		const terminatesTls = port.protocol === "tcp" && !!port.tlsTermination;
+		if (terminatesTls && (!port.isPublic || !port.domain)) {
+			return {
+				code: "INVALID_DOMAIN",
+				message: "TLS termination requires a public TCP port with a domain",
+			};
+		}
+		if (terminatesTls && port.tlsPassthrough) {
+			return {
+				code: "INVALID_DOMAIN",
+				message: "TLS passthrough and termination cannot both be enabled",
+			};
+		}
+		if (
+			(!port.isPublic || (port.protocol !== "http" && !terminatesTls)) &&
+			port.domain
+		) {
 			return {
 				code: "INVALID_DOMAIN",
-				message: "Only public HTTP ports can define a domain",
+				message:
+					"Only public HTTP ports and TLS terminated TCP ports can define a domain",
+			};
+		}
+		if (port.proxyProtocol != null) {
+			if (port.proxyProtocol !== 1 && port.proxyProtocol !== 2) {
+				return {
+					code: "INVALID_PROXY_PROTOCOL",
+					message: "PROXY protocol version must be 1 or 2",
+				};
+			}
+			if (!port.isPublic || port.protocol !== "tcp") {
+				return {
+					code: "INVALID_PROXY_PROTOCOL",
+					message: "PROXY protocol is only supported for public TCP ports",
+				};
+			}
+		}
+		if (port.clientAuth && !port.domain) {
+			return {
+				code: "INVALID_CLIENT_AUTH",
+				message:
+					"Client certificates require a public HTTP port with a domain or a TLS terminated TCP port",
 			};
 		}
 		if (port.domain) {
@@ -124,12 +309,7 @@ export function findServicePortValidationIssue(
 	return null;
 }
 
-export function validateServiceRevisionPorts(
-	ports: Pick<
-		ServiceRevisionPort,
-		"containerPort" | "isPublic" | "domain" | "protocol"
-	>[],
-) {
+export function validateServiceRevisionPorts(ports: ValidatedServicePort[]) {
 	const issue = findServicePortValidationIssue(ports);
 	if (issue) throw new Error(issue.message);
 }
@@ -168,8 +348,60 @@ export type ServiceRevisionSource =
 			authentication:
 				| { type: "anonymous" }
 				| { type: "github_app"; installationId: number };
+	  }
+	| {
+			type: "git";
+			repository: string;
+			branch: string;
+			commitSha: string;
+			rootDir: string | null;
+			submodules: boolean;
+			lfs: boolean;
+			authentication: { type: "none" | "https_token" | "ssh_key" };
+	  }
+	| {
+			type: "local";
+			contextId: string;
+			checksum: string;
+			rootDir: string | null;
 	  };
 
+export type BuiltServiceRevisionSource = Extract<
+	ServiceRevisionSource,
+	{ type: "github" | "git" | "local" }
+>;
+
+export const LOCAL_BUILD_BRANCH = "local";
+
+export function isBuiltServiceRevisionSource(
+	source: ServiceRevisionSource,
+): source is BuiltServiceRevisionSource {
+	return (
+		source.type === "github" ||
+		source.type === "git" ||
+		source.type === "local"
+	);
+}
+
+/** Services whose images are built from a repository rather than pulled. */
+export function isBuiltServiceSourceType(sourceType: string): boolean {
+	return sourceType === "github" || sourceType === "git";
+}
+
+/** The commit and branch recorded on build rows for a built source. */
+export function serviceRevisionBuildRef(source: BuiltServiceRevisionSource) {
+	return source.type === "local"
+		? { commitSha: source.checksum, branch: LOCAL_BUILD_BRANCH }
+		: { commitSha: source.commitSha, branch: source.branch };
+}
+
+/** Dockerfile build inputs; omitted when a built service uses the defaults. */
+export type ServiceRevisionBuildOptions = {
+	dockerfile: string | null;
+	target: string | null;
+	args: Record<string, string>;
+};
+
 export type ServiceRevisionSpec = {
 	schemaVersion: typeof SERVICE_REVISION_SCHEMA_VERSION;
 	image: string;
@@ -180,9 +412,18 @@ export type ServiceRevisionSpec = {
 		enabled: boolean;
 		sleepAfterSeconds: number;
 		wakeTimeoutSeconds: number;
+		autoscaling?: ServerlessAutoscalingPolicy;
+		maxQueuedRequests?: number;
+		maxQueueWaitSeconds?: number;
+		keepWarm?: ServerlessKeepWarmWindow[];
+		streamIdleTimeoutSeconds?: number;
+		maxConnectionLifetimeSeconds?: number;
+		eventPath?: string;
 	};
 	healthCheck: ServiceRevisionHealthCheck | null;
 	startCommand: string | null;
+	releaseCommand?: string;
+	build?: ServiceRevisionBuildOptions;
 	resourceLimits: {
 		cpuCores: number | null;
 		memoryMb: number | null;
@@ -205,12 +446,23 @@ export type ServiceRevisionDraft = {
 		serverlessEnabled: boolean | null;
 		serverlessSleepAfterSeconds: number | null;
 		serverlessWakeTimeoutSeconds: number | null;
+		serverlessAutoscaling?: ServerlessAutoscalingPolicy | null;
+		serverlessMaxQueuedRequests?: number | null;
+		serverlessMaxQueueWaitSeconds?: number | null;
+		serverlessKeepWarm?: ServerlessKeepWarmWindow[] | null;
+		serverlessStreamIdleTimeoutSeconds?: number | null;
+		serverlessMaxConnectionLifetimeSeconds?: number | null;
+		serverlessEventPath?: string | null;
 		healthCheckCmd: string | null;
 		healthCheckInterval: number | null;
 		healthCheckTimeout: number | null;
 		healthCheckRetries: number | null;
 		healthCheckStartPeriod: number | null;
 		startCommand: string | null;
+		releaseCommand?: string | null;
+		buildDockerfile?: string | null;
+		buildTarget?: string | null;
+		buildArgs?: Record<string, string> | null;
 		resourceCpuLimit: number | null;
 		resourceMemoryLimitMb: number | null;
 		placementMode?: "manual" | "automatic" | null;
@@ -227,6 +479,10 @@ export type ServiceRevisionDraft = {
 		protocol: "http" | "tcp" | "udp" | null;
 		externalPort: number | null;
 		tlsPassthrough: boolean | null;
+		tlsTermination?: boolean | null;
+		proxyProtocol?: ProxyProtocolVersion | null;
+		clientAuthMode?: ClientAuthMode | null;
+		clientCaBundle?: string | null;
 	}>;
 	secrets: Array<{
 		key: string;
@@ -293,6 +549,84 @@ function validateServiceRevisionSpec(
 		)
 			throw new Error("Autoscaling requires both CPU and memory limits");
 	}
+	const serverlessAutoscaling = specification.serverless.autoscaling;
+	if (serverlessAutoscaling) {
+		const { targetConcurrency, minReplicas, maxReplicas } =
+			serverlessAutoscaling;
+		if (
+			!Number.isInteger(minReplicas) ||
+			!Number.isInteger(maxReplicas) ||
+			minReplicas < 1 ||
+			maxReplicas > 32 ||
+			minReplicas > maxReplicas
+		) {
+			throw new Error(
+				"Serverless autoscaling range must satisfy 1 <= minimum <= maximum <= 32",
+			);
+		}
+		if (!Number.isInteger(targetConcurrency) || targetConcurrency < 1) {
+			throw new Error("Serverless target concurrency must be at least 1");
+		}
+		if (!specification.serverless.enabled)
+			throw new Error("Serverless autoscaling requires serverless mode");
+		if (specification.placement.mode !== "automatic")
+			throw new Error("Serverless autoscaling requires automatic placement");
+	}
+	const { maxQueuedRequests, maxQueueWaitSeconds } = specification.serverless;
+	if (
+		maxQueuedRequests !== undefined &&
+		(!Number.isInteger(maxQueuedRequests) ||
+			maxQueuedRequests < 1 ||
+			maxQueuedRequests > MAX_SERVERLESS_QUEUED_REQUESTS)
+	) {
+		throw new Error(
+			`Serverless queue limit must be between 1 and ${MAX_SERVERLESS_QUEUED_REQUESTS} requests`,
+		);
+	}
+	if (
+		maxQueueWaitSeconds !== undefined &&
+		(!Number.isInteger(maxQueueWaitSeconds) ||
+			maxQueueWaitSeconds < 1 ||
+			maxQueueWaitSeconds > 900)
+	) {
+		throw new Error("Serverless queue wait must be between 1 and 900 seconds");
+	}
+	const { streamIdleTimeoutSeconds, maxConnectionLifetimeSeconds } =
+		specification.serverless;
+	if (
+		streamIdleTimeoutSeconds !== undefined &&
+		!isServerlessStreamDuration(streamIdleTimeoutSeconds)
+	) {
+		throw new Error(
+			`Serverless stream idle timeout must be between 1 and ${MAX_SERVERLESS_STREAM_SECONDS} seconds`,
+		);
+	}
+	if (
+		maxConnectionLifetimeSeconds !== undefined &&
+		!isServerlessStreamDuration(maxConnectionLifetimeSeconds)
+	) {
+		throw new Error(
+			`Serverless connection lifetime must be between 1 and ${MAX_SERVERLESS_STREAM_SECONDS} seconds`,
+		);
+	}
+	const { eventPath } = specification.serverless;
+	if (eventPath !== undefined && !isServerlessEventPath(eventPath)) {
+		throw new Error(
+			"Serverless event path must be an absolute path such as /events",
+		);
+	}
+	const keepWarm = specification.serverless.keepWarm;
+	if (keepWarm) {
+		if (keepWarm.length > MAX_SERVERLESS_KEEP_WARM_WINDOWS) {
+			throw new Error(
+				`At most ${MAX_SERVERLESS_KEEP_WARM_WINDOWS} keep-warm windows are allowed`,
+			);
+		}
+		for (const window of keepWarm) {
+			const issue = findKeepWarmWindowIssue(window);
+			if (issue) throw new Error(issue);
+		}
+	}
 	if (
 		specification.placement.mode === "automatic" &&
 		specification.placements.length
@@ -331,6 +665,21 @@ function compareStrings(a: string, b: string) {
 	return a.localeCompare(b, "en");
 }
 
+function revisionBuildOptions(
+	service: ServiceRevisionDraft["service"],
+): ServiceRevisionBuildOptions | undefined {
+	const dockerfile = service.buildDockerfile?.trim() || null;
+	const target = service.buildTarget?.trim() || null;
+	const args = Object.fromEntries(
+		Object.entries(service.buildArgs ?? {}).sort(([a], [b]) =>
+			compareStrings(a, b),
+		),
+	);
+	return dockerfile || target || Object.keys(args).length > 0
+		? { dockerfile, target, args }
+		: undefined;
+}
+
 export function buildServiceRevisionSpec(
 	draft: ServiceRevisionDraft,
 	overrides: ServiceRevisionSpecOverrides = {},
@@ -344,10 +693,23 @@ export function buildServiceRevisionSpec(
 				maxReplicas: service.autoscalingMaxReplicas ?? 1,
 			}
 		: undefined;
-	const replicas = autoscaling
+	const serverlessAutoscaling = service.serverlessEnabled
+		? (service.serverlessAutoscaling ?? undefined)
+		: undefined;
+	const streamIdleTimeoutSeconds = service.serverlessEnabled
+		? service.serverlessStreamIdleTimeoutSeconds
+		: null;
+	const maxConnectionLifetimeSeconds = service.serverlessEnabled
+		? service.serverlessMaxConnectionLifetimeSeconds
+		: null;
+	const eventPath = service.serverlessEnabled
+		? service.serverlessEventPath?.trim()
+		: undefined;
+	const replicaRange = autoscaling ?? serverlessAutoscaling;
+	const replicas = replicaRange
 		? Math.min(
-				autoscaling.maxReplicas,
-				Math.max(autoscaling.minReplicas, service.replicas ?? 1),
+				replicaRange.maxReplicas,
+				Math.max(replicaRange.minReplicas, service.replicas ?? 1),
 			)
 		: (service.replicas ?? 1);
 
@@ -366,6 +728,25 @@ export function buildServiceRevisionSpec(
 				120,
 			),
 			wakeTimeoutSeconds: service.serverlessWakeTimeoutSeconds ?? 300,
+			...(serverlessAutoscaling
+				? { autoscaling: { ...serverlessAutoscaling } }
+				: {}),
+			...(service.serverlessEnabled && service.serverlessMaxQueuedRequests
+				? { maxQueuedRequests: service.serverlessMaxQueuedRequests }
+				: {}),
+			...(service.serverlessEnabled && service.serverlessMaxQueueWaitSeconds
+				? { maxQueueWaitSeconds: service.serverlessMaxQueueWaitSeconds }
+				: {}),
+			...(service.serverlessEnabled && service.serverlessKeepWarm?.length
+				? {
+						keepWarm: service.serverlessKeepWarm.map((window) => ({
+							...window,
+						})),
+					}
+				: {}),
+			...(streamIdleTimeoutSeconds ? { streamIdleTimeoutSeconds } : {}),
+			...(maxConnectionLifetimeSeconds ? { maxConnectionLifetimeSeconds } : {}),
+			...(eventPath ? { eventPath } : {}),
 		},
 		healthCheck: service.healthCheckCmd
 			? {
@@ -377,6 +758,11 @@ export function buildServiceRevisionSpec(
 				}
 			: null,
 		startCommand: service.startCommand?.trim() || null,
+		releaseCommand: service.releaseCommand?.trim() || undefined,
+		build:
+			overrides.source && overrides.source.type !== "image"
+				? revisionBuildOptions(service)
+				: undefined,
 		resourceLimits: {
 			cpuCores: service.resourceCpuLimit,
 			memoryMb: service.resourceMemoryLimitMb,
@@ -401,6 +787,16 @@ export function buildServiceRevisionSpec(
 				protocol: port.protocol ?? "http",
 				externalPort: port.externalPort,
 				tlsPassthrough: port.tlsPassthrough ?? false,
+				...(port.tlsTermination ? { tlsTermination: true } : {}),
+				...(port.proxyProtocol ? { proxyProtocol: port.proxyProtocol } : {}),
+				...(port.clientAuthMode && port.clientCaBundle
+					? {
+							clientAuth: {
+								mode: port.clientAuthMode,
+								caBundle: port.clientCaBundle,
+							},
+						}
+					: {}),
 			}))
 			.sort(
 				(a, b) =>
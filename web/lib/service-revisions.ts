@@ -6,6 +6,7 @@ import {
 	githubRepos,
 	rollouts,
 	secrets,
+	serviceGitCredentials,
 	servicePorts,
 	serviceReplicas,
 	serviceRevisions,
@@ -18,6 +19,7 @@ import type { ServiceRevisionActor } from "@/lib/service-revision-actor";
 import { parseServiceRevisionSpec } from "@/lib/service-revision-changes";
 import {
 	buildServiceRevisionSpec,
+	isBuiltServiceRevisionSource,
 	type ServiceRevisionSource,
 	type ServiceRevisionSpec,
 	type ServiceRevisionSpecOverrides,
@@ -39,9 +41,10 @@ function specificationsMatch(
 	}
 }
 
-function assertMatchingGitHubBuildRevision(
+function assertMatchingBuildRevision(
 	revision: typeof serviceRevisions.$inferSelect,
 	input: {
+		sourceType: "github" | "git";
 		serviceId: string;
 		image: string;
 		commitSha: string;
@@ -56,7 +59,7 @@ function assertMatchingGitHubBuildRevision(
 		const specification = parseServiceRevisionSpec(revision.specification);
 		if (
 			specification.image !== input.image ||
-			specification.source.type !== "github" ||
+			specification.source.type !== input.sourceType ||
 			specification.source.repository !== input.expectedRepository ||
 			specification.source.branch !== input.expectedBranch ||
 			specification.source.commitSha !== input.commitSha.toLowerCase()
@@ -197,7 +200,10 @@ export async function createGitHubBuildServiceRevision(input: {
 			.where(eq(serviceRevisions.id, input.id))
 			.then((rows) => rows[0]);
 		if (existing) {
-			assertMatchingGitHubBuildRevision(existing, input);
+			assertMatchingBuildRevision(existing, {
+				...input,
+				sourceType: "github",
+			});
 			return existing;
 		}
 
@@ -257,6 +263,136 @@ export async function createGitHubBuildServiceRevision(input: {
 	});
 }
 
+export async function createGitBuildServiceRevision(input: {
+	id: string;
+	serviceId: string;
+	image: string;
+	commitSha: string;
+	expectedRepository: string;
+	expectedBranch: string;
+	actor: ServiceRevisionActor | null;
+}) {
+	return db.transaction(async (tx) => {
+		await tx.execute(
+			sql`select pg_advisory_xact_lock(hashtext(${input.serviceId}))`,
+		);
+		const existing = await tx
+			.select()
+			.from(serviceRevisions)
+			.where(eq(serviceRevisions.id, input.id))
+			.then((rows) => rows[0]);
+		if (existing) {
+			assertMatchingBuildRevision(existing, { ...input, sourceType: "git" });
+			return existing;
+		}
+
+		const [service, credential] = await Promise.all([
+			tx
+				.select()
+				.from(services)
+				.where(
+					and(eq(services.id, input.serviceId), isNull(services.deletedAt)),
+				)
+				.then((rows) => rows[0]),
+			tx
+				.select({ type: serviceGitCredentials.type })
+				.from(serviceGitCredentials)
+				.where(eq(serviceGitCredentials.serviceId, input.serviceId))
+				.then((rows) => rows[0]),
+		]);
+		if (!service || service.sourceType !== "git") {
+			throw new Error("Active git service not found");
+		}
+
+		const currentSource = resolvePersistedSourceFromRows(service, undefined);
+		if (
+			currentSource.type !== "git" ||
+			!currentSource.repository ||
+			currentSource.repository !== input.expectedRepository ||
+			currentSource.branch !== input.expectedBranch
+		) {
+			throw new Error("Git source changed while resolving the build commit");
+		}
+
+		const source: ServiceRevisionSource = {
+			type: "git",
+			repository: currentSource.repository,
+			branch: currentSource.branch,
+			commitSha: input.commitSha.toLowerCase(),
+			rootDir: currentSource.rootDir,
+			submodules: currentSource.submodules,
+			lfs: currentSource.lfs,
+			authentication: { type: credential?.type ?? "none" },
+		};
+
+		return createServiceRevisionSnapshot(tx, {
+			id: input.id,
+			serviceId: input.serviceId,
+			actor: input.actor,
+			overrides: {
+				image: input.image,
+				source,
+				allowNoPlacements: true,
+			},
+		});
+	});
+}
+
+export async function createLocalBuildServiceRevision(input: {
+	id: string;
+	serviceId: string;
+	image: string;
+	contextId: string;
+	checksum: string;
+	actor: ServiceRevisionActor | null;
+}) {
+	return db.transaction(async (tx) => {
+		await tx.execute(
+			sql`select pg_advisory_xact_lock(hashtext(${input.serviceId}))`,
+		);
+		const [service, repo] = await Promise.all([
+			tx
+				.select()
+				.from(services)
+				.where(
+					and(eq(services.id, input.serviceId), isNull(services.deletedAt)),
+				)
+				.then((rows) => rows[0]),
+			tx
+				.select()
+				.from(githubRepos)
+				.where(eq(githubRepos.serviceId, input.serviceId))
+				.then((rows) => rows[0]),
+		]);
+		if (!service) throw new Error("Service not found");
+		if (service.previewOfService) {
+			throw new Error("Preview services build from their pull request");
+		}
+
+		const currentSource = resolvePersistedSourceFromRows(service, repo);
+		const source: ServiceRevisionSource = {
+			type: "local",
+			contextId: input.contextId,
+			checksum: input.checksum,
+			rootDir:
+				currentSource.type === "github" || currentSource.type === "git"
+					? currentSource.rootDir?.trim() || null
+					: null,
+		};
+
+		return createServiceRevisionSnapshot(tx, {
+			id: input.id,
+			serviceId: input.serviceId,
+			actor: input.actor,
+			overrides: {
+				image: input.image,
+				source,
+				allowNoPlacements: true,
+			},
+		});
+	});
+}
+
 export async function cloneGitHubBuildServiceRevision(input: {
 	serviceId: string;
 	sourceRevisionId: string;
@@ -288,8 +424,11 @@ export async function cloneGitHubBuildServiceRevision(input: {
 		const sourceSpecification = parseServiceRevisionSpec(
 			sourceRevision.specification,
 		);
-		if (sourceSpecification.source.type !== "github") {
-			throw new Error("Build service revision is not a GitHub revision");
+		if (
+			sourceSpecification.source.type !== "github" &&
+			sourceSpecification.source.type !== "git"
+		) {
+			throw new Error("Build service revision is not a Git revision");
 		}
 
 		const id = randomUUID();
@@ -340,7 +479,7 @@ export async function createRolloutWithServiceRevision(
 			const baseSpecification = parseServiceRevisionSpec(
 				baseRevision.specification,
 			);
-			if (baseSpecification.source.type !== "github") {
+			if (!isBuiltServiceRevisionSource(baseSpecification.source)) {
 				throw new Error("GitHub runtime base revision is not a GitHub build");
 			}
 			if (baseRevision.artifactDeletedAt) {
@@ -578,6 +717,123 @@ export async function cloneActiveRevisionForAutoscaling(input: {
 	});
 }
 
+export const SERVERLESS_SCALE_ATTEMPT_COOLDOWN_MS = 60 * 1000;
+
+/**
+ * Applies a replica count a serverless gateway asked for. The target is
+ * clamped to the active revision's policy and must move in the requested
+ * direction, so late or duplicate requests from other proxies are no-ops.
+ */
+export async function cloneActiveRevisionForServerlessScaling(input: {
+	serviceId: string;
+	direction: "up" | "down";
+	targetReplicas: number;
+	now?: Date;
+}) {
+	return db.transaction(async (tx) => {
+		await tx.execute(
+			sql`select pg_advisory_xact_lock(hashtext(${input.serviceId}))`,
+		);
+		const service = await tx
+			.select({
+				id: services.id,
+				deletedAt: services.deletedAt,
+				migrationStatus: services.migrationStatus,
+				lastAutoscaleAttemptAt: services.lastAutoscaleAttemptAt,
+			})
+			.from(services)
+			.where(eq(services.id, input.serviceId))
+			.then((rows) => rows[0]);
+		if (!service || service.deletedAt || service.migrationStatus)
+			return { created: false, reason: "service-unavailable" } as const;
+		const now = input.now ?? new Date();
+		if (
+			service.lastAutoscaleAttemptAt &&
+			now.getTime() - service.lastAutoscaleAttemptAt.getTime() <
+				SERVERLESS_SCALE_ATTEMPT_COOLDOWN_MS
+		)
+			return { created: false, reason: "cooldown" } as const;
+		const pending = await tx
+			.select({ id: rollouts.id })
+			.from(rollouts)
+			.where(
+				and(
+					eq(rollouts.serviceId, input.serviceId),
+					inArray(rollouts.status, ["queued", "in_progress"]),
+				),
+			)
+			.limit(1)
+			.then((rows) => rows[0]);
+		if (pending) return { created: false, reason: "pending-rollout" } as const;
+		const active = await tx
+			.select({
+				revisionId: deployments.serviceRevisionId,
+				specification: serviceRevisions.specification,
+			})
+			.from(deployments)
+			.innerJoin(
+				serviceRevisions,
+				eq(serviceRevisions.id, deployments.serviceRevisionId),
+			)
+			.where(
+				and(
+					eq(deployments.serviceId, input.serviceId),
+					eq(deployments.trafficState, "active"),
+					inArray(deployments.runtimeDesiredState, ["running", "stopped"]),
+				),
+			);
+		const source = active[0];
+		if (
+			!source ||
+			new Set(active.map((deployment) => deployment.revisionId)).size !== 1
+		)
+			return { created: false, reason: "stale-topology" } as const;
+		const specification = parseServiceRevisionSpec(source.specification);
+		const policy = specification.serverless.autoscaling;
+		if (
+			!specification.serverless.enabled ||
+			!policy ||
+			specification.placement.mode !== "automatic"
+		)
+			return { created: false, reason: "stale-policy" } as const;
+		const current = specification.placement.replicas;
+		const targetReplicas = Math.min(
+			policy.maxReplicas,
+			Math.max(policy.minReplicas, input.targetReplicas),
+		);
+		if (
+			input.direction === "up"
+				? targetReplicas <= current
+				: targetReplicas >= current
+		)
+			return { created: false, reason: "unchanged" } as const;
+
+		await tx
+			.update(services)
+			.set({ lastAutoscaleAttemptAt: now })
+			.where(eq(services.id, input.serviceId));
+		const revisionId = randomUUID();
+		await tx.insert(serviceRevisions).values({
+			id: revisionId,
+			serviceId: input.serviceId,
+			specification: {
+				...specification,
+				placement: { ...specification.placement, replicas: targetReplicas },
+			},
+			actor: { type: "system" },
+		});
+		const rolloutId = randomUUID();
+		await tx.insert(rollouts).values({
+			id: rolloutId,
+			serviceId: input.serviceId,
+			serviceRevisionId: revisionId,
+			status: "queued",
+			currentStage: "queued",
+		});
+		return { created: true, rolloutId, revisionId, targetReplicas } as const;
+	});
+}
+
 export async function createRolloutForServiceRevision(
 	serviceId: string,
 	serviceRevisionId: string,
@@ -624,7 +880,10 @@ export async function createRolloutForServiceRevision(
 		}
 
 		const specification = parseServiceRevisionSpec(revision.specification);
-		if (specification.source.type === "github" && revision.artifactDeletedAt) {
+		if (
+			isBuiltServiceRevisionSource(specification.source) &&
+			revision.artifactDeletedAt
+		) {
 			throw new Error("Service revision artifact is no longer available");
 		}
 		if (specification.image !== artifactImageUri) {
@@ -0,0 +1,291 @@
+import { randomUUID } from "node:crypto";
+import { and, desc, eq, inArray, or, sql } from "drizzle-orm";
+import { db } from "@/db";
+import {
+	deployments,
+	servers,
+	serviceRevisions,
+	serviceRuns,
+	services,
+	workQueue,
+} from "@/db/schema";
+import type { Service } from "@/db/types";
+import { buildTaskContainer } from "@/lib/agent/expected-state";
+import { getServiceSigningPolicy } from "@/lib/image-signing-policy";
+import {
+	activeTrafficStates,
+	runtimeExpectedStates,
+} from "@/lib/deployment-status";
+import { parseServiceRevisionSpec } from "@/lib/service-revision-changes";
+import { getRolloutServiceRevision } from "@/lib/service-revisions";
+import { assignContainerIp } from "@/lib/wireguard";
+import { enqueueWork } from "@/lib/work-queue";
+
+export const MAX_RUN_COMMAND_LENGTH = 4096;
+export const DEFAULT_RUN_TIMEOUT_SECONDS = 600;
+export const MAX_RUN_TIMEOUT_SECONDS = 3600;
+export const RELEASE_RUN_TIMEOUT_SECONDS = 1800;
+
+export class ServiceRunError extends Error {
+	constructor(
+		message: string,
+		readonly code: string,
+		readonly status = 409,
+	) {
+		super(message);
+		this.name = "ServiceRunError";
+	}
+}
+
+export type StartServiceRunInput = {
+	command: string;
+	server?: string;
+	timeoutSeconds?: number;
+};
+
+export async function startServiceRun(
+	service: Pick<Service, "id" | "name">,
+	actor: { id: string; name: string },
+	input: StartServiceRunInput,
+) {
+	const sources = await db
+		.select({
+			deploymentId: deployments.id,
+			serverId: deployments.serverId,
+			specification: serviceRevisions.specification,
+		})
+		.from(deployments)
+		.innerJoin(
+			serviceRevisions,
+			eq(serviceRevisions.id, deployments.serviceRevisionId),
+		)
+		.where(
+			and(
+				eq(deployments.serviceId, service.id),
+				inArray(deployments.runtimeDesiredState, runtimeExpectedStates),
+				inArray(deployments.trafficState, activeTrafficStates),
+			),
+		)
+		.orderBy(desc(deployments.createdAt), desc(deployments.id));
+	if (sources.length === 0) {
+		throw new ServiceRunError(
+			"Service has no current deployment to run from",
+			"NO_CURRENT_DEPLOYMENT",
+		);
+	}
+
+	const server = await db
+		.select({
+			id: servers.id,
+			name: servers.name,
+			status: servers.status,
+		})
+		.from(servers)
+		.where(
+			input.server
+				? or(eq(servers.id, input.server), eq(servers.name, input.server))
+				: eq(servers.id, sources[0].serverId),
+		)
+		.then((rows) => rows[0]);
+	if (!server) {
+		throw new ServiceRunError("Server not found", "SERVER_NOT_FOUND", 404);
+	}
+	if (server.status !== "online") {
+		throw new ServiceRunError(
+			`Server ${server.name} is not online`,
+			"SERVER_OFFLINE",
+		);
+	}
+
+	const source =
+		sources.find((row) => row.serverId === server.id) ?? sources[0];
+	const specification = parseServiceRevisionSpec(source.specification);
+	// Volumes live on the replica's server, so a run elsewhere would see
+	// empty directories instead of the service's data.
+	if (specification.volumes.length > 0 && source.serverId !== server.id) {
+		throw new ServiceRunError(
+			"Services with volumes can only run on a server hosting a replica",
+			"VOLUME_SERVER_MISMATCH",
+		);
+	}
+
+	const runId = randomUUID();
+	const timeoutSeconds = input.timeoutSeconds ?? DEFAULT_RUN_TIMEOUT_SECONDS;
+	const signingPolicy = await getServiceSigningPolicy(service.id);
+	await db.transaction(async (tx) => {
+		const ipAddress = await assignContainerIp(tx, server.id);
+		await tx.insert(serviceRuns).values({
+			id: runId,
+			serviceId: service.id,
+			deploymentId: source.deploymentId,
+			serverId: server.id,
+			serverName: server.name,
+			ipAddress,
+			actor,
+			command: input.command,
+			timeoutSeconds,
+		});
+		await enqueueWork(
+			server.id,
+			"run",
+			{
+				runId,
+				serviceId: service.id,
+				deploymentId: source.deploymentId,
+				command: input.command,
+				timeoutSeconds,
+				container: buildTaskContainer({
+					taskId: runId,
+					serviceId: service.id,
+					serviceName: service.name,
+					deploymentId: source.deploymentId,
+					ipAddress,
+					specification,
+					signingPolicy,
+				}),
+			},
+			{ id: runId, tx },
+		);
+	});
+
+	return {
+		id: runId,
+		deploymentId: source.deploymentId,
+		serverId: server.id,
+		serverName: server.name,
+		command: input.command,
+		timeoutSeconds,
+		status: "pending" as const,
+	};
+}
+
+// Starts the release command of a rollout's revision on serverId. A unique
+// index keeps a rollout to one release run, so retrying the step returns the
+// existing run instead of running the command twice.
+export async function startReleaseRun(input: {
+	rolloutId: string;
+	serviceId: string;
+	serverId: string;
+}) {
+	const existing = await findReleaseRunId(input.rolloutId);
+	if (existing) return existing;
+
+	const revision = await getRolloutServiceRevision(input.rolloutId);
+	const command = revision.specification.releaseCommand;
+	if (!command) throw new Error("Revision has no release command");
+	const [service, server] = await Promise.all([
+		db
+			.select({ name: services.name })
+			.from(services)
+			.where(eq(services.id, input.serviceId))
+			.then((rows) => rows[0]),
+		db
+			.select({ name: servers.name })
+			.from(servers)
+			.where(eq(servers.id, input.serverId))
+			.then((rows) => rows[0]),
+	]);
+	if (!service) throw new Error("Service not found");
+	if (!server) throw new Error("Server not found");
+
+	const runId = randomUUID();
+	const signingPolicy = await getServiceSigningPolicy(input.serviceId);
+	const created = await db.transaction(async (tx) => {
+		const ipAddress = await assignContainerIp(tx, input.serverId);
+		const inserted = await tx
+			.insert(serviceRuns)
+			.values({
+				id: runId,
+				serviceId: input.serviceId,
+				rolloutId: input.rolloutId,
+				serverId: input.serverId,
+				serverName: server.name,
+				ipAddress,
+				command,
+				timeoutSeconds: RELEASE_RUN_TIMEOUT_SECONDS,
+			})
+			.onConflictDoNothing({
+				target: serviceRuns.rolloutId,
+				where: sql`${serviceRuns.rolloutId} is not null`,
+			})
+			.returning({ id: serviceRuns.id });
+		if (inserted.length === 0) return false;
+		await enqueueWork(
+			input.serverId,
+			"run",
+			{
+				runId,
+				serviceId: input.serviceId,
+				deploymentId: null,
+				rolloutId: input.rolloutId,
+				command,
+				timeoutSeconds: RELEASE_RUN_TIMEOUT_SECONDS,
+				container: buildTaskContainer({
+					taskId: runId,
+					serviceId: input.serviceId,
+					serviceName: service.name,
+					deploymentId: null,
+					ipAddress,
+					specification: revision.specification,
+					signingPolicy,
+				}),
+			},
+			{ id: runId, tx },
+		);
+		return true;
+	});
+	if (created) return runId;
+
+	const concurrent = await findReleaseRunId(input.rolloutId);
+	if (!concurrent) throw new Error("Release run was not created");
+	return concurrent;
+}
+
+async function findReleaseRunId(rolloutId: string) {
+	return db
+		.select({ id: serviceRuns.id })
+		.from(serviceRuns)
+		.where(eq(serviceRuns.rolloutId, rolloutId))
+		.then((rows) => rows[0]?.id ?? null);
+}
+
+export async function getReleaseRun(runId: string) {
+	return db
+		.select({
+			status: serviceRuns.status,
+			exitCode: serviceRuns.exitCode,
+			errorMessage: serviceRuns.errorMessage,
+		})
+		.from(serviceRuns)
+		.where(eq(serviceRuns.id, runId))
+		.then((rows) => rows[0] ?? null);
+}
+
+// Fails a release the rollout stopped waiting for, so an agent that comes
+// back online later does not run the command after the rollout has failed.
+export async function abandonReleaseRun(runId: string) {
+	await db.transaction(async (tx) => {
+		await tx
+			.update(workQueue)
+			.set({ status: "failed" })
+			.where(
+				and(
+					eq(workQueue.id, runId),
+					inArray(workQueue.status, ["pending", "processing"]),
+				),
+			);
+		await tx
+			.update(serviceRuns)
+			.set({
+				status: "failed",
+				errorMessage: "Release did not finish before the rollout timed out",
+				completedAt: new Date(),
+			})
+			.where(
+				and(
+					eq(serviceRuns.id, runId),
+					inArray(serviceRuns.status, ["pending", "running"]),
+				),
+			);
+	});
+}
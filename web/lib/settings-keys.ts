@@ -5,6 +5,7 @@ export const SETTING_KEYS = {
 	BUILD_TIMEOUT_MINUTES: "build_timeout_minutes",
 	ACME_EMAIL: "acme_email",
 	EDGE_DOMAIN: "edge_domain",
+	TRUSTED_PROXIES: "trusted_proxies",
 	AUTO_SUBDOMAIN_DOMAIN: "auto_subdomain_domain",
 	EMAIL_ALERTS_CONFIG: "email_alerts_config",
 	CONTROL_PLANE_UPDATE_STATE: "control_plane_update_state",
@@ -2,22 +2,31 @@ import { createHash, randomUUID } from "node:crypto";
 import { and, eq, isNull } from "drizzle-orm";
 import { db } from "@/db";
 import { githubRepos, services } from "@/db/schema";
+import {
+	gitTokenAuthForService,
+	resolveGitBranchCommit,
+} from "@/lib/git-remote";
 import { resolveGitHubCommit } from "@/lib/github";
 import { inngest } from "@/lib/inngest/client";
 import { inngestEvents } from "@/lib/inngest/events";
 import {
 	canonicalGitHubRepository,
+	isSshGitRepository,
 	resolvePersistedSourceFromRows,
 } from "@/lib/public-api";
 import { resolveRegistryImageHost } from "@/lib/registry-reference";
 import type { ServiceRevisionActor } from "@/lib/service-revision-actor";
 import { parseServiceRevisionSpec } from "@/lib/service-revision-changes";
+import { LOCAL_BUILD_BRANCH } from "@/lib/service-revision-spec";
 import {
 	cloneGitHubBuildServiceRevision,
+	createGitBuildServiceRevision,
 	createGitHubBuildServiceRevision,
+	createLocalBuildServiceRevision,
 } from "@/lib/service-revisions";
 
 type BuildTrigger = "manual" | "scheduled" | "push" | "preview";
+const contextChecksum = /^[0-9a-f]{64}$/;
 const fullCommitSha = /^[0-9a-f]{40}$/i;
 
 type ResolvedBuildInput = {
@@ -38,7 +47,7 @@ function deterministicRevisionId(key: string): string {
 	return `${hash.slice(0, 8)}-${hash.slice(8, 12)}-5${hash.slice(13, 16)}-a${hash.slice(17, 20)}-${hash.slice(20, 32)}`;
 }
 
-async function getGitHubBuildSource(serviceId: string) {
+async function getServiceBuildRows(serviceId: string) {
 	const [service, repo] = await Promise.all([
 		db
 			.select()
@@ -53,6 +62,13 @@ async function getGitHubBuildSource(serviceId: string) {
 			.limit(1)
 			.then((rows) => rows[0]),
 	]);
+	return { service, repo };
+}
+
+function githubBuildSource({
+	service,
+	repo,
+}: Awaited<ReturnType<typeof getServiceBuildRows>>) {
 	if (!service || service.sourceType !== "github") {
 		throw new Error("Active GitHub service not found");
 	}
@@ -67,6 +83,10 @@ async function getGitHubBuildSource(serviceId: string) {
 	};
 }
 
+async function getGitHubBuildSource(serviceId: string) {
+	return githubBuildSource(await getServiceBuildRows(serviceId));
+}
+
 async function sendBuildTrigger(
 	data: Parameters<typeof inngestEvents.buildTrigger.create>[0],
 	eventId?: string,
@@ -91,7 +111,7 @@ export async function triggerResolvedBuildInternal(
 async function queueResolvedBuild(
 	serviceId: string,
 	input: ResolvedBuildInput,
-	{ service, source }: Awaited<ReturnType<typeof getGitHubBuildSource>>,
+	{ service, source }: ReturnType<typeof githubBuildSource>,
 ) {
 	if (!fullCommitSha.test(input.commitSha)) {
 		throw new Error("Commit SHA must be a full 40-character hexadecimal SHA");
@@ -152,6 +172,114 @@ async function queueResolvedBuild(
 	return { buildId: null, serviceRevisionId, status: "queued" as const };
 }
 
+async function queueGitBuild(
+	service: typeof services.$inferSelect,
+	input: {
+		trigger: "manual" | "scheduled";
+		actor: ServiceRevisionActor;
+		commitSha?: string;
+	},
+) {
+	if (service.previewOfService) {
+		throw new Error("Preview services build from their pull request");
+	}
+	const source = resolvePersistedSourceFromRows(service, undefined);
+	if (source.type !== "git" || !source.repository) {
+		throw new Error("No git repository configured for this service");
+	}
+	let commitSha = input.commitSha?.toLowerCase();
+	if (commitSha !== undefined && !fullCommitSha.test(commitSha)) {
+		throw new Error("Commit SHA must be a full 40-character hexadecimal SHA");
+	}
+	if (!commitSha) {
+		if (isSshGitRepository(source.repository)) {
+			throw new Error(
+				"SSH git sources need an explicit commit SHA; deploy with tc deploy or pass commitSha",
+			);
+		}
+		commitSha = await resolveGitBranchCommit(
+			source.repository,
+			source.branch,
+			await gitTokenAuthForService(service.id),
+		);
+	}
+
+	const registryHost = resolveRegistryImageHost();
+	const serviceRevisionId = randomUUID();
+	const image = `${registryHost}/${service.projectId}/${service.id}:revision-${serviceRevisionId}`;
+	await createGitBuildServiceRevision({
+		id: serviceRevisionId,
+		serviceId: service.id,
+		image,
+		commitSha,
+		expectedRepository: source.repository,
+		expectedBranch: source.branch,
+		actor: input.actor,
+	});
+
+	const buildRequestId = randomUUID();
+	await sendBuildTrigger({
+		serviceId: service.id,
+		serviceRevisionId,
+		buildRequestId,
+		trigger: input.trigger,
+		commitSha,
+		commitMessage: input.commitSha
+			? `Selected commit on ${source.branch}`
+			: `Latest commit on ${source.branch}`,
+		branch: source.branch,
+		actor: input.actor,
+	});
+
+	return { buildId: null, serviceRevisionId, status: "queued" as const };
+}
+
+export async function triggerLocalBuildInternal(
+	serviceId: string,
+	input: {
+		contextId: string;
+		checksum: string;
+		actor: ServiceRevisionActor;
+	},
+) {
+	if (!contextChecksum.test(input.checksum)) {
+		throw new Error("Build context checksum must be a SHA-256 hex digest");
+	}
+	const service = await db
+		.select({ id: services.id, projectId: services.projectId })
+		.from(services)
+		.where(and(eq(services.id, serviceId), isNull(services.deletedAt)))
+		.limit(1)
+		.then((rows) => rows[0]);
+	if (!service) throw new Error("Service not found");
+
+	const registryHost = resolveRegistryImageHost();
+	const serviceRevisionId = randomUUID();
+	const image = `${registryHost}/${service.projectId}/${service.id}:revision-${serviceRevisionId}`;
+	await createLocalBuildServiceRevision({
+		id: serviceRevisionId,
+		serviceId,
+		image,
+		contextId: input.contextId,
+		checksum: input.checksum,
+		actor: input.actor,
+	});
+
+	const buildRequestId = randomUUID();
+	await sendBuildTrigger({
+		serviceId,
+		serviceRevisionId,
+		buildRequestId,
+		trigger: "local",
+		commitSha: input.checksum,
+		commitMessage: "Local build context",
+		branch: LOCAL_BUILD_BRANCH,
+		actor: input.actor,
+	});
+
+	return { buildId: null, serviceRevisionId, status: "queued" as const };
+}
+
 export async function requeueBuildRevisionInternal(input: {
 	serviceId: string;
 	serviceRevisionId: string;
@@ -165,7 +293,10 @@ export async function requeueBuildRevisionInternal(input: {
 		actor: input.actor,
 	});
 	const specification = parseServiceRevisionSpec(revision.specification);
-	if (specification.source.type !== "github")
+	if (
+		specification.source.type !== "github" &&
+		specification.source.type !== "git"
+	)
 		throw new Error("Invalid retry revision");
 
 	const buildRequestId = randomUUID();
@@ -191,8 +322,20 @@ export async function triggerBuildInternal(
 	serviceId: string,
 	trigger: "manual" | "scheduled",
 	actor: ServiceRevisionActor,
+	options: { commitSha?: string } = {},
 ) {
-	const sourceContext = await getGitHubBuildSource(serviceId);
+	const rows = await getServiceBuildRows(serviceId);
+	if (rows.service?.sourceType === "git") {
+		return queueGitBuild(rows.service, {
+			trigger,
+			actor,
+			commitSha: options.commitSha,
+		});
+	}
+	if (options.commitSha) {
+		throw new Error("Only git sources accept an explicit build commit");
+	}
+	const sourceContext = githubBuildSource(rows);
 	if (
 		sourceContext.service.previewOfService &&
 		sourceContext.service.previewGitRef
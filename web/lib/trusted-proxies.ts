@@ -0,0 +1,40 @@
+import { isIP } from "node:net";
+
+const MAX_TRUSTED_PROXIES = 100;
+
+/**
+ * Parses the load balancer addresses proxies accept X-Forwarded-* headers
+ * from. Entries are IP addresses or CIDR ranges separated by commas or
+ * whitespace.
+ */
+export function parseTrustedProxies(input: string): string[] {
+	const entries = [
+		...new Set(
+			input
+				.split(/[\s,]+/)
+				.map((entry) => entry.trim())
+				.filter(Boolean),
+		),
+	];
+	if (entries.length > MAX_TRUSTED_PROXIES) {
+		throw new Error(
+			`At most ${MAX_TRUSTED_PROXIES} trusted proxies can be configured`,
+		);
+	}
+	for (const entry of entries) {
+		if (!isTrustedProxy(entry)) {
+			throw new Error(
+				`Invalid trusted proxy "${entry}": use an IP address or CIDR range`,
+			);
+		}
+	}
+	return entries.sort();
+}
+
+function isTrustedProxy(value: string) {
+	const [address, prefix, ...rest] = value.split("/");
+	const version = isIP(address);
+	if (version === 0 || rest.length > 0) return false;
+	if (prefix === undefined) return true;
+	return /^\d+$/.test(prefix) && Number(prefix) <= (version === 4 ? 32 : 128);
+}
@@ -101,6 +101,7 @@ type QueryLogsByServiceOptions = {
 	before?: string;
 	logType?: LogType;
 	serverId?: string;
+	deploymentId?: string;
 	search?: string;
 	range?: LogTimeRange;
 	signal?: AbortSignal;
@@ -129,7 +130,8 @@ function serviceLogSearchFields(
 }
 
 function buildServiceLogFilter(options: QueryLogsByServiceOptions): string {
-	const { serviceId, logType, serverId, search, range } = options;
+	const { serviceId, logType, serverId, deploymentId, search, range } =
+		options;
 	let query = formatLogSqlExactFilter("service_id", serviceId);
 	if (logType === "http") {
 		query += ` log_type:http`;
@@ -145,6 +147,9 @@ function buildServiceLogFilter(options: QueryLogsByServiceOptions): string {
 	if (serverId) {
 		query += ` ${formatLogSqlExactFilter("server_id", serverId)}`;
 	}
+	if (deploymentId) {
+		query += ` ${formatLogSqlExactFilter("deployment_id", deploymentId)}`;
+	}
 	if (range) {
 		query += ` _time:${range}`;
 	}
@@ -314,6 +319,7 @@ export async function queryLogsByDeployment(
 export type BuildLog = {
 	_msg: string;
 	_time: string;
+	event_id?: string;
 	build_id: string;
 	service_id: string;
 	project_id: string;
@@ -383,6 +389,7 @@ export type CronLog = {
 	path: string;
 	source: "scheduled" | "manual";
 	scheduled_for: string;
+	attempt: number;
 	started_at: string;
 	finished_at: string;
 	result: "succeeded" | "failed" | "skipped";
@@ -472,18 +479,41 @@ export async function queryLogsByRollout(
 
 export async function queryLogsByBuild(
 	buildId: string,
-	{ limit = 1000, search }: { limit?: number; search?: string } = {},
+	{
+		limit = 1000,
+		search,
+		after,
+		signal,
+	}: {
+		limit?: number;
+		search?: string;
+		after?: { time: string; eventId: string };
+		signal?: AbortSignal;
+	} = {},
 ): Promise<{ logs: BuildLog[] }> {
 	let query = `${formatLogSqlExactFilter("build_id", buildId)} log_type:build`;
 	const searchFilter = formatLogSqlSearchFilter(search);
 	if (searchFilter) {
 		query += ` ${searchFilter}`;
 	}
-	query += " | sort by (_time)";
+	// Lines written by older agents have no event ID, so they can only be
+	// followed by time.
+	const afterTime = normalizeLogCursor(after?.time);
+	if (afterTime && after?.eventId) {
+		if (!isPublicServiceLogEventId(after.eventId)) {
+			throw new RangeError("Invalid log cursor");
+		}
+		const eventId = JSON.stringify(after.eventId);
+		query += ` (_time:>${afterTime} OR (_time:>=${afterTime} _time:<=${afterTime} event_id:>${eventId}))`;
+	} else if (afterTime) {
+		query += ` _time:>${afterTime}`;
+	}
+	query += " | sort by (_time, event_id)";
 
 	const { logs } = await fetchLogQuery<BuildLog>(query, {
 		limit,
 		errorLabel: "build logs",
+		signal: signal ? providerSignal(signal) : undefined,
 	});
 
 	return { logs };
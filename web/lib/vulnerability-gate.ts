@@ -0,0 +1,56 @@
+import type { VulnerabilitySummary } from "@/db/schema";
+
+export const vulnerabilitySeverities = [
+	"critical",
+	"high",
+	"medium",
+	"low",
+	"unknown",
+] as const;
+
+export function parseVulnerabilitySummary(
+	value: unknown,
+): VulnerabilitySummary | null {
+	if (!value || typeof value !== "object") return null;
+	const counts = value as Record<string, unknown>;
+	const summary = {} as VulnerabilitySummary;
+	for (const severity of vulnerabilitySeverities) {
+		const count = counts[severity] ?? 0;
+		if (typeof count !== "number" || !Number.isInteger(count) || count < 0) {
+			return null;
+		}
+		summary[severity] = count;
+	}
+	return summary;
+}
+
+/**
+ * Decides whether a completed build group may roll out under the service's
+ * critical vulnerability threshold. Builds without a scan block the rollout
+ * once a threshold is set, so an agent without a vulnerability database
+ * cannot bypass the policy.
+ */
+export function evaluateVulnerabilityGate(
+	maxCritical: number | null,
+	groupBuilds: Array<{
+		targetPlatform: string;
+		vulnerabilities: VulnerabilitySummary | null;
+	}>,
+): { blocked: false } | { blocked: true; reason: string } {
+	if (maxCritical === null) return { blocked: false };
+	for (const build of groupBuilds) {
+		if (!build.vulnerabilities) {
+			return {
+				blocked: true,
+				reason: `Rollout blocked: the ${build.targetPlatform} image was not scanned for vulnerabilities`,
+			};
+		}
+		if (build.vulnerabilities.critical > maxCritical) {
+			return {
+				blocked: true,
+				reason: `Rollout blocked: the ${build.targetPlatform} image has ${build.vulnerabilities.critical} critical vulnerabilities (threshold ${maxCritical})`,
+			};
+		}
+	}
+	return { blocked: false };
+}
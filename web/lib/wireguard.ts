@@ -1,8 +1,8 @@
-import { and, eq, isNotNull, ne, sql } from "drizzle-orm";
+import { and, eq, inArray, isNotNull, ne, sql } from "drizzle-orm";
 import { DrizzleQueryError } from "drizzle-orm/errors";
 import { Address4 } from "ip-address";
 import { db } from "@/db";
-import { deployments, servers } from "@/db/schema";
+import { deployments, servers, serviceRuns } from "@/db/schema";
 import { CONTAINER_SUBNET_PREFIX, WIREGUARD_SUBNET_PREFIX } from "./constants";
 
 type AllocationTransaction = Parameters<
@@ -102,7 +102,21 @@ export async function assignContainerIp(
 			and(eq(deployments.serverId, serverId), isNotNull(deployments.ipAddress)),
 		);
 
-	const usedIps = new Set(existingDeployments.map((d) => d.ipAddress));
+	// One-off runs hold their address only while their container exists.
+	const activeRuns = await tx
+		.select({ ipAddress: serviceRuns.ipAddress })
+		.from(serviceRuns)
+		.where(
+			and(
+				eq(serviceRuns.serverId, serverId),
+				inArray(serviceRuns.status, ["pending", "running"]),
+				isNotNull(serviceRuns.ipAddress),
+			),
+		);
+
+	const usedIps = new Set(
+		[...existingDeployments, ...activeRuns].map((row) => row.ipAddress),
+	);
 
 	for (let hostPart = 2; hostPart <= 254; hostPart++) {
 		const ip = `${CONTAINER_SUBNET_PREFIX}.${server.subnetId}.${hostPart}`;
@@ -126,6 +140,7 @@ export async function getWireGuardPeers(
 			wireguardPublicKey: servers.wireguardPublicKey,
 			publicIp: servers.publicIp,
 			privateIp: servers.privateIp,
+			signingPublicKey: servers.signingPublicKey,
 		})
 		.from(servers)
 		.where(
@@ -153,6 +168,10 @@ export async function getWireGuardPeers(
 			publicKey: s.wireguardPublicKey,
 			allowedIps: `${WIREGUARD_SUBNET_PREFIX}.${s.subnetId}.0/24,${CONTAINER_SUBNET_PREFIX}.${s.subnetId}.0/24`,
 			endpoint,
+			// Lets agents address each other and verify signed peer requests.
+			serverId: s.id,
+			...(s.wireguardIp ? { wireguardIp: s.wireguardIp } : {}),
+			...(s.signingPublicKey ? { signingPublicKey: s.signingPublicKey } : {}),
 		};
 	});
 }
@@ -1,18 +1,23 @@
 import { randomUUID } from "node:crypto";
-import { and, eq, inArray, isNotNull, sql } from "drizzle-orm";
+import { and, eq, inArray, isNotNull, ne, sql } from "drizzle-orm";
 import { db } from "@/db";
 import {
 	deployments,
+	rollouts,
 	servers,
 	serviceCommands,
+	serviceRuns,
+	type VulnerabilitySummary,
 	volumeBackups,
 	workQueue,
 } from "@/db/schema";
 import type { WorkQueue } from "@/db/types";
+import type { ExpectedContainer } from "@/lib/agent/expected-state";
 import { MINUTE_IN_MILLISECONDS, subtractMilliseconds } from "@/lib/date";
 import { inngest } from "@/lib/inngest/client";
 import { inngestEvents } from "@/lib/inngest/events";
 import { reportOperationFailure, reportServerError } from "@/lib/server-errors";
+import { parseVulnerabilitySummary } from "@/lib/vulnerability-gate";
 import { notifyWorkAvailable } from "@/lib/work-queue-notifications";
 
 export const WORK_QUEUE_MAX_ATTEMPTS = 3;
@@ -42,6 +47,15 @@ export type WorkPayloadByType = {
 		containerId: string;
 		command: string;
 	};
+	run: {
+		runId: string;
+		serviceId: string;
+		deploymentId: string | null;
+		rolloutId?: string;
+		command: string;
+		timeoutSeconds: number;
+		container: ExpectedContainer;
+	};
 	deploy: ReconcileWorkPayload;
 	reconcile: ReconcileWorkPayload;
 	stop: { deploymentId: string; containerId: string | null };
@@ -85,15 +99,26 @@ export type WorkPayloadByType = {
 	};
 	upgrade_agent: { targetVersion: string; expectedSha256: string };
 	sync_registries: { version: string };
+	scan_image: { rolloutId: string; image: string };
 };
 
-export type WorkItemResult = {
-	type: "command";
-	output?: string;
-	exitCode?: number;
-	outputTruncated?: boolean;
-	timedOut?: boolean;
-};
+export type WorkItemResult =
+	| {
+			type: "command";
+			output?: string;
+			exitCode?: number;
+			outputTruncated?: boolean;
+			timedOut?: boolean;
+		}
+	| {
+			type: "run";
+			exitCode?: number;
+			timedOut?: boolean;
+		}
+	| {
+			type: "scan_image";
+			vulnerabilities?: VulnerabilitySummary;
+		};
 
 export type CompletedWorkItem = {
 	id: string;
@@ -255,6 +280,39 @@ export async function completeWorkItemResults(
 						})
 						.where(eq(serviceCommands.id, item.id));
 				}
+				if (item.type === "run") {
+					const runResult = result.result;
+					await tx
+						.update(serviceRuns)
+						.set({
+							status: runResult?.timedOut
+								? "timed_out"
+								: result.status === "completed" && runResult?.exitCode === 0
+									? "succeeded"
+									: "failed",
+							exitCode: runResult?.exitCode,
+							errorMessage: result.error ?? null,
+							completedAt: new Date(),
+						})
+						.where(eq(serviceRuns.id, item.id));
+				}
+				if (item.type === "scan_image" && result.status === "completed") {
+					const vulnerabilities = parseVulnerabilitySummary(
+						result.result?.type === "scan_image"
+							? result.result.vulnerabilities
+							: undefined,
+					);
+					if (!vulnerabilities) {
+						throw new WorkItemResultTypeMismatchError();
+					}
+					const payload = JSON.parse(
+						item.payload,
+					) as WorkPayloadByType["scan_image"];
+					await tx
+						.update(rollouts)
+						.set({ vulnerabilities })
+						.where(eq(rollouts.id, payload.rolloutId));
+				}
 
 				return item;
 			});
@@ -298,6 +356,19 @@ class WorkItemResultTypeMismatchError extends Error {}
 
 function isValidWorkItemResult(result: WorkItemResult | undefined): boolean {
 	if (result === undefined) return true;
+	if (result.type === "scan_image") {
+		return (
+			result.vulnerabilities === undefined ||
+			parseVulnerabilitySummary(result.vulnerabilities) !== null
+		);
+	}
+	if (result.type === "run") {
+		return (
+			(result.exitCode === undefined ||
+				(Number.isInteger(result.exitCode) && result.exitCode >= -1)) &&
+			(result.timedOut === undefined || typeof result.timedOut === "boolean")
+		);
+	}
 	return (
 		result.type === "command" &&
 		(result.output === undefined ||
@@ -344,8 +415,33 @@ export async function renewActiveWorkItems(
 	return rejected;
 }
 
+/**
+ * Task runs execute beside the agent's serialized work slot, so only other
+ * active items keep a server from leasing the next work item.
+ */
+export async function holdsWorkSlot(
+	serverId: string,
+	items: ActiveWorkItem[],
+): Promise<boolean> {
+	if (items.length === 0) return false;
+
+	const rows = await db
+		.select({ id: workQueue.id })
+		.from(workQueue)
+		.where(
+			and(
+				eq(workQueue.serverId, serverId),
+				inArray(workQueue.id, items.map((item) => item.id)),
+				ne(workQueue.type, "run"),
+			),
+		)
+		.limit(1);
+	return rows.length > 0;
+}
+
 export async function claimNextWorkItem(
 	serverId: string,
+	{ buildOnly = false }: { buildOnly?: boolean } = {},
 ): Promise<LeasedWorkItem | null> {
 	const staleThreshold = subtractMilliseconds(
 		new Date(),
@@ -369,6 +465,7 @@ export async function claimNextWorkItem(
 			FOR UPDATE SKIP LOCKED
 			LIMIT 1
 		)
+		${buildOnly ? sql`AND type = 'build'` : sql``}
 		RETURNING id, type, payload, attempts
 	`);
 
@@ -387,6 +484,12 @@ export async function claimNextWorkItem(
 			.set({ status: "running", startedAt: new Date() })
 			.where(eq(serviceCommands.id, row.id));
 	}
+	if (row.type === "run") {
+		await db
+			.update(serviceRuns)
+			.set({ status: "running", startedAt: new Date() })
+			.where(eq(serviceRuns.id, row.id));
+	}
 	if (row.type === "upgrade_agent") {
 		await markAgentUpgradeStarted(serverId, row.payload);
 	}
@@ -421,7 +524,7 @@ function claimableWorkCondition(serverId: string, staleThreshold: Date) {
 			status = 'pending'
 			OR (
 				status = 'processing'
-				AND type <> 'command'
+				AND type NOT IN ('command', 'run')
 				AND started_at < ${staleThreshold}
 				AND attempts < ${WORK_QUEUE_MAX_ATTEMPTS}
 			)
@@ -605,6 +708,16 @@ async function runWorkItemCompletionSideEffects(
 		return;
 	}
 
+	if (item.type === "run" && item.payload) {
+		await publishReleaseRunResult(item);
+		return;
+	}
+
+	if (item.type === "scan_image" && item.payload) {
+		await publishImageScanResult(item);
+		return;
+	}
+
 	if (item.type !== "create_manifest" || !item.payload) {
 		return;
 	}
@@ -662,6 +775,54 @@ async function runWorkItemCompletionSideEffects(
 	}
 }
 
+export async function publishReleaseRunResult(
+	item: Pick<WorkQueue, "id" | "payload">,
+): Promise<void> {
+	try {
+		const payload = JSON.parse(item.payload) as Partial<
+			WorkPayloadByType["run"]
+		>;
+		if (!payload.rolloutId) return;
+		await inngest.send(
+			inngestEvents.resourceStatusChanged.create({
+				type: "run",
+				id: item.id,
+				parentType: "rollout",
+				parentId: payload.rolloutId,
+			}),
+		);
+	} catch (error) {
+		reportServerError(error, "work-queue.release.completion", {
+			tags: { workItemId: item.id },
+		});
+		console.error("[work-queue] failed to publish release result:", error);
+	}
+}
+
+export async function publishImageScanResult(
+	item: Pick<WorkQueue, "id" | "payload">,
+): Promise<void> {
+	try {
+		const payload = JSON.parse(item.payload) as Partial<
+			WorkPayloadByType["scan_image"]
+		>;
+		if (!payload.rolloutId) return;
+		await inngest.send(
+			inngestEvents.resourceStatusChanged.create({
+				type: "workItem",
+				id: item.id,
+				parentType: "rollout",
+				parentId: payload.rolloutId,
+			}),
+		);
+	} catch (error) {
+		reportServerError(error, "work-queue.image-scan.completion", {
+			tags: { workItemId: item.id },
+		});
+		console.error("[work-queue] failed to publish image scan result:", error);
+	}
+}
+
 async function runAgentUpgradeCompletionSideEffects(
 	item: WorkQueue,
 	result: CompletedWorkItem,
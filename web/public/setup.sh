@@ -335,6 +335,23 @@ if ! /usr/local/bin/crane version &>/dev/null; then
 fi
 echo "✓ crane installed"
 
+step "Installing cosign (for image signature verification)..."
+COSIGN_VERSION="v2.5.3"
+COSIGN_ARCH="amd64"
+if [ "$ARCH" = "aarch64" ]; then
+  COSIGN_ARCH="arm64"
+fi
+if [ -x /usr/local/bin/cosign ]; then
+  echo "cosign already installed, skipping"
+else
+  curl -fsSL "https://github.com/sigstore/cosign/releases/download/${COSIGN_VERSION}/cosign-linux-${COSIGN_ARCH}" -o /usr/local/bin/cosign
+  chmod +x /usr/local/bin/cosign
+fi
+if ! /usr/local/bin/cosign version &>/dev/null; then
+  error "Failed to install cosign"
+fi
+echo "✓ cosign installed"
+
 step "Downloading Techulus Cloud agent..."
 LATEST_VERSION=$(curl -fsSL "https://api.github.com/repos/techulus/cloud/releases/latest" | sed -n 's/.*"tag_name": *"\([^"]*\)".*/\1/p')
 if [ -z "$LATEST_VERSION" ]; then
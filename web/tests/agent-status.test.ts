@@ -50,6 +50,12 @@ vi.mock("@/lib/victoria-logs", () => ({
 vi.mock("@/lib/work-queue", () => ({
 	enqueueWork: vi.fn(),
 }));
+vi.mock("@/lib/rollout-enqueue", () => ({
+	sendRolloutCreated: vi.fn(),
+}));
+vi.mock("@/lib/service-revisions", () => ({
+	cloneActiveRevisionForServerlessScaling: vi.fn(),
+}));
 
 import {
 	applyStatusReport,
@@ -59,6 +65,10 @@ import {
 	shouldAttachReportedContainer,
 } from "@/lib/agent-status";
 import { inngest } from "@/lib/inngest/client";
+import { sendRolloutCreated } from "@/lib/rollout-enqueue";
+import {
+	cloneActiveRevisionForServerlessScaling,
+} from "@/lib/service-revisions";
 
 beforeEach(() => {
 	mocks.selectResults.length = 0;
@@ -128,6 +138,44 @@ describe("agent status CrowdSec health", () => {
 	});
 });
 
+describe("agent status certificate issues", () => {
+	it("replaces stored issues with each proxy report and keeps them otherwise", async () => {
+		const certificateIssues = [
+			{
+				domain: "app.example.com",
+				issue: "expiring",
+				notAfter: "2026-08-10T00:00:00Z",
+				sans: ["app.example.com"],
+			},
+			{ domain: "bad.example.com", issue: "unknown" },
+		];
+
+		await applyStatusReport("server_1", {
+			containers: [],
+			certificateIssues,
+		});
+		expect(mocks.updateData[0]).toEqual(
+			expect.objectContaining({ certificateIssues: [certificateIssues[0]] }),
+		);
+
+		mocks.updateData.length = 0;
+		await applyStatusReport("server_1", {
+			containers: [],
+			certificateIssues: [],
+		});
+		expect(mocks.updateData[0]).toEqual(
+			expect.objectContaining({ certificateIssues: [] }),
+		);
+
+		mocks.updateData.length = 0;
+		await applyStatusReport("server_1", {
+			containers: [],
+			certificateIssues: null,
+		});
+		expect(mocks.updateData[0]).not.toHaveProperty("certificateIssues");
+	});
+});
+
 describe("agent status serverless attachment", () => {
 	it("does not attach reported containers to sleeping deployments", () => {
 		expect(shouldAttachReportedContainer("pending")).toBe(true);
@@ -219,6 +267,76 @@ describe("agent status serverless attachment", () => {
 	});
 });
 
+describe("agent status serverless scaling", () => {
+	it("turns gateway scale transitions into replica rollouts", async () => {
+		vi.mocked(cloneActiveRevisionForServerlessScaling)
+			.mockResolvedValueOnce({
+				created: true,
+				rolloutId: "rollout_1",
+				revisionId: "revision_2",
+				targetReplicas: 3,
+			})
+			.mockResolvedValueOnce({ created: false, reason: "unchanged" });
+		mocks.selectResults.push([{ isProxy: true }], [{ isProxy: true }]);
+		// Transitions exactly as the agent marshals them.
+		const transitions = JSON.parse(
+			JSON.stringify([
+				{
+					id: "t1",
+					type: "scale_up",
+					deploymentId: "",
+					serviceId: "svc_1",
+					replicas: 3,
+					activeRequests: 5,
+				},
+				{
+					id: "t2",
+					type: "scale_down",
+					deploymentId: "",
+					serviceId: "svc_1",
+					replicas: 1,
+				},
+				{ id: "t3", type: "scale_up", serviceId: "svc_1", replicas: 0 },
+			]),
+		);
+
+		const { serverlessTransitionResults } = await applyStatusReport(
+			"server_1",
+			{ containers: [] },
+			transitions,
+		);
+
+		expect(cloneActiveRevisionForServerlessScaling).toHaveBeenNthCalledWith(
+			1,
+			{ serviceId: "svc_1", direction: "up", targetReplicas: 3 },
+		);
+		expect(cloneActiveRevisionForServerlessScaling).toHaveBeenNthCalledWith(
+			2,
+			{ serviceId: "svc_1", direction: "down", targetReplicas: 1 },
+		);
+		expect(sendRolloutCreated).toHaveBeenCalledTimes(1);
+		expect(sendRolloutCreated).toHaveBeenCalledWith("rollout_1", "svc_1");
+		expect(serverlessTransitionResults).toEqual([
+			{ id: "t1", type: "scale_up", serviceId: "svc_1", outcome: "applied" },
+			{
+				id: "t2",
+				type: "scale_down",
+				serviceId: "svc_1",
+				outcome: "already_applied",
+				reason: "unchanged",
+			},
+			{
+				id: "t3",
+				type: "scale_up",
+				serviceId: "svc_1",
+				outcome: "rejected",
+				reason: "malformed transition",
+			},
+		]);
+		expect(getSleepTransitionDeploymentIds(transitions).size).toBe(0);
+	});
+});
+
 describe("agent status deployment cleanup", () => {
 	it("deletes a removed containerless deployment missing from the report", async () => {
 		mocks.selectResults.push([
@@ -50,6 +50,10 @@ vi.mock("@/lib/inngest/events", () => ({
 		buildTrigger: { create: vi.fn() },
 	},
 }));
+vi.mock("@/lib/build-control", () => ({
+	cancelBuildInternal: vi.fn(),
+	rebuildBuildInternal: vi.fn(),
+}));
 vi.mock("@/lib/trigger-build", () => ({
 	triggerResolvedBuildInternal: mocks.triggerResolvedBuildInternal,
 }));
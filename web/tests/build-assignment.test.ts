@@ -31,6 +31,7 @@ vi.mock("@/db", () => ({ db: mocks.db }));
 vi.mock("@/db/queries", () => ({ getSetting: mocks.getSetting }));
 
 import {
+	assignBuildServersForRevision,
 	getTargetPlatformsForRevision,
 	selectBuildServerForRevision,
 } from "@/lib/build-assignment";
@@ -163,6 +164,36 @@ describe("revision-backed build assignment", () => {
 		).resolves.toBe("server-arm");
 	});
 
+	it("fans platforms out to native builders when each has one online", async () => {
+		mocks.getSetting.mockResolvedValue(null);
+		mocks.queryResults.push([
+			{ id: "server-amd", meta: { arch: "amd64" } },
+			{ id: "server-arm", meta: { arch: "arm64" } },
+		]);
+		await expect(
+			assignBuildServersForRevision(specification(), [
+				"linux/amd64",
+				"linux/arm64",
+			]),
+		).resolves.toEqual([
+			{ platforms: ["linux/amd64"], serverId: "server-amd" },
+			{ platforms: ["linux/arm64"], serverId: "server-arm" },
+		]);
+	});
+
+	it("builds every platform on one server when an architecture has no native builder", async () => {
+		mocks.getSetting.mockResolvedValue(null);
+		mocks.queryResults.push([{ id: "server-arm", meta: { arch: "arm64" } }]);
+		await expect(
+			assignBuildServersForRevision(specification(), [
+				"linux/amd64",
+				"linux/arm64",
+			]),
+		).resolves.toEqual([
+			{ platforms: ["linux/amd64", "linux/arm64"], serverId: "server-arm" },
+		]);
+	});
+
 	it("limits stateless build assignment to configured build servers", async () => {
 		mocks.getSetting.mockResolvedValue(["server-arm"]);
 		mocks.queryResults.push([{ id: "server-arm", meta: { arch: "arm64" } }]);
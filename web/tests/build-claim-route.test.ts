@@ -162,4 +162,191 @@ describe("agent build claim", () => {
 			gitRef: "refs/pull/42/merge",
 		});
 	});
+
+	it("points a local build at its uploaded context instead of a clone", async () => {
+		const checksum = "ab".repeat(32);
+		mocks.updateResults.push([
+			{ ...build, commitSha: checksum, branch: "local" },
+		]);
+		mocks.selectResults.push(
+			[{ id: "service-1", projectId: "project-1", previewGitRef: null }],
+			[
+				{
+					specification: {
+						schemaVersion: 3,
+						image: "registry.example.com/project/service:revision-1",
+						source: {
+							type: "local",
+							contextId: "context-1",
+							checksum,
+							rootDir: "apps/web",
+						},
+						hostname: "service-1",
+						stateful: false,
+						serverless: {
+							enabled: false,
+							sleepAfterSeconds: 300,
+							wakeTimeoutSeconds: 300,
+						},
+						healthCheck: null,
+						startCommand: null,
+						resourceLimits: { cpuCores: null, memoryMb: null },
+						placement: { mode: "manual" },
+						placements: [],
+						ports: [],
+						secrets: [],
+						volumes: [],
+					},
+				},
+			],
+			[{ id: "context-1", checksum }],
+		);
+
+		const response = await POST(
+			new Request("http://localhost/api/v1/agent/builds/build-amd64", {
+				method: "POST",
+			}) as NextRequest,
+			{ params: Promise.resolve({ id: "build-amd64" }) },
+		);
+
+		expect(response.status).toBe(200);
+		expect(await response.json()).toMatchObject({
+			build: { commitSha: checksum, branch: "local" },
+			cloneUrl: "",
+			contextUrl: "/api/v1/agent/builds/build-amd64/context",
+			contextChecksum: checksum,
+			rootDir: "apps/web",
+		});
+	});
+
+	it("hands a git build the snapshotted credential without decrypting it", async () => {
+		mocks.updateResults.push([build]);
+		mocks.selectResults.push(
+			[{ id: "service-1", projectId: "project-1", previewGitRef: null }],
+			[
+				{
+					specification: {
+						schemaVersion: 3,
+						image: "registry.example.com/project/service:revision-1",
+						source: {
+							type: "git",
+							repository: "git@git.example.com:acme/app.git",
+							branch: "main",
+							commitSha: build.commitSha,
+							rootDir: null,
+							submodules: true,
+							lfs: false,
+							authentication: { type: "ssh_key" },
+						},
+						build: {
+							dockerfile: "docker/Dockerfile.prod",
+							target: "runtime",
+							args: { NODE_ENV: "production" },
+						},
+						hostname: "service-1",
+						stateful: false,
+						serverless: {
+							enabled: false,
+							sleepAfterSeconds: 300,
+							wakeTimeoutSeconds: 300,
+						},
+						healthCheck: null,
+						startCommand: null,
+						resourceLimits: { cpuCores: null, memoryMb: null },
+						placement: { mode: "manual" },
+						placements: [],
+						ports: [],
+						secrets: [],
+						volumes: [],
+					},
+				},
+			],
+			[
+				{
+					serviceId: "service-1",
+					type: "ssh_key",
+					username: null,
+					encryptedSecret: "encrypted-key",
+					knownHosts: "git.example.com ssh-ed25519 AAAA",
+				},
+			],
+		);
+
+		const response = await POST(
+			new Request("http://localhost/api/v1/agent/builds/build-amd64", {
+				method: "POST",
+			}) as NextRequest,
+			{ params: Promise.resolve({ id: "build-amd64" }) },
+		);
+
+		expect(response.status).toBe(200);
+		expect(await response.json()).toMatchObject({
+			cloneUrl: "git@git.example.com:acme/app.git",
+			gitAuth: {
+				type: "ssh_key",
+				username: "",
+				encryptedSecret: "encrypted-key",
+				knownHosts: "git.example.com ssh-ed25519 AAAA",
+			},
+			submodules: true,
+			lfs: false,
+			dockerfile: "docker/Dockerfile.prod",
+			target: "runtime",
+			buildArgs: { NODE_ENV: "production" },
+		});
+	});
+
+	it("fails a git build whose credential type changed after queueing", async () => {
+		mocks.updateResults.push([build], [{ id: build.id }]);
+		mocks.selectResults.push(
+			[{ id: "service-1", projectId: "project-1", previewGitRef: null }],
+			[
+				{
+					specification: {
+						schemaVersion: 3,
+						image: "registry.example.com/project/service:revision-1",
+						source: {
+							type: "git",
+							repository: "https://git.example.com/acme/app.git",
+							branch: "main",
+							commitSha: build.commitSha,
+							rootDir: null,
+							submodules: false,
+							lfs: false,
+							authentication: { type: "https_token" },
+						},
+						hostname: "service-1",
+						stateful: false,
+						serverless: {
+							enabled: false,
+							sleepAfterSeconds: 300,
+							wakeTimeoutSeconds: 300,
+						},
+						healthCheck: null,
+						startCommand: null,
+						resourceLimits: { cpuCores: null, memoryMb: null },
+						placement: { mode: "manual" },
+						placements: [],
+						ports: [],
+						secrets: [],
+						volumes: [],
+					},
+				},
+			],
+			[],
+		);
+
+		const response = await POST(
+			new Request("http://localhost/api/v1/agent/builds/build-amd64", {
+				method: "POST",
+			}) as NextRequest,
+			{ params: Promise.resolve({ id: "build-amd64" }) },
+		);
+
+		expect(response.status).toBe(409);
+		expect(mocks.updateSets[1]).toMatchObject({
+			status: "failed",
+			error: "Git credentials for this service changed since the build was queued",
+		});
+	});
 });
@@ -0,0 +1,182 @@
+import { beforeEach, describe, expect, it, vi } from "vitest";
+
+const mocks = vi.hoisted(() => {
+	const selectResults: unknown[][] = [];
+	const updateResults: unknown[][] = [];
+	const chain = (results: unknown[][]) => {
+		const query = {
+			from: vi.fn(),
+			where: vi.fn(),
+			set: vi.fn(),
+			returning: vi.fn(),
+			// oxlint-disable-next-line unicorn/no-thenable -- Drizzle query builders are awaitable.
+			then: (resolve: (value: unknown[]) => unknown) =>
+				Promise.resolve(results.shift() ?? []).then(resolve),
+		};
+		query.from.mockReturnValue(query);
+		query.where.mockReturnValue(query);
+		query.set.mockReturnValue(query);
+		query.returning.mockReturnValue(query);
+		return query;
+	};
+
+	return {
+		selectResults,
+		updateResults,
+		db: {
+			select: vi.fn(() => chain(selectResults)),
+			update: vi.fn(() => chain(updateResults)),
+		},
+		send: vi.fn(),
+		parseServiceRevisionSpec: vi.fn(),
+		requeueBuildRevisionInternal: vi.fn(),
+		triggerBuildInternal: vi.fn(),
+	};
+});
+
+vi.mock("@/db", () => ({ db: mocks.db }));
+vi.mock("@/lib/inngest/client", () => ({
+	inngest: { send: mocks.send },
+}));
+vi.mock("@/lib/inngest/events", () => ({
+	inngestEvents: {
+		buildCancelled: {
+			create: vi.fn((data: unknown, options: unknown) => ({
+				data,
+				options,
+			})),
+		},
+	},
+}));
+vi.mock("@/lib/service-revision-changes", () => ({
+	parseServiceRevisionSpec: mocks.parseServiceRevisionSpec,
+}));
+vi.mock("@/lib/trigger-build", () => ({
+	requeueBuildRevisionInternal: mocks.requeueBuildRevisionInternal,
+	triggerBuildInternal: mocks.triggerBuildInternal,
+}));
+
+import {
+	BuildControlError,
+	cancelBuildInternal,
+	rebuildBuildInternal,
+} from "@/lib/build-control";
+
+const actor = { type: "user" as const, userId: "user-1", name: "Alice" };
+const build = {
+	serviceId: "service-1",
+	serviceRevisionId: "revision-1",
+	commitMessage: "Fix the login page",
+	author: "octocat",
+};
+
+describe("build control", () => {
+	beforeEach(() => {
+		mocks.selectResults.length = 0;
+		mocks.updateResults.length = 0;
+		mocks.db.update.mockClear();
+		mocks.send.mockReset();
+		mocks.parseServiceRevisionSpec.mockReset();
+		mocks.requeueBuildRevisionInternal.mockReset();
+		mocks.triggerBuildInternal.mockReset();
+	});
+
+	it("cancels an in-progress build and notifies the build workflow", async () => {
+		mocks.selectResults.push([
+			{ status: "building", buildGroupId: "group-1" },
+		]);
+		mocks.updateResults.push([{ id: "build-1" }]);
+
+		await cancelBuildInternal("build-1");
+
+		expect(mocks.send).toHaveBeenCalledWith({
+			data: { buildId: "build-1", buildGroupId: "group-1" },
+			options: { id: "build-cancelled-build-1" },
+		});
+	});
+
+	it("refuses to cancel a finished build", async () => {
+		mocks.selectResults.push([{ status: "completed", buildGroupId: null }]);
+
+		await expect(cancelBuildInternal("build-1")).rejects.toMatchObject({
+			code: "BUILD_NOT_CANCELLABLE",
+			status: 409,
+		});
+		expect(mocks.db.update).not.toHaveBeenCalled();
+		expect(mocks.send).not.toHaveBeenCalled();
+	});
+
+	it("reports the current status when the build finishes before cancelling", async () => {
+		mocks.selectResults.push(
+			[{ status: "pushing", buildGroupId: null }],
+			[{ status: "completed" }],
+		);
+		mocks.updateResults.push([]);
+
+		await expect(cancelBuildInternal("build-1")).rejects.toThrow(
+			"Cannot cancel build in completed status",
+		);
+		expect(mocks.send).not.toHaveBeenCalled();
+	});
+
+	it("requeues the exact revision of a git build", async () => {
+		mocks.selectResults.push(
+			[{ id: "service-1", previewOfService: null }],
+			[{ specification: {} }],
+		);
+		mocks.parseServiceRevisionSpec.mockReturnValue({
+			source: { type: "git" },
+		});
+		mocks.requeueBuildRevisionInternal.mockResolvedValue({
+			status: "queued",
+			serviceRevisionId: "revision-2",
+			buildRequestId: "request-1",
+		});
+
+		await expect(rebuildBuildInternal(build, actor)).resolves.toEqual({
+			status: "queued",
+			serviceRevisionId: "revision-2",
+		});
+		expect(mocks.requeueBuildRevisionInternal).toHaveBeenCalledWith({
+			serviceId: "service-1",
+			serviceRevisionId: "revision-1",
+			commitMessage: "Fix the login page",
+			author: "octocat",
+			actor,
+		});
+	});
+
+	it("rebuilds preview services from their pull request ref", async () => {
+		mocks.selectResults.push(
+			[{ id: "service-1", previewOfService: "service-0" }],
+			[{ specification: {} }],
+		);
+
+		await rebuildBuildInternal(build, actor);
+
+		expect(mocks.triggerBuildInternal).toHaveBeenCalledWith(
+			"service-1",
+			"manual",
+			actor,
+		);
+		expect(mocks.requeueBuildRevisionInternal).not.toHaveBeenCalled();
+	});
+
+	it("rejects rebuilding an uploaded build context", async () => {
+		mocks.selectResults.push(
+			[{ id: "service-1", previewOfService: null }],
+			[{ specification: {} }],
+		);
+		mocks.parseServiceRevisionSpec.mockReturnValue({
+			source: { type: "local" },
+		});
+
+		const result = rebuildBuildInternal(build, actor);
+
+		await expect(result).rejects.toBeInstanceOf(BuildControlError);
+		await expect(result).rejects.toMatchObject({
+			code: "BUILD_NOT_REBUILDABLE",
+		});
+		expect(mocks.requeueBuildRevisionInternal).not.toHaveBeenCalled();
+	});
+});
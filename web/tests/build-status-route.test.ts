@@ -144,11 +144,17 @@ function post(
 	imageUri: string | null | undefined = status === "completed"
 		? amd64Image
 		: undefined,
+	extra: Record<string, unknown> = {},
 ) {
 	return POST(
 		new Request("http://localhost/api/v1/agent/builds/build-amd64/status", {
 			method: "POST",
-			body: JSON.stringify({ status, resolvedCommitSha: commitSha, imageUri }),
+			body: JSON.stringify({
+				status,
+				resolvedCommitSha: commitSha,
+				imageUri,
+				...extra,
+			}),
 		}) as NextRequest,
 		{ params: Promise.resolve({ id: "build-amd64" }) },
 	);
@@ -199,6 +205,68 @@ describe("agent build status transitions", () => {
 		});
 	});
 
+	it("stores valid build cache stats on a terminal transition", async () => {
+		mocks.selectResults.push(
+			[build("building")],
+			[{ specification }],
+			[build("building")],
+			[{ specification }],
+		);
+		mocks.updateResults.push([build("failed")], [build("failed")]);
+
+		await post("failed", undefined, { cache: { hits: 3, steps: 5 } });
+		await post("failed", undefined, { cache: { hits: 6, steps: 5 } });
+
+		expect(mocks.updateSets[0]).toMatchObject({ cacheHits: 3, cacheSteps: 5 });
+		expect(mocks.updateSets[1]).not.toHaveProperty("cacheHits");
+	});
+
+	it.each([
+		["stores", { sbomDigest: `sha256:${"c".repeat(64)}` }, true],
+		["ignores malformed", { sbomDigest: "sha256:short" }, false],
+	])(
+		"%s attestation digests and stores scan results on a completed build",
+		async (_label, layers, stored) => {
+			const attestation = {
+				platform: "linux/amd64",
+				imageDigest: `sha256:${"a".repeat(64)}`,
+				manifestDigest: `sha256:${"b".repeat(64)}`,
+				...layers,
+			};
+			const completedBuild = build("completed", { imageUri: amd64Image });
+			mocks.selectResults.push(
+				[build("pushing")],
+				[{ specification }],
+				[completedBuild],
+				[],
+			);
+			mocks.updateResults.push([completedBuild]);
+
+			const response = await post("completed", undefined, {
+				attestations: [attestation],
+				vulnerabilities: { critical: 1, high: 2 },
+			});
+
+			expect(response.status).toBe(200);
+			expect(mocks.updateSets[0]).toMatchObject({
+				vulnerabilities: {
+					critical: 1,
+					high: 2,
+					medium: 0,
+					low: 0,
+					unknown: 0,
+				},
+			});
+			if (stored) {
+				expect(mocks.updateSets[0]).toMatchObject({
+					attestations: [{ ...attestation, provenanceDigest: null }],
+				});
+			} else {
+				expect(mocks.updateSets[0]).not.toHaveProperty("attestations");
+			}
+		},
+	);
+
 	it("does not report a replayed failed transition", async () => {
 		const failedBuild = build("failed");
 		mocks.selectResults.push([failedBuild], [{ specification }], [failedBuild]);
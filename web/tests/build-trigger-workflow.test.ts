@@ -10,7 +10,7 @@ const mocks = vi.hoisted(() => ({
 	transactionSelectResults: [] as unknown[][],
 	execute: vi.fn(),
 	getTargetPlatformsForRevision: vi.fn(),
-	selectBuildServerForRevision: vi.fn(),
+	assignBuildServersForRevision: vi.fn(),
 	enqueueWork: vi.fn(),
 	createPreviewGitHubDeployment: vi.fn(),
 	send: vi.fn(),
@@ -73,8 +73,8 @@ vi.mock("@/db/schema", () => ({
 	},
 }));
 vi.mock("@/lib/build-assignment", () => ({
+	assignBuildServersForRevision: mocks.assignBuildServersForRevision,
 	getTargetPlatformsForRevision: mocks.getTargetPlatformsForRevision,
-	selectBuildServerForRevision: mocks.selectBuildServerForRevision,
 }));
 vi.mock("@/lib/preview-deployments", () => ({
 	createPreviewGitHubDeployment: mocks.createPreviewGitHubDeployment,
@@ -173,7 +173,13 @@ describe("build trigger fan-out", () => {
 			"linux/amd64",
 			"linux/arm64",
 		]);
-		mocks.selectBuildServerForRevision.mockResolvedValue("server-1");
+		mocks.assignBuildServersForRevision.mockImplementation(
+			async (_specification: unknown, platforms: string[]) =>
+				platforms.map((platform) => ({
+					platforms: [platform],
+					serverId: "server-1",
+				})),
+		);
 	});
 
 	it("persists one immutable commit for every target platform", async () => {
@@ -207,11 +213,25 @@ describe("build trigger fan-out", () => {
 			},
 		]);
 		expect(
-			mocks.selectBuildServerForRevision.mock.invocationCallOrder[1],
+			mocks.assignBuildServersForRevision.mock.invocationCallOrder[0],
 		).toBeLessThan(mocks.values.mock.invocationCallOrder[0]);
 		expect(mocks.enqueueWork).toHaveBeenCalledTimes(2);
 	});
 
+	it("persists one build for every platform assigned to a single builder", async () => {
+		mocks.assignBuildServersForRevision.mockResolvedValue([
+			{ platforms: ["linux/amd64", "linux/arm64"], serverId: "server-1" },
+		]);
+		mocks.returning.mockResolvedValue([{ id: "build-1" }]);
+		mocks.transactionSelectResults.push([{ id: "build-1", status: "pending" }]);
+		await invoke(exactSha);
+
+		expect(mocks.values.mock.calls[0]?.[0]).toEqual([
+			expect.objectContaining({ targetPlatform: "linux/amd64,linux/arm64" }),
+		]);
+		expect(mocks.enqueueWork).toHaveBeenCalledTimes(1);
+	});
+
 	it("does not persist work for a superseded preview revision", async () => {
 		const previewGitRef = "refs/pull/42/merge";
 		(mocks.revisionRows[0] as Record<string, unknown>).previewGitRef =
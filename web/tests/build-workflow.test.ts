@@ -14,15 +14,27 @@ const mocks = vi.hoisted(() => {
 		};
 		return builder;
 	}
+	const updateSets: Array<Record<string, unknown>> = [];
 	return {
 		queryResults,
+		updateSets,
 		select: vi.fn(() => query(queryResults.shift() ?? [])),
+		update: vi.fn(() => ({
+			set: vi.fn((value: Record<string, unknown>) => {
+				updateSets.push(value);
+				return { where: vi.fn(async () => []) };
+			}),
+		})),
+		notify: vi.fn(),
 		deployServiceRevisionInternal: vi.fn(),
 		updatePreviewGitHubStatus: vi.fn(),
 	};
 });
 
-vi.mock("@/db", () => ({ db: { select: mocks.select } }));
+vi.mock("@/db", () => ({
+	db: { select: mocks.select, update: mocks.update },
+}));
+vi.mock("@/lib/notifications", () => ({ notify: mocks.notify }));
 vi.mock("@/lib/deploy-service", () => ({
 	deployServiceRevisionInternal: mocks.deployServiceRevisionInternal,
 }));
@@ -122,6 +134,7 @@ describe("revision-first build completion", () => {
 	beforeEach(() => {
 		vi.clearAllMocks();
 		mocks.queryResults.length = 0;
+		mocks.updateSets.length = 0;
 		mocks.deployServiceRevisionInternal.mockResolvedValue({
 			rolloutId: "rollout-1",
 			created: true,
@@ -305,4 +318,59 @@ describe("revision-first build completion", () => {
 		);
 		expect(mocks.deployServiceRevisionInternal).not.toHaveBeenCalled();
 	});
+
+	it("blocks the rollout when critical vulnerabilities exceed the threshold", async () => {
+		const image = "registry/app:revision-vulnerable";
+		const group = [
+			{
+				id: "vulnerable-amd64",
+				status: "completed",
+				targetPlatform: "linux/amd64",
+				imageUri: digest(image),
+				vulnerabilities: {
+					critical: 2,
+					high: 4,
+					medium: 0,
+					low: 0,
+					unknown: 0,
+				},
+			},
+		];
+		mocks.queryResults.push(
+			group,
+			[
+				{
+					status: "completed",
+					payload: JSON.stringify({
+						serviceId: "service-1",
+						serviceRevisionId: "revision-vulnerable",
+						buildGroupId: "group-vulnerable",
+						finalImageUri: image,
+						images: [digest(image)],
+					}),
+				},
+			],
+			group,
+			[{ maxCritical: 0 }],
+		);
+
+		await expect(
+			invoke("revision-vulnerable", "group-vulnerable").result,
+		).resolves.toEqual({
+			status: "blocked",
+			reason: "vulnerabilities",
+			buildGroupId: "group-vulnerable",
+		});
+		const reason =
+			"Rollout blocked: the linux/amd64 image has 2 critical vulnerabilities (threshold 0)";
+		expect(mocks.updateSets).toEqual([{ rolloutBlockedReason: reason }]);
+		expect(mocks.notify).toHaveBeenCalledWith(
+			expect.objectContaining({
+				kind: "build.failed",
+				buildId: "vulnerable-amd64",
+				error: reason,
+			}),
+		);
+		expect(mocks.deployServiceRevisionInternal).not.toHaveBeenCalled();
+	});
 });
@@ -1,6 +1,7 @@
 import { describe, expect, it, vi } from "vitest";
 
 vi.mock("@/db", () => ({ db: {} }));
+vi.mock("@/db/queries", () => ({ getSetting: vi.fn() }));
 vi.mock("@/lib/acme-manager", () => ({
 	getAllCertificatesForDomains: vi.fn(),
 }));
@@ -228,6 +229,55 @@ describe("expected-state pure builders", () => {
 		).toEqual([]);
 	});
 
+	it("attaches the project's signing policy to each container", () => {
+		const policy = {
+			publicKeys: [],
+			identities: [
+				{
+					issuer: "https://token.actions.githubusercontent.com",
+					subject:
+						"https://github.com/acme/api/.github/workflows/release.yml@refs/heads/main",
+				},
+			],
+		};
+		const deployment = {
+			serviceRevisionId: "rev_svc_1",
+			runtimeDesiredState: "running",
+		};
+		const containers = buildExpectedContainersFromRows({
+			deployments: [
+				{ ...deployment, id: "dep_signed", serviceId: "svc_1" },
+				{ ...deployment, id: "dep_unsigned", serviceId: "svc_2" },
+				{ ...deployment, id: "dep_invalid", serviceId: "svc_3" },
+			] as any,
+			services: [
+				{ id: "svc_1", name: "api", projectId: "proj_signed" },
+				{ id: "svc_2", name: "worker", projectId: "proj_open" },
+				{ id: "svc_3", name: "cron", projectId: "proj_invalid" },
+			] as any,
+			revisions: [revision("svc_1")],
+			deploymentPorts: [],
+			projects: [
+				{ id: "proj_signed", imageSigningPolicy: policy },
+				{ id: "proj_open", imageSigningPolicy: null },
+				{ id: "proj_invalid", imageSigningPolicy: { publicKeys: "nope" } },
+			],
+		});
+
+		expect(
+			Object.fromEntries(
+				containers.map((container) => [
+					container.deploymentId,
+					container.signingPolicy,
+				]),
+			),
+		).toEqual({
+			dep_signed: policy,
+			dep_unsigned: null,
+			dep_invalid: null,
+		});
+	});
+
 	it("rejects partial expected state when deployment ports are incomplete", () => {
 		expect(() =>
 			buildExpectedContainersFromRows({
@@ -361,6 +411,159 @@ describe("expected-state pure builders", () => {
 		).toBe(true);
 	});
 
+	it("compiles client certificate auth into direct and gateway routes", () => {
+		const clientAuth = {
+			mode: "require" as const,
+			caBundle: "-----BEGIN CERTIFICATE-----",
+		};
+		const routes = buildTraefikRoutes({
+			serverId: "server_1",
+			ports: [
+				{
+					id: "port_1",
+					serviceId: "svc_direct",
+					port: 3000,
+					isPublic: true,
+					protocol: "http",
+					domain: "api.example.com",
+					clientAuth,
+				},
+				{
+					id: "port_2",
+					serviceId: "svc_serverless",
+					port: 3000,
+					isPublic: true,
+					protocol: "http",
+					domain: "hooks.example.com",
+					clientAuth: { ...clientAuth, mode: "optional" as const },
+				},
+			] as any,
+			routableDeployments: [
+				{
+					serviceId: "svc_direct",
+					serverId: "server_1",
+					ipAddress: "10.0.0.1",
+				},
+			] as any,
+			serverlessServiceIds: new Set(["svc_serverless"]),
+		});
+
+		expect(
+			routes.httpRoutes.map((route) => [route.domain, route.clientAuth]),
+		).toEqual([
+			[
+				"api.example.com",
+				{
+					caBundle: clientAuth.caBundle,
+					mode: "RequireAndVerifyClientCert",
+				},
+			],
+			[
+				"hooks.example.com",
+				{
+					caBundle: clientAuth.caBundle,
+					mode: "VerifyClientCertIfGiven",
+				},
+			],
+		]);
+	});
+
+	it("terminates TLS on TCP routes by SNI domain", () => {
+		const ports: Parameters<typeof buildTraefikCertificateDomains>[0] = [
+			{
+				id: "port_1",
+				serviceId: "svc_db",
+				port: 5432,
+				isPublic: true,
+				protocol: "tcp",
+				domain: "db.example.com",
+				externalPort: 15432,
+				tlsPassthrough: false,
+				tlsTermination: true,
+				clientAuth: {
+					mode: "require",
+					caBundle: "-----BEGIN CERTIFICATE-----",
+				},
+			},
+			{
+				id: "port_2",
+				serviceId: "svc_raw",
+				port: 6379,
+				isPublic: true,
+				protocol: "tcp",
+				domain: null,
+				externalPort: 16379,
+				tlsPassthrough: false,
+			},
+		];
+
+		const routes = buildTraefikRoutes({
+			serverId: "proxy_1",
+			ports,
+			routableDeployments: [
+				{ serviceId: "svc_db", serverId: "server_1", ipAddress: "10.0.0.1" },
+				{ serviceId: "svc_raw", serverId: "server_1", ipAddress: "10.0.0.2" },
+			] as any,
+		});
+
+		expect(routes.tcpRoutes).toEqual([
+			{
+				id: "tcp-svc_db-5432",
+				serviceId: "svc_db",
+				upstreams: ["10.0.0.1:5432"],
+				externalPort: 15432,
+				tlsPassthrough: false,
+				tlsTermination: true,
+				domain: "db.example.com",
+				clientAuth: {
+					caBundle: "-----BEGIN CERTIFICATE-----",
+					mode: "RequireAndVerifyClientCert",
+				},
+			},
+			{
+				id: "tcp-svc_raw-6379",
+				serviceId: "svc_raw",
+				upstreams: ["10.0.0.2:6379"],
+				externalPort: 16379,
+				tlsPassthrough: false,
+			},
+		]);
+		expect(buildTraefikCertificateDomains(ports)).toEqual(["db.example.com"]);
+	});
+
+	it("sends the PROXY protocol version to TCP backends", () => {
+		const routes = buildTraefikRoutes({
+			serverId: "proxy_1",
+			ports: [
+				{
+					id: "port_1",
+					serviceId: "svc_mail",
+					port: 25,
+					isPublic: true,
+					protocol: "tcp",
+					domain: null,
+					externalPort: 10025,
+					tlsPassthrough: false,
+					proxyProtocol: 2,
+				},
+			],
+			routableDeployments: [
+				{ serviceId: "svc_mail", serverId: "server_1", ipAddress: "10.0.0.1" },
+			] as any,
+		});
+
+		expect(routes.tcpRoutes).toEqual([
+			{
+				id: "tcp-svc_mail-25",
+				serviceId: "svc_mail",
+				upstreams: ["10.0.0.1:25"],
+				externalPort: 10025,
+				tlsPassthrough: false,
+				proxyProtocol: 2,
+			},
+		]);
+	});
+
 	it("keeps HTTP local upstreams before remote upstreams", () => {
 		const routes = buildTraefikRoutes({
 			serverId: "server_local",
@@ -844,6 +1047,47 @@ describe("expected-state pure builders", () => {
 		]);
 	});
 
+	function serverlessRouteFor(serverless: Record<string, unknown>) {
+		return buildServerlessRoutesFromRows({
+			serverId: "proxy_1",
+			services: [
+				runtimeRevision("svc_api", {
+					serverless: {
+						enabled: true,
+						sleepAfterSeconds: 300,
+						wakeTimeoutSeconds: 120,
+						...serverless,
+					},
+				}),
+			],
+			ports: [
+				{
+					id: "port_1",
+					serviceId: "svc_api",
+					port: 3000,
+					isPublic: true,
+					protocol: "http",
+					domain: "api.example.com",
+				},
+			] as any,
+			deployments: [
+				{
+					id: "dep_api",
+					serviceId: "svc_api",
+					serverId: "proxy_1",
+					ipAddress: "10.0.0.10",
+					runtimeDesiredState: "stopped",
+					trafficState: "active",
+					observedPhase: "sleeping",
+					serverIsProxy: true,
+				},
+			] as any,
+			containers: [
+				{ deploymentId: "dep_api", desiredState: "stopped" },
+			] as any,
+		})[0];
+	}
+
 	it("builds proxy-local serverless metadata for stateful services", () => {
 		const routes = buildServerlessRoutesFromRows({
 			serverId: "proxy_1",
@@ -900,6 +1144,232 @@ describe("expected-state pure builders", () => {
 		]);
 	});
 
+	it("passes the serverless autoscaling policy to the gateway", () => {
+		const autoscaling = {
+			targetConcurrency: 10,
+			minReplicas: 1,
+			maxReplicas: 4,
+			scaleDownCooldownSeconds: 300,
+		};
+		const routes = buildServerlessRoutesFromRows({
+			serverId: "proxy_1",
+			services: [
+				runtimeRevision("svc_api", {
+					serverless: {
+						enabled: true,
+						sleepAfterSeconds: 300,
+						wakeTimeoutSeconds: 120,
+						autoscaling,
+					},
+				}),
+			],
+			ports: [
+				{
+					id: "port_1",
+					serviceId: "svc_api",
+					port: 3000,
+					isPublic: true,
+					protocol: "http",
+					domain: "api.example.com",
+				},
+			] as any,
+			deployments: [
+				{
+					id: "dep_api",
+					serviceId: "svc_api",
+					serverId: "proxy_1",
+					ipAddress: "10.0.0.10",
+					runtimeDesiredState: "stopped",
+					trafficState: "active",
+					observedPhase: "sleeping",
+					serverIsProxy: true,
+				},
+			] as any,
+			containers: [{ deploymentId: "dep_api", desiredState: "stopped" }] as any,
+		});
+
+		expect(routes[0]?.autoscaling).toEqual(autoscaling);
+	});
+
+	it("passes wake queue limits to the gateway only when configured", () => {
+		expect(
+			serverlessRouteFor({ maxQueuedRequests: 50, maxQueueWaitSeconds: 30 }),
+		).toMatchObject({ maxQueuedRequests: 50, maxQueueWaitSeconds: 30 });
+		const defaults = serverlessRouteFor({});
+		expect(defaults).not.toHaveProperty("maxQueuedRequests");
+		expect(defaults).not.toHaveProperty("maxQueueWaitSeconds");
+	});
+
+	it("passes keep-warm windows to the gateway only when configured", () => {
+		const keepWarm = [
+			{
+				days: ["mon", "fri"],
+				start: "08:00",
+				end: "18:00",
+				timeZone: "Europe/Berlin",
+				prewarmSeconds: 120,
+			},
+		];
+		expect(serverlessRouteFor({ keepWarm })).toMatchObject({ keepWarm });
+		expect(serverlessRouteFor({})).not.toHaveProperty("keepWarm");
+	});
+
+	it("passes stream timeouts to the gateway only when configured", () => {
+		expect(
+			serverlessRouteFor({
+				streamIdleTimeoutSeconds: 600,
+				maxConnectionLifetimeSeconds: 3600,
+			}),
+		).toMatchObject({
+			streamIdleTimeoutSeconds: 600,
+			maxConnectionLifetimeSeconds: 3600,
+		});
+		const defaults = serverlessRouteFor({});
+		expect(defaults).not.toHaveProperty("streamIdleTimeoutSeconds");
+		expect(defaults).not.toHaveProperty("maxConnectionLifetimeSeconds");
+	});
+
+	it("passes the event path to the gateway only when configured", () => {
+		expect(serverlessRouteFor({ eventPath: "/events" })).toMatchObject({
+			triggers: { eventPath: "/events" },
+		});
+		expect(serverlessRouteFor({})).not.toHaveProperty("triggers");
+	});
+
+	it("wakes serverless services from public TCP ports through loopback triggers", () => {
+		const ports = [
+			{
+				id: "port_http",
+				serviceId: "svc_api",
+				port: 3000,
+				isPublic: true,
+				protocol: "http",
+				domain: "api.example.com",
+			},
+			{
+				id: "port_tcp",
+				serviceId: "svc_api",
+				port: 5432,
+				isPublic: true,
+				protocol: "tcp",
+				externalPort: 10005,
+				tlsPassthrough: false,
+			},
+		] as any;
+		const routes = buildServerlessRoutesFromRows({
+			serverId: "proxy_1",
+			services: [
+				runtimeRevision("svc_api", {
+					serverless: {
+						enabled: true,
+						sleepAfterSeconds: 300,
+						wakeTimeoutSeconds: 120,
+					},
+				}),
+			],
+			ports,
+			deployments: [
+				{
+					id: "dep_api",
+					serviceId: "svc_api",
+					serverId: "proxy_1",
+					ipAddress: "10.0.0.10",
+					runtimeDesiredState: "stopped",
+					trafficState: "active",
+					observedPhase: "sleeping",
+					serverIsProxy: true,
+				},
+			] as any,
+			containers: [
+				{ deploymentId: "dep_api", desiredState: "stopped" },
+			] as any,
+		});
+
+		expect(routes[0]?.triggers).toEqual({
+			tcp: [{ listenPort: 20005, port: 5432 }],
+		});
+		expect(
+			buildTraefikRoutes({
+				serverId: "proxy_1",
+				ports,
+				routableDeployments: [],
+				serverlessServiceIds: new Set(["svc_api"]),
+			}).tcpRoutes,
+		).toEqual([
+			{
+				id: "tcp-svc_api-5432",
+				serviceId: "svc_api",
+				upstreams: ["127.0.0.1:20005"],
+				externalPort: 10005,
+				tlsPassthrough: false,
+			},
+		]);
+	});
+
+	it("lists replicas on other proxies as remote deployments the gateway can wake", () => {
+		const routes = buildServerlessRoutesFromRows({
+			serverId: "proxy_1",
+			services: [
+				runtimeRevision("svc_api", {
+					serverless: {
+						enabled: true,
+						sleepAfterSeconds: 300,
+						wakeTimeoutSeconds: 120,
+					},
+				}),
+			],
+			ports: [
+				{
+					id: "port_1",
+					serviceId: "svc_api",
+					port: 3000,
+					isPublic: true,
+					protocol: "http",
+					domain: "api.example.com",
+				},
+			] as any,
+			deployments: [
+				{
+					id: "dep_b",
+					serviceId: "svc_api",
+					serverId: "proxy_2",
+					ipAddress: "10.1.0.10",
+					runtimeDesiredState: "stopped",
+					trafficState: "active",
+					observedPhase: "sleeping",
+					serverIsProxy: true,
+				},
+				{
+					id: "dep_a",
+					serviceId: "svc_api",
+					serverId: "proxy_3",
+					ipAddress: "10.2.0.10",
+					runtimeDesiredState: "stopped",
+					trafficState: "active",
+					observedPhase: "sleeping",
+					serverIsProxy: true,
+				},
+				{
+					id: "dep_draining",
+					serviceId: "svc_api",
+					serverId: "proxy_3",
+					ipAddress: "10.2.0.11",
+					runtimeDesiredState: "running",
+					trafficState: "draining",
+					observedPhase: "running",
+					serverIsProxy: true,
+				},
+			] as any,
+			containers: [],
+		});
+
+		expect(routes[0]?.localDeploymentIds).toEqual([]);
+		expect(routes[0]?.remoteDeployments).toEqual([
+			{ deploymentId: "dep_a", serverId: "proxy_3" },
+			{ deploymentId: "dep_b", serverId: "proxy_2" },
+		]);
+	});
+
 	it("does not include draining serverless deployments as wakeable local deployments", () => {
 		const routes = buildServerlessRoutesFromRows({
 			serverId: "proxy_1",
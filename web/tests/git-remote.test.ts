@@ -0,0 +1,94 @@
+import { describe, expect, it, vi } from "vitest";
+
+vi.mock("@/db", () => ({ db: {} }));
+
+import { findAdvertisedBranch, resolveGitBranchCommit } from "@/lib/git-remote";
+
+const main = "0123456789abcdef0123456789abcdef01234567";
+const release = "89abcdef0123456789abcdef0123456789abcdef";
+
+function pktLine(payload: string) {
+	return `${(payload.length + 4).toString(16).padStart(4, "0")}${payload}`;
+}
+
+const advertisement = Buffer.from(
+	[
+		pktLine("# service=git-upload-pack\n"),
+		"0000",
+		pktLine(
+			`${main} HEAD\0multi_ack side-band-64k symref=HEAD:refs/heads/main\n`,
+		),
+		pktLine(`${main} refs/heads/main\n`),
+		pktLine(`${release} refs/heads/release\n`),
+		pktLine(`${release} refs/tags/v1.0.0\n`),
+		"0000",
+	].join(""),
+);
+
+describe("git ref advertisement", () => {
+	it("finds the requested branch", () => {
+		expect(findAdvertisedBranch(advertisement, "release")).toBe(release);
+		expect(findAdvertisedBranch(advertisement, "main")).toBe(main);
+	});
+
+	it("does not match tags or missing branches", () => {
+		expect(findAdvertisedBranch(advertisement, "v1.0.0")).toBeNull();
+		expect(findAdvertisedBranch(advertisement, "develop")).toBeNull();
+	});
+
+	it("stops on malformed framing", () => {
+		expect(findAdvertisedBranch(Buffer.from("zzzz"), "main")).toBeNull();
+	});
+});
+
+describe("git branch resolution", () => {
+	it("queries smart HTTP with the configured token", async () => {
+		const fetchImpl = vi.fn(async () => new Response(advertisement));
+
+		await expect(
+			resolveGitBranchCommit(
+				"https://git.example.com/acme/app.git",
+				"release",
+				{ username: "deploy", token: "secret" },
+				fetchImpl,
+			),
+		).resolves.toBe(release);
+		expect(fetchImpl).toHaveBeenCalledWith(
+			"https://git.example.com/acme/app.git/info/refs?service=git-upload-pack",
+			expect.objectContaining({
+				headers: expect.objectContaining({
+					Authorization: `Basic ${Buffer.from("deploy:secret").toString("base64")}`,
+				}),
+			}),
+		);
+	});
+
+	it("reports rejected credentials", async () => {
+		const fetchImpl = vi.fn(
+			async () => new Response("denied", { status: 401 }),
+		);
+
+		await expect(
+			resolveGitBranchCommit(
+				"https://git.example.com/acme/app.git",
+				"main",
+				null,
+				fetchImpl,
+			),
+		).rejects.toThrow("Git repository rejected the configured credentials");
+	});
+
+	it("never contacts SSH remotes", async () => {
+		const fetchImpl = vi.fn();
+
+		await expect(
+			resolveGitBranchCommit(
+				"git@git.example.com:acme/app.git",
+				"main",
+				null,
+				fetchImpl,
+			),
+		).rejects.toThrow("Only HTTPS git sources can be resolved by the server");
+		expect(fetchImpl).not.toHaveBeenCalled();
+	});
+});
@@ -0,0 +1,41 @@
+import { describe, expect, it, vi } from "vitest";
+
+vi.mock("@/db", () => ({ db: {} }));
+
+import { parseImageSigningPolicy } from "@/lib/image-signing-policy";
+
+const publicKey =
+	"-----BEGIN PUBLIC KEY-----\nMFkwEwYHKoZIzj0CAQYIKoZIzj0DAQcDQgAE8nXRh950IZbRj8Ra/N9sbqOPZrfM\n5/KAQN0/KjHcorm/J5yctVd7iEcnessRQjU917hmKO6JWVGHpDguIyakZA==\n-----END PUBLIC KEY-----\n";
+const identity = {
+	issuer: "https://token.actions.githubusercontent.com",
+	subject:
+		"https://github.com/acme/api/.github/workflows/release.yml@refs/heads/main",
+};
+
+describe("image signing policy", () => {
+	it("accepts public keys and keyless identities", () => {
+		expect(parseImageSigningPolicy({ publicKeys: [publicKey] })).toEqual({
+			publicKeys: [publicKey],
+			identities: [],
+		});
+		expect(parseImageSigningPolicy({ identities: [identity] })).toEqual({
+			publicKeys: [],
+			identities: [identity],
+		});
+	});
+
+	it("rejects empty and malformed policies", () => {
+		for (const value of [
+			null,
+			{},
+			{ publicKeys: [], identities: [] },
+			{ publicKeys: ["ssh-ed25519 AAAA"] },
+			{ identities: [{ ...identity, issuer: "http://issuer.example" }] },
+			{ identities: [{ ...identity, subject: " " }] },
+			{ identities: [identity], unknown: true },
+			{ publicKeys: Array(9).fill(publicKey) },
+		]) {
+			expect(parseImageSigningPolicy(value)).toBeNull();
+		}
+	});
+});
@@ -335,6 +335,10 @@ describe("preview service cloning", () => {
 				domain: null,
 				externalPort: null,
 				tlsPassthrough: false,
+				tlsTermination: false,
+				proxyProtocol: null,
+				clientAuthMode: null,
+				clientCaBundle: null,
 			})),
 		);
 	});
@@ -1,5 +1,9 @@
 import { describe, expect, it } from "vitest";
-import { canonicalDesired, planCanonicalConfiguration } from "@/lib/public-api";
+import {
+	canonicalDesired,
+	isValidClientCaBundle,
+	planCanonicalConfiguration,
+} from "@/lib/public-api";
 import { parseConfigurationIfMatch } from "@/lib/public-api-routes";
 
 const version = `sha256:${"a".repeat(64)}`;
@@ -32,6 +36,51 @@ describe("configuration plan protocol", () => {
 		]);
 	});
 
+	it("keeps client certificate auth only on ports that set it", () => {
+		const clientAuth = {
+			mode: "require" as const,
+			caBundle: "-----BEGIN CERTIFICATE-----",
+		};
+		const desired = canonicalDesired({
+			name: "web",
+			source: { type: "image", image: "nginx:1.27" },
+			hostname: "web",
+			ports: [
+				{ containerPort: 8080, public: true, domain: "a.example.com" },
+				{
+					containerPort: 8080,
+					public: true,
+					domain: "b.example.com",
+					clientAuth,
+				},
+				{ containerPort: 9090, public: false, clientAuth: null },
+			],
+			placement: { mode: "automatic", replicas: 1 },
+			healthCheck: null,
+			startCommand: null,
+			resources: null,
+		});
+		expect(desired.ports).toEqual([
+			{ containerPort: 8080, public: true, domain: "a.example.com" },
+			{
+				containerPort: 8080,
+				public: true,
+				domain: "b.example.com",
+				clientAuth,
+			},
+			{ containerPort: 9090, public: false, domain: null },
+		]);
+	});
+
+	it("rejects client CA bundles without parseable certificates", () => {
+		expect(isValidClientCaBundle("not a certificate")).toBe(false);
+		expect(
+			isValidClientCaBundle(
+				"-----BEGIN CERTIFICATE-----\nnot base64\n-----END CERTIFICATE-----",
+			),
+		).toBe(false);
+	});
+
 	it("treats GitHub repository casing as the same repository identity", () => {
 		const current = {
 			name: "web",
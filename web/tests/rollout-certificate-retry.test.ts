@@ -23,6 +23,11 @@ vi.mock("@/lib/deployment-status", () => ({
 	isObservedReady: vi.fn(),
 	observedReadyPhases: [],
 }));
+vi.mock("@/lib/image-scans", () => ({
+	checkRolloutVulnerabilityGate: vi.fn(),
+	evaluateImageScan: vi.fn(),
+	getImageScanStatus: vi.fn(),
+}));
 vi.mock("@/lib/preview-deployments", () => ({
 	canDeployServiceRevision: vi.fn(),
 	updatePreviewGitHubStatus: vi.fn(),
@@ -101,6 +106,8 @@ function invokeRollout() {
 					};
 				case "validate-servers":
 					return { success: true, serverIds: ["server-1"] };
+				case "check-vulnerability-gate":
+					return { status: "passed" };
 				case "cleanup-terminal-deployments":
 					return undefined;
 				case "check-rolling-update":
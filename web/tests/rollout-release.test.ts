@@ -0,0 +1,218 @@
+import { beforeEach, describe, expect, it, vi } from "vitest";
+
+const mocks = vi.hoisted(() => {
+	function updateQuery() {
+		const query = {
+			set: vi.fn(() => query),
+			where: vi.fn(async () => undefined),
+		};
+		return query;
+	}
+
+	return {
+		update: vi.fn(updateQuery),
+		startReleaseRun: vi.fn(),
+		getReleaseRun: vi.fn(),
+		abandonReleaseRun: vi.fn(),
+		handleRolloutFailure: vi.fn(),
+		ingestRolloutLog: vi.fn(),
+	};
+});
+
+vi.mock("@/db", () => ({ db: { update: mocks.update } }));
+vi.mock("@/db/queries", () => ({ getService: vi.fn() }));
+vi.mock("@/lib/deployment-status", () => ({
+	isObservedReady: vi.fn(),
+	observedReadyPhases: [],
+}));
+vi.mock("@/lib/image-scans", () => ({
+	checkRolloutVulnerabilityGate: vi.fn(),
+	evaluateImageScan: vi.fn(),
+	getImageScanStatus: vi.fn(),
+}));
+vi.mock("@/lib/preview-deployments", () => ({
+	canDeployServiceRevision: vi.fn(),
+	updatePreviewGitHubStatus: vi.fn(),
+}));
+vi.mock("@/lib/routing-sync", () => ({ buildRoutingTargets: vi.fn() }));
+vi.mock("@/lib/service-revisions", () => ({
+	getRolloutServiceRevision: vi.fn(),
+}));
+vi.mock("@/lib/service-runs", () => ({
+	abandonReleaseRun: mocks.abandonReleaseRun,
+	getReleaseRun: mocks.getReleaseRun,
+	startReleaseRun: mocks.startReleaseRun,
+}));
+vi.mock("@/lib/victoria-logs", () => ({
+	ingestRolloutLog: mocks.ingestRolloutLog,
+}));
+vi.mock("@/lib/work-queue", () => ({
+	enqueueReconcileForAllOnlineServers: vi.fn(),
+}));
+vi.mock("@/lib/inngest/client", () => ({
+	inngest: {
+		createFunction: vi.fn(
+			(_options: unknown, handler: (input: unknown) => unknown) => handler,
+		),
+	},
+}));
+vi.mock("@/lib/inngest/events", () => ({
+	inngestEvents: {
+		rolloutCreated: { name: "rollout/created" },
+		rolloutCancelled: { name: "rollout/cancelled" },
+		resourceStatusChanged: { name: "resource/status.changed" },
+		serverDnsSynced: { name: "server/dns.synced" },
+	},
+}));
+vi.mock("@/lib/inngest/functions/rollout-helpers", () => ({
+	checkForRollingUpdate: vi.fn(),
+	cleanupExistingDeployments: vi.fn(),
+	cleanupTerminalDeployments: vi.fn(),
+	completeRollout: vi.fn(),
+	createDeploymentRecords: vi.fn(),
+	issueCertificatesForRevision: vi.fn(),
+	resolveRevisionPlacements: vi.fn(),
+	validateServers: vi.fn(),
+}));
+vi.mock("@/lib/inngest/functions/rollout-utils", () => ({
+	handleRolloutFailure: mocks.handleRolloutFailure,
+}));
+
+import { rolloutWorkflow } from "@/lib/inngest/functions/rollout-workflow";
+
+function invokeRollout(releaseCommand?: string) {
+	const step = {
+		run: vi.fn(async (name: string, operation: () => unknown) => {
+			if (name.includes("release")) {
+				return operation();
+			}
+
+			switch (name) {
+				case "validate-service":
+					return false;
+				case "acquire-rollout-turn-0":
+					return "acquired";
+				case "load-service-revision":
+					return {
+						id: "revision-1",
+						specification: {
+							ports: [],
+							serverless: { enabled: false },
+							releaseCommand,
+						},
+					};
+				case "log-rollout-started":
+					return undefined;
+				case "load-placements":
+					return {
+						success: true,
+						placements: [{ serverId: "server-1", replicas: 1 }],
+						totalReplicas: 1,
+					};
+				case "validate-servers":
+					return { success: true, serverIds: ["server-1", "server-2"] };
+				case "check-vulnerability-gate":
+					return { status: "passed" };
+				case "cleanup-terminal-deployments":
+					throw new Error("continued past release");
+				default:
+					throw new Error(`unexpected step: ${name}`);
+			}
+		}),
+		sleep: vi.fn(async () => undefined),
+		waitForEvent: vi.fn(async () => ({})),
+	};
+	const handler = rolloutWorkflow as unknown as (input: {
+		event: { data: { rolloutId: string; serviceId: string } };
+		step: typeof step;
+	}) => Promise<unknown>;
+
+	return {
+		result: handler({
+			event: {
+				data: { rolloutId: "rollout-1", serviceId: "service-1" },
+			},
+			step,
+		}),
+		step,
+	};
+}
+
+describe("rollout release phase", () => {
+	beforeEach(() => {
+		vi.clearAllMocks();
+		mocks.startReleaseRun.mockResolvedValue("run-1");
+	});
+
+	it("skips the release stage when the revision has no release command", async () => {
+		const { result } = invokeRollout();
+
+		await expect(result).rejects.toThrow("continued past release");
+		expect(mocks.startReleaseRun).not.toHaveBeenCalled();
+	});
+
+	it("runs the release on the first placement server before continuing", async () => {
+		mocks.getReleaseRun
+			.mockResolvedValueOnce({ status: "running" })
+			.mockResolvedValueOnce({ status: "succeeded", exitCode: 0 });
+
+		const { result, step } = invokeRollout("bin/migrate");
+
+		await expect(result).rejects.toThrow("continued past release");
+		expect(mocks.startReleaseRun).toHaveBeenCalledWith({
+			rolloutId: "rollout-1",
+			serviceId: "service-1",
+			serverId: "server-1",
+		});
+		expect(step.waitForEvent).toHaveBeenCalledWith(
+			"wait-release",
+			expect.objectContaining({
+				if: 'async.data.type == "run" && async.data.id == "run-1"',
+			}),
+		);
+		expect(mocks.handleRolloutFailure).not.toHaveBeenCalled();
+	});
+
+	it("fails the rollout before touching deployments on a non-zero exit", async () => {
+		mocks.getReleaseRun.mockResolvedValue({
+			status: "failed",
+			exitCode: 3,
+			errorMessage: "run exited with code 3",
+		});
+
+		const { result, step } = invokeRollout("bin/migrate");
+
+		await expect(result).resolves.toEqual({
+			status: "failed",
+			rolloutId: "rollout-1",
+			reason: "release_failed",
+		});
+		expect(step.waitForEvent).not.toHaveBeenCalled();
+		expect(mocks.abandonReleaseRun).not.toHaveBeenCalled();
+		expect(mocks.ingestRolloutLog).toHaveBeenCalledWith(
+			"rollout-1",
+			"service-1",
+			"release",
+			"Release command exited with code 3",
+		);
+		expect(mocks.handleRolloutFailure).toHaveBeenCalledWith({
+			rolloutId: "rollout-1",
+			serviceId: "service-1",
+			reason: "release_failed",
+			failureStage: "release_failed",
+			isRollingUpdate: false,
+		});
+	});
+
+	it("abandons a release that is still pending when the wait times out", async () => {
+		mocks.getReleaseRun.mockResolvedValue({ status: "pending" });
+
+		const { result } = invokeRollout("bin/migrate");
+
+		await expect(result).resolves.toMatchObject({
+			status: "failed",
+			reason: "release_timeout",
+		});
+		expect(mocks.abandonReleaseRun).toHaveBeenCalledWith("run-1");
+	});
+});
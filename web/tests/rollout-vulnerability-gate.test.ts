@@ -0,0 +1,222 @@
+import { beforeEach, describe, expect, it, vi } from "vitest";
+
+const mocks = vi.hoisted(() => {
+	function updateQuery() {
+		const query = {
+			set: vi.fn(() => query),
+			where: vi.fn(async () => undefined),
+		};
+		return query;
+	}
+
+	return {
+		update: vi.fn(updateQuery),
+		checkRolloutVulnerabilityGate: vi.fn(),
+		evaluateImageScan: vi.fn(),
+		getImageScanStatus: vi.fn(),
+		handleRolloutFailure: vi.fn(),
+		ingestRolloutLog: vi.fn(),
+	};
+});
+
+vi.mock("@/db", () => ({ db: { update: mocks.update } }));
+vi.mock("@/db/queries", () => ({ getService: vi.fn() }));
+vi.mock("@/lib/deployment-status", () => ({
+	isObservedReady: vi.fn(),
+	observedReadyPhases: [],
+}));
+vi.mock("@/lib/image-scans", () => ({
+	checkRolloutVulnerabilityGate: mocks.checkRolloutVulnerabilityGate,
+	evaluateImageScan: mocks.evaluateImageScan,
+	getImageScanStatus: mocks.getImageScanStatus,
+}));
+vi.mock("@/lib/preview-deployments", () => ({
+	canDeployServiceRevision: vi.fn(),
+	updatePreviewGitHubStatus: vi.fn(),
+}));
+vi.mock("@/lib/routing-sync", () => ({ buildRoutingTargets: vi.fn() }));
+vi.mock("@/lib/service-revisions", () => ({
+	getRolloutServiceRevision: vi.fn(),
+}));
+vi.mock("@/lib/service-runs", () => ({
+	abandonReleaseRun: vi.fn(),
+	getReleaseRun: vi.fn(),
+	startReleaseRun: vi.fn(),
+}));
+vi.mock("@/lib/victoria-logs", () => ({
+	ingestRolloutLog: mocks.ingestRolloutLog,
+}));
+vi.mock("@/lib/work-queue", () => ({
+	enqueueReconcileForAllOnlineServers: vi.fn(),
+}));
+vi.mock("@/lib/inngest/client", () => ({
+	inngest: {
+		createFunction: vi.fn(
+			(_options: unknown, handler: (input: unknown) => unknown) => handler,
+		),
+	},
+}));
+vi.mock("@/lib/inngest/events", () => ({
+	inngestEvents: {
+		rolloutCreated: { name: "rollout/created" },
+		rolloutCancelled: { name: "rollout/cancelled" },
+		resourceStatusChanged: { name: "resource/status.changed" },
+		serverDnsSynced: { name: "server/dns.synced" },
+	},
+}));
+vi.mock("@/lib/inngest/functions/rollout-helpers", () => ({
+	checkForRollingUpdate: vi.fn(),
+	cleanupExistingDeployments: vi.fn(),
+	cleanupTerminalDeployments: vi.fn(),
+	completeRollout: vi.fn(),
+	createDeploymentRecords: vi.fn(),
+	issueCertificatesForRevision: vi.fn(),
+	resolveRevisionPlacements: vi.fn(),
+	validateServers: vi.fn(),
+}));
+vi.mock("@/lib/inngest/functions/rollout-utils", () => ({
+	handleRolloutFailure: mocks.handleRolloutFailure,
+}));
+
+import { rolloutWorkflow } from "@/lib/inngest/functions/rollout-workflow";
+
+function invokeRollout() {
+	const step = {
+		run: vi.fn(async (name: string, operation: () => unknown) => {
+			if (
+				name.includes("vulnerability") ||
+				name.startsWith("check-image-scan-")
+			) {
+				return operation();
+			}
+
+			switch (name) {
+				case "validate-service":
+					return false;
+				case "acquire-rollout-turn-0":
+					return "acquired";
+				case "load-service-revision":
+					return {
+						id: "revision-1",
+						specification: {
+							ports: [],
+							image: "nginx:1.27",
+							serverless: { enabled: false },
+						},
+					};
+				case "log-rollout-started":
+					return undefined;
+				case "load-placements":
+					return {
+						success: true,
+						placements: [{ serverId: "server-1", replicas: 1 }],
+						totalReplicas: 1,
+					};
+				case "validate-servers":
+					return { success: true, serverIds: ["server-1", "server-2"] };
+				case "cleanup-terminal-deployments":
+					throw new Error("continued past vulnerability gate");
+				default:
+					throw new Error(`unexpected step: ${name}`);
+			}
+		}),
+		sleep: vi.fn(async () => undefined),
+		waitForEvent: vi.fn(async () => ({})),
+	};
+	const handler = rolloutWorkflow as unknown as (input: {
+		event: { data: { rolloutId: string; serviceId: string } };
+		step: typeof step;
+	}) => Promise<unknown>;
+
+	return {
+		result: handler({
+			event: {
+				data: { rolloutId: "rollout-1", serviceId: "service-1" },
+			},
+			step,
+		}),
+		step,
+	};
+}
+
+describe("rollout vulnerability gate", () => {
+	beforeEach(() => {
+		vi.clearAllMocks();
+	});
+
+	it("continues when the image passes the threshold", async () => {
+		mocks.checkRolloutVulnerabilityGate.mockResolvedValue({
+			status: "passed",
+		});
+
+		const { result, step } = invokeRollout();
+
+		await expect(result).rejects.toThrow("continued past vulnerability gate");
+		expect(mocks.checkRolloutVulnerabilityGate).toHaveBeenCalledWith(
+			"rollout-1",
+			"service-1",
+			expect.objectContaining({ image: "nginx:1.27" }),
+			"server-1",
+		);
+		expect(step.waitForEvent).not.toHaveBeenCalled();
+	});
+
+	it("blocks a pulled image over the threshold", async () => {
+		const reason =
+			"Rollout blocked: the nginx:1.27 image has 2 critical vulnerabilities (threshold 0)";
+		mocks.checkRolloutVulnerabilityGate.mockResolvedValue({
+			status: "scanning",
+			scanId: "image-scan-rollout-1",
+		});
+		mocks.getImageScanStatus.mockResolvedValue("processing");
+		mocks.evaluateImageScan.mockResolvedValue({ status: "blocked", reason });
+
+		const { result, step } = invokeRollout();
+
+		await expect(result).resolves.toEqual({
+			status: "failed",
+			rolloutId: "rollout-1",
+			reason: "vulnerabilities",
+		});
+		expect(step.waitForEvent).toHaveBeenCalledWith(
+			"wait-image-scan",
+			expect.objectContaining({
+				if: 'async.data.type == "workItem" && async.data.id == "image-scan-rollout-1"',
+			}),
+		);
+		expect(mocks.evaluateImageScan).toHaveBeenCalledWith(
+			"rollout-1",
+			"service-1",
+			"nginx:1.27",
+		);
+		expect(mocks.ingestRolloutLog).toHaveBeenCalledWith(
+			"rollout-1",
+			"service-1",
+			"preparing",
+			reason,
+		);
+		expect(mocks.handleRolloutFailure).toHaveBeenCalledWith({
+			rolloutId: "rollout-1",
+			serviceId: "service-1",
+			reason: "vulnerability_blocked",
+			failureStage: "vulnerability_blocked",
+			isRollingUpdate: false,
+			report: false,
+		});
+	});
+
+	it("does not wait for a scan that already finished", async () => {
+		mocks.checkRolloutVulnerabilityGate.mockResolvedValue({
+			status: "scanning",
+			scanId: "image-scan-rollout-1",
+		});
+		mocks.getImageScanStatus.mockResolvedValue("completed");
+		mocks.evaluateImageScan.mockResolvedValue({ status: "passed" });
+
+		const { result, step } = invokeRollout();
+
+		await expect(result).rejects.toThrow("continued past vulnerability gate");
+		expect(step.waitForEvent).not.toHaveBeenCalled();
+		expect(mocks.handleRolloutFailure).not.toHaveBeenCalled();
+	});
+});
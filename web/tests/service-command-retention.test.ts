@@ -17,9 +17,10 @@ vi.mock("@/lib/date", () => ({
 	subtractMilliseconds: mocks.subtractMilliseconds,
 }));
 
-import { serviceCommands } from "@/db/schema";
+import { serviceCommands, serviceRuns } from "@/db/schema";
 import {
 	cleanupOldServiceCommands,
+	cleanupOldServiceRuns,
 	COMMAND_RETENTION_DAYS,
 } from "@/lib/service-command-retention";
 
@@ -48,4 +49,17 @@ describe("service command retention", () => {
 		expect(deleted).toBe(3);
 		expect(mocks.where.mock.results[0]?.value).not.toHaveProperty("returning");
 	});
+
+	it("deletes runs with the same retention", async () => {
+		const now = new Date("2026-08-05T00:00:00Z");
+
+		const deleted = await cleanupOldServiceRuns(now);
+
+		expect(mocks.subtractMilliseconds).toHaveBeenCalledWith(
+			now,
+			90 * 86_400_000,
+		);
+		expect(mocks.deleteCommand).toHaveBeenCalledWith(serviceRuns);
+		expect(deleted).toBe(3);
+	});
 });
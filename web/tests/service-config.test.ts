@@ -208,6 +208,77 @@ describe("service config", () => {
 		expect(hasBuildAffectingChanges(changes)).toBe(true);
 	});
 
+	it("reports git submodule and LFS toggles as build-affecting", () => {
+		const source = {
+			type: "git" as const,
+			repository: "git@git.example.com:acme/api.git",
+			branch: "main",
+			rootDir: null,
+			submodules: false,
+			lfs: false,
+		};
+		const changes = diffConfigs(
+			deployedConfig({ source }),
+			deployedConfig({ source: { ...source, lfs: true } }),
+		);
+
+		expect(changes).toEqual([
+			{
+				field: "Git LFS",
+				from: "Disabled",
+				to: "Enabled",
+				requiresBuild: true,
+			},
+		]);
+		expect(
+			diffConfigs(
+				deployedConfig(),
+				deployedConfig({ source: { ...source, submodules: true } }),
+			),
+		).toContainEqual({
+			field: "Source",
+			from: "Image",
+			to: "Git",
+			requiresBuild: true,
+		});
+	});
+
+	it("reports Dockerfile, target and build argument changes as build-affecting", () => {
+		const source = {
+			type: "github" as const,
+			repository: "https://github.com/acme/api",
+			branch: "main",
+			rootDir: null,
+		};
+		const changes = diffConfigs(
+			deployedConfig({ source }),
+			deployedConfig({
+				source,
+				build: {
+					dockerfile: "docker/Dockerfile.prod",
+					target: null,
+					args: { VERSION: "2", NODE_ENV: "production" },
+				},
+			}),
+		);
+
+		expect(changes).toEqual([
+			{
+				field: "Dockerfile",
+				from: "(default)",
+				to: "docker/Dockerfile.prod",
+				requiresBuild: true,
+			},
+			{
+				field: "Build arguments",
+				from: "(none)",
+				to: "NODE_ENV=production, VERSION=2",
+				requiresBuild: true,
+			},
+		]);
+		expect(hasBuildAffectingChanges(changes)).toBe(true);
+	});
+
 	it("continues to report configured image changes", () => {
 		expect(
 			diffConfigs(
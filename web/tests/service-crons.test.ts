@@ -5,6 +5,7 @@ import { beforeEach, describe, expect, it, vi } from "vitest";
 const mocks = vi.hoisted(() => {
 	const selectResults: unknown[][] = [];
 	const updateResults: unknown[][] = [];
+	const inserted: unknown[] = [];
 	function query(result: unknown[]) {
 		const value = {
 			from: vi.fn(() => value),
@@ -12,6 +13,10 @@ const mocks = vi.hoisted(() => {
 			where: vi.fn(() => value),
 			limit: vi.fn(() => value),
 			set: vi.fn(() => value),
+			values: vi.fn((row: unknown) => {
+				inserted.push(row);
+				return value;
+			}),
 			returning: vi.fn(() => value),
 			// oxlint-disable-next-line unicorn/no-thenable -- Drizzle query builders are awaitable.
 			then: (
@@ -21,13 +26,19 @@ const mocks = vi.hoisted(() => {
 		};
 		return value;
 	}
+	const db = {
+		select: vi.fn(() => query(selectResults.shift() ?? [])),
+		update: vi.fn(() => query(updateResults.shift() ?? [])),
+		insert: vi.fn(() => query([])),
+		delete: vi.fn(() => query([])),
+		execute: vi.fn(async () => undefined),
+		transaction: vi.fn(async (fn: (tx: unknown) => unknown) => fn(db)),
+	};
 	return {
 		selectResults,
 		updateResults,
-		db: {
-			select: vi.fn(() => query(selectResults.shift() ?? [])),
-			update: vi.fn(() => query(updateResults.shift() ?? [])),
-		},
+		inserted,
+		db,
 		decryptSecret: vi.fn(),
 		notify: vi.fn(),
 		reportOperationFailure: vi.fn(),
@@ -54,6 +65,7 @@ import {
 	nextOccurrenceAfter,
 	parseCronUrl,
 	performCronGet,
+	retryBackoffSeconds,
 	validateCronTransport,
 } from "@/lib/service-crons";
 
@@ -62,6 +74,7 @@ describe("service cron scheduling and requests", () => {
 		vi.clearAllMocks();
 		mocks.selectResults.length = 0;
 		mocks.updateResults.length = 0;
+		mocks.inserted.length = 0;
 		mocks.notify.mockResolvedValue(undefined);
 		mocks.ingestCronLog.mockResolvedValue(undefined);
 	});
@@ -81,6 +94,30 @@ describe("service cron scheduling and requests", () => {
 		).toBe("2026-08-07T23:30:00.000Z");
 	});
 
+	it("evaluates schedules in the cron's time zone", () => {
+		expect(
+			nextOccurrenceAfter(
+				"0 9 * * *",
+				new Date("2026-08-06T12:00:00Z"),
+				"Europe/Berlin",
+			).toISOString(),
+		).toBe("2026-08-07T07:00:00.000Z");
+		expect(
+			latestDueOccurrence(
+				"0 9 * * *",
+				new Date("2026-08-06T00:00:00Z"),
+				new Date("2026-08-06T07:00:30Z"),
+				"Europe/Berlin",
+			)?.toISOString(),
+		).toBe("2026-08-06T07:00:00.000Z");
+	});
+
+	it("doubles the retry backoff per attempt up to an hour", () => {
+		expect(retryBackoffSeconds(10, 1)).toBe(10);
+		expect(retryBackoffSeconds(10, 3)).toBe(40);
+		expect(retryBackoffSeconds(1800, 4)).toBe(3600);
+	});
+
 	it("coalesces missed intervals to the latest UTC occurrence", () => {
 		const due = latestDueOccurrence(
 			"* * * * *",
@@ -134,6 +171,7 @@ describe("service cron scheduling and requests", () => {
 			status: "succeeded",
 			statusCode: 204,
 			error: null,
+			responseSnippet: null,
 		});
 		expect(options).toMatchObject({ method: "GET", agent: false });
 		expect(options.lookup).toBeUndefined();
@@ -141,25 +179,53 @@ describe("service cron scheduling and requests", () => {
 	});
 
 	it.each([302, 404])(
-		"classifies HTTP %s at headers without retaining a body",
+		"classifies HTTP %s and keeps a bounded response snippet",
 		async (code) => {
 			let destroyed = false;
-			const request = fakeRequest(code, undefined, () => {
-				destroyed = true;
-			});
-			await expect(
-				performCronGet(
-					new URL("https://example.com/job"),
-					undefined,
-					1_000,
-					request,
-				),
-			).resolves.toMatchObject({ status: "failed", statusCode: code });
+			const request = fakeRequest(
+				code,
+				undefined,
+				() => {
+					destroyed = true;
+				},
+				`not found\n${"x".repeat(2_000)}`,
+			);
+			const result = await performCronGet(
+				new URL("https://example.com/job"),
+				undefined,
+				1_000,
+				request,
+			);
+			expect(result).toMatchObject({ status: "failed", statusCode: code });
+			expect(result.responseSnippet).toMatch(/^not found x+$/);
+			expect(result.responseSnippet).toHaveLength(512);
 			expect(destroyed).toBe(true);
 			expect(request).toHaveBeenCalledTimes(1);
 		},
 	);
 
+	it("fails the request when the run is replaced", async () => {
+		const controller = new AbortController();
+		const request = vi.fn(() => {
+			const req = new EventEmitter() as ClientRequest;
+			req.end = vi.fn(() => req) as unknown as ClientRequest["end"];
+			req.destroy = vi.fn(() => req) as ClientRequest["destroy"];
+			return req;
+		}) as unknown as typeof import("node:http").request;
+		const result = performCronGet(
+			new URL("https://example.com/job"),
+			undefined,
+			1_000,
+			request,
+			controller.signal,
+		);
+		controller.abort();
+		await expect(result).resolves.toMatchObject({
+			status: "failed",
+			error: "Cron run was replaced",
+		});
+	});
+
 	it("settles once when the absolute request timeout expires", async () => {
 		vi.useFakeTimers();
 		try {
@@ -180,6 +246,7 @@ describe("service cron scheduling and requests", () => {
 				status: "failed",
 				statusCode: null,
 				error: "Cron request timed out",
+				responseSnippet: null,
 			});
 			expect(request).toHaveBeenCalledTimes(1);
 		} finally {
@@ -199,6 +266,7 @@ describe("service cron scheduling and requests", () => {
 					serviceId: "service-1",
 				},
 			],
+			[],
 			[
 				{
 					key: "CRON_BASE_URL",
@@ -206,7 +274,7 @@ describe("service cron scheduling and requests", () => {
 				},
 			],
 		);
-		mocks.updateResults.push([{ id: "cron-1" }], []);
+		mocks.updateResults.push([{ id: "cron-1" }], [{ id: "run-1" }], []);
 		mocks.decryptSecret.mockRejectedValue(new Error("decrypt failed"));
 
 		await expect(
@@ -223,7 +291,7 @@ describe("service cron scheduling and requests", () => {
 					serviceId: "service-1",
 					source: "scheduled",
 				},
-				extra: { statusCode: null },
+				extra: { statusCode: null, attempt: 1 },
 			},
 		);
 		expect(mocks.reportServerError).toHaveBeenCalledWith(
@@ -238,12 +306,108 @@ describe("service cron scheduling and requests", () => {
 		expect(captured).not.toContain("/private/job");
 		expect(captured).not.toContain("encrypted-sensitive-value");
 	});
+
+	it("records a skipped run when a forbidden overlap is still running", async () => {
+		const scheduledFor = new Date("2026-08-06T10:05:00Z");
+		mocks.selectResults.push(
+			[
+				{
+					cron: {
+						schedule: "* * * * *",
+						path: "/job",
+						concurrencyPolicy: "forbid",
+					},
+					serviceId: "service-1",
+				},
+			],
+			[{ id: "run-previous" }],
+		);
+		mocks.updateResults.push([{ id: "cron-1" }], []);
+
+		await expect(
+			executeServiceCron("cron-1", "* * * * *", scheduledFor, "scheduled"),
+		).resolves.toMatchObject({
+			stale: false,
+			status: "skipped",
+			error: "Previous run is still in progress",
+			retryInSeconds: null,
+		});
+		expect(mocks.db.execute).toHaveBeenCalledTimes(1);
+		expect(mocks.inserted).toEqual([
+			expect.objectContaining({
+				cronId: "cron-1",
+				status: "skipped",
+				attempt: 1,
+			}),
+		]);
+		expect(mocks.decryptSecret).not.toHaveBeenCalled();
+	});
+
+	it("schedules a retry instead of notifying while attempts remain", async () => {
+		const scheduledFor = new Date("2026-08-06T10:05:00Z");
+		mocks.selectResults.push(
+			[
+				{
+					cron: {
+						schedule: "* * * * *",
+						path: "/job",
+						concurrencyPolicy: "allow",
+						retries: 2,
+						retryBackoffSeconds: 30,
+					},
+					serviceId: "service-1",
+				},
+			],
+			[{ key: "CRON_BASE_URL", encryptedValue: "encrypted" }],
+		);
+		mocks.updateResults.push([{ id: "cron-1" }], [{ id: "run-3" }], []);
+		mocks.decryptSecret.mockRejectedValue(new Error("decrypt failed"));
+
+		await expect(
+			executeServiceCron("cron-1", "* * * * *", scheduledFor, "scheduled", 2),
+		).resolves.toMatchObject({
+			stale: false,
+			status: "failed",
+			retryInSeconds: 60,
+		});
+		expect(mocks.reportOperationFailure).not.toHaveBeenCalled();
+		expect(mocks.notify).not.toHaveBeenCalled();
+	});
+
+	it("discards the result of a run that was replaced", async () => {
+		mocks.selectResults.push(
+			[
+				{
+					cron: {
+						schedule: "* * * * *",
+						path: "/job",
+						concurrencyPolicy: "replace",
+					},
+					serviceId: "service-1",
+				},
+			],
+			[],
+			[],
+		);
+		mocks.updateResults.push([{ id: "cron-1" }], []);
+
+		await expect(
+			executeServiceCron(
+				"cron-1",
+				"* * * * *",
+				new Date("2026-08-06T10:05:00Z"),
+				"manual",
+			),
+		).resolves.toEqual({ stale: false, status: "cancelled" });
+		expect(mocks.ingestCronLog).not.toHaveBeenCalled();
+	});
 });
 
 function fakeRequest(
 	statusCode: number,
 	onOptions?: (options: RequestOptions) => void,
 	onResponseDestroy?: () => void,
+	body?: string,
 ) {
 	return vi.fn(
 		(
@@ -261,6 +425,8 @@ function fakeRequest(
 					return response;
 				}) as IncomingMessage["destroy"];
 				callback(response);
+				if (body) response.emit("data", Buffer.from(body));
+				response.emit("end");
 				return req;
 			}) as unknown as ClientRequest["end"];
 			req.destroy = vi.fn(() => req) as ClientRequest["destroy"];
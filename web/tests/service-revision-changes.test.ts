@@ -53,6 +53,7 @@ describe("diffServiceRevisionSpecs", () => {
 		if (!current.healthCheck) throw new Error("Expected health check fixture");
 		current.healthCheck.startPeriod = 40;
 		current.startCommand = "npm start";
+		current.releaseCommand = "npm run migrate";
 		current.resourceLimits = { cpuCores: 2, memoryMb: 512 };
 
 		expect(diffServiceRevisionSpecs(previous, current)).toEqual(
@@ -60,6 +61,11 @@ describe("diffServiceRevisionSpecs", () => {
 				{ field: "Image", from: "app:v1", to: "app:v2" },
 				{ field: "Health check start period", from: "30s", to: "40s" },
 				{ field: "Start command", from: "(default)", to: "npm start" },
+				{
+					field: "Release command",
+					from: "(none)",
+					to: "npm run migrate",
+				},
 				{ field: "CPU limit", from: "(no limit)", to: "2 cores" },
 				{ field: "Memory limit", from: "(no limit)", to: "512 MB" },
 			]),
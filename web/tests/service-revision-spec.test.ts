@@ -1,7 +1,9 @@
 import { describe, expect, it } from "vitest";
 import {
 	buildServiceRevisionSpec,
+	isBuiltServiceRevisionSource,
 	type ServiceRevisionDraft,
+	serviceRevisionBuildRef,
 } from "@/lib/service-revision-spec";
 
 function draft(
@@ -239,6 +241,24 @@ describe("service revision specification", () => {
 		});
 	});
 
+	it("keys a local build by its uploaded context checksum", () => {
+		const source = {
+			type: "local" as const,
+			contextId: "context-1",
+			checksum: "ef".repeat(32),
+			rootDir: null,
+		};
+
+		expect(isBuiltServiceRevisionSource(source)).toBe(true);
+		expect(
+			isBuiltServiceRevisionSource({ type: "image", image: "nginx" }),
+		).toBe(false);
+		expect(serviceRevisionBuildRef(source)).toEqual({
+			commitSha: "ef".repeat(32),
+			branch: "local",
+		});
+	});
+
 	it("allows an unrolled build revision to snapshot zero placements", () => {
 		expect(() =>
 			buildServiceRevisionSpec(draft({ placements: [] }), {
@@ -382,6 +402,221 @@ describe("service revision specification", () => {
 		expect(() => buildServiceRevisionSpec(input)).not.toThrow();
 	});
 
+	it("records wake queue limits only for serverless services", () => {
+		const input = draft();
+		input.ports[0] = {
+			port: 443,
+			isPublic: true,
+			domain: "api.example.com",
+			protocol: "http",
+			externalPort: null,
+			tlsPassthrough: false,
+		};
+		input.service.serverlessMaxQueuedRequests = 50;
+		input.service.serverlessMaxQueueWaitSeconds = 30;
+
+		expect(buildServiceRevisionSpec(input).serverless).toEqual({
+			enabled: false,
+			sleepAfterSeconds: 300,
+			wakeTimeoutSeconds: 300,
+		});
+
+		input.service.serverlessEnabled = true;
+		expect(buildServiceRevisionSpec(input).serverless).toMatchObject({
+			maxQueuedRequests: 50,
+			maxQueueWaitSeconds: 30,
+		});
+
+		input.service.serverlessMaxQueueWaitSeconds = 901;
+		expect(() => buildServiceRevisionSpec(input)).toThrow(
+			"Serverless queue wait must be between 1 and 900 seconds",
+		);
+	});
+
+	it("records stream timeouts only for serverless services", () => {
+		const input = draft();
+		input.ports[0] = {
+			port: 443,
+			isPublic: true,
+			domain: "api.example.com",
+			protocol: "http",
+			externalPort: null,
+			tlsPassthrough: false,
+		};
+		input.service.serverlessStreamIdleTimeoutSeconds = 600;
+		input.service.serverlessMaxConnectionLifetimeSeconds = 3600;
+
+		expect(buildServiceRevisionSpec(input).serverless).not.toHaveProperty(
+			"streamIdleTimeoutSeconds",
+		);
+
+		input.service.serverlessEnabled = true;
+		expect(buildServiceRevisionSpec(input).serverless).toMatchObject({
+			streamIdleTimeoutSeconds: 600,
+			maxConnectionLifetimeSeconds: 3600,
+		});
+
+		input.service.serverlessMaxConnectionLifetimeSeconds = 0.5;
+		expect(() => buildServiceRevisionSpec(input)).toThrow(
+			"Serverless connection lifetime must be between 1 and 86400 seconds",
+		);
+	});
+
+	it("records a valid event path only for serverless services", () => {
+		const input = draft();
+		input.ports[0] = {
+			port: 443,
+			isPublic: true,
+			domain: "api.example.com",
+			protocol: "http",
+			externalPort: null,
+			tlsPassthrough: false,
+		};
+		input.service.serverlessEventPath = "/events?source=queue";
+
+		expect(buildServiceRevisionSpec(input).serverless).not.toHaveProperty(
+			"eventPath",
+		);
+
+		input.service.serverlessEnabled = true;
+		expect(buildServiceRevisionSpec(input).serverless).toMatchObject({
+			eventPath: "/events?source=queue",
+		});
+
+		input.service.serverlessEventPath = "//other.example.com/events";
+		expect(() => buildServiceRevisionSpec(input)).toThrow(
+			"Serverless event path must be an absolute path such as /events",
+		);
+	});
+
+	it("records keep-warm windows only for serverless services", () => {
+		const input = draft();
+		input.ports[0] = {
+			port: 443,
+			isPublic: true,
+			domain: "api.example.com",
+			protocol: "http",
+			externalPort: null,
+			tlsPassthrough: false,
+		};
+		input.service.serverlessKeepWarm = [
+			{ days: ["mon", "tue"], start: "22:00", end: "06:00", timeZone: "UTC" },
+		];
+
+		expect(buildServiceRevisionSpec(input).serverless).not.toHaveProperty(
+			"keepWarm",
+		);
+
+		input.service.serverlessEnabled = true;
+		expect(buildServiceRevisionSpec(input).serverless.keepWarm).toEqual([
+			{ days: ["mon", "tue"], start: "22:00", end: "06:00", timeZone: "UTC" },
+		]);
+
+		input.service.serverlessKeepWarm = [
+			{ start: "08:00", end: "18:00", timeZone: "Mars/Olympus" },
+		];
+		expect(() => buildServiceRevisionSpec(input)).toThrow(
+			'Unknown time zone "Mars/Olympus"',
+		);
+
+		input.service.serverlessKeepWarm = [
+			{ days: ["someday"], start: "08:00", end: "18:00" },
+		];
+		expect(() => buildServiceRevisionSpec(input)).toThrow(
+			"Keep-warm days must be one of",
+		);
+	});
+
+	it("snapshots client certificate auth on public HTTP ports", () => {
+		const input = draft();
+		input.ports[0] = {
+			port: 443,
+			isPublic: true,
+			domain: "api.example.com",
+			protocol: "http",
+			externalPort: null,
+			tlsPassthrough: false,
+			clientAuthMode: "optional",
+			clientCaBundle: "-----BEGIN CERTIFICATE-----",
+		};
+
+		const specification = buildServiceRevisionSpec(input);
+
+		expect(
+			specification.ports.find((port) => port.containerPort === 443),
+		).toMatchObject({
+			clientAuth: {
+				mode: "optional",
+				caBundle: "-----BEGIN CERTIFICATE-----",
+			},
+		});
+		expect(
+			specification.ports.find((port) => port.containerPort === 80),
+		).not.toHaveProperty("clientAuth");
+	});
+
+	it("records TLS termination only for terminated TCP ports", () => {
+		const input = draft();
+		input.ports[0] = {
+			...input.ports[0],
+			domain: "db.example.com",
+			tlsPassthrough: false,
+			tlsTermination: true,
+		};
+
+		const specification = buildServiceRevisionSpec(input);
+
+		expect(
+			specification.ports.find((port) => port.containerPort === 443),
+		).toMatchObject({ domain: "db.example.com", tlsTermination: true });
+		expect(
+			specification.ports.find((port) => port.containerPort === 80),
+		).not.toHaveProperty("tlsTermination");
+	});
+
+	it("rejects TCP TLS termination without a domain or with passthrough", () => {
+		const input = draft();
+		input.ports[0] = { ...input.ports[0], tlsTermination: true };
+
+		expect(() => buildServiceRevisionSpec(input)).toThrow(
+			"TLS termination requires a public TCP port with a domain",
+		);
+
+		input.ports[0] = { ...input.ports[0], domain: "db.example.com" };
+		expect(() => buildServiceRevisionSpec(input)).toThrow(
+			"TLS passthrough and termination cannot both be enabled",
+		);
+	});
+
+	it("records PROXY protocol only for public TCP ports", () => {
+		const input = draft();
+		input.ports[0] = { ...input.ports[0], proxyProtocol: 2 };
+
+		expect(
+			buildServiceRevisionSpec(input).ports.find(
+				(port) => port.containerPort === 443,
+			),
+		).toMatchObject({ proxyProtocol: 2 });
+
+		input.ports[0] = { ...input.ports[0], protocol: "udp" };
+		expect(() => buildServiceRevisionSpec(input)).toThrow(
+			"PROXY protocol is only supported for public TCP ports",
+		);
+	});
+
+	it("rejects client certificate auth without a public HTTP domain", () => {
+		const input = draft();
+		input.ports[0] = {
+			...input.ports[0],
+			clientAuthMode: "require",
+			clientCaBundle: "-----BEGIN CERTIFICATE-----",
+		};
+
+		expect(() => buildServiceRevisionSpec(input)).toThrow(
+			"Client certificates require a public HTTP port with a domain or a TLS terminated TCP port",
+		);
+	});
+
 	it("accepts manually placed stateful serverless revisions", () => {
 		const input = draft({
 			placements: [{ serverId: "proxy-server", count: 1 }],
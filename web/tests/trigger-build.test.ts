@@ -9,6 +9,10 @@ const mocks = vi.hoisted(() => ({
 	createPreviewSync: vi.fn(),
 	createGitHubBuildServiceRevision: vi.fn(),
 	cloneGitHubBuildServiceRevision: vi.fn(),
+	createLocalBuildServiceRevision: vi.fn(),
+	createGitBuildServiceRevision: vi.fn(),
+	resolveGitBranchCommit: vi.fn(),
+	gitTokenAuthForService: vi.fn(),
 }));
 
 vi.mock("@/db", () => ({
@@ -29,11 +33,18 @@ vi.mock("@/lib/inngest/events", () => ({
 vi.mock("@/lib/service-revisions", () => ({
 	createGitHubBuildServiceRevision: mocks.createGitHubBuildServiceRevision,
 	cloneGitHubBuildServiceRevision: mocks.cloneGitHubBuildServiceRevision,
+	createLocalBuildServiceRevision: mocks.createLocalBuildServiceRevision,
+	createGitBuildServiceRevision: mocks.createGitBuildServiceRevision,
+}));
+vi.mock("@/lib/git-remote", () => ({
+	resolveGitBranchCommit: mocks.resolveGitBranchCommit,
+	gitTokenAuthForService: mocks.gitTokenAuthForService,
 }));
 
 import {
 	requeueBuildRevisionInternal,
 	triggerBuildInternal,
+	triggerLocalBuildInternal,
 } from "@/lib/trigger-build";
 
 function queryReturning(rows: unknown[]) {
@@ -302,3 +313,167 @@ describe("internal GitHub build trigger", () => {
 		);
 	});
 });
+
+describe("internal local build trigger", () => {
+	const checksum = "cd".repeat(32);
+	const actor = { type: "user" as const, userId: "user-1", name: "Alice" };
+
+	beforeEach(() => {
+		vi.clearAllMocks();
+		process.env.REGISTRY_HOST = "registry.test";
+		mocks.createLocalBuildServiceRevision.mockResolvedValue({});
+		mocks.select.mockImplementation(() =>
+			queryReturning([{ id: "service-1", projectId: "project-1" }]),
+		);
+	});
+
+	it("queues the uploaded context keyed by its checksum", async () => {
+		await expect(
+			triggerLocalBuildInternal("service-1", {
+				contextId: "context-1",
+				checksum,
+				actor,
+			}),
+		).resolves.toEqual(
+			expect.objectContaining({ buildId: null, status: "queued" }),
+		);
+		const revision = mocks.createLocalBuildServiceRevision.mock.calls[0][0];
+		expect(revision).toEqual({
+			id: revision.id,
+			serviceId: "service-1",
+			image: `registry.test/project-1/service-1:revision-${revision.id}`,
+			contextId: "context-1",
+			checksum,
+			actor,
+		});
+		expect(mocks.createBuildTrigger).toHaveBeenCalledWith(
+			expect.objectContaining({
+				serviceRevisionId: revision.id,
+				trigger: "local",
+				commitSha: checksum,
+				branch: "local",
+			}),
+		);
+		expect(mocks.resolveGitHubCommit).not.toHaveBeenCalled();
+	});
+
+	it("rejects a checksum that is not a SHA-256 digest", async () => {
+		await expect(
+			triggerLocalBuildInternal("service-1", {
+				contextId: "context-1",
+				checksum: "abc",
+				actor,
+			}),
+		).rejects.toThrow("Build context checksum must be a SHA-256 hex digest");
+		expect(mocks.createLocalBuildServiceRevision).not.toHaveBeenCalled();
+	});
+});
+
+describe("internal git build trigger", () => {
+	const actor = { type: "user" as const, userId: "user-1", name: "Alice" };
+	const sha = "89abcdef0123456789abcdef0123456789abcdef";
+	const gitService = (repository: string) => ({
+		id: "service-1",
+		projectId: "project-1",
+		sourceType: "git",
+		deletedAt: null,
+		previewOfService: null,
+		gitRepoUrl: repository,
+		gitBranch: "release",
+		gitRootDir: null,
+		gitSubmodules: false,
+		gitLfs: false,
+	});
+
+	beforeEach(() => {
+		vi.clearAllMocks();
+		process.env.REGISTRY_HOST = "registry.test";
+		mocks.rows = [];
+		mocks.createGitBuildServiceRevision.mockResolvedValue({});
+		mocks.gitTokenAuthForService.mockResolvedValue(null);
+		mocks.resolveGitBranchCommit.mockResolvedValue(sha);
+		mocks.select.mockImplementation(() =>
+			queryReturning(mocks.rows.shift() ?? []),
+		);
+	});
+
+	it("pins the advertised branch head of an HTTPS remote", async () => {
+		mocks.rows = [[gitService("https://git.example.com/acme/app.git")], []];
+
+		await triggerBuildInternal("service-1", "manual", actor);
+
+		expect(mocks.resolveGitBranchCommit).toHaveBeenCalledWith(
+			"https://git.example.com/acme/app.git",
+			"release",
+			null,
+		);
+		const revision = mocks.createGitBuildServiceRevision.mock.calls[0][0];
+		expect(revision).toEqual({
+			id: revision.id,
+			serviceId: "service-1",
+			image: `registry.test/project-1/service-1:revision-${revision.id}`,
+			commitSha: sha,
+			expectedRepository: "https://git.example.com/acme/app.git",
+			expectedBranch: "release",
+			actor,
+		});
+		expect(mocks.createBuildTrigger).toHaveBeenCalledWith(
+			expect.objectContaining({
+				commitSha: sha,
+				commitMessage: "Latest commit on release",
+				branch: "release",
+			}),
+		);
+		expect(mocks.resolveGitHubCommit).not.toHaveBeenCalled();
+	});
+
+	it("requires an explicit commit for SSH remotes", async () => {
+		mocks.rows = [[gitService("git@git.example.com:acme/app.git")], []];
+
+		await expect(
+			triggerBuildInternal("service-1", "manual", actor),
+		).rejects.toThrow("SSH git sources need an explicit commit SHA");
+		expect(mocks.resolveGitBranchCommit).not.toHaveBeenCalled();
+		expect(mocks.createGitBuildServiceRevision).not.toHaveBeenCalled();
+	});
+
+	it("builds the commit an SSH deploy resolved", async () => {
+		mocks.rows = [[gitService("git@git.example.com:acme/app.git")], []];
+
+		await triggerBuildInternal("service-1", "manual", actor, {
+			commitSha: sha.toUpperCase(),
+		});
+
+		expect(mocks.createGitBuildServiceRevision).toHaveBeenCalledWith(
+			expect.objectContaining({
+				commitSha: sha,
+				expectedRepository: "git@git.example.com:acme/app.git",
+			}),
+		);
+		expect(mocks.createBuildTrigger).toHaveBeenCalledWith(
+			expect.objectContaining({
+				commitSha: sha,
+				commitMessage: "Selected commit on release",
+			}),
+		);
+	});
+
+	it("rejects an explicit commit for GitHub sources", async () => {
+		mocks.rows = [
+			[
+				{
+					id: "service-1",
+					projectId: "project-1",
+					sourceType: "github",
+					deletedAt: null,
+					githubRepoUrl: "https://github.com/acme/app",
+				},
+			],
+			[],
+		];
+
+		await expect(
+			triggerBuildInternal("service-1", "manual", actor, { commitSha: sha }),
+		).rejects.toThrow("Only git sources accept an explicit build commit");
+	});
+});
@@ -0,0 +1,23 @@
+import { describe, expect, it } from "vitest";
+import { parseTrustedProxies } from "@/lib/trusted-proxies";
+
+describe("parseTrustedProxies", () => {
+	it("accepts IP addresses and CIDR ranges", () => {
+		expect(
+			parseTrustedProxies("10.0.0.0/8, 2001:db8::/32\n203.0.113.7 10.0.0.0/8"),
+		).toEqual(["10.0.0.0/8", "2001:db8::/32", "203.0.113.7"]);
+		expect(parseTrustedProxies("  ")).toEqual([]);
+	});
+
+	it("rejects hostnames and out of range prefixes", () => {
+		expect(() => parseTrustedProxies("lb.example.com")).toThrow(
+			'Invalid trusted proxy "lb.example.com"',
+		);
+		expect(() => parseTrustedProxies("10.0.0.0/33")).toThrow(
+			'Invalid trusted proxy "10.0.0.0/33"',
+		);
+		expect(() => parseTrustedProxies("10.0.0.0/8/1")).toThrow(
+			"Invalid trusted proxy",
+		);
+	});
+});
@@ -183,6 +183,29 @@ describe("VictoriaLogs queries", () => {
 		expect(url?.searchParams.get("query")).toContain("_time:24h");
 	});
 
+	it("narrows public service logs to a single run", async () => {
+		const { queryPublicServiceLogs } = await loadVictoriaLogs();
+		const urls: URL[] = [];
+		vi.stubGlobal(
+			"fetch",
+			vi.fn(async (input: string | URL | Request) => {
+				urls.push(new URL(String(input)));
+				return jsonLinesResponse([]);
+			}),
+		);
+
+		await queryPublicServiceLogs({
+			serviceId: "service-1",
+			deploymentId: "run-1",
+			limit: 100,
+			logType: "container",
+		});
+
+		expect(urls[0]?.searchParams.get("query")).toContain(
+			"service_id:service-1 -log_type:http -log_type:build -log_type:rollout -log_type:cron deployment_id:run-1",
+		);
+	});
+
 	it("pages equal-timestamp public logs by event ID without skips", async () => {
 		const { queryPublicServiceLogs } = await loadVictoriaLogs();
 		const queries: string[] = [];
@@ -303,6 +326,56 @@ describe("VictoriaLogs queries", () => {
 		expect(query).not.toContain("_time:<2026-07-10T01:02:03Z");
 	});
 
+	it("follows build logs after the supplied cursor in time order", async () => {
+		const { queryLogsByBuild } = await loadVictoriaLogs();
+		let query = "";
+		vi.stubGlobal(
+			"fetch",
+			vi.fn(async (input: string | URL | Request) => {
+				query = new URL(String(input)).searchParams.get("query") || "";
+				return jsonLinesResponse([]);
+			}),
+		);
+
+		await queryLogsByBuild("build-1", {
+			after: { time: "2026-07-10T01:02:03.000004Z", eventId: "" },
+		});
+
+		expect(query).toContain("build_id:build-1");
+		expect(query).toContain("_time:>2026-07-10T01:02:03.000004Z");
+		expect(query).toContain("sort by (_time, event_id)");
+		await expect(
+			queryLogsByBuild("build-1", {
+				after: { time: "yesterday", eventId: "" },
+			}),
+		).rejects.toThrow("Invalid log cursor");
+		await expect(
+			queryLogsByBuild("build-1", {
+				after: { time: "2026-07-10T01:02:03Z", eventId: "latest" },
+			}),
+		).rejects.toThrow("Invalid log cursor");
+	});
+
+	it("follows equal-timestamp build logs by event ID", async () => {
+		const { queryLogsByBuild } = await loadVictoriaLogs();
+		let query = "";
+		vi.stubGlobal(
+			"fetch",
+			vi.fn(async (input: string | URL | Request) => {
+				query = new URL(String(input)).searchParams.get("query") || "";
+				return jsonLinesResponse([]);
+			}),
+		);
+
+		await queryLogsByBuild("build-1", {
+			after: { time: "2026-07-10T01:02:03Z", eventId: eventId("b") },
+		});
+
+		expect(query).toContain(
+			`(_time:>2026-07-10T01:02:03Z OR (_time:>=2026-07-10T01:02:03Z _time:<=2026-07-10T01:02:03Z event_id:>"${eventId("b")}"))`,
+		);
+	});
+
 	it("searches every field exposed by the request-log search box", async () => {
 		const { queryLogsByService } = await loadVictoriaLogs();
 		let query = "";
@@ -425,6 +498,7 @@ describe("VictoriaLogs queries", () => {
 			path: "/job",
 			source: "manual",
 			scheduled_for: "2026-08-06T10:00:00Z",
+			attempt: 1,
 			started_at: "2026-08-06T10:00:00Z",
 			finished_at: "2026-08-06T10:00:01Z",
 			result: "succeeded",
@@ -0,0 +1,66 @@
+import { describe, expect, it } from "vitest";
+import {
+	evaluateVulnerabilityGate,
+	parseVulnerabilitySummary,
+} from "@/lib/vulnerability-gate";
+
+const clean = { critical: 0, high: 1, medium: 2, low: 3, unknown: 0 };
+
+describe("vulnerability gate", () => {
+	it("allows every rollout without a threshold", () => {
+		expect(
+			evaluateVulnerabilityGate(null, [
+				{ targetPlatform: "linux/amd64", vulnerabilities: null },
+			]),
+		).toEqual({ blocked: false });
+	});
+
+	it("allows critical findings up to the threshold", () => {
+		expect(
+			evaluateVulnerabilityGate(1, [
+				{
+					targetPlatform: "linux/amd64",
+					vulnerabilities: { ...clean, critical: 1 },
+				},
+			]),
+		).toEqual({ blocked: false });
+	});
+
+	it("blocks a platform over the threshold or without a scan", () => {
+		expect(
+			evaluateVulnerabilityGate(0, [
+				{ targetPlatform: "linux/amd64", vulnerabilities: clean },
+				{
+					targetPlatform: "linux/arm64",
+					vulnerabilities: { ...clean, critical: 3 },
+				},
+			]),
+		).toEqual({
+			blocked: true,
+			reason:
+				"Rollout blocked: the linux/arm64 image has 3 critical vulnerabilities (threshold 0)",
+		});
+		expect(
+			evaluateVulnerabilityGate(5, [
+				{ targetPlatform: "linux/amd64", vulnerabilities: null },
+			]),
+		).toEqual({
+			blocked: true,
+			reason:
+				"Rollout blocked: the linux/amd64 image was not scanned for vulnerabilities",
+		});
+	});
+
+	it("parses agent summaries and rejects invalid counts", () => {
+		expect(parseVulnerabilitySummary({ critical: 1, high: 2 })).toEqual({
+			critical: 1,
+			high: 2,
+			medium: 0,
+			low: 0,
+			unknown: 0,
+		});
+		expect(parseVulnerabilitySummary({ critical: -1 })).toBeNull();
+		expect(parseVulnerabilitySummary({ critical: 1.5 })).toBeNull();
+		expect(parseVulnerabilitySummary("critical")).toBeNull();
+	});
+});
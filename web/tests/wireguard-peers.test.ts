@@ -0,0 +1,63 @@
+import { describe, expect, it, vi } from "vitest";
+
+const mocks = vi.hoisted(() => {
+	const rows: unknown[] = [];
+	const query = {
+		from: vi.fn(() => query),
+		where: vi.fn(() => query),
+		// oxlint-disable-next-line unicorn/no-thenable -- Drizzle query builders are awaitable.
+		then: (
+			resolve: (value: unknown[]) => unknown,
+			reject?: (reason: unknown) => unknown,
+		) => Promise.resolve(rows).then(resolve, reject),
+	};
+	return { rows, db: { select: vi.fn(() => query) } };
+});
+
+vi.mock("@/db", () => ({ db: mocks.db }));
+
+import { getWireGuardPeers } from "@/lib/wireguard";
+
+describe("getWireGuardPeers", () => {
+	it("identifies peers so agents can address and verify each other", async () => {
+		mocks.rows.push(
+			{
+				id: "server_2",
+				subnetId: 2,
+				wireguardIp: "10.100.2.1",
+				wireguardPublicKey: "wg-key-2",
+				publicIp: "203.0.113.2",
+				privateIp: null,
+				signingPublicKey: "signing-key-2",
+			},
+			{
+				id: "server_3",
+				subnetId: 3,
+				wireguardIp: "10.100.3.1",
+				wireguardPublicKey: "wg-key-3",
+				publicIp: null,
+				privateIp: "192.168.0.3",
+				signingPublicKey: null,
+			},
+		);
+
+		const peers = await getWireGuardPeers("server_1", "192.168.0.1");
+
+		expect(peers).toEqual([
+			expect.objectContaining({
+				publicKey: "wg-key-2",
+				endpoint: "203.0.113.2:51820",
+				serverId: "server_2",
+				wireguardIp: "10.100.2.1",
+				signingPublicKey: "signing-key-2",
+			}),
+			expect.objectContaining({
+				publicKey: "wg-key-3",
+				endpoint: "192.168.0.3:51820",
+				serverId: "server_3",
+				wireguardIp: "10.100.3.1",
+			}),
+		]);
+		expect(peers[1]).not.toHaveProperty("signingPublicKey");
+	});
+});
@@ -17,6 +17,7 @@ const mocks = vi.hoisted(() => {
 			from: vi.fn(() => query),
 			set: vi.fn(() => query),
 			where: vi.fn(() => query),
+			limit: vi.fn(() => query),
 			returning: vi.fn(() => query),
 			// oxlint-disable-next-line unicorn/no-thenable -- Drizzle query builders are awaitable.
 			then: (
@@ -109,6 +110,9 @@ vi.mock("@/lib/inngest/events", () => ({
 				...options,
 			})),
 		},
+		resourceStatusChanged: {
+			create: vi.fn((data) => ({ name: "resource/status-changed", data })),
+		},
 	},
 }));
 vi.mock("@/lib/server-errors", () => ({
@@ -119,7 +123,11 @@ vi.mock("@/lib/work-queue-notifications", () => ({
 	notifyWorkAvailable: vi.fn(),
 }));
 
-import { claimNextWorkItem, completeWorkItemResults } from "@/lib/work-queue";
+import {
+	claimNextWorkItem,
+	completeWorkItemResults,
+	holdsWorkSlot,
+} from "@/lib/work-queue";
 
 function restoreWorkItem(id: string, overrides: Record<string, unknown> = {}) {
 	return {
@@ -139,11 +147,11 @@ function restoreWorkItem(id: string, overrides: Record<string, unknown> = {}) {
 	};
 }
 
-function commandWorkItem(id: string) {
+function commandWorkItem(id: string, type = "command") {
 	return {
 		id,
 		serverId: "server-1",
-		type: "command",
+		type,
 		payload: JSON.stringify({ commandRunId: id }),
 		status: "completed",
 		attempts: 1,
@@ -172,12 +180,34 @@ beforeEach(() => {
 });
 
 describe("command work completion", () => {
-	it("does not re-lease a processing command", async () => {
+	it("does not re-lease a processing command or run", async () => {
 		await claimNextWorkItem("server-1");
 
 		const condition = mocks.db.execute.mock.calls[0]?.[0];
 		const query = new PgDialect().sqlToQuery(condition);
-		expect(query.sql).toContain("type <> 'command'");
+		expect(query.sql).toContain("type NOT IN ('command', 'run')");
+	});
+
+	it("only leases a build when one heads the queue", async () => {
+		await claimNextWorkItem("server-1", { buildOnly: true });
+
+		const condition = mocks.db.execute.mock.calls[0]?.[0];
+		const query = new PgDialect().sqlToQuery(condition);
+		expect(query.sql).toMatch(/LIMIT 1\s*\)\s*AND type = 'build'/);
+	});
+
+	it("does not let active task runs hold the work slot", async () => {
+		expect(await holdsWorkSlot("server-1", [])).toBe(false);
+		expect(mocks.db.select).not.toHaveBeenCalled();
+
+		const active = [{ id: "run-1", attempt: 1 }];
+		expect(await holdsWorkSlot("server-1", active)).toBe(false);
+		const query = mocks.db.select.mock.results[0]?.value;
+		const condition = query.where.mock.calls[0]?.[0];
+		expect(new PgDialect().sqlToQuery(condition).sql).toContain('"type" <> $');
+
+		mocks.state.rejectionRows = [{ id: "deploy-1" }];
+		expect(await holdsWorkSlot("server-1", active)).toBe(true);
 	});
 
 	it("persists successful command output", async () => {
@@ -285,6 +315,110 @@ describe("command work completion", () => {
 	});
 });
 
+describe("run work completion", () => {
+	it("persists a successful run exit code", async () => {
+		mocks.state.updatedRows = [commandWorkItem("run-1", "run")];
+
+		const result = await completeWorkItemResults("server-1", [
+			{
+				id: "run-1",
+				attempt: 1,
+				status: "completed",
+				result: { type: "run", exitCode: 0 },
+			},
+		]);
+
+		expect(result).toEqual({ accepted: ["run-1"], rejected: [] });
+		expect(mocks.state.updates).toContainEqual(
+			expect.objectContaining({ status: "succeeded", exitCode: 0 }),
+		);
+	});
+
+	it("persists failed and timed out runs distinctly", async () => {
+		mocks.state.updatedRows = [commandWorkItem("run-1", "run")];
+
+		await completeWorkItemResults("server-1", [
+			{
+				id: "run-1",
+				attempt: 1,
+				status: "failed",
+				error: "run timed out",
+				result: { type: "run", exitCode: 124, timedOut: true },
+			},
+		]);
+
+		expect(mocks.state.updates).toContainEqual(
+			expect.objectContaining({
+				status: "timed_out",
+				exitCode: 124,
+				errorMessage: "run timed out",
+			}),
+		);
+	});
+
+	it("wakes the rollout waiting on a release run", async () => {
+		mocks.state.updatedRows = [
+			{
+				...commandWorkItem("run-1", "run"),
+				payload: JSON.stringify({ runId: "run-1", rolloutId: "rollout-1" }),
+			},
+		];
+
+		await completeWorkItemResults("server-1", [
+			{
+				id: "run-1",
+				attempt: 1,
+				status: "failed",
+				error: "run exited with code 1",
+				result: { type: "run", exitCode: 1 },
+			},
+		]);
+
+		expect(mocks.send).toHaveBeenCalledWith({
+			name: "resource/status-changed",
+			data: {
+				type: "run",
+				id: "run-1",
+				parentType: "rollout",
+				parentId: "rollout-1",
+			},
+		});
+	});
+
+	it("does not publish events for one-off runs", async () => {
+		mocks.state.updatedRows = [commandWorkItem("run-1", "run")];
+
+		await completeWorkItemResults("server-1", [
+			{
+				id: "run-1",
+				attempt: 1,
+				status: "completed",
+				result: { type: "run", exitCode: 0 },
+			},
+		]);
+
+		expect(mocks.send).not.toHaveBeenCalled();
+	});
+
+	it("rejects run result data for a command work item", async () => {
+		mocks.state.updatedRows = [commandWorkItem("command-1")];
+
+		const result = await completeWorkItemResults("server-1", [
+			{
+				id: "command-1",
+				attempt: 1,
+				status: "failed",
+				result: { type: "run", exitCode: 1 },
+			},
+		]);
+
+		expect(result).toEqual({
+			accepted: [],
+			rejected: [{ id: "command-1", reason: "invalid_result" }],
+		});
+	});
+});
+
 describe("restore work completion", () => {
 	it("publishes an authorized normal restore success", async () => {
 		const result = await completeWorkItemResults("server-1", [